@@ -19,8 +19,13 @@ import (
 	"net"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent"
@@ -31,11 +36,14 @@ import (
 	"antrea.io/antrea/pkg/agent/controller/egress"
 	"antrea.io/antrea/pkg/agent/controller/networkpolicy"
 	"antrea.io/antrea/pkg/agent/controller/noderoute"
+	"antrea.io/antrea/pkg/agent/controller/packetcapture"
+	"antrea.io/antrea/pkg/agent/controller/serviceexternalip"
 	"antrea.io/antrea/pkg/agent/controller/traceflow"
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/exporter"
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/ipassigner"
+	"antrea.io/antrea/pkg/agent/l7proxy"
 	"antrea.io/antrea/pkg/agent/memberlist"
 	"antrea.io/antrea/pkg/agent/metrics"
 	npl "antrea.io/antrea/pkg/agent/nodeportlocal"
@@ -47,15 +55,18 @@ import (
 	"antrea.io/antrea/pkg/agent/stats"
 	"antrea.io/antrea/pkg/agent/types"
 	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
 	"antrea.io/antrea/pkg/controller/externalippool"
 	"antrea.io/antrea/pkg/features"
 	"antrea.io/antrea/pkg/log"
 	"antrea.io/antrea/pkg/monitor"
 	ofconfig "antrea.io/antrea/pkg/ovs/openflow"
 	"antrea.io/antrea/pkg/ovs/ovsconfig"
+	commonquerier "antrea.io/antrea/pkg/querier"
 	"antrea.io/antrea/pkg/signals"
 	"antrea.io/antrea/pkg/util/cipher"
 	"antrea.io/antrea/pkg/util/k8s"
+	"antrea.io/antrea/pkg/util/rulemetrics"
 	"antrea.io/antrea/pkg/version"
 )
 
@@ -64,6 +75,11 @@ import (
 // https://github.com/kubernetes/kubernetes/blob/release-1.17/pkg/controller/apis/config/v1alpha1/defaults.go#L120
 const informerDefaultResync = 12 * time.Hour
 
+// dnsCacheFile is the file in the agent's state directory where the DNS cache
+// of FQDN policies is persisted, so that FQDN policy rules are realized with the
+// cached IPs right after the agent restarts.
+const dnsCacheFile = "/var/run/antrea/fqdn-dns-cache.json"
+
 // The devices that should be excluded from NodePort.
 var excludeNodePortDevices = []string{"antrea-egress0", "kube-ipvs0"}
 
@@ -83,6 +99,7 @@ func run(o *Options) error {
 	informerFactory := informers.NewSharedInformerFactory(k8sClient, informerDefaultResync)
 	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
 	traceflowInformer := crdInformerFactory.Crd().V1alpha1().Traceflows()
+	packetCaptureInformer := crdInformerFactory.Crd().V1alpha1().PacketCaptures()
 	egressInformer := crdInformerFactory.Crd().V1alpha2().Egresses()
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	externalIPPoolInformer := crdInformerFactory.Crd().V1alpha2().ExternalIPPools()
@@ -118,7 +135,8 @@ func run(o *Options) error {
 		features.DefaultFeatureGate.Enabled(features.Egress),
 		features.DefaultFeatureGate.Enabled(features.FlowExporter),
 		o.config.AntreaProxy.ProxyAll,
-		connectUplinkToBridge)
+		connectUplinkToBridge,
+		features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR))
 
 	_, serviceCIDRNet, _ := net.ParseCIDR(o.config.ServiceCIDR)
 	var serviceCIDRNetv6 *net.IPNet
@@ -231,13 +249,13 @@ func run(o *Options) error {
 
 		switch {
 		case v4Enabled && v6Enabled:
-			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, ofClient, routeClient, nodePortAddressesIPv4, nodePortAddressesIPv6, proxyAll, skipServices, v4GroupCounter, v6GroupCounter)
+			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, k8sClient, ofClient, routeClient, nodePortAddressesIPv4, nodePortAddressesIPv6, proxyAll, skipServices, v4GroupCounter, v6GroupCounter)
 			groupCounters = append(groupCounters, v4GroupCounter, v6GroupCounter)
 		case v4Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAll, skipServices, v4GroupCounter)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, k8sClient, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAll, skipServices, v4GroupCounter)
 			groupCounters = append(groupCounters, v4GroupCounter)
 		case v6Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAll, skipServices, v6GroupCounter)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, k8sClient, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAll, skipServices, v6GroupCounter)
 			groupCounters = append(groupCounters, v6GroupCounter)
 		default:
 			return fmt.Errorf("at least one of IPv4 or IPv6 should be enabled")
@@ -260,6 +278,20 @@ func run(o *Options) error {
 	statusManagerEnabled := antreaPolicyEnabled
 	loggingEnabled := antreaPolicyEnabled
 
+	// l7Proxy is left nil when the L7NetworkPolicy feature is disabled.
+	var l7Proxy l7proxy.Interface
+	if antreaPolicyEnabled && features.DefaultFeatureGate.Enabled(features.L7NetworkPolicy) {
+		l7Proxy, err = l7proxy.NewL7Proxy(o.config.HostGateway, l7proxy.DefaultListenPort, v4Enabled, v6Enabled)
+		if err != nil {
+			return fmt.Errorf("error creating new L7 proxy: %v", err)
+		}
+	}
+
+	// auditTierLister is used to log the names of the Tiers in the audit logs of Antrea-native policies.
+	var auditTierLister crdlisters.TierLister
+	if loggingEnabled {
+		auditTierLister = crdInformerFactory.Crd().V1alpha1().Tiers().Lister()
+	}
 	networkPolicyController, err := networkpolicy.NewNetworkPolicyController(
 		antreaClientProvider,
 		ofClient,
@@ -272,8 +304,12 @@ func run(o *Options) error {
 		antreaProxyEnabled,
 		statusManagerEnabled,
 		loggingEnabled,
+		o.config.AuditLogging,
 		asyncRuleDeleteInterval,
-		o.config.DNSServerOverride)
+		o.config.DNSServerOverride,
+		dnsCacheFile,
+		l7Proxy,
+		auditTierLister)
 	if err != nil {
 		return fmt.Errorf("error creating new NetworkPolicy controller: %v", err)
 	}
@@ -282,10 +318,23 @@ func run(o *Options) error {
 	// NetworkPolicy stats.
 	var statsCollector *stats.Collector
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
-		statsCollector = stats.NewCollector(antreaClientProvider, ofClient, networkPolicyController)
+		// ruleMetricsBudget and tierLister are left nil when the NetworkPolicy rule metrics are disabled or not
+		// filtered by Tier.
+		var ruleMetricsBudget *rulemetrics.Budget
+		var tierLister crdlisters.TierLister
+		if *o.config.EnablePrometheusMetrics && o.config.NetworkPolicyMetrics.Enable {
+			metricsConfig := o.config.NetworkPolicyMetrics
+			ruleMetricsBudget = rulemetrics.NewBudget(metricsConfig.MaxRules, metricsConfig.Tiers, metricsConfig.Namespaces)
+			if len(metricsConfig.Tiers) > 0 {
+				tierLister = crdInformerFactory.Crd().V1alpha1().Tiers().Lister()
+			}
+		}
+		statsCollector = stats.NewCollector(antreaClientProvider, ofClient, networkPolicyController, ruleMetricsBudget, tierLister)
 	}
 
 	var egressController *egress.EgressController
+	// egressQuerier is left nil when the Egress feature is disabled.
+	var egressQuerier commonquerier.EgressQuerier
 	var nodeTransportIP net.IP
 	if nodeConfig.NodeTransportIPv4Addr != nil {
 		nodeTransportIP = nodeConfig.NodeTransportIPv4Addr.IP
@@ -299,17 +348,21 @@ func run(o *Options) error {
 	var memberlistCluster *memberlist.Cluster
 	var localIPDetector ipassigner.LocalIPDetector
 
-	if features.DefaultFeatureGate.Enabled(features.Egress) {
-		externalIPPoolController = externalippool.NewExternalIPPoolController(
-			crdClient, externalIPPoolInformer,
-		)
-		localIPDetector = ipassigner.NewLocalIPDetector()
+	// The memberlist cluster selects the owner Nodes of the IPs of both Egresses and Services.
+	if features.DefaultFeatureGate.Enabled(features.Egress) || features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
 		memberlistCluster, err = memberlist.NewCluster(o.config.ClusterMembershipPort,
 			nodeConfig.Name, nodeInformer, externalIPPoolInformer,
 		)
 		if err != nil {
 			return fmt.Errorf("error creating new MemberList cluster: %v", err)
 		}
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.Egress) {
+		externalIPPoolController = externalippool.NewExternalIPPoolController(
+			crdClient, externalIPPoolInformer,
+		)
+		localIPDetector = ipassigner.NewLocalIPDetector()
 		egressController, err = egress.NewEgressController(
 			ofClient, antreaClientProvider, crdClient, ifaceStore, routeClient, nodeConfig.Name, nodeTransportIP,
 			memberlistCluster, egressInformer, nodeInformer, localIPDetector,
@@ -317,6 +370,17 @@ func run(o *Options) error {
 		if err != nil {
 			return fmt.Errorf("error creating new Egress controller: %v", err)
 		}
+		egressQuerier = egressController
+	}
+
+	var serviceExternalIPController *serviceexternalip.ServiceExternalIPController
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		serviceExternalIPController, err = serviceexternalip.NewServiceExternalIPController(
+			nodeConfig.Name, nodeTransportIP, memberlistCluster, informerFactory.Core().V1().Services(),
+		)
+		if err != nil {
+			return fmt.Errorf("error creating new ServiceExternalIP controller: %v", err)
+		}
 	}
 
 	isChaining := false
@@ -347,11 +411,25 @@ func run(o *Options) error {
 			traceflowInformer,
 			ofClient,
 			networkPolicyController,
+			egressQuerier,
 			ovsBridgeClient,
 			ifaceStore,
 			networkConfig,
 			nodeConfig,
-			serviceCIDRNet)
+			serviceCIDRNet,
+			egressConfig,
+			o.config.AntreaProxy.ProxyAll)
+	}
+
+	var packetCaptureController *packetcapture.Controller
+	if features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+		packetCaptureController = packetcapture.NewPacketCaptureController(
+			k8sClient,
+			crdClient,
+			packetCaptureInformer,
+			ofClient,
+			ifaceStore,
+			nodeConfig)
 	}
 
 	// TODO: we should call this after installing flows for initial node routes
@@ -372,15 +450,36 @@ func run(o *Options) error {
 		return err
 	}
 
+	enableFlowExporter := features.DefaultFeatureGate.Enabled(features.FlowExporter)
+	enableNodePortLocal := features.DefaultFeatureGate.Enabled(features.NodePortLocal) && o.config.NodePortLocal.Enable
+	// Create a Pod informer which only watches the Pods running on the Node, shared by the
+	// components which need them.
+	var localPodInformer cache.SharedIndexInformer
+	if enableFlowExporter || enableNodePortLocal {
+		localPodInformer = coreinformers.NewFilteredPodInformer(
+			k8sClient,
+			metav1.NamespaceAll,
+			0,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, // NamespaceIndex is used in NPLController.
+			func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeConfig.Name).String()
+			},
+		)
+	}
+
 	var flowExporter *exporter.FlowExporter
-	if features.DefaultFeatureGate.Enabled(features.FlowExporter) {
+	if enableFlowExporter {
 		flowExporterOptions := &flowexporter.FlowExporterOptions{
-			FlowCollectorAddr:      o.flowCollectorAddr,
-			FlowCollectorProto:     o.flowCollectorProto,
-			ActiveFlowTimeout:      o.activeFlowTimeout,
-			IdleFlowTimeout:        o.idleFlowTimeout,
-			StaleConnectionTimeout: o.staleConnectionTimeout,
-			PollInterval:           o.pollInterval}
+			FlowCollectorAddr:            o.flowCollectorAddr,
+			FlowCollectorProto:           o.flowCollectorProto,
+			FlowCollectorShardingService: o.flowCollectorShardingService,
+			ActiveFlowTimeout:            o.activeFlowTimeout,
+			IdleFlowTimeout:              o.idleFlowTimeout,
+			StaleConnectionTimeout:       o.staleConnectionTimeout,
+			PollInterval:                 o.pollInterval,
+			FlowExportPolicy:             o.config.FlowExportPolicy,
+			FlowExportPolicyFile:         o.getReloadableConfigFile(),
+			EnableTCPMetrics:             o.config.FlowExportTCPMetrics}
 		flowExporter, err = exporter.NewFlowExporter(
 			ifaceStore,
 			proxier,
@@ -395,18 +494,28 @@ func run(o *Options) error {
 			ovsDatapathType,
 			features.DefaultFeatureGate.Enabled(features.AntreaProxy),
 			networkPolicyController,
+			egressQuerier,
+			informerFactory.Core().V1().Services().Lister(),
+			corelisters.NewPodLister(localPodInformer.GetIndexer()),
 			flowExporterOptions)
 		if err != nil {
 			return fmt.Errorf("error when creating IPFIX flow exporter: %v", err)
 		}
 		networkPolicyController.SetDenyConnStore(flowExporter.GetDenyConnStore())
+		if o.config.FlowExportTCPMetrics {
+			ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonFE), "tcpmetrics", flowExporter.GetTCPMetricsStore())
+			if err := ofClient.InstallTCPMetricsFlows(); err != nil {
+				return fmt.Errorf("error when installing TCP metrics flows: %v", err)
+			}
+		}
 	}
 
 	// Start the NPL agent.
-	if features.DefaultFeatureGate.Enabled(features.NodePortLocal) && o.config.NodePortLocal.Enable {
+	if enableNodePortLocal {
 		nplController, err := npl.InitializeNPLAgent(
 			k8sClient,
 			informerFactory,
+			localPodInformer,
 			o.nplStartPort,
 			o.nplEndPort,
 			nodeConfig.Name)
@@ -436,20 +545,34 @@ func run(o *Options) error {
 
 	informerFactory.Start(stopCh)
 	crdInformerFactory.Start(stopCh)
+	if localPodInformer != nil {
+		go localPodInformer.Run(stopCh)
+	}
 
 	go antreaClientProvider.Run(stopCh)
 
 	go nodeRouteController.Run(stopCh)
 
+	if l7Proxy != nil {
+		go l7Proxy.Run(stopCh)
+	}
+
 	go networkPolicyController.Run(stopCh)
 
+	if features.DefaultFeatureGate.Enabled(features.Egress) || features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		go memberlistCluster.Run(stopCh)
+	}
+
 	if features.DefaultFeatureGate.Enabled(features.Egress) {
 		go externalIPPoolController.Run(stopCh)
 		go localIPDetector.Run(stopCh)
-		go memberlistCluster.Run(stopCh)
 		go egressController.Run(stopCh)
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		go serviceExternalIPController.Run(stopCh)
+	}
+
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
 		go statsCollector.Run(stopCh)
 	}
@@ -458,6 +581,10 @@ func run(o *Options) error {
 		go traceflowController.Run(stopCh)
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+		go packetCaptureController.Run(stopCh)
+	}
+
 	if features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 		go proxier.GetProxyProvider().Run(stopCh)
 
@@ -516,6 +643,12 @@ func run(o *Options) error {
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
 		packetInReasons = append(packetInReasons, uint8(openflow.PacketInReasonNP))
 	}
+	if features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+		packetInReasons = append(packetInReasons, uint8(openflow.PacketInReasonPC))
+	}
+	if features.DefaultFeatureGate.Enabled(features.FlowExporter) && o.config.FlowExportTCPMetrics {
+		packetInReasons = append(packetInReasons, uint8(openflow.PacketInReasonFE))
+	}
 	if len(packetInReasons) > 0 {
 		go ofClient.StartPacketInHandler(packetInReasons, stopCh)
 	}
@@ -23,9 +23,12 @@ import (
 
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/controller/networkpolicy"
 	"antrea.io/antrea/pkg/apis"
 	"antrea.io/antrea/pkg/cni"
 	agentconfig "antrea.io/antrea/pkg/config/agent"
@@ -48,6 +51,10 @@ const (
 	defaultIdleFlowExportTimeout   = 15 * time.Second
 	defaultStaleConnectionTimeout  = 5 * time.Minute
 	defaultNPLPortRange            = "61000-62000"
+	defaultAuditLogTransport       = "tcp"
+	defaultAuditLogBufferSize      = 10000
+	// defaultNetworkPolicyMetricsMaxRules is the default cardinality budget of the NetworkPolicy rule metrics.
+	defaultNetworkPolicyMetricsMaxRules = 500
 )
 
 type Options struct {
@@ -59,6 +66,8 @@ type Options struct {
 	flowCollectorAddr string
 	// IPFIX flow collector protocol
 	flowCollectorProto string
+	// Service of the sharded Flow Aggregator, nil if sharding is disabled
+	flowCollectorShardingService *types.NamespacedName
 	// Flow exporter poll interval
 	pollInterval time.Duration
 	// Active flow timeout to export records of active flows
@@ -167,6 +176,12 @@ func (o *Options) validate(args []string) error {
 	if err := o.validateAntreaIPAMConfig(); err != nil {
 		return fmt.Errorf("failed to validate AntreaIPAM config: %v", err)
 	}
+	if err := o.validateAuditLoggingConfig(); err != nil {
+		return fmt.Errorf("failed to validate audit logging config: %v", err)
+	}
+	if o.config.NetworkPolicyMetrics.MaxRules < 0 {
+		return fmt.Errorf("networkPolicyMetrics maxRules %d is invalid, it should be positive", o.config.NetworkPolicyMetrics.MaxRules)
+	}
 	return nil
 }
 
@@ -241,6 +256,19 @@ func (o *Options) setDefaults() {
 		}
 	}
 
+	if o.config.AuditLogging.Format == "" {
+		o.config.AuditLogging.Format = networkpolicy.AuditLogFormatText
+	}
+	if o.config.AuditLogging.Forwarding.Transport == "" {
+		o.config.AuditLogging.Forwarding.Transport = defaultAuditLogTransport
+	}
+	if o.config.AuditLogging.Forwarding.BufferSize == 0 {
+		o.config.AuditLogging.Forwarding.BufferSize = defaultAuditLogBufferSize
+	}
+	if o.config.NetworkPolicyMetrics.MaxRules == 0 {
+		o.config.NetworkPolicyMetrics.MaxRules = defaultNetworkPolicyMetricsMaxRules
+	}
+
 	if features.DefaultFeatureGate.Enabled(features.NodePortLocal) {
 		switch {
 		case o.config.NodePortLocal.PortRange != "":
@@ -268,6 +296,21 @@ func (o *Options) validateAntreaProxyConfig() error {
 			klog.InfoS("skipServices will be ignored because AntreaProxy is disabled", "skipServices", o.config.AntreaProxy.SkipServices)
 		}
 	}
+	if features.DefaultFeatureGate.Enabled(features.TopologyAwareHints) && !features.DefaultFeatureGate.Enabled(features.EndpointSlice) {
+		klog.InfoS("TopologyAwareHints will be ignored because EndpointSlice is disabled")
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR) {
+		if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) || !o.config.AntreaProxy.ProxyAll {
+			return fmt.Errorf("LoadBalancerModeDSR requires AntreaProxy and proxyAll to be enabled")
+		}
+		if strings.EqualFold(o.config.TrafficEncapMode, config.TrafficEncapModeNetworkPolicyOnly.String()) {
+			return fmt.Errorf("LoadBalancerModeDSR is not supported with TrafficEncapMode %s", o.config.TrafficEncapMode)
+		}
+		if _, encryptionMode := config.GetTrafficEncryptionModeFromStr(o.config.TrafficEncryptionMode); encryptionMode != config.TrafficEncryptionModeNone || o.config.EnableIPSecTunnel {
+			return fmt.Errorf("LoadBalancerModeDSR is not supported with traffic encryption")
+		}
+	}
 
 	if o.config.AntreaProxy.ProxyAll {
 		for _, nodePortAddress := range o.config.AntreaProxy.NodePortAddresses {
@@ -287,6 +330,13 @@ func (o *Options) validateFlowExporterConfig() error {
 		}
 		o.flowCollectorAddr = net.JoinHostPort(host, port)
 		o.flowCollectorProto = proto
+		if o.config.FlowCollectorShardingService != "" {
+			namespace, name, err := cache.SplitMetaNamespaceKey(o.config.FlowCollectorShardingService)
+			if err != nil || namespace == "" || name == "" {
+				return fmt.Errorf("FlowCollectorShardingService %s is invalid, it should be <Namespace>/<Name>", o.config.FlowCollectorShardingService)
+			}
+			o.flowCollectorShardingService = &types.NamespacedName{Namespace: namespace, Name: name}
+		}
 
 		// Parse the given flowPollInterval config
 		if o.config.FlowPollInterval != "" {
@@ -331,6 +381,26 @@ func (o *Options) validateFlowExporterConfig() error {
 	return nil
 }
 
+func (o *Options) validateAuditLoggingConfig() error {
+	auditLogging := &o.config.AuditLogging
+	if auditLogging.Format != networkpolicy.AuditLogFormatText && auditLogging.Format != networkpolicy.AuditLogFormatJSON {
+		return fmt.Errorf("format %s is invalid, it should be %s or %s", auditLogging.Format, networkpolicy.AuditLogFormatText, networkpolicy.AuditLogFormatJSON)
+	}
+	if auditLogging.Forwarding.Address == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(auditLogging.Forwarding.Address); err != nil {
+		return fmt.Errorf("forwarding address %s is invalid, it should be a host:port pair: %v", auditLogging.Forwarding.Address, err)
+	}
+	if auditLogging.Forwarding.Transport != "tcp" && auditLogging.Forwarding.Transport != "udp" {
+		return fmt.Errorf("forwarding transport %s is invalid, it should be tcp or udp", auditLogging.Forwarding.Transport)
+	}
+	if auditLogging.Forwarding.BufferSize < 0 {
+		return fmt.Errorf("forwarding bufferSize %d is invalid, it should be positive", auditLogging.Forwarding.BufferSize)
+	}
+	return nil
+}
+
 func (o *Options) validateAntreaIPAMConfig() error {
 	if features.DefaultFeatureGate.Enabled(features.AntreaIPAM) {
 		// AntreaIPAM will bridge uplink to OVS bridge, which is not compatible with OVSDatapathSystem 'netdev'
@@ -17,7 +17,23 @@
 
 package main
 
+import "os"
+
+// reloadableAgentConfigFile is the antrea-agent configuration file in the Antrea ConfigMap
+// volume mounted without subPath, which is updated by the kubelet when the ConfigMap is
+// edited, unlike the configuration file mounted with subPath.
+const reloadableAgentConfigFile = "/etc/antrea/config/antrea-agent.conf"
+
 func (o *Options) checkUnsupportedFeatures() error {
 	// All features are supported on a Linux Node.
 	return nil
 }
+
+// getReloadableConfigFile returns the configuration file which is updated when the Antrea
+// ConfigMap is edited, or an empty string if it is not mounted.
+func (o *Options) getReloadableConfigFile() string {
+	if _, err := os.Stat(reloadableAgentConfigFile); err != nil {
+		return ""
+	}
+	return reloadableAgentConfigFile
+}
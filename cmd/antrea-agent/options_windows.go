@@ -62,3 +62,9 @@ func (o *Options) checkUnsupportedFeatures() error {
 	}
 	return nil
 }
+
+// getReloadableConfigFile returns the configuration file which is updated when the Antrea
+// ConfigMap is edited. The whole ConfigMap volume is mounted on Windows Nodes.
+func (o *Options) getReloadableConfigFile() string {
+	return o.configFile
+}
@@ -48,6 +48,7 @@ import (
 	"antrea.io/antrea/pkg/controller/networkpolicy"
 	"antrea.io/antrea/pkg/controller/networkpolicy/store"
 	"antrea.io/antrea/pkg/controller/querier"
+	"antrea.io/antrea/pkg/controller/serviceexternalip"
 	"antrea.io/antrea/pkg/controller/stats"
 	"antrea.io/antrea/pkg/controller/traceflow"
 	"antrea.io/antrea/pkg/features"
@@ -58,6 +59,7 @@ import (
 	"antrea.io/antrea/pkg/util/cipher"
 	"antrea.io/antrea/pkg/util/env"
 	"antrea.io/antrea/pkg/util/k8s"
+	"antrea.io/antrea/pkg/util/rulemetrics"
 	"antrea.io/antrea/pkg/version"
 	"antrea.io/antrea/third_party/ipam/nodeipam"
 	"antrea.io/antrea/third_party/ipam/nodeipam/ipam"
@@ -234,13 +236,21 @@ func run(o *Options) error {
 
 	var egressController *egress.EgressController
 	var externalIPPoolController *externalippool.ExternalIPPoolController
-	if features.DefaultFeatureGate.Enabled(features.Egress) {
+	// The IPs of both Egresses and Services can be allocated from ExternalIPPools.
+	if features.DefaultFeatureGate.Enabled(features.Egress) || features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
 		externalIPPoolController = externalippool.NewExternalIPPoolController(
 			crdClient, externalIPPoolInformer,
 		)
+	}
+	if features.DefaultFeatureGate.Enabled(features.Egress) {
 		egressController = egress.NewEgressController(crdClient, groupEntityIndex, egressInformer, externalIPPoolController, egressGroupStore)
 	}
 
+	var serviceExternalIPController *serviceexternalip.ServiceExternalIPController
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		serviceExternalIPController = serviceexternalip.NewServiceExternalIPController(client, serviceInformer, externalIPPoolController)
+	}
+
 	var traceflowController *traceflow.Controller
 	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
 		traceflowController = traceflow.NewTraceflowController(crdClient, podInformer, tfInformer)
@@ -262,7 +272,13 @@ func run(o *Options) error {
 	// aggregated data. For now it's only used for NetworkPolicy stats.
 	var statsAggregator *stats.Aggregator
 	if features.DefaultFeatureGate.Enabled(features.NetworkPolicyStats) {
-		statsAggregator = stats.NewAggregator(networkPolicyInformer, cnpInformer, anpInformer)
+		// ruleMetricsBudget is left nil when the NetworkPolicy rule metrics are disabled.
+		var ruleMetricsBudget *rulemetrics.Budget
+		if *o.config.EnablePrometheusMetrics && o.config.NetworkPolicyMetrics.Enable {
+			metricsConfig := o.config.NetworkPolicyMetrics
+			ruleMetricsBudget = rulemetrics.NewBudget(metricsConfig.MaxRules, metricsConfig.Tiers, metricsConfig.Namespaces)
+		}
+		statsAggregator = stats.NewAggregator(networkPolicyInformer, cnpInformer, anpInformer, ruleMetricsBudget)
 	}
 
 	cipherSuites, err := cipher.GenerateCipherSuitesList(o.config.TLSCipherSuites)
@@ -374,11 +390,18 @@ func run(o *Options) error {
 		}
 	}
 
-	if features.DefaultFeatureGate.Enabled(features.Egress) {
+	if features.DefaultFeatureGate.Enabled(features.Egress) || features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
 		go externalIPPoolController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.Egress) {
 		go egressController.Run(stopCh)
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.ServiceExternalIP) {
+		go serviceExternalIPController.Run(stopCh)
+	}
+
 	<-stopCh
 	klog.Info("Stopping Antrea controller")
 	return nil
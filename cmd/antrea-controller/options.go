@@ -36,6 +36,8 @@ const (
 	ipamIPv6MaskLo      = 64
 	ipamIPv6MaskHi      = 126
 	ipamIPv6MaskDefault = 64
+
+	defaultNetworkPolicyMetricsMaxRules = 500
 )
 
 type Options struct {
@@ -79,6 +81,9 @@ func (o *Options) validate(args []string) error {
 			return err
 		}
 	}
+	if o.config.NetworkPolicyMetrics.MaxRules < 0 {
+		return fmt.Errorf("networkPolicyMetrics maxRules %d is invalid, it should be positive", o.config.NetworkPolicyMetrics.MaxRules)
+	}
 	return nil
 }
 
@@ -172,4 +177,8 @@ func (o *Options) setDefaults() {
 	if o.config.NodeIPAM.NodeCIDRMaskSizeIPv6 == 0 {
 		o.config.NodeIPAM.NodeCIDRMaskSizeIPv6 = ipamIPv6MaskDefault
 	}
+
+	if o.config.NetworkPolicyMetrics.MaxRules == 0 {
+		o.config.NetworkPolicyMetrics.MaxRules = defaultNetworkPolicyMetricsMaxRules
+	}
 }
@@ -17,10 +17,14 @@ package main
 import (
 	"fmt"
 	"hash/fnv"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -30,6 +34,12 @@ import (
 	"antrea.io/antrea/pkg/clusteridentity"
 	aggregator "antrea.io/antrea/pkg/flowaggregator"
 	"antrea.io/antrea/pkg/flowaggregator/apiserver"
+	"antrea.io/antrea/pkg/flowaggregator/exporter"
+	"antrea.io/antrea/pkg/flowaggregator/flowquery"
+	"antrea.io/antrea/pkg/flowaggregator/infoelements"
+	"antrea.io/antrea/pkg/flowaggregator/policyrecommendation"
+	"antrea.io/antrea/pkg/flowaggregator/sharding"
+	"antrea.io/antrea/pkg/ipfix"
 	"antrea.io/antrea/pkg/log"
 	"antrea.io/antrea/pkg/signals"
 	"antrea.io/antrea/pkg/util/cipher"
@@ -37,6 +47,16 @@ import (
 
 const informerDefaultResync = 12 * time.Hour
 
+const (
+	// Environment variables from which the ClickHouse credentials are read. They are
+	// populated from the clickhouse-secret Secret when it exists.
+	clickHouseUsernameEnvKey = "CH_USERNAME"
+	clickHousePasswordEnvKey = "CH_PASSWORD"
+	// Environment variable from which the Pod IP is read, required when sharding is
+	// enabled to identify this replica among the endpoints of the Service.
+	podIPEnvKey = "POD_IP"
+)
+
 // genObservationDomainID generates an IPFIX Observation Domain ID when one is not provided by the
 // user through the flow aggregator configuration. It will first try to generate one
 // deterministically based on the cluster UUID (if available, with a timeout of 10s). Otherwise, it
@@ -99,25 +119,59 @@ func run(o *Options) error {
 	}
 	klog.Infof("Flow aggregator Observation Domain ID: %d", observationDomainID)
 
-	var sendJSONRecord bool
-	if o.format == "JSON" {
-		sendJSONRecord = true
-	} else {
-		sendJSONRecord = false
+	registry := ipfix.NewIPFIXRegistry()
+	registry.LoadRegistry()
+	if err := checkRecordContents(o, registry); err != nil {
+		return err
+	}
+
+	exporters, err := createExporters(o, observationDomainID, registry)
+	if err != nil {
+		return err
+	}
+
+	var sharder *sharding.Sharder
+	var podIP string
+	if o.shardingService != nil {
+		podIP = os.Getenv(podIPEnvKey)
+		if podIP == "" {
+			return fmt.Errorf("environment variable %s must be set when sharding is enabled", podIPEnvKey)
+		}
+		// Only the Endpoints of the flow aggregator Service are watched.
+		service := *o.shardingService
+		shardingInformerFactory := informers.NewSharedInformerFactoryWithOptions(k8sClient, informerDefaultResync,
+			informers.WithNamespace(service.Namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", service.Name).String()
+			}))
+		sharder = sharding.NewSharder(service.Namespace, service.Name, shardingInformerFactory.Core().V1().Endpoints().Lister())
+		shardingInformerFactory.Start(stopCh)
+		klog.InfoS("Sharding is enabled", "service", service, "podIP", podIP)
+	}
+
+	var flowStore *flowquery.Store
+	if o.flowQueryWindow > 0 {
+		flowStore = flowquery.NewStore(o.flowQueryWindow, o.flowQueryMaxRecords)
+	}
+	var recommender *policyrecommendation.Recommender
+	if o.recommendationWindow > 0 {
+		recommender = policyrecommendation.NewRecommender(o.recommendationWindow, o.recommendationMaxConns)
 	}
 
 	flowAggregator := aggregator.NewFlowAggregator(
-		o.externalFlowCollectorAddr,
-		o.externalFlowCollectorProto,
 		o.activeFlowRecordTimeout,
 		o.inactiveFlowRecordTimeout,
 		o.aggregatorTransportProtocol,
 		o.flowAggregatorAddress,
 		o.includePodLabels,
 		k8sClient,
-		observationDomainID,
 		podInformer,
-		sendJSONRecord,
+		registry,
+		exporters,
+		sharder,
+		podIP,
+		flowStore,
+		recommender,
 	)
 	err = flowAggregator.InitCollectingProcess()
 	if err != nil {
@@ -153,6 +207,74 @@ func run(o *Options) error {
 	return nil
 }
 
+// checkRecordContents checks that the optional Information Elements enabled in
+// recordContents are known to the IPFIX registry, as they cannot be exported otherwise.
+func checkRecordContents(o *Options, registry ipfix.IPFIXRegistry) error {
+	var elements []string
+	if o.includeEgressInfo {
+		elements = append(elements, infoelements.AntreaEgressElementList...)
+	}
+	if o.includeServiceType {
+		elements = append(elements, infoelements.AntreaServiceTypeElementList...)
+	}
+	if o.includeTCPMetrics {
+		elements = append(elements, infoelements.AntreaTCPMetricsElementList...)
+	}
+	for _, ie := range elements {
+		if _, err := registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID); err != nil {
+			return fmt.Errorf("information element %s enabled in recordContents is not supported by the IPFIX registry: %v", ie, err)
+		}
+	}
+	return nil
+}
+
+// createExporters creates one exporter for each sink enabled in the flow aggregator
+// configuration. All exporters receive the same aggregated flow records.
+func createExporters(o *Options, observationDomainID uint32, registry ipfix.IPFIXRegistry) ([]exporter.Interface, error) {
+	var exporters []exporter.Interface
+	if o.externalFlowCollectorAddr != "" {
+		// Connecting to the IPFIX collector is retried at most once per active flow
+		// record timeout, as it was before exporters were introduced.
+		exporters = append(exporters, exporter.NewIPFIXExporter(
+			o.externalFlowCollectorAddr,
+			o.externalFlowCollectorProto,
+			o.format == "JSON",
+			o.includePodLabels,
+			o.includeEgressInfo,
+			o.includeServiceType,
+			o.includeTCPMetrics,
+			observationDomainID,
+			o.activeFlowRecordTimeout,
+			registry,
+		))
+	}
+	if o.kafkaInput != nil {
+		kafkaExporter, err := exporter.NewKafkaExporter(*o.kafkaInput)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating Kafka exporter: %v", err)
+		}
+		exporters = append(exporters, kafkaExporter)
+	}
+	if o.clickHouseInput != nil {
+		input := *o.clickHouseInput
+		input.Username = os.Getenv(clickHouseUsernameEnvKey)
+		input.Password = os.Getenv(clickHousePasswordEnvKey)
+		clickHouseExporter, err := exporter.NewClickHouseExporter(input)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating ClickHouse exporter: %v", err)
+		}
+		exporters = append(exporters, clickHouseExporter)
+	}
+	if o.fileInput != nil {
+		fileExporter, err := exporter.NewFileExporter(*o.fileInput)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating file exporter: %v", err)
+		}
+		exporters = append(exporters, fileExporter)
+	}
+	return exporters, nil
+}
+
 func createK8sClient() (kubernetes.Interface, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
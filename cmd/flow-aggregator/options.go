@@ -23,10 +23,13 @@ import (
 
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 
 	"antrea.io/antrea/pkg/apis"
 	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
 	"antrea.io/antrea/pkg/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/exporter"
 	"antrea.io/antrea/pkg/util/flowexport"
 )
 
@@ -38,6 +41,26 @@ const (
 	defaultAggregatorTransportProtocol    = flowaggregator.AggregatorTransportProtocolTLS
 	defaultFlowAggregatorAddress          = "flow-aggregator.flow-aggregator.svc"
 	defaultRecordFormat                   = "IPFIX"
+	defaultShardingService                = "flow-aggregator/flow-aggregator"
+
+	defaultExporterBufferSize      = 10000
+	defaultExporterFlushInterval   = 1 * time.Second
+	defaultExporterMaxRetries      = 5
+	defaultKafkaTopic              = "flows"
+	defaultKafkaFormat             = exporter.KafkaFormatProtobuf
+	defaultKafkaVersion            = "2.0.0"
+	defaultClickHouseDatabase      = "default"
+	defaultClickHouseDatabaseURL   = "tcp://clickhouse-clickhouse.flow-visibility.svc:9000"
+	defaultClickHouseFlushInterval = 8 * time.Second
+	defaultFilePath                = "/var/log/antrea/flow-aggregator/flows.log"
+	defaultFileFormat              = exporter.FileFormatCSV
+	defaultFileMaxSize             = 100
+	defaultFileMaxBackups          = 3
+	defaultFileMaxAge              = 28
+	defaultFlowQueryWindow         = 15 * time.Minute
+	defaultFlowQueryMaxRecords     = 100000
+	defaultRecommendationWindow    = 24 * time.Hour
+	defaultRecommendationMaxConns  = 100000
 )
 
 type Options struct {
@@ -45,7 +68,7 @@ type Options struct {
 	configFile string
 	// The configuration object
 	config *flowaggregatorconfig.FlowAggregatorConfig
-	// IPFIX flow collector address
+	// IPFIX flow collector address, records are not exported to an IPFIX collector if empty
 	externalFlowCollectorAddr string
 	// IPFIX flow collector transport protocol
 	externalFlowCollectorProto string
@@ -61,6 +84,24 @@ type Options struct {
 	format string
 	// includePodLabels indicates whether source and destination Pod labels are included or not
 	includePodLabels bool
+	// includeEgressInfo indicates whether the Egress name and IP are included or not
+	includeEgressInfo bool
+	// includeServiceType indicates whether the destination Service type is included or not
+	includeServiceType bool
+	// includeTCPMetrics indicates whether the TCP metrics of the connections are included or not
+	includeTCPMetrics bool
+	// Inputs of the Kafka, ClickHouse and file exporters, nil when the exporter is disabled
+	kafkaInput      *exporter.KafkaInput
+	clickHouseInput *exporter.ClickHouseInput
+	fileInput       *exporter.FileInput
+	// Service of the flow aggregator when sharding is enabled, nil otherwise
+	shardingService *types.NamespacedName
+	// Window and maximum number of records of the flows API, disabled if the window is 0
+	flowQueryWindow     time.Duration
+	flowQueryMaxRecords int
+	// Window and maximum number of connections of the policy recommendation API, disabled if the window is 0
+	recommendationWindow   time.Duration
+	recommendationMaxConns int
 }
 
 func newOptions() *Options {
@@ -91,15 +132,18 @@ func (o *Options) validate(args []string) error {
 	if len(args) != 0 {
 		return errors.New("no positional arguments are supported")
 	}
-	if o.config.ExternalFlowCollectorAddr == "" {
-		return fmt.Errorf("IPFIX flow collector address should be provided")
+	if o.config.ExternalFlowCollectorAddr == "" && !o.config.Kafka.Enable && !o.config.ClickHouse.Enable && !o.config.File.Enable {
+		return fmt.Errorf("IPFIX flow collector address should be provided when no other exporter is enabled")
 	}
-	host, port, proto, err := flowexport.ParseFlowCollectorAddr(o.config.ExternalFlowCollectorAddr, defaultExternalFlowCollectorPort, defaultExternalFlowCollectorTransport)
-	if err != nil {
-		return err
+	var err error
+	if o.config.ExternalFlowCollectorAddr != "" {
+		host, port, proto, err := flowexport.ParseFlowCollectorAddr(o.config.ExternalFlowCollectorAddr, defaultExternalFlowCollectorPort, defaultExternalFlowCollectorTransport)
+		if err != nil {
+			return err
+		}
+		o.externalFlowCollectorAddr = net.JoinHostPort(host, port)
+		o.externalFlowCollectorProto = proto
 	}
-	o.externalFlowCollectorAddr = net.JoinHostPort(host, port)
-	o.externalFlowCollectorProto = proto
 	if o.config.ActiveFlowRecordTimeout == "" {
 		o.activeFlowRecordTimeout = defaultActiveFlowRecordTimeout
 	} else {
@@ -138,9 +182,224 @@ func (o *Options) validate(args []string) error {
 		o.format = o.config.RecordFormat
 	}
 	o.includePodLabels = o.config.RecordContents.PodLabels
+	o.includeEgressInfo = o.config.RecordContents.EgressInfo
+	o.includeServiceType = o.config.RecordContents.ServiceType
+	o.includeTCPMetrics = o.config.RecordContents.TCPMetrics
 	if o.config.APIServer.APIPort == 0 {
 		o.config.APIServer.APIPort = apis.FlowAggregatorAPIPort
 	}
+	if o.config.Kafka.Enable {
+		if err := o.validateKafkaConfig(); err != nil {
+			return err
+		}
+	}
+	if o.config.ClickHouse.Enable {
+		if err := o.validateClickHouseConfig(); err != nil {
+			return err
+		}
+	}
+	if o.config.File.Enable {
+		if err := o.validateFileConfig(); err != nil {
+			return err
+		}
+	}
+	if o.config.Sharding.Enable {
+		service := o.config.Sharding.Service
+		if service == "" {
+			service = defaultShardingService
+		}
+		namespace, name, err := cache.SplitMetaNamespaceKey(service)
+		if err != nil || namespace == "" || name == "" {
+			return fmt.Errorf("sharding Service %s is invalid, it should be <Namespace>/<Name>", service)
+		}
+		o.shardingService = &types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	if err := o.validateFlowQueryConfig(); err != nil {
+		return err
+	}
+	if err := o.validatePolicyRecommendationConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *Options) validateFlowQueryConfig() error {
+	c := &o.config.FlowQuery
+	o.flowQueryWindow = defaultFlowQueryWindow
+	o.flowQueryMaxRecords = defaultFlowQueryMaxRecords
+	if c.Window != "" {
+		window, err := time.ParseDuration(c.Window)
+		if err != nil {
+			return fmt.Errorf("flowQuery window is not provided in right format: %v", err)
+		}
+		if window < 0 {
+			return fmt.Errorf("flowQuery window must not be negative")
+		}
+		o.flowQueryWindow = window
+	}
+	if c.MaxRecords < 0 {
+		return fmt.Errorf("flowQuery maxRecords must not be negative")
+	}
+	if c.MaxRecords != 0 {
+		o.flowQueryMaxRecords = c.MaxRecords
+	}
+	return nil
+}
+
+func (o *Options) validatePolicyRecommendationConfig() error {
+	c := &o.config.PolicyRecommendation
+	if !c.Enable {
+		return nil
+	}
+	if !o.includePodLabels {
+		return fmt.Errorf("policyRecommendation requires recordContents.podLabels to be enabled")
+	}
+	o.recommendationWindow = defaultRecommendationWindow
+	o.recommendationMaxConns = defaultRecommendationMaxConns
+	if c.Window != "" {
+		window, err := time.ParseDuration(c.Window)
+		if err != nil {
+			return fmt.Errorf("policyRecommendation window is not provided in right format: %v", err)
+		}
+		if window <= 0 {
+			return fmt.Errorf("policyRecommendation window must be positive")
+		}
+		o.recommendationWindow = window
+	}
+	if c.MaxConnections < 0 {
+		return fmt.Errorf("policyRecommendation maxConnections must not be negative")
+	}
+	if c.MaxConnections != 0 {
+		o.recommendationMaxConns = c.MaxConnections
+	}
+	return nil
+}
+
+func parseBufferConfig(c *flowaggregatorconfig.ExporterBufferConfig, defaultFlushInterval time.Duration) (exporter.BufferOptions, error) {
+	options := exporter.BufferOptions{
+		Size:          defaultExporterBufferSize,
+		FlushInterval: defaultFlushInterval,
+		MaxRetries:    defaultExporterMaxRetries,
+	}
+	if c.BufferSize < 0 || c.MaxRetries < 0 {
+		return options, fmt.Errorf("bufferSize and maxRetries must not be negative")
+	}
+	if c.BufferSize != 0 {
+		options.Size = c.BufferSize
+	}
+	if c.MaxRetries != 0 {
+		options.MaxRetries = c.MaxRetries
+	}
+	if c.FlushInterval != "" {
+		flushInterval, err := time.ParseDuration(c.FlushInterval)
+		if err != nil {
+			return options, err
+		}
+		if flushInterval <= 0 {
+			return options, fmt.Errorf("flushInterval must be positive")
+		}
+		options.FlushInterval = flushInterval
+	}
+	return options, nil
+}
+
+func (o *Options) validateKafkaConfig() error {
+	c := &o.config.Kafka
+	if len(c.Brokers) == 0 {
+		return fmt.Errorf("at least one Kafka broker should be provided when the Kafka exporter is enabled")
+	}
+	buffer, err := parseBufferConfig(&c.ExporterBufferConfig, defaultExporterFlushInterval)
+	if err != nil {
+		return fmt.Errorf("invalid Kafka exporter configuration: %v", err)
+	}
+	input := &exporter.KafkaInput{
+		Brokers:            c.Brokers,
+		Topic:              c.Topic,
+		Format:             c.Format,
+		Version:            c.Version,
+		CAFile:             c.TLSCAFile,
+		CertFile:           c.TLSCertFile,
+		KeyFile:            c.TLSKeyFile,
+		InsecureSkipVerify: c.TLSInsecureSkipVerify,
+		Buffer:             buffer,
+	}
+	if input.Topic == "" {
+		input.Topic = defaultKafkaTopic
+	}
+	if input.Format == "" {
+		input.Format = defaultKafkaFormat
+	} else if input.Format != exporter.KafkaFormatProtobuf && input.Format != exporter.KafkaFormatJSON {
+		return fmt.Errorf("Kafka format %s is not supported", input.Format)
+	}
+	if input.Version == "" {
+		input.Version = defaultKafkaVersion
+	}
+	o.kafkaInput = input
+	return nil
+}
+
+func (o *Options) validateClickHouseConfig() error {
+	c := &o.config.ClickHouse
+	buffer, err := parseBufferConfig(&c.ExporterBufferConfig, defaultClickHouseFlushInterval)
+	if err != nil {
+		return fmt.Errorf("invalid ClickHouse exporter configuration: %v", err)
+	}
+	input := &exporter.ClickHouseInput{
+		Database:    c.Database,
+		DatabaseURL: c.DatabaseURL,
+		Debug:       c.Debug,
+		Compress:    true,
+		Buffer:      buffer,
+	}
+	if input.Database == "" {
+		input.Database = defaultClickHouseDatabase
+	}
+	if input.DatabaseURL == "" {
+		input.DatabaseURL = defaultClickHouseDatabaseURL
+	}
+	if c.Compress != nil {
+		input.Compress = *c.Compress
+	}
+	o.clickHouseInput = input
+	return nil
+}
+
+func (o *Options) validateFileConfig() error {
+	c := &o.config.File
+	buffer, err := parseBufferConfig(&c.ExporterBufferConfig, defaultExporterFlushInterval)
+	if err != nil {
+		return fmt.Errorf("invalid file exporter configuration: %v", err)
+	}
+	input := &exporter.FileInput{
+		Path:       c.Path,
+		Format:     c.Format,
+		MaxSize:    c.MaxSize,
+		MaxBackups: c.MaxBackups,
+		MaxAge:     c.MaxAge,
+		Compress:   true,
+		Buffer:     buffer,
+	}
+	if input.Path == "" {
+		input.Path = defaultFilePath
+	}
+	if input.Format == "" {
+		input.Format = defaultFileFormat
+	} else if input.Format != exporter.FileFormatCSV && input.Format != exporter.FileFormatJSON {
+		return fmt.Errorf("file format %s is not supported", input.Format)
+	}
+	if input.MaxSize == 0 {
+		input.MaxSize = defaultFileMaxSize
+	}
+	if input.MaxBackups == 0 {
+		input.MaxBackups = defaultFileMaxBackups
+	}
+	if input.MaxAge == 0 {
+		input.MaxAge = defaultFileMaxAge
+	}
+	if c.Compress != nil {
+		input.Compress = *c.Compress
+	}
+	o.fileInput = input
 	return nil
 }
 
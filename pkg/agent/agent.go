@@ -455,7 +455,7 @@ func (i *Initializer) initOpenFlowPipeline() error {
 		return err
 	}
 
-	if i.networkConfig.TrafficEncapMode.SupportsEncap() {
+	if i.needsDefaultTunnel() {
 		// Set up flow entries for the default tunnel port interface.
 		if err := i.ofClient.InstallDefaultTunnelFlows(); err != nil {
 			klog.Errorf("Failed to setup openflow entries for tunnel interface: %v", err)
@@ -691,6 +691,17 @@ func (i *Initializer) configureGatewayInterface(gatewayIface *interfacestore.Int
 	return nil
 }
 
+// needsDefaultTunnel returns whether the default tunnel port is needed. Besides the cross-Node traffic when the traffic
+// encap mode supports encap, it's used to forward the LoadBalancer traffic to remote Endpoints in DSR mode, which is
+// not supported in networkPolicyOnly mode.
+func (i *Initializer) needsDefaultTunnel() bool {
+	if i.networkConfig.TrafficEncapMode.SupportsEncap() {
+		return true
+	}
+	return i.enableProxy && i.proxyAll && !i.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() &&
+		features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR)
+}
+
 func (i *Initializer) setupDefaultTunnelInterface() error {
 	tunnelPortName := i.nodeConfig.DefaultTunName
 	tunnelIface, portExists := i.ifaceStore.GetInterface(tunnelPortName)
@@ -706,7 +717,7 @@ func (i *Initializer) setupDefaultTunnelInterface() error {
 
 	// Check the default tunnel port.
 	if portExists {
-		if i.networkConfig.TrafficEncapMode.SupportsEncap() &&
+		if i.needsDefaultTunnel() &&
 			tunnelIface.TunnelInterfaceConfig.Type == i.networkConfig.TunnelType &&
 			tunnelIface.TunnelInterfaceConfig.LocalIP.Equal(localIP) {
 			klog.V(2).Infof("Tunnel port %s already exists on OVS bridge", tunnelPortName)
@@ -721,7 +732,7 @@ func (i *Initializer) setupDefaultTunnelInterface() error {
 		}
 
 		if err := i.ovsBridgeClient.DeletePort(tunnelIface.PortUUID); err != nil {
-			if i.networkConfig.TrafficEncapMode.SupportsEncap() {
+			if i.needsDefaultTunnel() {
 				return fmt.Errorf("failed to remove tunnel port %s with wrong tunnel type: %s", tunnelPortName, err)
 			}
 			klog.Errorf("Failed to remove tunnel port %s in NoEncapMode: %v", tunnelPortName, err)
@@ -732,7 +743,7 @@ func (i *Initializer) setupDefaultTunnelInterface() error {
 	}
 
 	// Create the default tunnel port and interface.
-	if i.networkConfig.TrafficEncapMode.SupportsEncap() {
+	if i.needsDefaultTunnel() {
 		if tunnelPortName != defaultTunInterfaceName {
 			// Reset the tunnel interface name to the desired name before
 			// recreating the tunnel port and interface.
@@ -0,0 +1,94 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fqdncache
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"antrea.io/antrea/pkg/querier"
+)
+
+// Response is the response struct of the fqdncache command.
+type Response struct {
+	Entries []Entry `json:"entries"`
+	Stats   Stats   `json:"stats"`
+}
+
+// Entry describes a FQDN cached by the agent for FQDN policy rules.
+type Entry struct {
+	FQDN string   `json:"fqdn"`
+	IPs  []string `json:"ips,omitempty"`
+	// RemainingTTL is the number of seconds before the IPs expire. It is 0
+	// if the IPs have expired and the FQDN is being resolved again.
+	RemainingTTL int64    `json:"remainingTTL"`
+	RuleIDs      []string `json:"ruleIDs,omitempty"`
+	Policies     []string `json:"policies,omitempty"`
+}
+
+// Stats describes the counters of the DNS responses intercepted by the agent
+// and of the rules resynced because of them.
+type Stats struct {
+	DNSPacketIns  uint64 `json:"dnsPacketIns"`
+	ParseFailures uint64 `json:"parseFailures"`
+	RulesResynced uint64 `json:"rulesResynced"`
+}
+
+func generateResponse(entries []querier.FQDNCacheEntry, stats querier.FQDNStats, fqdn string, now time.Time) Response {
+	resp := Response{
+		Entries: []Entry{},
+		Stats: Stats{
+			DNSPacketIns:  stats.DNSPacketIns,
+			ParseFailures: stats.ParseFailures,
+			RulesResynced: stats.RulesResynced,
+		},
+	}
+	for _, e := range entries {
+		if fqdn != "" && e.FQDN != fqdn {
+			continue
+		}
+		entry := Entry{
+			FQDN:    e.FQDN,
+			RuleIDs: e.RuleIDs,
+		}
+		for _, ip := range e.IPs {
+			entry.IPs = append(entry.IPs, ip.String())
+		}
+		if remaining := e.ExpirationTime.Sub(now); remaining > 0 {
+			entry.RemainingTTL = int64(remaining.Seconds())
+		}
+		for _, policy := range e.Policies {
+			entry.Policies = append(entry.Policies, policy.ToString())
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return resp
+}
+
+// HandleFunc creates a http.HandlerFunc which uses an AgentNetworkPolicyInfoQuerier
+// to query the FQDNs cached for FQDN policy rules in current agent, along with
+// the counters of the DNS responses intercepted. The HandlerFunc accepts `fqdn`
+// parameter in URL and returns the specific FQDN only.
+func HandleFunc(npq querier.AgentNetworkPolicyInfoQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fqdn := strings.TrimSuffix(strings.ToLower(r.URL.Query().Get("fqdn")), ".")
+		resp := generateResponse(npq.GetFQDNCache(), npq.GetFQDNStats(), fqdn, time.Now())
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
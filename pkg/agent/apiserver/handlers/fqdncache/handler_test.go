@@ -0,0 +1,118 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fqdncache
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cpv1beta "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+	"antrea.io/antrea/pkg/querier"
+	queriertest "antrea.io/antrea/pkg/querier/testing"
+)
+
+var (
+	testPolicy = cpv1beta.NetworkPolicyReference{
+		Type: cpv1beta.AntreaClusterNetworkPolicy,
+		Name: "acnp-fqdn",
+	}
+	testStats = querier.FQDNStats{
+		DNSPacketIns:  10,
+		ParseFailures: 1,
+		RulesResynced: 3,
+	}
+)
+
+func TestGenerateResponse(t *testing.T) {
+	now := time.Now()
+	entries := []querier.FQDNCacheEntry{
+		{
+			FQDN:           "expired.antrea.io",
+			IPs:            []net.IP{net.ParseIP("1.1.1.1")},
+			ExpirationTime: now.Add(-time.Second),
+		},
+		{
+			FQDN:           "www.antrea.io",
+			IPs:            []net.IP{net.ParseIP("2.2.2.2"), net.ParseIP("2001::2")},
+			ExpirationTime: now.Add(30 * time.Second),
+			RuleIDs:        []string{"rule1"},
+			Policies:       []cpv1beta.NetworkPolicyReference{testPolicy},
+		},
+	}
+	expectedEntries := []Entry{
+		{
+			FQDN:         "expired.antrea.io",
+			IPs:          []string{"1.1.1.1"},
+			RemainingTTL: 0,
+		},
+		{
+			FQDN:         "www.antrea.io",
+			IPs:          []string{"2.2.2.2", "2001::2"},
+			RemainingTTL: 30,
+			RuleIDs:      []string{"rule1"},
+			Policies:     []string{"AntreaClusterNetworkPolicy:acnp-fqdn"},
+		},
+	}
+	expectedStats := Stats{DNSPacketIns: 10, ParseFailures: 1, RulesResynced: 3}
+
+	resp := generateResponse(entries, testStats, "", now)
+	assert.Equal(t, Response{Entries: expectedEntries, Stats: expectedStats}, resp)
+
+	resp = generateResponse(entries, testStats, "www.antrea.io", now)
+	assert.Equal(t, Response{Entries: expectedEntries[1:], Stats: expectedStats}, resp)
+
+	resp = generateResponse(entries, testStats, "unknown.antrea.io", now)
+	assert.Equal(t, Response{Entries: []Entry{}, Stats: expectedStats}, resp)
+}
+
+func TestFQDNCacheQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	npq := queriertest.NewMockAgentNetworkPolicyInfoQuerier(ctrl)
+	npq.EXPECT().GetFQDNCache().Return([]querier.FQDNCacheEntry{
+		{
+			FQDN:           "www.antrea.io",
+			IPs:            []net.IP{net.ParseIP("2.2.2.2")},
+			ExpirationTime: time.Now().Add(time.Hour),
+			RuleIDs:        []string{"rule1"},
+			Policies:       []cpv1beta.NetworkPolicyReference{testPolicy},
+		},
+	})
+	npq.EXPECT().GetFQDNStats().Return(testStats)
+
+	handler := HandleFunc(npq)
+	req, err := http.NewRequest(http.MethodGet, "?fqdn=WWW.antrea.io.", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var received Response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+	require.Len(t, received.Entries, 1)
+	assert.Equal(t, "www.antrea.io", received.Entries[0].FQDN)
+	assert.Equal(t, []string{"2.2.2.2"}, received.Entries[0].IPs)
+	assert.Equal(t, []string{"rule1"}, received.Entries[0].RuleIDs)
+	assert.Equal(t, []string{"AntreaClusterNetworkPolicy:acnp-fqdn"}, received.Entries[0].Policies)
+	assert.Equal(t, Stats{DNSPacketIns: 10, ParseFailures: 1, RulesResynced: 3}, received.Stats)
+}
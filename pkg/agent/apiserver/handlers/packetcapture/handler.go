@@ -0,0 +1,68 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/controller/packetcapture"
+)
+
+var (
+	// Use function variables for tests.
+	getCaptureDir = packetcapture.GetCaptureDir
+)
+
+// HandleFunc returns the function which can handle the requests issued by the
+// 'antctl packetcapture download' command. The handler function writes the
+// pcapng file of the PacketCapture specified by the "name" query parameter to
+// the response.
+func HandleFunc() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "PacketCapture name must be provided", http.StatusBadRequest)
+			return
+		}
+		// The name is used to build the file path, make sure it is a
+		// valid resource name.
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			http.Error(w, "invalid PacketCapture name: "+strings.Join(errs, ", "), http.StatusBadRequest)
+			return
+		}
+		f, err := os.Open(filepath.Join(getCaptureDir(), packetcapture.GetCaptureFileName(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.Error(w, "capture file of PacketCapture "+name+" not found", http.StatusNotFound)
+				return
+			}
+			klog.Errorf("Error when opening the capture file of PacketCapture %s: %v", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if _, err := io.Copy(w, f); err != nil {
+			klog.Errorf("Error when writing the capture file of PacketCapture %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketCaptureDownload(t *testing.T) {
+	captureDir := t.TempDir()
+	originalGetCaptureDir := getCaptureDir
+	getCaptureDir = func() string { return captureDir }
+	defer func() {
+		getCaptureDir = originalGetCaptureDir
+	}()
+	content := []byte("pcapng content")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(captureDir, "pc1.pcapng"), content, 0644))
+
+	testcases := map[string]struct {
+		query              string
+		expectedStatusCode int
+		expectedContent    []byte
+	}{
+		"Existing PacketCapture": {
+			query:              "?name=pc1",
+			expectedStatusCode: http.StatusOK,
+			expectedContent:    content,
+		},
+		"Non-existing PacketCapture": {
+			query:              "?name=pc2",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"No name": {
+			query:              "",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"Invalid name": {
+			query:              "?name=../pc1",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	handler := HandleFunc()
+	for k, tc := range testcases {
+		req, err := http.NewRequest(http.MethodGet, tc.query, nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, tc.expectedStatusCode, recorder.Code, k)
+		if tc.expectedContent != nil {
+			assert.Equal(t, tc.expectedContent, recorder.Body.Bytes(), k)
+		}
+	}
+}
@@ -17,13 +17,16 @@ package egress
 import (
 	"context"
 	"fmt"
+	"hash/crc32"
 	"net"
 	"reflect"
 	"strings"
 	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -40,14 +43,17 @@ import (
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/ipassigner"
 	"antrea.io/antrea/pkg/agent/memberlist"
+	agentmetrics "antrea.io/antrea/pkg/agent/metrics"
 	"antrea.io/antrea/pkg/agent/openflow"
 	"antrea.io/antrea/pkg/agent/route"
+	"antrea.io/antrea/pkg/agent/types"
 	cpv1b2 "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
 	clientsetversioned "antrea.io/antrea/pkg/client/clientset/versioned"
 	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha2"
 	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha2"
 	"antrea.io/antrea/pkg/controller/metrics"
+	egressutil "antrea.io/antrea/pkg/util/egress"
 	"antrea.io/antrea/pkg/util/k8s"
 )
 
@@ -70,20 +76,26 @@ const (
 
 	// egressDummyDevice is the dummy device that holds the Egress IPs configured to the system by antrea-agent.
 	egressDummyDevice = "antrea-egress0"
+
+	// snatMetricsInterval is the interval at which the SNAT metrics of Egresses are read from the OVS flow statistics.
+	snatMetricsInterval = 30 * time.Second
 )
 
 var emptyWatch = watch.NewEmptyWatch()
 
 // egressState keeps the actual state of an Egress that has been realized.
 type egressState struct {
-	// The actual egress IP of the Egress. If it's different from the desired IP, there is an update to EgressIP, and we
-	// need to remove previously installed flows.
-	egressIP string
-	// The actual datapath mark of this Egress. Used to check if the mark changes since last process.
-	mark uint32
-	// The actual openflow ports for which we have installed SNAT rules. Used to identify stale openflow ports when
-	// updating or deleting an Egress.
-	ofPorts sets.Int32
+	// The actual egress IPs of the Egress. If they're different from the desired IPs, there is an update to EgressIP
+	// or EgressIPs, and we need to remove previously installed flows.
+	egressIPs []string
+	// The actual datapath marks of the Egress IPs. Used to check if the mark of an IP changes since last process.
+	marks map[string]uint32
+	// The local Egress IPs whose bandwidth is limited. Used to check if the flows of the Pods using an IP need to be
+	// reinstalled to apply or remove the bandwidth meter.
+	meteredIPs sets.String
+	// The actual openflow ports for which we have installed SNAT rules, mapped to the Egress IPs used by them. Used to
+	// identify stale openflow ports when updating or deleting an Egress.
+	ofPorts map[int32]string
 	// The actual Pods of the Egress. Used to identify stale Pods when updating or deleting an Egress.
 	pods sets.String
 }
@@ -96,12 +108,43 @@ type egressIPState struct {
 	egressNames sets.String
 	// The datapath mark of this Egress IP. 0 if this is not a local IP.
 	mark uint32
+	// The bandwidth limits specified by the Egresses referring to it, keyed by the Egress names.
+	bandwidths map[string]*crdv1a2.Bandwidth
+	// The bandwidth limit enforced by the meter of this Egress IP. nil if the meter is not installed.
+	bandwidth *crdv1a2.Bandwidth
 	// Whether its flows have been installed.
 	flowsInstalled bool
 	// Whether its iptables rule has been installed.
 	ruleInstalled bool
 }
 
+// getEffectiveBandwidth returns the bandwidth limit that should be enforced for the Egress IP. If multiple Egresses
+// sharing the IP specify bandwidth limits, the one of the Egress with the smallest name is used, so that the result
+// doesn't depend on the order in which the Egresses are processed.
+func (s *egressIPState) getEffectiveBandwidth() *crdv1a2.Bandwidth {
+	var effectiveEgress string
+	var bandwidth *crdv1a2.Bandwidth
+	for egressName, b := range s.bandwidths {
+		if bandwidth == nil || egressName < effectiveEgress {
+			effectiveEgress, bandwidth = egressName, b
+		}
+	}
+	return bandwidth
+}
+
+// getBandwidthKbps returns the rate of the Bandwidth in kbps and the burst of it in kbits.
+func getBandwidthKbps(bandwidth *crdv1a2.Bandwidth) (uint32, uint32, error) {
+	rate, err := resource.ParseQuantity(bandwidth.Rate)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid bandwidth rate %s: %v", bandwidth.Rate, err)
+	}
+	burst, err := resource.ParseQuantity(bandwidth.Burst)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid bandwidth burst %s: %v", bandwidth.Burst, err)
+	}
+	return uint32(rate.Value() / 1000), uint32(burst.Value() / 1000), nil
+}
+
 // egressBinding keeps the Egresses applying to a Pod.
 // There is one effective Egress for a Pod at any given time.
 type egressBinding struct {
@@ -142,6 +185,10 @@ type EgressController struct {
 
 	cluster    *memberlist.Cluster
 	ipAssigner ipassigner.IPAssigner
+
+	// The SNAT statistics of the local Pods read last time, keyed by the ofPorts of the Pods. Only accessed by
+	// updateSNATMetrics.
+	snatMetrics map[uint32]*types.SNATMetric
 }
 
 func NewEgressController(
@@ -171,6 +218,7 @@ func NewEgressController(
 		egressGroups:         map[string]sets.String{},
 		egressStates:         map[string]*egressState{},
 		egressIPStates:       map[string]*egressIPState{},
+		snatMetrics:          map[uint32]*types.SNATMetric{},
 		egressBindings:       map[string]*egressBinding{},
 		localIPDetector:      localIPDetector,
 		idAllocator:          newIDAllocator(minEgressMark, maxEgressMark),
@@ -187,7 +235,7 @@ func NewEgressController(
 		if !ok {
 			return nil, fmt.Errorf("obj is not Egress: %+v", obj)
 		}
-		return []string{egress.Spec.EgressIP}, nil
+		return getEgressIPs(egress), nil
 	}})
 	// externalIPPoolIndex will be used to get all Egresses associated with a given ExternalIPPool.
 	c.egressInformer.AddIndexers(cache.Indexers{externalIPPoolIndex: func(obj interface{}) (strings []string, e error) {
@@ -213,10 +261,55 @@ func NewEgressController(
 	return c, nil
 }
 
+// getEgressIPs returns the Egress IPs specified in the Egress, either by EgressIPs or by EgressIP.
+func getEgressIPs(egress *crdv1a2.Egress) []string {
+	if len(egress.Spec.EgressIPs) > 0 {
+		return egress.Spec.EgressIPs
+	}
+	if egress.Spec.EgressIP != "" {
+		return []string{egress.Spec.EgressIP}
+	}
+	return nil
+}
+
+// selectEgressIP returns the Egress IP that the Pod's traffic should be SNAT'd to. The Pods are distributed across the
+// IPs by hashing their names, which doesn't depend on the Nodes the IPs are assigned to, so that a Pod keeps using the
+// same IP when the IP fails over to another Node.
+func selectEgressIP(pod string, egressIPs []string) string {
+	if len(egressIPs) == 1 {
+		return egressIPs[0]
+	}
+	return egressIPs[crc32.ChecksumIEEE([]byte(pod))%uint32(len(egressIPs))]
+}
+
+// getEgressIPNode returns the Node which holds the provided IP of the Egress according to the Egress's status.
+func getEgressIPNode(egress *crdv1a2.Egress, egressIP string) string {
+	if len(egress.Spec.EgressIPs) == 0 {
+		return egress.Status.EgressNode
+	}
+	for _, assignment := range egress.Status.EgressIPAssignments {
+		if assignment.EgressIP == egressIP {
+			return assignment.EgressNode
+		}
+	}
+	return ""
+}
+
+// isEgressStatusUpToDate returns whether the status of the Egress reports this Node as the owner of exactly the
+// Egress IPs assigned to this Node.
+func (c *EgressController) isEgressStatusUpToDate(egress *crdv1a2.Egress) bool {
+	for _, egressIP := range egress.Spec.EgressIPs {
+		if c.localIPDetector.IsLocalIP(egressIP) != (getEgressIPNode(egress, egressIP) == c.nodeName) {
+			return false
+		}
+	}
+	return true
+}
+
 // addEgress processes Egress ADD events.
 func (c *EgressController) addEgress(obj interface{}) {
 	egress := obj.(*crdv1a2.Egress)
-	if egress.Spec.EgressIP == "" {
+	if len(getEgressIPs(egress)) == 0 {
 		return
 	}
 	c.queue.Add(egress.Name)
@@ -228,8 +321,15 @@ func (c *EgressController) updateEgress(old, cur interface{}) {
 	oldEgress := old.(*crdv1a2.Egress)
 	curEgress := cur.(*crdv1a2.Egress)
 	// Ignore handling the Egress Status change if Egress IP already has been assigned on current node.
-	if curEgress.Status.EgressNode == c.nodeName && oldEgress.GetGeneration() == curEgress.GetGeneration() {
-		return
+	if oldEgress.GetGeneration() == curEgress.GetGeneration() {
+		if len(curEgress.Spec.EgressIPs) == 0 && curEgress.Status.EgressNode == c.nodeName {
+			return
+		}
+		// With multiple Egress IPs, the status is updated by all the Nodes holding the IPs, ignore the change if the
+		// status of the IPs assigned on current node is correct.
+		if len(curEgress.Spec.EgressIPs) > 0 && c.isEgressStatusUpToDate(curEgress) {
+			return
+		}
 	}
 	c.queue.Add(curEgress.Name)
 	klog.V(2).InfoS("Processed Egress UPDATE event", "egress", klog.KObj(curEgress))
@@ -300,12 +400,54 @@ func (c *EgressController) Run(stopCh <-chan struct{}) {
 
 	go wait.NonSlidingUntil(c.watchEgressGroup, 5*time.Second, stopCh)
 
+	// Reading the flow statistics is only needed when the Prometheus metrics are enabled.
+	if agentmetrics.EgressSNATPacketCount.IsCreated() {
+		go wait.Until(c.updateSNATMetrics, snatMetricsInterval, stopCh)
+	}
+
 	for i := 0; i < defaultWorkers; i++ {
 		go wait.Until(c.worker, time.Second, stopCh)
 	}
 	<-stopCh
 }
 
+// updateSNATMetrics reads the statistics of the SNAT flows of the local Pods and adds the packets and bytes SNAT'd
+// since last time to the metrics of the effective Egresses of the Pods.
+func (c *EgressController) updateSNATMetrics() {
+	podEgresses := func() map[string]string {
+		c.egressBindingsMutex.RLock()
+		defer c.egressBindingsMutex.RUnlock()
+		podEgresses := make(map[string]string, len(c.egressBindings))
+		for pod, binding := range c.egressBindings {
+			podEgresses[pod] = binding.effectiveEgress
+		}
+		return podEgresses
+	}()
+	ofPortEgresses := map[uint32]string{}
+	for _, iface := range c.ifaceStore.GetInterfacesByType(interfacestore.ContainerInterface) {
+		if egressName, exists := podEgresses[k8s.NamespacedName(iface.PodNamespace, iface.PodName)]; exists {
+			ofPortEgresses[uint32(iface.OFPort)] = egressName
+		}
+	}
+
+	snatMetrics := c.ofClient.PodSNATMetrics()
+	for ofPort, metric := range snatMetrics {
+		delta := *metric
+		// The statistics restart from 0 if the flows have been reinstalled since last time.
+		if prevMetric, exists := c.snatMetrics[ofPort]; exists && metric.Packets >= prevMetric.Packets && metric.Bytes >= prevMetric.Bytes {
+			delta.Packets -= prevMetric.Packets
+			delta.Bytes -= prevMetric.Bytes
+		}
+		egressName, exists := ofPortEgresses[ofPort]
+		if !exists {
+			continue
+		}
+		agentmetrics.EgressSNATPacketCount.WithLabelValues(egressName).Add(float64(delta.Packets))
+		agentmetrics.EgressSNATByteCount.WithLabelValues(egressName).Add(float64(delta.Bytes))
+	}
+	c.snatMetrics = snatMetrics
+}
+
 // removeStaleEgressIPs unassigns stale Egress IPs that shouldn't be present on this Node.
 // These Egresses were either deleted from the Kubernetes API or migrated to other Nodes when the agent on this Node
 // was not running.
@@ -313,8 +455,13 @@ func (c *EgressController) removeStaleEgressIPs() {
 	desiredLocalEgressIPs := sets.NewString()
 	egresses, _ := c.egressLister.List(labels.Everything())
 	for _, egress := range egresses {
-		if egress.Spec.EgressIP != "" && egress.Spec.ExternalIPPool != "" && egress.Status.EgressNode == c.nodeName {
-			desiredLocalEgressIPs.Insert(egress.Spec.EgressIP)
+		if egress.Spec.ExternalIPPool == "" {
+			continue
+		}
+		for _, egressIP := range getEgressIPs(egress) {
+			if getEgressIPNode(egress, egressIP) == c.nodeName {
+				desiredLocalEgressIPs.Insert(egressIP)
+			}
 		}
 	}
 	actualLocalEgressIPs := c.ipAssigner.AssignedIPs()
@@ -363,8 +510,9 @@ func (c *EgressController) processNextWorkItem() bool {
 // If it's called the first time for a local Egress IP, it allocates a locally-unique mark for the IP and installs flows
 // and iptables rule for this IP and the mark.
 // If the Egress IP is changed from local to non local, it uninstalls flows and iptables rule and releases the mark.
-// The method returns the mark on success. Non local Egresses use 0 as the mark.
-func (c *EgressController) realizeEgressIP(egressName, egressIP string) (uint32, error) {
+// The bandwidth of a local Egress IP is limited with a meter if any Egress referring to it specifies a limit.
+// The method returns the mark and whether the bandwidth is limited on success. Non local Egresses use 0 as the mark.
+func (c *EgressController) realizeEgressIP(egressName, egressIP string, bandwidth *crdv1a2.Bandwidth) (uint32, bool, error) {
 	isLocalIP := c.localIPDetector.IsLocalIP(egressIP)
 
 	c.egressIPStatesMutex.Lock()
@@ -376,11 +524,17 @@ func (c *EgressController) realizeEgressIP(egressName, egressIP string) (uint32,
 		ipState = &egressIPState{
 			egressIP:    net.ParseIP(egressIP),
 			egressNames: sets.NewString(egressName),
+			bandwidths:  map[string]*crdv1a2.Bandwidth{},
 		}
 		c.egressIPStates[egressIP] = ipState
 	} else if !ipState.egressNames.Has(egressName) {
 		ipState.egressNames.Insert(egressName)
 	}
+	if bandwidth != nil {
+		ipState.bandwidths[egressName] = bandwidth.DeepCopy()
+	} else {
+		delete(ipState.bandwidths, egressName)
+	}
 
 	var err error
 	if isLocalIP {
@@ -388,19 +542,23 @@ func (c *EgressController) realizeEgressIP(egressName, egressIP string) (uint32,
 		if ipState.mark == 0 {
 			ipState.mark, err = c.idAllocator.allocate()
 			if err != nil {
-				return 0, fmt.Errorf("error allocating mark for IP %s: %v", egressIP, err)
+				return 0, false, fmt.Errorf("error allocating mark for IP %s: %v", egressIP, err)
 			}
 		}
+		// Ensure the meter of the IP enforces the desired bandwidth limit.
+		if err := c.syncEgressIPMeter(egressName, ipState); err != nil {
+			return 0, false, err
+		}
 		// Ensure datapath is installed properly.
 		if !ipState.flowsInstalled {
 			if err := c.ofClient.InstallSNATMarkFlows(ipState.egressIP, ipState.mark); err != nil {
-				return 0, fmt.Errorf("error installing SNAT mark flows for IP %s: %v", ipState.egressIP, err)
+				return 0, false, fmt.Errorf("error installing SNAT mark flows for IP %s: %v", ipState.egressIP, err)
 			}
 			ipState.flowsInstalled = true
 		}
 		if !ipState.ruleInstalled {
 			if err := c.routeClient.AddSNATRule(ipState.egressIP, ipState.mark); err != nil {
-				return 0, fmt.Errorf("error installing SNAT rule for IP %s: %v", ipState.egressIP, err)
+				return 0, false, fmt.Errorf("error installing SNAT rule for IP %s: %v", ipState.egressIP, err)
 			}
 			ipState.ruleInstalled = true
 		}
@@ -408,25 +566,69 @@ func (c *EgressController) realizeEgressIP(egressName, egressIP string) (uint32,
 		// Ensure datapath is uninstalled properly.
 		if ipState.ruleInstalled {
 			if err := c.routeClient.DeleteSNATRule(ipState.mark); err != nil {
-				return 0, fmt.Errorf("error uninstalling SNAT rule for IP %s: %v", ipState.egressIP, err)
+				return 0, false, fmt.Errorf("error uninstalling SNAT rule for IP %s: %v", ipState.egressIP, err)
 			}
 			ipState.ruleInstalled = false
 		}
 		if ipState.flowsInstalled {
 			if err := c.ofClient.UninstallSNATMarkFlows(ipState.mark); err != nil {
-				return 0, fmt.Errorf("error uninstalling SNAT mark flows for IP %s: %v", ipState.egressIP, err)
+				return 0, false, fmt.Errorf("error uninstalling SNAT mark flows for IP %s: %v", ipState.egressIP, err)
 			}
 			ipState.flowsInstalled = false
 		}
+		if ipState.bandwidth != nil {
+			if err := c.ofClient.UninstallSNATMeter(ipState.mark); err != nil {
+				return 0, false, fmt.Errorf("error uninstalling SNAT meter for IP %s: %v", ipState.egressIP, err)
+			}
+			ipState.bandwidth = nil
+		}
 		if ipState.mark != 0 {
 			err := c.idAllocator.release(ipState.mark)
 			if err != nil {
-				return 0, fmt.Errorf("error releasing mark for IP %s: %v", egressIP, err)
+				return 0, false, fmt.Errorf("error releasing mark for IP %s: %v", egressIP, err)
 			}
 			ipState.mark = 0
 		}
 	}
-	return ipState.mark, nil
+	return ipState.mark, ipState.bandwidth != nil, nil
+}
+
+// syncEgressIPMeter ensures the meter of a local Egress IP enforces the effective bandwidth limit of the IP. When the
+// meter is added or removed, the SNAT mark flows of the IP are uninstalled to be reinstalled with the change, and the
+// other Egresses sharing the IP are enqueued to reinstall the flows of their Pods.
+// It must be called with egressIPStatesMutex held.
+func (c *EgressController) syncEgressIPMeter(egressName string, ipState *egressIPState) error {
+	bandwidth := ipState.getEffectiveBandwidth()
+	if reflect.DeepEqual(ipState.bandwidth, bandwidth) {
+		return nil
+	}
+	meterChanged := (ipState.bandwidth == nil) != (bandwidth == nil)
+	if meterChanged && ipState.flowsInstalled {
+		if err := c.ofClient.UninstallSNATMarkFlows(ipState.mark); err != nil {
+			return fmt.Errorf("error uninstalling SNAT mark flows for IP %s: %v", ipState.egressIP, err)
+		}
+		ipState.flowsInstalled = false
+	}
+	if bandwidth != nil {
+		rate, burst, err := getBandwidthKbps(bandwidth)
+		if err != nil {
+			return err
+		}
+		if err := c.ofClient.InstallSNATMeter(ipState.mark, rate, burst); err != nil {
+			return fmt.Errorf("error installing SNAT meter for IP %s: %v", ipState.egressIP, err)
+		}
+	} else if err := c.ofClient.UninstallSNATMeter(ipState.mark); err != nil {
+		return fmt.Errorf("error uninstalling SNAT meter for IP %s: %v", ipState.egressIP, err)
+	}
+	ipState.bandwidth = bandwidth
+	if meterChanged {
+		for name := range ipState.egressNames {
+			if name != egressName {
+				c.queue.Add(name)
+			}
+		}
+	}
+	return nil
 }
 
 // unrealizeEgressIP unrealizes an Egress IP, reverts what realizeEgressIP does.
@@ -444,7 +646,15 @@ func (c *EgressController) unrealizeEgressIP(egressName, egressIP string) error
 	// Unlink the Egress from the EgressIP. If it's the last Egress referring to it, uninstall its datapath rules and
 	// release the mark if installed.
 	ipState.egressNames.Delete(egressName)
+	delete(ipState.bandwidths, egressName)
 	if len(ipState.egressNames) > 0 {
+		// The effective bandwidth limit of the IP may change after the Egress is unlinked, the remaining Egresses
+		// will update the meter when they are processed.
+		if ipState.mark != 0 && !reflect.DeepEqual(ipState.bandwidth, ipState.getEffectiveBandwidth()) {
+			for name := range ipState.egressNames {
+				c.queue.Add(name)
+			}
+		}
 		return nil
 	}
 	if ipState.mark != 0 {
@@ -460,6 +670,12 @@ func (c *EgressController) unrealizeEgressIP(egressName, egressIP string) error
 			}
 			ipState.flowsInstalled = false
 		}
+		if ipState.bandwidth != nil {
+			if err := c.ofClient.UninstallSNATMeter(ipState.mark); err != nil {
+				return err
+			}
+			ipState.bandwidth = nil
+		}
 		c.idAllocator.release(ipState.mark)
 	}
 	delete(c.egressIPStates, egressIP)
@@ -479,13 +695,15 @@ func (c *EgressController) deleteEgressState(egressName string) {
 	delete(c.egressStates, egressName)
 }
 
-func (c *EgressController) newEgressState(egressName string, egressIP string) *egressState {
+func (c *EgressController) newEgressState(egressName string, egressIPs []string) *egressState {
 	c.egressStatesMutex.Lock()
 	defer c.egressStatesMutex.Unlock()
 	state := &egressState{
-		egressIP: egressIP,
-		ofPorts:  sets.NewInt32(),
-		pods:     sets.NewString(),
+		egressIPs:  egressIPs,
+		marks:      map[string]uint32{},
+		meteredIPs: sets.NewString(),
+		ofPorts:    map[int32]string{},
+		pods:       sets.NewString(),
 	}
 	c.egressStates[egressName] = state
 	return state
@@ -537,24 +755,88 @@ func (c *EgressController) unbindPodEgress(pod, egress string) (string, bool) {
 	return "", false
 }
 
+// GetEgress returns the name of the effective Egress of the Pod, the Egress IP
+// used by the Pod, and the Node holding the IP. An error is returned if no
+// Egress applies to the Pod.
+func (c *EgressController) GetEgress(podNamespace, podName string) (string, string, string, error) {
+	pod := k8s.NamespacedName(podNamespace, podName)
+	egressName := func() string {
+		c.egressBindingsMutex.RLock()
+		defer c.egressBindingsMutex.RUnlock()
+		binding, exists := c.egressBindings[pod]
+		if !exists {
+			return ""
+		}
+		return binding.effectiveEgress
+	}()
+	if egressName == "" {
+		return "", "", "", fmt.Errorf("no Egress applied to Pod %s", pod)
+	}
+	egress, err := c.egressLister.Get(egressName)
+	if err != nil {
+		return "", "", "", err
+	}
+	egressIPs := getEgressIPs(egress)
+	if len(egressIPs) == 0 {
+		return egress.Name, "", "", nil
+	}
+	egressIP := selectEgressIP(pod, egressIPs)
+	return egress.Name, egressIP, getEgressIPNode(egress, egressIP), nil
+}
+
+// GetEgressByIP returns the name of an Egress using the provided IP, if the IP
+// is assigned to the local Node.
+func (c *EgressController) GetEgressByIP(egressIP string) (string, bool) {
+	if !c.localIPDetector.IsLocalIP(egressIP) {
+		return "", false
+	}
+	egresses, _ := c.egressInformer.GetIndexer().ByIndex(egressIPIndex, egressIP)
+	if len(egresses) == 0 {
+		return "", false
+	}
+	return egresses[0].(*crdv1a2.Egress).Name, true
+}
+
 func (c *EgressController) updateEgressStatus(egress *crdv1a2.Egress, isLocal bool) error {
 	toUpdate := egress.DeepCopy()
 	var updateErr, getErr error
 	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		prevNode := toUpdate.Status.EgressNode
+		changed := false
 		if isLocal {
-			// Do nothing if the current EgressNode in status is already this Node.
-			if toUpdate.Status.EgressNode == c.nodeName {
-				return nil
+			if prevNode != c.nodeName {
+				toUpdate.Status.EgressNode = c.nodeName
+				changed = true
+				// The Egress IP was held by another Node, it has been taken over by this Node.
+				if prevNode != "" {
+					c.setFailoverCondition(&toUpdate.Status, prevNode)
+				}
 			}
-			toUpdate.Status.EgressNode = c.nodeName
+			changed = egressutil.SetEgressCondition(&toUpdate.Status, crdv1a2.EgressCondition{
+				Type:    crdv1a2.IPAssigned,
+				Status:  corev1.ConditionTrue,
+				Reason:  "Assigned",
+				Message: fmt.Sprintf("EgressIP is assigned to Node %s", c.nodeName),
+			}) || changed
 		} else {
 			// Do nothing if the current EgressNode in status is not this Node.
-			if toUpdate.Status.EgressNode != c.nodeName {
+			if prevNode != c.nodeName {
 				return nil
 			}
 			toUpdate.Status.EgressNode = ""
+			egressutil.SetEgressCondition(&toUpdate.Status, crdv1a2.EgressCondition{
+				Type:    crdv1a2.IPAssigned,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Released",
+				Message: fmt.Sprintf("EgressIP is released by Node %s", c.nodeName),
+			})
+			changed = true
+		}
+		// Do nothing if the status is already up to date.
+		if !changed {
+			return nil
 		}
-		klog.V(2).InfoS("Updating Egress status", "Egress", egress.Name, "oldNode", egress.Status.EgressNode, "newNode", toUpdate.Status.EgressNode)
+		klog.V(2).InfoS("Updating Egress status", "Egress", egress.Name, "oldNode", prevNode, "newNode", toUpdate.Status.EgressNode)
 		_, updateErr = c.crdClient.CrdV1alpha2().Egresses().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
 		if updateErr != nil && errors.IsConflict(updateErr) {
 			if toUpdate, getErr = c.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{}); getErr != nil {
@@ -571,6 +853,83 @@ func (c *EgressController) updateEgressStatus(egress *crdv1a2.Egress, isLocal bo
 	return nil
 }
 
+// setFailoverCondition records in the Failover condition that an Egress IP has been moved from prevNode to this
+// Node. The condition is replaced so that its LastTransitionTime reflects the latest failover.
+func (c *EgressController) setFailoverCondition(status *crdv1a2.EgressStatus, prevNode string) {
+	egressutil.RemoveEgressCondition(status, crdv1a2.Failover)
+	egressutil.SetEgressCondition(status, crdv1a2.EgressCondition{
+		Type:    crdv1a2.Failover,
+		Status:  corev1.ConditionTrue,
+		Reason:  "NodeChanged",
+		Message: fmt.Sprintf("EgressIP is moved from Node %s to Node %s", prevNode, c.nodeName),
+	})
+}
+
+// updateEgressIPAssignments updates the Node assignments of the Egress IPs in the status of an Egress which has
+// multiple Egress IPs. Each Node only updates the assignments of the IPs assigned to it or previously assigned to it.
+func (c *EgressController) updateEgressIPAssignments(egress *crdv1a2.Egress, localIPs sets.String) error {
+	toUpdate := egress.DeepCopy()
+	var updateErr, getErr error
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		assignedNodes := map[string]string{}
+		for _, assignment := range toUpdate.Status.EgressIPAssignments {
+			assignedNodes[assignment.EgressIP] = assignment.EgressNode
+		}
+		changed := false
+		for _, egressIP := range toUpdate.Spec.EgressIPs {
+			if localIPs.Has(egressIP) && assignedNodes[egressIP] != c.nodeName {
+				// The Egress IP was held by another Node, it has been taken over by this Node.
+				if prevNode := assignedNodes[egressIP]; prevNode != "" {
+					c.setFailoverCondition(&toUpdate.Status, prevNode)
+				}
+				assignedNodes[egressIP] = c.nodeName
+				changed = true
+			} else if !localIPs.Has(egressIP) && assignedNodes[egressIP] == c.nodeName {
+				delete(assignedNodes, egressIP)
+				changed = true
+			}
+		}
+		// Keep the assignments in the order of the Egress IPs and drop the ones of the removed IPs.
+		var assignments []crdv1a2.EgressIPAssignment
+		for _, egressIP := range toUpdate.Spec.EgressIPs {
+			if node, exists := assignedNodes[egressIP]; exists && node != "" {
+				assignments = append(assignments, crdv1a2.EgressIPAssignment{EgressIP: egressIP, EgressNode: node})
+			}
+		}
+		// IPAssigned is true only when all of the Egress IPs are assigned.
+		assignedCondition := crdv1a2.EgressCondition{
+			Type:    crdv1a2.IPAssigned,
+			Status:  corev1.ConditionTrue,
+			Reason:  "Assigned",
+			Message: fmt.Sprintf("%d of %d EgressIPs are assigned", len(assignments), len(toUpdate.Spec.EgressIPs)),
+		}
+		if len(assignments) < len(toUpdate.Spec.EgressIPs) {
+			assignedCondition.Status = corev1.ConditionFalse
+			assignedCondition.Reason = "PartiallyAssigned"
+		}
+		changed = egressutil.SetEgressCondition(&toUpdate.Status, assignedCondition) || changed
+		// Do nothing if the assignments of the local Egress IPs are already correct.
+		if !changed {
+			return nil
+		}
+		toUpdate.Status.EgressIPAssignments = assignments
+		klog.V(2).InfoS("Updating Egress IP assignments", "Egress", egress.Name, "assignments", assignments)
+		_, updateErr = c.crdClient.CrdV1alpha2().Egresses().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		if updateErr != nil && errors.IsConflict(updateErr) {
+			if toUpdate, getErr = c.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{}); getErr != nil {
+				return getErr
+			}
+		}
+		// Return the error from UPDATE.
+		return updateErr
+	}); err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Updated Egress IP assignments", "Egress", egress.Name)
+	metrics.AntreaEgressStatusUpdates.Inc()
+	return nil
+}
+
 func (c *EgressController) syncEgress(egressName string) error {
 	startTime := time.Now()
 	defer func() {
@@ -589,79 +948,113 @@ func (c *EgressController) syncEgress(egressName string) error {
 			if err := c.uninstallEgress(egressName, eState); err != nil {
 				return err
 			}
+			agentmetrics.EgressSNATPacketCount.Delete(map[string]string{"egress": egressName})
+			agentmetrics.EgressSNATByteCount.Delete(map[string]string{"egress": egressName})
 			return nil
 		}
 		return err
 	}
 
+	desiredEgressIPs := getEgressIPs(egress)
 	eState, exist := c.getEgressState(egressName)
-	// If the EgressIP changes, uninstalls this Egress first.
-	if exist && eState.egressIP != egress.Spec.EgressIP {
+	// If the EgressIPs change, uninstalls this Egress first.
+	if exist && !reflect.DeepEqual(eState.egressIPs, desiredEgressIPs) {
 		if err := c.uninstallEgress(egressName, eState); err != nil {
 			return err
 		}
 		exist = false
 	}
 	// Do not proceed if EgressIP is empty.
-	if egress.Spec.EgressIP == "" {
+	if len(desiredEgressIPs) == 0 {
 		return nil
 	}
 	if !exist {
-		eState = c.newEgressState(egressName, egress.Spec.EgressIP)
+		eState = c.newEgressState(egressName, desiredEgressIPs)
 	}
 
-	localNodeSelected, err := c.cluster.ShouldSelectIP(egress.Spec.EgressIP, egress.Spec.ExternalIPPool)
-	if err != nil {
-		return err
-	}
-	if localNodeSelected {
-		// Ensure the Egress IP is assigned to the system.
-		if err := c.ipAssigner.AssignIP(egress.Spec.EgressIP); err != nil {
-			return err
+	// Get a copy of the desired Pods.
+	pods := func() sets.String {
+		c.egressGroupsMutex.RLock()
+		defer c.egressGroupsMutex.RUnlock()
+		pods, exist := c.egressGroups[egressName]
+		if !exist {
+			return nil
 		}
-	} else {
-		// Unassign the Egress IP from the local Node if it was assigned by the agent.
-		if err := c.ipAssigner.UnassignIP(egress.Spec.EgressIP); err != nil {
+		return pods.Union(nil)
+	}()
+
+	localEgressIPs := sets.NewString()
+	for _, egressIP := range desiredEgressIPs {
+		// Each Egress IP is assigned to a Node independently, so that only the IPs held by a failed Node will be moved
+		// to other Nodes.
+		localNodeSelected, err := c.cluster.ShouldSelectIP(egressIP, egress.Spec.ExternalIPPool)
+		if err != nil {
 			return err
 		}
-	}
-
-	// Realize the latest EgressIP and get the desired mark.
-	mark, err := c.realizeEgressIP(egressName, egress.Spec.EgressIP)
-	if err != nil {
-		return err
-	}
+		if localNodeSelected {
+			// Ensure the Egress IP is assigned to the system.
+			if err := c.ipAssigner.AssignIP(egressIP); err != nil {
+				return err
+			}
+		} else {
+			// Unassign the Egress IP from the local Node if it was assigned by the agent.
+			if err := c.ipAssigner.UnassignIP(egressIP); err != nil {
+				return err
+			}
+		}
 
-	// If the mark changes, uninstall all of the Egress's Pod flows first, then installs them with new mark.
-	// It could happen when the Egress IP is added to or removed from the Node.
-	if eState.mark != mark {
-		// Uninstall all of its Pod flows.
-		if err := c.uninstallPodFlows(egressName, eState, eState.ofPorts, eState.pods); err != nil {
+		// Realize the latest EgressIP and get the desired mark.
+		mark, metered, err := c.realizeEgressIP(egressName, egressIP, egress.Spec.Bandwidth)
+		if err != nil {
 			return err
 		}
-		eState.mark = mark
+
+		// If the mark changes, uninstall the flows of the Pods using the IP first, then installs them with new mark.
+		// It could happen when the Egress IP is added to or removed from the Node. Similarly, the flows are
+		// reinstalled when the bandwidth meter of the IP is added or removed.
+		if eState.marks[egressIP] != mark || eState.meteredIPs.Has(egressIP) != metered {
+			ofPorts := map[int32]string{}
+			for ofPort, ip := range eState.ofPorts {
+				if ip == egressIP {
+					ofPorts[ofPort] = ip
+				}
+			}
+			ipPods := sets.NewString()
+			for pod := range eState.pods {
+				if selectEgressIP(pod, eState.egressIPs) == egressIP {
+					ipPods.Insert(pod)
+				}
+			}
+			if err := c.uninstallPodFlows(egressName, eState, ofPorts, ipPods); err != nil {
+				return err
+			}
+			eState.marks[egressIP] = mark
+			if metered {
+				eState.meteredIPs.Insert(egressIP)
+			} else {
+				eState.meteredIPs.Delete(egressIP)
+			}
+		}
+		if c.localIPDetector.IsLocalIP(egressIP) {
+			localEgressIPs.Insert(egressIP)
+		}
 	}
 
-	if err := c.updateEgressStatus(egress, c.localIPDetector.IsLocalIP(egress.Spec.EgressIP)); err != nil {
+	if len(egress.Spec.EgressIPs) > 0 {
+		if err := c.updateEgressIPAssignments(egress, localEgressIPs); err != nil {
+			return fmt.Errorf("update Egress %s status error: %v", egressName, err)
+		}
+	} else if err := c.updateEgressStatus(egress, localEgressIPs.Has(egress.Spec.EgressIP)); err != nil {
 		return fmt.Errorf("update Egress %s status error: %v", egressName, err)
 	}
 
 	// Copy the previous ofPorts and Pods. They will be used to identify stale ofPorts and Pods.
-	staleOFPorts := eState.ofPorts.Union(nil)
+	staleOFPorts := make(map[int32]string, len(eState.ofPorts))
+	for ofPort, ip := range eState.ofPorts {
+		staleOFPorts[ofPort] = ip
+	}
 	stalePods := eState.pods.Union(nil)
 
-	// Get a copy of the desired Pods.
-	pods := func() sets.String {
-		c.egressGroupsMutex.RLock()
-		defer c.egressGroupsMutex.RUnlock()
-		pods, exist := c.egressGroups[egressName]
-		if !exist {
-			return nil
-		}
-		return pods.Union(nil)
-	}()
-
-	egressIP := net.ParseIP(eState.egressIP)
 	// Install SNAT flows for desired Pods.
 	for pod := range pods {
 		eState.pods.Insert(pod)
@@ -682,14 +1075,16 @@ func (c *EgressController) syncEgress(egressName string) error {
 		}
 
 		ofPort := ifaces[0].OFPort
-		if eState.ofPorts.Has(ofPort) {
-			staleOFPorts.Delete(ofPort)
+		if _, installed := eState.ofPorts[ofPort]; installed {
+			delete(staleOFPorts, ofPort)
 			continue
 		}
-		if err := c.ofClient.InstallPodSNATFlows(uint32(ofPort), egressIP, mark); err != nil {
+		// Distribute the Pods across the Egress IPs.
+		egressIP := selectEgressIP(pod, eState.egressIPs)
+		if err := c.ofClient.InstallPodSNATFlows(uint32(ofPort), net.ParseIP(egressIP), eState.marks[egressIP]); err != nil {
 			return err
 		}
-		eState.ofPorts.Insert(ofPort)
+		eState.ofPorts[ofPort] = egressIP
 	}
 
 	// Uninstall SNAT flows for stale Pods.
@@ -704,25 +1099,27 @@ func (c *EgressController) uninstallEgress(egressName string, eState *egressStat
 	if err := c.uninstallPodFlows(egressName, eState, eState.ofPorts, eState.pods); err != nil {
 		return err
 	}
-	// Release the EgressIP's mark if the Egress is the last one referring to it.
-	if err := c.unrealizeEgressIP(egressName, eState.egressIP); err != nil {
-		return err
-	}
-	// Unassign the Egress IP from the local Node if it was assigned by the agent.
-	if err := c.ipAssigner.UnassignIP(eState.egressIP); err != nil {
-		return err
+	for _, egressIP := range eState.egressIPs {
+		// Release the EgressIP's mark if the Egress is the last one referring to it.
+		if err := c.unrealizeEgressIP(egressName, egressIP); err != nil {
+			return err
+		}
+		// Unassign the Egress IP from the local Node if it was assigned by the agent.
+		if err := c.ipAssigner.UnassignIP(egressIP); err != nil {
+			return err
+		}
 	}
 	// Remove the Egress's state.
 	c.deleteEgressState(egressName)
 	return nil
 }
 
-func (c *EgressController) uninstallPodFlows(egressName string, egressState *egressState, ofPorts sets.Int32, pods sets.String) error {
+func (c *EgressController) uninstallPodFlows(egressName string, egressState *egressState, ofPorts map[int32]string, pods sets.String) error {
 	for ofPort := range ofPorts {
 		if err := c.ofClient.UninstallPodSNATFlows(uint32(ofPort)); err != nil {
 			return err
 		}
-		egressState.ofPorts.Delete(ofPort)
+		delete(egressState.ofPorts, ofPort)
 	}
 
 	// Remove Pods from the Egress state after uninstalling Pod's flows to avoid overlapping. Otherwise another Egress
@@ -25,6 +25,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,18 +33,22 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics/testutil"
 
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/ipassigner"
 	ipassignertest "antrea.io/antrea/pkg/agent/ipassigner/testing"
+	agentmetrics "antrea.io/antrea/pkg/agent/metrics"
 	openflowtest "antrea.io/antrea/pkg/agent/openflow/testing"
 	routetest "antrea.io/antrea/pkg/agent/route/testing"
+	"antrea.io/antrea/pkg/agent/types"
 	"antrea.io/antrea/pkg/agent/util"
 	cpv1b2 "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
 	"antrea.io/antrea/pkg/client/clientset/versioned"
 	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
 	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	egressutil "antrea.io/antrea/pkg/util/egress"
 	"antrea.io/antrea/pkg/util/k8s"
 )
 
@@ -304,6 +309,109 @@ func TestSyncEgress(t *testing.T) {
 				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP2)
 			},
 		},
+		{
+			name: "Add bandwidth to local Egress IP",
+			existingEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
+			},
+			newEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: crdv1a2.EgressSpec{
+					EgressIP:  fakeLocalEgressIP1,
+					Bandwidth: &crdv1a2.Bandwidth{Rate: "10M", Burst: "1M"},
+				},
+			},
+			existingEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+					{Pod: &cpv1b2.PodReference{Name: "pod2", Namespace: "ns2"}},
+				},
+			},
+			newEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+					{Pod: &cpv1b2.PodReference{Name: "pod2", Namespace: "ns2"}},
+				},
+			},
+			expectedEgresses: []*crdv1a2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec: crdv1a2.EgressSpec{
+						EgressIP:  fakeLocalEgressIP1,
+						Bandwidth: &crdv1a2.Bandwidth{Rate: "10M", Burst: "1M"},
+					},
+					Status: crdv1a2.EgressStatus{EgressNode: fakeNode},
+				},
+			},
+			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(2), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockRouteClient.EXPECT().AddSNATRule(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(3)
+
+				// The SNAT flows of the IP and its Pods are reinstalled to apply the meter.
+				mockOFClient.EXPECT().UninstallSNATMarkFlows(uint32(1))
+				mockOFClient.EXPECT().InstallSNATMeter(uint32(1), uint32(10000), uint32(1000))
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().UninstallPodSNATFlows(uint32(1))
+				mockOFClient.EXPECT().UninstallPodSNATFlows(uint32(2))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(2), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+			},
+		},
+		{
+			name: "Update bandwidth of local Egress IP",
+			existingEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: crdv1a2.EgressSpec{
+					EgressIP:  fakeLocalEgressIP1,
+					Bandwidth: &crdv1a2.Bandwidth{Rate: "10M", Burst: "1M"},
+				},
+			},
+			newEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: crdv1a2.EgressSpec{
+					EgressIP:  fakeLocalEgressIP1,
+					Bandwidth: &crdv1a2.Bandwidth{Rate: "20M", Burst: "2M"},
+				},
+			},
+			existingEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+				},
+			},
+			newEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+				},
+			},
+			expectedEgresses: []*crdv1a2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec: crdv1a2.EgressSpec{
+						EgressIP:  fakeLocalEgressIP1,
+						Bandwidth: &crdv1a2.Bandwidth{Rate: "20M", Burst: "2M"},
+					},
+					Status: crdv1a2.EgressStatus{EgressNode: fakeNode},
+				},
+			},
+			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
+				mockOFClient.EXPECT().InstallSNATMeter(uint32(1), uint32(10000), uint32(1000))
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockRouteClient.EXPECT().AddSNATRule(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(3)
+
+				// Only the meter is updated, the flows are not affected.
+				mockOFClient.EXPECT().InstallSNATMeter(uint32(1), uint32(20000), uint32(2000))
+			},
+		},
 		{
 			name: "Change from local Egress IP to a remote one",
 			existingEgress: &crdv1a2.Egress{
@@ -497,6 +605,64 @@ func TestSyncEgress(t *testing.T) {
 				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(3)
 			},
 		},
+		{
+			name: "Multiple Egress IPs change Nodes",
+			existingEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIPs: []string{fakeLocalEgressIP1, fakeRemoteEgressIP1}},
+			},
+			newEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIPs: []string{fakeLocalEgressIP1, fakeRemoteEgressIP1}},
+			},
+			// pod1 and pod4 are hashed to fakeRemoteEgressIP1, pod3 is hashed to fakeLocalEgressIP1.
+			existingEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+					{Pod: &cpv1b2.PodReference{Name: "pod3", Namespace: "ns3"}},
+				},
+			},
+			newEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+					{Pod: &cpv1b2.PodReference{Name: "pod3", Namespace: "ns3"}},
+					{Pod: &cpv1b2.PodReference{Name: "pod4", Namespace: "ns4"}},
+				},
+			},
+			newLocalIPs: sets.NewString(fakeRemoteEgressIP1),
+			expectedEgresses: []*crdv1a2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec:       crdv1a2.EgressSpec{EgressIPs: []string{fakeLocalEgressIP1, fakeRemoteEgressIP1}},
+					Status: crdv1a2.EgressStatus{
+						EgressIPAssignments: []crdv1a2.EgressIPAssignment{{EgressIP: fakeRemoteEgressIP1, EgressNode: fakeNode}},
+					},
+				},
+			},
+			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockRouteClient.EXPECT().AddSNATRule(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeRemoteEgressIP1), uint32(0))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(3), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+
+				// fakeLocalEgressIP1 is moved to another Node and fakeRemoteEgressIP1 is moved to this Node, only the
+				// flows of the Pods using them are reinstalled.
+				mockOFClient.EXPECT().UninstallSNATMarkFlows(uint32(1))
+				mockRouteClient.EXPECT().DeleteSNATRule(uint32(1))
+				mockOFClient.EXPECT().UninstallPodSNATFlows(uint32(3))
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeRemoteEgressIP1), uint32(1))
+				mockRouteClient.EXPECT().AddSNATRule(net.ParseIP(fakeRemoteEgressIP1), uint32(1))
+				mockOFClient.EXPECT().UninstallPodSNATFlows(uint32(1))
+
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeRemoteEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(3), net.ParseIP(fakeLocalEgressIP1), uint32(0))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(4), net.ParseIP(fakeRemoteEgressIP1), uint32(1))
+				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(3)
+				mockIPAssigner.EXPECT().UnassignIP(fakeRemoteEgressIP1).Times(3)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -778,3 +944,130 @@ func TestUpdateEgressStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateEgressStatusConditions(t *testing.T) {
+	egress := &crdv1a2.Egress{
+		ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+		Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
+		Status:     crdv1a2.EgressStatus{EgressNode: "node2"},
+	}
+	fakeClient := fakeversioned.NewSimpleClientset(egress)
+	c := &EgressController{crdClient: fakeClient, nodeName: fakeNode}
+
+	// The Egress IP is taken over from node2.
+	require.NoError(t, c.updateEgressStatus(egress, true))
+	egress, err := fakeClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fakeNode, egress.Status.EgressNode)
+	assignedCondition := egressutil.GetEgressCondition(&egress.Status, crdv1a2.IPAssigned)
+	require.NotNil(t, assignedCondition)
+	assert.Equal(t, corev1.ConditionTrue, assignedCondition.Status)
+	failoverCondition := egressutil.GetEgressCondition(&egress.Status, crdv1a2.Failover)
+	require.NotNil(t, failoverCondition)
+	assert.Equal(t, corev1.ConditionTrue, failoverCondition.Status)
+	assert.Equal(t, "EgressIP is moved from Node node2 to Node node1", failoverCondition.Message)
+
+	// The Egress IP is released by this Node.
+	require.NoError(t, c.updateEgressStatus(egress, false))
+	egress, err = fakeClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "", egress.Status.EgressNode)
+	assignedCondition = egressutil.GetEgressCondition(&egress.Status, crdv1a2.IPAssigned)
+	require.NotNil(t, assignedCondition)
+	assert.Equal(t, corev1.ConditionFalse, assignedCondition.Status)
+}
+
+func TestUpdateEgressIPAssignmentsConditions(t *testing.T) {
+	egress := &crdv1a2.Egress{
+		ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+		Spec:       crdv1a2.EgressSpec{EgressIPs: []string{fakeLocalEgressIP1, fakeRemoteEgressIP1}},
+		Status: crdv1a2.EgressStatus{
+			EgressIPAssignments: []crdv1a2.EgressIPAssignment{{EgressIP: fakeLocalEgressIP1, EgressNode: "node2"}},
+		},
+	}
+	fakeClient := fakeversioned.NewSimpleClientset(egress)
+	c := &EgressController{crdClient: fakeClient, nodeName: fakeNode}
+
+	require.NoError(t, c.updateEgressIPAssignments(egress, sets.NewString(fakeLocalEgressIP1)))
+	egress, err := fakeClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []crdv1a2.EgressIPAssignment{{EgressIP: fakeLocalEgressIP1, EgressNode: fakeNode}}, egress.Status.EgressIPAssignments)
+	// Only one of the two Egress IPs is assigned.
+	assignedCondition := egressutil.GetEgressCondition(&egress.Status, crdv1a2.IPAssigned)
+	require.NotNil(t, assignedCondition)
+	assert.Equal(t, corev1.ConditionFalse, assignedCondition.Status)
+	assert.Equal(t, "1 of 2 EgressIPs are assigned", assignedCondition.Message)
+	failoverCondition := egressutil.GetEgressCondition(&egress.Status, crdv1a2.Failover)
+	require.NotNil(t, failoverCondition)
+	assert.Equal(t, "EgressIP is moved from Node node2 to Node node1", failoverCondition.Message)
+}
+
+func TestUpdateSNATMetrics(t *testing.T) {
+	agentmetrics.InitializeEgressMetrics()
+	agentmetrics.EgressSNATPacketCount.Reset()
+	agentmetrics.EgressSNATByteCount.Reset()
+	c := newFakeController(t, nil)
+	defer c.mockController.Finish()
+	c.bindPodEgress(k8s.NamespacedName("ns1", "pod1"), "egressA")
+	c.bindPodEgress(k8s.NamespacedName("ns2", "pod2"), "egressB")
+
+	checkSNATMetrics := func(egressName string, expectedPackets, expectedBytes float64) {
+		packets, err := testutil.GetCounterMetricValue(agentmetrics.EgressSNATPacketCount.WithLabelValues(egressName))
+		require.NoError(t, err)
+		assert.Equal(t, expectedPackets, packets)
+		bytes, err := testutil.GetCounterMetricValue(agentmetrics.EgressSNATByteCount.WithLabelValues(egressName))
+		require.NoError(t, err)
+		assert.Equal(t, expectedBytes, bytes)
+	}
+
+	// pod3 is not applied any Egress, its statistics are ignored.
+	c.mockOFClient.EXPECT().PodSNATMetrics().Return(map[uint32]*types.SNATMetric{
+		1: {Packets: 10, Bytes: 1000},
+		2: {Packets: 5, Bytes: 500},
+		3: {Packets: 1, Bytes: 100},
+	})
+	c.updateSNATMetrics()
+	checkSNATMetrics("egressA", 10, 1000)
+	checkSNATMetrics("egressB", 5, 500)
+
+	// The flows of pod2 have been reinstalled, its statistics restart from 0.
+	c.mockOFClient.EXPECT().PodSNATMetrics().Return(map[uint32]*types.SNATMetric{
+		1: {Packets: 15, Bytes: 1500},
+		2: {Packets: 2, Bytes: 200},
+	})
+	c.updateSNATMetrics()
+	checkSNATMetrics("egressA", 15, 1500)
+	checkSNATMetrics("egressB", 7, 700)
+}
+
+func TestGetEgress(t *testing.T) {
+	egress := &crdv1a2.Egress{
+		ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+		Spec:       crdv1a2.EgressSpec{EgressIPs: []string{fakeLocalEgressIP1, fakeRemoteEgressIP1}},
+		Status: crdv1a2.EgressStatus{
+			EgressIPAssignments: []crdv1a2.EgressIPAssignment{
+				{EgressIP: fakeLocalEgressIP1, EgressNode: fakeNode},
+				{EgressIP: fakeRemoteEgressIP1, EgressNode: "node2"},
+			},
+		},
+	}
+	c := newFakeController(t, []runtime.Object{egress})
+	defer c.mockController.Finish()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c.crdInformerFactory.Start(stopCh)
+	c.crdInformerFactory.WaitForCacheSync(stopCh)
+
+	// pod1 is hashed to fakeRemoteEgressIP1, pod3 is hashed to fakeLocalEgressIP1.
+	c.bindPodEgress(k8s.NamespacedName("ns1", "pod1"), egress.Name)
+	c.bindPodEgress(k8s.NamespacedName("ns3", "pod3"), egress.Name)
+
+	name, ip, node, err := c.GetEgress("ns1", "pod1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"egressA", fakeRemoteEgressIP1, "node2"}, []string{name, ip, node})
+	name, ip, node, err = c.GetEgress("ns3", "pod3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"egressA", fakeLocalEgressIP1, fakeNode}, []string{name, ip, node})
+	_, _, _, err = c.GetEgress("ns2", "pod2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	forwarderDialTimeout  = 5 * time.Second
+	forwarderWriteTimeout = 5 * time.Second
+	forwarderRetryDelay   = 5 * time.Second
+	// syslogPriority is the PRI of the syslog messages: facility local0 (16) and
+	// severity informational (6).
+	syslogPriority = 16*8 + 6
+	syslogAppName  = "antrea-agent"
+)
+
+// logForwarder forwards the audit logs to a remote collector. It implements
+// io.Writer so that it can be used as an output of the audit logger. Writes
+// never block: the logs are buffered in a bounded buffer, which is drained by
+// run, and the oldest logs are dropped when the buffer is full.
+type logForwarder struct {
+	transport  string
+	address    string
+	syslog     bool
+	hostname   string
+	bufferSize int
+	clock      Clock
+	dial       func(network, address string, timeout time.Duration) (net.Conn, error)
+
+	mutex   sync.Mutex
+	buffer  [][]byte
+	dropped uint64
+	// reportedDropped is the number of dropped logs which have been reported.
+	reportedDropped uint64
+	// notifyCh is signaled when logs are added to buffer.
+	notifyCh chan struct{}
+	conn     net.Conn
+}
+
+func newLogForwarder(transport, address string, syslog bool, hostname string, bufferSize int, clock Clock) *logForwarder {
+	return &logForwarder{
+		transport:  transport,
+		address:    address,
+		syslog:     syslog,
+		hostname:   hostname,
+		bufferSize: bufferSize,
+		clock:      clock,
+		dial:       net.DialTimeout,
+		notifyCh:   make(chan struct{}, 1),
+	}
+}
+
+// Write buffers a copy of the log line p to be forwarded.
+func (f *logForwarder) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	msg := make([]byte, len(p))
+	copy(msg, p)
+	f.mutex.Lock()
+	if len(f.buffer) >= f.bufferSize {
+		f.buffer[0] = nil
+		f.buffer = f.buffer[1:]
+		f.dropped++
+	}
+	f.buffer = append(f.buffer, msg)
+	f.mutex.Unlock()
+	select {
+	case f.notifyCh <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// run forwards the buffered logs until stopCh is closed.
+func (f *logForwarder) run(stopCh <-chan struct{}) {
+	klog.InfoS("Starting audit log forwarder", "address", f.address, "transport", f.transport)
+	defer f.closeConn()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-f.notifyCh:
+		}
+		if !f.flush(stopCh) {
+			return
+		}
+	}
+}
+
+// flush forwards the buffered logs until the buffer is empty. A log which
+// cannot be forwarded is retried after forwarderRetryDelay, unless it's
+// dropped from the buffer in the meantime. It returns false if stopCh is
+// closed.
+func (f *logForwarder) flush(stopCh <-chan struct{}) bool {
+	for {
+		f.mutex.Lock()
+		if len(f.buffer) == 0 {
+			f.mutex.Unlock()
+			return true
+		}
+		msg := f.buffer[0]
+		if f.dropped != f.reportedDropped {
+			klog.InfoS("Dropped audit logs as the forwarding buffer was full", "count", f.dropped-f.reportedDropped)
+			f.reportedDropped = f.dropped
+		}
+		f.mutex.Unlock()
+
+		if err := f.send(msg); err != nil {
+			klog.ErrorS(err, "Failed to forward audit log", "address", f.address)
+			select {
+			case <-stopCh:
+				return false
+			case <-f.clock.After(forwarderRetryDelay):
+			}
+			continue
+		}
+
+		f.mutex.Lock()
+		// The log may have been dropped while it was being sent.
+		if len(f.buffer) > 0 && &f.buffer[0][0] == &msg[0] {
+			f.buffer[0] = nil
+			f.buffer = f.buffer[1:]
+		}
+		f.mutex.Unlock()
+	}
+}
+
+// send writes msg to the remote collector, connecting to it first if needed.
+// The connection is closed on error, so that the next call reconnects.
+func (f *logForwarder) send(msg []byte) error {
+	if f.conn == nil {
+		conn, err := f.dial(f.transport, f.address, forwarderDialTimeout)
+		if err != nil {
+			return fmt.Errorf("error when connecting to %s: %v", f.address, err)
+		}
+		f.conn = conn
+	}
+	f.conn.SetWriteDeadline(time.Now().Add(forwarderWriteTimeout))
+	if _, err := f.conn.Write(f.frame(msg)); err != nil {
+		f.closeConn()
+		return fmt.Errorf("error when writing to %s: %v", f.address, err)
+	}
+	return nil
+}
+
+// frame returns the message sent for the log line msg: the line itself, or a
+// newline-terminated syslog message (RFC 5424) wrapping it.
+func (f *logForwarder) frame(msg []byte) []byte {
+	if !f.syslog {
+		return msg
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<%d>1 %s %s %s - - - ", syslogPriority, f.clock.Now().UTC().Format(time.RFC3339Nano), f.hostname, syslogAppName)
+	b.Write(bytes.TrimRight(msg, "\n"))
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func (f *logForwarder) closeConn() {
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogForwarderBuffer(t *testing.T) {
+	forwarder := newLogForwarder("tcp", "127.0.0.1:514", false, "node1", 2, &realClock{})
+	for _, msg := range []string{"log1\n", "log2\n", "log3\n"} {
+		n, err := forwarder.Write([]byte(msg))
+		require.NoError(t, err)
+		assert.Equal(t, len(msg), n)
+	}
+	// The oldest log is dropped when the buffer is full.
+	assert.Equal(t, [][]byte{[]byte("log2\n"), []byte("log3\n")}, forwarder.buffer)
+	assert.Equal(t, uint64(1), forwarder.dropped)
+}
+
+func TestLogForwarderSyslog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	clock := NewVirtualClock(time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC))
+	defer clock.Stop()
+	forwarder := newLogForwarder("tcp", listener.Addr().String(), true, "node1", 10, clock)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go forwarder.run(stopCh)
+
+	_, err = forwarder.Write([]byte(`{"policy":"AntreaNetworkPolicy:default/test"}` + "\n"))
+	require.NoError(t, err)
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `<134>1 2021-10-01T08:00:00Z node1 antrea-agent - - - {"policy":"AntreaNetworkPolicy:default/test"}`+"\n", line)
+}
@@ -15,7 +15,9 @@
 package networkpolicy
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -23,15 +25,30 @@ import (
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
+	"antrea.io/antrea/pkg/agent/l7proxy"
 	"antrea.io/antrea/pkg/agent/openflow"
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+	agentconfig "antrea.io/antrea/pkg/config/agent"
 	"antrea.io/antrea/pkg/util/logdir"
 )
 
 const (
 	logfileSubdir string = "networkpolicy"
 	logfileName   string = "np.log"
+	// l7ProxyLogTableName is logged in place of the table name for the decisions made by the L7 proxy.
+	l7ProxyLogTableName string = "L7Proxy"
+	// auditDispositionPrefix is prepended to the action of an audited rule, which is logged in
+	// place of the disposition of the allowed traffic, e.g. "AuditDrop".
+	auditDispositionPrefix string = "Audit"
+
+	// AuditLogFormatText is the format of the audit logs as space-separated fields.
+	AuditLogFormatText = "text"
+	// AuditLogFormatJSON is the format of the audit logs as JSON objects, one per line.
+	AuditLogFormatJSON = "json"
 )
 
 type Clock interface {
@@ -56,18 +73,62 @@ type AntreaPolicyLogger struct {
 	clock            Clock // enable the use of a "virtual" clock for unit tests
 	anpLogger        *log.Logger
 	logDeduplication logRecordDedupMap
+	jsonFormat       bool   // log JSON objects instead of space-separated fields
+	nodeName         string // name of the Node, only included in JSON logs
+	// forwarder forwards the logs to a remote collector. It's nil if forwarding is disabled.
+	forwarder *logForwarder
+	// tierLister is used to get the names of the Tiers included in JSON logs. It's nil if
+	// the Tiers are unknown.
+	tierLister crdlisters.TierLister
 }
 
-// logInfo will be set by retrieving info from packetin and register.
+// logInfo will be set by retrieving info from packetin and register. It's
+// used as the key of the logDeduplication map, so it must stay comparable.
 type logInfo struct {
 	tableName   string // name of the table sending packetin
 	npRef       string // Network Policy name reference for Antrea NetworkPolicy
-	disposition string // Allow/Drop of the rule sending packetin
+	disposition string // Allow/Drop of the rule sending packetin, AuditDrop/AuditReject for audited rules
 	ofPriority  string // openflow priority of the flow sending packetin
 	srcIP       string // source IP of the traffic logged
 	destIP      string // destination IP of the traffic logged
 	pktLength   uint16 // packet length of packetin
 	protocolStr string // protocol of the traffic logged
+	// The following fields are only set when the rule is found in the controller.
+	policyUID    string // UID of the Network Policy
+	tierPriority int32  // priority of the Tier of the Network Policy
+	ruleName     string // name of the rule
+	direction    string // Ingress/Egress direction of the rule
+	// srcPod and destPod are the Namespace/Name of the source and destination Pods, set for local Pods only.
+	srcPod  string
+	destPod string
+	// destPort and httpRequest are only set for the decisions made by the L7 proxy.
+	destPort    uint16
+	httpRequest string
+}
+
+// auditLogRecord is the JSON representation of an audit log.
+type auditLogRecord struct {
+	Timestamp       string `json:"timestamp"`
+	Node            string `json:"node"`
+	TableName       string `json:"tableName"`
+	Policy          string `json:"policy"`
+	PolicyUID       string `json:"policyUID,omitempty"`
+	Tier            string `json:"tier,omitempty"`
+	TierPriority    *int32 `json:"tierPriority,omitempty"`
+	RuleName        string `json:"ruleName,omitempty"`
+	Direction       string `json:"direction,omitempty"`
+	Disposition     string `json:"disposition"`
+	OFPriority      string `json:"ofPriority,omitempty"`
+	SourceIP        string `json:"sourceIP"`
+	SourcePod       string `json:"sourcePod,omitempty"`
+	DestinationIP   string `json:"destinationIP"`
+	DestinationPod  string `json:"destinationPod,omitempty"`
+	DestinationPort uint16 `json:"destinationPort,omitempty"`
+	Protocol        string `json:"protocol"`
+	PacketLength    uint16 `json:"packetLength,omitempty"`
+	HTTPRequest     string `json:"httpRequest,omitempty"`
+	PacketCount     int64  `json:"packetCount"`
+	Duration        string `json:"duration,omitempty"`
 }
 
 // logDedupRecord will be used as 1 sec buffer for log deduplication.
@@ -80,69 +141,173 @@ type logDedupRecord struct {
 // logRecordDedupMap includes a map of log buffers and a r/w mutex for accessing the map.
 type logRecordDedupMap struct {
 	logMutex sync.Mutex
-	logMap   map[string]*logDedupRecord
+	logMap   map[logInfo]*logDedupRecord
+}
+
+// textMessage returns the log of ob as space-separated fields. For the
+// decisions made by the L7 proxy, the rule name is logged in place of the
+// openflow priority, and the destination port and the request are logged in
+// place of the packet length and protocol.
+func (ob *logInfo) textMessage() string {
+	if ob.httpRequest != "" {
+		return fmt.Sprintf("%s %s %s %s %s %s %d %s %s", ob.tableName, ob.npRef, ob.disposition, ob.ruleName, ob.srcIP, ob.destIP, ob.destPort, ob.protocolStr, ob.httpRequest)
+	}
+	return fmt.Sprintf("%s %s %s %s %s %s %d %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.destIP, ob.pktLength, ob.protocolStr)
+}
+
+// formatLog returns the log of ob for count packets, the first one of which was received at initTime.
+func (l *AntreaPolicyLogger) formatLog(ob *logInfo, count int64, initTime time.Time) string {
+	if !l.jsonFormat {
+		logMsg := ob.textMessage()
+		if count == 1 {
+			return logMsg
+		}
+		return fmt.Sprintf("%s [%d packets in %s]", logMsg, count, time.Since(initTime))
+	}
+	record := auditLogRecord{
+		Timestamp:       initTime.Format(time.RFC3339Nano),
+		Node:            l.nodeName,
+		TableName:       ob.tableName,
+		Policy:          ob.npRef,
+		PolicyUID:       ob.policyUID,
+		RuleName:        ob.ruleName,
+		Direction:       ob.direction,
+		Disposition:     ob.disposition,
+		OFPriority:      ob.ofPriority,
+		SourceIP:        ob.srcIP,
+		SourcePod:       ob.srcPod,
+		DestinationIP:   ob.destIP,
+		DestinationPod:  ob.destPod,
+		DestinationPort: ob.destPort,
+		Protocol:        ob.protocolStr,
+		PacketLength:    ob.pktLength,
+		HTTPRequest:     ob.httpRequest,
+		PacketCount:     count,
+	}
+	if ob.policyUID != "" {
+		tierPriority := ob.tierPriority
+		record.TierPriority = &tierPriority
+		record.Tier = l.getTierName(tierPriority)
+	}
+	if count > 1 {
+		record.Duration = l.clock.Now().Sub(initTime).String()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		klog.ErrorS(err, "Failed to marshal audit log", "policy", ob.npRef)
+		return ob.textMessage()
+	}
+	return string(data)
+}
+
+// getTierName returns the name of the Tier with the given priority, or an empty string if
+// it's unknown. The Tiers are looked up when the logs are written rather than when the
+// packets are received, as the deduplicated packets are logged once.
+func (l *AntreaPolicyLogger) getTierName(priority int32) string {
+	if l.tierLister == nil {
+		return ""
+	}
+	tiers, err := l.tierLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list Tiers")
+		return ""
+	}
+	for _, tier := range tiers {
+		if tier.Spec.Priority == priority {
+			return tier.Name
+		}
+	}
+	return ""
 }
 
-// getLogKey returns the log record in logDeduplication map by logMsg.
-func (l *AntreaPolicyLogger) getLogKey(logMsg string) *logDedupRecord {
+// getLogKey returns the log record in logDeduplication map by ob.
+func (l *AntreaPolicyLogger) getLogKey(ob logInfo) *logDedupRecord {
 	l.logDeduplication.logMutex.Lock()
 	defer l.logDeduplication.logMutex.Unlock()
-	return l.logDeduplication.logMap[logMsg]
+	return l.logDeduplication.logMap[ob]
 }
 
 // logAfterTimer runs concurrently until buffer timer stops, then call terminateLogKey.
-func (l *AntreaPolicyLogger) logAfterTimer(logMsg string) {
-	ch := l.getLogKey(logMsg).bufferTimerCh
+func (l *AntreaPolicyLogger) logAfterTimer(ob logInfo) {
+	ch := l.getLogKey(ob).bufferTimerCh
 	<-ch
-	l.terminateLogKey(logMsg)
+	l.terminateLogKey(ob)
 }
 
-// terminateLogKey logs and deletes the log record in logDeduplication map by logMsg.
-func (l *AntreaPolicyLogger) terminateLogKey(logMsg string) {
+// terminateLogKey logs and deletes the log record in logDeduplication map by ob.
+func (l *AntreaPolicyLogger) terminateLogKey(ob logInfo) {
 	l.logDeduplication.logMutex.Lock()
 	defer l.logDeduplication.logMutex.Unlock()
-	logRecord := l.logDeduplication.logMap[logMsg]
-	if logRecord.count == 1 {
-		l.anpLogger.Printf(logMsg)
-	} else {
-		l.anpLogger.Printf("%s [%d packets in %s]", logMsg, logRecord.count, time.Since(logRecord.initTime))
-	}
-	delete(l.logDeduplication.logMap, logMsg)
+	logRecord := l.logDeduplication.logMap[ob]
+	l.anpLogger.Print(l.formatLog(&ob, logRecord.count, logRecord.initTime))
+	delete(l.logDeduplication.logMap, ob)
 }
 
-// updateLogKey initiates record or increases the count in logDeduplication corresponding to given logMsg.
-func (l *AntreaPolicyLogger) updateLogKey(logMsg string, bufferLength time.Duration) bool {
+// updateLogKey initiates record or increases the count in logDeduplication corresponding to given ob.
+func (l *AntreaPolicyLogger) updateLogKey(ob logInfo, bufferLength time.Duration) bool {
 	l.logDeduplication.logMutex.Lock()
 	defer l.logDeduplication.logMutex.Unlock()
-	_, exists := l.logDeduplication.logMap[logMsg]
+	_, exists := l.logDeduplication.logMap[ob]
 	if exists {
-		l.logDeduplication.logMap[logMsg].count++
+		l.logDeduplication.logMap[ob].count++
 	} else {
 		record := logDedupRecord{1, l.clock.Now(), l.clock.After(bufferLength)}
-		l.logDeduplication.logMap[logMsg] = &record
+		l.logDeduplication.logMap[ob] = &record
 	}
 	return exists
 }
 
 // LogDedupPacket logs information in ob based on disposition and duplication conditions.
 func (l *AntreaPolicyLogger) LogDedupPacket(ob *logInfo) {
-	// Deduplicate non-Allow packet log.
-	logMsg := fmt.Sprintf("%s %s %s %s %s %s %d %s", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.destIP, ob.pktLength, ob.protocolStr)
 	if ob.disposition == openflow.DispositionToString[openflow.DispositionAllow] {
-		l.anpLogger.Printf(logMsg)
+		l.anpLogger.Print(l.formatLog(ob, 1, l.clock.Now()))
 	} else {
+		// Deduplicate non-Allow packet log.
 		// Increase count if duplicated within 1 sec, create buffer otherwise.
-		exists := l.updateLogKey(logMsg, l.bufferLength)
+		exists := l.updateLogKey(*ob, l.bufferLength)
 		if !exists {
 			// Go routine for logging when buffer timer stops.
-			go l.logAfterTimer(logMsg)
+			go l.logAfterTimer(*ob)
 		}
 	}
 }
 
+// LogL7Decision logs the decision made by the L7 proxy on an HTTP request.
+func (l *AntreaPolicyLogger) LogL7Decision(decision *l7proxy.Decision, srcPod, destPod string) {
+	disposition := openflow.DispositionToString[openflow.DispositionDrop]
+	if decision.Allowed {
+		disposition = openflow.DispositionToString[openflow.DispositionAllow]
+	}
+	l.LogDedupPacket(&logInfo{
+		tableName:    l7ProxyLogTableName,
+		npRef:        decision.Rule.PolicyRef.ToString(),
+		disposition:  disposition,
+		srcIP:        decision.SrcIP.String(),
+		destIP:       decision.DstIP.String(),
+		protocolStr:  "HTTP",
+		policyUID:    string(decision.Rule.PolicyRef.UID),
+		tierPriority: decision.Rule.Priority.TierPriority,
+		ruleName:     decision.Rule.Name,
+		direction:    directionToString(decision.Rule.Direction),
+		srcPod:       srcPod,
+		destPod:      destPod,
+		destPort:     uint16(decision.DstPort),
+		httpRequest:  fmt.Sprintf("%s %s%s", decision.Method, decision.Host, decision.Path),
+	})
+}
+
+// directionToString returns the direction of a rule as it is logged.
+func directionToString(direction v1beta2.Direction) string {
+	if direction == v1beta2.DirectionOut {
+		return "Egress"
+	}
+	return "Ingress"
+}
+
 // newAntreaPolicyLogger is called while newing Antrea network policy agent controller.
 // Customize AntreaPolicyLogger specifically for Antrea Policies audit logging.
-func newAntreaPolicyLogger() (*AntreaPolicyLogger, error) {
+// tierLister can be nil, in which case the names of the Tiers are not logged.
+func newAntreaPolicyLogger(nodeName string, config agentconfig.AuditLoggingConfig, tierLister crdlisters.TierLister) (*AntreaPolicyLogger, error) {
 	logDir := filepath.Join(logdir.GetLogDir(), logfileSubdir)
 	logFile := filepath.Join(logDir, logfileName)
 	_, err := os.Stat(logDir)
@@ -164,9 +329,23 @@ func newAntreaPolicyLogger() (*AntreaPolicyLogger, error) {
 	antreaPolicyLogger := &AntreaPolicyLogger{
 		bufferLength:     time.Second,
 		clock:            &realClock{},
-		anpLogger:        log.New(logOutput, "", log.Ldate|log.Lmicroseconds),
-		logDeduplication: logRecordDedupMap{logMap: make(map[string]*logDedupRecord)},
+		logDeduplication: logRecordDedupMap{logMap: make(map[logInfo]*logDedupRecord)},
+		jsonFormat:       config.Format == AuditLogFormatJSON,
+		nodeName:         nodeName,
+		tierLister:       tierLister,
+	}
+	var output io.Writer = logOutput
+	if config.Forwarding.Address != "" {
+		antreaPolicyLogger.forwarder = newLogForwarder(config.Forwarding.Transport, config.Forwarding.Address, config.Forwarding.Syslog,
+			nodeName, config.Forwarding.BufferSize, antreaPolicyLogger.clock)
+		output = io.MultiWriter(logOutput, antreaPolicyLogger.forwarder)
+	}
+	// The JSON logs include their timestamp.
+	logFlags := log.Ldate | log.Lmicroseconds
+	if antreaPolicyLogger.jsonFormat {
+		logFlags = 0
 	}
-	klog.InfoS("Initialized Antrea-native Policy Logger for audit logging", "logFile", logFile)
+	antreaPolicyLogger.anpLogger = log.New(output, "", logFlags)
+	klog.InfoS("Initialized Antrea-native Policy Logger for audit logging", "logFile", logFile, "format", config.Format, "forwardingAddress", config.Forwarding.Address)
 	return antreaPolicyLogger, nil
 }
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,6 +27,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"antrea.io/antrea/pkg/agent/l7proxy"
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
 )
 
 const (
@@ -106,11 +114,19 @@ func newTestAntreaPolicyLogger(bufferLength time.Duration, clock Clock) (*Antrea
 		bufferLength:     bufferLength,
 		clock:            clock,
 		anpLogger:        log.New(mockAnpLogger, "", log.Ldate),
-		logDeduplication: logRecordDedupMap{logMap: make(map[string]*logDedupRecord)},
+		logDeduplication: logRecordDedupMap{logMap: make(map[logInfo]*logDedupRecord)},
+		nodeName:         "node1",
 	}
 	return antreaLogger, mockAnpLogger
 }
 
+func newTestJSONAntreaPolicyLogger(bufferLength time.Duration, clock Clock) (*AntreaPolicyLogger, *mockLogger) {
+	antreaLogger, mockAnpLogger := newTestAntreaPolicyLogger(bufferLength, clock)
+	antreaLogger.jsonFormat = true
+	antreaLogger.anpLogger = log.New(mockAnpLogger, "", 0)
+	return antreaLogger, mockAnpLogger
+}
+
 func newLogInfo(disposition string) (*logInfo, string) {
 	expected := fmt.Sprintf("AntreaPolicyIngressRule AntreaNetworkPolicy:default/test %s 0 0.0.0.0 1.1.1.1 60 TCP", disposition)
 	return &logInfo{
@@ -149,6 +165,57 @@ func TestAllowPacketLog(t *testing.T) {
 	assert.Contains(t, actual, expected)
 }
 
+func TestJSONPacketLog(t *testing.T) {
+	clock := NewVirtualClock(time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC))
+	defer clock.Stop()
+	antreaLogger, mockAnpLogger := newTestJSONAntreaPolicyLogger(testBufferLength, clock)
+	tierIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	tierIndexer.Add(&crdv1alpha1.Tier{
+		ObjectMeta: metav1.ObjectMeta{Name: "application"},
+		Spec:       crdv1alpha1.TierSpec{Priority: 250},
+	})
+	antreaLogger.tierLister = crdlisters.NewTierLister(tierIndexer)
+	ob, _ := newLogInfo("Drop")
+	ob.policyUID = "uid1"
+	ob.tierPriority = 250
+	ob.ruleName = "drop-web"
+	ob.direction = "Ingress"
+	ob.destPod = "default/web"
+
+	antreaLogger.LogDedupPacket(ob)
+	clock.Advance(50 * time.Millisecond)
+	antreaLogger.LogDedupPacket(ob)
+	clock.Advance(60 * time.Millisecond)
+	actual := <-mockAnpLogger.logged
+	expected := `{"timestamp":"2021-10-01T08:00:00Z","node":"node1","tableName":"AntreaPolicyIngressRule",` +
+		`"policy":"AntreaNetworkPolicy:default/test","policyUID":"uid1","tier":"application","tierPriority":250,"ruleName":"drop-web",` +
+		`"direction":"Ingress","disposition":"Drop","ofPriority":"0","sourceIP":"0.0.0.0","destinationIP":"1.1.1.1",` +
+		`"destinationPod":"default/web","protocol":"TCP","packetLength":60,"packetCount":2,"duration":"110ms"}` + "\n"
+	assert.Equal(t, expected, actual)
+}
+
+func TestL7DecisionLog(t *testing.T) {
+	antreaLogger, mockAnpLogger := newTestAntreaPolicyLogger(testBufferLength, &realClock{})
+	decision := &l7proxy.Decision{
+		Rule: &l7proxy.Rule{
+			Name:      "allow-get",
+			PolicyRef: &v1beta2.NetworkPolicyReference{Type: v1beta2.AntreaNetworkPolicy, Namespace: "default", Name: "test", UID: "uid1"},
+			Direction: v1beta2.DirectionIn,
+		},
+		Allowed: true,
+		SrcIP:   net.ParseIP("10.10.0.1"),
+		DstIP:   net.ParseIP("10.10.1.1"),
+		DstPort: 8080,
+		Method:  "GET",
+		Host:    "web",
+		Path:    "/api",
+	}
+
+	antreaLogger.LogL7Decision(decision, "default/client", "default/web")
+	actual := <-mockAnpLogger.logged
+	assert.Contains(t, actual, "L7Proxy AntreaNetworkPolicy:default/test Allow allow-get 10.10.0.1 10.10.1.1 8080 HTTP GET web/api")
+}
+
 func TestDropPacketLog(t *testing.T) {
 	antreaLogger, mockAnpLogger := newTestAntreaPolicyLogger(testBufferLength, &realClock{})
 	ob, expected := newLogInfo("Drop")
@@ -169,6 +236,17 @@ func TestDropPacketDedupLog(t *testing.T) {
 	assert.Contains(t, actual, expected)
 }
 
+func TestAuditPacketDedupLog(t *testing.T) {
+	antreaLogger, mockAnpLogger := newTestAntreaPolicyLogger(testBufferLength, &realClock{})
+	ob, expected := newLogInfo("AuditDrop")
+	// The traffic allowed by an audited rule is deduplicated like the dropped traffic.
+	expected = expectedLogWithCount(expected, 2)
+
+	go sendMultiplePackets(antreaLogger, ob, 2, time.Millisecond)
+	actual := <-mockAnpLogger.logged
+	assert.Contains(t, actual, expected)
+}
+
 // TestDropPacketMultiDedupLog sends 3 packets, with a 60ms interval. The test
 // is meant to verify that any given packet is never buffered for more than the
 // configured bufferLength (100ms for this test). To avoid flakiness issues
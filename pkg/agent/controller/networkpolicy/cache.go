@@ -92,6 +92,10 @@ type rule struct {
 	SourceRef *v1beta.NetworkPolicyReference
 	// EnableLogging is a boolean indicating whether logging is required for Antrea Policies. Always false for K8s NetworkPolicy.
 	EnableLogging bool
+	// L7Protocols is the list of L7 protocol matches of this rule. Empty if the rule only matches L3/L4 traffic.
+	L7Protocols []v1beta.L7Protocol
+	// Audit is a boolean indicating whether the Drop or Reject action of this rule is only logged instead of enforced.
+	Audit bool
 }
 
 // hashRule calculates a string based on the rule's content.
@@ -602,6 +606,8 @@ func toRule(r *v1beta.NetworkPolicyRule, policy *v1beta.NetworkPolicy, maxPriori
 		PolicyUID:       policy.UID,
 		SourceRef:       policy.SourceRef,
 		EnableLogging:   r.EnableLogging,
+		L7Protocols:     r.L7Protocols,
+		Audit:           r.Audit,
 	}
 	rule.ID = hashRule(rule)
 	rule.PolicyName = policy.Name
@@ -750,6 +756,16 @@ func (c *ruleCache) deleteNetworkPolicyLocked(uid string) {
 	metrics.NetworkPolicyCount.Dec()
 }
 
+// getRulePolicy returns the original NetworkPolicy of a rule, or nil if the
+// rule is not found.
+func (c *ruleCache) getRulePolicy(ruleID string) *v1beta.NetworkPolicyReference {
+	obj, exists, _ := c.rules.GetByKey(ruleID)
+	if !exists {
+		return nil
+	}
+	return obj.(*rule).SourceRef
+}
+
 // GetCompletedRule constructs a *CompletedRule for the provided ruleID.
 // If the rule is not effective or not realizable due to missing group data, the return value will indicate it.
 // A rule is considered effective when any of its AppliedToGroups can be populated.
@@ -21,8 +21,10 @@ import (
 	"net"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"antrea.io/libOpenflow/protocol"
@@ -36,6 +38,7 @@ import (
 	"antrea.io/antrea/pkg/agent/openflow"
 	"antrea.io/antrea/pkg/agent/types"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
+	"antrea.io/antrea/pkg/querier"
 	utilsets "antrea.io/antrea/pkg/util/sets"
 )
 
@@ -118,6 +121,16 @@ type ruleSyncTracker struct {
 }
 
 type fqdnController struct {
+	// The counters are accessed atomically and must be kept at the beginning
+	// of the struct to be 64-bit aligned on 32-bit platforms.
+	// dnsPacketInCount is the number of DNS response packets intercepted.
+	dnsPacketInCount uint64
+	// parseFailureCount is the number of DNS responses failed to be parsed.
+	parseFailureCount uint64
+	// ruleResyncCount is the number of times rules were resynced because of
+	// DNS responses.
+	ruleResyncCount uint64
+
 	// ofClient is the Openflow interface.
 	ofClient openflow.Client
 	// dnsServerAddr stores the coreDNS server address, or the user provided DNS server address.
@@ -129,6 +142,14 @@ type fqdnController struct {
 	ruleSyncTracker *ruleSyncTracker
 	// FQDN names this controller is tracking, with their corresponding dnsMeta.
 	dnsEntryCache map[string]dnsMeta
+	// dnsCacheFile is the file dnsEntryCache is persisted to, so that it can be
+	// restored after agent restarts. Persistence is disabled if it's empty.
+	dnsCacheFile string
+	// dnsCacheDirty indicates whether dnsEntryCache has changed since it was last
+	// persisted. Protected by fqdnSelectorMutex.
+	dnsCacheDirty bool
+	// expiredFQDNs are the FQDNs restored from dnsCacheFile whose TTL has expired.
+	expiredFQDNs []string
 	// FQDN names that needs to be re-queried after their respective TTLs.
 	dnsQueryQueue workqueue.RateLimitingInterface
 	// idAllocator provides interfaces to allocateForRule and release uint32 id.
@@ -150,7 +171,7 @@ type fqdnController struct {
 	selectorItemToRuleIDs map[fqdnSelectorItem]sets.String
 }
 
-func newFQDNController(client openflow.Client, allocator *idAllocator, dnsServerOverride string, dirtyRuleHandler func(string)) (*fqdnController, error) {
+func newFQDNController(client openflow.Client, allocator *idAllocator, dnsServerOverride string, dnsCacheFile string, dirtyRuleHandler func(string)) (*fqdnController, error) {
 	controller := &fqdnController{
 		ofClient:               client,
 		dirtyRuleHandler:       dirtyRuleHandler,
@@ -158,6 +179,7 @@ func newFQDNController(client openflow.Client, allocator *idAllocator, dnsServer
 		idAllocator:            allocator,
 		dnsQueryQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "fqdn"),
 		dnsEntryCache:          map[string]dnsMeta{},
+		dnsCacheFile:           dnsCacheFile,
 		fqdnRuleToSelectedPods: map[string]sets.Int32{},
 		fqdnToSelectorItem:     map[string]map[fqdnSelectorItem]struct{}{},
 		selectorItemToFQDN:     map[fqdnSelectorItem]sets.String{},
@@ -181,6 +203,9 @@ func newFQDNController(client openflow.Client, allocator *idAllocator, dnsServer
 			klog.InfoS("Using kube-dns Service for DNS requests", "dnsServer", controller.dnsServerAddr)
 		}
 	}
+	if controller.dnsCacheFile != "" {
+		controller.restoreDNSCache()
+	}
 	return controller, nil
 }
 
@@ -191,14 +216,16 @@ func fqdnToSelectorItem(fqdn string) fqdnSelectorItem {
 			matchRegex: toRegex(fqdn),
 		}
 	}
-	return fqdnSelectorItem{matchName: fqdn}
+	return fqdnSelectorItem{matchName: normalizeFQDN(strings.TrimSpace(fqdn))}
 }
 
 // toRegex converts a FQDN wildcard expression to the regex pattern used to
-// match FQDNs against.
+// match FQDNs against. A "*" can appear anywhere in the expression, any number
+// of times, and matches any sequence of characters, including dots. For
+// example, "*.example.com" matches "www.example.com" and "a.b.example.com",
+// and "api-*.example.com" matches "api-v1.example.com".
 func toRegex(pattern string) string {
-	pattern = strings.TrimSpace(pattern)
-	pattern = strings.ToLower(pattern)
+	pattern = normalizeFQDN(strings.TrimSpace(pattern))
 
 	// Replace "." as a regex literal, since it's recogized as a separator in FQDN.
 	pattern = strings.Replace(pattern, ".", "[.]", -1)
@@ -252,6 +279,41 @@ func (f *fqdnController) getIPsForFQDNSelectors(fqdns []string) []net.IP {
 	return matchedIPs
 }
 
+// getFQDNCache returns the FQDNs in dnsEntryCache, sorted by name, with the IDs
+// of the rules selecting them. Policies of the rules are not filled in.
+func (f *fqdnController) getFQDNCache() []querier.FQDNCacheEntry {
+	f.fqdnSelectorMutex.Lock()
+	defer f.fqdnSelectorMutex.Unlock()
+	entries := make([]querier.FQDNCacheEntry, 0, len(f.dnsEntryCache))
+	for fqdn, meta := range f.dnsEntryCache {
+		entry := querier.FQDNCacheEntry{
+			FQDN:           fqdn,
+			ExpirationTime: meta.expirationTime,
+			RuleIDs:        f.getRulesForFQDNs([]string{fqdn}).List(),
+		}
+		for _, ip := range meta.responseIPs {
+			entry.IPs = append(entry.IPs, ip)
+		}
+		sort.Slice(entry.IPs, func(i, j int) bool {
+			return entry.IPs[i].String() < entry.IPs[j].String()
+		})
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FQDN < entries[j].FQDN
+	})
+	return entries
+}
+
+// getFQDNStats returns the counters of the fqdnController.
+func (f *fqdnController) getFQDNStats() querier.FQDNStats {
+	return querier.FQDNStats{
+		DNSPacketIns:  atomic.LoadUint64(&f.dnsPacketInCount),
+		ParseFailures: atomic.LoadUint64(&f.parseFailureCount),
+		RulesResynced: atomic.LoadUint64(&f.ruleResyncCount),
+	}
+}
+
 // addFQDNRule adds a new FQDN rule to fqdnSelectorItem mapping, as well as the OFAddresses of
 // Pods selected by the FQDN rule.
 func (f *fqdnController) addFQDNRule(ruleID string, fqdns []string, podOFAddrs sets.Int32) error {
@@ -354,6 +416,7 @@ func (f *fqdnController) cleanupFQDNSelectorItem(fs fqdnSelectorItem) {
 				// tracked by the fqdnController.
 				delete(f.fqdnToSelectorItem, fqdn)
 				delete(f.dnsEntryCache, fqdn)
+				f.dnsCacheDirty = true
 			}
 			delete(selectors, fs)
 		}
@@ -390,29 +453,52 @@ func (f *fqdnController) deleteRuleSelectedPods(ruleID string) error {
 	return nil
 }
 
+// onDNSResponse handles the name resolution results of a DNS response. fqdns
+// are the names of the CNAME chain of the response, starting with the queried
+// name, all of which are resolved to the responseIPs.
 func (f *fqdnController) onDNSResponse(
-	fqdn string,
+	fqdns []string,
 	responseIPs map[string]net.IP,
 	lowestTTL uint32,
 	lookupTime time.Time,
 	waitCh chan error,
 ) {
 	if len(responseIPs) == 0 {
-		klog.V(4).InfoS("FQDN was not resolved to any addresses, skip updating DNS cache", "fqdn", fqdn)
+		klog.V(4).InfoS("FQDN was not resolved to any addresses, skip updating DNS cache", "fqdns", fqdns)
 		if waitCh != nil {
 			waitCh <- nil
 		}
 		return
 	}
+	recordTTL := lookupTime.Add(time.Duration(lowestTTL) * time.Second)
+
+	f.fqdnSelectorMutex.Lock()
+	defer f.fqdnSelectorMutex.Unlock()
+	// FQDNs whose IP addresses have been updated by this response.
+	updatedFQDNs := sets.NewString()
+	for _, fqdn := range fqdns {
+		// Each FQDN gets its own copy of the IPs, as unexpired IPs previously
+		// cached for the FQDN are merged into it.
+		fqdnIPs := make(map[string]net.IP, len(responseIPs))
+		for ipStr, ip := range responseIPs {
+			fqdnIPs[ipStr] = ip
+		}
+		if f.updateDNSEntryCache(fqdn, fqdnIPs, recordTTL) {
+			updatedFQDNs.Insert(fqdn)
+		}
+	}
+	f.syncDirtyRules(fqdns, updatedFQDNs, waitCh)
+}
+
+// updateDNSEntryCache updates the cached IP addresses of a FQDN with the ones in
+// a DNS response, and returns whether the addresses of the FQDN have changed.
+// fqdnSelectorMutex must have been acquired by the caller.
+func (f *fqdnController) updateDNSEntryCache(fqdn string, responseIPs map[string]net.IP, recordTTL time.Time) bool {
 	// mustCacheResponse is only true if the FQDN is already tracked by this
 	// controller, or it matches at least one fqdnSelectorItem from the policy rules.
 	// addressUpdate is only true if there has been an update in IP addresses
 	// corresponded with the FQDN.
 	mustCacheResponse, addressUpdate := false, false
-	recordTTL := lookupTime.Add(time.Duration(lowestTTL) * time.Second)
-
-	f.fqdnSelectorMutex.Lock()
-	defer f.fqdnSelectorMutex.Unlock()
 	oldDNSMeta, exist := f.dnsEntryCache[fqdn]
 	if exist {
 		mustCacheResponse = true
@@ -452,64 +538,73 @@ func (f *fqdnController) onDNSResponse(
 			responseIPs:    responseIPs,
 		}
 		f.dnsQueryQueue.AddAfter(fqdn, recordTTL.Sub(time.Now()))
+		f.dnsCacheDirty = true
 	}
-	f.syncDirtyRules(fqdn, waitCh, addressUpdate)
+	return addressUpdate
 }
 
 // onDNSResponseMsg handles a DNS response message intercepted.
 func (f *fqdnController) onDNSResponseMsg(dnsMsg *dns.Msg, lookupTime time.Time, waitCh chan error) {
-	fqdn, responseIPs, lowestTTL, err := f.parseDNSResponse(dnsMsg)
+	fqdns, responseIPs, lowestTTL, err := f.parseDNSResponse(dnsMsg)
 	if err != nil {
 		klog.V(2).InfoS("Failed to parse DNS response")
+		atomic.AddUint64(&f.parseFailureCount, 1)
 		if waitCh != nil {
 			waitCh <- fmt.Errorf("failed to parse DNS response: %v", err)
 		}
 		return
 	}
-	f.onDNSResponse(fqdn, responseIPs, lowestTTL, lookupTime, waitCh)
+	f.onDNSResponse(fqdns, responseIPs, lowestTTL, lookupTime, waitCh)
 }
 
-// syncDirtyRules triggers rule syncs for rules that are affected by the FQDN of DNS response
+// getRulesForFQDNs returns the IDs of the rules that select any of the FQDNs.
+// fqdnSelectorMutex must have been acquired by the caller.
+func (f *fqdnController) getRulesForFQDNs(fqdns []string) sets.String {
+	ruleIDs := sets.NewString()
+	for _, fqdn := range fqdns {
+		for selectorItem := range f.fqdnToSelectorItem[fqdn] {
+			utilsets.MergeString(ruleIDs, f.selectorItemToRuleIDs[selectorItem])
+		}
+	}
+	return ruleIDs
+}
+
+// syncDirtyRules triggers rule syncs for rules that are affected by the FQDNs of DNS response
 // event. Note that if the query is initiated by the client Pod (not by the fqdnController, in
-// which case waitCh will not be nil), even when addressUpdate is false, the function will still
-// verify if there was any previous rule realization error for the dirty rules. If so, it will
-// wait for another attempt of realization of these rules, before forwarding the response to the
-// original client.
-func (f *fqdnController) syncDirtyRules(fqdn string, waitCh chan error, addressUpdate bool) {
+// which case waitCh will not be nil), even when no address of the FQDNs was updated, the
+// function will still verify if there was any previous rule realization error for the dirty
+// rules. If so, it will wait for another attempt of realization of these rules, before
+// forwarding the response to the original client.
+func (f *fqdnController) syncDirtyRules(fqdns []string, updatedFQDNs sets.String, waitCh chan error) {
+	dirtyRules := f.getRulesForFQDNs(updatedFQDNs.List())
 	if waitCh == nil {
-		if addressUpdate {
-			for selectorItem := range f.fqdnToSelectorItem[fqdn] {
-				for ruleID := range f.selectorItemToRuleIDs[selectorItem] {
-					klog.V(4).InfoS("Reconciling dirty rule", "ruleID", ruleID)
-					f.dirtyRuleHandler(ruleID)
-				}
-			}
+		for ruleID := range dirtyRules {
+			klog.V(4).InfoS("Reconciling dirty rule", "ruleID", ruleID)
+			f.dirtyRuleHandler(ruleID)
 		}
+		atomic.AddUint64(&f.ruleResyncCount, uint64(len(dirtyRules)))
+		return
+	}
+	if len(updatedFQDNs) < len(fqdns) {
+		f.ruleSyncTracker.mutex.Lock()
+		// For the FQDNs without address update, if rules selecting them were all
+		// previously realized successfully, then there will be no dirty rules left
+		// to be synced. On the contrary, if some rules that select them are still in
+		// the dirtyRules set of the ruleSyncTracker, then those rules should be retried
+		// for reconciliation as well, and packetOut shall be blocked.
+		utilsets.MergeString(dirtyRules, f.ruleSyncTracker.dirtyRules.Intersection(f.getRulesForFQDNs(fqdns)))
+		f.ruleSyncTracker.mutex.Unlock()
+	}
+	if len(dirtyRules) > 0 {
+		klog.V(4).InfoS("Dirty rules blocking packetOut", "dirtyRules", dirtyRules)
+		f.ruleSyncTracker.subscribe(waitCh, dirtyRules)
+		for r := range dirtyRules {
+			f.dirtyRuleHandler(r)
+		}
+		atomic.AddUint64(&f.ruleResyncCount, uint64(len(dirtyRules)))
 	} else {
-		dirtyRules := sets.NewString()
-		for selectorItem := range f.fqdnToSelectorItem[fqdn] {
-			utilsets.MergeString(dirtyRules, f.selectorItemToRuleIDs[selectorItem])
-		}
-		if !addressUpdate {
-			f.ruleSyncTracker.mutex.Lock()
-			defer f.ruleSyncTracker.mutex.Unlock()
-			// If there is no address update for this FQDN, and rules selecting this FQDN
-			// were all previously realized successfully, then there will be no dirty rules
-			// left to be synced. On the contrary, if some rules that select this FQDN are
-			// still in the dirtyRules set of the ruleSyncTracker, then only those rules
-			// should be retried for reconciliation, and packetOut shall be blocked.
-			dirtyRules = f.ruleSyncTracker.dirtyRules.Intersection(dirtyRules)
-		}
-		if len(dirtyRules) > 0 {
-			klog.V(4).InfoS("Dirty rules blocking packetOut", "dirtyRules", dirtyRules)
-			f.ruleSyncTracker.subscribe(waitCh, dirtyRules)
-			for r := range dirtyRules {
-				f.dirtyRuleHandler(r)
-			}
-		} else {
-			klog.V(4).InfoS("Rules are already synced for this FQDN")
-			waitCh <- nil
-		}
+		klog.V(4).InfoS("Rules are already synced for this FQDN")
+		waitCh <- nil
 	}
 }
 
@@ -573,15 +668,46 @@ func (f *fqdnController) runRuleSyncTracker(stopCh <-chan struct{}) {
 	f.ruleSyncTracker.Run(stopCh)
 }
 
-// parseDNSResponse returns the FQDN, IP query result and lowest applicable TTL of a DNS response.
-func (f *fqdnController) parseDNSResponse(msg *dns.Msg) (string, map[string]net.IP, uint32, error) {
+// parseDNSResponse returns the FQDNs, IP query result and lowest applicable TTL of a DNS response.
+// The returned FQDNs are the names of the CNAME chain of the response, starting with the queried
+// name and ending with the canonical name, which the IPs in the response belong to.
+func (f *fqdnController) parseDNSResponse(msg *dns.Msg) ([]string, map[string]net.IP, uint32, error) {
 	if len(msg.Question) == 0 {
-		return "", nil, 0, fmt.Errorf("invalid DNS message")
+		return nil, nil, 0, fmt.Errorf("invalid DNS message")
 	}
-	fqdn := strings.ToLower(msg.Question[0].Name)
+	fqdn := normalizeFQDN(msg.Question[0].Name)
 	lowestTTL := uint32(math.MaxUint32) // a TTL must exist in the RRs
+	cnames := map[string]*dns.CNAME{}
+	for _, ans := range msg.Answer {
+		if r, ok := ans.(*dns.CNAME); ok {
+			cnames[normalizeFQDN(r.Hdr.Name)] = r
+		}
+	}
+	// Follow the CNAME chain from the queried name. The TTLs of the CNAME records
+	// apply to the mapping from the queried name to the IPs as well.
+	fqdns := []string{fqdn}
+	chain := sets.NewString(fqdn)
+	for name := fqdn; ; {
+		r, ok := cnames[name]
+		if !ok {
+			break
+		}
+		name = normalizeFQDN(r.Target)
+		if chain.Has(name) {
+			return nil, nil, 0, fmt.Errorf("CNAME loop detected for %s", fqdn)
+		}
+		fqdns = append(fqdns, name)
+		chain.Insert(name)
+		if r.Hdr.Ttl < lowestTTL {
+			lowestTTL = r.Hdr.Ttl
+		}
+	}
 	responseIPs := map[string]net.IP{}
 	for _, ans := range msg.Answer {
+		// Ignore the records that don't belong to the CNAME chain.
+		if !chain.Has(normalizeFQDN(ans.Header().Name)) {
+			continue
+		}
 		switch r := ans.(type) {
 		case *dns.A:
 			if f.ofClient.IsIPv4Enabled() {
@@ -600,12 +726,15 @@ func (f *fqdnController) parseDNSResponse(msg *dns.Msg) (string, map[string]net.
 		}
 	}
 	if len(responseIPs) > 0 {
-		klog.V(4).InfoS("Received DNS Packet with valid Answer", "IPs", responseIPs, "TTL", lowestTTL)
+		klog.V(4).InfoS("Received DNS Packet with valid Answer", "FQDNs", fqdns, "IPs", responseIPs, "TTL", lowestTTL)
 	}
-	if strings.HasSuffix(fqdn, ".") {
-		fqdn = fqdn[:len(fqdn)-1]
-	}
-	return fqdn, responseIPs, lowestTTL, nil
+	return fqdns, responseIPs, lowestTTL, nil
+}
+
+// normalizeFQDN converts a domain name to its lower case form without the
+// trailing dot, which is how FQDNs are tracked by the fqdnController.
+func normalizeFQDN(fqdn string) string {
+	return strings.TrimSuffix(strings.ToLower(fqdn), ".")
 }
 
 func (f *fqdnController) worker() {
@@ -620,6 +749,11 @@ func (f *fqdnController) processNextWorkItem() bool {
 	}
 	defer f.dnsQueryQueue.Done(key)
 
+	if !f.isFQDNTracked(key.(string)) {
+		klog.V(2).InfoS("FQDN is no longer selected by any rule, skip querying it", "fqdn", key)
+		f.dnsQueryQueue.Forget(key)
+		return true
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), dnsRequestTimeout)
 	defer cancel()
 	err := f.makeDNSRequest(ctx, key.(string))
@@ -627,6 +761,22 @@ func (f *fqdnController) processNextWorkItem() bool {
 	return true
 }
 
+// isFQDNTracked returns whether a FQDN is selected by any fqdnSelectorItem. If
+// not, it also removes the FQDN from dnsEntryCache, in case it's an entry restored
+// from the DNS cache file that is not selected by any rule after restart.
+func (f *fqdnController) isFQDNTracked(fqdn string) bool {
+	f.fqdnSelectorMutex.Lock()
+	defer f.fqdnSelectorMutex.Unlock()
+	if _, ok := f.fqdnToSelectorItem[fqdn]; ok {
+		return true
+	}
+	if _, ok := f.dnsEntryCache[fqdn]; ok {
+		delete(f.dnsEntryCache, fqdn)
+		f.dnsCacheDirty = true
+	}
+	return false
+}
+
 func (f *fqdnController) handleErr(err error, key interface{}) {
 	if err == nil {
 		f.dnsQueryQueue.Forget(key)
@@ -653,7 +803,7 @@ func (f *fqdnController) lookupIP(ctx context.Context, fqdn string) error {
 	if f.ofClient.IsIPv4Enabled() {
 		lookupTime := time.Now()
 		if ips, err := resolver.LookupIP(ctx, "ip4", fqdn); err == nil {
-			f.onDNSResponse(fqdn, makeResponseIPs(ips), defaultTTL, lookupTime, nil)
+			f.onDNSResponse([]string{fqdn}, makeResponseIPs(ips), defaultTTL, lookupTime, nil)
 		} else {
 			v4ok = false
 		}
@@ -661,7 +811,7 @@ func (f *fqdnController) lookupIP(ctx context.Context, fqdn string) error {
 	if f.ofClient.IsIPv6Enabled() {
 		lookupTime := time.Now()
 		if ips, err := resolver.LookupIP(ctx, "ip6", fqdn); err == nil {
-			f.onDNSResponse(fqdn, makeResponseIPs(ips), defaultTTL, lookupTime, nil)
+			f.onDNSResponse([]string{fqdn}, makeResponseIPs(ips), defaultTTL, lookupTime, nil)
 		} else {
 			v6ok = false
 		}
@@ -740,11 +890,13 @@ func (f *fqdnController) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 
 func (f *fqdnController) handlePacketIn(pktIn *ofctrl.PacketIn) error {
 	klog.V(4).InfoS("Received a packetIn for DNS response")
+	atomic.AddUint64(&f.dnsPacketInCount, 1)
 	waitCh := make(chan error, 1)
 	handleUDPData := func(dnsPkt *protocol.UDP) {
 		dnsData := dnsPkt.Data
 		dnsMsg := dns.Msg{}
 		if err := dnsMsg.Unpack(dnsData); err != nil {
+			atomic.AddUint64(&f.parseFailureCount, 1)
 			waitCh <- err
 			return
 		}
@@ -0,0 +1,168 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// dnsCacheSyncInterval is the interval at which the DNS cache is persisted to
+// the DNS cache file if it has changed.
+const dnsCacheSyncInterval = 10 * time.Second
+
+// dnsCacheEntry is the persisted form of the dnsMeta of a FQDN.
+type dnsCacheEntry struct {
+	FQDN           string    `json:"fqdn"`
+	IPs            []string  `json:"ips"`
+	ExpirationTime time.Time `json:"expirationTime"`
+}
+
+// restoreDNSCache restores dnsEntryCache from the DNS cache file persisted by
+// a previous instance of the fqdnController. It must be called before any FQDN
+// rule is added, so that the rules are realized with the cached IPs instead of
+// dropping the traffic of existing connections until the FQDNs are resolved
+// again. The unexpired entries are re-queried after their TTLs, like the ones
+// received at runtime, while the expired entries are recorded in expiredFQDNs
+// and re-resolved when the controller starts.
+func (f *fqdnController) restoreDNSCache() {
+	data, err := ioutil.ReadFile(f.dnsCacheFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.ErrorS(err, "Failed to read DNS cache file", "file", f.dnsCacheFile)
+		}
+		return
+	}
+	var entries []dnsCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		klog.ErrorS(err, "Failed to decode DNS cache file, ignoring it", "file", f.dnsCacheFile)
+		return
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		responseIPs := map[string]net.IP{}
+		for _, ipStr := range entry.IPs {
+			if ip := net.ParseIP(ipStr); ip != nil {
+				responseIPs[ip.String()] = ip
+			}
+		}
+		if len(responseIPs) == 0 {
+			continue
+		}
+		f.dnsEntryCache[entry.FQDN] = dnsMeta{
+			expirationTime: entry.ExpirationTime,
+			responseIPs:    responseIPs,
+		}
+		if entry.ExpirationTime.After(now) {
+			f.dnsQueryQueue.AddAfter(entry.FQDN, entry.ExpirationTime.Sub(now))
+		} else {
+			f.expiredFQDNs = append(f.expiredFQDNs, entry.FQDN)
+		}
+	}
+	klog.InfoS("Restored DNS cache", "file", f.dnsCacheFile, "entries", len(f.dnsEntryCache), "expiredEntries", len(f.expiredFQDNs))
+}
+
+// saveDNSCache persists dnsEntryCache to the DNS cache file if it has changed
+// since the last time it was persisted.
+func (f *fqdnController) saveDNSCache() error {
+	f.fqdnSelectorMutex.Lock()
+	if !f.dnsCacheDirty {
+		f.fqdnSelectorMutex.Unlock()
+		return nil
+	}
+	entries := make([]dnsCacheEntry, 0, len(f.dnsEntryCache))
+	for fqdn, meta := range f.dnsEntryCache {
+		entry := dnsCacheEntry{FQDN: fqdn, ExpirationTime: meta.expirationTime}
+		for ipStr := range meta.responseIPs {
+			entry.IPs = append(entry.IPs, ipStr)
+		}
+		sort.Strings(entry.IPs)
+		entries = append(entries, entry)
+	}
+	f.dnsCacheDirty = false
+	f.fqdnSelectorMutex.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FQDN < entries[j].FQDN
+	})
+	if err := f.writeDNSCacheFile(entries); err != nil {
+		// Persist it again at the next attempt.
+		f.fqdnSelectorMutex.Lock()
+		f.dnsCacheDirty = true
+		f.fqdnSelectorMutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// writeDNSCacheFile writes the DNS cache entries to a temporary file first and
+// then renames it to the DNS cache file, so that the file is never left
+// partially written if the agent is stopped in the middle.
+func (f *fqdnController) writeDNSCacheFile(entries []dnsCacheEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("error encoding DNS cache: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.dnsCacheFile), 0755); err != nil {
+		return fmt.Errorf("error creating directory for DNS cache file: %v", err)
+	}
+	tmpFile := f.dnsCacheFile + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("error writing DNS cache file: %v", err)
+	}
+	if err := os.Rename(tmpFile, f.dnsCacheFile); err != nil {
+		return fmt.Errorf("error renaming DNS cache file: %v", err)
+	}
+	return nil
+}
+
+// resolveExpiredDNSEntries proactively resolves the restored FQDNs whose TTL
+// has expired. The FQDNs failed to be resolved are retried by the DNS query
+// workers.
+func (f *fqdnController) resolveExpiredDNSEntries() {
+	for _, fqdn := range f.expiredFQDNs {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsRequestTimeout)
+		if err := f.lookupIP(ctx, fqdn); err != nil {
+			klog.ErrorS(err, "Failed to resolve restored FQDN, retrying", "fqdn", fqdn)
+			f.dnsQueryQueue.AddRateLimited(fqdn)
+		}
+		cancel()
+	}
+	f.expiredFQDNs = nil
+}
+
+// runDNSCacheSyncer re-resolves the expired entries restored from the DNS cache
+// file, then persists the DNS cache periodically until stopCh is closed.
+func (f *fqdnController) runDNSCacheSyncer(stopCh <-chan struct{}) {
+	f.resolveExpiredDNSEntries()
+	wait.Until(func() {
+		if err := f.saveDNSCache(); err != nil {
+			klog.ErrorS(err, "Failed to persist DNS cache", "file", f.dnsCacheFile)
+		}
+	}, dnsCacheSyncInterval, stopCh)
+	if err := f.saveDNSCache(); err != nil {
+		klog.ErrorS(err, "Failed to persist DNS cache", "file", f.dnsCacheFile)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	openflowtest "antrea.io/antrea/pkg/agent/openflow/testing"
+)
+
+func newFQDNControllerWithCacheFile(t *testing.T, controller *gomock.Controller, dnsCacheFile string) (*fqdnController, *openflowtest.MockClient) {
+	mockOFClient := openflowtest.NewMockClient(controller)
+	mockOFClient.EXPECT().IsIPv4Enabled().Return(true).AnyTimes()
+	mockOFClient.EXPECT().IsIPv6Enabled().Return(false).AnyTimes()
+	mockOFClient.EXPECT().NewDNSpacketInConjunction(gomock.Any()).Return(nil).AnyTimes()
+	f, err := newFQDNController(mockOFClient, newIDAllocator(testAsyncDeleteInterval), "8.8.8.8:53", dnsCacheFile, func(rule string) {})
+	require.NoError(t, err)
+	return f, mockOFClient
+}
+
+func TestSaveAndRestoreDNSCache(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	dnsCacheFile := filepath.Join(t.TempDir(), "fqdn-dns-cache.json")
+
+	now := time.Now()
+	f, _ := newFQDNControllerWithCacheFile(t, controller, dnsCacheFile)
+	f.dnsEntryCache = map[string]dnsMeta{
+		"www.antrea.io": {
+			expirationTime: now.Add(time.Hour),
+			responseIPs:    map[string]net.IP{"1.1.1.1": net.ParseIP("1.1.1.1"), "1.1.1.2": net.ParseIP("1.1.1.2")},
+		},
+		"expired.antrea.io": {
+			expirationTime: now.Add(-time.Minute),
+			responseIPs:    map[string]net.IP{"2.2.2.2": net.ParseIP("2.2.2.2")},
+		},
+	}
+	// Nothing is persisted if the cache hasn't changed.
+	require.NoError(t, f.saveDNSCache())
+	assert.NoFileExists(t, dnsCacheFile)
+	f.dnsCacheDirty = true
+	require.NoError(t, f.saveDNSCache())
+	assert.False(t, f.dnsCacheDirty)
+	assert.FileExists(t, dnsCacheFile)
+
+	restored, c := newFQDNControllerWithCacheFile(t, controller, dnsCacheFile)
+	require.Len(t, restored.dnsEntryCache, 2)
+	for fqdn, meta := range f.dnsEntryCache {
+		restoredMeta := restored.dnsEntryCache[fqdn]
+		assert.True(t, meta.expirationTime.Equal(restoredMeta.expirationTime), "Expiration time of %s should be restored", fqdn)
+		assert.Equal(t, meta.responseIPs, restoredMeta.responseIPs)
+	}
+	assert.Equal(t, []string{"expired.antrea.io"}, restored.expiredFQDNs)
+	assert.False(t, restored.dnsCacheDirty)
+
+	// Rules added after the restoration get the restored IPs.
+	c.EXPECT().AddAddressToDNSConjunction(dnsInterceptRuleID, gomock.Any()).Times(1)
+	require.NoError(t, restored.addFQDNRule("mockRule1", []string{"www.antrea.io"}, sets.NewInt32(1)))
+	assert.ElementsMatch(t, []net.IP{net.ParseIP("1.1.1.1"), net.ParseIP("1.1.1.2")}, restored.getIPsForFQDNSelectors([]string{"www.antrea.io"}))
+
+	// The restored entries not selected by any rule are removed when they are due
+	// to be queried.
+	assert.True(t, restored.isFQDNTracked("www.antrea.io"))
+	assert.False(t, restored.isFQDNTracked("expired.antrea.io"))
+	assert.NotContains(t, restored.dnsEntryCache, "expired.antrea.io")
+	assert.True(t, restored.dnsCacheDirty)
+}
+
+func TestRestoreInvalidDNSCache(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	dnsCacheFile := filepath.Join(t.TempDir(), "fqdn-dns-cache.json")
+	require.NoError(t, ioutil.WriteFile(dnsCacheFile, []byte("invalid"), 0600))
+
+	f, _ := newFQDNControllerWithCacheFile(t, controller, dnsCacheFile)
+	assert.Empty(t, f.dnsEntryCache)
+	assert.Empty(t, f.expiredFQDNs)
+}
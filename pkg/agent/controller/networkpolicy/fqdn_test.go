@@ -16,10 +16,12 @@ package networkpolicy
 
 import (
 	"context"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -41,6 +43,7 @@ func newMockFQDNController(t *testing.T, controller *gomock.Controller, dnsServe
 		mockOFClient,
 		newIDAllocator(testAsyncDeleteInterval),
 		dnsServerAddr,
+		"",
 		dirtyRuleHandler,
 	)
 	require.NoError(t, err)
@@ -291,6 +294,184 @@ func TestDeleteFQDNRule(t *testing.T) {
 	}
 }
 
+func TestFQDNSelectorItemMatches(t *testing.T) {
+	tests := []struct {
+		name            string
+		fqdn            string
+		matchedNames    []string
+		notMatchedNames []string
+	}{
+		{
+			name:            "exact name",
+			fqdn:            "www.Antrea.io.",
+			matchedNames:    []string{"www.antrea.io"},
+			notMatchedNames: []string{"antrea.io", "wwwXantrea.io"},
+		},
+		{
+			name:            "leading wildcard",
+			fqdn:            "*.s3.amazonaws.com",
+			matchedNames:    []string{"bucket.s3.amazonaws.com", "a.b.s3.amazonaws.com"},
+			notMatchedNames: []string{"s3.amazonaws.com", "bucket.s3Xamazonaws.com"},
+		},
+		{
+			name:            "wildcard in the middle of a label",
+			fqdn:            "api-*.example.com",
+			matchedNames:    []string{"api-v1.example.com", "api-.example.com"},
+			notMatchedNames: []string{"api.example.com", "web-v1.example.com", "api-v1.example.com.cn"},
+		},
+		{
+			name:            "wildcard label in the middle",
+			fqdn:            "s3.*.amazonaws.com",
+			matchedNames:    []string{"s3.us-west-2.amazonaws.com"},
+			notMatchedNames: []string{"s3.amazonaws.com", "bucket.s3.us-west-2.amazonaws.com"},
+		},
+		{
+			name:            "multiple wildcards",
+			fqdn:            "*.api-*.example.com",
+			matchedNames:    []string{"eu.api-v2.example.com"},
+			notMatchedNames: []string{"api-v2.example.com", "eu.web-v2.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectorItem := fqdnToSelectorItem(tt.fqdn)
+			for _, name := range tt.matchedNames {
+				assert.True(t, selectorItem.matches(name), "%s should match %s", selectorItem.String(), name)
+			}
+			for _, name := range tt.notMatchedNames {
+				assert.False(t, selectorItem.matches(name), "%s should not match %s", selectorItem.String(), name)
+			}
+		})
+	}
+}
+
+func newDNSResponse(question string, answers ...dns.RR) *dns.Msg {
+	msg := &dns.Msg{}
+	msg.SetQuestion(question, dns.TypeA)
+	msg.Response = true
+	msg.Answer = answers
+	return msg
+}
+
+func newCNAMERecord(name, target string, ttl uint32) dns.RR {
+	return &dns.CNAME{
+		Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: ttl},
+		Target: target,
+	}
+}
+
+func newARecord(name, ip string, ttl uint32) dns.RR {
+	return &dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+		A:   net.ParseIP(ip),
+	}
+}
+
+func TestParseDNSResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		msg           *dns.Msg
+		expectedFQDNs []string
+		expectedIPs   map[string]net.IP
+		expectedTTL   uint32
+		expectedErr   bool
+	}{
+		{
+			name:          "A records",
+			msg:           newDNSResponse("www.Antrea.io.", newARecord("www.antrea.io.", "1.1.1.1", 60), newARecord("www.antrea.io.", "1.1.1.2", 30)),
+			expectedFQDNs: []string{"www.antrea.io"},
+			expectedIPs:   map[string]net.IP{"1.1.1.1": net.ParseIP("1.1.1.1"), "1.1.1.2": net.ParseIP("1.1.1.2")},
+			expectedTTL:   30,
+		},
+		{
+			name: "CNAME chain",
+			msg: newDNSResponse("bucket.s3.amazonaws.com.",
+				newCNAMERecord("bucket.s3.amazonaws.com.", "s3-1-w.amazonaws.com.", 20),
+				newCNAMERecord("s3-1-w.amazonaws.com.", "s3-1.amazonaws.com.", 300),
+				newARecord("s3-1.amazonaws.com.", "2.2.2.2", 60),
+				newARecord("unrelated.amazonaws.com.", "3.3.3.3", 60)),
+			expectedFQDNs: []string{"bucket.s3.amazonaws.com", "s3-1-w.amazonaws.com", "s3-1.amazonaws.com"},
+			expectedIPs:   map[string]net.IP{"2.2.2.2": net.ParseIP("2.2.2.2")},
+			expectedTTL:   20,
+		},
+		{
+			name: "CNAME loop",
+			msg: newDNSResponse("a.antrea.io.",
+				newCNAMERecord("a.antrea.io.", "b.antrea.io.", 20),
+				newCNAMERecord("b.antrea.io.", "a.antrea.io.", 20)),
+			expectedErr: true,
+		},
+		{
+			name:        "no question",
+			msg:         &dns.Msg{},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			f, _ := newMockFQDNController(t, controller, nil)
+			fqdns, ips, ttl, err := f.parseDNSResponse(tt.msg)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFQDNs, fqdns)
+			assert.Equal(t, tt.expectedIPs, ips)
+			assert.Equal(t, tt.expectedTTL, ttl)
+		})
+	}
+}
+
+func TestOnDNSResponseMsgCNAMEChain(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	f, c := newMockFQDNController(t, controller, nil)
+	dirtyRules := sets.NewString()
+	f.dirtyRuleHandler = func(rule string) { dirtyRules.Insert(rule) }
+	c.EXPECT().AddAddressToDNSConjunction(dnsInterceptRuleID, gomock.Any()).Times(2)
+	require.NoError(t, f.addFQDNRule("mockRule1", []string{"*.s3.amazonaws.com"}, sets.NewInt32(1)))
+	require.NoError(t, f.addFQDNRule("mockRule2", []string{"s3-1.amazonaws.com"}, sets.NewInt32(2)))
+
+	msg := newDNSResponse("bucket.s3.amazonaws.com.",
+		newCNAMERecord("bucket.s3.amazonaws.com.", "s3-1-w.amazonaws.com.", 60),
+		newCNAMERecord("s3-1-w.amazonaws.com.", "s3-1.amazonaws.com.", 60),
+		newARecord("s3-1.amazonaws.com.", "2.2.2.2", 60))
+	waitCh := make(chan error, 1)
+	f.onDNSResponseMsg(msg, time.Now(), waitCh)
+
+	// The IP of the canonical name is mapped to the selectors matching the
+	// queried name and the canonical name, but not the intermediate name which
+	// is not selected by any selector.
+	expectedIPs := []net.IP{net.ParseIP("2.2.2.2")}
+	assert.Equal(t, expectedIPs, f.getIPsForFQDNSelectors([]string{"*.s3.amazonaws.com"}))
+	assert.Equal(t, expectedIPs, f.getIPsForFQDNSelectors([]string{"s3-1.amazonaws.com"}))
+	assert.Contains(t, f.dnsEntryCache, "bucket.s3.amazonaws.com")
+	assert.Contains(t, f.dnsEntryCache, "s3-1.amazonaws.com")
+	assert.NotContains(t, f.dnsEntryCache, "s3-1-w.amazonaws.com")
+	assert.Equal(t, sets.NewString("mockRule1", "mockRule2"), dirtyRules)
+	// The response is held until both rules are realized.
+	f.ruleSyncTracker.updateCh = make(chan ruleRealizationUpdate)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go f.runRuleSyncTracker(stopCh)
+	f.notifyRuleUpdate("mockRule1", nil)
+	select {
+	case <-waitCh:
+		t.Fatal("DNS response should not be released before all rules are realized")
+	default:
+	}
+	f.notifyRuleUpdate("mockRule2", nil)
+	select {
+	case err := <-waitCh:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("DNS response was not released after all rules were realized")
+	}
+}
+
 func TestLookupIPFallback(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -0,0 +1,121 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"net"
+
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/l7proxy"
+	"antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+	"antrea.io/antrea/pkg/util/ip"
+)
+
+// syncL7Rule pushes the rule to the L7 proxy if it has L7 protocols. It returns
+// false if the rule must not be reconciled because the L7 proxy is not enabled,
+// in which case the rule is not enforced at all rather than enforced without
+// its L7 protocols.
+func (c *Controller) syncL7Rule(rule *CompletedRule) bool {
+	if len(rule.L7Protocols) == 0 {
+		return true
+	}
+	if c.l7Proxy == nil {
+		klog.InfoS("Skipped rule with L7 protocols as L7NetworkPolicy is not enabled", "ruleID", rule.ID, "policy", rule.SourceRef.ToString())
+		return false
+	}
+	c.l7Proxy.AddRule(toL7ProxyRule(rule))
+	return true
+}
+
+// logL7Decision logs the decisions made by the L7 proxy for the rules with logging enabled.
+func (c *Controller) logL7Decision(decision *l7proxy.Decision) {
+	if decision.Rule == nil {
+		klog.V(2).InfoS("Denied HTTP request without L7 rule", "src", decision.SrcIP, "dst", decision.DstIP, "dstPort", decision.DstPort)
+		return
+	}
+	if decision.Rule.EnableLogging {
+		c.antreaPolicyLogger.LogL7Decision(decision, c.getLocalPodRef(decision.SrcIP.String()), c.getLocalPodRef(decision.DstIP.String()))
+	}
+}
+
+func toL7ProxyRule(rule *CompletedRule) *l7proxy.Rule {
+	l7Rule := &l7proxy.Rule{
+		ID:            rule.ID,
+		Name:          rule.Name,
+		PolicyRef:     rule.SourceRef,
+		Direction:     rule.Direction,
+		EnableLogging: rule.EnableLogging,
+	}
+	if rule.TierPriority != nil && rule.PolicyPriority != nil {
+		l7Rule.Priority = types.Priority{
+			TierPriority:   *rule.TierPriority,
+			PolicyPriority: *rule.PolicyPriority,
+			RulePriority:   rule.Priority,
+		}
+	}
+	if rule.Direction == v1beta2.DirectionIn {
+		l7Rule.From = append(groupMembersToL7IPBlocks(rule.FromAddresses), ipBlocksToL7IPBlocks(rule.From.IPBlocks)...)
+		l7Rule.To = groupMembersToL7IPBlocks(rule.TargetMembers)
+	} else {
+		l7Rule.From = groupMembersToL7IPBlocks(rule.TargetMembers)
+		l7Rule.To = append(groupMembersToL7IPBlocks(rule.ToAddresses), ipBlocksToL7IPBlocks(rule.To.IPBlocks)...)
+	}
+	for _, service := range rule.Services {
+		// Named ports and non-TCP protocols are rejected by the validation of
+		// rules with L7 protocols.
+		if service.Port == nil {
+			continue
+		}
+		portRange := l7proxy.PortRange{Port: service.Port.IntVal, EndPort: service.Port.IntVal}
+		if service.EndPort != nil {
+			portRange.EndPort = *service.EndPort
+		}
+		l7Rule.Ports = append(l7Rule.Ports, portRange)
+	}
+	for _, protocol := range rule.L7Protocols {
+		if protocol.HTTP != nil {
+			l7Rule.HTTP = append(l7Rule.HTTP, *protocol.HTTP)
+		}
+	}
+	return l7Rule
+}
+
+func groupMembersToL7IPBlocks(members v1beta2.GroupMemberSet) []l7proxy.IPBlock {
+	var blocks []l7proxy.IPBlock
+	for _, member := range members {
+		for _, ipAddr := range member.IPs {
+			memberIP := net.IP(ipAddr)
+			if ipv4 := memberIP.To4(); ipv4 != nil {
+				memberIP = ipv4
+			}
+			blocks = append(blocks, l7proxy.IPBlock{CIDR: net.IPNet{IP: memberIP, Mask: net.CIDRMask(len(memberIP)*8, len(memberIP)*8)}})
+		}
+	}
+	return blocks
+}
+
+func ipBlocksToL7IPBlocks(ipBlocks []v1beta2.IPBlock) []l7proxy.IPBlock {
+	var blocks []l7proxy.IPBlock
+	for i := range ipBlocks {
+		block := l7proxy.IPBlock{CIDR: *ip.IPNetToNetIPNet(&ipBlocks[i].CIDR)}
+		for j := range ipBlocks[i].Except {
+			block.Except = append(block.Except, *ip.IPNetToNetIPNet(&ipBlocks[i].Except[j]))
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
@@ -32,10 +32,13 @@ import (
 	"antrea.io/antrea/pkg/agent"
 	"antrea.io/antrea/pkg/agent/flowexporter/connections"
 	"antrea.io/antrea/pkg/agent/interfacestore"
+	"antrea.io/antrea/pkg/agent/l7proxy"
 	"antrea.io/antrea/pkg/agent/openflow"
 	proxytypes "antrea.io/antrea/pkg/agent/proxy/types"
 	"antrea.io/antrea/pkg/agent/types"
 	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+	agentconfig "antrea.io/antrea/pkg/config/agent"
 	"antrea.io/antrea/pkg/querier"
 )
 
@@ -105,6 +108,8 @@ type Controller struct {
 	ifaceStore            interfacestore.InterfaceStore
 	// denyConnStore is for storing deny connections for flow exporter.
 	denyConnStore *connections.DenyConnectionStore
+	// l7Proxy enforces the L7 protocols of rules. It's nil if L7NetworkPolicy is not enabled.
+	l7Proxy l7proxy.Interface
 }
 
 // NewNetworkPolicyController returns a new *Controller.
@@ -119,8 +124,12 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 	antreaProxyEnabled bool,
 	statusManagerEnabled bool,
 	loggingEnabled bool,
+	auditLoggingConfig agentconfig.AuditLoggingConfig,
 	asyncRuleDeleteInterval time.Duration,
-	dnsServerOverride string) (*Controller, error) {
+	dnsServerOverride string,
+	dnsCacheFile string,
+	l7Proxy l7proxy.Interface,
+	tierLister crdlisters.TierLister) (*Controller, error) {
 	idAllocator := newIDAllocator(asyncRuleDeleteInterval, dnsInterceptRuleID)
 	c := &Controller{
 		antreaClientProvider: antreaClientGetter,
@@ -130,10 +139,11 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		antreaProxyEnabled:   antreaProxyEnabled,
 		statusManagerEnabled: statusManagerEnabled,
 		loggingEnabled:       loggingEnabled,
+		l7Proxy:              l7Proxy,
 	}
 	if antreaPolicyEnabled {
 		var err error
-		if c.fqdnController, err = newFQDNController(ofClient, idAllocator, dnsServerOverride, c.enqueueRule); err != nil {
+		if c.fqdnController, err = newFQDNController(ofClient, idAllocator, dnsServerOverride, dnsCacheFile, c.enqueueRule); err != nil {
 			return nil, err
 		}
 		if c.ofClient != nil {
@@ -156,11 +166,14 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		c.ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonNP), "networkpolicy", c)
 		if loggingEnabled {
 			// Initiate logger for Antrea Policy audit logging
-			antreaPolicyLogger, err := newAntreaPolicyLogger()
+			antreaPolicyLogger, err := newAntreaPolicyLogger(nodeName, auditLoggingConfig, tierLister)
 			if err != nil {
 				return nil, err
 			}
 			c.antreaPolicyLogger = antreaPolicyLogger
+			if c.l7Proxy != nil {
+				c.l7Proxy.RegisterDecisionHandler(c.logL7Decision)
+			}
 		}
 	}
 
@@ -407,6 +420,35 @@ func (c *Controller) GetRuleByFlowID(ruleFlowID uint32) *types.PolicyRule {
 	return rule
 }
 
+// GetFQDNCache returns the FQDNs cached for FQDN policy rules, along with the
+// original NetworkPolicies of the rules selecting them.
+func (c *Controller) GetFQDNCache() []querier.FQDNCacheEntry {
+	if c.fqdnController == nil {
+		return nil
+	}
+	entries := c.fqdnController.getFQDNCache()
+	for i := range entries {
+		policies := map[v1beta2.NetworkPolicyReference]struct{}{}
+		for _, ruleID := range entries[i].RuleIDs {
+			if policy := c.ruleCache.getRulePolicy(ruleID); policy != nil {
+				if _, ok := policies[*policy]; !ok {
+					policies[*policy] = struct{}{}
+					entries[i].Policies = append(entries[i].Policies, *policy)
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// GetFQDNStats returns the counters of the FQDN policy implementation.
+func (c *Controller) GetFQDNStats() querier.FQDNStats {
+	if c.fqdnController == nil {
+		return querier.FQDNStats{}
+	}
+	return c.fqdnController.getFQDNStats()
+}
+
 func (c *Controller) GetControllerConnectionStatus() bool {
 	// When the watchers are connected, controller connection status is true. Otherwise, it is false.
 	return c.addressGroupWatcher.isConnected() && c.appliedToGroupWatcher.isConnected() && c.networkPolicyWatcher.isConnected()
@@ -448,6 +490,12 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 			go wait.Until(c.fqdnController.worker, time.Second, stopCh)
 		}
 		go c.fqdnController.runRuleSyncTracker(stopCh)
+		if c.fqdnController.dnsCacheFile != "" {
+			go c.fqdnController.runDNSCacheSyncer(stopCh)
+		}
+	}
+	if c.antreaPolicyLogger != nil && c.antreaPolicyLogger.forwarder != nil {
+		go c.antreaPolicyLogger.forwarder.run(stopCh)
 	}
 	klog.Infof("Waiting for all watchers to complete full sync")
 	c.fullSyncGroup.Wait()
@@ -528,6 +576,9 @@ func (c *Controller) syncRule(key string) error {
 		if err := c.reconciler.Forget(key); err != nil {
 			return err
 		}
+		if c.l7Proxy != nil {
+			c.l7Proxy.DeleteRule(key)
+		}
 		if c.statusManagerEnabled {
 			// We don't know whether this is a rule owned by Antrea Policy, but
 			// harmless to delete it.
@@ -541,6 +592,10 @@ func (c *Controller) syncRule(key string) error {
 		klog.V(2).InfoS("Rule is not realizable, skipping", "ruleID", key)
 		return nil
 	}
+	// The rule must be known by the L7 proxy before its flows start redirecting traffic to the proxy.
+	if !c.syncL7Rule(rule) {
+		return nil
+	}
 	err := c.reconciler.Reconcile(rule)
 	if c.fqdnController != nil {
 		// No matter whether the rule reconciliation succeeds or not, fqdnController
@@ -575,7 +630,7 @@ func (c *Controller) syncRules(keys []string) error {
 			klog.Infof("Rule %s is not effective on this Node", key)
 		} else if !realizable {
 			klog.Errorf("Rule %s is effective but not realizable", key)
-		} else {
+		} else if c.syncL7Rule(rule) {
 			allRules = append(allRules, rule)
 		}
 	}
@@ -29,6 +29,7 @@ import (
 	"antrea.io/antrea/pkg/agent/openflow"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	"antrea.io/antrea/pkg/util/ip"
+	"antrea.io/antrea/pkg/util/k8s"
 )
 
 // HandlePacketIn is the packetin handler registered to openflow by Antrea network
@@ -113,6 +114,18 @@ func getNetworkPolicyInfo(pktIn *ofctrl.PacketIn, c *Controller, ob *logInfo) er
 	var match *ofctrl.MatchField
 	// Get table name
 	tableID := pktIn.TableId
+	// The copy of a packet logged by a rule in audit mode is sent from AuditLoggingTable, get the table of the rule.
+	if tableID == openflow.AuditLoggingTable.GetID() {
+		match = getMatchRegField(matchers, openflow.AuditRuleTableField)
+		if match == nil {
+			return errors.New("audited packet-in without the table of the rule")
+		}
+		info, err := getInfoInReg(match, openflow.AuditRuleTableField.GetRange().ToNXRange())
+		if err != nil {
+			return fmt.Errorf("received error while unloading the table of the audited rule from reg: %v", err)
+		}
+		tableID = uint8(info)
+	}
 	ob.tableName = openflow.GetFlowTableName(tableID)
 
 	// Get disposition Allow or Drop
@@ -132,6 +145,21 @@ func getNetworkPolicyInfo(pktIn *ofctrl.PacketIn, c *Controller, ob *logInfo) er
 		return fmt.Errorf("received error while unloading conjunction id from reg: %v", err)
 	}
 	ob.npRef, ob.ofPriority = c.ofClient.GetPolicyInfoFromConjunction(info)
+	if rule := c.GetRuleByFlowID(info); rule != nil {
+		// The action of an audited rule is not applied to the traffic, log the action
+		// which would have been applied.
+		if rule.Audit && rule.Action != nil {
+			ob.disposition = auditDispositionPrefix + string(*rule.Action)
+		}
+		ob.ruleName = rule.Name
+		ob.direction = directionToString(rule.Direction)
+		if rule.PolicyRef != nil {
+			ob.policyUID = string(rule.PolicyRef.UID)
+			if policy := c.ruleCache.getNetworkPolicy(ob.policyUID); policy != nil && policy.TierPriority != nil {
+				ob.tierPriority = *policy.TierPriority
+			}
+		}
+	}
 
 	return nil
 }
@@ -176,11 +204,27 @@ func (c *Controller) logPacket(pktIn *ofctrl.PacketIn) error {
 		return fmt.Errorf("received error while retrieving NetworkPolicy info: %v", err)
 	}
 
+	ob.srcPod = c.getLocalPodRef(ob.srcIP)
+	ob.destPod = c.getLocalPodRef(ob.destIP)
+
 	// Log the ob info to corresponding file w/ deduplication
 	c.antreaPolicyLogger.LogDedupPacket(ob)
 	return nil
 }
 
+// getLocalPodRef returns the Namespace/Name of the Pod running on the Node
+// which has the provided IP, or an empty string if there is none.
+func (c *Controller) getLocalPodRef(podIP string) string {
+	if c.ifaceStore == nil {
+		return ""
+	}
+	iface, ok := c.ifaceStore.GetInterfaceByIP(podIP)
+	if !ok || iface.ContainerInterfaceConfig == nil {
+		return ""
+	}
+	return k8s.NamespacedName(iface.PodNamespace, iface.PodName)
+}
+
 func (c *Controller) storeDenyConnection(pktIn *ofctrl.PacketIn) error {
 	packet, err := binding.ParsePacketIn(pktIn)
 	if err != nil {
@@ -463,6 +463,8 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 				TableID:       table,
 				PolicyRef:     rule.SourceRef,
 				EnableLogging: rule.EnableLogging,
+				L7Protocols:   rule.L7Protocols,
+				Audit:         rule.Audit,
 			}
 		}
 	} else {
@@ -491,6 +493,8 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 				TableID:       table,
 				PolicyRef:     rule.SourceRef,
 				EnableLogging: rule.EnableLogging,
+				L7Protocols:   rule.L7Protocols,
+				Audit:         rule.Audit,
 			}
 		}
 
@@ -515,6 +519,8 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 					TableID:       table,
 					PolicyRef:     rule.SourceRef,
 					EnableLogging: rule.EnableLogging,
+					L7Protocols:   rule.L7Protocols,
+					Audit:         rule.Audit,
 				}
 				ofRuleByServicesMap[svcKey] = ofRule
 			}
@@ -629,6 +635,8 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 					TableID:       table,
 					PolicyRef:     newRule.SourceRef,
 					EnableLogging: newRule.EnableLogging,
+					L7Protocols:   newRule.L7Protocols,
+					Audit:         newRule.Audit,
 				}
 				err := r.idAllocator.allocateForRule(ofRule)
 				if err != nil {
@@ -683,6 +691,8 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 					TableID:       table,
 					PolicyRef:     newRule.SourceRef,
 					EnableLogging: newRule.EnableLogging,
+					L7Protocols:   newRule.L7Protocols,
+					Audit:         newRule.Audit,
 				}
 				// If the PolicyRule for the original services doesn't exist and IPBlocks is present, it means the
 				// reconciler hasn't installed flows for IPBlocks, then it must be added to the new PolicyRule.
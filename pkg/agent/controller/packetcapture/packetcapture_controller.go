@@ -0,0 +1,577 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"antrea.io/libOpenflow/protocol"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	"antrea.io/antrea/pkg/agent/openflow"
+	"antrea.io/antrea/pkg/agent/util"
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	clientsetversioned "antrea.io/antrea/pkg/client/clientset/versioned"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
+	"antrea.io/antrea/pkg/util/logdir"
+	"antrea.io/antrea/pkg/util/pcapng"
+)
+
+const (
+	controllerName = "AntreaAgentPacketCaptureController"
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+	// How long to wait before retrying the processing of a PacketCapture.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// Default number of workers processing PacketCapture requests.
+	defaultWorkers = 2
+
+	captureDirName = "packetcapture"
+	captureFileExt = ".pcapng"
+)
+
+// GetCaptureDir returns the directory where the pcapng files are saved.
+func GetCaptureDir() string {
+	return filepath.Join(logdir.GetLogDir(), captureDirName)
+}
+
+// GetCaptureFileName returns the name of the pcapng file of a PacketCapture.
+func GetCaptureFileName(name string) string {
+	return name + captureFileExt
+}
+
+type packetCaptureState struct {
+	name string
+	// packet is the filter the captured packets must match.
+	packet             *binding.Packet
+	firstN             int32
+	numCapturedPackets int32
+	file               *os.File
+	writer             *pcapng.Writer
+	timer              *time.Timer
+}
+
+// Controller is responsible for setting up Openflow entries and writing the
+// packets sent to Antrea Agent to pcapng files for PacketCapture requests. A
+// PacketCapture is processed by the Antrea Agent running on the Node of the
+// source Pod, or of the destination Pod if no source Pod is specified.
+type Controller struct {
+	kubeClient                 clientset.Interface
+	crdClient                  clientsetversioned.Interface
+	packetCaptureInformer      crdinformers.PacketCaptureInformer
+	packetCaptureLister        crdlisters.PacketCaptureLister
+	packetCaptureListerSynced  cache.InformerSynced
+	podInformer                cache.SharedIndexInformer
+	podListerSynced            cache.InformerSynced
+	ofClient                   openflow.Client
+	interfaceStore             interfacestore.InterfaceStore
+	nodeConfig                 *config.NodeConfig
+	captureDir                 string
+	queue                      workqueue.RateLimitingInterface
+	runningPacketCapturesMutex sync.Mutex
+	// runningPacketCaptures is a map for storing the running PacketCapture
+	// state with the PacketCapture name to be the key.
+	runningPacketCaptures map[string]*packetCaptureState
+}
+
+// NewPacketCaptureController instantiates a new Controller object which will
+// process PacketCapture events.
+func NewPacketCaptureController(
+	kubeClient clientset.Interface,
+	crdClient clientsetversioned.Interface,
+	packetCaptureInformer crdinformers.PacketCaptureInformer,
+	client openflow.Client,
+	interfaceStore interfacestore.InterfaceStore,
+	nodeConfig *config.NodeConfig) *Controller {
+	// Only the Pods running on the Node are watched.
+	podInformer := coreinformers.NewFilteredPodInformer(kubeClient, metav1.NamespaceAll, resyncPeriod, cache.Indexers{}, func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeConfig.Name).String()
+	})
+	c := &Controller{
+		kubeClient:                kubeClient,
+		crdClient:                 crdClient,
+		packetCaptureInformer:     packetCaptureInformer,
+		packetCaptureLister:       packetCaptureInformer.Lister(),
+		packetCaptureListerSynced: packetCaptureInformer.Informer().HasSynced,
+		podInformer:               podInformer,
+		podListerSynced:           podInformer.HasSynced,
+		ofClient:                  client,
+		interfaceStore:            interfaceStore,
+		nodeConfig:                nodeConfig,
+		captureDir:                GetCaptureDir(),
+		queue:                     workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "packetcapture"),
+		runningPacketCaptures:     make(map[string]*packetCaptureState),
+	}
+
+	packetCaptureInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPacketCapture,
+			UpdateFunc: c.updatePacketCapture,
+			DeleteFunc: c.deletePacketCapture,
+		},
+		resyncPeriod,
+	)
+	podInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPod,
+			UpdateFunc: c.updatePod,
+		},
+		resyncPeriod,
+	)
+	c.ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonPC), "packetcapture", c)
+	return c
+}
+
+func (c *Controller) enqueuePacketCapture(pc *crdv1alpha1.PacketCapture) {
+	c.queue.Add(pc.Name)
+}
+
+// Run will create defaultWorkers workers (go routines) which will process the
+// PacketCapture events from the workqueue.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	go c.podInformer.Run(stopCh)
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.packetCaptureListerSynced, c.podListerSynced) {
+		return
+	}
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *Controller) addPacketCapture(obj interface{}) {
+	pc := obj.(*crdv1alpha1.PacketCapture)
+	klog.Infof("Processing PacketCapture %s ADD event", pc.Name)
+	c.enqueuePacketCapture(pc)
+}
+
+func (c *Controller) updatePacketCapture(_, curObj interface{}) {
+	pc := curObj.(*crdv1alpha1.PacketCapture)
+	klog.Infof("Processing PacketCapture %s UPDATE event", pc.Name)
+	c.enqueuePacketCapture(pc)
+}
+
+func (c *Controller) deletePacketCapture(old interface{}) {
+	pc, ok := old.(*crdv1alpha1.PacketCapture)
+	if !ok {
+		tombstone, ok := old.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Error decoding object when deleting PacketCapture, invalid type: %v", old)
+			return
+		}
+		pc, ok = tombstone.Obj.(*crdv1alpha1.PacketCapture)
+		if !ok {
+			klog.Errorf("Error decoding object tombstone when deleting PacketCapture, invalid type: %v", tombstone.Obj)
+			return
+		}
+	}
+	klog.Infof("Processing PacketCapture %s DELETE event", pc.Name)
+	c.enqueuePacketCapture(pc)
+}
+
+func (c *Controller) addPod(obj interface{}) {
+	pod := obj.(*corev1.Pod)
+	c.enqueuePodPacketCaptures(pod)
+}
+
+func (c *Controller) updatePod(_, curObj interface{}) {
+	pod := curObj.(*corev1.Pod)
+	c.enqueuePodPacketCaptures(pod)
+}
+
+// enqueuePodPacketCaptures enqueues the PacketCaptures which have not started
+// yet and which should be processed by the Agent running the Pod. They were
+// ignored if they were processed before the Pod interface was created.
+func (c *Controller) enqueuePodPacketCaptures(pod *corev1.Pod) {
+	pcs, err := c.packetCaptureLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list PacketCaptures: %v", err)
+		return
+	}
+	for _, pc := range pcs {
+		if pc.Status.Phase != "" {
+			continue
+		}
+		if pc.Spec.Source.Pod != "" {
+			if pc.Spec.Source.Pod == pod.Name && pc.Spec.Source.Namespace == pod.Namespace {
+				c.enqueuePacketCapture(pc)
+			}
+		} else if pc.Spec.Destination.Pod == pod.Name && pc.Spec.Destination.Namespace == pod.Namespace {
+			c.enqueuePacketCapture(pc)
+		}
+	}
+}
+
+func (c *Controller) worker() {
+	for c.processPacketCaptureItem() {
+	}
+}
+
+func (c *Controller) processPacketCaptureItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncPacketCapture(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Error syncing PacketCapture %s, requeuing. Error: %v", key, err)
+	}
+	return true
+}
+
+func (c *Controller) syncPacketCapture(name string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing PacketCapture for %s. (%v)", name, time.Since(startTime))
+	}()
+
+	pc, err := c.packetCaptureLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			c.cleanupPacketCapture(name)
+			// The captured packets are not useful anymore.
+			if err := os.Remove(filepath.Join(c.captureDir, GetCaptureFileName(name))); err != nil && !os.IsNotExist(err) {
+				klog.Errorf("Failed to remove the capture file of PacketCapture %s: %v", name, err)
+			}
+			return nil
+		}
+		return err
+	}
+
+	switch pc.Status.Phase {
+	case "":
+		if c.isOwner(pc) && !c.isRunning(name) {
+			return c.startPacketCapture(pc)
+		}
+	case crdv1alpha1.PacketCaptureRunning:
+		// The Agent was restarted while capturing packets, the capture
+		// cannot be resumed.
+		if pc.Status.Node == c.nodeConfig.Name && !c.isRunning(name) {
+			return c.updateStatus(name, func(status *crdv1alpha1.PacketCaptureStatus) {
+				status.Phase = crdv1alpha1.PacketCaptureFailed
+				status.Reason = "Antrea Agent was restarted during the capture"
+			})
+		}
+	default:
+		c.cleanupPacketCapture(name)
+	}
+	return nil
+}
+
+// isOwner returns whether the PacketCapture should be processed by this Agent,
+// i.e. whether the source Pod, or the destination Pod if no source Pod is
+// specified, runs on this Node.
+func (c *Controller) isOwner(pc *crdv1alpha1.PacketCapture) bool {
+	if pc.Spec.Source.Pod != "" {
+		return len(c.interfaceStore.GetContainerInterfacesByPod(pc.Spec.Source.Pod, pc.Spec.Source.Namespace)) > 0
+	}
+	if pc.Spec.Destination.Pod != "" {
+		return len(c.interfaceStore.GetContainerInterfacesByPod(pc.Spec.Destination.Pod, pc.Spec.Destination.Namespace)) > 0
+	}
+	return false
+}
+
+func (c *Controller) isRunning(name string) bool {
+	c.runningPacketCapturesMutex.Lock()
+	defer c.runningPacketCapturesMutex.Unlock()
+	_, ok := c.runningPacketCaptures[name]
+	return ok
+}
+
+// startPacketCapture creates the pcapng file and installs the OVS flows for the
+// PacketCapture. The capture is stopped after FirstN packets are captured or
+// when the timeout expires, whichever comes first.
+func (c *Controller) startPacketCapture(pc *crdv1alpha1.PacketCapture) error {
+	packet, err := c.preparePacket(pc)
+	if err != nil {
+		return c.updateStatus(pc.Name, func(status *crdv1alpha1.PacketCaptureStatus) {
+			status.Phase = crdv1alpha1.PacketCaptureFailed
+			status.Reason = fmt.Sprintf("Node: %s, error: %v", c.nodeConfig.Name, err)
+			status.Node = c.nodeConfig.Name
+		})
+	}
+	klog.V(2).Infof("PacketCapture %s packet filter %v", pc.Name, *packet)
+
+	if err := os.MkdirAll(c.captureDir, 0755); err != nil {
+		return fmt.Errorf("error when creating the capture directory: %w", err)
+	}
+	filePath := filepath.Join(c.captureDir, GetCaptureFileName(pc.Name))
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("error when creating the capture file: %w", err)
+	}
+	writer, err := pcapng.NewWriter(file)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error when writing the capture file headers: %w", err)
+	}
+
+	firstN := pc.Spec.FirstN
+	if firstN == 0 {
+		firstN = crdv1alpha1.DefaultPacketCaptureFirstN
+	}
+	timeout := pc.Spec.Timeout
+	if timeout == 0 {
+		timeout = crdv1alpha1.DefaultPacketCaptureTimeout
+	}
+	pcState := &packetCaptureState{
+		name:   pc.Name,
+		packet: packet,
+		firstN: firstN,
+		file:   file,
+		writer: writer,
+	}
+	c.runningPacketCapturesMutex.Lock()
+	c.runningPacketCaptures[pc.Name] = pcState
+	c.runningPacketCapturesMutex.Unlock()
+
+	klog.V(2).Infof("Installing flow entries for PacketCapture %s", pc.Name)
+	if err := c.ofClient.InstallPacketCaptureFlows(pc.Name, packet, timeout); err != nil {
+		c.cleanupPacketCapture(pc.Name)
+		return c.updateStatus(pc.Name, func(status *crdv1alpha1.PacketCaptureStatus) {
+			status.Phase = crdv1alpha1.PacketCaptureFailed
+			status.Reason = fmt.Sprintf("Node: %s, error: failed to install flows: %v", c.nodeConfig.Name, err)
+			status.Node = c.nodeConfig.Name
+		})
+	}
+
+	startTime := metav1.Now()
+	if err := c.updateStatus(pc.Name, func(status *crdv1alpha1.PacketCaptureStatus) {
+		// Do not overwrite the phase if the capture has completed already.
+		if status.Phase == "" {
+			status.Phase = crdv1alpha1.PacketCaptureRunning
+		}
+		status.StartTime = &startTime
+		status.Node = c.nodeConfig.Name
+		status.FilePath = filePath
+	}); err != nil {
+		c.cleanupPacketCapture(pc.Name)
+		return err
+	}
+
+	c.runningPacketCapturesMutex.Lock()
+	defer c.runningPacketCapturesMutex.Unlock()
+	// The capture may have completed already.
+	if _, ok := c.runningPacketCaptures[pc.Name]; ok {
+		pcState.timer = time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+			c.completePacketCapture(pc.Name, true)
+		})
+	}
+	return nil
+}
+
+// completePacketCapture stops a running PacketCapture and reports its result in
+// the PacketCapture status. It is a no-op if the PacketCapture is not running.
+func (c *Controller) completePacketCapture(name string, timedOut bool) {
+	pcState := c.deletePacketCaptureState(name)
+	if pcState == nil {
+		return
+	}
+	c.stopPacketCapture(pcState)
+	numCapturedPackets := pcState.numCapturedPackets
+	err := c.updateStatus(name, func(status *crdv1alpha1.PacketCaptureStatus) {
+		status.NumCapturedPackets = numCapturedPackets
+		if !timedOut {
+			status.Phase = crdv1alpha1.PacketCaptureSucceeded
+			status.Reason = ""
+		} else if numCapturedPackets > 0 {
+			status.Phase = crdv1alpha1.PacketCaptureSucceeded
+			status.Reason = fmt.Sprintf("Timeout reached after capturing %d packets", numCapturedPackets)
+		} else {
+			status.Phase = crdv1alpha1.PacketCaptureFailed
+			// The packets are captured when they are output, so the packets dropped by the
+			// OVS pipeline, e.g. by NetworkPolicies, are never captured.
+			status.Reason = "Timeout reached before any packet was captured, the packets may have been dropped before being output by OVS"
+		}
+	})
+	if err != nil {
+		klog.Errorf("Failed to update status of PacketCapture %s: %v", name, err)
+	}
+}
+
+// updateStatus updates the status of a PacketCapture. The PacketCapture is
+// retrieved from the K8s API instead of the lister to get the latest version,
+// as it may be updated multiple times in a short period.
+func (c *Controller) updateStatus(name string, updateFn func(status *crdv1alpha1.PacketCaptureStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pc, err := c.crdClient.CrdV1alpha1().PacketCaptures().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		toUpdate := pc.DeepCopy()
+		updateFn(&toUpdate.Status)
+		_, err = c.crdClient.CrdV1alpha1().PacketCaptures().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (c *Controller) preparePacket(pc *crdv1alpha1.PacketCapture) (*binding.Packet, error) {
+	if pc.Spec.Destination.Service != "" {
+		return nil, errors.New("using Service destination is not supported by PacketCapture")
+	}
+	packet := new(binding.Packet)
+	packet.IsIPv6 = pc.Spec.Packet.IPv6Header != nil
+
+	var err error
+	if pc.Spec.Source.Pod != "" {
+		packet.SourceIP, err = c.getPodIP(pc.Spec.Source.Namespace, pc.Spec.Source.Pod, packet.IsIPv6)
+		if err != nil {
+			return nil, fmt.Errorf("source Pod: %w", err)
+		}
+	} else if pc.Spec.Source.IP != "" {
+		packet.SourceIP = net.ParseIP(pc.Spec.Source.IP)
+		if packet.SourceIP == nil {
+			return nil, errors.New("invalid source IP address")
+		}
+		if (packet.SourceIP.To4() == nil) != packet.IsIPv6 {
+			return nil, errors.New("source IP does not match the IP header family")
+		}
+	}
+	if pc.Spec.Destination.Pod != "" {
+		packet.DestinationIP, err = c.getPodIP(pc.Spec.Destination.Namespace, pc.Spec.Destination.Pod, packet.IsIPv6)
+		if err != nil {
+			return nil, fmt.Errorf("destination Pod: %w", err)
+		}
+	} else if pc.Spec.Destination.IP != "" {
+		packet.DestinationIP = net.ParseIP(pc.Spec.Destination.IP)
+		if packet.DestinationIP == nil {
+			return nil, errors.New("invalid destination IP address")
+		}
+		if (packet.DestinationIP.To4() == nil) != packet.IsIPv6 {
+			return nil, errors.New("destination IP does not match the IP header family")
+		}
+	}
+
+	if pc.Spec.Packet.IPv6Header != nil {
+		if pc.Spec.Packet.IPv6Header.NextHeader != nil {
+			packet.IPProto = uint8(*pc.Spec.Packet.IPv6Header.NextHeader)
+		}
+	} else {
+		packet.IPProto = uint8(pc.Spec.Packet.IPHeader.Protocol)
+	}
+	if pc.Spec.Packet.TransportHeader.TCP != nil {
+		packet.IPProto = protocol.Type_TCP
+		packet.SourcePort = uint16(pc.Spec.Packet.TransportHeader.TCP.SrcPort)
+		packet.DestinationPort = uint16(pc.Spec.Packet.TransportHeader.TCP.DstPort)
+	} else if pc.Spec.Packet.TransportHeader.UDP != nil {
+		packet.IPProto = protocol.Type_UDP
+		packet.SourcePort = uint16(pc.Spec.Packet.TransportHeader.UDP.SrcPort)
+		packet.DestinationPort = uint16(pc.Spec.Packet.TransportHeader.UDP.DstPort)
+	}
+	return packet, nil
+}
+
+// getPodIP returns the IP of the Pod in the requested family, looking up the
+// local interfaces first and then the K8s API for a Pod on another Node.
+func (c *Controller) getPodIP(namespace, name string, isIPv6 bool) (net.IP, error) {
+	var podIP net.IP
+	if podInterfaces := c.interfaceStore.GetContainerInterfacesByPod(name, namespace); len(podInterfaces) > 0 {
+		if isIPv6 {
+			podIP = podInterfaces[0].GetIPv6Addr()
+		} else {
+			podIP = podInterfaces[0].GetIPv4Addr()
+		}
+	} else {
+		pod, err := c.kubeClient.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Pod: %v", err)
+		}
+		podIPs := make([]net.IP, len(pod.Status.PodIPs))
+		for i, ip := range pod.Status.PodIPs {
+			podIPs[i] = net.ParseIP(ip.IP)
+		}
+		if isIPv6 {
+			podIP, _ = util.GetIPWithFamily(podIPs, util.FamilyIPv6)
+		} else {
+			podIP = util.GetIPv4Addr(podIPs)
+		}
+	}
+	if podIP == nil {
+		if isIPv6 {
+			return nil, errors.New("Pod does not have an IPv6 address")
+		}
+		return nil, errors.New("Pod does not have an IPv4 address")
+	}
+	return podIP, nil
+}
+
+func (c *Controller) deletePacketCaptureState(name string) *packetCaptureState {
+	c.runningPacketCapturesMutex.Lock()
+	defer c.runningPacketCapturesMutex.Unlock()
+	pcState, ok := c.runningPacketCaptures[name]
+	if !ok {
+		return nil
+	}
+	delete(c.runningPacketCaptures, name)
+	return pcState
+}
+
+// stopPacketCapture uninstalls the OVS flows and closes the capture file of a
+// PacketCapture whose state has been removed from runningPacketCaptures.
+func (c *Controller) stopPacketCapture(pcState *packetCaptureState) {
+	if pcState.timer != nil {
+		pcState.timer.Stop()
+	}
+	if err := c.ofClient.UninstallPacketCaptureFlows(pcState.name); err != nil {
+		klog.Errorf("Failed to uninstall PacketCapture %s flows: %v", pcState.name, err)
+	}
+	if err := pcState.file.Close(); err != nil {
+		klog.Errorf("Failed to close the capture file of PacketCapture %s: %v", pcState.name, err)
+	}
+}
+
+// cleanupPacketCapture stops the PacketCapture if it is running on this Node.
+func (c *Controller) cleanupPacketCapture(name string) {
+	if pcState := c.deletePacketCaptureState(name); pcState != nil {
+		c.stopPacketCapture(pcState)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"antrea.io/libOpenflow/protocol"
+	"antrea.io/libOpenflow/util"
+	"antrea.io/ofnet/ofctrl"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	openflowtest "antrea.io/antrea/pkg/agent/openflow/testing"
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
+)
+
+var (
+	srcPodIP  = net.ParseIP("10.10.0.2")
+	dstPodIP  = net.ParseIP("10.10.1.2")
+	srcMAC, _ = net.ParseMAC("aa:bb:cc:dd:ee:0f")
+	dstMAC, _ = net.ParseMAC("aa:bb:cc:dd:ee:00")
+)
+
+type fakeController struct {
+	*Controller
+	mockOFClient *openflowtest.MockClient
+	crdClient    *fakeversioned.Clientset
+}
+
+func newFakeController(t *testing.T, initObjects ...*crdv1alpha1.PacketCapture) *fakeController {
+	controller := gomock.NewController(t)
+	mockOFClient := openflowtest.NewMockClient(controller)
+	mockOFClient.EXPECT().RegisterPacketInHandler(gomock.Any(), gomock.Any(), gomock.Any())
+
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dst"},
+		Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: dstPodIP.String()}}},
+	}
+	kubeClient := fake.NewSimpleClientset(dstPod)
+	crdClient := fakeversioned.NewSimpleClientset()
+	for _, pc := range initObjects {
+		crdClient.CrdV1alpha1().PacketCaptures().Create(context.TODO(), pc, metav1.CreateOptions{})
+	}
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	ifaceStore := interfacestore.NewInterfaceStore()
+	ifaceStore.AddInterface(interfacestore.NewContainerInterface("src-12345", "12345", "src", "default", srcMAC, []net.IP{srcPodIP}))
+
+	c := NewPacketCaptureController(
+		kubeClient,
+		crdClient,
+		crdInformerFactory.Crd().V1alpha1().PacketCaptures(),
+		mockOFClient,
+		ifaceStore,
+		&config.NodeConfig{Name: "node1"})
+	c.captureDir = t.TempDir()
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	crdInformerFactory.Start(stopCh)
+	crdInformerFactory.WaitForCacheSync(stopCh)
+	return &fakeController{Controller: c, mockOFClient: mockOFClient, crdClient: crdClient}
+}
+
+func newTCPPacketIn(srcIP, dstIP net.IP, srcPort, dstPort uint16) *ofctrl.PacketIn {
+	tcp := protocol.TCP{PortSrc: srcPort, PortDst: dstPort, SeqNum: 1, HdrLen: 5, Code: 2}
+	tcpBytes, _ := tcp.MarshalBinary()
+	ipPacket := protocol.IPv4{
+		Version:  4,
+		IHL:      5,
+		Length:   20 + uint16(len(tcpBytes)),
+		TTL:      64,
+		Protocol: protocol.Type_TCP,
+		NWSrc:    srcIP,
+		NWDst:    dstIP,
+		Data:     util.NewBuffer(tcpBytes),
+	}
+	pktIn := &ofctrl.PacketIn{
+		Data: protocol.Ethernet{
+			HWDst:     dstMAC,
+			HWSrc:     srcMAC,
+			Ethertype: protocol.IPv4_MSG,
+			Data:      &ipPacket,
+		},
+	}
+	pktIn.TotalLen = 14 + ipPacket.Length
+	return pktIn
+}
+
+func TestPacketCapture(t *testing.T) {
+	pc := &crdv1alpha1.PacketCapture{
+		ObjectMeta: metav1.ObjectMeta{Name: "pc1"},
+		Spec: crdv1alpha1.PacketCaptureSpec{
+			Source:      crdv1alpha1.Source{Namespace: "default", Pod: "src"},
+			Destination: crdv1alpha1.Destination{Namespace: "default", Pod: "dst"},
+			Packet: crdv1alpha1.Packet{
+				TransportHeader: crdv1alpha1.TransportHeader{TCP: &crdv1alpha1.TCPHeader{DstPort: 80}},
+			},
+			FirstN: 2,
+		},
+	}
+	c := newFakeController(t, pc)
+
+	expectedFilter := &binding.Packet{
+		SourceIP:        srcPodIP,
+		DestinationIP:   dstPodIP,
+		IPProto:         protocol.Type_TCP,
+		DestinationPort: 80,
+	}
+	c.mockOFClient.EXPECT().InstallPacketCaptureFlows("pc1", expectedFilter, crdv1alpha1.DefaultPacketCaptureTimeout)
+	require.NoError(t, c.syncPacketCapture("pc1"))
+
+	pc, err := c.crdClient.CrdV1alpha1().PacketCaptures().Get(context.TODO(), "pc1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, crdv1alpha1.PacketCaptureRunning, pc.Status.Phase)
+	assert.Equal(t, "node1", pc.Status.Node)
+	filePath := filepath.Join(c.captureDir, "pc1.pcapng")
+	assert.Equal(t, filePath, pc.Status.FilePath)
+	assert.True(t, c.isRunning("pc1"))
+
+	// Packets not matching the filter are ignored.
+	require.NoError(t, c.HandlePacketIn(newTCPPacketIn(srcPodIP, dstPodIP, 34567, 443)))
+	require.NoError(t, c.HandlePacketIn(newTCPPacketIn(srcPodIP, dstPodIP, 34567, 80)))
+	assert.True(t, c.isRunning("pc1"))
+
+	c.mockOFClient.EXPECT().UninstallPacketCaptureFlows("pc1")
+	require.NoError(t, c.HandlePacketIn(newTCPPacketIn(srcPodIP, dstPodIP, 34568, 80)))
+	assert.False(t, c.isRunning("pc1"))
+
+	pc, err = c.crdClient.CrdV1alpha1().PacketCaptures().Get(context.TODO(), "pc1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, crdv1alpha1.PacketCaptureSucceeded, pc.Status.Phase)
+	assert.Equal(t, int32(2), pc.Status.NumCapturedPackets)
+
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	// Section Header Block (28 bytes), Interface Description Block (20
+	// bytes) and 2 Enhanced Packet Blocks of 32 + 54 (Ethernet, IPv4 and
+	// TCP headers) + 2 (padding) bytes.
+	assert.Equal(t, 28+20+2*(32+54+2), len(data))
+
+	// The capture file is removed with the PacketCapture.
+	require.NoError(t, c.crdClient.CrdV1alpha1().PacketCaptures().Delete(context.TODO(), "pc1", metav1.DeleteOptions{}))
+	require.Eventually(t, func() bool {
+		_, err := c.packetCaptureLister.Get("pc1")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, c.syncPacketCapture("pc1"))
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPacketCaptureTimeout(t *testing.T) {
+	pc := &crdv1alpha1.PacketCapture{
+		ObjectMeta: metav1.ObjectMeta{Name: "pc1"},
+		Spec: crdv1alpha1.PacketCaptureSpec{
+			Source:      crdv1alpha1.Source{Namespace: "default", Pod: "src"},
+			Destination: crdv1alpha1.Destination{Namespace: "default", Pod: "dst"},
+			Timeout:     10,
+		},
+	}
+	c := newFakeController(t, pc)
+	c.mockOFClient.EXPECT().InstallPacketCaptureFlows("pc1", gomock.Any(), uint16(10))
+	require.NoError(t, c.syncPacketCapture("pc1"))
+	assert.True(t, c.isRunning("pc1"))
+
+	// The packets dropped before being output by OVS, e.g. by NetworkPolicies, are not
+	// sent to the Antrea Agent, and the PacketCapture fails.
+	c.mockOFClient.EXPECT().UninstallPacketCaptureFlows("pc1")
+	c.completePacketCapture("pc1", true)
+	assert.False(t, c.isRunning("pc1"))
+
+	pc, err := c.crdClient.CrdV1alpha1().PacketCaptures().Get(context.TODO(), "pc1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, crdv1alpha1.PacketCaptureFailed, pc.Status.Phase)
+	assert.Equal(t, int32(0), pc.Status.NumCapturedPackets)
+	assert.Equal(t, "Timeout reached before any packet was captured, the packets may have been dropped before being output by OVS", pc.Status.Reason)
+}
+
+func TestPacketCaptureNotOwner(t *testing.T) {
+	pc := &crdv1alpha1.PacketCapture{
+		ObjectMeta: metav1.ObjectMeta{Name: "pc1"},
+		Spec: crdv1alpha1.PacketCaptureSpec{
+			Source:      crdv1alpha1.Source{Namespace: "default", Pod: "dst"},
+			Destination: crdv1alpha1.Destination{Namespace: "default", Pod: "src"},
+		},
+	}
+	c := newFakeController(t, pc)
+	require.NoError(t, c.syncPacketCapture("pc1"))
+	assert.False(t, c.isRunning("pc1"))
+}
+
+func TestPacketCaptureRequeuedForLocalPod(t *testing.T) {
+	pc := &crdv1alpha1.PacketCapture{
+		ObjectMeta: metav1.ObjectMeta{Name: "pc1"},
+		Spec: crdv1alpha1.PacketCaptureSpec{
+			Source:      crdv1alpha1.Source{Namespace: "default", Pod: "new"},
+			Destination: crdv1alpha1.Destination{Namespace: "default", Pod: "dst"},
+		},
+	}
+	c := newFakeController(t, pc)
+	require.NoError(t, c.syncPacketCapture("pc1"))
+	assert.False(t, c.isRunning("pc1"))
+
+	// The PacketCapture is not enqueued for other Pods.
+	c.addPod(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dst"}})
+	assert.Equal(t, 0, c.queue.Len())
+
+	// The PacketCapture is enqueued again once its source Pod runs on the Node.
+	newPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "new"}}
+	c.addPod(newPod)
+	assert.Equal(t, 1, c.queue.Len())
+	key, _ := c.queue.Get()
+	assert.Equal(t, "pc1", key)
+	c.queue.Done(key)
+
+	// The PacketCaptures which have started are not enqueued.
+	_, err := c.crdClient.CrdV1alpha1().PacketCaptures().UpdateStatus(context.TODO(), &crdv1alpha1.PacketCapture{
+		ObjectMeta: pc.ObjectMeta,
+		Spec:       pc.Spec,
+		Status:     crdv1alpha1.PacketCaptureStatus{Phase: crdv1alpha1.PacketCaptureRunning, Node: "node2"},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		pc, err := c.packetCaptureLister.Get("pc1")
+		return err == nil && pc.Status.Phase == crdv1alpha1.PacketCaptureRunning
+	}, time.Second, 10*time.Millisecond)
+	c.updatePod(newPod, newPod)
+	assert.Equal(t, 0, c.queue.Len())
+}
+
+func TestPacketMatches(t *testing.T) {
+	packet := &binding.Packet{
+		SourceIP:        srcPodIP,
+		DestinationIP:   dstPodIP,
+		IPProto:         protocol.Type_UDP,
+		SourcePort:      34567,
+		DestinationPort: 53,
+	}
+	tests := []struct {
+		name   string
+		filter *binding.Packet
+		want   bool
+	}{
+		{
+			name:   "empty filter",
+			filter: &binding.Packet{},
+			want:   true,
+		},
+		{
+			name:   "IPs and protocol",
+			filter: &binding.Packet{SourceIP: srcPodIP, DestinationIP: dstPodIP, IPProto: protocol.Type_UDP},
+			want:   true,
+		},
+		{
+			name:   "full 5-tuple",
+			filter: packet,
+			want:   true,
+		},
+		{
+			name:   "different source IP",
+			filter: &binding.Packet{SourceIP: dstPodIP},
+			want:   false,
+		},
+		{
+			name:   "different protocol",
+			filter: &binding.Packet{IPProto: protocol.Type_TCP},
+			want:   false,
+		},
+		{
+			name:   "different destination port",
+			filter: &binding.Packet{IPProto: protocol.Type_UDP, DestinationPort: 5353},
+			want:   false,
+		},
+		{
+			name:   "different IP family",
+			filter: &binding.Packet{IsIPv6: true},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, packetMatches(tt.filter, packet))
+		})
+	}
+}
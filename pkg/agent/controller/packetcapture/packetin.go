@@ -0,0 +1,90 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"antrea.io/ofnet/ofctrl"
+	"k8s.io/klog/v2"
+
+	binding "antrea.io/antrea/pkg/ovs/openflow"
+)
+
+// HandlePacketIn writes the packet to the capture files of all the running
+// PacketCaptures it matches. OVS may truncate the packet sent to the Agent, in
+// which case the original length of the packet is recorded in the file.
+func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
+	if !c.packetCaptureListerSynced() {
+		return errors.New("PacketCapture controller is not started")
+	}
+	packet, err := binding.ParsePacketIn(pktIn)
+	if err != nil {
+		return fmt.Errorf("error when parsing packet: %w", err)
+	}
+	data, err := pktIn.Data.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("error when serializing packet: %w", err)
+	}
+	timestamp := time.Now()
+
+	var completed []string
+	c.runningPacketCapturesMutex.Lock()
+	for name, pcState := range c.runningPacketCaptures {
+		if pcState.numCapturedPackets >= pcState.firstN || !packetMatches(pcState.packet, packet) {
+			continue
+		}
+		if err := pcState.writer.WritePacket(timestamp, data, int(pktIn.TotalLen)); err != nil {
+			klog.Errorf("Failed to write packet to the capture file of PacketCapture %s: %v", name, err)
+			continue
+		}
+		pcState.numCapturedPackets++
+		if pcState.numCapturedPackets >= pcState.firstN {
+			completed = append(completed, name)
+		}
+	}
+	c.runningPacketCapturesMutex.Unlock()
+
+	for _, name := range completed {
+		c.completePacketCapture(name, false)
+	}
+	return nil
+}
+
+// packetMatches returns whether the packet matches the filter of a
+// PacketCapture. The fields not set in the filter match any value.
+func packetMatches(filter, packet *binding.Packet) bool {
+	if filter.IsIPv6 != packet.IsIPv6 {
+		return false
+	}
+	if filter.SourceIP != nil && !filter.SourceIP.Equal(packet.SourceIP) {
+		return false
+	}
+	if filter.DestinationIP != nil && !filter.DestinationIP.Equal(packet.DestinationIP) {
+		return false
+	}
+	if filter.IPProto != 0 && filter.IPProto != packet.IPProto {
+		return false
+	}
+	if filter.SourcePort != 0 && filter.SourcePort != packet.SourcePort {
+		return false
+	}
+	if filter.DestinationPort != 0 && filter.DestinationPort != packet.DestinationPort {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,334 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/ipassigner"
+	"antrea.io/antrea/pkg/agent/memberlist"
+	"antrea.io/antrea/pkg/apis"
+)
+
+const (
+	controllerName = "AntreaAgentServiceExternalIPController"
+	// How long to wait before retrying the processing of a Service change.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// Default number of workers processing a Service change.
+	defaultWorkers = 4
+	// Disable resyncing.
+	resyncPeriod time.Duration = 0
+
+	externalIPPoolIndex = "externalIPPool"
+
+	// serviceExternalIPDummyDevice is the dummy device that holds the LoadBalancer IPs of Services configured to the
+	// system by antrea-agent. It's different from the Egress one so that the stale IPs of either feature can be
+	// cleaned up independently.
+	serviceExternalIPDummyDevice = "antrea-svc0"
+)
+
+// ServiceExternalIPController is responsible for announcing the LoadBalancer IPs allocated from ExternalIPPools for
+// Services. Each IP is owned by a single Node selected by the consistent hash of the memberlist cluster, and the
+// owner Node assigns the IP to a dummy device and advertises it with gratuitous ARP or NDP.
+type ServiceExternalIPController struct {
+	nodeName string
+
+	serviceLister       corelisters.ServiceLister
+	serviceListerSynced cache.InformerSynced
+	serviceIndexer      cache.Indexer
+	queue               workqueue.RateLimitingInterface
+
+	cluster    memberlist.Interface
+	ipAssigner ipassigner.IPAssigner
+
+	// assignedIPs is a map from Service key to the LoadBalancer IP assigned to this Node for the Service.
+	assignedIPs      map[string]string
+	assignedIPsMutex sync.Mutex
+}
+
+// NewServiceExternalIPController returns a new *ServiceExternalIPController.
+func NewServiceExternalIPController(
+	nodeName string,
+	nodeTransportIP net.IP,
+	cluster memberlist.Interface,
+	serviceInformer coreinformers.ServiceInformer,
+) (*ServiceExternalIPController, error) {
+	c := &ServiceExternalIPController{
+		nodeName:            nodeName,
+		serviceLister:       serviceInformer.Lister(),
+		serviceListerSynced: serviceInformer.Informer().HasSynced,
+		serviceIndexer:      serviceInformer.Informer().GetIndexer(),
+		queue:               workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "serviceExternalIP"),
+		cluster:             cluster,
+		assignedIPs:         map[string]string{},
+	}
+	ipAssigner, err := ipassigner.NewIPAssigner(nodeTransportIP, serviceExternalIPDummyDevice)
+	if err != nil {
+		return nil, fmt.Errorf("initializing Service external IP assigner failed: %v", err)
+	}
+	c.ipAssigner = ipAssigner
+
+	serviceInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addService,
+			UpdateFunc: c.updateService,
+			DeleteFunc: c.deleteService,
+		},
+		resyncPeriod,
+	)
+	// externalIPPoolIndex will be used to get all Services associated with a given ExternalIPPool.
+	serviceInformer.Informer().AddIndexers(cache.Indexers{externalIPPoolIndex: func(obj interface{}) ([]string, error) {
+		service, ok := obj.(*corev1.Service)
+		if !ok {
+			return nil, fmt.Errorf("obj is not Service: %+v", obj)
+		}
+		ipPool := getServiceExternalIPPool(service)
+		if ipPool == "" {
+			return nil, nil
+		}
+		return []string{ipPool}, nil
+	}})
+	c.cluster.AddClusterEventHandler(c.enqueueServicesByExternalIPPool)
+	return c, nil
+}
+
+// getServiceExternalIPPool returns the ExternalIPPool from which the LoadBalancer IP of the Service is allocated.
+func getServiceExternalIPPool(service *corev1.Service) string {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return ""
+	}
+	return service.Annotations[apis.ServiceExternalIPPoolAnnotationKey]
+}
+
+// getServiceExternalIP returns the first LoadBalancer IP in the status of the Service.
+func getServiceExternalIP(service *corev1.Service) string {
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
+
+func (c *ServiceExternalIPController) enqueueService(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get key of Service")
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *ServiceExternalIPController) addService(obj interface{}) {
+	service := obj.(*corev1.Service)
+	if getServiceExternalIPPool(service) == "" {
+		return
+	}
+	klog.V(2).InfoS("Processing Service ADD event", "service", klog.KObj(service))
+	c.enqueueService(service)
+}
+
+func (c *ServiceExternalIPController) updateService(oldObj, curObj interface{}) {
+	oldService := oldObj.(*corev1.Service)
+	curService := curObj.(*corev1.Service)
+	if getServiceExternalIPPool(oldService) == "" && getServiceExternalIPPool(curService) == "" {
+		return
+	}
+	if getServiceExternalIPPool(oldService) == getServiceExternalIPPool(curService) &&
+		getServiceExternalIP(oldService) == getServiceExternalIP(curService) {
+		return
+	}
+	klog.V(2).InfoS("Processing Service UPDATE event", "service", klog.KObj(curService))
+	c.enqueueService(curService)
+}
+
+func (c *ServiceExternalIPController) deleteService(obj interface{}) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		service, ok = deletedState.Obj.(*corev1.Service)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-Service object: %v", deletedState.Obj)
+			return
+		}
+	}
+	if getServiceExternalIPPool(service) == "" {
+		return
+	}
+	klog.V(2).InfoS("Processing Service DELETE event", "service", klog.KObj(service))
+	c.enqueueService(service)
+}
+
+// enqueueServicesByExternalIPPool enqueues all Services that refer to the provided ExternalIPPool, whose owner Nodes
+// may have changed because of a Node or ExternalIPPool event.
+func (c *ServiceExternalIPController) enqueueServicesByExternalIPPool(eipName string) {
+	objects, _ := c.serviceIndexer.ByIndex(externalIPPoolIndex, eipName)
+	for _, object := range objects {
+		c.enqueueService(object)
+	}
+	klog.InfoS("Detected ExternalIPPool event", "ExternalIPPool", eipName, "enqueueServiceNum", len(objects))
+}
+
+// Run will create defaultWorkers workers (go routines) which will process the Service events from the workqueue.
+func (c *ServiceExternalIPController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.serviceListerSynced) {
+		return
+	}
+
+	c.removeStaleExternalIPs()
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+// removeStaleExternalIPs unassigns the IPs that are not the LoadBalancer IP of any Service. The IPs that are still
+// used by Services are recorded so that they will be unassigned if this Node is no longer their owner.
+func (c *ServiceExternalIPController) removeStaleExternalIPs() {
+	serviceKeysByIP := map[string]string{}
+	services, _ := c.serviceLister.List(labels.Everything())
+	for _, service := range services {
+		if getServiceExternalIPPool(service) == "" {
+			continue
+		}
+		if ip := getServiceExternalIP(service); ip != "" {
+			key, _ := cache.MetaNamespaceKeyFunc(service)
+			serviceKeysByIP[ip] = key
+		}
+	}
+	c.assignedIPsMutex.Lock()
+	defer c.assignedIPsMutex.Unlock()
+	for ip := range c.ipAssigner.AssignedIPs() {
+		if key, exists := serviceKeysByIP[ip]; exists {
+			c.assignedIPs[key] = ip
+			continue
+		}
+		if err := c.ipAssigner.UnassignIP(ip); err != nil {
+			klog.ErrorS(err, "Failed to clean up stale Service external IP", "ip", ip)
+		}
+	}
+}
+
+// worker is a long-running function that will continually call the processNextWorkItem function in order to read
+// and process a message on the workqueue.
+func (c *ServiceExternalIPController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *ServiceExternalIPController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncService(key.(string)); err == nil {
+		// If no error occurs we Forget this item so it does not get queued again until
+		// another change happens.
+		c.queue.Forget(key)
+	} else {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.queue.AddRateLimited(key)
+		klog.ErrorS(err, "Syncing Service failed, requeue", "service", key)
+	}
+	return true
+}
+
+// getDesiredExternalIP returns the LoadBalancer IP of the Service if the local Node is selected to own it, otherwise
+// an empty string.
+func (c *ServiceExternalIPController) getDesiredExternalIP(service *corev1.Service) (string, error) {
+	ipPool := getServiceExternalIPPool(service)
+	ip := getServiceExternalIP(service)
+	if ipPool == "" || ip == "" {
+		return "", nil
+	}
+	selected, err := c.cluster.ShouldSelectIP(ip, ipPool)
+	if err != nil {
+		return "", err
+	}
+	if !selected {
+		return "", nil
+	}
+	return ip, nil
+}
+
+func (c *ServiceExternalIPController) syncService(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).InfoS("Finished syncing Service", "service", key, "durationTime", time.Since(startTime))
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	var desiredIP string
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		if desiredIP, err = c.getDesiredExternalIP(service); err != nil {
+			return err
+		}
+	}
+
+	c.assignedIPsMutex.Lock()
+	defer c.assignedIPsMutex.Unlock()
+	if prevIP, exists := c.assignedIPs[key]; exists && prevIP != desiredIP {
+		if err := c.ipAssigner.UnassignIP(prevIP); err != nil {
+			return err
+		}
+		delete(c.assignedIPs, key)
+		klog.InfoS("Unassigned Service external IP", "service", key, "ip", prevIP)
+	}
+	if desiredIP == "" {
+		return nil
+	}
+	if err := c.ipAssigner.AssignIP(desiredIP); err != nil {
+		return err
+	}
+	if _, exists := c.assignedIPs[key]; !exists {
+		c.assignedIPs[key] = desiredIP
+		klog.InfoS("Assigned Service external IP", "service", key, "ip", desiredIP, "node", c.nodeName)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+
+	ipassignertest "antrea.io/antrea/pkg/agent/ipassigner/testing"
+	memberlisttest "antrea.io/antrea/pkg/agent/memberlist/testing"
+	"antrea.io/antrea/pkg/apis"
+)
+
+const (
+	fakeNode   = "node1"
+	fakeIPPool = "pool1"
+	fakeIP1    = "1.1.1.1"
+	fakeIP2    = "1.1.1.2"
+)
+
+type fakeController struct {
+	*ServiceExternalIPController
+	mockCluster    *memberlisttest.MockInterface
+	mockIPAssigner *ipassignertest.MockIPAssigner
+}
+
+func newService(name, externalIPPool, ingressIP string) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	if externalIPPool != "" {
+		service.Annotations = map[string]string{apis.ServiceExternalIPPoolAnnotationKey: externalIPPool}
+	}
+	if ingressIP != "" {
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: ingressIP}}
+	}
+	return service
+}
+
+func newFakeController(t *testing.T, initObjects []runtime.Object) *fakeController {
+	controller := gomock.NewController(t)
+	mockCluster := memberlisttest.NewMockInterface(controller)
+	mockIPAssigner := ipassignertest.NewMockIPAssigner(controller)
+
+	client := fake.NewSimpleClientset(initObjects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	serviceInformer := informerFactory.Core().V1().Services()
+
+	c := &ServiceExternalIPController{
+		nodeName:            fakeNode,
+		serviceLister:       serviceInformer.Lister(),
+		serviceListerSynced: serviceInformer.Informer().HasSynced,
+		serviceIndexer:      serviceInformer.Informer().GetIndexer(),
+		queue:               workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "serviceExternalIP"),
+		cluster:             mockCluster,
+		ipAssigner:          mockIPAssigner,
+		assignedIPs:         map[string]string{},
+	}
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+	return &fakeController{
+		ServiceExternalIPController: c,
+		mockCluster:                 mockCluster,
+		mockIPAssigner:              mockIPAssigner,
+	}
+}
+
+func TestSyncService(t *testing.T) {
+	tests := []struct {
+		name                string
+		service             *corev1.Service
+		existingAssignedIPs map[string]string
+		expectedCalls       func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder)
+		expectedAssignedIPs map[string]string
+	}{
+		{
+			name:    "local Node selected",
+			service: newService("svc1", fakeIPPool, fakeIP1),
+			expectedCalls: func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder) {
+				mockCluster.ShouldSelectIP(fakeIP1, fakeIPPool).Return(true, nil)
+				mockIPAssigner.AssignIP(fakeIP1)
+			},
+			expectedAssignedIPs: map[string]string{"default/svc1": fakeIP1},
+		},
+		{
+			name:    "remote Node selected",
+			service: newService("svc1", fakeIPPool, fakeIP1),
+			expectedCalls: func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder) {
+				mockCluster.ShouldSelectIP(fakeIP1, fakeIPPool).Return(false, nil)
+			},
+			expectedAssignedIPs: map[string]string{},
+		},
+		{
+			name:                "IP moved to remote Node",
+			service:             newService("svc1", fakeIPPool, fakeIP1),
+			existingAssignedIPs: map[string]string{"default/svc1": fakeIP1},
+			expectedCalls: func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder) {
+				mockCluster.ShouldSelectIP(fakeIP1, fakeIPPool).Return(false, nil)
+				mockIPAssigner.UnassignIP(fakeIP1)
+			},
+			expectedAssignedIPs: map[string]string{},
+		},
+		{
+			name:                "IP changed",
+			service:             newService("svc1", fakeIPPool, fakeIP2),
+			existingAssignedIPs: map[string]string{"default/svc1": fakeIP1},
+			expectedCalls: func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder) {
+				mockCluster.ShouldSelectIP(fakeIP2, fakeIPPool).Return(true, nil)
+				mockIPAssigner.UnassignIP(fakeIP1)
+				mockIPAssigner.AssignIP(fakeIP2)
+			},
+			expectedAssignedIPs: map[string]string{"default/svc1": fakeIP2},
+		},
+		{
+			name:                "annotation removed",
+			service:             newService("svc1", "", fakeIP1),
+			existingAssignedIPs: map[string]string{"default/svc1": fakeIP1},
+			expectedCalls: func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder) {
+				mockIPAssigner.UnassignIP(fakeIP1)
+			},
+			expectedAssignedIPs: map[string]string{},
+		},
+		{
+			name:                "Service deleted",
+			existingAssignedIPs: map[string]string{"default/svc1": fakeIP1},
+			expectedCalls: func(mockCluster *memberlisttest.MockInterfaceMockRecorder, mockIPAssigner *ipassignertest.MockIPAssignerMockRecorder) {
+				mockIPAssigner.UnassignIP(fakeIP1)
+			},
+			expectedAssignedIPs: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []runtime.Object
+			if tt.service != nil {
+				objects = append(objects, tt.service)
+			}
+			c := newFakeController(t, objects)
+			for key, ip := range tt.existingAssignedIPs {
+				c.assignedIPs[key] = ip
+			}
+			tt.expectedCalls(c.mockCluster.EXPECT(), c.mockIPAssigner.EXPECT())
+			require.NoError(t, c.syncService("default/svc1"))
+			assert.Equal(t, tt.expectedAssignedIPs, c.assignedIPs)
+		})
+	}
+}
+
+func TestRemoveStaleExternalIPs(t *testing.T) {
+	c := newFakeController(t, []runtime.Object{newService("svc1", fakeIPPool, fakeIP1)})
+	c.mockIPAssigner.EXPECT().AssignedIPs().Return(sets.NewString(fakeIP1, fakeIP2))
+	c.mockIPAssigner.EXPECT().UnassignIP(fakeIP2)
+	c.removeStaleExternalIPs()
+	assert.Equal(t, map[string]string{"default/svc1": fakeIP1}, c.assignedIPs)
+}
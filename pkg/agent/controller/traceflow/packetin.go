@@ -25,12 +25,14 @@ import (
 	"antrea.io/libOpenflow/protocol"
 	"antrea.io/ofnet/ofctrl"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/openflow"
 	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	"antrea.io/antrea/pkg/features"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 )
 
@@ -76,7 +78,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 	// Get data plane tag.
 	// Directly read data plane tag from packet.
 	var err error
-	var tag uint8
+	var tag, ttl uint8
 	var ctNwDst, ctNwSrc, ipDst, ipSrc string
 	if pktIn.Data.Ethertype == protocol.IPv4_MSG {
 		ipPacket, ok := pktIn.Data.Data.(*protocol.IPv4)
@@ -84,6 +86,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 			return nil, nil, nil, errors.New("invalid traceflow IPv4 packet")
 		}
 		tag = ipPacket.DSCP
+		ttl = ipPacket.TTL
 		ctNwDst, err = getCTDstValue(matchers, false)
 		if err != nil {
 			return nil, nil, nil, err
@@ -100,6 +103,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 			return nil, nil, nil, errors.New("invalid traceflow IPv6 packet")
 		}
 		tag = ipv6Packet.TrafficClass >> 2
+		ttl = ipv6Packet.HopLimit
 		ctNwDst, err = getCTDstValue(matchers, true)
 		if err != nil {
 			return nil, nil, nil, err
@@ -148,15 +152,20 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 
 	obs := []crdv1alpha1.Observation{}
 	tableID := pktIn.TableId
+	// The TTL of the packet is recorded in the first Observation. It is
+	// used by the Antrea Controller to order the results of the Nodes the
+	// packet went through.
 	if tfState.isSender {
 		ob := new(crdv1alpha1.Observation)
 		ob.Component = crdv1alpha1.ComponentSpoofGuard
 		ob.Action = crdv1alpha1.ActionForwarded
+		ob.TTL = int32(ttl)
 		obs = append(obs, *ob)
 	} else {
 		ob := new(crdv1alpha1.Observation)
 		ob.Component = crdv1alpha1.ComponentForwarding
 		ob.Action = crdv1alpha1.ActionReceived
+		ob.TTL = int32(ttl)
 		obs = append(obs, *ob)
 	}
 
@@ -249,19 +258,13 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 		if pktIn.Data.Ethertype == protocol.IPv6_MSG {
 			gatewayIP = c.nodeConfig.GatewayConfig.IPv6
 		}
+		if egressOb := c.getEgressObservation(tf, tfState, outputPort, tunnelDstIP, ipDst); egressOb != nil {
+			obs = append(obs, *egressOb)
+		}
 		if c.networkConfig.TrafficEncapMode.SupportsEncap() && outputPort == config.DefaultTunOFPort {
 			ob.TunnelDstIP = tunnelDstIP
-			ob.Action = crdv1alpha1.ActionForwarded
-		} else if ipDst == gatewayIP.String() && outputPort == config.HostGatewayOFPort {
-			ob.Action = crdv1alpha1.ActionDelivered
-		} else if c.networkConfig.TrafficEncapMode.SupportsEncap() && outputPort == config.HostGatewayOFPort {
-			ob.Action = crdv1alpha1.ActionForwardedOutOfOverlay
-		} else if outputPort == config.HostGatewayOFPort { // noEncap
-			ob.Action = crdv1alpha1.ActionForwarded
-		} else {
-			// Output port is Pod port, packet is delivered.
-			ob.Action = crdv1alpha1.ActionDelivered
 		}
+		ob.Action = c.getOutputAction(tfState, outputPort, ipDst, gatewayIP)
 		ob.ComponentInfo = openflow.L2ForwardingOutTable.GetName()
 		ob.Component = crdv1alpha1.ComponentForwarding
 		obs = append(obs, *ob)
@@ -271,6 +274,116 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 	return tf, &nodeResult, capturedPacket, nil
 }
 
+// getOutputAction returns the action of the Forwarding Observation of a
+// Traceflow packet output to the provided port.
+func (c *Controller) getOutputAction(tfState *traceflowState, outputPort uint32, dstIP string, gatewayIP net.IP) crdv1alpha1.TraceflowAction {
+	if c.networkConfig.TrafficEncapMode.SupportsEncap() && outputPort == config.DefaultTunOFPort {
+		return crdv1alpha1.ActionForwarded
+	} else if dstIP == gatewayIP.String() && outputPort == config.HostGatewayOFPort {
+		return crdv1alpha1.ActionDelivered
+	} else if c.networkConfig.TrafficEncapMode.SupportsEncap() && outputPort == config.HostGatewayOFPort {
+		if tfState.multiHop {
+			// The packet leaves the overlay but re-enters the pipeline
+			// on the Node serving the NodePort or LoadBalancer IP, which
+			// will report the following hops.
+			return crdv1alpha1.ActionForwarded
+		}
+		return crdv1alpha1.ActionForwardedOutOfOverlay
+	} else if outputPort == config.HostGatewayOFPort { // noEncap
+		return crdv1alpha1.ActionForwarded
+	}
+	// Output port is Pod port, packet is delivered.
+	return crdv1alpha1.ActionDelivered
+}
+
+// getEgressObservation returns an Observation of the Egress applied to the
+// Traceflow packet, or nil if no Egress applies. On the Node of the source Pod,
+// the packet is either marked for SNAT when the Egress IP is local, or tunnelled
+// to the Egress Node. On the Egress Node, the packet tunnelled from the source
+// Node is marked for SNAT with the Egress IP, which is the tunnel destination.
+// Like the Egress SNAT flows, the packets sent to in-cluster destinations are
+// never SNAT'd.
+func (c *Controller) getEgressObservation(tf *crdv1alpha1.Traceflow, tfState *traceflowState, outputPort uint32, tunnelDstIP, dstIP string) *crdv1alpha1.Observation {
+	if c.egressQuerier == nil || tfState.multiHop || c.isEgressExemptDestination(net.ParseIP(dstIP)) {
+		return nil
+	}
+	if tfState.isSender {
+		egressName, egressIP, egressNode, err := c.egressQuerier.GetEgress(tf.Spec.Source.Namespace, tf.Spec.Source.Pod)
+		if err != nil {
+			return nil
+		}
+		ob := &crdv1alpha1.Observation{
+			Component:  crdv1alpha1.ComponentEgress,
+			Egress:     egressName,
+			EgressIP:   egressIP,
+			EgressNode: egressNode,
+		}
+		if outputPort == config.HostGatewayOFPort && egressNode == c.nodeConfig.Name {
+			ob.Action = crdv1alpha1.ActionMarkedForSNAT
+			return ob
+		}
+		if outputPort == config.DefaultTunOFPort && tunnelDstIP == egressIP {
+			ob.Action = crdv1alpha1.ActionForwardedToEgressNode
+			return ob
+		}
+		return nil
+	}
+	if outputPort != config.HostGatewayOFPort || tunnelDstIP == "" {
+		return nil
+	}
+	egressName, ok := c.egressQuerier.GetEgressByIP(tunnelDstIP)
+	if !ok {
+		return nil
+	}
+	return &crdv1alpha1.Observation{
+		Component:  crdv1alpha1.ComponentEgress,
+		Action:     crdv1alpha1.ActionMarkedForSNAT,
+		Egress:     egressName,
+		EgressIP:   tunnelDstIP,
+		EgressNode: c.nodeConfig.Name,
+	}
+}
+
+// isEgressExemptDestination returns whether the destination IP is the IP of a
+// Pod, a Service or a Node of the cluster, or is in the Egress except CIDRs,
+// in which case the Egress SNAT flows don't apply to the packet.
+func (c *Controller) isEgressExemptDestination(dstIP net.IP) bool {
+	if dstIP == nil {
+		return true
+	}
+	// When AntreaProxy is enabled, the packets to ClusterIPs are DNAT'd before
+	// being output, and serviceCIDR may not match the cluster configuration.
+	if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) && c.serviceCIDR != nil && c.serviceCIDR.Contains(dstIP) {
+		return true
+	}
+	for _, cidr := range []*net.IPNet{c.nodeConfig.PodIPv4CIDR, c.nodeConfig.PodIPv6CIDR} {
+		if cidr != nil && cidr.Contains(dstIP) {
+			return true
+		}
+	}
+	if c.egressConfig != nil {
+		for _, cidr := range c.egressConfig.ExceptCIDRs {
+			if cidr.Contains(dstIP) {
+				return true
+			}
+		}
+	}
+	nodes, _ := c.nodeLister.List(labels.Everything())
+	for _, node := range nodes {
+		for _, addr := range node.Status.Addresses {
+			if dstIP.Equal(net.ParseIP(addr.Address)) {
+				return true
+			}
+		}
+		for _, podCIDR := range node.Spec.PodCIDRs {
+			if _, cidr, err := net.ParseCIDR(podCIDR); err == nil && cidr.Contains(dstIP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func getMatchRegField(matchers *ofctrl.Matchers, field *binding.RegField) *ofctrl.MatchField {
 	return matchers.GetMatchByName(field.GetNXFieldName())
 }
@@ -22,10 +22,19 @@ import (
 	"antrea.io/libOpenflow/protocol"
 	"antrea.io/libOpenflow/util"
 	"antrea.io/ofnet/ofctrl"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
 
+	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/openflow"
 	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	queriertest "antrea.io/antrea/pkg/querier/testing"
 )
 
 func Test_getNetworkPolicyObservation(t *testing.T) {
@@ -160,3 +169,228 @@ func TestParseCapturedPacket(t *testing.T) {
 		})
 	}
 }
+
+func newTestController(t *testing.T, objects ...runtime.Object) *Controller {
+	kubeClient := fake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	serviceInformer := informerFactory.Core().V1().Services()
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.Node:
+			require.NoError(t, nodeInformer.Informer().GetIndexer().Add(o))
+		case *corev1.Service:
+			require.NoError(t, serviceInformer.Informer().GetIndexer().Add(o))
+		}
+	}
+	_, podCIDR, _ := net.ParseCIDR("10.10.0.0/24")
+	_, exceptCIDR, _ := net.ParseCIDR("172.16.0.0/16")
+	return &Controller{
+		kubeClient:    kubeClient,
+		nodeLister:    nodeInformer.Lister(),
+		serviceLister: serviceInformer.Lister(),
+		networkConfig: &config.NetworkConfig{TrafficEncapMode: config.TrafficEncapModeEncap},
+		nodeConfig: &config.NodeConfig{
+			Name:        "node1",
+			PodIPv4CIDR: podCIDR,
+			GatewayConfig: &config.GatewayConfig{
+				IPv4: net.ParseIP("10.10.0.1"),
+			},
+		},
+		egressConfig: &config.EgressConfig{ExceptCIDRs: []net.IPNet{*exceptCIDR}},
+	}
+}
+
+func newTestNode(name, ip, podCIDR string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{PodCIDR: podCIDR, PodCIDRs: []string{podCIDR}},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+		},
+	}
+}
+
+func TestGetOutputAction(t *testing.T) {
+	c := newTestController(t)
+	gatewayIP := c.nodeConfig.GatewayConfig.IPv4
+	tests := []struct {
+		name       string
+		tfState    *traceflowState
+		outputPort uint32
+		dstIP      string
+		noEncap    bool
+		expected   crdv1alpha1.TraceflowAction
+	}{
+		{
+			name:       "sender to Node IP",
+			tfState:    &traceflowState{isSender: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      "192.168.77.102",
+			expected:   crdv1alpha1.ActionForwardedOutOfOverlay,
+		},
+		{
+			name:       "sender to NodePort",
+			tfState:    &traceflowState{isSender: true, multiHop: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      "192.168.77.102",
+			expected:   crdv1alpha1.ActionForwarded,
+		},
+		{
+			name:       "sender to local gateway",
+			tfState:    &traceflowState{isSender: true, multiHop: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      "10.10.0.1",
+			expected:   crdv1alpha1.ActionDelivered,
+		},
+		{
+			name:       "NodePort Node forwarding to remote Endpoint",
+			tfState:    &traceflowState{multiHop: true},
+			outputPort: config.DefaultTunOFPort,
+			dstIP:      "10.10.1.5",
+			expected:   crdv1alpha1.ActionForwarded,
+		},
+		{
+			name:       "NodePort Node delivering to local Endpoint",
+			tfState:    &traceflowState{multiHop: true},
+			outputPort: 5,
+			dstIP:      "10.10.0.5",
+			expected:   crdv1alpha1.ActionDelivered,
+		},
+		{
+			name:       "noEncap to gateway",
+			tfState:    &traceflowState{isSender: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      "10.10.1.5",
+			noEncap:    true,
+			expected:   crdv1alpha1.ActionForwarded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.networkConfig.TrafficEncapMode = config.TrafficEncapModeEncap
+			if tt.noEncap {
+				c.networkConfig.TrafficEncapMode = config.TrafficEncapModeNoEncap
+			}
+			assert.Equal(t, tt.expected, c.getOutputAction(tt.tfState, tt.outputPort, tt.dstIP, gatewayIP))
+		})
+	}
+}
+
+func TestGetEgressObservation(t *testing.T) {
+	const (
+		egressName   = "egress1"
+		egressIP     = "192.168.77.200"
+		externalIP   = "8.8.8.8"
+		remotePodIP  = "10.10.1.5"
+		remoteNodeIP = "192.168.77.102"
+	)
+	tf := &crdv1alpha1.Traceflow{
+		Spec: crdv1alpha1.TraceflowSpec{
+			Source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+		},
+	}
+	tests := []struct {
+		name        string
+		tfState     *traceflowState
+		outputPort  uint32
+		tunnelDstIP string
+		dstIP       string
+		egressNode  string
+		expected    *crdv1alpha1.Observation
+	}{
+		{
+			name:       "sender on Egress Node",
+			tfState:    &traceflowState{isSender: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      externalIP,
+			egressNode: "node1",
+			expected: &crdv1alpha1.Observation{
+				Component:  crdv1alpha1.ComponentEgress,
+				Action:     crdv1alpha1.ActionMarkedForSNAT,
+				Egress:     egressName,
+				EgressIP:   egressIP,
+				EgressNode: "node1",
+			},
+		},
+		{
+			name:        "sender tunnelling to Egress Node",
+			tfState:     &traceflowState{isSender: true},
+			outputPort:  config.DefaultTunOFPort,
+			tunnelDstIP: egressIP,
+			dstIP:       externalIP,
+			egressNode:  "node2",
+			expected: &crdv1alpha1.Observation{
+				Component:  crdv1alpha1.ComponentEgress,
+				Action:     crdv1alpha1.ActionForwardedToEgressNode,
+				Egress:     egressName,
+				EgressIP:   egressIP,
+				EgressNode: "node2",
+			},
+		},
+		{
+			name:       "sender on Egress Node to Node IP",
+			tfState:    &traceflowState{isSender: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      remoteNodeIP,
+			egressNode: "node1",
+		},
+		{
+			name:        "sender on Egress Node to remote Pod",
+			tfState:     &traceflowState{isSender: true},
+			outputPort:  config.DefaultTunOFPort,
+			tunnelDstIP: remoteNodeIP,
+			dstIP:       remotePodIP,
+			egressNode:  "node1",
+		},
+		{
+			name:       "sender on Egress Node to except CIDR",
+			tfState:    &traceflowState{isSender: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      "172.16.1.1",
+			egressNode: "node1",
+		},
+		{
+			name:       "sender to NodePort",
+			tfState:    &traceflowState{isSender: true, multiHop: true},
+			outputPort: config.HostGatewayOFPort,
+			dstIP:      externalIP,
+			egressNode: "node1",
+		},
+		{
+			name:        "Egress Node",
+			tfState:     &traceflowState{},
+			outputPort:  config.HostGatewayOFPort,
+			tunnelDstIP: egressIP,
+			dstIP:       externalIP,
+			expected: &crdv1alpha1.Observation{
+				Component:  crdv1alpha1.ComponentEgress,
+				Action:     crdv1alpha1.ActionMarkedForSNAT,
+				Egress:     egressName,
+				EgressIP:   egressIP,
+				EgressNode: "node1",
+			},
+		},
+		{
+			name:        "Egress Node to local Pod",
+			tfState:     &traceflowState{},
+			outputPort:  config.HostGatewayOFPort,
+			tunnelDstIP: egressIP,
+			dstIP:       "10.10.0.5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := newTestController(t,
+				newTestNode("node1", "192.168.77.101", "10.10.0.0/24"),
+				newTestNode("node2", remoteNodeIP, "10.10.1.0/24"))
+			egressQuerier := queriertest.NewMockEgressQuerier(ctrl)
+			egressQuerier.EXPECT().GetEgress("ns1", "pod1").Return(egressName, egressIP, tt.egressNode, nil).AnyTimes()
+			egressQuerier.EXPECT().GetEgressByIP(egressIP).Return(egressName, true).AnyTimes()
+			c.egressQuerier = egressQuerier
+			assert.Equal(t, tt.expected, c.getEgressObservation(tf, tt.tfState, tt.outputPort, tt.tunnelDstIP, tt.dstIP))
+		})
+	}
+}
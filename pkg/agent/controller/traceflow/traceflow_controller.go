@@ -24,8 +24,10 @@ import (
 	"time"
 
 	"antrea.io/libOpenflow/protocol"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
@@ -79,6 +81,10 @@ type traceflowState struct {
 	// Live-traffic Traceflow with only destination Pod specified.
 	receiverOnly bool
 	isSender     bool
+	// The destination is a NodePort or LoadBalancer IP served by AntreaProxy,
+	// and the packet is expected to re-enter the pipeline after leaving the
+	// overlay.
+	multiHop bool
 	// Agent received the first Traceflow packet from OVS.
 	receivedPacket bool
 }
@@ -89,6 +95,8 @@ type Controller struct {
 	kubeClient             clientset.Interface
 	serviceLister          corelisters.ServiceLister
 	serviceListerSynced    cache.InformerSynced
+	nodeLister             corelisters.NodeLister
+	nodeListerSynced       cache.InformerSynced
 	traceflowClient        clientsetversioned.Interface
 	traceflowInformer      crdinformers.TraceflowInformer
 	traceflowLister        crdlisters.TraceflowLister
@@ -96,10 +104,13 @@ type Controller struct {
 	ovsBridgeClient        ovsconfig.OVSBridgeClient
 	ofClient               openflow.Client
 	networkPolicyQuerier   querier.AgentNetworkPolicyInfoQuerier
+	egressQuerier          querier.EgressQuerier
 	interfaceStore         interfacestore.InterfaceStore
 	networkConfig          *config.NetworkConfig
 	nodeConfig             *config.NodeConfig
 	serviceCIDR            *net.IPNet // K8s Service ClusterIP CIDR
+	egressConfig           *config.EgressConfig
+	proxyAll               bool
 	queue                  workqueue.RateLimitingInterface
 	runningTraceflowsMutex sync.RWMutex
 	// runningTraceflows is a map for storing the running Traceflow state
@@ -116,24 +127,32 @@ func NewTraceflowController(
 	traceflowInformer crdinformers.TraceflowInformer,
 	client openflow.Client,
 	npQuerier querier.AgentNetworkPolicyInfoQuerier,
+	egressQuerier querier.EgressQuerier,
 	ovsBridgeClient ovsconfig.OVSBridgeClient,
 	interfaceStore interfacestore.InterfaceStore,
 	networkConfig *config.NetworkConfig,
 	nodeConfig *config.NodeConfig,
-	serviceCIDR *net.IPNet) *Controller {
+	serviceCIDR *net.IPNet,
+	egressConfig *config.EgressConfig,
+	proxyAll bool) *Controller {
 	c := &Controller{
 		kubeClient:            kubeClient,
 		traceflowClient:       traceflowClient,
 		traceflowInformer:     traceflowInformer,
 		traceflowLister:       traceflowInformer.Lister(),
 		traceflowListerSynced: traceflowInformer.Informer().HasSynced,
+		nodeLister:            informerFactory.Core().V1().Nodes().Lister(),
+		nodeListerSynced:      informerFactory.Core().V1().Nodes().Informer().HasSynced,
 		ovsBridgeClient:       ovsBridgeClient,
 		ofClient:              client,
 		networkPolicyQuerier:  npQuerier,
+		egressQuerier:         egressQuerier,
 		interfaceStore:        interfaceStore,
 		networkConfig:         networkConfig,
 		nodeConfig:            nodeConfig,
 		serviceCIDR:           serviceCIDR,
+		egressConfig:          egressConfig,
+		proxyAll:              proxyAll,
 		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "traceflow"),
 		runningTraceflows:     make(map[uint8]*traceflowState),
 	}
@@ -170,7 +189,7 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	klog.Infof("Starting %s", controllerName)
 	defer klog.Infof("Shutting down %s", controllerName)
 
-	cacheSyncs := []cache.InformerSynced{c.traceflowListerSynced}
+	cacheSyncs := []cache.InformerSynced{c.traceflowListerSynced, c.nodeListerSynced}
 	if features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 		cacheSyncs = append(cacheSyncs, c.serviceListerSynced)
 	}
@@ -345,7 +364,8 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 	tfState := traceflowState{
 		name: tf.Name, tag: tf.Status.DataplaneTag,
 		liveTraffic: liveTraffic, droppedOnly: tf.Spec.DroppedOnly && liveTraffic,
-		receiverOnly: receiverOnly, isSender: isSender}
+		receiverOnly: receiverOnly, isSender: isSender,
+		multiHop: c.isMultiHopDestination(tf)}
 	c.runningTraceflows[tfState.tag] = &tfState
 	c.runningTraceflowsMutex.Unlock()
 
@@ -355,7 +375,7 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 	if timeout == 0 {
 		timeout = crdv1alpha1.DefaultTraceflowTimeout
 	}
-	err = c.ofClient.InstallTraceflowFlows(tfState.tag, liveTraffic, tfState.droppedOnly, receiverOnly, matchPacket, ofPort, timeout)
+	err = c.ofClient.InstallTraceflowFlows(tfState.tag, liveTraffic, tfState.droppedOnly, receiverOnly, tfState.multiHop, matchPacket, ofPort, timeout)
 	if err != nil {
 		return err
 	}
@@ -396,6 +416,59 @@ func (c *Controller) validateTraceflow(tf *crdv1alpha1.Traceflow) error {
 	return nil
 }
 
+// isMultiHopDestination returns whether the Traceflow packet is sent to a
+// NodePort or to a LoadBalancer IP served by AntreaProxy. Such a packet leaves
+// the overlay through the gateway and re-enters the OVS pipeline on the Node
+// serving the NodePort or LoadBalancer IP, which may forward it to a Service
+// Endpoint on a third Node. This is only the case when AntreaProxy proxies all
+// Service traffic, otherwise the packet is handled by kube-proxy and doesn't
+// re-enter the pipeline as a Traceflow packet.
+func (c *Controller) isMultiHopDestination(tf *crdv1alpha1.Traceflow) bool {
+	if !c.proxyAll || c.serviceLister == nil || tf.Spec.Destination.IP == "" {
+		return false
+	}
+	dstIP := tf.Spec.Destination.IP
+	var svcProtocol corev1.Protocol
+	var dstPort int32
+	if tf.Spec.Packet.TransportHeader.TCP != nil {
+		svcProtocol, dstPort = corev1.ProtocolTCP, tf.Spec.Packet.TransportHeader.TCP.DstPort
+	} else if tf.Spec.Packet.TransportHeader.UDP != nil {
+		svcProtocol, dstPort = corev1.ProtocolUDP, tf.Spec.Packet.TransportHeader.UDP.DstPort
+	}
+	if dstPort == 0 {
+		return false
+	}
+	isNodeIP := false
+	nodes, _ := c.nodeLister.List(labels.Everything())
+	for _, node := range nodes {
+		for _, addr := range node.Status.Addresses {
+			if addr.Address == dstIP {
+				isNodeIP = true
+			}
+		}
+	}
+	services, _ := c.serviceLister.List(labels.Everything())
+	for _, svc := range services {
+		for _, port := range svc.Spec.Ports {
+			if port.Protocol != svcProtocol {
+				continue
+			}
+			if isNodeIP && port.NodePort == dstPort {
+				return true
+			}
+			if port.Port != dstPort {
+				continue
+			}
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				if ingress.IP == dstIP {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (c *Controller) preparePacket(tf *crdv1alpha1.Traceflow, intf *interfacestore.InterfaceConfig, receiverOnly bool) (*binding.Packet, error) {
 	liveTraffic := tf.Spec.LiveTraffic
 	isICMP := false
@@ -0,0 +1,125 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+)
+
+func TestIsMultiHopDestination(t *testing.T) {
+	nodePortSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "svc1"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 80, NodePort: 30080}},
+		},
+	}
+	loadBalancerSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "svc2"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 443, NodePort: 30443}},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.168.88.10"}}},
+		},
+	}
+	newTraceflow := func(dstIP string, tcpPort, udpPort int32) *crdv1alpha1.Traceflow {
+		tf := &crdv1alpha1.Traceflow{
+			Spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{IP: dstIP},
+			},
+		}
+		if tcpPort != 0 {
+			tf.Spec.Packet.TransportHeader.TCP = &crdv1alpha1.TCPHeader{DstPort: tcpPort}
+		}
+		if udpPort != 0 {
+			tf.Spec.Packet.TransportHeader.UDP = &crdv1alpha1.UDPHeader{DstPort: udpPort}
+		}
+		return tf
+	}
+	tests := []struct {
+		name     string
+		tf       *crdv1alpha1.Traceflow
+		proxyAll bool
+		expected bool
+	}{
+		{
+			name:     "NodePort",
+			tf:       newTraceflow("192.168.77.102", 30080, 0),
+			proxyAll: true,
+			expected: true,
+		},
+		{
+			name:     "NodePort without proxyAll",
+			tf:       newTraceflow("192.168.77.102", 30080, 0),
+			proxyAll: false,
+			expected: false,
+		},
+		{
+			name:     "Node IP",
+			tf:       newTraceflow("192.168.77.102", 22, 0),
+			proxyAll: true,
+			expected: false,
+		},
+		{
+			name:     "Node IP without port",
+			tf:       newTraceflow("192.168.77.102", 0, 0),
+			proxyAll: true,
+			expected: false,
+		},
+		{
+			name:     "NodePort with other protocol",
+			tf:       newTraceflow("192.168.77.102", 0, 30080),
+			proxyAll: true,
+			expected: false,
+		},
+		{
+			name:     "LoadBalancer IP",
+			tf:       newTraceflow("192.168.88.10", 443, 0),
+			proxyAll: true,
+			expected: true,
+		},
+		{
+			name:     "LoadBalancer IP with other port",
+			tf:       newTraceflow("192.168.88.10", 80, 0),
+			proxyAll: true,
+			expected: false,
+		},
+		{
+			name:     "external IP",
+			tf:       newTraceflow("8.8.8.8", 30080, 0),
+			proxyAll: true,
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t,
+				newTestNode("node1", "192.168.77.101", "10.10.0.0/24"),
+				newTestNode("node2", "192.168.77.102", "10.10.1.0/24"),
+				nodePortSvc, loadBalancerSvc)
+			c.proxyAll = tt.proxyAll
+			assert.Equal(t, tt.expected, c.isMultiHopDestination(tt.tf))
+		})
+	}
+}
@@ -21,12 +21,14 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/priorityqueue"
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/proxy"
+	"antrea.io/antrea/pkg/querier"
 )
 
 const (
@@ -35,21 +37,33 @@ const (
 
 type connectionStore struct {
 	connections            map[flowexporter.ConnectionKey]*flowexporter.Connection
+	nodeName               string
 	ifaceStore             interfacestore.InterfaceStore
 	antreaProxier          proxy.Proxier
+	serviceLister          corelisters.ServiceLister
+	egressQuerier          querier.EgressQuerier
 	expirePriorityQueue    *priorityqueue.ExpirePriorityQueue
 	staleConnectionTimeout time.Duration
 	mutex                  sync.Mutex
 }
 
+// NewConnectionStore creates a connectionStore. serviceLister and egressQuerier are
+// optional: the Service type and the Egress of connections are not resolved when they
+// are nil.
 func NewConnectionStore(
+	nodeName string,
 	ifaceStore interfacestore.InterfaceStore,
 	proxier proxy.Proxier,
+	serviceLister corelisters.ServiceLister,
+	egressQuerier querier.EgressQuerier,
 	o *flowexporter.FlowExporterOptions) connectionStore {
 	return connectionStore{
 		connections:            make(map[flowexporter.ConnectionKey]*flowexporter.Connection),
+		nodeName:               nodeName,
 		ifaceStore:             ifaceStore,
 		antreaProxier:          proxier,
+		serviceLister:          serviceLister,
+		egressQuerier:          egressQuerier,
 		expirePriorityQueue:    priorityqueue.NewExpirePriorityQueue(o.ActiveFlowTimeout, o.IdleFlowTimeout),
 		staleConnectionTimeout: o.StaleConnectionTimeout,
 	}
@@ -102,13 +116,31 @@ func (cs *connectionStore) fillPodInfo(conn *flowexporter.Connection) {
 	if srcFound && sIface.Type == interfacestore.ContainerInterface {
 		conn.SourcePodName = sIface.ContainerInterfaceConfig.PodName
 		conn.SourcePodNamespace = sIface.ContainerInterfaceConfig.PodNamespace
+		conn.SourceNodeName = cs.nodeName
 	}
 	if dstFound && dIface.Type == interfacestore.ContainerInterface {
 		conn.DestinationPodName = dIface.ContainerInterfaceConfig.PodName
 		conn.DestinationPodNamespace = dIface.ContainerInterfaceConfig.PodNamespace
+		conn.DestinationNodeName = cs.nodeName
 	}
 }
 
+// fillEgressInfo resolves the Egress applied to the local source Pod of the
+// connection, if any. It must be called after fillPodInfo.
+func (cs *connectionStore) fillEgressInfo(conn *flowexporter.Connection) {
+	if cs.egressQuerier == nil || conn.SourcePodName == "" {
+		return
+	}
+	egressName, egressIP, _, err := cs.egressQuerier.GetEgress(conn.SourcePodNamespace, conn.SourcePodName)
+	if err != nil {
+		// Most Pods are not selected by any Egress.
+		klog.V(5).InfoS("No Egress found for Pod", "pod", klog.KRef(conn.SourcePodNamespace, conn.SourcePodName), "err", err)
+		return
+	}
+	conn.EgressName = egressName
+	conn.EgressIP = egressIP
+}
+
 func (cs *connectionStore) fillServiceInfo(conn *flowexporter.Connection, serviceStr string) {
 	// resolve destination Service information
 	if cs.antreaProxier != nil {
@@ -117,10 +149,23 @@ func (cs *connectionStore) fillServiceInfo(conn *flowexporter.Connection, servic
 			klog.Warningf("Could not retrieve the Service info from antrea-agent-proxier for the serviceStr: %s", serviceStr)
 		} else {
 			conn.DestinationServicePortName = servicePortName.String()
+			conn.DestinationServiceType = cs.getServiceType(servicePortName.Namespace, servicePortName.Name)
 		}
 	}
 }
 
+func (cs *connectionStore) getServiceType(namespace, name string) string {
+	if cs.serviceLister == nil {
+		return ""
+	}
+	service, err := cs.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		klog.V(4).InfoS("Could not retrieve the Service type", "service", klog.KRef(namespace, name), "err", err)
+		return ""
+	}
+	return string(service.Spec.Type)
+}
+
 // LookupServiceProtocol returns the corresponding Service protocol string for a given protocol identifier
 func lookupServiceProtocol(protoID uint8) (corev1.Protocol, error) {
 	serviceProto, found := serviceProtocolMap[protoID]
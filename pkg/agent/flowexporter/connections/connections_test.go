@@ -15,17 +15,23 @@
 package connections
 
 import (
+	"fmt"
 	"net"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	connectionstest "antrea.io/antrea/pkg/agent/flowexporter/connections/testing"
 	interfacestoretest "antrea.io/antrea/pkg/agent/interfacestore/testing"
 	"antrea.io/antrea/pkg/agent/metrics"
+	queriertest "antrea.io/antrea/pkg/querier/testing"
 )
 
 const (
@@ -81,7 +87,7 @@ func TestConnectionStore_ForAllConnectionsDo(t *testing.T) {
 	}
 	// Create connectionStore
 	mockIfaceStore := interfacestoretest.NewMockInterfaceStore(ctrl)
-	connStore := NewConnectionStore(mockIfaceStore, nil, testFlowExporterOptions)
+	connStore := NewConnectionStore("", mockIfaceStore, nil, nil, nil, testFlowExporterOptions)
 	// Add flows to the Connection store
 	for i, flow := range testFlows {
 		connStore.connections[*testFlowKeys[i]] = flow
@@ -108,7 +114,7 @@ func TestConnectionStore_DeleteConnWithoutLock(t *testing.T) {
 	metrics.InitializeConnectionMetrics()
 	// test on deny connection store
 	mockIfaceStore := interfacestoretest.NewMockInterfaceStore(ctrl)
-	denyConnStore := NewDenyConnectionStore(mockIfaceStore, nil, testFlowExporterOptions)
+	denyConnStore := NewDenyConnectionStore("", mockIfaceStore, nil, nil, testFlowExporterOptions)
 	tuple := flowexporter.Tuple{SourceAddress: net.IP{1, 2, 3, 4}, DestinationAddress: net.IP{4, 3, 2, 1}, Protocol: 6, SourcePort: 65280, DestinationPort: 255}
 	conn := &flowexporter.Connection{
 		FlowKey: tuple,
@@ -125,7 +131,7 @@ func TestConnectionStore_DeleteConnWithoutLock(t *testing.T) {
 
 	// test on conntrack connection store
 	mockConnDumper := connectionstest.NewMockConnTrackDumper(ctrl)
-	conntrackConnStore := NewConntrackConnectionStore(mockConnDumper, true, false, nil, mockIfaceStore, nil, testFlowExporterOptions)
+	conntrackConnStore := NewConntrackConnectionStore("", mockConnDumper, true, false, nil, nil, mockIfaceStore, nil, nil, testFlowExporterOptions)
 	conntrackConnStore.connections[connKey] = conn
 
 	metrics.TotalAntreaConnectionsInConnTrackTable.Set(1)
@@ -134,3 +140,59 @@ func TestConnectionStore_DeleteConnWithoutLock(t *testing.T) {
 	assert.Equal(t, false, exists, "connection should be deleted in connection store")
 	checkAntreaConnectionMetrics(t, len(conntrackConnStore.connections))
 }
+
+func TestConnectionStore_FillEgressInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	egressQuerier := queriertest.NewMockEgressQuerier(ctrl)
+	connStore := NewConnectionStore("node1", nil, nil, nil, egressQuerier, testFlowExporterOptions)
+
+	tc := []struct {
+		name               string
+		sourcePodName      string
+		egressName         string
+		egressIP           string
+		egressErr          error
+		expectedEgressName string
+		expectedEgressIP   string
+	}{
+		{
+			name:               "local Pod selected by an Egress",
+			sourcePodName:      "pod1",
+			egressName:         "egress1",
+			egressIP:           "172.18.0.100",
+			expectedEgressName: "egress1",
+			expectedEgressIP:   "172.18.0.100",
+		},
+		{
+			name:          "local Pod not selected by any Egress",
+			sourcePodName: "pod1",
+			egressErr:     fmt.Errorf("no Egress applied to Pod ns1/pod1"),
+		},
+		{
+			name: "source is not a local Pod",
+		},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			conn := &flowexporter.Connection{
+				SourcePodName:      c.sourcePodName,
+				SourcePodNamespace: "ns1",
+			}
+			if c.sourcePodName != "" {
+				egressQuerier.EXPECT().GetEgress("ns1", c.sourcePodName).Return(c.egressName, c.egressIP, "node2", c.egressErr)
+			}
+			connStore.fillEgressInfo(conn)
+			assert.Equal(t, c.expectedEgressName, conn.EgressName)
+			assert.Equal(t, c.expectedEgressIP, conn.EgressIP)
+		})
+	}
+}
+
+func newServiceLister(t *testing.T, services ...*v1.Service) corelisters.ServiceLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, service := range services {
+		require.NoError(t, indexer.Add(service))
+	}
+	return corelisters.NewServiceLister(indexer)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/vmware/go-ipfix/pkg/registry"
 	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/flowexporter"
@@ -29,6 +30,7 @@ import (
 	"antrea.io/antrea/pkg/agent/metrics"
 	"antrea.io/antrea/pkg/agent/openflow"
 	"antrea.io/antrea/pkg/agent/proxy"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
 	"antrea.io/antrea/pkg/querier"
 )
 
@@ -48,12 +50,15 @@ type ConntrackConnectionStore struct {
 }
 
 func NewConntrackConnectionStore(
+	nodeName string,
 	connTrackDumper ConnTrackDumper,
 	v4Enabled bool,
 	v6Enabled bool,
 	npQuerier querier.AgentNetworkPolicyInfoQuerier,
+	egressQuerier querier.EgressQuerier,
 	ifaceStore interfacestore.InterfaceStore,
 	proxier proxy.Proxier,
+	serviceLister corelisters.ServiceLister,
 	o *flowexporter.FlowExporterOptions,
 ) *ConntrackConnectionStore {
 	return &ConntrackConnectionStore{
@@ -62,7 +67,7 @@ func NewConntrackConnectionStore(
 		v6Enabled:            v6Enabled,
 		networkPolicyQuerier: npQuerier,
 		pollInterval:         o.PollInterval,
-		connectionStore:      NewConnectionStore(ifaceStore, proxier, o),
+		connectionStore:      NewConnectionStore(nodeName, ifaceStore, proxier, serviceLister, egressQuerier, o),
 	}
 }
 
@@ -172,6 +177,11 @@ func (cs *ConntrackConnectionStore) addNetworkPolicyMetadata(conn *flowexporter.
 				conn.IngressNetworkPolicyRuleAction = registry.NetworkPolicyRuleActionAllow
 			}
 		}
+		// The connection would have been denied by a rule in audit mode, before being allowed by the rules
+		// evaluated after it, if any.
+		if hasCTLabel(conn.Labels, openflow.IngressAuditedCTLabel) {
+			conn.IngressNetworkPolicyRuleAction = flowexporter.NetworkPolicyRuleActionAudit
+		}
 		if egressOfID != 0 {
 			policy := cs.networkPolicyQuerier.GetNetworkPolicyByRuleFlowID(egressOfID)
 			rule := cs.networkPolicyQuerier.GetRuleByFlowID(egressOfID)
@@ -187,7 +197,19 @@ func (cs *ConntrackConnectionStore) addNetworkPolicyMetadata(conn *flowexporter.
 				conn.EgressNetworkPolicyRuleAction = registry.NetworkPolicyRuleActionAllow
 			}
 		}
+		if hasCTLabel(conn.Labels, openflow.EgressAuditedCTLabel) {
+			conn.EgressNetworkPolicyRuleAction = flowexporter.NetworkPolicyRuleActionAudit
+		}
+	}
+}
+
+// hasCTLabel returns whether the one-bit label is set in the little endian labels of a connection.
+func hasCTLabel(labels []byte, label *binding.CtLabel) bool {
+	bit := label.GetRange()[0]
+	if int(bit/8) >= len(labels) {
+		return false
 	}
+	return labels[bit/8]&(1<<(bit%8)) != 0
 }
 
 // AddOrUpdateConn updates the connection if it is already present, i.e., update timestamp, counters etc.,
@@ -227,6 +249,7 @@ func (cs *ConntrackConnectionStore) AddOrUpdateConn(conn *flowexporter.Connectio
 		klog.V(4).InfoS("Antrea flow updated", "connection", existingConn)
 	} else {
 		cs.fillPodInfo(conn)
+		cs.fillEgressInfo(conn)
 		if conn.Mark&openflow.ServiceCTMark.GetRange().ToNXRange().ToUint32Mask() == openflow.ServiceCTMark.GetValue() {
 			clusterIP := conn.DestinationServiceAddress.String()
 			svcPort := conn.DestinationServicePort
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/component-base/metrics/legacyregistry"
 
@@ -122,7 +123,9 @@ func TestConntrackConnectionStore_AddOrUpdateConn(t *testing.T) {
 				IsActive:                       true,
 				DestinationPodName:             "pod1",
 				DestinationPodNamespace:        "ns1",
+				DestinationNodeName:            "node1",
 				DestinationServicePortName:     servicePortName.String(),
+				DestinationServiceType:         string(v1.ServiceTypeClusterIP),
 				IngressNetworkPolicyName:       np1.Name,
 				IngressNetworkPolicyNamespace:  np1.Namespace,
 				IngressNetworkPolicyType:       flowexporter.PolicyTypeToUint8(np1.Type),
@@ -210,7 +213,13 @@ func TestConntrackConnectionStore_AddOrUpdateConn(t *testing.T) {
 	mockProxier := proxytest.NewMockProxier(ctrl)
 	mockConnDumper := connectionstest.NewMockConnTrackDumper(ctrl)
 	npQuerier := queriertest.NewMockAgentNetworkPolicyInfoQuerier(ctrl)
-	conntrackConnStore := NewConntrackConnectionStore(mockConnDumper, true, false, npQuerier, mockIfaceStore, mockProxier, testFlowExporterOptions)
+	// The source of the new connection is not a local Pod, so its Egress is never queried.
+	egressQuerier := queriertest.NewMockEgressQuerier(ctrl)
+	serviceLister := newServiceLister(t, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: servicePortName.Namespace, Name: servicePortName.Name},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP},
+	})
+	conntrackConnStore := NewConntrackConnectionStore("node1", mockConnDumper, true, false, npQuerier, egressQuerier, mockIfaceStore, mockProxier, serviceLister, testFlowExporterOptions)
 
 	for _, c := range tc {
 		t.Run(c.name, func(t *testing.T) {
@@ -253,6 +262,27 @@ func addConnToStore(cs *ConntrackConnectionStore, conn *flowexporter.Connection)
 	metrics.TotalAntreaConnectionsInConnTrackTable.Inc()
 }
 
+func TestConntrackConnectionStore_AuditedConn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	npQuerier := queriertest.NewMockAgentNetworkPolicyInfoQuerier(ctrl)
+	conntrackConnStore := NewConntrackConnectionStore("node1", nil, true, false, npQuerier, nil, nil, nil, nil, testFlowExporterOptions)
+
+	// The connection is allowed by the ingress rule 1 after matching an ingress rule in audit mode, and matches an
+	// egress rule in audit mode without being allowed by any egress rule.
+	conn := &flowexporter.Connection{
+		FlowKey: tuple1,
+		Labels:  []byte{0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+	}
+	npQuerier.EXPECT().GetNetworkPolicyByRuleFlowID(uint32(1)).Return(&np1)
+	npQuerier.EXPECT().GetRuleByFlowID(uint32(1)).Return(&rule1)
+	conntrackConnStore.addNetworkPolicyMetadata(conn)
+	assert.Equal(t, np1.Name, conn.IngressNetworkPolicyName)
+	assert.Equal(t, flowexporter.NetworkPolicyRuleActionAudit, conn.IngressNetworkPolicyRuleAction)
+	assert.Equal(t, "", conn.EgressNetworkPolicyName)
+	assert.Equal(t, flowexporter.NetworkPolicyRuleActionAudit, conn.EgressNetworkPolicyRuleAction)
+}
+
 func TestConnectionStore_DeleteConnectionByKey(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -293,7 +323,7 @@ func TestConnectionStore_DeleteConnectionByKey(t *testing.T) {
 	metrics.TotalAntreaConnectionsInConnTrackTable.Set(float64(len(testFlows)))
 	// Create connectionStore
 	mockIfaceStore := interfacestoretest.NewMockInterfaceStore(ctrl)
-	connStore := NewConntrackConnectionStore(nil, true, false, nil, mockIfaceStore, nil, testFlowExporterOptions)
+	connStore := NewConntrackConnectionStore("", nil, true, false, nil, nil, mockIfaceStore, nil, nil, testFlowExporterOptions)
 	// Add flows to the connection store.
 	for i, flow := range testFlows {
 		connStore.connections[*testFlowKeys[i]] = flow
@@ -317,7 +347,7 @@ func TestConnectionStore_MetricSettingInPoll(t *testing.T) {
 	// Create connectionStore
 	mockIfaceStore := interfacestoretest.NewMockInterfaceStore(ctrl)
 	mockConnDumper := connectionstest.NewMockConnTrackDumper(ctrl)
-	conntrackConnStore := NewConntrackConnectionStore(mockConnDumper, true, false, nil, mockIfaceStore, nil, testFlowExporterOptions)
+	conntrackConnStore := NewConntrackConnectionStore("", mockConnDumper, true, false, nil, nil, mockIfaceStore, nil, nil, testFlowExporterOptions)
 	// Hard-coded conntrack occupancy metrics for test
 	TotalConnections := 0
 	MaxConnections := 300000
@@ -244,9 +244,14 @@ func flowStringToAntreaConnection(flow string, zoneFilter uint16) (*flowexporter
 		case strings.Contains(fs, "labels"):
 			fields := strings.Split(fs, "=")
 			labelStr := strings.Replace(fields[len(fields)-1], "0x", "", -1)
-			// Add leading zeros since DecodeString() expects the input string has even length
-			if len(labelStr) < 16 {
-				labelStr = strings.Repeat("0", 16-len(labelStr)) + labelStr
+			// Add leading zeros since DecodeString() expects the input string has even length. The labels
+			// use 64 bits, or 128 bits when the audited marks are set.
+			labelLen := 16
+			if len(labelStr) > labelLen {
+				labelLen = 32
+			}
+			if len(labelStr) < labelLen {
+				labelStr = strings.Repeat("0", labelLen-len(labelStr)) + labelStr
 			}
 			hexval, err := hex.DecodeString(labelStr)
 			if err != nil {
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"time"
 
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/flowexporter"
@@ -32,9 +33,10 @@ type DenyConnectionStore struct {
 	connectionStore
 }
 
-func NewDenyConnectionStore(ifaceStore interfacestore.InterfaceStore, proxier proxy.Proxier, o *flowexporter.FlowExporterOptions) *DenyConnectionStore {
+func NewDenyConnectionStore(nodeName string, ifaceStore interfacestore.InterfaceStore, proxier proxy.Proxier, serviceLister corelisters.ServiceLister, o *flowexporter.FlowExporterOptions) *DenyConnectionStore {
 	return &DenyConnectionStore{
-		connectionStore: NewConnectionStore(ifaceStore, proxier, o),
+		// Denied connections are never SNATed, there is no need to resolve their Egress.
+		connectionStore: NewConnectionStore(nodeName, ifaceStore, proxier, serviceLister, nil, o),
 	}
 }
 
@@ -0,0 +1,243 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"antrea.io/libOpenflow/protocol"
+	"antrea.io/ofnet/ofctrl"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
+)
+
+const (
+	tcpFlagSYN = 0b10
+	tcpFlagRST = 0b100
+	tcpFlagACK = 0b10000
+)
+
+// tcpClient identifies the client of a TCP connection by its address and port. It is
+// used to find the connection of the packets sent by the server when their address is
+// not the one of the connection, e.g. the SYN-ACK packets of the DNATed connections,
+// which have the Service address while the SYN packets have the Endpoint address.
+type tcpClient struct {
+	address string
+	port    uint16
+}
+
+type tcpMetricsEntry struct {
+	flowexporter.TCPMetrics
+	client     tcpClient
+	synTime    time.Time
+	synAckSeen bool
+	// lastUpdate is the last time the entry was updated by a packet or read by the Flow
+	// Exporter. The entry is deleted when it has not been updated for staleTimeout.
+	lastUpdate time.Time
+}
+
+// TCPMetricsStore computes the TCP metrics of the connections from the SYN, SYN-ACK and
+// RST packets sent to the Agent by OVS. It is registered as the packet-in handler of
+// openflow.PacketInReasonFE. As OVS rate-limits the packets sent to the Agent, the
+// handshake of some connections may not be observed, in which case their metrics are
+// not available.
+type TCPMetricsStore struct {
+	mutex   sync.Mutex
+	entries map[flowexporter.ConnectionKey]*tcpMetricsEntry
+	// clientEntries indexes the keys of the entries by client.
+	clientEntries map[tcpClient]map[flowexporter.ConnectionKey]struct{}
+	staleTimeout  time.Duration
+}
+
+func NewTCPMetricsStore(staleTimeout time.Duration) *TCPMetricsStore {
+	return &TCPMetricsStore{
+		entries:       make(map[flowexporter.ConnectionKey]*tcpMetricsEntry),
+		clientEntries: make(map[tcpClient]map[flowexporter.ConnectionKey]struct{}),
+		staleTimeout:  staleTimeout,
+	}
+}
+
+// HandlePacketIn updates the TCP metrics of the connection of the packet.
+func (s *TCPMetricsStore) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
+	if pktIn == nil {
+		return errors.New("empty packetin for TCP metrics")
+	}
+	packet, err := binding.ParsePacketIn(pktIn)
+	if err != nil {
+		return fmt.Errorf("error in parsing packetin: %v", err)
+	}
+	if packet.IPProto != protocol.Type_TCP {
+		return nil
+	}
+	s.processPacket(packet.SourceIP, packet.SourcePort, packet.DestinationIP, packet.DestinationPort, packet.TCPFlags, time.Now())
+	return nil
+}
+
+func (s *TCPMetricsStore) processPacket(srcIP net.IP, srcPort uint16, dstIP net.IP, dstPort uint16, flags uint8, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := tcpConnectionKey(srcIP, srcPort, dstIP, dstPort)
+	reverseKey := tcpConnectionKey(dstIP, dstPort, srcIP, srcPort)
+	switch {
+	case flags&tcpFlagSYN != 0 && flags&tcpFlagACK == 0:
+		entry, exist := s.entries[key]
+		if !exist || entry.synAckSeen {
+			// A SYN packet after the handshake starts a new connection reusing the
+			// same 5-tuple.
+			s.addEntry(key, &tcpMetricsEntry{
+				client:     tcpClient{address: srcIP.String(), port: srcPort},
+				synTime:    now,
+				lastUpdate: now,
+			})
+			return
+		}
+		entry.HandshakeRetransmissions = incrementCount(entry.HandshakeRetransmissions)
+		entry.lastUpdate = now
+	case flags&tcpFlagSYN != 0:
+		// The client is the destination of the SYN-ACK packets.
+		entry := s.getServerEntry(reverseKey, tcpClient{address: dstIP.String(), port: dstPort})
+		if entry == nil {
+			return
+		}
+		if entry.synAckSeen {
+			entry.HandshakeRetransmissions = incrementCount(entry.HandshakeRetransmissions)
+		} else {
+			entry.HandshakeRTT = now.Sub(entry.synTime)
+			entry.synAckSeen = true
+		}
+		entry.lastUpdate = now
+	case flags&tcpFlagRST != 0:
+		// Both endpoints can reset the connection.
+		entry, exist := s.entries[key]
+		if !exist {
+			entry = s.getServerEntry(reverseKey, tcpClient{address: dstIP.String(), port: dstPort})
+		}
+		if entry == nil {
+			return
+		}
+		entry.Resets = incrementCount(entry.Resets)
+		entry.lastUpdate = now
+	}
+}
+
+// getServerEntry returns the entry of a packet sent by the server, given the key of the
+// connection in the client to server direction. If the key is not found, e.g. because
+// the packet has been un-DNATed, the entry is only returned when it is the only one of
+// the client, as the connections cannot be told apart otherwise.
+func (s *TCPMetricsStore) getServerEntry(key flowexporter.ConnectionKey, client tcpClient) *tcpMetricsEntry {
+	if entry, exist := s.entries[key]; exist {
+		return entry
+	}
+	var found *tcpMetricsEntry
+	for k := range s.clientEntries[client] {
+		if found != nil {
+			klog.V(4).InfoS("Cannot identify the TCP connection of the packet", "client", client.address, "port", client.port)
+			return nil
+		}
+		found = s.entries[k]
+	}
+	return found
+}
+
+func (s *TCPMetricsStore) addEntry(key flowexporter.ConnectionKey, entry *tcpMetricsEntry) {
+	s.entries[key] = entry
+	keys, exist := s.clientEntries[entry.client]
+	if !exist {
+		keys = make(map[flowexporter.ConnectionKey]struct{})
+		s.clientEntries[entry.client] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+func (s *TCPMetricsStore) deleteEntry(key flowexporter.ConnectionKey) {
+	entry, exist := s.entries[key]
+	if !exist {
+		return
+	}
+	delete(s.entries, key)
+	keys := s.clientEntries[entry.client]
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(s.clientEntries, entry.client)
+	}
+}
+
+func tcpConnectionKey(srcIP net.IP, srcPort uint16, dstIP net.IP, dstPort uint16) flowexporter.ConnectionKey {
+	return flowexporter.NewConnectionKey(&flowexporter.Connection{
+		FlowKey: flowexporter.Tuple{
+			SourceAddress:      srcIP,
+			DestinationAddress: dstIP,
+			Protocol:           uint8(protocol.Type_TCP),
+			SourcePort:         srcPort,
+			DestinationPort:    dstPort,
+		},
+	})
+}
+
+func incrementCount(count uint16) uint16 {
+	if count == ^uint16(0) {
+		return count
+	}
+	return count + 1
+}
+
+// GetTCPMetrics returns the TCP metrics of the connection, and whether they are available.
+func (s *TCPMetricsStore) GetTCPMetrics(conn *flowexporter.Connection) (flowexporter.TCPMetrics, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, exist := s.entries[flowexporter.NewConnectionKey(conn)]
+	if !exist {
+		return flowexporter.TCPMetrics{}, false
+	}
+	// The entry is kept as long as the connection is exported.
+	entry.lastUpdate = time.Now()
+	return entry.TCPMetrics, true
+}
+
+// RunPeriodicDeletion deletes the metrics of the connections which have not been
+// updated or exported for staleTimeout.
+func (s *TCPMetricsStore) RunPeriodicDeletion(stopCh <-chan struct{}) {
+	pollTicker := time.NewTicker(periodicDeleteInterval)
+	defer pollTicker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-pollTicker.C:
+			s.deleteStaleEntries(time.Now())
+		}
+	}
+}
+
+func (s *TCPMetricsStore) deleteStaleEntries(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, entry := range s.entries {
+		if now.Sub(entry.lastUpdate) >= s.staleTimeout {
+			s.deleteEntry(key)
+		}
+	}
+	klog.V(2).InfoS("Stale entries in the TCP metrics store are deleted", "remaining", len(s.entries))
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connections
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+)
+
+func TestTCPMetricsStore(t *testing.T) {
+	clientIP, endpointIP, serviceIP := net.ParseIP("10.10.0.1"), net.ParseIP("10.10.1.1"), net.ParseIP("10.96.0.10")
+	conn := &flowexporter.Connection{
+		FlowKey: flowexporter.Tuple{
+			SourceAddress:      clientIP,
+			DestinationAddress: endpointIP,
+			Protocol:           6,
+			SourcePort:         34567,
+			DestinationPort:    8080,
+		},
+	}
+	synAck := uint8(tcpFlagSYN | tcpFlagACK)
+	rstAck := uint8(tcpFlagRST | tcpFlagACK)
+	start := time.Now()
+
+	tests := []struct {
+		name     string
+		packets  func(s *TCPMetricsStore)
+		expected *flowexporter.TCPMetrics
+	}{
+		{
+			name: "handshake of a DNATed connection",
+			packets: func(s *TCPMetricsStore) {
+				s.processPacket(clientIP, 34567, endpointIP, 8080, tcpFlagSYN, start)
+				// The SYN-ACK packet has been un-DNATed.
+				s.processPacket(serviceIP, 80, clientIP, 34567, synAck, start.Add(2*time.Millisecond))
+			},
+			expected: &flowexporter.TCPMetrics{HandshakeRTT: 2 * time.Millisecond},
+		},
+		{
+			name: "retransmissions and reset",
+			packets: func(s *TCPMetricsStore) {
+				s.processPacket(clientIP, 34567, endpointIP, 8080, tcpFlagSYN, start)
+				s.processPacket(clientIP, 34567, endpointIP, 8080, tcpFlagSYN, start.Add(time.Second))
+				s.processPacket(endpointIP, 8080, clientIP, 34567, synAck, start.Add(1200*time.Millisecond))
+				s.processPacket(endpointIP, 8080, clientIP, 34567, synAck, start.Add(2200*time.Millisecond))
+				s.processPacket(endpointIP, 8080, clientIP, 34567, rstAck, start.Add(3*time.Second))
+			},
+			expected: &flowexporter.TCPMetrics{HandshakeRTT: 1200 * time.Millisecond, HandshakeRetransmissions: 2, Resets: 1},
+		},
+		{
+			name: "new connection reusing the client port",
+			packets: func(s *TCPMetricsStore) {
+				s.processPacket(clientIP, 34567, endpointIP, 8080, tcpFlagSYN, start)
+				s.processPacket(endpointIP, 8080, clientIP, 34567, synAck, start.Add(time.Millisecond))
+				s.processPacket(clientIP, 34567, endpointIP, 8080, tcpFlagRST, start.Add(time.Second))
+				s.processPacket(clientIP, 34567, endpointIP, 8080, tcpFlagSYN, start.Add(2*time.Second))
+			},
+			expected: &flowexporter.TCPMetrics{},
+		},
+		{
+			name: "handshake not observed",
+			packets: func(s *TCPMetricsStore) {
+				s.processPacket(endpointIP, 8080, clientIP, 34567, synAck, start)
+				s.processPacket(endpointIP, 8080, clientIP, 34567, rstAck, start)
+			},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTCPMetricsStore(time.Minute)
+			tt.packets(s)
+			metrics, exist := s.GetTCPMetrics(conn)
+			if tt.expected == nil {
+				assert.False(t, exist)
+				return
+			}
+			assert.True(t, exist)
+			assert.Equal(t, *tt.expected, metrics)
+		})
+	}
+}
+
+func TestTCPMetricsStoreDeleteStaleEntries(t *testing.T) {
+	s := NewTCPMetricsStore(time.Minute)
+	now := time.Now()
+	s.processPacket(net.ParseIP("10.10.0.1"), 34567, net.ParseIP("10.10.1.1"), 80, tcpFlagSYN, now.Add(-2*time.Minute))
+	s.processPacket(net.ParseIP("10.10.0.2"), 34567, net.ParseIP("10.10.1.1"), 80, tcpFlagSYN, now)
+	s.deleteStaleEntries(now)
+	assert.Len(t, s.entries, 1)
+	assert.Contains(t, s.entries, tcpConnectionKey(net.ParseIP("10.10.0.2"), 34567, net.ParseIP("10.10.1.1"), 80))
+	assert.Len(t, s.clientEntries, 1)
+}
+
+func TestTCPMetricsStoreSameClientPort(t *testing.T) {
+	clientIP, serverIP1, serverIP2 := net.ParseIP("10.10.0.1"), net.ParseIP("10.10.1.1"), net.ParseIP("10.10.1.2")
+	newConn := func(serverIP net.IP, serverPort uint16) *flowexporter.Connection {
+		return &flowexporter.Connection{
+			FlowKey: flowexporter.Tuple{
+				SourceAddress:      clientIP,
+				DestinationAddress: serverIP,
+				Protocol:           6,
+				SourcePort:         34567,
+				DestinationPort:    serverPort,
+			},
+		}
+	}
+	synAck := uint8(tcpFlagSYN | tcpFlagACK)
+	start := time.Now()
+
+	s := NewTCPMetricsStore(time.Minute)
+	// The client uses the same source port for two connections to different servers.
+	s.processPacket(clientIP, 34567, serverIP1, 80, tcpFlagSYN, start)
+	s.processPacket(clientIP, 34567, serverIP2, 8080, tcpFlagSYN, start.Add(time.Millisecond))
+	s.processPacket(serverIP2, 8080, clientIP, 34567, synAck, start.Add(3*time.Millisecond))
+	s.processPacket(serverIP1, 80, clientIP, 34567, synAck, start.Add(5*time.Millisecond))
+	s.processPacket(serverIP2, 8080, clientIP, 34567, tcpFlagRST, start.Add(time.Second))
+	// The SYN-ACK of an un-DNATed connection cannot be attributed to either connection.
+	s.processPacket(net.ParseIP("10.96.0.10"), 80, clientIP, 34567, synAck, start.Add(2*time.Second))
+
+	metrics, exist := s.GetTCPMetrics(newConn(serverIP1, 80))
+	assert.True(t, exist)
+	assert.Equal(t, flowexporter.TCPMetrics{HandshakeRTT: 5 * time.Millisecond}, metrics)
+	metrics, exist = s.GetTCPMetrics(newConn(serverIP2, 8080))
+	assert.True(t, exist)
+	assert.Equal(t, flowexporter.TCPMetrics{HandshakeRTT: 2 * time.Millisecond, Resets: 1}, metrics)
+	_, exist = s.GetTCPMetrics(newConn(serverIP2, 80))
+	assert.False(t, exist)
+}
@@ -17,24 +17,32 @@ package exporter
 import (
 	"fmt"
 	"hash/fnv"
+	"math"
 	"net"
 	"time"
 
+	"antrea.io/libOpenflow/protocol"
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	"github.com/vmware/go-ipfix/pkg/exporter"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/controller/noderoute"
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/connections"
+	"antrea.io/antrea/pkg/agent/flowexporter/filter"
 	"antrea.io/antrea/pkg/agent/flowexporter/priorityqueue"
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/metrics"
 	"antrea.io/antrea/pkg/agent/openflow"
 	"antrea.io/antrea/pkg/agent/proxy"
+	"antrea.io/antrea/pkg/flowaggregator/sharding"
 	"antrea.io/antrea/pkg/ipfix"
 	"antrea.io/antrea/pkg/ovs/ovsconfig"
 	"antrea.io/antrea/pkg/querier"
@@ -99,6 +107,24 @@ var (
 	}
 	AntreaInfoElementsIPv4 = append(antreaInfoElementsCommon, []string{"destinationClusterIPv4"}...)
 	AntreaInfoElementsIPv6 = append(antreaInfoElementsCommon, []string{"destinationClusterIPv6"}...)
+	// AntreaOptionalInfoElements are only added to the templates if they are present in
+	// the Antrea registry of go-ipfix, so that the templates never include an element
+	// which the Flow Aggregator cannot decode.
+	AntreaOptionalInfoElements = []string{
+		"destinationServiceType",
+		"egressName",
+		"egressIP",
+		"tcpHandshakeRTT",
+		"tcpHandshakeRetransmissionCount",
+		"tcpResetCount",
+	}
+	// tcpMetricsInfoElementTypes are the optional elements which are only exported when
+	// the TCP metrics are enabled, with the data types of their values.
+	tcpMetricsInfoElementTypes = map[string]ipfixentities.IEDataType{
+		"tcpHandshakeRTT":                 ipfixentities.Signed32,
+		"tcpHandshakeRetransmissionCount": ipfixentities.Unsigned16,
+		"tcpResetCount":                   ipfixentities.Unsigned16,
+	}
 )
 
 type FlowExporter struct {
@@ -122,6 +148,16 @@ type FlowExporter struct {
 	conntrackPriorityQueue *priorityqueue.ExpirePriorityQueue
 	denyPriorityQueue      *priorityqueue.ExpirePriorityQueue
 	expiredConns           []flowexporter.Connection
+	// sharder and shardingInformers are only set when the Flow Aggregator is
+	// sharded, in which case the records are sent to the replicas owning the flows.
+	sharder           *sharding.Sharder
+	shardingInformers informers.SharedInformerFactory
+	// policyController provides the policy deciding which connections are exported. The
+	// labels of the local Pods are needed for its podSelectors.
+	policyController *filter.Controller
+	podLister        corelisters.PodLister
+	// tcpMetricsStore is only set when the TCP metrics are enabled.
+	tcpMetricsStore *connections.TCPMetricsStore
 }
 
 func genObservationID(nodeName string) uint32 {
@@ -149,7 +185,8 @@ func prepareExporterInputArgs(collectorAddr, collectorProto, nodeName string) ex
 
 func NewFlowExporter(ifaceStore interfacestore.InterfaceStore, proxier proxy.Proxier, k8sClient kubernetes.Interface, nodeRouteController *noderoute.Controller,
 	trafficEncapMode config.TrafficEncapModeType, nodeConfig *config.NodeConfig, v4Enabled, v6Enabled bool, serviceCIDRNet, serviceCIDRNetv6 *net.IPNet,
-	ovsDatapathType ovsconfig.OVSDatapathType, proxyEnabled bool, npQuerier querier.AgentNetworkPolicyInfoQuerier, o *flowexporter.FlowExporterOptions) (*FlowExporter, error) {
+	ovsDatapathType ovsconfig.OVSDatapathType, proxyEnabled bool, npQuerier querier.AgentNetworkPolicyInfoQuerier, egressQuerier querier.EgressQuerier,
+	serviceLister corelisters.ServiceLister, podLister corelisters.PodLister, o *flowexporter.FlowExporterOptions) (*FlowExporter, error) {
 	// Initialize IPFIX registry
 	registry := ipfix.NewIPFIXRegistry()
 	registry.LoadRegistry()
@@ -162,8 +199,31 @@ func NewFlowExporter(ifaceStore interfacestore.InterfaceStore, proxier proxy.Pro
 	expInput := prepareExporterInputArgs(o.FlowCollectorAddr, o.FlowCollectorProto, nodeName)
 
 	connTrackDumper := connections.InitializeConnTrackDumper(nodeConfig, serviceCIDRNet, serviceCIDRNetv6, ovsDatapathType, proxyEnabled)
-	denyConnStore := connections.NewDenyConnectionStore(ifaceStore, proxier, o)
-	conntrackConnStore := connections.NewConntrackConnectionStore(connTrackDumper, v4Enabled, v6Enabled, npQuerier, ifaceStore, proxier, o)
+	denyConnStore := connections.NewDenyConnectionStore(nodeName, ifaceStore, proxier, serviceLister, o)
+	conntrackConnStore := connections.NewConntrackConnectionStore(nodeName, connTrackDumper, v4Enabled, v6Enabled, npQuerier, egressQuerier, ifaceStore, proxier, serviceLister, o)
+
+	var sharder *sharding.Sharder
+	var shardingInformerFactory informers.SharedInformerFactory
+	if o.FlowCollectorShardingService != nil {
+		// Only the Endpoints of the Flow Aggregator Service are watched.
+		service := *o.FlowCollectorShardingService
+		shardingInformerFactory = informers.NewSharedInformerFactoryWithOptions(k8sClient, 0,
+			informers.WithNamespace(service.Namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", service.Name).String()
+			}))
+		sharder = sharding.NewSharder(service.Namespace, service.Name, shardingInformerFactory.Core().V1().Endpoints().Lister())
+	}
+
+	policyController, err := filter.NewController(o.FlowExportPolicyFile, o.FlowExportPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flowExportPolicy: %v", err)
+	}
+
+	var tcpMetricsStore *connections.TCPMetricsStore
+	if o.EnableTCPMetrics {
+		tcpMetricsStore = connections.NewTCPMetricsStore(o.StaleConnectionTimeout)
+	}
 
 	return &FlowExporter{
 		conntrackConnStore:     conntrackConnStore,
@@ -180,6 +240,11 @@ func NewFlowExporter(ifaceStore interfacestore.InterfaceStore, proxier proxy.Pro
 		conntrackPriorityQueue: conntrackConnStore.GetPriorityQueue(),
 		denyPriorityQueue:      denyConnStore.GetPriorityQueue(),
 		expiredConns:           make([]flowexporter.Connection, 0, maxConnsToExport*2),
+		sharder:                sharder,
+		shardingInformers:      shardingInformerFactory,
+		policyController:       policyController,
+		podLister:              podLister,
+		tcpMetricsStore:        tcpMetricsStore,
 	}, nil
 }
 
@@ -187,6 +252,11 @@ func (exp *FlowExporter) GetDenyConnStore() *connections.DenyConnectionStore {
 	return exp.denyConnStore
 }
 
+// GetTCPMetricsStore returns the store computing the TCP metrics, nil if they are disabled.
+func (exp *FlowExporter) GetTCPMetricsStore() *connections.TCPMetricsStore {
+	return exp.tcpMetricsStore
+}
+
 func (exp *FlowExporter) Run(stopCh <-chan struct{}) {
 	// Start the goroutine to periodically delete stale deny connections.
 	go exp.denyConnStore.RunPeriodicDeletion(stopCh)
@@ -194,6 +264,15 @@ func (exp *FlowExporter) Run(stopCh <-chan struct{}) {
 	// Start the goroutine to poll conntrack flows.
 	go exp.conntrackConnStore.Run(stopCh)
 
+	if exp.shardingInformers != nil {
+		exp.shardingInformers.Start(stopCh)
+	}
+
+	go exp.policyController.Run(stopCh)
+	if exp.tcpMetricsStore != nil {
+		go exp.tcpMetricsStore.RunPeriodicDeletion(stopCh)
+	}
+
 	defaultTimeout := exp.conntrackPriorityQueue.ActiveFlowTimeout
 	expireTimer := time.NewTimer(defaultTimeout)
 	for {
@@ -243,10 +322,23 @@ func (exp *FlowExporter) sendFlowRecords() (time.Duration, error) {
 	currTime := time.Now()
 	var expireTime1, expireTime2 time.Duration
 	exp.expiredConns, expireTime1 = exp.conntrackConnStore.GetExpiredConns(exp.expiredConns, currTime, maxConnsToExport)
+	// The connections after this index come from the deny connection store.
+	numConntrackConns := len(exp.expiredConns)
 	exp.expiredConns, expireTime2 = exp.denyConnStore.GetExpiredConns(exp.expiredConns, currTime, maxConnsToExport)
 	// Select the shorter time out among two connection stores to do the next round of export.
 	nextExpireTime := getMinTime(expireTime1, expireTime2)
+	var policy *filter.Policy
+	if exp.policyController != nil {
+		policy = exp.policyController.GetPolicy()
+	}
 	for i := range exp.expiredConns {
+		// The connection stores are updated whether the connection is exported or not.
+		if !policy.Match(&exp.expiredConns[i], i >= numConntrackConns, exp.podLister) {
+			continue
+		}
+		if exp.tcpMetricsStore != nil && exp.expiredConns[i].FlowKey.Protocol == protocol.Type_TCP {
+			exp.expiredConns[i].TCPMetrics, _ = exp.tcpMetricsStore.GetTCPMetrics(&exp.expiredConns[i])
+		}
 		if err := exp.exportConn(&exp.expiredConns[i]); err != nil {
 			klog.ErrorS(err, "Error when sending expired flow record")
 			return nextExpireTime, err
@@ -281,11 +373,16 @@ func (exp *FlowExporter) initFlowExporter() error {
 		// For UDP transport, hardcoding tempRefTimeout value as 1800s.
 		exp.exporterInput.TempRefTimeout = 1800
 	}
-	expProcess, err := ipfix.NewIPFIXExportingProcess(exp.exporterInput)
-	if err != nil {
-		return fmt.Errorf("error when starting exporter: %v", err)
+	if exp.sharder != nil {
+		// The connections to the replicas are established when sending the data records.
+		exp.process = newShardedExportingProcess(exp.sharder, exp.exporterInput)
+	} else {
+		expProcess, err := ipfix.NewIPFIXExportingProcess(exp.exporterInput)
+		if err != nil {
+			return fmt.Errorf("error when starting exporter: %v", err)
+		}
+		exp.process = expProcess
 	}
-	exp.process = expProcess
 	if exp.v4Enabled {
 		templateID := exp.process.NewTemplateID()
 		exp.templateIDv4 = templateID
@@ -352,6 +449,26 @@ func (exp *FlowExporter) sendTemplateSet(isIPv6 bool) (int, error) {
 		}
 		elements = append(elements, ieWithValue)
 	}
+	for _, ie := range AntreaOptionalInfoElements {
+		dataType, isTCPMetric := tcpMetricsInfoElementTypes[ie]
+		if isTCPMetric && exp.tcpMetricsStore == nil {
+			continue
+		}
+		element, err := exp.registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID)
+		if err != nil {
+			klog.InfoS("Information element is not present in Antrea registry, it will not be exported", "element", ie)
+			continue
+		}
+		if isTCPMetric && element.DataType != dataType {
+			klog.InfoS("Information element in Antrea registry has an unexpected data type, it will not be exported", "element", ie, "dataType", element.DataType)
+			continue
+		}
+		ieWithValue, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, fmt.Errorf("error when creating information element: %v", err)
+		}
+		elements = append(elements, ieWithValue)
+	}
 	exp.ipfixSet.ResetSet()
 	if err := exp.ipfixSet.PrepareSet(ipfixentities.Template, templateID); err != nil {
 		return 0, err
@@ -387,6 +504,14 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 	if err := exp.ipfixSet.PrepareSet(ipfixentities.Data, templateID); err != nil {
 		return err
 	}
+	flowType := exp.findFlowType(*conn)
+	tcpMetrics := conn.TCPMetrics
+	if flowType == ipfixregistry.FlowTypeInterNode && conn.SourcePodName == "" {
+		// The metrics are exported by the Node of the client Pod, where the handshake
+		// RTT includes the network latency. The Flow Aggregator correlates them with
+		// the record of this Node.
+		tcpMetrics = flowexporter.TCPMetrics{}
+	}
 	// Iterate over all infoElements in the list
 	for i := range eL {
 		ie := eL[i]
@@ -454,23 +579,14 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 		case "sourcePodName":
 			ie.SetStringValue(conn.SourcePodName)
 		case "sourceNodeName":
-			// Add nodeName for only local pods whose pod names are resolved.
-			if conn.SourcePodName != "" {
-				ie.SetStringValue(exp.nodeName)
-			} else {
-				ie.SetStringValue("")
-			}
+			// The Node name is only set for local Pods whose names are resolved.
+			ie.SetStringValue(conn.SourceNodeName)
 		case "destinationPodNamespace":
 			ie.SetStringValue(conn.DestinationPodNamespace)
 		case "destinationPodName":
 			ie.SetStringValue(conn.DestinationPodName)
 		case "destinationNodeName":
-			// Add nodeName for only local pods whose pod names are resolved.
-			if conn.DestinationPodName != "" {
-				ie.SetStringValue(exp.nodeName)
-			} else {
-				ie.SetStringValue("")
-			}
+			ie.SetStringValue(conn.DestinationNodeName)
 		case "destinationClusterIPv4":
 			if conn.DestinationServicePortName != "" {
 				ie.SetIPAddressValue(conn.DestinationServiceAddress)
@@ -495,6 +611,8 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 			}
 		case "destinationServicePortName":
 			ie.SetStringValue(conn.DestinationServicePortName)
+		case "destinationServiceType":
+			ie.SetStringValue(conn.DestinationServiceType)
 		case "ingressNetworkPolicyName":
 			ie.SetStringValue(conn.IngressNetworkPolicyName)
 		case "ingressNetworkPolicyNamespace":
@@ -518,7 +636,26 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 		case "tcpState":
 			ie.SetStringValue(conn.TCPState)
 		case "flowType":
-			ie.SetUnsigned8Value(exp.findFlowType(*conn))
+			ie.SetUnsigned8Value(flowType)
+		case "egressName":
+			// Egress SNAT only applies to Pod-to-External traffic.
+			if flowType == ipfixregistry.FlowTypeToExternal {
+				ie.SetStringValue(conn.EgressName)
+			} else {
+				ie.SetStringValue("")
+			}
+		case "egressIP":
+			if flowType == ipfixregistry.FlowTypeToExternal {
+				ie.SetStringValue(conn.EgressIP)
+			} else {
+				ie.SetStringValue("")
+			}
+		case "tcpHandshakeRTT":
+			ie.SetSigned32Value(durationToMicroseconds(tcpMetrics.HandshakeRTT))
+		case "tcpHandshakeRetransmissionCount":
+			ie.SetUnsigned16Value(tcpMetrics.HandshakeRetransmissions)
+		case "tcpResetCount":
+			ie.SetUnsigned16Value(tcpMetrics.Resets)
 		}
 	}
 	err := exp.ipfixSet.AddRecord(eL, templateID)
@@ -577,6 +714,15 @@ func (exp *FlowExporter) exportConn(conn *flowexporter.Connection) error {
 	return nil
 }
 
+// durationToMicroseconds converts d to microseconds, capped to the maximum value of the
+// element.
+func durationToMicroseconds(d time.Duration) int32 {
+	if d.Microseconds() > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(d.Microseconds())
+}
+
 func getMinTime(t1, t2 time.Duration) time.Duration {
 	if t1 <= t2 {
 		return t1
@@ -158,8 +158,8 @@ func NewFlowExporterForTest(o *flowexporter.FlowExporterOptions) *FlowExporter {
 	v4Enabled := true
 	v6Enabled := false
 
-	denyConnStore := connections.NewDenyConnectionStore(nil, nil, o)
-	conntrackConnStore := connections.NewConntrackConnectionStore(nil, v4Enabled, v6Enabled, nil, nil, nil, o)
+	denyConnStore := connections.NewDenyConnectionStore(nodeName, nil, nil, nil, o)
+	conntrackConnStore := connections.NewConntrackConnectionStore(nodeName, nil, v4Enabled, v6Enabled, nil, nil, nil, nil, nil, o)
 
 	return &FlowExporter{
 		conntrackConnStore:     conntrackConnStore,
@@ -15,6 +15,7 @@
 package exporter
 
 import (
+	"fmt"
 	"net"
 	"strings"
 	"testing"
@@ -106,6 +107,14 @@ func sendTemplateSet(t *testing.T, ctrl *gomock.Controller, mockIPFIXExpProc *ip
 	for i, ie := range antreaIE {
 		mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(elemList[i+len(ianaIE)+len(IANAReverseInfoElements)].GetInfoElement(), nil)
 	}
+	// Optional elements which are not in the registry are not part of the template. The
+	// TCP metrics are not looked up when they are disabled.
+	for _, ie := range AntreaOptionalInfoElements {
+		if _, isTCPMetric := tcpMetricsInfoElementTypes[ie]; isTCPMetric {
+			continue
+		}
+		mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(nil, fmt.Errorf("%s not present", ie))
+	}
 	if !isIPv6 {
 		mockTempSet.EXPECT().AddRecord(elemList, testTemplateIDv4).Return(nil)
 	} else {
@@ -262,6 +271,45 @@ func testSendDataSet(t *testing.T, v4Enabled bool, v6Enabled bool) {
 	}
 }
 
+func TestFlowExporter_addConnToSetTCPMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newElement := func(name string, dataType ipfixentities.IEDataType) ipfixentities.InfoElementWithValue {
+		ie, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(ipfixentities.NewInfoElement(name, 0, dataType, ipfixregistry.AntreaEnterpriseID, 0), nil)
+		return ie
+	}
+	elemList := []ipfixentities.InfoElementWithValue{
+		newElement("tcpHandshakeRTT", ipfixentities.Signed32),
+		newElement("tcpHandshakeRetransmissionCount", ipfixentities.Unsigned16),
+		newElement("tcpResetCount", ipfixentities.Unsigned16),
+	}
+	mockDataSet := ipfixentitiestesting.NewMockSet(ctrl)
+	flowExp := &FlowExporter{
+		elementsListv4:      elemList,
+		templateIDv4:        testTemplateIDv4,
+		ipfixSet:            mockDataSet,
+		isNetworkPolicyOnly: true,
+	}
+	conn := getConnection(false, true, 302, 6, "ESTABLISHED")
+	conn.TCPMetrics = flowexporter.TCPMetrics{HandshakeRTT: 1500 * time.Microsecond, HandshakeRetransmissions: 1, Resets: 2}
+
+	mockDataSet.EXPECT().ResetSet().Times(2)
+	mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil).Times(2)
+	mockDataSet.EXPECT().AddRecord(elemList, testTemplateIDv4).Return(nil).Times(2)
+	assert.NoError(t, flowExp.addConnToSet(conn))
+	assert.Equal(t, int32(1500), elemList[0].GetSigned32Value())
+	assert.Equal(t, uint16(1), elemList[1].GetUnsigned16Value())
+	assert.Equal(t, uint16(2), elemList[2].GetUnsigned16Value())
+
+	// The metrics are only exported by the Node of the client Pod for Inter-Node flows.
+	conn.SourcePodName = ""
+	assert.NoError(t, flowExp.addConnToSet(conn))
+	assert.Equal(t, int32(0), elemList[0].GetSigned32Value())
+	assert.Equal(t, uint16(0), elemList[1].GetUnsigned16Value())
+	assert.Equal(t, uint16(0), elemList[2].GetUnsigned16Value())
+}
+
 func TestFlowExporter_initFlowExporter(t *testing.T) {
 	metrics.InitializeConnectionMetrics()
 	udpAddr, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
@@ -551,8 +599,8 @@ func runSendFlowRecordTests(t *testing.T, flowExp *FlowExporter, isIPv6 bool) {
 				IdleFlowTimeout:        testIdleFlowTimeout,
 				StaleConnectionTimeout: 1,
 				PollInterval:           1}
-			flowExp.conntrackConnStore = connections.NewConntrackConnectionStore(mockConnDumper, !isIPv6, isIPv6, nil, nil, nil, o)
-			flowExp.denyConnStore = connections.NewDenyConnectionStore(nil, nil, o)
+			flowExp.conntrackConnStore = connections.NewConntrackConnectionStore("", mockConnDumper, !isIPv6, isIPv6, nil, nil, nil, nil, nil, o)
+			flowExp.denyConnStore = connections.NewDenyConnectionStore("", nil, nil, nil, o)
 			flowExp.conntrackPriorityQueue = flowExp.conntrackConnStore.GetPriorityQueue()
 			flowExp.denyPriorityQueue = flowExp.denyConnStore.GetPriorityQueue()
 			flowExp.numDataSetsSent = 0
@@ -0,0 +1,223 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/exporter"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/sharding"
+	"antrea.io/antrea/pkg/ipfix"
+)
+
+// startTemplateID is the ID after which template IDs are allocated, as done by the
+// exporting process of go-ipfix.
+const startTemplateID uint16 = 255
+
+type templateRecord struct {
+	templateID uint16
+	elements   []ipfixentities.InfoElementWithValue
+}
+
+var _ ipfix.IPFIXExportingProcess = new(shardedExportingProcess)
+
+// shardedExportingProcess sends the records of each flow to the Flow Aggregator replica
+// owning the flow. A connection is established to each replica when the first record is
+// sent to it, and the templates sent so far are replayed on it first. Connections to the
+// replicas which are no longer ready are closed.
+type shardedExportingProcess struct {
+	sharder *sharding.Sharder
+	// input is used to connect to the replicas, after setting CollectorAddress.
+	input      exporter.ExporterInput
+	newProcess func(input exporter.ExporterInput) (ipfix.IPFIXExportingProcess, error)
+	templateID uint16
+	templates  []templateRecord
+	processes  map[string]ipfix.IPFIXExportingProcess
+}
+
+func newShardedExportingProcess(sharder *sharding.Sharder, input exporter.ExporterInput) *shardedExportingProcess {
+	return &shardedExportingProcess{
+		sharder: sharder,
+		input:   input,
+		newProcess: func(input exporter.ExporterInput) (ipfix.IPFIXExportingProcess, error) {
+			return ipfix.NewIPFIXExportingProcess(input)
+		},
+		templateID: startTemplateID,
+		processes:  make(map[string]ipfix.IPFIXExportingProcess),
+	}
+}
+
+func (p *shardedExportingProcess) NewTemplateID() uint16 {
+	p.templateID++
+	return p.templateID
+}
+
+func (p *shardedExportingProcess) SendSet(set ipfixentities.Set) (int, error) {
+	if set.GetSetType() == ipfixentities.Template {
+		return p.sendTemplateSet(set)
+	}
+	p.closeStaleProcesses()
+	recordsByReplica := make(map[string][]ipfixentities.Record)
+	for _, record := range set.GetRecords() {
+		key, err := sharding.RecordFlowKey(record)
+		if err != nil {
+			return 0, err
+		}
+		replica, ok := p.sharder.GetOwner(key)
+		if !ok {
+			return 0, fmt.Errorf("no Flow Aggregator replica is ready")
+		}
+		recordsByReplica[replica] = append(recordsByReplica[replica], record)
+	}
+	sentBytes := 0
+	for replica, records := range recordsByReplica {
+		replicaSet := set
+		// The set is only split when its records are owned by different replicas,
+		// which never happens with the FlowExporter as it sends one record per set.
+		if len(recordsByReplica) > 1 {
+			replicaSet = ipfixentities.NewSet(false)
+			if err := replicaSet.PrepareSet(ipfixentities.Data, records[0].GetTemplateID()); err != nil {
+				return sentBytes, err
+			}
+			for _, record := range records {
+				if err := replicaSet.AddRecord(record.GetOrderedElementList(), record.GetTemplateID()); err != nil {
+					return sentBytes, err
+				}
+			}
+		}
+		process, err := p.getProcess(replica)
+		if err != nil {
+			return sentBytes, err
+		}
+		n, err := process.SendSet(replicaSet)
+		if err != nil {
+			p.closeProcess(replica)
+			return sentBytes, fmt.Errorf("error when sending data set to Flow Aggregator replica %s: %v", replica, err)
+		}
+		sentBytes += n
+	}
+	return sentBytes, nil
+}
+
+// sendTemplateSet stores the templates of the set, so that they can be sent to the
+// replicas connected later, and sends it to the replicas already connected.
+func (p *shardedExportingProcess) sendTemplateSet(set ipfixentities.Set) (int, error) {
+	for _, record := range set.GetRecords() {
+		// The elements are copied, as the FlowExporter sets the values of the
+		// elements of its templates when sending data records, and a template
+		// record cannot include elements with values.
+		template := templateRecord{templateID: record.GetTemplateID()}
+		for _, ie := range record.GetOrderedElementList() {
+			element, err := ipfixentities.DecodeAndCreateInfoElementWithValue(ie.GetInfoElement(), nil)
+			if err != nil {
+				return 0, err
+			}
+			template.elements = append(template.elements, element)
+		}
+		replaced := false
+		for i := range p.templates {
+			if p.templates[i].templateID == template.templateID {
+				p.templates[i] = template
+				replaced = true
+			}
+		}
+		if !replaced {
+			p.templates = append(p.templates, template)
+		}
+	}
+	sentBytes := 0
+	for replica, process := range p.processes {
+		n, err := process.SendSet(set)
+		if err != nil {
+			p.closeProcess(replica)
+			return sentBytes, fmt.Errorf("error when sending template set to Flow Aggregator replica %s: %v", replica, err)
+		}
+		sentBytes += n
+	}
+	return sentBytes, nil
+}
+
+func (p *shardedExportingProcess) getProcess(replica string) (ipfix.IPFIXExportingProcess, error) {
+	if process, ok := p.processes[replica]; ok {
+		return process, nil
+	}
+	protocol := corev1.ProtocolTCP
+	if p.input.CollectorProtocol == "udp" {
+		protocol = corev1.ProtocolUDP
+	}
+	port, ok := p.sharder.GetPort(protocol)
+	if !ok {
+		return nil, fmt.Errorf("no %s port for Flow Aggregator replicas", protocol)
+	}
+	input := p.input
+	input.CollectorAddress = net.JoinHostPort(replica, strconv.Itoa(int(port)))
+	process, err := p.newProcess(input)
+	if err != nil {
+		return nil, fmt.Errorf("error when connecting to Flow Aggregator replica %s: %v", replica, err)
+	}
+	for _, template := range p.templates {
+		set := ipfixentities.NewSet(false)
+		if err := set.PrepareSet(ipfixentities.Template, template.templateID); err != nil {
+			process.CloseConnToCollector()
+			return nil, err
+		}
+		if err := set.AddRecord(template.elements, template.templateID); err != nil {
+			process.CloseConnToCollector()
+			return nil, err
+		}
+		if _, err := process.SendSet(set); err != nil {
+			process.CloseConnToCollector()
+			return nil, fmt.Errorf("error when sending template set to Flow Aggregator replica %s: %v", replica, err)
+		}
+	}
+	klog.InfoS("Connected to Flow Aggregator replica", "address", input.CollectorAddress)
+	p.processes[replica] = process
+	return process, nil
+}
+
+// closeStaleProcesses closes the connections to the replicas which are no longer ready.
+// The flows they owned have been moved to other replicas.
+func (p *shardedExportingProcess) closeStaleProcesses() {
+	if len(p.processes) == 0 {
+		return
+	}
+	replicas := make(map[string]struct{})
+	for _, replica := range p.sharder.GetReplicas() {
+		replicas[replica] = struct{}{}
+	}
+	for replica := range p.processes {
+		if _, ok := replicas[replica]; !ok {
+			klog.InfoS("Flow Aggregator replica is no longer ready, closing connection", "replica", replica)
+			p.closeProcess(replica)
+		}
+	}
+}
+
+func (p *shardedExportingProcess) closeProcess(replica string) {
+	p.processes[replica].CloseConnToCollector()
+	delete(p.processes, replica)
+}
+
+func (p *shardedExportingProcess) CloseConnToCollector() {
+	for replica := range p.processes {
+		p.closeProcess(replica)
+	}
+}
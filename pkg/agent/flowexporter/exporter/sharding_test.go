@@ -0,0 +1,146 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/exporter"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"antrea.io/antrea/pkg/flowaggregator/sharding"
+	"antrea.io/antrea/pkg/ipfix"
+	ipfixtest "antrea.io/antrea/pkg/ipfix/testing"
+)
+
+func newFlowAggregatorEndpoints(resourceVersion string, replicas ...string) *corev1.Endpoints {
+	subset := corev1.EndpointSubset{
+		Ports: []corev1.EndpointPort{{Name: "ipfix-tcp", Port: 4739, Protocol: corev1.ProtocolTCP}},
+	}
+	for _, replica := range replicas {
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: replica})
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "flow-aggregator", Name: "flow-aggregator", ResourceVersion: resourceVersion},
+		Subsets:    []corev1.EndpointSubset{subset},
+	}
+}
+
+func newShardingTestSets(t *testing.T, templateID uint16) (ipfixentities.Set, ipfixentities.Set) {
+	ipfixregistry.LoadRegistry()
+	newElement := func(name string) *ipfixentities.InfoElement {
+		element, err := ipfixregistry.GetInfoElement(name, ipfixregistry.IANAEnterpriseID)
+		require.NoError(t, err)
+		return element
+	}
+	elements := []ipfixentities.InfoElementWithValue{
+		ipfixentities.NewIPAddressInfoElement(newElement("sourceIPv4Address"), net.ParseIP("10.10.0.1")),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationIPv4Address"), net.ParseIP("10.10.1.2")),
+		ipfixentities.NewUnsigned16InfoElement(newElement("sourceTransportPort"), 34567),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationTransportPort"), 80),
+		ipfixentities.NewUnsigned8InfoElement(newElement("protocolIdentifier"), 6),
+	}
+	templateElements := make([]ipfixentities.InfoElementWithValue, len(elements))
+	for i := range elements {
+		element, err := ipfixentities.DecodeAndCreateInfoElementWithValue(elements[i].GetInfoElement(), nil)
+		require.NoError(t, err)
+		templateElements[i] = element
+	}
+	templateSet := ipfixentities.NewSet(false)
+	require.NoError(t, templateSet.PrepareSet(ipfixentities.Template, templateID))
+	require.NoError(t, templateSet.AddRecord(templateElements, templateID))
+	dataSet := ipfixentities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(ipfixentities.Data, templateID))
+	require.NoError(t, dataSet.AddRecord(elements, templateID))
+	return templateSet, dataSet
+}
+
+func TestShardedExportingProcess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(newFlowAggregatorEndpoints("1", "10.0.0.1", "10.0.0.2")))
+	sharder := sharding.NewSharder("flow-aggregator", "flow-aggregator", corelisters.NewEndpointsLister(indexer))
+	owner, ok := sharder.GetOwner(sharding.FlowKey("10.10.0.1", "10.10.1.2", 6, 34567, 80))
+	require.True(t, ok)
+	other := "10.0.0.1"
+	if owner == other {
+		other = "10.0.0.2"
+	}
+
+	processes := map[string]*ipfixtest.MockIPFIXExportingProcess{
+		net.JoinHostPort(owner, "4739"): ipfixtest.NewMockIPFIXExportingProcess(ctrl),
+		net.JoinHostPort(other, "4739"): ipfixtest.NewMockIPFIXExportingProcess(ctrl),
+	}
+	p := newShardedExportingProcess(sharder, exporter.ExporterInput{CollectorProtocol: "tcp"})
+	p.newProcess = func(input exporter.ExporterInput) (ipfix.IPFIXExportingProcess, error) {
+		process, ok := processes[input.CollectorAddress]
+		require.True(t, ok, "unexpected replica address %s", input.CollectorAddress)
+		return process, nil
+	}
+	expectSets := func(process *ipfixtest.MockIPFIXExportingProcess, setTypes ...ipfixentities.ContentType) {
+		var calls []*gomock.Call
+		for i := range setTypes {
+			setType := setTypes[i]
+			calls = append(calls, process.EXPECT().SendSet(gomock.Any()).DoAndReturn(func(set ipfixentities.Set) (int, error) {
+				assert.Equal(t, setType, set.GetSetType())
+				return 10, nil
+			}))
+		}
+		gomock.InOrder(calls...)
+	}
+
+	templateID := p.NewTemplateID()
+	assert.Equal(t, uint16(256), templateID)
+	templateSet, dataSet := newShardingTestSets(t, templateID)
+
+	// No replica is connected yet, the template is only stored.
+	sentBytes, err := p.SendSet(templateSet)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sentBytes)
+
+	// The template is replayed when connecting to the owner of the flow.
+	expectSets(processes[net.JoinHostPort(owner, "4739")], ipfixentities.Template, ipfixentities.Data, ipfixentities.Data)
+	sentBytes, err = p.SendSet(dataSet)
+	require.NoError(t, err)
+	assert.Equal(t, 10, sentBytes)
+	_, err = p.SendSet(dataSet)
+	require.NoError(t, err)
+
+	// The flow is moved to the other replica when its owner is no longer ready.
+	processes[net.JoinHostPort(owner, "4739")].EXPECT().CloseConnToCollector()
+	require.NoError(t, indexer.Update(newFlowAggregatorEndpoints("2", other)))
+	expectSets(processes[net.JoinHostPort(other, "4739")], ipfixentities.Template, ipfixentities.Data)
+	_, err = p.SendSet(dataSet)
+	require.NoError(t, err)
+
+	processes[net.JoinHostPort(other, "4739")].EXPECT().CloseConnToCollector()
+	p.CloseConnToCollector()
+
+	// Data records cannot be sent without any ready replica.
+	require.NoError(t, indexer.Update(newFlowAggregatorEndpoints("3")))
+	_, err = p.SendSet(dataSet)
+	assert.Error(t, err)
+}
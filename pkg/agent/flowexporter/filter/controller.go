@@ -0,0 +1,123 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	agentconfig "antrea.io/antrea/pkg/config/agent"
+)
+
+const controllerName = "FlowExportPolicyController"
+
+// configFileCheckInterval is the interval at which the configuration file is checked for
+// changes. The kubelet takes up to a minute to update a ConfigMap volume after the
+// ConfigMap is edited.
+var configFileCheckInterval = 10 * time.Second
+
+// Controller keeps the flowExportPolicy up to date with the antrea-agent configuration
+// file, so that it can be changed without restarting the antrea-agent. The file must be
+// in a ConfigMap volume mounted without subPath, otherwise it is never updated by the
+// kubelet.
+type Controller struct {
+	configFile string
+	// configData is the last content read from configFile.
+	configData []byte
+
+	mutex  sync.RWMutex
+	config agentconfig.FlowExportPolicyConfig
+	policy *Policy
+}
+
+// NewController returns a Controller starting with the flowExportPolicy of the agent
+// configuration, which is then reloaded from configFile. When configFile is empty, the
+// policy is never reloaded.
+func NewController(configFile string, config agentconfig.FlowExportPolicyConfig) (*Controller, error) {
+	policy, err := NewPolicy(&config)
+	if err != nil {
+		return nil, err
+	}
+	c := &Controller{
+		configFile: configFile,
+		config:     config,
+		policy:     policy,
+	}
+	if configFile == "" {
+		klog.InfoS("No reloadable antrea-agent configuration file, flowExportPolicy will not be reloaded")
+	}
+	return c, nil
+}
+
+// GetPolicy returns the current Policy.
+func (c *Controller) GetPolicy() *Policy {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.policy
+}
+
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	if c.configFile == "" {
+		return
+	}
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+	wait.Until(c.checkConfigFile, configFileCheckInterval, stopCh)
+}
+
+func (c *Controller) checkConfigFile() {
+	data, err := ioutil.ReadFile(c.configFile)
+	if err != nil {
+		klog.ErrorS(err, "Failed to read antrea-agent configuration file", "file", c.configFile)
+		return
+	}
+	// The file is only parsed when it changes, so that an invalid policy is only logged once.
+	if bytes.Equal(data, c.configData) {
+		return
+	}
+	c.configData = data
+	if err := c.syncConfig(data); err != nil {
+		// The previous policy is kept until the configuration is fixed.
+		klog.ErrorS(err, "Invalid flowExportPolicy in antrea-agent configuration file, keeping the current policy", "file", c.configFile)
+	}
+}
+
+func (c *Controller) syncConfig(data []byte) error {
+	agentConfig := &agentconfig.AgentConfig{}
+	if err := yaml.Unmarshal(data, agentConfig); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %v", c.configFile, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if reflect.DeepEqual(agentConfig.FlowExportPolicy, c.config) {
+		return nil
+	}
+	policy, err := NewPolicy(&agentConfig.FlowExportPolicy)
+	if err != nil {
+		return err
+	}
+	c.config = agentConfig.FlowExportPolicy
+	c.policy = policy
+	klog.InfoS("Reloaded flowExportPolicy", "file", c.configFile)
+	return nil
+}
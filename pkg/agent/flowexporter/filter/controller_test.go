@@ -0,0 +1,88 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	agentconfig "antrea.io/antrea/pkg/config/agent"
+)
+
+func TestController(t *testing.T) {
+	defer func(interval time.Duration) {
+		configFileCheckInterval = interval
+	}(configFileCheckInterval)
+	configFileCheckInterval = 10 * time.Millisecond
+
+	configDir, err := ioutil.TempDir("", "flow-export-policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(configDir)
+	configFile := filepath.Join(configDir, "antrea-agent.conf")
+	writeConfigFile := func(agentConf string) {
+		require.NoError(t, ioutil.WriteFile(configFile, []byte(agentConf), 0644))
+	}
+	writeConfigFile(`
+featureGates:
+  FlowExporter: true
+flowExportPolicy:
+  exclude:
+  - namespaces: [ns1]
+`)
+
+	c, err := NewController(configFile, agentconfig.FlowExportPolicyConfig{})
+	require.NoError(t, err)
+	conn := newConnection("10.10.0.1", "10.10.1.2", 6, 34567, 80)
+	assert.True(t, c.GetPolicy().Match(conn, false, nil))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(stopCh)
+
+	// The policy of the mounted file replaces the one the antrea-agent was started with.
+	require.NoError(t, wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return !c.GetPolicy().Match(conn, false, nil), nil
+	}))
+
+	// An invalid policy is ignored.
+	writeConfigFile(`
+flowExportPolicy:
+  samplingRate: 2
+`)
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, c.GetPolicy().Match(conn, false, nil))
+
+	writeConfigFile(`
+flowExportPolicy:
+  include:
+  - podSelector: app=web
+`)
+	require.NoError(t, wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		policy := c.GetPolicy()
+		return len(policy.include) == 1 && len(policy.exclude) == 0, nil
+	}))
+}
+
+func TestNewControllerInvalidPolicy(t *testing.T) {
+	_, err := NewController("", agentconfig.FlowExportPolicyConfig{SamplingRate: 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,268 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filter decides which connections are exported by the flow exporter, according to
+// the flowExportPolicy of the antrea-agent configuration.
+package filter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	agentconfig "antrea.io/antrea/pkg/config/agent"
+)
+
+var protocolNumbers = map[string]uint8{
+	"TCP":    6,
+	"UDP":    17,
+	"SCTP":   132,
+	"ICMP":   1,
+	"ICMPV6": 58,
+}
+
+type portRange struct {
+	start, end uint16
+}
+
+type filter struct {
+	namespaces    sets.String
+	podSelector   labels.Selector
+	cidrs         []*net.IPNet
+	protocols     map[uint8]bool
+	ports         []portRange
+	policyActions map[uint8]bool
+}
+
+// Policy is the compiled flowExportPolicy. A nil Policy exports all the connections.
+type Policy struct {
+	// samplingThreshold is the upper bound of the hashes of the sampled connections.
+	samplingThreshold uint64
+	sampleDenied      bool
+	include           []*filter
+	exclude           []*filter
+}
+
+// NewPolicy validates and compiles the provided configuration.
+func NewPolicy(config *agentconfig.FlowExportPolicyConfig) (*Policy, error) {
+	rate := config.SamplingRate
+	if rate == 0 {
+		rate = 1
+	}
+	if rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("samplingRate %v is invalid, it should be in (0, 1]", config.SamplingRate)
+	}
+	p := &Policy{
+		samplingThreshold: math.MaxUint64,
+		sampleDenied:      config.SampleDeniedConnections,
+	}
+	if rate < 1 {
+		p.samplingThreshold = uint64(rate * math.MaxUint64)
+	}
+	var err error
+	if p.include, err = newFilters(config.Include); err != nil {
+		return nil, fmt.Errorf("invalid include filter: %v", err)
+	}
+	if p.exclude, err = newFilters(config.Exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude filter: %v", err)
+	}
+	return p, nil
+}
+
+func newFilters(configs []agentconfig.FlowExportFilter) ([]*filter, error) {
+	filters := make([]*filter, 0, len(configs))
+	for i := range configs {
+		f, err := newFilter(&configs[i])
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+func newFilter(config *agentconfig.FlowExportFilter) (*filter, error) {
+	f := &filter{}
+	if len(config.Namespaces) > 0 {
+		f.namespaces = sets.NewString(config.Namespaces...)
+	}
+	if config.PodSelector != "" {
+		selector, err := labels.Parse(config.PodSelector)
+		if err != nil {
+			return nil, fmt.Errorf("podSelector %s is invalid: %v", config.PodSelector, err)
+		}
+		f.podSelector = selector
+	}
+	for _, cidr := range config.CIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("CIDR %s is invalid: %v", cidr, err)
+		}
+		f.cidrs = append(f.cidrs, ipNet)
+	}
+	for _, protocol := range config.Protocols {
+		number, ok := protocolNumbers[strings.ToUpper(protocol)]
+		if !ok {
+			return nil, fmt.Errorf("protocol %s is not supported", protocol)
+		}
+		if f.protocols == nil {
+			f.protocols = make(map[uint8]bool)
+		}
+		f.protocols[number] = true
+	}
+	for _, port := range config.Ports {
+		r, err := parsePortRange(port)
+		if err != nil {
+			return nil, err
+		}
+		f.ports = append(f.ports, r)
+	}
+	for _, action := range config.PolicyActions {
+		value := flowexporter.RuleActionToUint8(action)
+		if value == registry.NetworkPolicyRuleActionNoAction {
+			return nil, fmt.Errorf("policy action %s is invalid, it should be Allow, Drop, Reject or Audit", action)
+		}
+		if f.policyActions == nil {
+			f.policyActions = make(map[uint8]bool)
+		}
+		f.policyActions[value] = true
+	}
+	return f, nil
+}
+
+func parsePortRange(port string) (portRange, error) {
+	start, end := port, port
+	if i := strings.Index(port, "-"); i >= 0 {
+		start, end = port[:i], port[i+1:]
+	}
+	startPort, err := strconv.ParseUint(start, 10, 16)
+	if err != nil {
+		return portRange{}, fmt.Errorf("port %s is invalid: %v", port, err)
+	}
+	endPort, err := strconv.ParseUint(end, 10, 16)
+	if err != nil {
+		return portRange{}, fmt.Errorf("port %s is invalid: %v", port, err)
+	}
+	if startPort > endPort {
+		return portRange{}, fmt.Errorf("port range %s is invalid", port)
+	}
+	return portRange{start: uint16(startPort), end: uint16(endPort)}, nil
+}
+
+// Match returns whether the connection should be exported. denied must be true for the
+// connections of the DenyConnectionStore, which are not sampled unless
+// sampleDeniedConnections is set. podLister is used to get the labels of the Pods for
+// podSelector, and may be nil, in which case podSelector never matches.
+func (p *Policy) Match(conn *flowexporter.Connection, denied bool, podLister corelisters.PodLister) bool {
+	if p == nil {
+		return true
+	}
+	if len(p.include) > 0 {
+		included := false
+		for _, f := range p.include {
+			if f.match(conn, podLister) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, f := range p.exclude {
+		if f.match(conn, podLister) {
+			return false
+		}
+	}
+	if denied && !p.sampleDenied {
+		return true
+	}
+	return p.sampled(conn)
+}
+
+// sampled returns whether the connection is sampled. The decision only depends on the
+// 5-tuple of the connection, so that all the records of a connection are exported or none.
+func (p *Policy) sampled(conn *flowexporter.Connection) bool {
+	if p.samplingThreshold == math.MaxUint64 {
+		return true
+	}
+	key := flowexporter.NewConnectionKey(conn)
+	h := fnv.New64a()
+	for _, s := range key {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return h.Sum64() <= p.samplingThreshold
+}
+
+func (f *filter) match(conn *flowexporter.Connection, podLister corelisters.PodLister) bool {
+	if f.namespaces != nil && !f.namespaces.Has(conn.SourcePodNamespace) && !f.namespaces.Has(conn.DestinationPodNamespace) {
+		return false
+	}
+	if f.podSelector != nil && !podMatches(f.podSelector, conn.SourcePodNamespace, conn.SourcePodName, podLister) &&
+		!podMatches(f.podSelector, conn.DestinationPodNamespace, conn.DestinationPodName, podLister) {
+		return false
+	}
+	if f.cidrs != nil && !cidrsContain(f.cidrs, conn.FlowKey.SourceAddress) && !cidrsContain(f.cidrs, conn.FlowKey.DestinationAddress) {
+		return false
+	}
+	if f.protocols != nil && !f.protocols[conn.FlowKey.Protocol] {
+		return false
+	}
+	if f.ports != nil && !portsContain(f.ports, conn.FlowKey.DestinationPort) {
+		return false
+	}
+	if f.policyActions != nil && !f.policyActions[conn.IngressNetworkPolicyRuleAction] && !f.policyActions[conn.EgressNetworkPolicyRuleAction] {
+		return false
+	}
+	return true
+}
+
+func podMatches(selector labels.Selector, namespace, name string, podLister corelisters.PodLister) bool {
+	if name == "" || podLister == nil {
+		return false
+	}
+	pod, err := podLister.Pods(namespace).Get(name)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+func cidrsContain(cidrs []*net.IPNet, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func portsContain(ports []portRange, port uint16) bool {
+	for _, r := range ports {
+		if port >= r.start && port <= r.end {
+			return true
+		}
+	}
+	return false
+}
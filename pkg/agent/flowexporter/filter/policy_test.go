@@ -0,0 +1,237 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/go-ipfix/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	agentconfig "antrea.io/antrea/pkg/config/agent"
+)
+
+func newConnection(srcIP, dstIP string, protocol uint8, srcPort, dstPort uint16) *flowexporter.Connection {
+	return &flowexporter.Connection{
+		FlowKey: flowexporter.Tuple{
+			SourceAddress:      net.ParseIP(srcIP),
+			DestinationAddress: net.ParseIP(dstIP),
+			Protocol:           protocol,
+			SourcePort:         srcPort,
+			DestinationPort:    dstPort,
+		},
+		SourcePodNamespace:      "ns1",
+		SourcePodName:           "client",
+		DestinationPodNamespace: "ns2",
+		DestinationPodName:      "server",
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		config agentconfig.FlowExportPolicyConfig
+	}{
+		{
+			name:   "negative samplingRate",
+			config: agentconfig.FlowExportPolicyConfig{SamplingRate: -0.5},
+		},
+		{
+			name:   "samplingRate greater than 1",
+			config: agentconfig.FlowExportPolicyConfig{SamplingRate: 1.5},
+		},
+		{
+			name:   "invalid podSelector",
+			config: agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{PodSelector: "app in web"}}},
+		},
+		{
+			name:   "invalid CIDR",
+			config: agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{CIDRs: []string{"10.0.0.0"}}}},
+		},
+		{
+			name:   "invalid protocol",
+			config: agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{{Protocols: []string{"GRE"}}}},
+		},
+		{
+			name:   "invalid port range",
+			config: agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{{Ports: []string{"90-80"}}}},
+		},
+		{
+			name:   "invalid policy action",
+			config: agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{{PolicyActions: []string{"Pass"}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy(&tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPolicyMatch(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "server", Labels: map[string]string{"app": "web"}},
+	}))
+	podLister := corelisters.NewPodLister(indexer)
+
+	tcpConn := newConnection("10.10.0.1", "10.10.1.2", 6, 34567, 80)
+	udpConn := newConnection("10.10.0.1", "192.168.1.1", 17, 34567, 53)
+	deniedConn := newConnection("10.10.0.1", "10.10.1.2", 6, 34568, 8080)
+	deniedConn.EgressNetworkPolicyRuleAction = registry.NetworkPolicyRuleActionDrop
+	auditedConn := newConnection("10.10.0.1", "10.10.1.2", 6, 34569, 8080)
+	auditedConn.IngressNetworkPolicyRuleAction = flowexporter.NetworkPolicyRuleActionAudit
+
+	tests := []struct {
+		name     string
+		config   agentconfig.FlowExportPolicyConfig
+		conn     *flowexporter.Connection
+		denied   bool
+		expected bool
+	}{
+		{
+			name:     "empty policy",
+			conn:     tcpConn,
+			expected: true,
+		},
+		{
+			name:     "included by namespace",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{Namespaces: []string{"ns2"}}}},
+			conn:     tcpConn,
+			expected: true,
+		},
+		{
+			name:     "not included by namespace",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{Namespaces: []string{"ns3"}}}},
+			conn:     tcpConn,
+			expected: false,
+		},
+		{
+			name:     "included by Pod label",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{PodSelector: "app in (web,db)"}}},
+			conn:     tcpConn,
+			expected: true,
+		},
+		{
+			name:     "not included by Pod label",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{PodSelector: "app=db"}}},
+			conn:     tcpConn,
+			expected: false,
+		},
+		{
+			name:     "excluded by CIDR",
+			config:   agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{{CIDRs: []string{"192.168.0.0/16"}}}},
+			conn:     udpConn,
+			expected: false,
+		},
+		{
+			name: "excluded by protocol and port",
+			config: agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{
+				{Protocols: []string{"udp"}, Ports: []string{"53"}},
+			}},
+			conn:     udpConn,
+			expected: false,
+		},
+		{
+			name: "not excluded when a field does not match",
+			config: agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{
+				{Protocols: []string{"TCP"}, Ports: []string{"53"}},
+			}},
+			conn:     udpConn,
+			expected: true,
+		},
+		{
+			name:     "included by port range",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{Ports: []string{"8000-8080"}}}},
+			conn:     deniedConn,
+			denied:   true,
+			expected: true,
+		},
+		{
+			name:     "included by policy action",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{PolicyActions: []string{"Drop", "Reject"}}}},
+			conn:     deniedConn,
+			denied:   true,
+			expected: true,
+		},
+		{
+			name:     "not included by policy action",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{PolicyActions: []string{"Drop"}}}},
+			conn:     tcpConn,
+			expected: false,
+		},
+		{
+			name:     "included by audit policy action",
+			config:   agentconfig.FlowExportPolicyConfig{Include: []agentconfig.FlowExportFilter{{PolicyActions: []string{"Audit"}}}},
+			conn:     auditedConn,
+			expected: true,
+		},
+		{
+			name:     "denied connection is not sampled",
+			config:   agentconfig.FlowExportPolicyConfig{SamplingRate: 1e-9},
+			conn:     deniedConn,
+			denied:   true,
+			expected: true,
+		},
+		{
+			name:     "denied connection is sampled",
+			config:   agentconfig.FlowExportPolicyConfig{SamplingRate: 1e-9, SampleDeniedConnections: true},
+			conn:     deniedConn,
+			denied:   true,
+			expected: false,
+		},
+		{
+			name:     "denied connection is still filtered",
+			config:   agentconfig.FlowExportPolicyConfig{Exclude: []agentconfig.FlowExportFilter{{Namespaces: []string{"ns1"}}}},
+			conn:     deniedConn,
+			denied:   true,
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(&tt.config)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy.Match(tt.conn, tt.denied, podLister))
+		})
+	}
+
+	var nilPolicy *Policy
+	assert.True(t, nilPolicy.Match(tcpConn, false, nil))
+}
+
+func TestPolicySampling(t *testing.T) {
+	policy, err := NewPolicy(&agentconfig.FlowExportPolicyConfig{SamplingRate: 0.25})
+	require.NoError(t, err)
+	sampled := 0
+	for i := 0; i < 4000; i++ {
+		conn := newConnection("10.10.0.1", "10.10.1.2", 6, uint16(30000+i), 80)
+		match := policy.Match(conn, false, nil)
+		// The decision is the same for every record of the connection.
+		assert.Equal(t, match, policy.Match(conn, false, nil))
+		if match {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 1000, sampled, 150)
+}
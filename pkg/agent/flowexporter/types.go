@@ -17,6 +17,10 @@ package flowexporter
 import (
 	"net"
 	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	agentconfig "antrea.io/antrea/pkg/config/agent"
 )
 
 type ConnectionKey [5]string
@@ -59,9 +63,12 @@ type Connection struct {
 	SourcePodName                  string
 	DestinationPodNamespace        string
 	DestinationPodName             string
+	SourceNodeName                 string
+	DestinationNodeName            string
 	DestinationServicePortName     string
 	DestinationServiceAddress      net.IP
 	DestinationServicePort         uint16
+	DestinationServiceType         string
 	IngressNetworkPolicyName       string
 	IngressNetworkPolicyNamespace  string
 	IngressNetworkPolicyType       uint8
@@ -72,12 +79,29 @@ type Connection struct {
 	EgressNetworkPolicyType        uint8
 	EgressNetworkPolicyRuleName    string
 	EgressNetworkPolicyRuleAction  uint8
-	PrevPackets, PrevBytes         uint64
+	// EgressName and EgressIP are set when the source Pod is local and is selected
+	// by an Egress. They are only meaningful for Pod-to-External flows.
+	EgressName             string
+	EgressIP               string
+	PrevPackets, PrevBytes uint64
 	// Fields specific to conntrack connections
 	ReversePackets, ReverseBytes         uint64
 	PrevReversePackets, PrevReverseBytes uint64
 	TCPState                             string
 	PrevTCPState                         string
+	// TCPMetrics is only set when the TCP metrics are enabled.
+	TCPMetrics TCPMetrics
+}
+
+// TCPMetrics are computed from the SYN, SYN-ACK and RST packets of a TCP connection.
+type TCPMetrics struct {
+	// HandshakeRTT is the time between the first SYN and SYN-ACK packets of the
+	// connection observed by the Node. It is 0 if the handshake was not observed.
+	HandshakeRTT time.Duration
+	// HandshakeRetransmissions is the number of retransmitted SYN and SYN-ACK packets.
+	HandshakeRetransmissions uint16
+	// Resets is the number of RST packets.
+	Resets uint16
 }
 
 type ItemToExpire struct {
@@ -95,4 +119,15 @@ type FlowExporterOptions struct {
 	IdleFlowTimeout        time.Duration
 	StaleConnectionTimeout time.Duration
 	PollInterval           time.Duration
+	// FlowCollectorShardingService is the Service of the sharded Flow Aggregator, nil
+	// when sharding is disabled.
+	FlowCollectorShardingService *types.NamespacedName
+	// FlowExportPolicy decides which connections are exported.
+	FlowExportPolicy agentconfig.FlowExportPolicyConfig
+	// FlowExportPolicyFile is the antrea-agent configuration file from which
+	// FlowExportPolicy is reloaded when it changes. It is never reloaded when empty.
+	FlowExportPolicyFile string
+	// EnableTCPMetrics enables the handshake RTT, retransmission and reset metrics of
+	// TCP connections, which are computed from packets sent to the Agent by OVS.
+	EnableTCPMetrics bool
 }
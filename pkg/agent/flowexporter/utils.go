@@ -64,6 +64,12 @@ func CheckConntrackConnActive(conn *Connection) bool {
 	return false
 }
 
+// NetworkPolicyRuleActionAudit is the rule action of the connections matching a
+// Drop or Reject rule in audit mode, which would have been denied by the rule
+// and were allowed by the rules evaluated after it. It follows the rule actions
+// defined by the IPFIX registry.
+const NetworkPolicyRuleActionAudit = registry.NetworkPolicyRuleActionReject + 1
+
 // RuleActionToUint8 converts network policy rule action to uint8.
 func RuleActionToUint8(action string) uint8 {
 	switch action {
@@ -73,6 +79,8 @@ func RuleActionToUint8(action string) uint8 {
 		return registry.NetworkPolicyRuleActionDrop
 	case "Reject":
 		return registry.NetworkPolicyRuleActionReject
+	case "Audit":
+		return NetworkPolicyRuleActionAudit
 	default:
 		return registry.NetworkPolicyRuleActionNoAction
 	}
@@ -0,0 +1,262 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package l7proxy implements the HTTP proxy enforcing the L7 protocol fields of
+// Antrea-native policy rules. The connections allowed by such rules are
+// redirected by OVS to the host gateway and intercepted by the proxy, which
+// forwards the requests matching the rules to their original destination and
+// replies 403 to the others.
+package l7proxy
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+const (
+	// DefaultListenPort is the TCP port on the loopback interface that the
+	// redirected connections are delivered to.
+	DefaultListenPort = 10355
+)
+
+// IPBlock matches the IPs in CIDR except the ones in Except.
+type IPBlock struct {
+	CIDR   net.IPNet
+	Except []net.IPNet
+}
+
+// PortRange matches the TCP ports in [Port, EndPort]. EndPort equals to Port
+// if it matches a single port.
+type PortRange struct {
+	Port    int32
+	EndPort int32
+}
+
+// Rule is an Antrea-native policy rule with L7 protocols enforced by the proxy.
+type Rule struct {
+	// ID is the ID of the rule in the NetworkPolicy controller.
+	ID        string
+	Name      string
+	PolicyRef *v1beta2.NetworkPolicyReference
+	Direction v1beta2.Direction
+	Priority  types.Priority
+	// From and To are the source and destination addresses matched by the rule.
+	From []IPBlock
+	To   []IPBlock
+	// Ports is the list of destination ports matched by the rule. Empty means all ports.
+	Ports []PortRange
+	// HTTP is the list of HTTP requests allowed by the rule.
+	HTTP          []v1beta2.HTTPProtocol
+	EnableLogging bool
+}
+
+// Decision is the verdict of the proxy on an HTTP request.
+type Decision struct {
+	// Rule is the rule which made the decision. It's nil if the request was
+	// denied because no rule matched its connection.
+	Rule    *Rule
+	Allowed bool
+	SrcIP   net.IP
+	DstIP   net.IP
+	DstPort int
+	Method  string
+	Host    string
+	Path    string
+}
+
+// DecisionHandler is called with every decision made by the proxy.
+type DecisionHandler func(decision *Decision)
+
+// Interface is the interface of the L7 proxy used by the NetworkPolicy controller.
+type Interface interface {
+	// AddRule adds a rule or replaces the rule with the same ID.
+	AddRule(rule *Rule)
+	// DeleteRule deletes the rule with the given ID.
+	DeleteRule(ruleID string)
+	// RegisterDecisionHandler registers a handler to receive the decisions made by the proxy.
+	RegisterDecisionHandler(handler DecisionHandler)
+	// Run sets up the redirection on the host and serves the redirected connections
+	// until stopCh is closed.
+	Run(stopCh <-chan struct{})
+}
+
+// httpMatcher is the compiled form of a v1beta2.HTTPProtocol.
+type httpMatcher struct {
+	method     string
+	host       *regexp.Regexp
+	pathPrefix string
+}
+
+func newHTTPMatcher(protocol v1beta2.HTTPProtocol) *httpMatcher {
+	m := &httpMatcher{
+		method:     strings.ToUpper(protocol.Method),
+		pathPrefix: strings.TrimSuffix(protocol.Path, "*"),
+	}
+	if protocol.Host != "" {
+		host := regexp.QuoteMeta(strings.ToLower(protocol.Host))
+		m.host = regexp.MustCompile("^" + strings.ReplaceAll(host, `\*`, ".*") + "$")
+	}
+	return m
+}
+
+func (m *httpMatcher) match(req *http.Request) bool {
+	if m.method != "" && m.method != req.Method {
+		return false
+	}
+	if m.host != nil {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !m.host.MatchString(strings.ToLower(host)) {
+			return false
+		}
+	}
+	return matchPathPrefix(req.URL.Path, m.pathPrefix)
+}
+
+// matchPathPrefix returns whether prefix matches path on a segment boundary, so
+// that "/api" matches "/api" and "/api/v1" but not "/apiary".
+func matchPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
+
+type ruleEntry struct {
+	*Rule
+	matchers []*httpMatcher
+}
+
+func matchIPBlocks(blocks []IPBlock, ip net.IP) bool {
+	for _, block := range blocks {
+		if !block.CIDR.Contains(ip) {
+			continue
+		}
+		excepted := false
+		for _, except := range block.Except {
+			if except.Contains(ip) {
+				excepted = true
+				break
+			}
+		}
+		if !excepted {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPorts(ports []PortRange, port int) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		if int32(port) >= p.Port && int32(port) <= p.EndPort {
+			return true
+		}
+	}
+	return false
+}
+
+// matchConnection returns whether the connection is selected by the rule at L3/L4.
+func (e *ruleEntry) matchConnection(srcIP, dstIP net.IP, dstPort int) bool {
+	return matchIPBlocks(e.From, srcIP) && matchIPBlocks(e.To, dstIP) && matchPorts(e.Ports, dstPort)
+}
+
+func (e *ruleEntry) matchRequest(req *http.Request) bool {
+	for _, m := range e.matchers {
+		if m.match(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleSet stores the rules enforced by the proxy and evaluates requests against them.
+type ruleSet struct {
+	mutex sync.RWMutex
+	rules map[string]*ruleEntry
+}
+
+func newRuleSet() *ruleSet {
+	return &ruleSet{rules: map[string]*ruleEntry{}}
+}
+
+func (s *ruleSet) AddRule(rule *Rule) {
+	entry := &ruleEntry{Rule: rule}
+	for _, protocol := range rule.HTTP {
+		entry.matchers = append(entry.matchers, newHTTPMatcher(protocol))
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rules[rule.ID] = entry
+}
+
+func (s *ruleSet) DeleteRule(ruleID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.rules, ruleID)
+}
+
+// evaluate decides whether the request sent over the connection from srcIP to
+// dstIP:dstPort is allowed. The egress rules of the source and the ingress rules
+// of the destination are evaluated separately and the request must be allowed in
+// both directions which have rules selecting the connection. In each direction,
+// the request is allowed by the first rule in priority order whose HTTP matches
+// select it, and is denied by the highest priority rule otherwise.
+func (s *ruleSet) evaluate(srcIP, dstIP net.IP, dstPort int, req *http.Request) (*Rule, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	candidates := map[v1beta2.Direction][]*ruleEntry{}
+	for _, entry := range s.rules {
+		if entry.matchConnection(srcIP, dstIP, dstPort) {
+			candidates[entry.Direction] = append(candidates[entry.Direction], entry)
+		}
+	}
+	if len(candidates) == 0 {
+		// The connection was redirected but the rule no longer exists.
+		return nil, false
+	}
+	var allowedBy *Rule
+	for _, direction := range []v1beta2.Direction{v1beta2.DirectionOut, v1beta2.DirectionIn} {
+		entries := candidates[direction]
+		if len(entries) == 0 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[j].Priority.Less(entries[i].Priority)
+		})
+		allowed := false
+		for _, entry := range entries {
+			if entry.matchRequest(req) {
+				allowed = true
+				allowedBy = entry.Rule
+				break
+			}
+		}
+		if !allowed {
+			return entries[0].Rule, false
+		}
+	}
+	return allowedBy, true
+}
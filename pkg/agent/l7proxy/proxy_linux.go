@@ -0,0 +1,302 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/agent/util/iptables"
+)
+
+const (
+	// l7ProxyChain is the chain of the mangle table that delivers the packets
+	// redirected by OVS to the proxy.
+	l7ProxyChain = "ANTREA-L7-PROXY"
+	// l7ProxyRouteTable is the routing table used to deliver the redirected
+	// packets locally, as their destination IPs are not local.
+	l7ProxyRouteTable = 167
+	// l7ProxyRulePriority is the priority of the IP rule looking up l7ProxyRouteTable.
+	l7ProxyRulePriority = 100
+)
+
+// connContextKey is the key of the client connection in the context of HTTP requests.
+type connContextKey struct{}
+
+type proxy struct {
+	*ruleSet
+	gatewayName string
+	listenPort  int
+	ipv4Enabled bool
+	ipv6Enabled bool
+	ipt         *iptables.Client
+	server      *http.Server
+
+	handlerMutex sync.RWMutex
+	handlers     []DecisionHandler
+}
+
+// NewL7Proxy returns a proxy which receives the connections redirected to the
+// host gateway gatewayName on listenPort.
+func NewL7Proxy(gatewayName string, listenPort int, ipv4Enabled, ipv6Enabled bool) (*proxy, error) {
+	ipt, err := iptables.New(ipv4Enabled, ipv6Enabled)
+	if err != nil {
+		return nil, fmt.Errorf("error creating iptables client: %v", err)
+	}
+	p := &proxy{
+		ruleSet:     newRuleSet(),
+		gatewayName: gatewayName,
+		listenPort:  listenPort,
+		ipv4Enabled: ipv4Enabled,
+		ipv6Enabled: ipv6Enabled,
+		ipt:         ipt,
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   transparentControl,
+	}
+	reverseProxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			// The connection is accepted by a transparent socket, so its local
+			// address is the original destination of the client.
+			conn := req.Context().Value(connContextKey{}).(net.Conn)
+			req.URL.Scheme = "http"
+			req.URL.Host = conn.LocalAddr().String()
+		},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialFromClient(ctx, dialer, network, address)
+			},
+			// The upstream connections are bound to the IP of the client, so they
+			// cannot be shared by the requests of different clients.
+			DisableKeepAlives: true,
+		},
+	}
+	p.server = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if p.handleRequest(w, req) {
+				reverseProxy.ServeHTTP(w, req)
+			}
+		}),
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			return context.WithValue(ctx, connContextKey{}, conn)
+		},
+	}
+	return p, nil
+}
+
+func (p *proxy) RegisterDecisionHandler(handler DecisionHandler) {
+	p.handlerMutex.Lock()
+	defer p.handlerMutex.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+// handleRequest evaluates the request and replies 403 if it's denied. It
+// returns whether the request should be forwarded to its destination.
+func (p *proxy) handleRequest(w http.ResponseWriter, req *http.Request) bool {
+	conn := req.Context().Value(connContextKey{}).(net.Conn)
+	src := conn.RemoteAddr().(*net.TCPAddr)
+	dst := conn.LocalAddr().(*net.TCPAddr)
+	rule, allowed := p.evaluate(src.IP, dst.IP, dst.Port, req)
+	decision := &Decision{
+		Rule:    rule,
+		Allowed: allowed,
+		SrcIP:   src.IP,
+		DstIP:   dst.IP,
+		DstPort: dst.Port,
+		Method:  req.Method,
+		Host:    req.Host,
+		Path:    req.URL.Path,
+	}
+	p.handlerMutex.RLock()
+	for _, handler := range p.handlers {
+		handler(decision)
+	}
+	p.handlerMutex.RUnlock()
+	if !allowed {
+		http.Error(w, "Forbidden by Antrea NetworkPolicy", http.StatusForbidden)
+	}
+	return allowed
+}
+
+// initialize installs the iptables rules, IP rules and routes which deliver the
+// packets marked with types.L7RedirectMark by OVS to the proxy.
+func (p *proxy) initialize() error {
+	mark := fmt.Sprintf("%#08x/%#08x", types.L7RedirectMark, types.L7RedirectMark)
+	lo, err := netlink.LinkByName("lo")
+	if err != nil {
+		return fmt.Errorf("error getting loopback interface: %v", err)
+	}
+	for _, family := range []struct {
+		enabled   bool
+		protocol  iptables.Protocol
+		family    int
+		listenIP  string
+		allIPsNet string
+	}{
+		{p.ipv4Enabled, iptables.ProtocolIPv4, netlink.FAMILY_V4, "127.0.0.1", "0.0.0.0/0"},
+		{p.ipv6Enabled, iptables.ProtocolIPv6, netlink.FAMILY_V6, "::1", "::/0"},
+	} {
+		if !family.enabled {
+			continue
+		}
+		if err := p.ipt.EnsureChain(family.protocol, iptables.MangleTable, l7ProxyChain); err != nil {
+			return err
+		}
+		if err := p.ipt.AppendRule(family.protocol, iptables.MangleTable, iptables.PreRoutingChain, []string{
+			"-m", "comment", "--comment", `"Antrea: jump to Antrea L7 proxy rules"`,
+			"-j", l7ProxyChain,
+		}); err != nil {
+			return err
+		}
+		// The proxy connects to the destinations from the IPs of the clients, so
+		// the replies are not destined to local IPs. Mark the packets of the
+		// connections of transparent sockets, so that they are delivered locally
+		// too. With noEncap mode, the replies may be received from any interface.
+		if err := p.ipt.AppendRule(family.protocol, iptables.MangleTable, l7ProxyChain, []string{
+			"-p", "tcp", "-m", "socket", "--transparent",
+			"-m", "comment", "--comment", `"Antrea: deliver traffic of Antrea L7 proxy connections locally"`,
+			"-j", "MARK", "--set-mark", mark,
+		}); err != nil {
+			return err
+		}
+		if err := p.ipt.AppendRule(family.protocol, iptables.MangleTable, l7ProxyChain, []string{
+			"-i", p.gatewayName, "-p", "tcp", "-m", "mark", "--mark", mark,
+			"-m", "comment", "--comment", `"Antrea: redirect traffic to Antrea L7 proxy"`,
+			"-j", "TPROXY", "--on-ip", family.listenIP, "--on-port", strconv.Itoa(p.listenPort), "--tproxy-mark", mark,
+		}); err != nil {
+			return err
+		}
+
+		_, dst, _ := net.ParseCIDR(family.allIPsNet)
+		route := &netlink.Route{
+			LinkIndex: lo.Attrs().Index,
+			Dst:       dst,
+			Table:     l7ProxyRouteTable,
+			Type:      unix.RTN_LOCAL,
+			Scope:     netlink.SCOPE_HOST,
+		}
+		if err := netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("error installing route %s in table %d: %v", family.allIPsNet, l7ProxyRouteTable, err)
+		}
+		rule := netlink.NewRule()
+		rule.Family = family.family
+		rule.Priority = l7ProxyRulePriority
+		rule.Mark = int(types.L7RedirectMark)
+		rule.Mask = int(types.L7RedirectMark)
+		rule.Table = l7ProxyRouteTable
+		if err := netlink.RuleAdd(rule); err != nil && !errors.Is(err, unix.EEXIST) {
+			return fmt.Errorf("error installing IP rule for table %d: %v", l7ProxyRouteTable, err)
+		}
+	}
+	return nil
+}
+
+// transparentControl sets IP_TRANSPARENT or IPV6_TRANSPARENT on the socket, so
+// that it can accept connections to, or bind to, an IP which is not local.
+func transparentControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		if network == "tcp6" {
+			sockErr = unix.SetsockoptInt(int(fd), unix.SOL_IPV6, unix.IPV6_TRANSPARENT, 1)
+		} else {
+			sockErr = unix.SetsockoptInt(int(fd), unix.SOL_IP, unix.IP_TRANSPARENT, 1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
+
+// dialFromClient connects to address from the IP of the client which sent the
+// request, so that the destination sees the original source IP. The source port
+// is picked by the kernel, and it must differ from the one of the client, as OVS
+// would otherwise take the new connection for the redirected one.
+func dialFromClient(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	client := ctx.Value(connContextKey{}).(net.Conn).RemoteAddr().(*net.TCPAddr)
+	d := *dialer
+	d.LocalAddr = &net.TCPAddr{IP: client.IP}
+	for {
+		conn, err := d.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		if conn.LocalAddr().(*net.TCPAddr).Port != client.Port {
+			return conn, nil
+		}
+		conn.Close()
+	}
+}
+
+// listen creates a transparent listener, which accepts the connections whose
+// destination IPs are not local.
+func (p *proxy) listen(network, address string) (net.Listener, error) {
+	lc := net.ListenConfig{Control: transparentControl}
+	return lc.Listen(context.TODO(), network, address)
+}
+
+func (p *proxy) Run(stopCh <-chan struct{}) {
+	klog.InfoS("Starting L7 proxy", "port", p.listenPort)
+	if err := p.initialize(); err != nil {
+		klog.ErrorS(err, "Failed to initialize L7 proxy")
+		return
+	}
+	var listeners []net.Listener
+	for _, addr := range []struct {
+		enabled bool
+		network string
+		ip      string
+	}{
+		{p.ipv4Enabled, "tcp4", "127.0.0.1"},
+		{p.ipv6Enabled, "tcp6", "::1"},
+	} {
+		if !addr.enabled {
+			continue
+		}
+		ln, err := p.listen(addr.network, net.JoinHostPort(addr.ip, strconv.Itoa(p.listenPort)))
+		if err != nil {
+			klog.ErrorS(err, "Failed to listen for L7 proxy", "address", addr.ip)
+			for _, l := range listeners {
+				l.Close()
+			}
+			return
+		}
+		listeners = append(listeners, ln)
+	}
+	for _, ln := range listeners {
+		go func(ln net.Listener) {
+			if err := p.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+				klog.ErrorS(err, "L7 proxy stopped serving", "address", ln.Addr())
+			}
+		}(ln)
+	}
+	<-stopCh
+	p.server.Close()
+}
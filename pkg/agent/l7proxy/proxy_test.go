@@ -0,0 +1,275 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
+)
+
+func mustParseIPBlock(cidr string, excepts ...string) IPBlock {
+	_, ipNet, _ := net.ParseCIDR(cidr)
+	block := IPBlock{CIDR: *ipNet}
+	for _, except := range excepts {
+		_, exceptNet, _ := net.ParseCIDR(except)
+		block.Except = append(block.Except, *exceptNet)
+	}
+	return block
+}
+
+func TestEvaluate(t *testing.T) {
+	clientIP := net.ParseIP("10.10.0.1")
+	serverIP := net.ParseIP("10.10.1.1")
+	ingressGetAPI := &Rule{
+		ID:        "ingress-get-api",
+		Direction: v1beta2.DirectionIn,
+		Priority:  types.Priority{TierPriority: 250, PolicyPriority: 5, RulePriority: 1},
+		From:      []IPBlock{mustParseIPBlock("10.10.0.0/24", "10.10.0.128/25")},
+		To:        []IPBlock{mustParseIPBlock("10.10.1.1/32")},
+		Ports:     []PortRange{{Port: 80, EndPort: 80}},
+		HTTP:      []v1beta2.HTTPProtocol{{Method: "GET", Path: "/api/*"}},
+	}
+	ingressPostUpload := &Rule{
+		ID:        "ingress-post-upload",
+		Direction: v1beta2.DirectionIn,
+		Priority:  types.Priority{TierPriority: 250, PolicyPriority: 5, RulePriority: 0},
+		From:      []IPBlock{mustParseIPBlock("10.10.0.0/24")},
+		To:        []IPBlock{mustParseIPBlock("10.10.1.1/32")},
+		HTTP:      []v1beta2.HTTPProtocol{{Method: "POST", Host: "*.example.com", Path: "/upload"}},
+	}
+	ingressGetAPINoWildcard := &Rule{
+		ID:        "ingress-get-api-no-wildcard",
+		Direction: v1beta2.DirectionIn,
+		Priority:  types.Priority{TierPriority: 250, PolicyPriority: 5, RulePriority: 1},
+		From:      []IPBlock{mustParseIPBlock("10.10.0.0/24")},
+		To:        []IPBlock{mustParseIPBlock("10.10.1.1/32")},
+		HTTP:      []v1beta2.HTTPProtocol{{Method: "GET", Path: "/api"}},
+	}
+	egressAll := &Rule{
+		ID:        "egress-all",
+		Direction: v1beta2.DirectionOut,
+		Priority:  types.Priority{TierPriority: 250, PolicyPriority: 1, RulePriority: 0},
+		From:      []IPBlock{mustParseIPBlock("10.10.0.1/32")},
+		To:        []IPBlock{mustParseIPBlock("0.0.0.0/0")},
+		HTTP:      []v1beta2.HTTPProtocol{{}},
+	}
+	egressNoDelete := &Rule{
+		ID:        "egress-no-delete",
+		Direction: v1beta2.DirectionOut,
+		Priority:  types.Priority{TierPriority: 250, PolicyPriority: 1, RulePriority: 1},
+		From:      []IPBlock{mustParseIPBlock("10.10.0.1/32")},
+		To:        []IPBlock{mustParseIPBlock("0.0.0.0/0")},
+		HTTP:      []v1beta2.HTTPProtocol{{Method: "GET"}, {Method: "POST"}},
+	}
+
+	tests := []struct {
+		name          string
+		rules         []*Rule
+		srcIP         net.IP
+		dstPort       int
+		method        string
+		url           string
+		expectedRule  *Rule
+		expectAllowed bool
+	}{
+		{
+			name:          "no rule",
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  nil,
+			expectAllowed: false,
+		},
+		{
+			name:          "allowed by ingress rule",
+			rules:         []*Rule{ingressGetAPI},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  ingressGetAPI,
+			expectAllowed: true,
+		},
+		{
+			name:          "denied by ingress rule with path mismatch",
+			rules:         []*Rule{ingressGetAPI},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/admin",
+			expectedRule:  ingressGetAPI,
+			expectAllowed: false,
+		},
+		{
+			name:          "denied by ingress rule with path sharing the prefix",
+			rules:         []*Rule{ingressGetAPI},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/apiary",
+			expectedRule:  ingressGetAPI,
+			expectAllowed: false,
+		},
+		{
+			name:          "allowed by ingress rule with path equal to the prefix",
+			rules:         []*Rule{ingressGetAPINoWildcard},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api",
+			expectedRule:  ingressGetAPINoWildcard,
+			expectAllowed: true,
+		},
+		{
+			name:          "allowed by ingress rule with path under the prefix",
+			rules:         []*Rule{ingressGetAPINoWildcard},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  ingressGetAPINoWildcard,
+			expectAllowed: true,
+		},
+		{
+			name:          "denied by ingress rule with path extending the last segment",
+			rules:         []*Rule{ingressGetAPINoWildcard},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/apiary",
+			expectedRule:  ingressGetAPINoWildcard,
+			expectAllowed: false,
+		},
+		{
+			name:          "denied by ingress rule with path extending the last segment with a dash",
+			rules:         []*Rule{ingressGetAPINoWildcard},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api-admin",
+			expectedRule:  ingressGetAPINoWildcard,
+			expectAllowed: false,
+		},
+		{
+			name:          "denied by ingress rule with method mismatch",
+			rules:         []*Rule{ingressGetAPI},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodDelete,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  ingressGetAPI,
+			expectAllowed: false,
+		},
+		{
+			name:          "source excepted",
+			rules:         []*Rule{ingressGetAPI},
+			srcIP:         net.ParseIP("10.10.0.200"),
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  nil,
+			expectAllowed: false,
+		},
+		{
+			name:          "port not matched",
+			rules:         []*Rule{ingressGetAPI},
+			srcIP:         clientIP,
+			dstPort:       8080,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1:8080/api/v1",
+			expectedRule:  nil,
+			expectAllowed: false,
+		},
+		{
+			name:          "allowed by higher priority ingress rule with host wildcard",
+			rules:         []*Rule{ingressGetAPI, ingressPostUpload},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodPost,
+			url:           "http://www.Example.com/upload/file",
+			expectedRule:  ingressPostUpload,
+			expectAllowed: true,
+		},
+		{
+			name:          "denied by highest priority ingress rule",
+			rules:         []*Rule{ingressGetAPI, ingressPostUpload},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodPost,
+			url:           "http://example.com/upload",
+			expectedRule:  ingressPostUpload,
+			expectAllowed: false,
+		},
+		{
+			name:          "allowed by both egress and ingress rules",
+			rules:         []*Rule{ingressGetAPI, egressAll},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodGet,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  ingressGetAPI,
+			expectAllowed: true,
+		},
+		{
+			name:          "denied by egress rule",
+			rules:         []*Rule{ingressGetAPI, egressNoDelete},
+			srcIP:         clientIP,
+			dstPort:       80,
+			method:        http.MethodDelete,
+			url:           "http://10.10.1.1/api/v1",
+			expectedRule:  egressNoDelete,
+			expectAllowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRuleSet()
+			for _, rule := range tt.rules {
+				s.AddRule(rule)
+			}
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			rule, allowed := s.evaluate(tt.srcIP, serverIP, tt.dstPort, req)
+			assert.Equal(t, tt.expectedRule, rule)
+			assert.Equal(t, tt.expectAllowed, allowed)
+		})
+	}
+}
+
+func TestDeleteRule(t *testing.T) {
+	s := newRuleSet()
+	s.AddRule(&Rule{
+		ID:        "rule1",
+		Direction: v1beta2.DirectionIn,
+		From:      []IPBlock{mustParseIPBlock("0.0.0.0/0")},
+		To:        []IPBlock{mustParseIPBlock("0.0.0.0/0")},
+		HTTP:      []v1beta2.HTTPProtocol{{}},
+	})
+	req := httptest.NewRequest(http.MethodGet, "http://10.10.1.1/", nil)
+	_, allowed := s.evaluate(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.1.1"), 80, req)
+	assert.True(t, allowed)
+
+	s.DeleteRule("rule1")
+	rule, allowed := s.evaluate(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.1.1"), 80, req)
+	assert.Nil(t, rule)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7proxy
+
+import (
+	"errors"
+)
+
+type proxy struct {
+	*ruleSet
+}
+
+// NewL7Proxy is not supported on Windows.
+func NewL7Proxy(gatewayName string, listenPort int, ipv4Enabled, ipv6Enabled bool) (*proxy, error) {
+	return nil, errors.New("L7 proxy is not supported on Windows")
+}
+
+func (p *proxy) RegisterDecisionHandler(handler DecisionHandler) {}
+
+func (p *proxy) Run(stopCh <-chan struct{}) {}
@@ -75,9 +75,20 @@ var mapNodeEventType = map[memberlist.NodeEventType]nodeEventType{
 	memberlist.NodeUpdate: nodeEventTypeUpdate,
 }
 
-type clusterNodeEventHandler func(objName string)
+// ClusterNodeEventHandler is notified with the name of the ExternalIPPool whose consistent hash ring is updated.
+type ClusterNodeEventHandler func(objName string)
+
+// Interface provides the methods to determine which Node in the cluster owns an IP of an ExternalIPPool.
+type Interface interface {
+	// ShouldSelectIP returns true if the local Node is selected as the owner Node of the IP in the ExternalIPPool.
+	ShouldSelectIP(ip, externalIPPool string) (bool, error)
+	// AddClusterEventHandler adds a handler which will run when the owner Nodes of an ExternalIPPool may change.
+	AddClusterEventHandler(handler ClusterNodeEventHandler)
+}
+
+var _ Interface = (*Cluster)(nil)
 
-// Cluster implements ClusterInterface.
+// Cluster implements Interface.
 type Cluster struct {
 	bindPort int
 	// Name of local Node. Node name must be unique in the cluster.
@@ -97,7 +108,7 @@ type Cluster struct {
 	// For example, when a new Node joins the cluster, each Node should compute whether it should still hold all
 	// its existing Egresses, and when a Node leaves the cluster,
 	// each Node should check whether it is now responsible for some of the Egresses from that Node.
-	clusterNodeEventHandlers []clusterNodeEventHandler
+	clusterNodeEventHandlers []ClusterNodeEventHandler
 
 	nodeInformer     coreinformers.NodeInformer
 	nodeLister       corelisters.NodeLister
@@ -485,8 +496,8 @@ func (c *Cluster) notify(objName string) {
 	}
 }
 
-// AddClusterEventHandler adds a clusterNodeEventHandler, which will run when consistentHashMap is updated,
+// AddClusterEventHandler adds a ClusterNodeEventHandler, which will run when consistentHashMap is updated,
 // due to an ExternalIPPool or Node event.
-func (c *Cluster) AddClusterEventHandler(handler clusterNodeEventHandler) {
+func (c *Cluster) AddClusterEventHandler(handler ClusterNodeEventHandler) {
 	c.clusterNodeEventHandlers = append(c.clusterNodeEventHandlers, handler)
 }
@@ -18,6 +18,8 @@ import (
 	"k8s.io/component-base/metrics"
 	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/util/rulemetrics"
 )
 
 const (
@@ -66,6 +68,49 @@ var (
 		},
 	)
 
+	NetworkPolicyRulePacketCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "networkpolicy_rule_packet_count",
+			Help:           "Number of packets matched by the NetworkPolicy rules on local Node, partitioned by policy and rule. Only the rules selected by the networkPolicyMetrics config option are included. This metric is read from the OVS flow statistics periodically.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		rulemetrics.Labels,
+	)
+
+	NetworkPolicyRuleByteCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "networkpolicy_rule_byte_count",
+			Help:           "Number of bytes matched by the NetworkPolicy rules on local Node, partitioned by policy and rule. Only the rules selected by the networkPolicyMetrics config option are included. This metric is read from the OVS flow statistics periodically.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		rulemetrics.Labels,
+	)
+
+	NetworkPolicyRuleSessionCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "networkpolicy_rule_session_count",
+			Help:           "Number of sessions matched by the NetworkPolicy rules on local Node, partitioned by policy and rule. Only the rules selected by the networkPolicyMetrics config option are included. This metric is read from the OVS flow statistics periodically.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		rulemetrics.Labels,
+	)
+
+	NetworkPolicyRuleMetricsRejectedCount = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "networkpolicy_rule_metrics_rejected_count",
+			Help:           "Number of times the statistics of a NetworkPolicy rule were not exported because the maxRules budget of the networkPolicyMetrics config option was exhausted.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
 	OVSTotalFlowCount = metrics.NewGauge(&metrics.GaugeOpts{
 		Namespace:      metricNamespaceAntrea,
 		Subsystem:      metricSubsystemAgent,
@@ -156,6 +201,28 @@ var (
 		},
 	)
 
+	EgressSNATPacketCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "egress_snat_packet_count",
+			Help:           "Number of egress packets from the local Pods which are SNAT'd by an Egress, partitioned by Egress. This metric is read from the OVS flow statistics periodically.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"egress"},
+	)
+
+	EgressSNATByteCount = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "egress_snat_byte_count",
+			Help:           "Number of bytes of the egress packets from the local Pods which are SNAT'd by an Egress, partitioned by Egress. This metric is read from the OVS flow statistics periodically.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"egress"},
+	)
+
 	MaxConnectionsInConnTrackTable = metrics.NewGauge(
 		&metrics.GaugeOpts{
 			Namespace:      metricNamespaceAntrea,
@@ -174,6 +241,7 @@ func InitializePrometheusMetrics() {
 	InitializeNetworkPolicyMetrics()
 	InitializeOVSMetrics()
 	InitializeConnectionMetrics()
+	InitializeEgressMetrics()
 }
 
 func InitializePodMetrics() {
@@ -194,6 +262,19 @@ func InitializeNetworkPolicyMetrics() {
 	if err := legacyregistry.Register(NetworkPolicyCount); err != nil {
 		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_count")
 	}
+
+	if err := legacyregistry.Register(NetworkPolicyRulePacketCount); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_rule_packet_count")
+	}
+	if err := legacyregistry.Register(NetworkPolicyRuleByteCount); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_rule_byte_count")
+	}
+	if err := legacyregistry.Register(NetworkPolicyRuleSessionCount); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_rule_session_count")
+	}
+	if err := legacyregistry.Register(NetworkPolicyRuleMetricsRejectedCount); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_rule_metrics_rejected_count")
+	}
 }
 
 func InitializeOVSMetrics() {
@@ -240,3 +321,12 @@ func InitializeConnectionMetrics() {
 		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_conntrack_max_connection_count")
 	}
 }
+
+func InitializeEgressMetrics() {
+	if err := legacyregistry.Register(EgressSNATPacketCount); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_egress_snat_packet_count")
+	}
+	if err := legacyregistry.Register(EgressSNATByteCount); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_egress_snat_byte_count")
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
 // InitializeNPLAgent starts NodePortLocal (NPL) agent.
@@ -29,6 +30,7 @@ import (
 func InitializeNPLAgent(
 	kubeClient clientset.Interface,
 	informerFactory informers.SharedInformerFactory,
+	podInformer cache.SharedIndexInformer,
 	startPort int,
 	endPort int,
 	nodeName string,
@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"strconv"
+	"strings"
 
 	"antrea.io/libOpenflow/protocol"
 	v1 "k8s.io/api/core/v1"
@@ -29,6 +31,7 @@ import (
 	"antrea.io/antrea/pkg/agent/util"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	utilip "antrea.io/antrea/pkg/util/ip"
+	"antrea.io/antrea/pkg/util/runtime"
 	"antrea.io/antrea/third_party/proxy"
 )
 
@@ -90,10 +93,24 @@ type Client interface {
 	UninstallPodFlows(interfaceName string) error
 
 	// InstallServiceGroup installs a group for Service LB. Each endpoint
-	// is a bucket of the group. For now, each bucket has the same weight.
-	InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) error
+	// is a bucket of the group. The buckets of the ready endpoints have the
+	// same weight, or weights proportional to the endpoint weights with the
+	// Weighted lbMode. The buckets of the terminating endpoints have weight
+	// 0, unless there is no ready endpoint, in which case the serving
+	// terminating endpoints are used as fallback endpoints. With the
+	// ConsistentHash and SourceIPHash lbModes, the endpoints receiving new
+	// connections are selected with Maglev lookup tables instead.
+	InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, lbMode types.ServiceLBMode, endpoints []proxy.Endpoint) error
+	// InstallServiceGroupWithSubGroups installs a group for Service LB
+	// whose endpoints don't fit in a single group, as given by
+	// ServiceSubGroupCount. The endpoints are split across the sub-groups,
+	// and each sub-group is a bucket of the group, whose weight is
+	// proportional to the total weight of the endpoints of the sub-group
+	// receiving new connections. The sub-groups are removed with
+	// UninstallServiceGroup, after the group stops using them.
+	InstallServiceGroupWithSubGroups(groupID binding.GroupIDType, subGroupIDs []binding.GroupIDType, withSessionAffinity bool, lbMode types.ServiceLBMode, endpoints []proxy.Endpoint) error
 	// UninstallServiceGroup removes the group and its buckets that are
-	// installed by InstallServiceGroup.
+	// installed by InstallServiceGroup, and the flows of its lbMode.
 	UninstallServiceGroup(groupID binding.GroupIDType) error
 
 	// InstallEndpointFlows installs flows for accessing Endpoints.
@@ -119,6 +136,14 @@ type Client interface {
 	InstallLoadBalancerServiceFromOutsideFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
 	// UninstallLoadBalancerServiceFromOutsideFlows removes flows installed by InstallLoadBalancerServiceFromOutsideFlows.
 	UninstallLoadBalancerServiceFromOutsideFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// InstallLoadBalancerServiceDSRFlows installs flows for the LoadBalancer IP of a Service in DSR mode. The requests
+	// from outside the cluster are load balanced with the group with groupID, and the requests to remote Endpoints are
+	// forwarded to their Nodes with the LoadBalancer IP preserved. The requests forwarded by other Nodes are load
+	// balanced with the group with localGroupID, whose Endpoints are local. Both groups must be installed before.
+	// The flows take precedence over the flows installed by InstallServiceFlows for the same LoadBalancer IP.
+	InstallLoadBalancerServiceDSRFlows(groupID, localGroupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// UninstallLoadBalancerServiceDSRFlows removes flows installed by InstallLoadBalancerServiceDSRFlows.
+	UninstallLoadBalancerServiceDSRFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
 
 	// GetFlowTableStatus should return an array of flow table status, all existing flow tables should be included in the list.
 	GetFlowTableStatus() []binding.TableStatus
@@ -167,6 +192,18 @@ type Client interface {
 	// mark for a SNAT IP.
 	UninstallSNATMarkFlows(mark uint32) error
 
+	// InstallSNATMeter installs or updates the meter entry which limits the
+	// bandwidth of the egress traffic SNAT'd with the local SNAT IP of the
+	// mark. rate is in kbps and burst is in kbits. The SNAT flows for the
+	// mark, including the SNAT flows of the local Pods using the mark, which
+	// are installed after the meter entry, send the traffic to the meter.
+	// If OVS meters are not supported, the traffic is not limited.
+	InstallSNATMeter(mark uint32, rate, burst uint32) error
+
+	// UninstallSNATMeter removes the meter entry installed for the SNAT IP
+	// mark. The SNAT flows using the meter should be uninstalled first.
+	UninstallSNATMeter(mark uint32) error
+
 	// InstallPodSNATFlows installs the SNAT flows for a local Pod. If the
 	// SNAT IP for the Pod is on the local Node, a non-zero SNAT ID should
 	// allocated for the SNAT IP, and the installed flow sets the SNAT IP
@@ -228,12 +265,28 @@ type Client interface {
 	// SendTraceflowPacket injects packet to specified OVS port for Openflow.
 	SendTraceflowPacket(dataplaneTag uint8, packet *binding.Packet, inPort uint32, outPort int32) error
 
-	// InstallTraceflowFlows installs flows for a Traceflow request.
-	InstallTraceflowFlows(dataplaneTag uint8, liveTraffic, droppedOnly, receiverOnly bool, packet *binding.Packet, ofPort uint32, timeoutSeconds uint16) error
+	// InstallTraceflowFlows installs flows for a Traceflow request. When
+	// multiHop is true, Traceflow packets leaving the overlay through the
+	// gateway are output with the dataplane tag kept, so that the Nodes they
+	// reach afterwards can keep tracing them.
+	InstallTraceflowFlows(dataplaneTag uint8, liveTraffic, droppedOnly, receiverOnly, multiHop bool, packet *binding.Packet, ofPort uint32, timeoutSeconds uint16) error
 
 	// UninstallTraceflowFlows uninstalls flows for a Traceflow request.
 	UninstallTraceflowFlows(dataplaneTag uint8) error
 
+	// InstallPacketCaptureFlows installs flows to send a copy of the packets
+	// matching the provided packet to the Antrea Agent for a PacketCapture
+	// request. The packets are still forwarded normally.
+	InstallPacketCaptureFlows(name string, packet *binding.Packet, timeoutSeconds uint16) error
+
+	// UninstallPacketCaptureFlows uninstalls flows for a PacketCapture request.
+	UninstallPacketCaptureFlows(name string) error
+
+	// InstallTCPMetricsFlows installs flows to send a copy of the SYN, SYN-ACK and
+	// RST packets of TCP connections to the Antrea Agent, which uses them to compute
+	// TCP metrics for the Flow Exporter. The packets are still forwarded normally.
+	InstallTCPMetricsFlows() error
+
 	// Initial tun_metadata0 in TLV map for Traceflow.
 	InitialTLVMap() error
 
@@ -247,6 +300,9 @@ type Client interface {
 	StartPacketInHandler(packetInStartedReason []uint8, stopCh <-chan struct{})
 	// Get traffic metrics of each NetworkPolicy rule.
 	NetworkPolicyMetrics() map[uint32]*types.RuleMetric
+	// Get traffic metrics of the egress packets SNAT'd for each local Pod
+	// with SNAT flows, keyed by the ofPort of the Pod.
+	PodSNATMetrics() map[uint32]*types.SNATMetric
 	// Returns if IPv4 is supported on this Node or not.
 	IsIPv4Enabled() bool
 	// Returns if IPv6 is supported on this Node or not.
@@ -444,6 +500,11 @@ func (c *client) InstallNodeFlows(hostname string,
 		} else {
 			flows = append(flows, c.l3FwdFlowToRemoteViaRouting(localGatewayMAC, remoteGatewayMAC, cookie.Node, tunnelPeerIP, peerPodCIDR)...)
 		}
+		if c.enableDSR && !isIPv6 {
+			// The LoadBalancer traffic to the Endpoints of the peer Node is always forwarded through the tunnel in
+			// DSR mode, as the LoadBalancer IP is preserved.
+			flows = append(flows, c.serviceDSRForwardingFlows(localGatewayMAC, *peerPodCIDR, tunnelPeerIP, cookie.Node)...)
+		}
 		if c.enableEgress {
 			flows = append(flows, c.snatSkipNodeFlow(tunnelPeerIP, cookie.Node))
 		}
@@ -537,18 +598,54 @@ func (c *client) GetPodFlowKeys(interfaceName string) []string {
 	return c.getFlowKeysFromCache(c.podFlowCache, interfaceName)
 }
 
-func (c *client) InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) error {
+func (c *client) InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, lbMode types.ServiceLBMode, endpoints []proxy.Endpoint) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
 
-	group := c.serviceEndpointGroup(groupID, withSessionAffinity, endpoints...)
+	var flows []binding.Flow
+	cacheKey := generateServiceGroupFlowCacheKey(groupID)
+	if lbMode == types.ServiceLBModeSourceIPHash {
+		flows = c.serviceSourceIPHashFlows(groupID, withSessionAffinity, endpoints)
+	}
+	// The flows must exist before the group resubmits packets to them.
+	if len(flows) > 0 {
+		if err := c.modifyFlows(c.serviceFlowCache, cacheKey, flows); err != nil {
+			return fmt.Errorf("error when installing Service Endpoints selection flows: %w", err)
+		}
+	}
+	group := c.serviceEndpointGroup(groupID, withSessionAffinity, lbMode, endpoints...)
 	if err := group.Add(); err != nil {
 		return fmt.Errorf("error when installing Service Endpoints Group: %w", err)
 	}
+	c.chainedGroupCache.Delete(groupID)
 	c.groupCache.Store(groupID, group)
+	if len(flows) == 0 {
+		return c.deleteFlows(c.serviceFlowCache, cacheKey)
+	}
 	return nil
 }
 
+func (c *client) InstallServiceGroupWithSubGroups(groupID binding.GroupIDType, subGroupIDs []binding.GroupIDType, withSessionAffinity bool, lbMode types.ServiceLBMode, endpoints []proxy.Endpoint) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	group, subGroups := c.serviceEndpointGroupWithSubGroups(groupID, subGroupIDs, withSessionAffinity, lbMode, endpoints)
+	// The sub-groups must exist before the group refers to them.
+	for i, subGroup := range subGroups {
+		if err := subGroup.Add(); err != nil {
+			return fmt.Errorf("error when installing Service Endpoints sub-group: %w", err)
+		}
+		c.chainedGroupCache.Delete(subGroupIDs[i])
+		c.groupCache.Store(subGroupIDs[i], subGroup)
+	}
+	if err := group.Add(); err != nil {
+		return fmt.Errorf("error when installing Service Endpoints Group: %w", err)
+	}
+	c.groupCache.Delete(groupID)
+	c.chainedGroupCache.Store(groupID, group)
+	return c.deleteFlows(c.serviceFlowCache, generateServiceGroupFlowCacheKey(groupID))
+}
+
 func (c *client) UninstallServiceGroup(groupID binding.GroupIDType) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -556,17 +653,26 @@ func (c *client) UninstallServiceGroup(groupID binding.GroupIDType) error {
 		return fmt.Errorf("group %d delete failed", groupID)
 	}
 	c.groupCache.Delete(groupID)
-	return nil
+	c.chainedGroupCache.Delete(groupID)
+	return c.deleteFlows(c.serviceFlowCache, generateServiceGroupFlowCacheKey(groupID))
 }
 
 func generateEndpointFlowCacheKey(endpointIP string, endpointPort int, protocol binding.Protocol) string {
 	return fmt.Sprintf("E%s%s%x", endpointIP, protocol, endpointPort)
 }
 
+func generateServiceGroupFlowCacheKey(groupID binding.GroupIDType) string {
+	return fmt.Sprintf("G%x", groupID)
+}
+
 func generateServicePortFlowCacheKey(svcIP net.IP, svcPort uint16, protocol binding.Protocol) string {
 	return fmt.Sprintf("S%s%s%x", svcIP, protocol, svcPort)
 }
 
+func generateServiceDSRFlowCacheKey(svcIP net.IP, svcPort uint16, protocol binding.Protocol) string {
+	return fmt.Sprintf("D%s%s%x", svcIP, protocol, svcPort)
+}
+
 func (c *client) InstallEndpointFlows(protocol binding.Protocol, endpoints []proxy.Endpoint) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -620,6 +726,21 @@ func (c *client) UninstallServiceFlows(svcIP net.IP, svcPort uint16, protocol bi
 	return c.deleteFlows(c.serviceFlowCache, cacheKey)
 }
 
+func (c *client) InstallLoadBalancerServiceDSRFlows(groupID, localGroupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	flows := c.serviceLoadBalancerDSRFlows(groupID, localGroupID, svcIP, svcPort, protocol)
+	cacheKey := generateServiceDSRFlowCacheKey(svcIP, svcPort, protocol)
+	return c.addFlows(c.serviceFlowCache, cacheKey, flows)
+}
+
+func (c *client) UninstallLoadBalancerServiceDSRFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	cacheKey := generateServiceDSRFlowCacheKey(svcIP, svcPort, protocol)
+	return c.deleteFlows(c.serviceFlowCache, cacheKey)
+}
+
 func (c *client) GetServiceFlowKeys(svcIP net.IP, svcPort uint16, protocol binding.Protocol, endpoints []proxy.Endpoint) []string {
 	cacheKey := generateServicePortFlowCacheKey(svcIP, svcPort, protocol)
 	flowKeys := c.getFlowKeysFromCache(c.serviceFlowCache, cacheKey)
@@ -700,6 +821,11 @@ func (c *client) InstallGatewayFlows() error {
 	// Add flow to ensure the liveness check packet could be forwarded correctly.
 	flows = append(flows, c.localProbeFlow(gatewayIPs, cookie.Default)...)
 	flows = append(flows, c.l3FwdFlowToGateway(gatewayIPs, gatewayConfig.MAC, cookie.Default)...)
+	// Add flows to redirect the traffic allowed by rules with L7 protocols to the L7 proxy, which is only supported
+	// on Linux.
+	if c.enableAntreaPolicy && !runtime.IsWindowsPlatform() {
+		flows = append(flows, c.l7RedirectFlows(gatewayConfig.MAC, cookie.Policy)...)
+	}
 
 	if err := c.ofEntryOperations.AddAll(flows); err != nil {
 		return err
@@ -751,6 +877,11 @@ func (c *client) initialize() error {
 	if err := c.ofEntryOperations.AddAll(c.rejectBypassNetworkpolicyFlows(cookie.Default)); err != nil {
 		return fmt.Errorf("failed to install flows to skip generated reject responses: %v", err)
 	}
+	if c.enableAntreaPolicy {
+		if err := c.ofEntryOperations.Add(c.auditLoggingFlow(cookie.Default)); err != nil {
+			return fmt.Errorf("failed to install flow to log the packets of the rules in audit mode: %v", err)
+		}
+	}
 	if c.networkConfig.TrafficEncapMode.IsNetworkPolicyOnly() {
 		if err := c.setupPolicyOnlyFlows(); err != nil {
 			return fmt.Errorf("failed to setup policy only flows: %w", err)
@@ -763,6 +894,12 @@ func (c *client) initialize() error {
 		if err := c.genPacketInMeter(PacketInMeterIDTF, PacketInMeterRateTF).Add(); err != nil {
 			return fmt.Errorf("failed to install OpenFlow meter entry (meterID:%d, rate:%d) for TraceFlow packet-in rate limiting: %v", PacketInMeterIDTF, PacketInMeterRateTF, err)
 		}
+		if err := c.genPacketInMeter(PacketInMeterIDPC, PacketInMeterRatePC).Add(); err != nil {
+			return fmt.Errorf("failed to install OpenFlow meter entry (meterID:%d, rate:%d) for PacketCapture packet-in rate limiting: %v", PacketInMeterIDPC, PacketInMeterRatePC, err)
+		}
+		if err := c.genPacketInMeter(PacketInMeterIDFE, PacketInMeterRateFE).Add(); err != nil {
+			return fmt.Errorf("failed to install OpenFlow meter entry (meterID:%d, rate:%d) for Flow Exporter packet-in rate limiting: %v", PacketInMeterIDFE, PacketInMeterRateFE, err)
+		}
 	}
 	return nil
 }
@@ -840,6 +977,9 @@ func (c *client) InstallExternalFlows(exceptCIDRs []net.IPNet) error {
 
 func (c *client) InstallSNATMarkFlows(snatIP net.IP, mark uint32) error {
 	flows := c.snatMarkFlows(snatIP, mark)
+	if meterID, ok := c.getSNATMeterID(mark); ok {
+		flows = append(flows, c.snatMeterFromTunnelFlow(snatIP, meterID))
+	}
 	cacheKey := fmt.Sprintf("s%x", mark)
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -855,6 +995,9 @@ func (c *client) UninstallSNATMarkFlows(mark uint32) error {
 
 func (c *client) InstallPodSNATFlows(ofPort uint32, snatIP net.IP, snatMark uint32) error {
 	flows := []binding.Flow{c.snatRuleFlow(ofPort, snatIP, snatMark, c.nodeConfig.GatewayConfig.MAC)}
+	if snatMark != 0 {
+		flows = append(flows, c.snatEstablishedFromPodFlow(ofPort, snatIP, snatMark))
+	}
 	cacheKey := fmt.Sprintf("p%x", ofPort)
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -868,6 +1011,69 @@ func (c *client) UninstallPodSNATFlows(ofPort uint32) error {
 	return c.deleteFlows(c.snatFlowCache, cacheKey)
 }
 
+func (c *client) PodSNATMetrics() map[uint32]*types.SNATMetric {
+	result := map[uint32]*types.SNATMetric{}
+	flows, _ := c.ovsctlClient.DumpTableFlows(SNATTable.GetID())
+	for _, flow := range flows {
+		// Only the match fields are parsed, as some actions may contain "=".
+		// example Pod SNAT flow format:
+		// table=71, n_packets=7, n_bytes=590, priority=200,ct_state=+new+trk,ip,in_port=3 actions=load:0x1->NXM_NX_PKT_MARK[0..7],goto_table:80
+		flowMap := parseFlowToMap(strings.Split(flow, " actions=")[0])
+		inPort, ok := flowMap["in_port"]
+		if !ok {
+			continue
+		}
+		ofPort, err := strconv.ParseUint(inPort, 10, 32)
+		if err != nil {
+			continue
+		}
+		metric := types.SNATMetric{}
+		metric.Packets, _ = strconv.ParseUint(flowMap["n_packets"], 10, 64)
+		metric.Bytes, _ = strconv.ParseUint(flowMap["n_bytes"], 10, 64)
+		// For the Pods using a local SNAT IP, the statistics of the flows
+		// matching the new and the established connections are merged.
+		if accMetric, ok := result[uint32(ofPort)]; ok {
+			accMetric.Merge(&metric)
+		} else {
+			result[uint32(ofPort)] = &metric
+		}
+	}
+	return result
+}
+
+func (c *client) InstallSNATMeter(mark uint32, rate, burst uint32) error {
+	if !c.ovsMetersAreSupported {
+		klog.InfoS("OVS meters are not supported, the bandwidth of the SNAT IP will not be limited", "mark", mark)
+		return nil
+	}
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	_, exists := c.snatMeterCache.Load(mark)
+	meter := c.genSNATMeter(mark, rate, burst)
+	if exists {
+		if err := meter.Modify(); err != nil {
+			return fmt.Errorf("failed to update OpenFlow meter entry (meterID:%d, rate:%d) for SNAT IP mark %d: %v", snatMeterID(mark), rate, mark, err)
+		}
+	} else if err := meter.Add(); err != nil {
+		return fmt.Errorf("failed to install OpenFlow meter entry (meterID:%d, rate:%d) for SNAT IP mark %d: %v", snatMeterID(mark), rate, mark, err)
+	}
+	c.snatMeterCache.Store(mark, meter)
+	return nil
+}
+
+func (c *client) UninstallSNATMeter(mark uint32) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	if _, exists := c.snatMeterCache.Load(mark); !exists {
+		return nil
+	}
+	if !c.bridge.DeleteMeter(snatMeterID(mark)) {
+		return fmt.Errorf("failed to delete OpenFlow meter entry (meterID:%d) for SNAT IP mark %d", snatMeterID(mark), mark)
+	}
+	c.snatMeterCache.Delete(mark)
+	return nil
+}
+
 func (c *client) ReplayFlows() {
 	c.replayMutex.Lock()
 	defer c.replayMutex.Unlock()
@@ -889,6 +1095,7 @@ func (c *client) ReplayFlows() {
 	addFixedFlows(c.gatewayFlows)
 	addFixedFlows(c.defaultServiceFlows)
 	addFixedFlows(c.defaultTunnelFlows)
+	addFixedFlows(c.tcpMetricsFlows)
 	// hostNetworkingFlows is used only on Windows. Replay the flows only when there are flows in this cache.
 	if len(c.hostNetworkingFlows) > 0 {
 		addFixedFlows(c.hostNetworkingFlows)
@@ -909,13 +1116,24 @@ func (c *client) ReplayFlows() {
 		return true
 	}
 
-	c.groupCache.Range(func(id, value interface{}) bool {
+	replayGroup := func(id, value interface{}) bool {
 		group := value.(binding.Group)
 		group.Reset()
 		if err := group.Add(); err != nil {
 			klog.Errorf("Error when replaying cached group %d: %v", id, err)
 		}
 		return true
+	}
+	c.groupCache.Range(replayGroup)
+	// The groups referring to other groups are replayed after them.
+	c.chainedGroupCache.Range(replayGroup)
+	c.snatMeterCache.Range(func(mark, value interface{}) bool {
+		meter := value.(binding.Meter)
+		meter.Reset()
+		if err := meter.Add(); err != nil {
+			klog.Errorf("Error when replaying cached meter for SNAT IP mark %d: %v", mark, err)
+		}
+		return true
 	})
 	c.nodeFlowCache.Range(installCachedFlows)
 	c.podFlowCache.Range(installCachedFlows)
@@ -1018,11 +1236,11 @@ func (c *client) SendTraceflowPacket(dataplaneTag uint8, packet *binding.Packet,
 	return c.bridge.SendPacketOut(packetOutObj)
 }
 
-func (c *client) InstallTraceflowFlows(dataplaneTag uint8, liveTraffic, droppedOnly, receiverOnly bool, packet *binding.Packet, ofPort uint32, timeoutSeconds uint16) error {
+func (c *client) InstallTraceflowFlows(dataplaneTag uint8, liveTraffic, droppedOnly, receiverOnly, multiHop bool, packet *binding.Packet, ofPort uint32, timeoutSeconds uint16) error {
 	cacheKey := fmt.Sprintf("%x", dataplaneTag)
 	flows := []binding.Flow{}
 	flows = append(flows, c.traceflowConnectionTrackFlows(dataplaneTag, receiverOnly, packet, ofPort, timeoutSeconds, cookie.Default)...)
-	flows = append(flows, c.traceflowL2ForwardOutputFlows(dataplaneTag, liveTraffic, droppedOnly, timeoutSeconds, cookie.Default)...)
+	flows = append(flows, c.traceflowL2ForwardOutputFlows(dataplaneTag, liveTraffic, droppedOnly, multiHop, timeoutSeconds, cookie.Default)...)
 	flows = append(flows, c.traceflowNetworkPolicyFlows(dataplaneTag, timeoutSeconds, cookie.Default)...)
 	return c.addFlows(c.tfFlowCache, cacheKey, flows)
 }
@@ -1032,6 +1250,24 @@ func (c *client) UninstallTraceflowFlows(dataplaneTag uint8) error {
 	return c.deleteFlows(c.tfFlowCache, cacheKey)
 }
 
+func (c *client) InstallPacketCaptureFlows(name string, packet *binding.Packet, timeoutSeconds uint16) error {
+	flows := c.packetCaptureFlows(packet, timeoutSeconds, cookie.Default)
+	return c.addFlows(c.pcFlowCache, name, flows)
+}
+
+func (c *client) UninstallPacketCaptureFlows(name string) error {
+	return c.deleteFlows(c.pcFlowCache, name)
+}
+
+func (c *client) InstallTCPMetricsFlows() error {
+	flows := c.tcpMetricsPacketInFlows(cookie.Default)
+	if err := c.ofEntryOperations.AddAll(flows); err != nil {
+		return err
+	}
+	c.tcpMetricsFlows = flows
+	return nil
+}
+
 // Add TLV map optClass 0x0104, optType 0x80 optLength 4 tunMetadataIndex 0 to store data plane tag
 // in tunnel. Data plane tag will be stored to NXM_NX_TUN_METADATA0[28..31] when packet get encapsulated
 // into geneve, and will be stored back to NXM_NX_REG9[28..31] when packet get decapsulated.
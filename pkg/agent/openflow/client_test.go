@@ -31,10 +31,13 @@ import (
 	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/openflow/cookie"
 	oftest "antrea.io/antrea/pkg/agent/openflow/testing"
+	"antrea.io/antrea/pkg/agent/types"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	ovsoftest "antrea.io/antrea/pkg/ovs/openflow/testing"
 	"antrea.io/antrea/pkg/ovs/ovsconfig"
+	ovsctltest "antrea.io/antrea/pkg/ovs/ovsctl/testing"
 	utilip "antrea.io/antrea/pkg/util/ip"
+	"antrea.io/antrea/third_party/proxy"
 )
 
 const bridgeName = "dummy-br"
@@ -103,7 +106,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -132,7 +135,7 @@ func TestIdempotentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -174,7 +177,7 @@ func TestFlowInstallationFailed(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -209,7 +212,7 @@ func TestConcurrentFlowInstallation(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			m := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, false, false, false, false, false, false)
 			client := ofClient.(*client)
 			client.cookieAllocator = cookie.NewAllocator(0)
 			client.ofEntryOperations = m
@@ -286,7 +289,7 @@ func Test_client_InstallTraceflowFlows(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			c := tt.prepareFunc(ctrl)
-			if err := c.InstallTraceflowFlows(tt.args.dataplaneTag, false, false, false, nil, 0, 300); (err != nil) != tt.wantErr {
+			if err := c.InstallTraceflowFlows(tt.args.dataplaneTag, false, false, false, false, nil, 0, 300); (err != nil) != tt.wantErr {
 				t.Errorf("InstallTraceflowFlows() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -400,7 +403,7 @@ func Test_client_SendTraceflowPacket(t *testing.T) {
 }
 
 func prepareTraceflowFlow(ctrl *gomock.Controller) *client {
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, true, false, false, false, false)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, true, false, false, false, false, false)
 	c := ofClient.(*client)
 	c.cookieAllocator = cookie.NewAllocator(0)
 	c.nodeConfig = nodeConfig
@@ -418,7 +421,7 @@ func prepareTraceflowFlow(ctrl *gomock.Controller) *client {
 }
 
 func prepareSendTraceflowPacket(ctrl *gomock.Controller, success bool) *client {
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, true, false, false, false, false)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, true, false, false, false, false, false)
 	c := ofClient.(*client)
 	c.nodeConfig = nodeConfig
 	m := ovsoftest.NewMockBridge(ctrl)
@@ -506,7 +509,7 @@ func Test_client_setBasePacketOutBuilder(t *testing.T) {
 }
 
 func prepareSetBasePacketOutBuilder(ctrl *gomock.Controller, success bool) *client {
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, true, false, false, false, false)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, true, true, false, false, false, false, false)
 	c := ofClient.(*client)
 	m := ovsoftest.NewMockBridge(ctrl)
 	c.bridge = m
@@ -517,3 +520,121 @@ func prepareSetBasePacketOutBuilder(ctrl *gomock.Controller, success bool) *clie
 	}
 	return c
 }
+
+func Test_client_PodSNATMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOVSClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	c := &client{ovsctlClient: mockOVSClient}
+	mockOVSClient.EXPECT().DumpTableFlows(SNATTable.GetID()).Return([]string{
+		"table=71, n_packets=2, n_bytes=148, priority=200,ct_state=+new+trk,ip,in_port=3 actions=load:0x1->NXM_NX_PKT_MARK[0..7],goto_table:80",
+		"table=71, n_packets=10, n_bytes=1500, priority=200,ct_state=-new+trk,ip,in_port=3 actions=goto_table:80",
+		"table=71, n_packets=5, n_bytes=420, priority=200,ip,in_port=4 actions=mod_dl_src:aa:bb:cc:dd:ee:ee,mod_dl_dst:aa:bb:cc:dd:ee:ff,load:0xac100a01->NXM_NX_TUN_IPV4_DST[],goto_table:72",
+		"table=71, n_packets=8, n_bytes=640, priority=200,ct_state=+new+trk,ip,tun_dst=1.1.1.1 actions=load:0x1->NXM_NX_PKT_MARK[0..7],goto_table:80",
+		"table=71, n_packets=100, n_bytes=10000, priority=0 actions=goto_table:80",
+	}, nil)
+	assert.Equal(t, map[uint32]*types.SNATMetric{
+		3: {Bytes: 1648, Packets: 12},
+		4: {Bytes: 420, Packets: 5},
+	}, c.PodSNATMetrics())
+}
+
+func TestServiceEndpointBucketWeights(t *testing.T) {
+	ready := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.1:80", Ready: true, Serving: true}
+	servingTerminating := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.2:80", Serving: true, Terminating: true}
+	terminating := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.3:80", Terminating: true}
+	weightedReady := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.4:80", Ready: true, Serving: true, Weight: 3}
+	tests := []struct {
+		name            string
+		endpoints       []proxy.Endpoint
+		weighted        bool
+		expectedWeights []uint16
+	}{
+		{
+			name:            "ready and terminating Endpoints",
+			endpoints:       []proxy.Endpoint{ready, servingTerminating, terminating},
+			expectedWeights: []uint16{100, 0, 0},
+		},
+		{
+			name:            "fallback to serving terminating Endpoints",
+			endpoints:       []proxy.Endpoint{servingTerminating, terminating},
+			expectedWeights: []uint16{100, 0},
+		},
+		{
+			name:            "no serving Endpoint",
+			endpoints:       []proxy.Endpoint{terminating},
+			expectedWeights: []uint16{0},
+		},
+		{
+			name:            "weights of Endpoints ignored",
+			endpoints:       []proxy.Endpoint{ready, weightedReady},
+			expectedWeights: []uint16{100, 100},
+		},
+		{
+			name:            "weighted Endpoints",
+			endpoints:       []proxy.Endpoint{ready, weightedReady, terminating},
+			weighted:        true,
+			expectedWeights: []uint16{100, 300, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedWeights, serviceEndpointBucketWeights(tt.endpoints, tt.weighted))
+		})
+	}
+}
+
+func TestServiceLookupTableEndpoints(t *testing.T) {
+	ready1 := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.1:80", Ready: true, Serving: true}
+	ready2 := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.2:80", Ready: true, Serving: true}
+	terminating := &proxy.BaseEndpointInfo{Endpoint: "10.10.0.3:80", Serving: true, Terminating: true}
+
+	tableEndpoints := serviceLookupTableEndpoints([]proxy.Endpoint{ready1, terminating, ready2}, consistentHashTableSize)
+	assert.Len(t, tableEndpoints, consistentHashTableSize)
+	counts := map[proxy.Endpoint]int{}
+	for _, endpoint := range tableEndpoints {
+		counts[endpoint]++
+	}
+	// The terminating Endpoint doesn't receive new connections as there are ready Endpoints.
+	assert.Equal(t, consistentHashTableSize, counts[ready1]+counts[ready2])
+	assert.InDelta(t, counts[ready1], counts[ready2], 1)
+
+	assert.Nil(t, serviceLookupTableEndpoints([]proxy.Endpoint{&proxy.BaseEndpointInfo{Endpoint: "10.10.0.4:80", Terminating: true}}, sourceIPHashTableSize))
+}
+
+func TestServiceSubGroupCount(t *testing.T) {
+	for endpointCount, expectedCount := range map[int]int{
+		10:                  0,
+		800:                 0,
+		801:                 2,
+		5000:                7,
+		MaxServiceEndpoints: 400,
+	} {
+		subGroupCount := ServiceSubGroupCount(endpointCount, types.ServiceLBModeDefault)
+		assert.Equal(t, expectedCount, subGroupCount, "Unexpected sub-group count for %d Endpoints", endpointCount)
+		if subGroupCount > 0 {
+			// Each sub-group has a placeholder bucket per sub-group.
+			assert.LessOrEqual(t, subGroupCount+(endpointCount+subGroupCount-1)/subGroupCount, maxServiceGroupBuckets)
+		}
+	}
+	// The groups of the lookup table modes never have sub-groups.
+	assert.Equal(t, 0, ServiceSubGroupCount(5000, types.ServiceLBModeConsistentHash))
+	assert.Equal(t, 0, ServiceSubGroupCount(5000, types.ServiceLBModeSourceIPHash))
+	assert.Equal(t, 7, ServiceSubGroupCount(5000, types.ServiceLBModeWeighted))
+}
+
+func TestServiceMaxEndpoints(t *testing.T) {
+	assert.Equal(t, MaxServiceEndpoints, ServiceMaxEndpoints(types.ServiceLBModeDefault))
+	assert.Equal(t, MaxServiceEndpoints, ServiceMaxEndpoints(types.ServiceLBModeWeighted))
+	// Each Endpoint of the lookup table modes needs an entry in the lookup table.
+	assert.Equal(t, consistentHashTableSize, ServiceMaxEndpoints(types.ServiceLBModeConsistentHash))
+	assert.Equal(t, sourceIPHashTableSize, ServiceMaxEndpoints(types.ServiceLBModeSourceIPHash))
+	endpoints := make([]proxy.Endpoint, sourceIPHashTableSize)
+	for i := range endpoints {
+		endpoints[i] = &proxy.BaseEndpointInfo{Endpoint: fmt.Sprintf("10.10.%d.%d:80", i/256, i%256), Ready: true, Serving: true}
+	}
+	tableEndpoints := serviceLookupTableEndpoints(endpoints, sourceIPHashTableSize)
+	for _, endpoint := range endpoints {
+		assert.Contains(t, tableEndpoints, endpoint)
+	}
+}
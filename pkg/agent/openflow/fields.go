@@ -36,6 +36,9 @@ var (
 	FromBridgeRegMark  = binding.NewRegMark(PktSourceField, 5)
 	// reg0[16]: Mark to indicate the ofPort number of an interface is found.
 	OFPortFoundRegMark = binding.NewOneBitRegMark(0, 16, "OFPortFound")
+	// reg0[17]: Mark to indicate the packet is a request to a LoadBalancer IP in DSR mode, which is forwarded to remote
+	// Endpoints without NAT.
+	ServiceDSRRegMark = binding.NewOneBitRegMark(0, 17, "ServiceDSR")
 	// reg0[18]: Mark to indicate the packet needs DNAT to virtual IP.
 	// If a packet uses HairpinRegMark, it will be output to the port where it enters OVS pipeline in L2ForwardingOutTable.
 	HairpinRegMark = binding.NewOneBitRegMark(0, 18, "Hairpin")
@@ -65,6 +68,14 @@ var (
 	CustomReasonRejectRegMark  = binding.NewRegMark(CustomReasonField, CustomReasonReject)
 	CustomReasonDenyRegMark    = binding.NewRegMark(CustomReasonField, CustomReasonDeny)
 	CustomReasonDNSRegMark     = binding.NewRegMark(CustomReasonField, CustomReasonDNS)
+	// reg0[28]: Mark to indicate the packet has been logged by an egress rule in audit mode. The action flow of an
+	// audited rule only matches the packets without the mark, so that the rules after it are evaluated when the
+	// packet is resubmitted to the same table.
+	EgressAuditedRegMark    = binding.NewOneBitRegMark(0, 28, "EgressAudited")
+	NotEgressAuditedRegMark = binding.NewOneBitZeroRegMark(0, 28, "EgressAudited")
+	// reg0[29]: Mark to indicate the packet has been logged by an ingress rule in audit mode.
+	IngressAuditedRegMark    = binding.NewOneBitRegMark(0, 29, "IngressAudited")
+	NotIngressAuditedRegMark = binding.NewOneBitZeroRegMark(0, 29, "IngressAudited")
 
 	// reg1(NXM_NX_REG1)
 	// Field to cache the ofPort of the OVS interface where to output packet.
@@ -114,6 +125,9 @@ var (
 	// NotAntreaFlexibleIPAMRegMark will be used with RewriteMACRegMark, thus the reg id must not be same due to the limitation of ofnet library.
 	AntreaFlexibleIPAMRegMark    = binding.NewOneBitRegMark(4, 21, "AntreaFlexibleIPAM")
 	NotAntreaFlexibleIPAMRegMark = binding.NewOneBitZeroRegMark(4, 21, "AntreaFlexibleIPAM")
+	// reg4[24..31]: Field to store the ID of the table of the rule in audit mode which logs the packet, as the copy of
+	// the packet is sent to the controller from AuditLoggingTable.
+	AuditRuleTableField = binding.NewRegField(4, 24, 31, "AuditRuleTable")
 
 	// reg5(NXM_NX_REG5)
 	// Field to cache the Egress conjunction ID hit by TraceFlow packet.
@@ -126,6 +140,10 @@ var (
 	// reg7(NXM_NX_REG7)
 	// Field to store the GroupID corresponding to the Service
 	ServiceGroupIDField = binding.NewRegField(7, 0, 31, "ServiceGroupID")
+
+	// reg8(NXM_NX_REG8)
+	// Field to store the index of the lookup table of the SourceIPHash mode, which is the hash of the source IP.
+	ServiceSourceIPHashField = binding.NewRegField(8, 0, 7, "ServiceSourceIPHash")
 )
 
 // Fields using xxreg.
@@ -148,6 +166,8 @@ var (
 	// Mark to indicate the connection is initiated through the host bridge interface
 	// (i.e. for which the first packet of the connection was received through the bridge).
 	FromBridgeCTMark = binding.NewCTMark(0x1, 3, 3)
+	// Mark to indicate the connection is allowed by a rule with L7 protocols and must be redirected to the L7 proxy.
+	L7RedirectCTMark = binding.NewCTMark(0b1, 4, 4)
 )
 
 // Fields using CT label.
@@ -157,4 +177,11 @@ var (
 
 	// Field to store the egress rule ID.
 	EgressRuleCTLabel = binding.NewCTLabel(32, 63, "egressRuleCTLabel")
+
+	// Field to store EgressAuditedRegMark of the packet committing the connection, to indicate the connection would
+	// have been denied by an egress rule in audit mode.
+	EgressAuditedCTLabel = binding.NewCTLabel(64, 64, "egressAuditedCTLabel")
+
+	// Field to store IngressAuditedRegMark of the packet committing the connection.
+	IngressAuditedCTLabel = binding.NewCTLabel(65, 65, "ingressAuditedCTLabel")
 )
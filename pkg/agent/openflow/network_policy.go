@@ -951,7 +951,11 @@ func (c *client) calculateActionFlowChangesForRule(rule *types.PolicyRule) *poli
 		// Install action flows.
 		var actionFlows []binding.Flow
 		var metricFlows []binding.Flow
-		if rule.IsAntreaNetworkPolicyRule() && *rule.Action == crdv1alpha1.RuleActionDrop {
+		if rule.IsAntreaNetworkPolicyRule() && rule.Audit {
+			// The traffic matching an audited Drop or Reject rule is always logged, and then evaluated against the
+			// rules after it. The action flow also counts the traffic, there is no metric flow for the rule.
+			actionFlows = append(actionFlows, c.conjunctionActionAuditFlow(ruleOfID, ruleTable, rule.Priority))
+		} else if rule.IsAntreaNetworkPolicyRule() && *rule.Action == crdv1alpha1.RuleActionDrop {
 			metricFlows = append(metricFlows, c.denyRuleMetricFlow(ruleOfID, isIngress))
 			actionFlows = append(actionFlows, c.conjunctionActionDenyFlow(ruleOfID, ruleTable, rule.Priority, DispositionDrop, rule.EnableLogging))
 		} else if rule.IsAntreaNetworkPolicyRule() && *rule.Action == crdv1alpha1.RuleActionReject {
@@ -961,7 +965,7 @@ func (c *client) calculateActionFlowChangesForRule(rule *types.PolicyRule) *poli
 			actionFlows = append(actionFlows, c.conjunctionActionPassFlow(ruleOfID, ruleTable, rule.Priority, rule.EnableLogging))
 		} else {
 			metricFlows = append(metricFlows, c.allowRulesMetricFlows(ruleOfID, isIngress)...)
-			actionFlows = append(actionFlows, c.conjunctionActionFlow(ruleOfID, ruleTable, dropTable.GetNext(), rule.Priority, rule.EnableLogging)...)
+			actionFlows = append(actionFlows, c.conjunctionActionFlow(ruleOfID, ruleTable, dropTable.GetNext(), rule.Priority, rule.EnableLogging, len(rule.L7Protocols) > 0)...)
 		}
 		conj.actionFlows = actionFlows
 		conj.metricFlows = metricFlows
@@ -1698,5 +1702,52 @@ func (c *client) NetworkPolicyMetrics() map[uint32]*types.RuleMetric {
 	// flows to get the correct number of total packets.
 	collectMetricsFromFlows(egressFlows)
 	collectMetricsFromFlows(ingressFlows)
+	if c.enableAntreaPolicy {
+		// The metrics of the audited rules are the counters of their action flows, which only match the
+		// packets of the rules without the audited mark. They count the traffic which would have been denied
+		// by the rules.
+		for _, mark := range []*binding.RegMark{NotEgressAuditedRegMark, NotIngressAuditedRegMark} {
+			auditFlows, _ := c.ovsctlClient.DumpFlows(auditFlowFilter(mark))
+			for _, flow := range auditFlows {
+				if !strings.Contains(flow, "conj_id=") {
+					continue
+				}
+				ruleID, metric := parseAuditFlow(flow)
+				if accMetric, ok := result[ruleID]; ok {
+					accMetric.Merge(&metric)
+				} else {
+					result[ruleID] = &metric
+				}
+			}
+		}
+	}
 	return result
 }
+
+// auditFlowFilter returns the match used to dump the action flows of the audited rules, which are the only flows
+// matching the given mark.
+func auditFlowFilter(mark *binding.RegMark) string {
+	field := mark.GetField()
+	mask := ((uint32(1) << field.GetRange().Length()) - 1) << field.GetRange().Offset()
+	return fmt.Sprintf("reg%d=%#x/%#x", field.GetRegID(), mark.GetValue()<<field.GetRange().Offset(), mask)
+}
+
+func parseAuditFlow(flow string) (uint32, types.RuleMetric) {
+	// example audit flow format:
+	// table=85, n_packets=3, n_bytes=222, priority=14900,conj_id=7,reg0=0/0x20000000 actions=load:0x7->NXM_NX_REG6[],...
+	flowMap := parseFlowToMap(flow)
+	m := types.RuleMetric{}
+	pkts, _ := strconv.ParseUint(flowMap["n_packets"], 10, 64)
+	// Only the packets of the connections which are not committed yet reach the flow, which are mostly the first
+	// packet of each connection.
+	m.Packets = pkts
+	m.Sessions = pkts
+	bytes, _ := strconv.ParseUint(flowMap["n_bytes"], 10, 64)
+	m.Bytes = bytes
+	conjID := flowMap["conj_id"]
+	if i := strings.Index(conjID, " "); i != -1 {
+		conjID = conjID[:i]
+	}
+	id, _ := strconv.ParseUint(conjID, 10, 32)
+	return uint32(id), m
+}
@@ -501,13 +501,94 @@ func TestBatchInstallPolicyRuleFlows(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "audited Antrea NetworkPolicy rule above enforced Drop rule",
+			rules: []*types.PolicyRule{
+				{
+					Direction: v1beta2.DirectionIn,
+					From:      parseAddresses([]string{"192.168.1.40"}),
+					Action:    &actionDrop,
+					Priority:  &priority200,
+					To:        []types.Address{NewOFPortAddress(1)},
+					Service:   []v1beta2.Service{{Protocol: &protocolTCP, Port: &port8080}},
+					FlowID:    uint32(20),
+					TableID:   AntreaPolicyIngressRuleTable.GetID(),
+					PolicyRef: &v1beta2.NetworkPolicyReference{
+						Type:      v1beta2.AntreaNetworkPolicy,
+						Namespace: "ns1",
+						Name:      "np1",
+						UID:       "id1",
+					},
+					Audit: true,
+				},
+				{
+					Direction: v1beta2.DirectionIn,
+					From:      parseAddresses([]string{"192.168.1.40"}),
+					Action:    &actionDrop,
+					Priority:  &priority100,
+					To:        []types.Address{NewOFPortAddress(1)},
+					Service:   []v1beta2.Service{{Protocol: &protocolTCP, Port: &port8080}},
+					FlowID:    uint32(21),
+					TableID:   AntreaPolicyIngressRuleTable.GetID(),
+					PolicyRef: &v1beta2.NetworkPolicyReference{
+						Type:      v1beta2.AntreaNetworkPolicy,
+						Namespace: "ns1",
+						Name:      "np2",
+						UID:       "id2",
+					},
+				},
+			},
+			expectedFlowsFn: func(c *client) []binding.Flow {
+				cookiePolicy := c.cookieAllocator.Request(cookie.Policy).Raw()
+				return []binding.Flow{
+					// The audited rule logs the packet and resubmits it to the same table without committing the
+					// connection, so that it is dropped by the rule with the lower priority.
+					AntreaPolicyIngressRuleTable.BuildFlow(priority200).Cookie(cookiePolicy).
+						MatchConjID(20).MatchRegMark(NotIngressAuditedRegMark).
+						Action().LoadToRegField(TFIngressConjIDField, 20).
+						Action().LoadToRegField(AuditRuleTableField, uint32(AntreaPolicyIngressRuleTable.GetID())).
+						Action().LoadRegMark(IngressAuditedRegMark).
+						Action().LoadRegMark(DispositionAllowRegMark).
+						Action().LoadRegMark(CustomReasonLoggingRegMark).
+						Action().CloneResubmitToTable(AuditLoggingTable.GetID()).
+						Action().ResubmitToTable(AntreaPolicyIngressRuleTable.GetID()).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority100).Cookie(cookiePolicy).
+						MatchConjID(21).
+						Action().LoadToRegField(CNPDenyConjIDField, 21).
+						Action().LoadRegMark(CnpDenyRegMark).
+						Action().GotoTable(IngressMetricTable.GetID()).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority200).Cookie(cookiePolicy).
+						MatchProtocol(binding.ProtocolIP).MatchSrcIP(net.ParseIP("192.168.1.40")).
+						Action().Conjunction(20, 1, 3).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority100).Cookie(cookiePolicy).
+						MatchProtocol(binding.ProtocolIP).MatchSrcIP(net.ParseIP("192.168.1.40")).
+						Action().Conjunction(21, 1, 3).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority200).Cookie(cookiePolicy).
+						MatchRegFieldWithValue(TargetOFPortField, uint32(1)).
+						Action().Conjunction(20, 2, 3).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority100).Cookie(cookiePolicy).
+						MatchRegFieldWithValue(TargetOFPortField, uint32(1)).
+						Action().Conjunction(21, 2, 3).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority200).Cookie(cookiePolicy).
+						MatchProtocol(binding.ProtocolTCP).MatchDstPort(8080, nil).
+						Action().Conjunction(20, 3, 3).Done(),
+					AntreaPolicyIngressRuleTable.BuildFlow(priority100).Cookie(cookiePolicy).
+						MatchProtocol(binding.ProtocolTCP).MatchDstPort(8080, nil).
+						Action().Conjunction(21, 3, 3).Done(),
+					// Only the enforced rule has a metric flow.
+					IngressMetricTable.BuildFlow(priorityNormal).Cookie(cookiePolicy).
+						MatchRegMark(CnpDenyRegMark).MatchRegFieldWithValue(CNPDenyConjIDField, 21).
+						Action().Drop().Done(),
+				}
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			mockOperations := oftest.NewMockOFEntryOperations(ctrl)
-			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, false, true, false, false, false, false)
+			ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, false, true, false, false, false, false, false)
 			c = ofClient.(*client)
 			c.cookieAllocator = cookie.NewAllocator(0)
 			c.ofEntryOperations = mockOperations
@@ -575,7 +656,7 @@ func BenchmarkBatchInstallPolicyRuleFlows(b *testing.B) {
 	ctrl := gomock.NewController(b)
 	defer ctrl.Finish()
 	mockOperations := oftest.NewMockOFEntryOperations(ctrl)
-	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, false, true, false, false, false, false)
+	ofClient := NewClient(bridgeName, bridgeMgmtAddr, ovsconfig.OVSDatapathSystem, false, true, false, false, false, false, false)
 	c = ofClient.(*client)
 	c.cookieAllocator = cookie.NewAllocator(0)
 	c.ofEntryOperations = mockOperations
@@ -1108,6 +1189,40 @@ func TestNetworkPolicyMetrics(t *testing.T) {
 	}
 }
 
+func TestNetworkPolicyMetricsWithAuditedRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c = prepareClient(ctrl)
+	c.enableAntreaPolicy = true
+	mockOVSClient := ovsctltest.NewMockOVSCtlClient(ctrl)
+	c.ovsctlClient = mockOVSClient
+	gomock.InOrder(
+		mockOVSClient.EXPECT().DumpTableFlows(EgressMetricTable.GetID()).Return([]string{
+			"table=61, n_packets=1, n_bytes=74, priority=200,ct_state=+new,ct_label=0x200000000/0xffffffff00000000,ip actions=goto_table:70",
+			"table=61, n_packets=1502362, n_bytes=601635949, priority=0 actions=goto_table:70",
+		}, nil),
+		mockOVSClient.EXPECT().DumpTableFlows(IngressMetricTable.GetID()).Return([]string{
+			"table=101, n_packets=4, n_bytes=338, priority=200,reg0=0x100000/0x100000,reg3=0xb actions=drop",
+			"table=101, n_packets=1407190, n_bytes=509746586, priority=0 actions=resubmit(,105)",
+		}, nil),
+		mockOVSClient.EXPECT().DumpFlows("reg0=0/0x10000000").Return([]string{
+			"table=45, n_packets=2, n_bytes=148, priority=14900,conj_id=7,reg0=0/0x10000000 actions=load:0x7->NXM_NX_REG5[],load:0x1->NXM_NX_REG0[28],load:0->NXM_NX_REG0[21..22],load:0x1->NXM_NX_REG0[24..27],controller(reason=no_match,id=24213),resubmit(,45)",
+		}, nil),
+		mockOVSClient.EXPECT().DumpFlows("reg0=0/0x20000000").Return([]string{
+			"table=85, n_packets=3, n_bytes=222, priority=14900,conj_id=9,reg0=0/0x20000000 actions=load:0x9->NXM_NX_REG6[],load:0x1->NXM_NX_REG0[29],load:0->NXM_NX_REG0[21..22],load:0x1->NXM_NX_REG0[24..27],controller(reason=no_match,id=24213),resubmit(,85)",
+		}, nil),
+	)
+	got := c.NetworkPolicyMetrics()
+	// The metrics of the audited rules 7 and 9 only count the packets matching them.
+	assert.Equal(t, map[uint32]*types.RuleMetric{
+		2:  {Bytes: 74, Sessions: 1, Packets: 1},
+		11: {Bytes: 338, Sessions: 4, Packets: 4},
+		7:  {Bytes: 148, Sessions: 2, Packets: 2},
+		9:  {Bytes: 222, Sessions: 3, Packets: 3},
+	}, got)
+}
+
 func TestGetMatchFlowUpdates(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
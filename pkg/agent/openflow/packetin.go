@@ -35,14 +35,21 @@ const (
 	// Meter Entry ID.
 	PacketInMeterIDNP = 1
 	PacketInMeterIDTF = 2
+	PacketInMeterIDPC = 3
+	PacketInMeterIDFE = 4
 	// Meter Entry Rate. It is represented as number of events per second.
 	// Packets which exceed the rate will be dropped.
 	PacketInMeterRateNP = 100
 	PacketInMeterRateTF = 100
+	PacketInMeterRatePC = 100
+	PacketInMeterRateFE = 100
 
 	// PacketIn reasons
 	PacketInReasonTF ofpPacketInReason = 1
 	PacketInReasonNP ofpPacketInReason = 0
+	PacketInReasonPC ofpPacketInReason = 2
+	// PacketInReasonFE is used by the Flow Exporter to compute TCP metrics.
+	PacketInReasonFE ofpPacketInReason = 3
 	// PacketInQueueSize defines the size of PacketInQueue.
 	// When PacketInQueue reaches PacketInQueueSize, new packet-in will be dropped.
 	PacketInQueueSize = 200
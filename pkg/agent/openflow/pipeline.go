@@ -36,6 +36,7 @@ import (
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	"antrea.io/antrea/pkg/ovs/ovsconfig"
 	"antrea.io/antrea/pkg/ovs/ovsctl"
+	"antrea.io/antrea/pkg/util/maglev"
 	"antrea.io/antrea/pkg/util/runtime"
 	"antrea.io/antrea/third_party/proxy"
 )
@@ -73,6 +74,7 @@ var (
 	ServiceConntrackCommitTable  = binding.NewOFTable(106, "ServiceConntrackCommit")
 	HairpinSNATTable             = binding.NewOFTable(108, "HairpinSNAT")
 	L2ForwardingOutTable         = binding.NewOFTable(110, "Output")
+	AuditLoggingTable            = binding.NewOFTable(115, "AuditLogging")
 
 	// Flow priority level
 	priorityHigh            = uint16(210)
@@ -86,6 +88,9 @@ var (
 	// Index for priority cache
 	priorityIndex = "priority"
 
+	// Weight of the Service group buckets of the Endpoints which receive new connections
+	serviceEndpointBucketWeight = uint16(100)
+
 	// IPv6 multicast prefix
 	ipv6MulticastAddr = "FF00::/8"
 	// IPv6 link-local prefix
@@ -234,6 +239,17 @@ const (
 	// policy rules.
 	CustomReasonDeny = 0b100
 	CustomReasonDNS  = 0b1000
+
+	// snatMeterIDBase is the base ID of the meter entries limiting the
+	// bandwidth of SNAT IPs, which doesn't overlap with the IDs of the
+	// packet-in meter entries. The meter ID of a SNAT IP is the sum of the
+	// base and the SNAT IP mark.
+	snatMeterIDBase = 256
+
+	// TCP flags of the packets sent to the controller to compute the TCP metrics of
+	// the connections.
+	tcpFlagSYN = 0b10
+	tcpFlagRST = 0b100
 )
 
 var DispositionToString = map[uint32]string{
@@ -251,6 +267,9 @@ var (
 	// snatPktMarkRange takes an 8-bit range of pkt_mark to store the ID of
 	// a SNAT IP. The bit range must match SNATIPMarkMask.
 	snatPktMarkRange = &binding.Range{0, 7}
+	// l7RedirectPktMarkRange takes a 1-bit range of pkt_mark to mark the
+	// packets redirected to the L7 proxy. The bit must match types.L7RedirectBit.
+	l7RedirectPktMarkRange = &binding.Range{types.L7RedirectBit, types.L7RedirectBit}
 
 	GlobalVirtualMAC, _ = net.ParseMAC("aa:bb:cc:dd:ee:ff")
 	hairpinIP           = net.ParseIP("169.254.169.252").To4()
@@ -291,16 +310,17 @@ type client struct {
 	enableEgress          bool
 	enableWireGuard       bool
 	connectUplinkToBridge bool
+	enableDSR             bool
 	roundInfo             types.RoundInfo
 	cookieAllocator       cookie.Allocator
 	bridge                binding.Bridge
 	egressEntryTable      uint8
 	ingressEntryTable     uint8
 	// Flow caches for corresponding deletions.
-	nodeFlowCache, podFlowCache, serviceFlowCache, snatFlowCache, tfFlowCache *flowCategoryCache
+	nodeFlowCache, podFlowCache, serviceFlowCache, snatFlowCache, tfFlowCache, pcFlowCache *flowCategoryCache
 	// "fixed" flows installed by the agent after initialization and which do not change during
 	// the lifetime of the client.
-	gatewayFlows, defaultServiceFlows, defaultTunnelFlows, hostNetworkingFlows []binding.Flow
+	gatewayFlows, defaultServiceFlows, defaultTunnelFlows, hostNetworkingFlows, tcpMetricsFlows []binding.Flow
 	// ofEntryOperations is a wrapper interface for OpenFlow entry Add / Modify / Delete operations. It
 	// enables convenient mocking in unit tests.
 	ofEntryOperations OFEntryOperations
@@ -309,6 +329,12 @@ type client struct {
 	policyCache       cache.Indexer
 	conjMatchFlowLock sync.Mutex // Lock for access globalConjMatchFlowCache
 	groupCache        sync.Map
+	// chainedGroupCache stores the groups whose buckets refer to other groups
+	// in groupCache.
+	chainedGroupCache sync.Map
+	// snatMeterCache stores the meter entries limiting the bandwidth of
+	// SNAT IPs, keyed by the SNAT IP marks.
+	snatMeterCache sync.Map
 	// globalConjMatchFlowCache is a global map for conjMatchFlowContext. The key is a string generated from the
 	// conjMatchFlowContext.
 	globalConjMatchFlowCache map[string]*conjMatchFlowContext
@@ -569,6 +595,18 @@ func (c *client) connectionTrackFlows(category cookie.Category) []binding.Flow {
 			)
 
 			if c.proxyAll {
+				if c.enableDSR && proto == binding.ProtocolIP {
+					// This flow is used to skip the commit of the requests forwarded to remote Endpoints through the
+					// tunnel in DSR mode. As the replies don't go through this Node, the connections would be invalid
+					// for conntrack once committed. The Endpoints selected for the connections are cached by learned
+					// flows instead.
+					flows = append(flows, ConntrackCommitTable.BuildFlow(priorityHigh).MatchProtocol(proto).
+						MatchRegMark(ServiceDSRRegMark).
+						MatchRegFieldWithValue(TargetOFPortField, config.DefaultTunOFPort).
+						Action().GotoTable(ConntrackCommitTable.GetNext()).
+						Cookie(c.cookieAllocator.Request(category).Raw()).
+						Done())
+				}
 				flows = append(flows,
 					// This flow is used to match the Service traffic from Antrea gateway. The Service traffic from gateway
 					// should enter table serviceConntrackCommitTable, otherwise it will be matched by other flows in
@@ -673,17 +711,17 @@ func (c *client) connectionTrackFlows(category cookie.Category) []binding.Flow {
 		}
 		flows = append(flows,
 			// Connections initiated through the gateway are marked with FromGatewayCTMark.
-			ConntrackCommitTable.BuildFlow(priorityNormal).MatchProtocol(proto).
+			c.moveAuditedMarksToLabel(ConntrackCommitTable.BuildFlow(priorityNormal).MatchProtocol(proto).
 				MatchRegMark(FromGatewayRegMark).
 				MatchCTStateNew(true).MatchCTStateTrk(true).
-				Action().CT(true, ConntrackCommitTable.GetNext(), ctZone).LoadToCtMark(FromGatewayCTMark).CTDone().
+				Action().CT(true, ConntrackCommitTable.GetNext(), ctZone).LoadToCtMark(FromGatewayCTMark)).CTDone().
 				Cookie(c.cookieAllocator.Request(category).Raw()).
 				Done(),
 			// Connections initiated through the bridge port are marked with FromBridgeCTMark.
-			ConntrackCommitTable.BuildFlow(priorityNormal).MatchProtocol(proto).
+			c.moveAuditedMarksToLabel(ConntrackCommitTable.BuildFlow(priorityNormal).MatchProtocol(proto).
 				MatchRegMark(FromBridgeRegMark).
 				MatchCTStateNew(true).MatchCTStateTrk(true).
-				Action().CT(true, ConntrackCommitTable.GetNext(), ctZone).LoadToCtMark(FromBridgeCTMark).CTDone().
+				Action().CT(true, ConntrackCommitTable.GetNext(), ctZone).LoadToCtMark(FromBridgeCTMark)).CTDone().
 				Cookie(c.cookieAllocator.Request(category).Raw()).
 				Done(),
 			// Add reject response packet bypass flow.
@@ -735,9 +773,9 @@ func (c *client) conntrackBasicFlows(category cookie.Category) []binding.Flow {
 				Action().Drop().
 				Cookie(c.cookieAllocator.Request(category).Raw()).
 				Done(),
-			ConntrackCommitTable.BuildFlow(priorityLow).MatchProtocol(proto).
+			c.moveAuditedMarksToLabel(ConntrackCommitTable.BuildFlow(priorityLow).MatchProtocol(proto).
 				MatchCTStateNew(true).MatchCTStateTrk(true).
-				Action().CT(true, ConntrackCommitTable.GetNext(), ctZone).CTDone().
+				Action().CT(true, ConntrackCommitTable.GetNext(), ctZone)).CTDone().
 				Cookie(c.cookieAllocator.Request(category).Raw()).
 				Done(),
 		)
@@ -983,12 +1021,12 @@ func (c *client) l2ForwardCalcFlow(dstMAC net.HardwareAddr, ofPort uint32, skipI
 
 // traceflowL2ForwardOutputFlows generates Traceflow specific flows that outputs traceflow packets
 // to OVS port and Antrea Agent after L2forwarding calculation.
-func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic, droppedOnly bool, timeout uint16, category cookie.Category) []binding.Flow {
+func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic, droppedOnly, multiHop bool, timeout uint16, category cookie.Category) []binding.Flow {
 	flows := []binding.Flow{}
 	for _, ipProtocol := range c.ipProtocols {
 		if c.networkConfig.TrafficEncapMode.SupportsEncap() {
 			// SendToController and Output if output port is tunnel port.
-			fb1 := L2ForwardingOutTable.BuildFlow(priorityNormal+3).
+			fb1 := L2ForwardingOutTable.BuildFlow(priorityNormal+5).
 				MatchRegFieldWithValue(TargetOFPortField, config.DefaultTunOFPort).
 				MatchIPDSCP(dataplaneTag).
 				SetHardTimeout(timeout).
@@ -999,8 +1037,9 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic,
 			// For injected packets, only SendToController if output port is local
 			// gateway. In encapMode, a Traceflow packet going out of the gateway
 			// port (i.e. exiting the overlay) essentially means that the Traceflow
-			// request is complete.
-			fb2 := L2ForwardingOutTable.BuildFlow(priorityNormal+2).
+			// request is complete, unless the packet is destined to a NodePort or
+			// LoadBalancer IP and will re-enter the cluster.
+			fb2 := L2ForwardingOutTable.BuildFlow(priorityNormal+4).
 				MatchRegFieldWithValue(TargetOFPortField, config.HostGatewayOFPort).
 				MatchIPDSCP(dataplaneTag).
 				SetHardTimeout(timeout).
@@ -1017,7 +1056,11 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic,
 				fb1 = fb1.Action().SendToController(uint8(PacketInReasonTF))
 				fb2 = fb2.Action().SendToController(uint8(PacketInReasonTF))
 			}
-			if liveTraffic {
+			if multiHop {
+				// Keep the DSCP bits, so that the Node the packet
+				// reaches next can keep tracing it.
+				fb2 = fb2.Action().OutputToRegField(TargetOFPortField)
+			} else if liveTraffic {
 				// Clear the loaded DSCP bits before output.
 				fb2 = fb2.Action().LoadIPDSCP(0).
 					Action().OutputToRegField(TargetOFPortField)
@@ -1027,7 +1070,7 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic,
 			// SendToController and Output if output port is local gateway. Unlike in
 			// encapMode, inter-Node Pod-to-Pod traffic is expected to go out of the
 			// gateway port on the way to its destination.
-			fb1 := L2ForwardingOutTable.BuildFlow(priorityNormal+2).
+			fb1 := L2ForwardingOutTable.BuildFlow(priorityNormal+4).
 				MatchRegFieldWithValue(TargetOFPortField, config.HostGatewayOFPort).
 				MatchIPDSCP(dataplaneTag).
 				SetHardTimeout(timeout).
@@ -1049,7 +1092,7 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic,
 			gatewayIP = c.nodeConfig.GatewayConfig.IPv6
 		}
 		if gatewayIP != nil {
-			fb := L2ForwardingOutTable.BuildFlow(priorityNormal+3).
+			fb := L2ForwardingOutTable.BuildFlow(priorityNormal+5).
 				MatchRegFieldWithValue(TargetOFPortField, config.HostGatewayOFPort).
 				MatchDstIP(gatewayIP).
 				MatchIPDSCP(dataplaneTag).
@@ -1070,7 +1113,7 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic,
 			flows = append(flows, fb.Done())
 		}
 		// Only SendToController if output port is Pod port.
-		fb := L2ForwardingOutTable.BuildFlow(priorityNormal + 2).
+		fb := L2ForwardingOutTable.BuildFlow(priorityNormal + 4).
 			MatchIPDSCP(dataplaneTag).
 			SetHardTimeout(timeout).
 			MatchProtocol(ipProtocol).
@@ -1112,6 +1155,115 @@ func (c *client) traceflowL2ForwardOutputFlows(dataplaneTag uint8, liveTraffic,
 	return flows
 }
 
+// packetCaptureFlows generates the flows that send a copy of the packets matching
+// the provided packet to Antrea Agent after L2 forwarding calculation, and then
+// output them like l2ForwardOutputFlows. They have a lower priority than the
+// Traceflow flows, so that they don't interfere with Traceflow requests, and a
+// higher priority than the TCP metrics flows. When the TCP metrics are enabled,
+// the TCP packets with the SYN or RST flag which match the provided packet are
+// sent to Antrea Agent for both features by flows with a higher priority.
+// The packets dropped by the previous tables, e.g. by NetworkPolicies, are
+// not captured.
+func (c *client) packetCaptureFlows(packet *binding.Packet, timeout uint16, category cookie.Category) []binding.Flow {
+	var ipProtocol binding.Protocol
+	tcpProtocol := binding.ProtocolTCP
+	if packet.IsIPv6 {
+		tcpProtocol = binding.ProtocolTCPv6
+	}
+	switch packet.IPProto {
+	case protocol.Type_ICMP:
+		ipProtocol = binding.ProtocolICMP
+	case protocol.Type_IPv6ICMP:
+		ipProtocol = binding.ProtocolICMPv6
+	case protocol.Type_TCP:
+		ipProtocol = tcpProtocol
+	case protocol.Type_UDP:
+		ipProtocol = binding.ProtocolUDP
+		if packet.IsIPv6 {
+			ipProtocol = binding.ProtocolUDPv6
+		}
+	default:
+		ipProtocol = binding.ProtocolIP
+		if packet.IsIPv6 {
+			ipProtocol = binding.ProtocolIPv6
+		}
+	}
+	buildFlow := func(priority uint16, ipProtocol binding.Protocol) binding.FlowBuilder {
+		fb := L2ForwardingOutTable.BuildFlow(priority).
+			MatchProtocol(ipProtocol).
+			MatchRegMark(OFPortFoundRegMark).
+			SetHardTimeout(timeout).
+			Cookie(c.cookieAllocator.Request(category).Raw())
+		if packet.SourceIP != nil {
+			fb = fb.MatchSrcIP(packet.SourceIP)
+		}
+		if packet.DestinationIP != nil {
+			fb = fb.MatchDstIP(packet.DestinationIP)
+		}
+		if ipProtocol == binding.ProtocolIP || ipProtocol == binding.ProtocolIPv6 {
+			if packet.IPProto != 0 {
+				fb = fb.MatchIPProtocolValue(packet.IsIPv6, packet.IPProto)
+			}
+		} else if packet.IPProto == protocol.Type_TCP || packet.IPProto == protocol.Type_UDP {
+			if packet.SourcePort != 0 {
+				fb = fb.MatchSrcPort(packet.SourcePort, nil)
+			}
+			if packet.DestinationPort != 0 {
+				fb = fb.MatchDstPort(packet.DestinationPort, nil)
+			}
+		}
+		if c.ovsMetersAreSupported {
+			fb = fb.Action().Meter(PacketInMeterIDPC)
+		}
+		return fb.Action().SendToController(uint8(PacketInReasonPC))
+	}
+
+	flows := []binding.Flow{buildFlow(priorityNormal+2, ipProtocol).
+		Action().OutputToRegField(TargetOFPortField).
+		Done()}
+	capturesTCP := ipProtocol == tcpProtocol || (packet.IPProto == 0 && (ipProtocol == binding.ProtocolIP || ipProtocol == binding.ProtocolIPv6))
+	if len(c.tcpMetricsFlows) > 0 && capturesTCP {
+		for _, flag := range []uint16{tcpFlagSYN, tcpFlagRST} {
+			flows = append(flows, buildFlow(priorityNormal+3, tcpProtocol).
+				MatchTCPFlags(flag, flag).
+				Action().SendToController(uint8(PacketInReasonFE)).
+				Action().OutputToRegField(TargetOFPortField).
+				Done())
+		}
+	}
+	return flows
+}
+
+// tcpMetricsPacketInFlows generates the flows that send a copy of the TCP packets with
+// the SYN flag (SYN and SYN-ACK) or the RST flag to the controller, so that the Flow
+// Exporter can compute the handshake RTT and count the retransmissions and resets of
+// the TCP connections. The packets are still forwarded normally. The flows have a lower
+// priority than the PacketCapture flows, which also send the matching packets to the
+// controller for the Flow Exporter.
+func (c *client) tcpMetricsPacketInFlows(category cookie.Category) []binding.Flow {
+	var flows []binding.Flow
+	for _, ipProtocol := range c.ipProtocols {
+		tcpProtocol := binding.ProtocolTCP
+		if ipProtocol == binding.ProtocolIPv6 {
+			tcpProtocol = binding.ProtocolTCPv6
+		}
+		for _, flag := range []uint16{tcpFlagSYN, tcpFlagRST} {
+			fb := L2ForwardingOutTable.BuildFlow(priorityNormal+1).
+				MatchProtocol(tcpProtocol).
+				MatchRegMark(OFPortFoundRegMark).
+				MatchTCPFlags(flag, flag).
+				Cookie(c.cookieAllocator.Request(category).Raw())
+			if c.ovsMetersAreSupported {
+				fb = fb.Action().Meter(PacketInMeterIDFE)
+			}
+			flows = append(flows, fb.Action().SendToController(uint8(PacketInReasonFE)).
+				Action().OutputToRegField(TargetOFPortField).
+				Done())
+		}
+	}
+	return flows
+}
+
 // l2ForwardOutputServiceHairpinFlow uses in_port action for Service
 // hairpin packets to avoid packets from being dropped by OVS.
 func (c *client) l2ForwardOutputServiceHairpinFlow() binding.Flow {
@@ -1122,6 +1274,31 @@ func (c *client) l2ForwardOutputServiceHairpinFlow() binding.Flow {
 		Done()
 }
 
+// l7RedirectFlows generates the flows that redirect the packets of the connections allowed by rules with L7 protocols
+// to the host gateway, where they are intercepted by the L7 proxy. The flows only match the packets in the original
+// direction which are sent by local Pods or received from the tunnel, so that the connections initiated by the L7 proxy
+// itself through the gateway are not redirected again. The replies of the L7 proxy are sent back through the gateway
+// and forwarded to the client as regular traffic.
+func (c *client) l7RedirectFlows(gatewayMAC net.HardwareAddr, category cookie.Category) []binding.Flow {
+	var flows []binding.Flow
+	for _, ipProtocol := range c.ipProtocols {
+		for _, sourceMark := range []*binding.RegMark{FromLocalRegMark, FromTunnelRegMark} {
+			flows = append(flows, L2ForwardingOutTable.BuildFlow(priorityHigh+1).
+				MatchProtocol(ipProtocol).
+				MatchRegMark(sourceMark).
+				MatchCTStateTrk(true).
+				MatchCTStateRpl(false).
+				MatchCTMark(L7RedirectCTMark).
+				Action().SetDstMAC(gatewayMAC).
+				Action().LoadPktMarkRange(1, l7RedirectPktMarkRange).
+				Action().Output(config.HostGatewayOFPort).
+				Cookie(c.cookieAllocator.Request(category).Raw()).
+				Done())
+		}
+	}
+	return flows
+}
+
 // l2ForwardOutputFlows generates the flows that output packets to OVS port after L2 forwarding calculation.
 func (c *client) l2ForwardOutputFlows(category cookie.Category) []binding.Flow {
 	var flows []binding.Flow
@@ -1653,7 +1830,8 @@ func (c *client) ipv6Flows(category cookie.Category) []binding.Flow {
 
 // conjunctionActionFlow generates the flow to jump to a specific table if policyRuleConjunction ID is matched. Priority of
 // conjunctionActionFlow is created at priorityLow for k8s network policies, and *priority assigned by PriorityAssigner for AntreaPolicy.
-func (c *client) conjunctionActionFlow(conjunctionID uint32, table binding.Table, nextTable uint8, priority *uint16, enableLogging bool) []binding.Flow {
+// If l7Redirect is true, the connection is committed with L7RedirectCTMark so that its packets are redirected to the L7 proxy.
+func (c *client) conjunctionActionFlow(conjunctionID uint32, table binding.Table, nextTable uint8, priority *uint16, enableLogging bool, l7Redirect bool) []binding.Flow {
 	var ofPriority uint16
 	if priority == nil {
 		ofPriority = priorityLow
@@ -1678,23 +1856,31 @@ func (c *client) conjunctionActionFlow(conjunctionID uint32, table binding.Table
 			if c.ovsMetersAreSupported {
 				fb = fb.Action().Meter(PacketInMeterIDNP)
 			}
-			return fb.
+			ctAction := fb.
 				Action().LoadToRegField(conjReg, conjunctionID).  // Traceflow.
 				Action().LoadRegMark(DispositionAllowRegMark).    // AntreaPolicy.
 				Action().LoadRegMark(CustomReasonLoggingRegMark). // Enable logging.
 				Action().SendToController(uint8(PacketInReasonNP)).
 				Action().CT(true, nextTable, ctZone). // CT action requires commit flag if actions other than NAT without arguments are specified.
-				LoadToLabelField(uint64(conjunctionID), labelField).
-				CTDone().
+				LoadToLabelField(uint64(conjunctionID), labelField)
+			ctAction = c.moveAuditedMarksToLabel(ctAction)
+			if l7Redirect {
+				ctAction = ctAction.LoadToCtMark(L7RedirectCTMark)
+			}
+			return ctAction.CTDone().
 				Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
 				Done()
 		}
-		return table.BuildFlow(ofPriority).MatchProtocol(proto).
+		ctAction := table.BuildFlow(ofPriority).MatchProtocol(proto).
 			MatchConjID(conjunctionID).
 			Action().LoadToRegField(conjReg, conjunctionID). // Traceflow.
 			Action().CT(true, nextTable, ctZone).            // CT action requires commit flag if actions other than NAT without arguments are specified.
-			LoadToLabelField(uint64(conjunctionID), labelField).
-			CTDone().
+			LoadToLabelField(uint64(conjunctionID), labelField)
+		ctAction = c.moveAuditedMarksToLabel(ctAction)
+		if l7Redirect {
+			ctAction = ctAction.LoadToCtMark(L7RedirectCTMark)
+		}
+		return ctAction.CTDone().
 			Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
 			Done()
 	}
@@ -1773,6 +1959,68 @@ func (c *client) conjunctionActionPassFlow(conjunctionID uint32, table binding.T
 		Done()
 }
 
+// moveAuditedMarksToLabel copies the audited marks of the packet committing a connection to its ct_label, so that the
+// Flow Exporter can report the connections which would have been denied by a rule in audit mode. The marks are only
+// set by the packets of the current Node, and are never cleared once set in the pipeline, so committing a connection
+// more than once in the pipeline doesn't lose them.
+func (c *client) moveAuditedMarksToLabel(ctAction binding.CTAction) binding.CTAction {
+	if !c.enableAntreaPolicy {
+		return ctAction
+	}
+	for _, m := range []struct {
+		mark  *binding.RegMark
+		label *binding.CtLabel
+	}{
+		{EgressAuditedRegMark, EgressAuditedCTLabel},
+		{IngressAuditedRegMark, IngressAuditedCTLabel},
+	} {
+		field := m.mark.GetField()
+		ctAction = ctAction.MoveToLabel(field.GetNXFieldName(), field.GetRange(), m.label.GetRange())
+	}
+	return ctAction
+}
+
+// conjunctionActionAuditFlow generates the flow for a Drop or Reject rule in audit mode. The packet is marked as
+// audited and a copy of it is sent to AuditLoggingTable to be logged, then the packet is resubmitted to the same table
+// without committing the connection. As the flow only matches packets without the mark, OVS then falls back to the
+// conjunctions with a lower priority, so the packet is evaluated against the rules after the audited rule as if it
+// didn't exist. Only the first audited rule matched by a packet in each direction logs it. The flow is also the metric
+// flow of the audited rule, as its counters are those of the packets which would have been denied.
+func (c *client) conjunctionActionAuditFlow(conjunctionID uint32, table binding.Table, priority *uint16) binding.Flow {
+	ofPriority := *priority
+	conjReg := TFIngressConjIDField
+	notAuditedMark, auditedMark := NotIngressAuditedRegMark, IngressAuditedRegMark
+	tableID := table.GetID()
+	if _, ok := egressTables[tableID]; ok {
+		conjReg = TFEgressConjIDField
+		notAuditedMark, auditedMark = NotEgressAuditedRegMark, EgressAuditedRegMark
+	}
+	return table.BuildFlow(ofPriority).MatchConjID(conjunctionID).
+		MatchRegMark(notAuditedMark).
+		Action().LoadToRegField(conjReg, conjunctionID).
+		Action().LoadToRegField(AuditRuleTableField, uint32(tableID)).
+		Action().LoadRegMark(auditedMark).
+		Action().LoadRegMark(DispositionAllowRegMark).
+		Action().LoadRegMark(CustomReasonLoggingRegMark).
+		Action().CloneResubmitToTable(AuditLoggingTable.GetID()).
+		Action().ResubmitToTable(tableID).
+		Cookie(c.cookieAllocator.Request(cookie.Policy).Raw()).
+		Done()
+}
+
+// auditLoggingFlow generates the flow to send the copies of the packets matching the rules in audit mode to the
+// controller. The packet-in meter is applied to the copies only, so the packets exceeding its rate are not logged but
+// are still forwarded.
+func (c *client) auditLoggingFlow(category cookie.Category) binding.Flow {
+	fb := AuditLoggingTable.BuildFlow(priorityNormal)
+	if c.ovsMetersAreSupported {
+		fb = fb.Action().Meter(PacketInMeterIDNP)
+	}
+	return fb.Action().SendToController(uint8(PacketInReasonNP)).
+		Cookie(c.cookieAllocator.Request(category).Raw()).
+		Done()
+}
+
 func (c *client) Disconnect() error {
 	return c.bridge.Disconnect()
 }
@@ -2177,14 +2425,18 @@ func (c *client) snatCommonFlows(nodeIP net.IP, localSubnet net.IPNet, localGate
 }
 
 // snatIPFromTunnelFlow generates a flow that marks SNAT packets tunnelled from
-// remote Nodes. The SNAT IP matches the packet's tunnel destination IP.
+// remote Nodes. The SNAT IP matches the packet's tunnel destination IP. If a
+// meter has been installed for the SNAT IP, the packets are also metered.
 func (c *client) snatIPFromTunnelFlow(snatIP net.IP, mark uint32) binding.Flow {
 	ipProto := getIPProtocol(snatIP)
-	return SNATTable.BuildFlow(priorityNormal).
+	fb := SNATTable.BuildFlow(priorityNormal).
 		MatchProtocol(ipProto).
 		MatchCTStateNew(true).MatchCTStateTrk(true).
-		MatchTunnelDst(snatIP).
-		Action().LoadPktMarkRange(mark, snatPktMarkRange).
+		MatchTunnelDst(snatIP)
+	if meterID, ok := c.getSNATMeterID(mark); ok {
+		fb = fb.Action().Meter(uint32(meterID))
+	}
+	return fb.Action().LoadPktMarkRange(mark, snatPktMarkRange).
 		Action().GotoTable(L3DecTTLTable.GetID()).
 		Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
 		Done()
@@ -2198,11 +2450,14 @@ func (c *client) snatRuleFlow(ofPort uint32, snatIP net.IP, snatMark uint32, loc
 	ipProto := getIPProtocol(snatIP)
 	if snatMark != 0 {
 		// Local SNAT IP.
-		return SNATTable.BuildFlow(priorityNormal).
+		fb := SNATTable.BuildFlow(priorityNormal).
 			MatchProtocol(ipProto).
 			MatchCTStateNew(true).MatchCTStateTrk(true).
-			MatchInPort(ofPort).
-			Action().LoadPktMarkRange(snatMark, snatPktMarkRange).
+			MatchInPort(ofPort)
+		if meterID, ok := c.getSNATMeterID(snatMark); ok {
+			fb = fb.Action().Meter(uint32(meterID))
+		}
+		return fb.Action().LoadPktMarkRange(snatMark, snatPktMarkRange).
 			Action().GotoTable(SNATTable.GetNext()).
 			Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
 			Done()
@@ -2220,6 +2475,66 @@ func (c *client) snatRuleFlow(ofPort uint32, snatIP net.IP, snatMark uint32, loc
 		Done()
 }
 
+// snatMeterFromTunnelFlow generates a flow that meters the packets of the
+// established connections tunnelled from remote Nodes to the local SNAT IP.
+// The packets of new connections are metered by snatIPFromTunnelFlow.
+func (c *client) snatMeterFromTunnelFlow(snatIP net.IP, meterID binding.MeterIDType) binding.Flow {
+	ipProto := getIPProtocol(snatIP)
+	return SNATTable.BuildFlow(priorityNormal).
+		MatchProtocol(ipProto).
+		MatchCTStateNew(false).MatchCTStateTrk(true).
+		MatchTunnelDst(snatIP).
+		Action().Meter(uint32(meterID)).
+		Action().GotoTable(SNATTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
+		Done()
+}
+
+// snatEstablishedFromPodFlow generates a flow that matches the packets of the
+// established connections from a local Pod using a local SNAT IP, and meters
+// them if a meter entry has been installed for the SNAT IP. The packets of new
+// connections are matched by snatRuleFlow. The statistics of both flows are
+// used to count the packets SNAT'd for the Pod.
+func (c *client) snatEstablishedFromPodFlow(ofPort uint32, snatIP net.IP, snatMark uint32) binding.Flow {
+	ipProto := getIPProtocol(snatIP)
+	fb := SNATTable.BuildFlow(priorityNormal).
+		MatchProtocol(ipProto).
+		MatchCTStateNew(false).MatchCTStateTrk(true).
+		MatchInPort(ofPort)
+	if meterID, ok := c.getSNATMeterID(snatMark); ok {
+		fb = fb.Action().Meter(uint32(meterID))
+	}
+	return fb.Action().GotoTable(SNATTable.GetNext()).
+		Cookie(c.cookieAllocator.Request(cookie.SNAT).Raw()).
+		Done()
+}
+
+func snatMeterID(mark uint32) binding.MeterIDType {
+	return binding.MeterIDType(snatMeterIDBase + mark)
+}
+
+// getSNATMeterID returns the ID of the meter entry of the SNAT IP mark, and
+// whether the meter entry has been installed.
+func (c *client) getSNATMeterID(mark uint32) (binding.MeterIDType, bool) {
+	if _, ok := c.snatMeterCache.Load(mark); !ok {
+		return 0, false
+	}
+	return snatMeterID(mark), true
+}
+
+// genSNATMeter generates a meter entry limiting the bandwidth of the SNAT IP of
+// the mark. `rate` is represented as kbps and `burst` as kbits. Packets which
+// exceed the rate will be dropped.
+func (c *client) genSNATMeter(mark, rate, burst uint32) binding.Meter {
+	meter := c.bridge.CreateMeter(snatMeterID(mark), ofctrl.MeterBurst|ofctrl.MeterKbps).ResetMeterBands()
+	meter = meter.MeterBand().
+		MeterType(ofctrl.MeterDrop).
+		Rate(rate).
+		Burst(burst).
+		Done()
+	return meter
+}
+
 // loadBalancerServiceFromOutsideFlow generates the flow to forward LoadBalancer service traffic from outside node
 // to gateway. kube-proxy will then handle the traffic.
 // This flow is for Windows Node only.
@@ -2393,6 +2708,94 @@ func (c *client) serviceLBFlow(groupID binding.GroupIDType, svcIP net.IP, svcPor
 		Action().Group(groupID).Done()
 }
 
+// serviceLoadBalancerDSRFlows generates the flows which do Endpoint selection for the traffic of a LoadBalancer IP in
+// DSR mode:
+//   - The requests from Antrea gateway, i.e. from outside the cluster, are load balanced across all the Endpoints with
+//     groupID, without SNAT. The requests to remote Endpoints are marked with ServiceDSRRegMark to be forwarded to the
+//     Nodes of the Endpoints without NAT, see serviceDSRForwardingFlows. The other requests are load balanced as usual,
+//     the requests to the Endpoints on remote host network are SNAT'd as they leave through Antrea gateway.
+//   - The requests from the tunnel, i.e. forwarded by other Nodes in DSR mode, are load balanced across the local
+//     Endpoints with localGroupID, without SNAT, so that the replies are sent directly to the clients.
+func (c *client) serviceLoadBalancerDSRFlows(groupID, localGroupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) []binding.Flow {
+	return []binding.Flow{
+		ServiceLBTable.BuildFlow(priorityHigh).
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			MatchProtocol(protocol).
+			MatchDstPort(svcPort, nil).
+			MatchDstIP(svcIP).
+			MatchRegMark(FromGatewayRegMark).
+			MatchRegMark(EpToSelectRegMark).
+			Action().LoadRegMark(EpSelectedRegMark).
+			Action().LoadRegMark(RewriteMACRegMark).
+			Action().LoadRegMark(ServiceDSRRegMark).
+			Action().LoadToRegField(ServiceGroupIDField, uint32(groupID)).
+			Action().Group(groupID).Done(),
+		ServiceLBTable.BuildFlow(priorityHigh).
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			MatchProtocol(protocol).
+			MatchDstPort(svcPort, nil).
+			MatchDstIP(svcIP).
+			MatchRegMark(FromTunnelRegMark).
+			MatchRegMark(EpToSelectRegMark).
+			Action().LoadRegMark(EpSelectedRegMark).
+			Action().LoadRegMark(RewriteMACRegMark).
+			Action().LoadToRegField(ServiceGroupIDField, uint32(localGroupID)).
+			Action().Group(localGroupID).Done(),
+	}
+}
+
+// dsrLearnedFlowIdleTimeout is the idle timeout of the flows learned by serviceDSRForwardingFlows. When a learned flow
+// expires, the next packets of the connection select the same Endpoint again, unless the Endpoints have changed.
+const dsrLearnedFlowIdleTimeout = 300
+
+// serviceDSRForwardingFlows generates the flows which forward the requests to the Endpoints in the Pod subnet of a
+// remote Node in DSR mode: the requests are not DNAT'd, but sent to the Node through the tunnel with the LoadBalancer
+// IP preserved. The Node load balances them across its local Endpoints, and replies directly to the clients. As the
+// replies don't go through this Node, the connections are not committed to conntrack, and the flows learn the
+// Endpoint selected for each connection in SessionAffinityTable instead.
+func (c *client) serviceDSRForwardingFlows(localGatewayMAC net.HardwareAddr, peerSubnet net.IPNet, tunnelPeer net.IP, category cookie.Category) []binding.Flow {
+	// The Endpoints in the peer subnet are matched with the prefix of the Endpoint IP.
+	prefixLength, _ := peerSubnet.Mask.Size()
+	endpointPrefixField := binding.NewRegField(EndpointIPField.GetRegID(), uint32(32-prefixLength), 31, "EndpointIPPrefix")
+	endpointPrefix := binary.BigEndian.Uint32(peerSubnet.IP.To4()) >> (32 - prefixLength)
+	cookieID := c.cookieAllocator.Request(category).Raw()
+
+	var flows []binding.Flow
+	for _, protocol := range []binding.Protocol{binding.ProtocolTCP, binding.ProtocolUDP, binding.ProtocolSCTP} {
+		learnAction := EndpointDNATTable.BuildFlow(priorityHigh).
+			Cookie(cookieID).
+			MatchProtocol(protocol).
+			MatchRegMark(ServiceDSRRegMark).
+			MatchRegMark(EpSelectedRegMark).
+			MatchRegFieldWithValue(endpointPrefixField, endpointPrefix).
+			Action().Learn(SessionAffinityTable.GetID(), priorityHigh, dsrLearnedFlowIdleTimeout, 0, cookieID).
+			DeleteLearned()
+		switch protocol {
+		case binding.ProtocolTCP:
+			learnAction = learnAction.MatchLearnedTCPDstPort()
+		case binding.ProtocolUDP:
+			learnAction = learnAction.MatchLearnedUDPDstPort()
+		case binding.ProtocolSCTP:
+			learnAction = learnAction.MatchLearnedSCTPDstPort()
+		}
+		flows = append(flows, learnAction.
+			MatchLearnedSrcPort(protocol).
+			MatchLearnedDstIP().
+			MatchLearnedSrcIP().
+			LoadFieldToField(EndpointIPField, EndpointIPField).
+			LoadFieldToField(EndpointPortField, EndpointPortField).
+			LoadRegMark(EpSelectedRegMark).
+			LoadRegMark(ServiceDSRRegMark).
+			Done().
+			Action().SetSrcMAC(localGatewayMAC).
+			Action().SetDstMAC(GlobalVirtualMAC).
+			Action().SetTunnelDst(tunnelPeer).
+			Action().GotoTable(L3DecTTLTable.GetID()).
+			Done())
+	}
+	return flows
+}
+
 // endpointDNATFlow generates the flow which transforms the Service Cluster IP
 // to the Endpoint IP according to the Endpoint selection decision which is stored
 // in regs.
@@ -2442,13 +2845,107 @@ func (c *client) hairpinSNATFlow(endpointIP net.IP) binding.Flow {
 		Done()
 }
 
+const (
+	// consistentHashTableSize is the size of the lookup table of the
+	// ConsistentHash mode, whose entries are the buckets of the Service group.
+	// It's the largest prime number for which the group fits in an OpenFlow
+	// message.
+	consistentHashTableSize = 797
+	// sourceIPHashTableSize is the size of the lookup table of the
+	// SourceIPHash mode, which is indexed by the hash of the source IP loaded
+	// in ServiceSourceIPHashField.
+	sourceIPHashTableSize = 256
+)
+
+// serviceEndpointBucketWeights returns the weights of the buckets of the
+// Endpoints of a Service. Only the ready Endpoints receive new connections, or,
+// when there is no ready Endpoint, the serving terminating Endpoints, which are
+// used as fallback Endpoints. The other Endpoints get weight 0: they are only
+// kept in the group for their existing connections. When weighted is true, the
+// weights are proportional to the weights of the Endpoints.
+func serviceEndpointBucketWeights(endpoints []proxy.Endpoint, weighted bool) []uint16 {
+	weights := make([]uint16, len(endpoints))
+	hasReadyEndpoint := false
+	for _, endpoint := range endpoints {
+		if endpoint.IsReady() {
+			hasReadyEndpoint = true
+			break
+		}
+	}
+	for i, endpoint := range endpoints {
+		if endpoint.IsReady() || (!hasReadyEndpoint && endpoint.IsServing() && endpoint.IsTerminating()) {
+			weights[i] = serviceEndpointBucketWeight
+			if weighted {
+				weights[i] *= uint16(endpoint.GetWeight())
+			}
+		}
+	}
+	return weights
+}
+
+// serviceLookupTableEndpoints returns the Endpoints of the entries of a Maglev
+// lookup table of the provided size, which are the Endpoints receiving new
+// connections. It returns nil if there is no such Endpoint.
+func serviceLookupTableEndpoints(endpoints []proxy.Endpoint, size int) []proxy.Endpoint {
+	var names []string
+	var selected []proxy.Endpoint
+	for i, weight := range serviceEndpointBucketWeights(endpoints, false) {
+		if weight > 0 {
+			names = append(names, endpoints[i].String())
+			selected = append(selected, endpoints[i])
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	table := maglev.Table(names, size)
+	tableEndpoints := make([]proxy.Endpoint, len(table))
+	for i, endpoint := range table {
+		tableEndpoints[i] = selected[endpoint]
+	}
+	return tableEndpoints
+}
+
 // serviceEndpointGroup creates/modifies the group/buckets of Endpoints. If the
 // withSessionAffinity is true, then buckets will resubmit packets back to
 // ServiceLBTable to trigger the learn flow, the learn flow will then send packets
 // to EndpointDNATTable. Otherwise, buckets will resubmit packets to
-// EndpointDNATTable directly.
-func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints ...proxy.Endpoint) binding.Group {
+// EndpointDNATTable directly. The buckets of the Endpoints which must not
+// receive new connections have weight 0. With the ConsistentHash mode, the
+// buckets are the entries of a Maglev lookup table of the Endpoints receiving
+// new connections. With the SourceIPHash mode, the single bucket loads the hash
+// of the source IP and resubmits packets to the flows generated by
+// serviceSourceIPHashFlows. Both modes fall
+// back to the default mode when no Endpoint receives new connections.
+func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAffinity bool, lbMode types.ServiceLBMode, endpoints ...proxy.Endpoint) binding.Group {
 	group := c.bridge.CreateGroup(groupID).ResetBuckets()
+	switch lbMode {
+	case types.ServiceLBModeConsistentHash:
+		if tableEndpoints := serviceLookupTableEndpoints(endpoints, consistentHashTableSize); tableEndpoints != nil {
+			return addServiceEndpointBuckets(group, withSessionAffinity, tableEndpoints, serviceEndpointBucketWeights(tableEndpoints, false))
+		}
+	case types.ServiceLBModeSourceIPHash:
+		if serviceLookupTableEndpoints(endpoints, sourceIPHashTableSize) != nil {
+			return group.Bucket().Weight(serviceEndpointBucketWeight).
+				Multipath(binding.HashFieldsNWSrc, 0, binding.MultipathAlgorithmModuloN, sourceIPHashTableSize-1, 0, ServiceSourceIPHashField).
+				ResubmitToTable(SessionAffinityTable.GetID()).
+				Done()
+		}
+	}
+	return addServiceEndpointBuckets(group, withSessionAffinity, endpoints, serviceEndpointBucketWeights(endpoints, lbMode == types.ServiceLBModeWeighted))
+}
+
+// serviceSourceIPHashFlows generates the flows selecting the Endpoints of the
+// new connections load-balanced by a group with the SourceIPHash mode. The
+// flows are in SessionAffinityTable, to which the bucket of the group resubmits
+// packets, and match the group ID loaded by serviceLBFlow and the hash of the
+// source IP loaded by the bucket, which indexes a Maglev lookup table of the
+// Endpoints. It returns nil if no Endpoint receives new connections.
+func (c *client) serviceSourceIPHashFlows(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) []binding.Flow {
+	tableEndpoints := serviceLookupTableEndpoints(endpoints, sourceIPHashTableSize)
+	if tableEndpoints == nil {
+		return nil
+	}
 	var resubmitTableID uint8
 	if withSessionAffinity {
 		resubmitTableID = ServiceLBTable.GetID()
@@ -2456,21 +2953,100 @@ func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAf
 		resubmitTableID = EndpointDNATTable.GetID()
 	}
 
-	for _, endpoint := range endpoints {
+	flows := make([]binding.Flow, 0, len(tableEndpoints))
+	for i, endpoint := range tableEndpoints {
+		endpointPort, _ := endpoint.Port()
+		endpointIP := net.ParseIP(endpoint.IP())
+		flowBuilder := SessionAffinityTable.BuildFlow(priorityHigh).
+			Cookie(c.cookieAllocator.Request(cookie.Service).Raw()).
+			MatchRegFieldWithValue(ServiceGroupIDField, uint32(groupID)).
+			MatchRegFieldWithValue(ServiceSourceIPHashField, uint32(i))
+		if getIPProtocol(endpointIP) == binding.ProtocolIP {
+			flowBuilder = flowBuilder.MatchProtocol(binding.ProtocolIP).
+				Action().LoadToRegField(EndpointIPField, binary.BigEndian.Uint32(endpointIP.To4()))
+		} else {
+			ipVal := endpointIP.To16()
+			flowBuilder = flowBuilder.MatchProtocol(binding.ProtocolIPv6).
+				Action().LoadRange(EndpointIP6Field.GetNXFieldName(), binary.BigEndian.Uint64(ipVal[:8]), &binding.Range{64, 127}).
+				Action().LoadRange(EndpointIP6Field.GetNXFieldName(), binary.BigEndian.Uint64(ipVal[8:]), &binding.Range{0, 63})
+		}
+		flows = append(flows, flowBuilder.
+			Action().LoadToRegField(EndpointPortField, uint32(portToUint16(endpointPort))).
+			Action().ResubmitToTable(resubmitTableID).
+			Done())
+	}
+	return flows
+}
+
+// serviceEndpointGroupWithSubGroups creates/modifies the group of a Service
+// whose Endpoints are split across the sub-groups, and returns the group and
+// the sub-groups. The group has a bucket for each sub-group, whose weight is
+// proportional to the total weight of the Endpoints of the sub-group, so that
+// new connections are load-balanced across the Endpoints as with a single
+// group.
+func (c *client) serviceEndpointGroupWithSubGroups(groupID binding.GroupIDType, subGroupIDs []binding.GroupIDType, withSessionAffinity bool, lbMode types.ServiceLBMode, endpoints []proxy.Endpoint) (binding.Group, []binding.Group) {
+	group := c.bridge.CreateGroup(groupID).ResetBuckets()
+	subGroups := make([]binding.Group, 0, len(subGroupIDs))
+	weights := serviceEndpointBucketWeights(endpoints, lbMode == types.ServiceLBModeWeighted)
+	subGroupWeights := make([]uint32, len(subGroupIDs))
+	maxSubGroupWeight := uint32(0)
+	for i, subGroupID := range subGroupIDs {
+		start, end := i*len(endpoints)/len(subGroupIDs), (i+1)*len(endpoints)/len(subGroupIDs)
+		subGroup := c.bridge.CreateGroup(subGroupID).ResetBuckets()
+		// When OVS doesn't use the dp_hash selection method, the bucket of a group
+		// is selected with a hash of the bucket ID and of the packet 5-tuple. As
+		// the buckets IDs of all the groups start from 0, the buckets of the
+		// sub-groups are preceded by placeholder buckets, so that the selections
+		// of the group and of the sub-groups use different bucket IDs and are
+		// independent.
+		for range subGroupIDs {
+			subGroup = subGroup.Bucket().Weight(0).Done()
+		}
+		subGroups = append(subGroups, addServiceEndpointBuckets(subGroup, withSessionAffinity, endpoints[start:end], weights[start:end]))
+		for _, weight := range weights[start:end] {
+			subGroupWeights[i] += uint32(weight / serviceEndpointBucketWeight)
+		}
+		if subGroupWeights[i] > maxSubGroupWeight {
+			maxSubGroupWeight = subGroupWeights[i]
+		}
+	}
+	// The weights of the buckets are scaled down when they don't fit in 16 bits,
+	// which is only possible with the Weighted mode.
+	scale := uint32(1)
+	if maxSubGroupWeight > math.MaxUint16 {
+		scale = (maxSubGroupWeight + math.MaxUint16 - 1) / math.MaxUint16
+	}
+	for i, subGroupID := range subGroupIDs {
+		group = group.Bucket().Weight(uint16((subGroupWeights[i] + scale - 1) / scale)).Group(subGroupID).Done()
+	}
+	return group, subGroups
+}
+
+// addServiceEndpointBuckets adds a bucket with the provided weight to the group
+// for each Endpoint.
+func addServiceEndpointBuckets(group binding.Group, withSessionAffinity bool, endpoints []proxy.Endpoint, weights []uint16) binding.Group {
+	var resubmitTableID uint8
+	if withSessionAffinity {
+		resubmitTableID = ServiceLBTable.GetID()
+	} else {
+		resubmitTableID = EndpointDNATTable.GetID()
+	}
+
+	for i, endpoint := range endpoints {
 		endpointPort, _ := endpoint.Port()
 		endpointIP := net.ParseIP(endpoint.IP())
 		portVal := portToUint16(endpointPort)
 		ipProtocol := getIPProtocol(endpointIP)
 		if ipProtocol == binding.ProtocolIP {
 			ipVal := binary.BigEndian.Uint32(endpointIP.To4())
-			group = group.Bucket().Weight(100).
+			group = group.Bucket().Weight(weights[i]).
 				LoadToRegField(EndpointIPField, ipVal).
 				LoadToRegField(EndpointPortField, uint32(portVal)).
 				ResubmitToTable(resubmitTableID).
 				Done()
 		} else if ipProtocol == binding.ProtocolIPv6 {
 			ipVal := []byte(endpointIP)
-			group = group.Bucket().Weight(100).
+			group = group.Bucket().Weight(weights[i]).
 				LoadXXReg(EndpointIP6Field.GetRegID(), ipVal).
 				LoadToRegField(EndpointPortField, uint32(portVal)).
 				ResubmitToTable(resubmitTableID).
@@ -2480,6 +3056,47 @@ func (c *client) serviceEndpointGroup(groupID binding.GroupIDType, withSessionAf
 	return group
 }
 
+const (
+	// maxServiceGroupBuckets is the maximum number of buckets of a Service
+	// group, as a group and its buckets must fit in a single OpenFlow message,
+	// whose length is at most 64KB.
+	maxServiceGroupBuckets = 800
+	// MaxServiceEndpoints is the maximum number of Endpoints of a Service
+	// group, when they are split across the maximum number of sub-groups.
+	MaxServiceEndpoints = (maxServiceGroupBuckets / 2) * (maxServiceGroupBuckets / 2)
+)
+
+// ServiceMaxEndpoints returns the maximum number of Endpoints of a Service
+// group with the provided lbMode. With the ConsistentHash and SourceIPHash
+// modes, it's the size of the lookup table, as the Endpoints without an entry
+// in the table would never receive new connections.
+func ServiceMaxEndpoints(lbMode types.ServiceLBMode) int {
+	switch lbMode {
+	case types.ServiceLBModeConsistentHash:
+		return consistentHashTableSize
+	case types.ServiceLBModeSourceIPHash:
+		return sourceIPHashTableSize
+	}
+	return MaxServiceEndpoints
+}
+
+// ServiceSubGroupCount returns the number of sub-groups across which the
+// Endpoints of a Service group must be split, or 0 if they fit in the group.
+// As each sub-group has a placeholder bucket per sub-group, endpointCount must
+// not exceed ServiceMaxEndpoints. The groups of the ConsistentHash and
+// SourceIPHash modes never have sub-groups, as their numbers of buckets don't
+// depend on the number of Endpoints.
+func ServiceSubGroupCount(endpointCount int, lbMode types.ServiceLBMode) int {
+	if endpointCount <= maxServiceGroupBuckets || lbMode == types.ServiceLBModeConsistentHash || lbMode == types.ServiceLBModeSourceIPHash {
+		return 0
+	}
+	count := 2
+	for count < maxServiceGroupBuckets/2 && count+(endpointCount+count-1)/count > maxServiceGroupBuckets {
+		count++
+	}
+	return count
+}
+
 // decTTLFlows decrements TTL by one for the packets forwarded across Nodes.
 // The TTL decrement should be skipped for the packets which enter OVS pipeline
 // from the gateway interface, as the host IP stack should have decremented the
@@ -2589,6 +3206,7 @@ func (c *client) generatePipeline() {
 	if c.enableAntreaPolicy {
 		c.createOFTable(AntreaPolicyEgressRuleTable, EgressRuleTable.GetID(), binding.TableMissActionNext)
 		c.createOFTable(AntreaPolicyIngressRuleTable, IngressRuleTable.GetID(), binding.TableMissActionNext)
+		c.createOFTable(AuditLoggingTable, binding.LastTableID, binding.TableMissActionDrop)
 	}
 }
 
@@ -2608,7 +3226,8 @@ func NewClient(bridgeName string,
 	enableEgress bool,
 	enableDenyTracking bool,
 	proxyAll bool,
-	connectUplinkToBridge bool) Client {
+	connectUplinkToBridge bool,
+	enableDSR bool) Client {
 	bridge := binding.NewOFBridge(bridgeName, mgmtAddr)
 	policyCache := cache.NewIndexer(
 		policyConjKeyFunc,
@@ -2622,12 +3241,15 @@ func NewClient(bridgeName string,
 		enableDenyTracking:       enableDenyTracking,
 		enableEgress:             enableEgress,
 		connectUplinkToBridge:    connectUplinkToBridge,
+		enableDSR:                enableDSR,
 		nodeFlowCache:            newFlowCategoryCache(),
 		podFlowCache:             newFlowCategoryCache(),
 		serviceFlowCache:         newFlowCategoryCache(),
 		tfFlowCache:              newFlowCategoryCache(),
+		pcFlowCache:              newFlowCategoryCache(),
 		policyCache:              policyCache,
 		groupCache:               sync.Map{},
+		chainedGroupCache:        sync.Map{},
 		globalConjMatchFlowCache: map[string]*conjMatchFlowContext{},
 		packetInHandlers:         map[uint8]map[string]PacketInHandler{},
 		ovsctlClient:             ovsctl.NewClient(bridgeName),
@@ -364,6 +364,20 @@ func (mr *MockClientMockRecorder) InstallGatewayFlows() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallGatewayFlows", reflect.TypeOf((*MockClient)(nil).InstallGatewayFlows))
 }
 
+// InstallLoadBalancerServiceDSRFlows mocks base method
+func (m *MockClient) InstallLoadBalancerServiceDSRFlows(arg0, arg1 openflow.GroupIDType, arg2 net.IP, arg3 uint16, arg4 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallLoadBalancerServiceDSRFlows", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallLoadBalancerServiceDSRFlows indicates an expected call of InstallLoadBalancerServiceDSRFlows
+func (mr *MockClientMockRecorder) InstallLoadBalancerServiceDSRFlows(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallLoadBalancerServiceDSRFlows", reflect.TypeOf((*MockClient)(nil).InstallLoadBalancerServiceDSRFlows), arg0, arg1, arg2, arg3, arg4)
+}
+
 // InstallLoadBalancerServiceFromOutsideFlows mocks base method
 func (m *MockClient) InstallLoadBalancerServiceFromOutsideFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
 	m.ctrl.T.Helper()
@@ -392,6 +406,20 @@ func (mr *MockClientMockRecorder) InstallNodeFlows(arg0, arg1, arg2, arg3, arg4
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallNodeFlows", reflect.TypeOf((*MockClient)(nil).InstallNodeFlows), arg0, arg1, arg2, arg3, arg4)
 }
 
+// InstallPacketCaptureFlows mocks base method
+func (m *MockClient) InstallPacketCaptureFlows(arg0 string, arg1 *openflow.Packet, arg2 uint16) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallPacketCaptureFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallPacketCaptureFlows indicates an expected call of InstallPacketCaptureFlows
+func (mr *MockClientMockRecorder) InstallPacketCaptureFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallPacketCaptureFlows", reflect.TypeOf((*MockClient)(nil).InstallPacketCaptureFlows), arg0, arg1, arg2)
+}
+
 // InstallPodFlows mocks base method
 func (m *MockClient) InstallPodFlows(arg0 string, arg1 []net.IP, arg2 net.HardwareAddr, arg3 uint32) error {
 	m.ctrl.T.Helper()
@@ -448,6 +476,20 @@ func (mr *MockClientMockRecorder) InstallSNATMarkFlows(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallSNATMarkFlows", reflect.TypeOf((*MockClient)(nil).InstallSNATMarkFlows), arg0, arg1)
 }
 
+// InstallSNATMeter mocks base method
+func (m *MockClient) InstallSNATMeter(arg0, arg1, arg2 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallSNATMeter", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallSNATMeter indicates an expected call of InstallSNATMeter
+func (mr *MockClientMockRecorder) InstallSNATMeter(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallSNATMeter", reflect.TypeOf((*MockClient)(nil).InstallSNATMeter), arg0, arg1, arg2)
+}
+
 // InstallServiceFlows mocks base method
 func (m *MockClient) InstallServiceFlows(arg0 openflow.GroupIDType, arg1 net.IP, arg2 uint16, arg3 openflow.Protocol, arg4 uint16, arg5 bool, arg6 v1.ServiceType) error {
 	m.ctrl.T.Helper()
@@ -463,31 +505,59 @@ func (mr *MockClientMockRecorder) InstallServiceFlows(arg0, arg1, arg2, arg3, ar
 }
 
 // InstallServiceGroup mocks base method
-func (m *MockClient) InstallServiceGroup(arg0 openflow.GroupIDType, arg1 bool, arg2 []proxy.Endpoint) error {
+func (m *MockClient) InstallServiceGroup(arg0 openflow.GroupIDType, arg1 bool, arg2 types.ServiceLBMode, arg3 []proxy.Endpoint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InstallServiceGroup", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "InstallServiceGroup", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InstallServiceGroup indicates an expected call of InstallServiceGroup
-func (mr *MockClientMockRecorder) InstallServiceGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) InstallServiceGroup(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceGroup", reflect.TypeOf((*MockClient)(nil).InstallServiceGroup), arg0, arg1, arg2, arg3)
+}
+
+// InstallServiceGroupWithSubGroups mocks base method
+func (m *MockClient) InstallServiceGroupWithSubGroups(arg0 openflow.GroupIDType, arg1 []openflow.GroupIDType, arg2 bool, arg3 types.ServiceLBMode, arg4 []proxy.Endpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallServiceGroupWithSubGroups", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallServiceGroupWithSubGroups indicates an expected call of InstallServiceGroupWithSubGroups
+func (mr *MockClientMockRecorder) InstallServiceGroupWithSubGroups(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceGroup", reflect.TypeOf((*MockClient)(nil).InstallServiceGroup), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallServiceGroupWithSubGroups", reflect.TypeOf((*MockClient)(nil).InstallServiceGroupWithSubGroups), arg0, arg1, arg2, arg3, arg4)
+}
+
+// InstallTCPMetricsFlows mocks base method
+func (m *MockClient) InstallTCPMetricsFlows() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallTCPMetricsFlows")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InstallTCPMetricsFlows indicates an expected call of InstallTCPMetricsFlows
+func (mr *MockClientMockRecorder) InstallTCPMetricsFlows() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallTCPMetricsFlows", reflect.TypeOf((*MockClient)(nil).InstallTCPMetricsFlows))
 }
 
 // InstallTraceflowFlows mocks base method
-func (m *MockClient) InstallTraceflowFlows(arg0 byte, arg1, arg2, arg3 bool, arg4 *openflow.Packet, arg5 uint32, arg6 uint16) error {
+func (m *MockClient) InstallTraceflowFlows(arg0 byte, arg1, arg2, arg3, arg4 bool, arg5 *openflow.Packet, arg6 uint32, arg7 uint16) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InstallTraceflowFlows", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret := m.ctrl.Call(m, "InstallTraceflowFlows", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InstallTraceflowFlows indicates an expected call of InstallTraceflowFlows
-func (mr *MockClientMockRecorder) InstallTraceflowFlows(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) InstallTraceflowFlows(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallTraceflowFlows", reflect.TypeOf((*MockClient)(nil).InstallTraceflowFlows), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallTraceflowFlows", reflect.TypeOf((*MockClient)(nil).InstallTraceflowFlows), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 }
 
 // IsConnected mocks base method
@@ -560,6 +630,20 @@ func (mr *MockClientMockRecorder) NewDNSpacketInConjunction(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDNSpacketInConjunction", reflect.TypeOf((*MockClient)(nil).NewDNSpacketInConjunction), arg0)
 }
 
+// PodSNATMetrics mocks base method
+func (m *MockClient) PodSNATMetrics() map[uint32]*types.SNATMetric {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PodSNATMetrics")
+	ret0, _ := ret[0].(map[uint32]*types.SNATMetric)
+	return ret0
+}
+
+// PodSNATMetrics indicates an expected call of PodSNATMetrics
+func (mr *MockClientMockRecorder) PodSNATMetrics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PodSNATMetrics", reflect.TypeOf((*MockClient)(nil).PodSNATMetrics))
+}
+
 // ReassignFlowPriorities mocks base method
 func (m *MockClient) ReassignFlowPriorities(arg0 map[uint16]uint16, arg1 byte) error {
 	m.ctrl.T.Helper()
@@ -694,6 +778,20 @@ func (mr *MockClientMockRecorder) UninstallEndpointFlows(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallEndpointFlows", reflect.TypeOf((*MockClient)(nil).UninstallEndpointFlows), arg0, arg1)
 }
 
+// UninstallLoadBalancerServiceDSRFlows mocks base method
+func (m *MockClient) UninstallLoadBalancerServiceDSRFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallLoadBalancerServiceDSRFlows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallLoadBalancerServiceDSRFlows indicates an expected call of UninstallLoadBalancerServiceDSRFlows
+func (mr *MockClientMockRecorder) UninstallLoadBalancerServiceDSRFlows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallLoadBalancerServiceDSRFlows", reflect.TypeOf((*MockClient)(nil).UninstallLoadBalancerServiceDSRFlows), arg0, arg1, arg2)
+}
+
 // UninstallLoadBalancerServiceFromOutsideFlows mocks base method
 func (m *MockClient) UninstallLoadBalancerServiceFromOutsideFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
 	m.ctrl.T.Helper()
@@ -722,6 +820,20 @@ func (mr *MockClientMockRecorder) UninstallNodeFlows(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallNodeFlows", reflect.TypeOf((*MockClient)(nil).UninstallNodeFlows), arg0)
 }
 
+// UninstallPacketCaptureFlows mocks base method
+func (m *MockClient) UninstallPacketCaptureFlows(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallPacketCaptureFlows", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallPacketCaptureFlows indicates an expected call of UninstallPacketCaptureFlows
+func (mr *MockClientMockRecorder) UninstallPacketCaptureFlows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallPacketCaptureFlows", reflect.TypeOf((*MockClient)(nil).UninstallPacketCaptureFlows), arg0)
+}
+
 // UninstallPodFlows mocks base method
 func (m *MockClient) UninstallPodFlows(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -779,6 +891,20 @@ func (mr *MockClientMockRecorder) UninstallSNATMarkFlows(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallSNATMarkFlows", reflect.TypeOf((*MockClient)(nil).UninstallSNATMarkFlows), arg0)
 }
 
+// UninstallSNATMeter mocks base method
+func (m *MockClient) UninstallSNATMeter(arg0 uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UninstallSNATMeter", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UninstallSNATMeter indicates an expected call of UninstallSNATMeter
+func (mr *MockClientMockRecorder) UninstallSNATMeter(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallSNATMeter", reflect.TypeOf((*MockClient)(nil).UninstallSNATMeter), arg0)
+}
+
 // UninstallServiceFlows mocks base method
 func (m *MockClient) UninstallServiceFlows(arg0 net.IP, arg1 uint16, arg2 openflow.Protocol) error {
 	m.ctrl.T.Helper()
@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1beta1"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/proxy/types"
@@ -54,14 +55,14 @@ type endpointsChangesTracker struct {
 	sliceCache *EndpointSliceCache
 }
 
-func newEndpointsChangesTracker(hostname string, enableEndpointSlice bool, isIPv6 bool) *endpointsChangesTracker {
+func newEndpointsChangesTracker(hostname string, enableEndpointSlice bool, isIPv6 bool, podLister corelisters.PodLister) *endpointsChangesTracker {
 	tracker := &endpointsChangesTracker{
 		hostname: hostname,
 		changes:  map[apimachinerytypes.NamespacedName]*endpointsChange{},
 	}
 
 	if enableEndpointSlice {
-		tracker.sliceCache = NewEndpointSliceCache(hostname, isIPv6)
+		tracker.sliceCache = NewEndpointSliceCache(hostname, isIPv6, podLister)
 	}
 	return tracker
 }
@@ -200,6 +201,8 @@ func (t *endpointsChangesTracker) endpointsToEndpointsMap(endpoints *corev1.Endp
 				ei := types.NewEndpointInfo(&k8sproxy.BaseEndpointInfo{
 					Endpoint: net.JoinHostPort(addr.IP, fmt.Sprint(port.Port)),
 					IsLocal:  isLocal,
+					Ready:    true,
+					Serving:  true,
 				})
 				endpointsMap[svcPortName][ei.String()] = ei
 			}
@@ -34,6 +34,9 @@ limitations under the License.
 // Remove unused standardEndpointInfo.
 // Remove unneeded sort.Sort in endpointsMapFromEndpointInfo.
 // Update import paths.
+// Copy the zone hints of EndpointSlice Endpoints.
+// Keep the terminating EndpointSlice Endpoints and copy their conditions.
+// Copy the weight of EndpointSlice Endpoints from the annotation of their Pod or of the EndpointSlice.
 
 package proxy
 
@@ -41,19 +44,26 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1beta1"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 
 	"antrea.io/antrea/pkg/agent/proxy/types"
+	"antrea.io/antrea/pkg/apis"
 	"antrea.io/antrea/third_party/proxy"
 )
 
+// maxEndpointWeight is the maximum relative weight of Endpoints.
+const maxEndpointWeight = 100
+
 // EndpointSliceCache is used as a cache of EndpointSlice information.
 type EndpointSliceCache struct {
 	// lock protects trackerByServiceMap.
@@ -69,6 +79,9 @@ type EndpointSliceCache struct {
 
 	hostname   string
 	isIPv6Mode bool
+	// podLister is used to read the weight of the Endpoints from their Pods. The weight is only read from the
+	// EndpointSlices when it's nil.
+	podLister corelisters.PodLister
 }
 
 // endpointSliceTracker keeps track of EndpointSlices as they have been applied
@@ -93,10 +106,18 @@ type endpointSliceInfo struct {
 
 // endpointInfo contains just the attributes kube-proxy cares about.
 // Used for caching. Intentionally small to limit memory util.
-// Addresses and Topology are copied from EndpointSlice Endpoints.
+// Addresses, Topology, ZoneHints and the conditions are copied from EndpointSlice Endpoints. Weight is copied from the
+// annotation of the Pod of the Endpoint, or from the annotation of the EndpointSlice when the Pod doesn't specify it.
 type endpointInfo struct {
 	Addresses []string
 	Topology  map[string]string
+	ZoneHints sets.String
+
+	Ready       bool
+	Serving     bool
+	Terminating bool
+
+	Weight int
 }
 
 // spToEndpointMap stores groups Endpoint objects by ServicePortName and
@@ -104,11 +125,12 @@ type endpointInfo struct {
 type spToEndpointMap map[proxy.ServicePortName]map[string]proxy.Endpoint
 
 // NewEndpointSliceCache initializes an EndpointSliceCache.
-func NewEndpointSliceCache(hostname string, isIPv6Mode bool) *EndpointSliceCache {
+func NewEndpointSliceCache(hostname string, isIPv6Mode bool, podLister corelisters.PodLister) *EndpointSliceCache {
 	return &EndpointSliceCache{
 		trackerByServiceMap: map[apimachinerytypes.NamespacedName]*endpointSliceTracker{},
 		hostname:            hostname,
 		isIPv6Mode:          isIPv6Mode,
+		podLister:           podLister,
 	}
 }
 
@@ -121,7 +143,7 @@ func newEndpointSliceTracker() *endpointSliceTracker {
 }
 
 // newEndpointSliceInfo generates endpointSliceInfo from an EndpointSlice.
-func newEndpointSliceInfo(endpointSlice *discovery.EndpointSlice, remove bool) *endpointSliceInfo {
+func newEndpointSliceInfo(endpointSlice *discovery.EndpointSlice, remove bool, podLister corelisters.PodLister) *endpointSliceInfo {
 	esInfo := &endpointSliceInfo{
 		Ports:     make([]discovery.EndpointPort, len(endpointSlice.Ports)),
 		Endpoints: []*endpointInfo{},
@@ -133,13 +155,34 @@ func newEndpointSliceInfo(endpointSlice *discovery.EndpointSlice, remove bool) *
 	sort.Sort(byPort(esInfo.Ports))
 
 	if !remove {
+		sliceWeight := endpointSliceWeight(endpointSlice)
 		for _, endpoint := range endpointSlice.Endpoints {
-			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
-				esInfo.Endpoints = append(esInfo.Endpoints, &endpointInfo{
-					Addresses: endpoint.Addresses,
-					Topology:  endpoint.Topology,
-				})
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			terminating := endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating
+			// The terminating Endpoints are kept so that their existing connections are not broken, and so that
+			// they can be used as fallback Endpoints when there is no ready Endpoint.
+			if !ready && !terminating {
+				continue
+			}
+			weight := podEndpointWeight(endpointSlice.Namespace, endpoint.TargetRef, podLister)
+			if weight == 0 {
+				weight = sliceWeight
+			}
+			epInfo := &endpointInfo{
+				Addresses:   endpoint.Addresses,
+				Topology:    endpoint.Topology,
+				Ready:       ready,
+				Serving:     endpoint.Conditions.Serving == nil || *endpoint.Conditions.Serving,
+				Terminating: terminating,
+				Weight:      weight,
 			}
+			if endpoint.Hints != nil && len(endpoint.Hints.ForZones) > 0 {
+				epInfo.ZoneHints = sets.String{}
+				for _, zone := range endpoint.Hints.ForZones {
+					epInfo.ZoneHints.Insert(zone.Name)
+				}
+			}
+			esInfo.Endpoints = append(esInfo.Endpoints, epInfo)
 		}
 
 		sort.Sort(byAddress(esInfo.Endpoints))
@@ -148,6 +191,53 @@ func newEndpointSliceInfo(endpointSlice *discovery.EndpointSlice, remove bool) *
 	return esInfo
 }
 
+// endpointSliceWeight returns the weight of the Endpoints of an EndpointSlice, or 0 if the EndpointSlice doesn't have a
+// valid weight annotation.
+func endpointSliceWeight(endpointSlice *discovery.EndpointSlice) int {
+	value, ok := endpointSlice.Annotations[apis.EndpointSliceWeightAnnotationKey]
+	if !ok {
+		return 0
+	}
+	weight := parseEndpointWeight(value)
+	if weight == 0 {
+		klog.InfoS("Ignoring invalid weight of EndpointSlice", "EndpointSlice", klog.KObj(endpointSlice), "weight", value)
+	}
+	return weight
+}
+
+// podEndpointWeight returns the weight of an EndpointSlice Endpoint from the annotation of the Pod it references, or 0
+// if it doesn't reference a Pod or the Pod doesn't have a valid weight annotation.
+func podEndpointWeight(namespace string, targetRef *v1.ObjectReference, podLister corelisters.PodLister) int {
+	if podLister == nil || targetRef == nil || targetRef.Kind != "Pod" {
+		return 0
+	}
+	if targetRef.Namespace != "" {
+		namespace = targetRef.Namespace
+	}
+	pod, err := podLister.Pods(namespace).Get(targetRef.Name)
+	if err != nil {
+		return 0
+	}
+	value, ok := pod.Annotations[apis.PodEndpointWeightAnnotationKey]
+	if !ok {
+		return 0
+	}
+	weight := parseEndpointWeight(value)
+	if weight == 0 {
+		klog.InfoS("Ignoring invalid Endpoint weight of Pod", "Pod", klog.KObj(pod), "weight", value)
+	}
+	return weight
+}
+
+// parseEndpointWeight returns the weight specified by the value of a weight annotation, or 0 if it's invalid.
+func parseEndpointWeight(value string) int {
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 1 || weight > maxEndpointWeight {
+		return 0
+	}
+	return weight
+}
+
 // updatePending updates a pending slice in the cache.
 func (cache *EndpointSliceCache) updatePending(endpointSlice *discovery.EndpointSlice, remove bool) bool {
 	serviceKey, sliceKey, err := endpointSliceCacheKeys(endpointSlice)
@@ -156,7 +246,7 @@ func (cache *EndpointSliceCache) updatePending(endpointSlice *discovery.Endpoint
 		return false
 	}
 
-	esInfo := newEndpointSliceInfo(endpointSlice, remove)
+	esInfo := newEndpointSliceInfo(endpointSlice, remove, cache.podLister)
 
 	cache.lock.Lock()
 	defer cache.lock.Unlock()
@@ -263,7 +353,8 @@ func (cache *EndpointSliceCache) addEndpointsByIP(serviceNN apimachinerytypes.Na
 		}
 
 		isLocal := cache.isLocal(endpoint.Topology[v1.LabelHostname])
-		endpointInfo := proxy.NewBaseEndpointInfo(endpoint.Addresses[0], portNum, isLocal, endpoint.Topology)
+		endpointInfo := proxy.NewBaseEndpointInfo(endpoint.Addresses[0], portNum, isLocal, endpoint.Topology,
+			endpoint.Ready, endpoint.Serving, endpoint.Terminating, endpoint.ZoneHints, endpoint.Weight)
 
 		// This logic ensures we're deduping potential overlapping endpoints
 		// isLocal should not vary between matching IPs, but if it does, we
@@ -46,6 +46,16 @@ var (
 			Help:           "The number of Endpoints installed by AntreaProxy",
 		},
 	)
+	ServicesTruncatedTotal = kmetrics.NewGauge(
+		&kmetrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemProxy,
+			ConstLabels:    map[string]string{"ip_family": "v4"},
+			StabilityLevel: kmetrics.ALPHA,
+			Name:           "total_services_truncated",
+			Help:           "The number of Services whose Endpoints are truncated by AntreaProxy, as they exceed the maximum number of Endpoints of a Service",
+		},
+	)
 	ServicesUpdatesTotal = kmetrics.NewCounter(
 		&kmetrics.CounterOpts{
 			Namespace:      metricNamespaceAntrea,
@@ -97,6 +107,16 @@ var (
 			Help:           "The number of Endpoints installed by AntreaProxy",
 		},
 	)
+	ServicesTruncatedTotalV6 = kmetrics.NewGauge(
+		&kmetrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemProxy,
+			ConstLabels:    map[string]string{"ip_family": "v6"},
+			StabilityLevel: kmetrics.ALPHA,
+			Name:           "total_services_truncated",
+			Help:           "The number of Services whose Endpoints are truncated by AntreaProxy, as they exceed the maximum number of Endpoints of a Service",
+		},
+	)
 	ServicesUpdatesTotalV6 = kmetrics.NewCounter(
 		&kmetrics.CounterOpts{
 			Namespace:      metricNamespaceAntrea,
@@ -126,11 +146,13 @@ func Register() {
 			SyncProxyDuration,
 			ServicesInstalledTotal,
 			EndpointsInstalledTotal,
+			ServicesTruncatedTotal,
 			ServicesUpdatesTotal,
 			EndpointsUpdatesTotal,
 			SyncProxyDurationV6,
 			ServicesInstalledTotalV6,
 			EndpointsInstalledTotalV6,
+			ServicesTruncatedTotalV6,
 			ServicesUpdatesTotalV6,
 			EndpointsUpdatesTotalV6,
 		)
@@ -17,6 +17,7 @@ package proxy
 import (
 	"fmt"
 	"net"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -24,10 +25,16 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/discovery/v1beta1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/labels"
 	k8sapitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1beta1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
@@ -37,6 +44,8 @@ import (
 	"antrea.io/antrea/pkg/agent/proxy/metrics"
 	"antrea.io/antrea/pkg/agent/proxy/types"
 	"antrea.io/antrea/pkg/agent/route"
+	agenttypes "antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/apis"
 	"antrea.io/antrea/pkg/features"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	k8sproxy "antrea.io/antrea/third_party/proxy"
@@ -46,12 +55,14 @@ import (
 const (
 	resyncPeriod  = time.Minute
 	componentName = "antrea-agent-proxy"
-	// Due to the maximum message size in Openflow 1.3 and the implementation of Services in Antrea, the maximum number
-	// of Endpoints that Antrea can support at the moment is 800. If the number of Endpoints for a given Service exceeds
-	// 800, extra Endpoints will be dropped.
-	maxEndpoints = 800
 )
 
+// serviceGroupKey identifies the group of a Service which has all its Endpoints, or only its local Endpoints.
+type serviceGroupKey struct {
+	svcPortName      k8sproxy.ServicePortName
+	isEndpointsLocal bool
+}
+
 // Proxier wraps proxy.Provider and adds extra methods. It is introduced for
 // extending the proxy.Provider implementations with extra methods, without
 // modifying the proxy.Provider interface.
@@ -72,6 +83,7 @@ type proxier struct {
 	endpointSliceConfig *config.EndpointSliceConfig
 	endpointsConfig     *config.EndpointsConfig
 	serviceConfig       *config.ServiceConfig
+	nodeConfig          *config.NodeConfig
 	// endpointsChanges and serviceChanges contains all changes to endpoints and
 	// services that happened since last syncProxyRules call. For a single object,
 	// changes are accumulated. Once both endpointsChanges and serviceChanges
@@ -98,12 +110,20 @@ type proxier struct {
 	serviceStringMap map[string]k8sproxy.ServicePortName
 	// serviceStringMapMutex protects serviceStringMap object.
 	serviceStringMapMutex sync.Mutex
-	// oversizeServiceSet records the Services that have more than 800 Endpoints.
+	// oversizeServiceSet records the Services that have more Endpoints than the maximum number of Endpoints of their
+	// groups.
 	oversizeServiceSet sets.String
+	// serviceSubGroupCounts stores the number of sub-groups installed for the Service groups whose Endpoints are
+	// split across sub-groups.
+	serviceSubGroupCounts map[serviceGroupKey]int
 
 	// syncedOnce returns true if the proxier has synced rules at least once.
 	syncedOnce      bool
 	syncedOnceMutex sync.RWMutex
+	// nodeLabels stores the labels of the Node the proxier runs on. It is only
+	// maintained when topologyAwareHintsEnabled is true.
+	nodeLabels      map[string]string
+	nodeLabelsMutex sync.RWMutex
 
 	runner               *k8sproxy.BoundedFrequencyRunner
 	stopChan             <-chan struct{}
@@ -114,6 +134,20 @@ type proxier struct {
 	isIPv6               bool
 	proxyAll             bool
 	endpointSliceEnabled bool
+	// topologyAwareHintsEnabled indicates whether the zone hints of the Endpoints are taken into account for the
+	// Services annotated with "service.kubernetes.io/topology-aware-hints: auto".
+	topologyAwareHintsEnabled bool
+	// loadBalancerDSREnabled indicates whether the LoadBalancer traffic of the Services annotated with the DSR load
+	// balancer mode is forwarded to remote Endpoints with Direct Server Return.
+	loadBalancerDSREnabled bool
+	hostname               string
+	// serviceLister and recorder are used to report the Services whose annotations can't be honored.
+	serviceLister corelisters.ServiceLister
+	recorder      record.EventRecorder
+	// podListerSynced and endpointSliceLister are used to update the weights of the Endpoints when the weight
+	// annotation of their Pods changes. They are only set when endpointSliceEnabled is true.
+	podListerSynced     cache.InformerSynced
+	endpointSliceLister discoverylisters.EndpointSliceLister
 }
 
 func (p *proxier) SyncedOnce() bool {
@@ -160,18 +194,22 @@ func (p *proxier) removeStaleServices() {
 		}
 		// Remove LoadBalancer flows and configurations.
 		if len(svcInfo.LoadBalancerIPStrings()) > 0 {
-			if err := p.uninstallLoadBalancerService(svcInfo.LoadBalancerIPStrings(), uint16(svcInfo.Port()), svcInfo.OFProtocol); err != nil {
+			if err := p.uninstallLoadBalancerService(svcInfo.LoadBalancerIPStrings(), uint16(svcInfo.Port()), svcInfo.OFProtocol, p.serviceUsesDSR(svcInfo)); err != nil {
 				klog.ErrorS(err, "Failed to remove flows and configurations of Service", "Service", svcPortName)
 				continue
 			}
 		}
 		// Remove Service group whose Endpoints are local.
-		if svcInfo.NodeLocalExternal() {
+		if svcInfo.NodeLocalExternal() || p.serviceUsesDSR(svcInfo) {
 			groupIDLocal, _ := p.groupCounter.Get(svcPortName, true)
 			if err := p.ofClient.UninstallServiceGroup(groupIDLocal); err != nil {
 				klog.ErrorS(err, "Failed to remove flows of Service", "Service", svcPortName)
 				continue
 			}
+			if err := p.removeServiceSubGroups(serviceGroupKey{svcPortName, true}, 0); err != nil {
+				klog.ErrorS(err, "Failed to remove flows of Service", "Service", svcPortName)
+				continue
+			}
 			p.groupCounter.Recycle(svcPortName, true)
 		}
 		// Remove Service group which has all Endpoints.
@@ -180,6 +218,10 @@ func (p *proxier) removeStaleServices() {
 			klog.ErrorS(err, "Failed to remove flows of Service", "Service", svcPortName)
 			continue
 		}
+		if err := p.removeServiceSubGroups(serviceGroupKey{svcPortName, false}, 0); err != nil {
+			klog.ErrorS(err, "Failed to remove flows of Service", "Service", svcPortName)
+			continue
+		}
 
 		delete(p.serviceInstalledMap, svcPortName)
 		p.deleteServiceByIP(svcInfo.String())
@@ -307,12 +349,17 @@ func (p *proxier) uninstallNodePortService(svcPort uint16, protocol binding.Prot
 	return nil
 }
 
-func (p *proxier) installLoadBalancerService(groupID binding.GroupIDType, loadBalancerIPStrings []string, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, nodeLocalExternal bool) error {
+func (p *proxier) installLoadBalancerService(groupID, localGroupID binding.GroupIDType, loadBalancerIPStrings []string, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, nodeLocalExternal, dsr bool) error {
 	for _, ingress := range loadBalancerIPStrings {
 		if ingress != "" {
 			if err := p.ofClient.InstallServiceFlows(groupID, net.ParseIP(ingress), svcPort, protocol, affinityTimeout, nodeLocalExternal, corev1.ServiceTypeLoadBalancer); err != nil {
 				return fmt.Errorf("failed to install Service LoadBalancer load balancing flows: %w", err)
 			}
+			if dsr {
+				if err := p.ofClient.InstallLoadBalancerServiceDSRFlows(groupID, localGroupID, net.ParseIP(ingress), svcPort, protocol); err != nil {
+					return fmt.Errorf("failed to install Service LoadBalancer DSR flows: %w", err)
+				}
+			}
 			if err := p.ofClient.InstallLoadBalancerServiceFromOutsideFlows(net.ParseIP(ingress), svcPort, protocol); err != nil {
 				return fmt.Errorf("failed to install Service LoadBalancer flows: %w", err)
 			}
@@ -322,14 +369,24 @@ func (p *proxier) installLoadBalancerService(groupID binding.GroupIDType, loadBa
 		if err := p.routeClient.AddLoadBalancer(loadBalancerIPStrings); err != nil {
 			return fmt.Errorf("failed to install Service LoadBalancer traffic redirecting flows: %w", err)
 		}
+		if dsr {
+			if err := p.routeClient.AddLoadBalancerDSR(loadBalancerIPStrings, svcPort, protocol); err != nil {
+				return fmt.Errorf("failed to install Service LoadBalancer DSR configurations: %w", err)
+			}
+		}
 	}
 
 	return nil
 }
 
-func (p *proxier) uninstallLoadBalancerService(loadBalancerIPStrings []string, svcPort uint16, protocol binding.Protocol) error {
+func (p *proxier) uninstallLoadBalancerService(loadBalancerIPStrings []string, svcPort uint16, protocol binding.Protocol, dsr bool) error {
 	for _, ingress := range loadBalancerIPStrings {
 		if ingress != "" {
+			if dsr {
+				if err := p.ofClient.UninstallLoadBalancerServiceDSRFlows(net.ParseIP(ingress), svcPort, protocol); err != nil {
+					return fmt.Errorf("failed to remove Service LoadBalancer DSR flows: %w", err)
+				}
+			}
 			if err := p.ofClient.UninstallServiceFlows(net.ParseIP(ingress), svcPort, protocol); err != nil {
 				return fmt.Errorf("failed to remove Service LoadBalancer load balancing flows: %w", err)
 			}
@@ -342,6 +399,11 @@ func (p *proxier) uninstallLoadBalancerService(loadBalancerIPStrings []string, s
 		if err := p.routeClient.DeleteLoadBalancer(loadBalancerIPStrings); err != nil {
 			return fmt.Errorf("failed to remove Service LoadBalancer traffic redirecting flows: %w", err)
 		}
+		if dsr {
+			if err := p.routeClient.DeleteLoadBalancerDSR(loadBalancerIPStrings, svcPort, protocol); err != nil {
+				return fmt.Errorf("failed to remove Service LoadBalancer DSR configurations: %w", err)
+			}
+		}
 	}
 
 	return nil
@@ -361,6 +423,9 @@ func (p *proxier) installServices() {
 		if len(endpoints) == 0 && len(endpointsInstalled) == 0 {
 			continue
 		}
+		// Only the Endpoints selected by the internalTrafficPolicy and the topology aware hints of the Service are
+		// installed.
+		endpoints = p.filterEndpoints(svcInfo, endpoints)
 
 		installedSvcPort, ok := p.serviceInstalledMap[svcPortName]
 		var pSvcInfo *types.ServiceInfo
@@ -369,12 +434,28 @@ func (p *proxier) installServices() {
 			pSvcInfo = installedSvcPort.(*types.ServiceInfo)
 			needRemoval = serviceIdentityChanged(svcInfo, pSvcInfo) || (svcInfo.SessionAffinityType() != pSvcInfo.SessionAffinityType())
 			needUpdateService = needRemoval || (svcInfo.StickyMaxAgeSeconds() != pSvcInfo.StickyMaxAgeSeconds())
-			needUpdateEndpoints = pSvcInfo.SessionAffinityType() != svcInfo.SessionAffinityType()
+			needUpdateEndpoints = pSvcInfo.SessionAffinityType() != svcInfo.SessionAffinityType() || pSvcInfo.LBMode != svcInfo.LBMode
+			// The LoadBalancer flows and the group whose Endpoints are local must be updated when the DSR mode of the
+			// Service changes.
+			if p.serviceUsesDSR(pSvcInfo) != p.serviceUsesDSR(svcInfo) {
+				needRemoval = true
+				needUpdateService = true
+				needUpdateEndpoints = true
+			}
 		} else { // Need to install.
 			needUpdateService = true
 		}
+		if pSvcInfo == nil || pSvcInfo.LoadBalancerMode != svcInfo.LoadBalancerMode ||
+			len(pSvcInfo.ExternalIPStrings()) != len(svcInfo.ExternalIPStrings()) {
+			p.reportIgnoredLoadBalancerMode(svcPortName, svcInfo)
+		}
 
 		var endpointUpdateList []k8sproxy.Endpoint
+		// The Endpoints of a Service which don't fit in a single OVS group are split across sub-groups. If the number
+		// of Endpoints for a given Service exceeds the number which can be split across the maximum number of
+		// sub-groups, or the size of the lookup table of the ConsistentHash and SourceIPHash modes, extra Endpoints
+		// will be dropped.
+		maxEndpoints := openflow.ServiceMaxEndpoints(svcInfo.LBMode)
 		if len(endpoints) > maxEndpoints {
 			if !p.oversizeServiceSet.Has(svcPortName.String()) {
 				klog.Warningf("Since Endpoints of Service %s exceeds %d, extra Endpoints will be dropped", svcPortName.String(), maxEndpoints)
@@ -392,7 +473,7 @@ func (p *proxier) installServices() {
 			endpointList = endpointList[:maxEndpoints]
 
 			for _, endpoint := range endpointList { // Check if there is any installed Endpoint which is not expected anymore.
-				if _, ok := endpointsInstalled[endpoint.String()]; !ok { // There is an expected Endpoint which is not installed.
+				if !endpointInstalled(endpointsInstalled, endpoint) { // There is an expected Endpoint which is not installed.
 					needUpdateEndpoints = true
 				}
 				endpointUpdateList = append(endpointUpdateList, endpoint)
@@ -402,14 +483,16 @@ func (p *proxier) installServices() {
 				p.oversizeServiceSet.Delete(svcPortName.String())
 			}
 			for _, endpoint := range endpoints { // Check if there is any installed Endpoint which is not expected anymore.
-				if _, ok := endpointsInstalled[endpoint.String()]; !ok { // There is an expected Endpoint which is not installed.
+				if !endpointInstalled(endpointsInstalled, endpoint) { // There is an expected Endpoint which is not installed.
 					needUpdateEndpoints = true
 				}
 				endpointUpdateList = append(endpointUpdateList, endpoint)
 			}
 		}
 
-		if len(endpoints) < len(endpointsInstalled) { // There are Endpoints which expired.
+		// All the expected Endpoints are installed at this point unless needUpdateEndpoints is true, so there are
+		// installed Endpoints which are not expected anymore if there are more installed Endpoints than expected.
+		if len(endpointUpdateList) < len(endpointsInstalled) { // There are Endpoints which expired or were filtered out.
 			klog.V(2).Infof("Some Endpoints of Service %s removed, updating Endpoints", svcInfo.String())
 			needUpdateEndpoints = true
 		}
@@ -443,14 +526,15 @@ func (p *proxier) installServices() {
 				klog.ErrorS(err, "Error when installing Endpoints flows")
 				continue
 			}
-			err = p.ofClient.InstallServiceGroup(groupID, svcInfo.StickyMaxAgeSeconds() != 0, endpointUpdateList)
+			err = p.installServiceGroup(serviceGroupKey{svcPortName, false}, groupID, svcInfo.StickyMaxAgeSeconds() != 0, svcInfo.LBMode, endpointUpdateList)
 			if err != nil {
 				klog.ErrorS(err, "Error when installing Endpoints groups")
 				continue
 			}
 
-			// Install another group when Service externalTrafficPolicy is Local.
-			if p.proxyAll && svcInfo.NodeLocalExternal() {
+			// Install another group when Service externalTrafficPolicy is Local, or when the Service uses DSR mode,
+			// for the LoadBalancer traffic forwarded by other Nodes.
+			if p.proxyAll && (svcInfo.NodeLocalExternal() || p.serviceUsesDSR(svcInfo)) {
 				groupIDLocal, _ := p.groupCounter.Get(svcPortName, true)
 				var localEndpointList []k8sproxy.Endpoint
 				for _, ed := range endpointUpdateList {
@@ -459,19 +543,33 @@ func (p *proxier) installServices() {
 					}
 					localEndpointList = append(localEndpointList, ed)
 				}
-				if err = p.ofClient.InstallServiceGroup(groupIDLocal, svcInfo.StickyMaxAgeSeconds() != 0, localEndpointList); err != nil {
+				if err = p.installServiceGroup(serviceGroupKey{svcPortName, true}, groupIDLocal, svcInfo.StickyMaxAgeSeconds() != 0, svcInfo.LBMode, localEndpointList); err != nil {
 					klog.ErrorS(err, "Error when installing Group for Service whose externalTrafficPolicy is Local")
 					continue
 				}
 			}
 
+			endpointUpdateSet := make(map[string]struct{}, len(endpointUpdateList))
 			for _, e := range endpointUpdateList {
+				endpointUpdateSet[e.String()] = struct{}{}
 				// If the Endpoint is newly installed, add a reference.
 				if _, ok := endpointsInstalled[e.String()]; !ok {
 					key := endpointKey(e, svcInfo.OFProtocol)
 					p.endpointReferenceCounter[key] = p.endpointReferenceCounter[key] + 1
-					endpointsInstalled[e.String()] = e
 				}
+				// The conditions of the Endpoint may have changed.
+				endpointsInstalled[e.String()] = e
+			}
+			// Remove the installed Endpoints which are no longer in the group of the Service.
+			for name, e := range endpointsInstalled {
+				if _, ok := endpointUpdateSet[name]; ok {
+					continue
+				}
+				if _, err := p.removeEndpoint(e, getBindingProtoForIPProto(e.IP(), svcPortName.Protocol)); err != nil {
+					klog.ErrorS(err, "Error when removing Endpoint", "Endpoint", e, "Service", svcPortName)
+					continue
+				}
+				delete(endpointsInstalled, name)
 			}
 		}
 
@@ -546,14 +644,19 @@ func (p *proxier) installServices() {
 			}
 			// Remove LoadBalancer flows and configurations.
 			if len(toDelete) > 0 {
-				if err := p.uninstallLoadBalancerService(toDelete, uint16(pSvcInfo.Port()), pSvcInfo.OFProtocol); err != nil {
+				if err := p.uninstallLoadBalancerService(toDelete, uint16(pSvcInfo.Port()), pSvcInfo.OFProtocol, p.serviceUsesDSR(pSvcInfo)); err != nil {
 					klog.ErrorS(err, "Failed to remove flows and configurations of Service", "Service", svcPortName)
 					continue
 				}
 			}
 			// Install LoadBalancer flows and configurations.
 			if len(toAdd) > 0 {
-				if err := p.installLoadBalancerService(nGroupID, toAdd, uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(svcInfo.StickyMaxAgeSeconds()), svcInfo.NodeLocalExternal()); err != nil {
+				var localGroupID binding.GroupIDType
+				dsr := p.serviceUsesDSR(svcInfo)
+				if dsr {
+					localGroupID, _ = p.groupCounter.Get(svcPortName, true)
+				}
+				if err := p.installLoadBalancerService(nGroupID, localGroupID, toAdd, uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(svcInfo.StickyMaxAgeSeconds()), svcInfo.NodeLocalExternal(), dsr); err != nil {
 					klog.ErrorS(err, "Failed to install LoadBalancer flows and configurations of Service", "Service", svcPortName)
 					continue
 				}
@@ -565,6 +668,116 @@ func (p *proxier) installServices() {
 	}
 }
 
+// serviceUsesDSR returns whether the LoadBalancer traffic of the Service is forwarded to remote Endpoints with Direct
+// Server Return. DSR is only used when the traffic can be load balanced across the Endpoints of all the Nodes, and when
+// the Endpoint selection doesn't depend on the previous connections of the clients, as the Node forwarding the traffic
+// doesn't see the replies. The Services with externalIPs are rejected, as AntreaProxy doesn't handle the traffic to
+// the externalIPs, which would be sent to the Endpoints without being load balanced in DSR mode.
+func (p *proxier) serviceUsesDSR(svcInfo *types.ServiceInfo) bool {
+	return p.loadBalancerDSREnabled &&
+		svcInfo.LoadBalancerMode == agenttypes.LoadBalancerModeDSR &&
+		len(svcInfo.ExternalIPStrings()) == 0 &&
+		!svcInfo.NodeLocalExternal() &&
+		svcInfo.SessionAffinityType() != corev1.ServiceAffinityClientIP
+}
+
+// reportIgnoredLoadBalancerMode reports the Services annotated with the DSR load balancer mode which are load balanced
+// with NAT because DSR is not supported for them: a warning is logged for the IPv6 Services, and an event is recorded
+// for the Services with externalIPs.
+func (p *proxier) reportIgnoredLoadBalancerMode(svcPortName k8sproxy.ServicePortName, svcInfo *types.ServiceInfo) {
+	if svcInfo.LoadBalancerMode != agenttypes.LoadBalancerModeDSR {
+		return
+	}
+	if p.isIPv6 {
+		klog.Warningf("Ignoring the DSR load balancer mode of Service %s for IPv6, DSR is only supported for IPv4", svcPortName)
+		return
+	}
+	if !p.loadBalancerDSREnabled || len(svcInfo.ExternalIPStrings()) == 0 {
+		return
+	}
+	klog.Warningf("Ignoring the DSR load balancer mode of Service %s, DSR is not supported for Services with externalIPs", svcPortName)
+	svc, err := p.serviceLister.Services(svcPortName.Namespace).Get(svcPortName.Name)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Service to record event", "Service", svcPortName)
+		return
+	}
+	p.recorder.Eventf(svc, corev1.EventTypeWarning, "LoadBalancerModeNotSupported",
+		"The DSR load balancer mode is not supported for Services with externalIPs, the Service is load balanced with NAT")
+}
+
+// installServiceGroup installs the group of a Service. When the Endpoints don't fit in a single group, they are split
+// across sub-groups, which are sorted so that the Endpoints stay in the same sub-groups across updates. The sub-groups
+// which are not needed anymore are removed once the group doesn't use them.
+func (p *proxier) installServiceGroup(key serviceGroupKey, groupID binding.GroupIDType, withSessionAffinity bool, lbMode agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) error {
+	subGroupCount := openflow.ServiceSubGroupCount(len(endpoints), lbMode)
+	if subGroupCount == 0 {
+		if err := p.ofClient.InstallServiceGroup(groupID, withSessionAffinity, lbMode, endpoints); err != nil {
+			return err
+		}
+	} else {
+		subGroupIDs := make([]binding.GroupIDType, subGroupCount)
+		for i := range subGroupIDs {
+			subGroupIDs[i], _ = p.groupCounter.GetSubGroup(key.svcPortName, key.isEndpointsLocal, i)
+		}
+		sortedEndpoints := make([]k8sproxy.Endpoint, len(endpoints))
+		copy(sortedEndpoints, endpoints)
+		sort.Sort(byEndpoint(sortedEndpoints))
+		if err := p.ofClient.InstallServiceGroupWithSubGroups(groupID, subGroupIDs, withSessionAffinity, lbMode, sortedEndpoints); err != nil {
+			return err
+		}
+		if subGroupCount > p.serviceSubGroupCounts[key] {
+			p.serviceSubGroupCounts[key] = subGroupCount
+		}
+	}
+	return p.removeServiceSubGroups(key, subGroupCount)
+}
+
+// removeServiceSubGroups removes the sub-groups of a Service group from the given index.
+func (p *proxier) removeServiceSubGroups(key serviceGroupKey, from int) error {
+	for i := p.serviceSubGroupCounts[key] - 1; i >= from; i-- {
+		subGroupID, _ := p.groupCounter.GetSubGroup(key.svcPortName, key.isEndpointsLocal, i)
+		if err := p.ofClient.UninstallServiceGroup(subGroupID); err != nil {
+			return err
+		}
+		p.groupCounter.RecycleSubGroup(key.svcPortName, key.isEndpointsLocal, i)
+		p.serviceSubGroupCounts[key] = i
+	}
+	if p.serviceSubGroupCounts[key] == 0 {
+		delete(p.serviceSubGroupCounts, key)
+	}
+	return nil
+}
+
+// endpointInstalled returns whether the Endpoint is installed with its current conditions. An Endpoint whose
+// conditions changed must be updated, as the conditions decide whether new connections are load-balanced to it.
+func endpointInstalled(endpointsInstalled map[string]k8sproxy.Endpoint, endpoint k8sproxy.Endpoint) bool {
+	installed, ok := endpointsInstalled[endpoint.String()]
+	return ok && installed.Equal(endpoint)
+}
+
+// filterEndpoints returns the Endpoints of a Service selected by its internalTrafficPolicy and, when TopologyAwareHints
+// is enabled, by the zone hints of the Endpoints. All the Endpoints are returned when none of them is selected.
+func (p *proxier) filterEndpoints(svcInfo *types.ServiceInfo, endpoints map[string]k8sproxy.Endpoint) map[string]k8sproxy.Endpoint {
+	if len(endpoints) == 0 {
+		return endpoints
+	}
+	endpointList := make([]k8sproxy.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpointList = append(endpointList, endpoint)
+	}
+	p.nodeLabelsMutex.RLock()
+	filteredEndpointList := k8sproxy.FilterEndpoints(endpointList, svcInfo, p.nodeLabels, p.topologyAwareHintsEnabled)
+	p.nodeLabelsMutex.RUnlock()
+	if len(filteredEndpointList) == len(endpointList) {
+		return endpoints
+	}
+	filteredEndpoints := make(map[string]k8sproxy.Endpoint, len(filteredEndpointList))
+	for _, endpoint := range filteredEndpointList {
+		filteredEndpoints[endpoint.String()] = endpoint
+	}
+	return filteredEndpoints
+}
+
 // syncProxyRules applies current changes in change trackers and then updates
 // flows for services and endpoints. It will return immediately if either
 // endpoints or services resources are not synced. syncProxyRules is only called
@@ -608,9 +821,11 @@ func (p *proxier) syncProxyRules() {
 	if p.isIPv6 {
 		metrics.ServicesInstalledTotalV6.Set(float64(len(p.serviceMap)))
 		metrics.EndpointsInstalledTotalV6.Set(float64(counter))
+		metrics.ServicesTruncatedTotalV6.Set(float64(p.oversizeServiceSet.Len()))
 	} else {
 		metrics.ServicesInstalledTotal.Set(float64(len(p.serviceMap)))
 		metrics.EndpointsInstalledTotal.Set(float64(counter))
+		metrics.ServicesTruncatedTotal.Set(float64(p.oversizeServiceSet.Len()))
 	}
 
 	p.syncedOnceMutex.Lock()
@@ -673,6 +888,100 @@ func (p *proxier) OnEndpointSlicesSynced() {
 	}
 }
 
+func (p *proxier) OnNodeAdd(node *corev1.Node) {
+	p.OnNodeUpdate(nil, node)
+}
+
+func (p *proxier) OnNodeUpdate(oldNode, node *corev1.Node) {
+	if node.Name != p.hostname {
+		return
+	}
+	p.nodeLabelsMutex.Lock()
+	if reflect.DeepEqual(p.nodeLabels, node.Labels) {
+		p.nodeLabelsMutex.Unlock()
+		return
+	}
+	p.nodeLabels = make(map[string]string, len(node.Labels))
+	for k, v := range node.Labels {
+		p.nodeLabels[k] = v
+	}
+	p.nodeLabelsMutex.Unlock()
+	klog.V(4).InfoS("Updated proxier Node labels", "labels", node.Labels)
+	if p.isInitialized() {
+		p.runner.Run()
+	}
+}
+
+func (p *proxier) OnNodeDelete(node *corev1.Node) {
+	if node.Name != p.hostname {
+		return
+	}
+	p.nodeLabelsMutex.Lock()
+	p.nodeLabels = nil
+	p.nodeLabelsMutex.Unlock()
+	if p.isInitialized() {
+		p.runner.Run()
+	}
+}
+
+func (p *proxier) OnNodeSynced() {
+}
+
+func (p *proxier) onPodAdd(obj interface{}) {
+	p.onPodWeightUpdate(nil, obj.(*corev1.Pod))
+}
+
+func (p *proxier) onPodUpdate(oldObj, newObj interface{}) {
+	p.onPodWeightUpdate(oldObj.(*corev1.Pod), newObj.(*corev1.Pod))
+}
+
+func (p *proxier) onPodDelete(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		pod, ok = deletedState.Obj.(*corev1.Pod)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-Pod object: %v", deletedState.Obj)
+			return
+		}
+	}
+	p.onPodWeightUpdate(pod, nil)
+}
+
+// onPodWeightUpdate updates the EndpointSlices which reference a Pod when the Endpoint weight annotation of the Pod
+// changes, so that the weight of its Endpoints is read again.
+func (p *proxier) onPodWeightUpdate(oldPod, newPod *corev1.Pod) {
+	var oldWeight, newWeight string
+	pod := newPod
+	if oldPod != nil {
+		oldWeight = oldPod.Annotations[apis.PodEndpointWeightAnnotationKey]
+		pod = oldPod
+	}
+	if newPod != nil {
+		newWeight = newPod.Annotations[apis.PodEndpointWeightAnnotationKey]
+	}
+	if oldWeight == newWeight {
+		return
+	}
+	endpointSlices, err := p.endpointSliceLister.EndpointSlices(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list EndpointSlices", "namespace", pod.Namespace)
+		return
+	}
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" && endpoint.TargetRef.Name == pod.Name {
+				p.OnEndpointSliceUpdate(nil, endpointSlice)
+				break
+			}
+		}
+	}
+}
+
 func (p *proxier) OnServiceAdd(service *corev1.Service) {
 	p.OnServiceUpdate(nil, service)
 }
@@ -726,8 +1035,18 @@ func (p *proxier) deleteServiceByIP(serviceStr string) {
 func (p *proxier) Run(stopCh <-chan struct{}) {
 	p.once.Do(func() {
 		go p.serviceConfig.Run(stopCh)
+		if p.nodeConfig != nil {
+			go p.nodeConfig.Run(stopCh)
+		}
 		if p.endpointSliceEnabled {
-			go p.endpointSliceConfig.Run(stopCh)
+			go func() {
+				// Wait for the Pods, so that the weights of the Endpoints are read from their Pods when the
+				// EndpointSlices are first synced.
+				if !cache.WaitForNamedCacheSync(componentName, stopCh, p.podListerSynced) {
+					return
+				}
+				p.endpointSliceConfig.Run(stopCh)
+			}()
 		} else {
 			go p.endpointsConfig.Run(stopCh)
 		}
@@ -763,7 +1082,7 @@ func (p *proxier) GetServiceFlowKeys(serviceName, namespace string) ([]string, [
 		svcInfo := installedSvcPort.(*types.ServiceInfo)
 
 		var epList []k8sproxy.Endpoint
-		endpoints, ok := p.endpointsMap[svcPortName]
+		endpoints, ok := p.endpointsInstalledMap[svcPortName]
 		if ok && len(endpoints) > 0 {
 			epList = make([]k8sproxy.Endpoint, 0, len(endpoints))
 			for _, ep := range endpoints {
@@ -785,6 +1104,7 @@ func (p *proxier) GetServiceFlowKeys(serviceName, namespace string) ([]string, [
 func NewProxier(
 	hostname string,
 	informerFactory informers.SharedInformerFactory,
+	k8sClient clientset.Interface,
 	ofClient openflow.Client,
 	isIPv6 bool,
 	routeClient route.Interface,
@@ -792,38 +1112,57 @@ func NewProxier(
 	proxyAllEnabled bool,
 	skipServices []string,
 	groupCounter types.GroupCounter) *proxier {
-	recorder := record.NewBroadcaster().NewRecorder(
-		runtime.NewScheme(),
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: k8sClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(
+		scheme.Scheme,
 		corev1.EventSource{Component: componentName, Host: hostname},
 	)
 	metrics.Register()
 	klog.V(2).Infof("Creating proxier with IPv6 enabled=%t", isIPv6)
 
 	endpointSliceEnabled := features.DefaultFeatureGate.Enabled(features.EndpointSlice)
+	// The zone hints are only available in EndpointSlices.
+	topologyAwareHintsEnabled := endpointSliceEnabled && features.DefaultFeatureGate.Enabled(features.TopologyAwareHints)
+	// The LoadBalancer traffic is only forwarded with DSR for IPv4, when AntreaProxy handles the traffic from outside
+	// the cluster.
+	loadBalancerDSREnabled := proxyAllEnabled && !isIPv6 && features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR)
 	ipFamily := corev1.IPv4Protocol
 	if isIPv6 {
 		ipFamily = corev1.IPv6Protocol
 	}
 
+	// The weights of the Endpoints are read from the annotations of their Pods, which are referenced by EndpointSlices.
+	var podLister corelisters.PodLister
+	if endpointSliceEnabled {
+		podLister = informerFactory.Core().V1().Pods().Lister()
+	}
+
 	p := &proxier{
-		endpointsConfig:          config.NewEndpointsConfig(informerFactory.Core().V1().Endpoints(), resyncPeriod),
-		serviceConfig:            config.NewServiceConfig(informerFactory.Core().V1().Services(), resyncPeriod),
-		endpointsChanges:         newEndpointsChangesTracker(hostname, endpointSliceEnabled, isIPv6),
-		serviceChanges:           newServiceChangesTracker(recorder, ipFamily, skipServices),
-		serviceMap:               k8sproxy.ServiceMap{},
-		serviceInstalledMap:      k8sproxy.ServiceMap{},
-		endpointsInstalledMap:    types.EndpointsMap{},
-		endpointsMap:             types.EndpointsMap{},
-		endpointReferenceCounter: map[string]int{},
-		serviceStringMap:         map[string]k8sproxy.ServicePortName{},
-		oversizeServiceSet:       sets.NewString(),
-		groupCounter:             groupCounter,
-		ofClient:                 ofClient,
-		routeClient:              routeClient,
-		nodePortAddresses:        nodePortAddresses,
-		isIPv6:                   isIPv6,
-		proxyAll:                 proxyAllEnabled,
-		endpointSliceEnabled:     endpointSliceEnabled,
+		endpointsConfig:           config.NewEndpointsConfig(informerFactory.Core().V1().Endpoints(), resyncPeriod),
+		serviceConfig:             config.NewServiceConfig(informerFactory.Core().V1().Services(), resyncPeriod),
+		endpointsChanges:          newEndpointsChangesTracker(hostname, endpointSliceEnabled, isIPv6, podLister),
+		serviceChanges:            newServiceChangesTracker(recorder, ipFamily, skipServices),
+		serviceMap:                k8sproxy.ServiceMap{},
+		serviceInstalledMap:       k8sproxy.ServiceMap{},
+		endpointsInstalledMap:     types.EndpointsMap{},
+		endpointsMap:              types.EndpointsMap{},
+		endpointReferenceCounter:  map[string]int{},
+		serviceStringMap:          map[string]k8sproxy.ServicePortName{},
+		oversizeServiceSet:        sets.NewString(),
+		serviceSubGroupCounts:     map[serviceGroupKey]int{},
+		groupCounter:              groupCounter,
+		ofClient:                  ofClient,
+		routeClient:               routeClient,
+		nodePortAddresses:         nodePortAddresses,
+		isIPv6:                    isIPv6,
+		proxyAll:                  proxyAllEnabled,
+		endpointSliceEnabled:      endpointSliceEnabled,
+		topologyAwareHintsEnabled: topologyAwareHintsEnabled,
+		loadBalancerDSREnabled:    loadBalancerDSREnabled,
+		hostname:                  hostname,
+		serviceLister:             informerFactory.Core().V1().Services().Lister(),
+		recorder:                  recorder,
 	}
 
 	p.serviceConfig.RegisterEventHandler(p)
@@ -832,10 +1171,22 @@ func NewProxier(
 	if endpointSliceEnabled {
 		p.endpointSliceConfig = config.NewEndpointSliceConfig(informerFactory.Discovery().V1beta1().EndpointSlices(), resyncPeriod)
 		p.endpointSliceConfig.RegisterEventHandler(p)
+		p.endpointSliceLister = informerFactory.Discovery().V1beta1().EndpointSlices().Lister()
+		podInformer := informerFactory.Core().V1().Pods().Informer()
+		podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    p.onPodAdd,
+			UpdateFunc: p.onPodUpdate,
+			DeleteFunc: p.onPodDelete,
+		})
+		p.podListerSynced = podInformer.HasSynced
 	} else {
 		p.endpointsConfig = config.NewEndpointsConfig(informerFactory.Core().V1().Endpoints(), resyncPeriod)
 		p.endpointsConfig.RegisterEventHandler(p)
 	}
+	if topologyAwareHintsEnabled {
+		p.nodeConfig = config.NewNodeConfig(informerFactory.Core().V1().Nodes(), resyncPeriod)
+		p.nodeConfig.RegisterEventHandler(p)
+	}
 	return p
 }
 
@@ -871,6 +1222,7 @@ func (p *metaProxierWrapper) GetServiceByIP(serviceStr string) (k8sproxy.Service
 func NewDualStackProxier(
 	hostname string,
 	informerFactory informers.SharedInformerFactory,
+	k8sClient clientset.Interface,
 	ofClient openflow.Client,
 	routeClient route.Interface,
 	nodePortAddressesIPv4 []net.IP,
@@ -881,10 +1233,10 @@ func NewDualStackProxier(
 	v6groupCounter types.GroupCounter) *metaProxierWrapper {
 
 	// Create an IPv4 instance of the single-stack proxier.
-	ipv4Proxier := NewProxier(hostname, informerFactory, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAllEnabled, skipServices, v4groupCounter)
+	ipv4Proxier := NewProxier(hostname, informerFactory, k8sClient, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAllEnabled, skipServices, v4groupCounter)
 
 	// Create an IPv6 instance of the single-stack proxier.
-	ipv6Proxier := NewProxier(hostname, informerFactory, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAllEnabled, skipServices, v6groupCounter)
+	ipv6Proxier := NewProxier(hostname, informerFactory, k8sClient, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAllEnabled, skipServices, v6groupCounter)
 
 	// Create a meta-proxier that dispatch calls between the two
 	// single-stack proxier instances.
@@ -22,11 +22,16 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/component-base/metrics/testutil"
 
@@ -36,6 +41,8 @@ import (
 	"antrea.io/antrea/pkg/agent/proxy/types"
 	"antrea.io/antrea/pkg/agent/route"
 	routemock "antrea.io/antrea/pkg/agent/route/testing"
+	agenttypes "antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/apis"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	k8sproxy "antrea.io/antrea/third_party/proxy"
 )
@@ -113,7 +120,7 @@ func NewFakeProxier(routeClient route.Interface, ofClient openflow.Client, nodeP
 	}
 
 	p := &proxier{
-		endpointsChanges:         newEndpointsChangesTracker(hostname, false, isIPv6),
+		endpointsChanges:         newEndpointsChangesTracker(hostname, false, isIPv6, nil),
 		serviceChanges:           newServiceChangesTracker(recorder, ipFamily, []string{"kube-system/kube-dns", "192.168.1.2"}),
 		serviceMap:               k8sproxy.ServiceMap{},
 		serviceInstalledMap:      k8sproxy.ServiceMap{},
@@ -121,12 +128,16 @@ func NewFakeProxier(routeClient route.Interface, ofClient openflow.Client, nodeP
 		endpointReferenceCounter: map[string]int{},
 		endpointsMap:             types.EndpointsMap{},
 		groupCounter:             types.NewGroupCounter(isIPv6, make(chan string, 100)),
+		oversizeServiceSet:       sets.NewString(),
+		serviceSubGroupCounts:    map[serviceGroupKey]int{},
 		ofClient:                 ofClient,
 		routeClient:              routeClient,
 		serviceStringMap:         map[string]k8sproxy.ServicePortName{},
 		isIPv6:                   isIPv6,
 		nodePortAddresses:        nodePortAddresses,
 		proxyAll:                 proxyAllEnabled,
+		hostname:                 hostname,
+		recorder:                 recorder,
 	}
 	p.runner = k8sproxy.NewBoundedFrequencyRunner(componentName, p.syncProxyRules, time.Second, 30*time.Second, 2)
 	return p
@@ -171,7 +182,7 @@ func testClusterIP(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool, extraSv
 	makeEndpointsMap(fp, allEps...)
 
 	groupID, _ := fp.groupCounter.Get(svcPortName, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	bindingProtocol := binding.ProtocolTCP
 	if isIPv6 {
 		bindingProtocol = binding.ProtocolTCPv6
@@ -257,12 +268,12 @@ func testLoadBalancer(t *testing.T, nodePortAddresses []net.IP, svcIP, ep1IP, ep
 	if isIPv6 {
 		bindingProtocol = binding.ProtocolTCPv6
 	}
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
 	if nodeLocalExternal {
 		groupID, _ = fp.groupCounter.Get(svcPortName, true)
-		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	}
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeLoadBalancer).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
@@ -345,12 +356,12 @@ func testNodePort(t *testing.T, nodePortAddresses []net.IP, svcIP, ep1IP, ep2IP
 	if isIPv6 {
 		bindingProtocol = binding.ProtocolTCPv6
 	}
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
 	if nodeLocalExternal {
 		groupID, _ = fp.groupCounter.Get(svcPortName, true)
-		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	}
 
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
@@ -524,11 +535,11 @@ func TestDualStackService(t *testing.T) {
 	groupIDv4, _ := fpv4.groupCounter.Get(svcPortName, false)
 	groupIDv6, _ := fpv6.groupCounter.Get(svcPortName, false)
 
-	mockOFClient.EXPECT().InstallServiceGroup(groupIDv4, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupIDv4, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupIDv4, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
 
-	mockOFClient.EXPECT().InstallServiceGroup(groupIDv6, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupIDv6, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCPv6, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupIDv6, svcIPv6, uint16(svcPort), binding.ProtocolTCPv6, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
 
@@ -579,7 +590,7 @@ func testClusterIPRemoval(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool)
 	ep := makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, epFunc)
 	makeEndpointsMap(fp, ep)
 	groupID, _ := fp.groupCounter.Get(svcPortName, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
 	mockRouteClient.EXPECT().AddClusterIPRoute(svcIP).Times(1)
@@ -711,8 +722,8 @@ func testClusterIPRemoveSamePortEndpoint(t *testing.T, svcIP net.IP, epIP net.IP
 
 	groupID, _ := fp.groupCounter.Get(svcPortName, false)
 	groupIDUDP, _ := fp.groupCounter.Get(svcPortNameUDP, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
-	mockOFClient.EXPECT().InstallServiceGroup(groupIDUDP, false, gomock.Any()).Times(2)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupIDUDP, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallEndpointFlows(protocolTCP, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(protocolUDP, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), protocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
@@ -774,7 +785,7 @@ func testClusterIPRemoveEndpoints(t *testing.T, svcIP net.IP, epIP net.IP, isIPv
 	}
 	makeEndpointsMap(fp, ep)
 	groupID, _ := fp.groupCounter.Get(svcPortName, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(2)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
 	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
@@ -846,7 +857,7 @@ func testSessionAffinityNoEndpoint(t *testing.T, svcExternalIPs net.IP, svcIP ne
 		bindingProtocol = binding.ProtocolTCPv6
 	}
 	groupID, _ := fp.groupCounter.Get(svcPortName, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, true, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, true, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(corev1.DefaultClientIPServiceAffinitySeconds), false, corev1.ServiceTypeClusterIP).Times(1)
 
@@ -954,7 +965,7 @@ func testPortChange(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool) {
 	ep := makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, epFunc)
 	makeEndpointsMap(fp, ep)
 	groupID, _ := fp.groupCounter.Get(svcPortName, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort1), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP)
 	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort1), bindingProtocol)
@@ -1041,8 +1052,8 @@ func TestServicesWithSameEndpoints(t *testing.T) {
 
 	groupID1, _ := fp.groupCounter.Get(svcPortName1, false)
 	groupID2, _ := fp.groupCounter.Get(svcPortName2, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID1, false, gomock.Any()).Times(1)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID2, false, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID1, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID2, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
 	bindingProtocol := binding.ProtocolTCP
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallServiceFlows(groupID1, svcIP1, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
@@ -1118,3 +1129,792 @@ func TestMetrics(t *testing.T) {
 		})
 	}
 }
+
+func getEndpointStrings(endpoints []k8sproxy.Endpoint) []string {
+	var endpointStrings []string
+	for _, endpoint := range endpoints {
+		endpointStrings = append(endpointStrings, endpoint.String())
+	}
+	return endpointStrings
+}
+
+func TestInternalTrafficPolicy(t *testing.T) {
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	localNodeName := "localhost"
+	remoteNodeName := "remote"
+	internalTrafficPolicyLocal := corev1.ServiceInternalTrafficPolicyLocal
+	internalTrafficPolicyCluster := corev1.ServiceInternalTrafficPolicyCluster
+	tests := []struct {
+		name                  string
+		internalTrafficPolicy *corev1.ServiceInternalTrafficPolicyType
+		ep1NodeName           *string
+		expectedEndpoints     []string
+	}{
+		{
+			name:                  "Local with local Endpoint",
+			internalTrafficPolicy: &internalTrafficPolicyLocal,
+			ep1NodeName:           &localNodeName,
+			expectedEndpoints:     []string{"10.180.0.1:80"},
+		},
+		{
+			name:                  "Local without local Endpoint",
+			internalTrafficPolicy: &internalTrafficPolicyLocal,
+			ep1NodeName:           &remoteNodeName,
+			expectedEndpoints:     []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+		{
+			name:                  "Cluster",
+			internalTrafficPolicy: &internalTrafficPolicyCluster,
+			ep1NodeName:           &localNodeName,
+			expectedEndpoints:     []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockOFClient := ofmock.NewMockClient(ctrl)
+			mockRouteClient := routemock.NewMockInterface(ctrl)
+			fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+
+			makeServiceMap(fp, makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+				svc.Spec.ClusterIP = svcIPv4.String()
+				svc.Spec.InternalTrafficPolicy = tt.internalTrafficPolicy
+				svc.Spec.Ports = []corev1.ServicePort{{
+					Name:     svcPortName.Port,
+					Port:     int32(svcPort),
+					Protocol: corev1.ProtocolTCP,
+				}}
+			}))
+			makeEndpointsMap(fp, makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+				ept.Subsets = []corev1.EndpointSubset{{
+					Addresses: []corev1.EndpointAddress{
+						{IP: ep1IPv4.String(), NodeName: tt.ep1NodeName},
+						{IP: ep2IPv4.String(), NodeName: &remoteNodeName},
+					},
+					Ports: []corev1.EndpointPort{{
+						Name:     svcPortName.Port,
+						Port:     int32(svcPort),
+						Protocol: corev1.ProtocolTCP,
+					}},
+				}}
+			}))
+
+			groupID, _ := fp.groupCounter.Get(svcPortName, false)
+			mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+			mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+				assert.ElementsMatch(t, tt.expectedEndpoints, getEndpointStrings(endpoints))
+			}).Times(1)
+			mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+
+			fp.syncProxyRules()
+		})
+	}
+}
+
+func TestInternalTrafficPolicyWithTerminatingEndpoints(t *testing.T) {
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	localNodeName := "localhost"
+	remoteNodeName := "remote"
+	internalTrafficPolicyLocal := corev1.ServiceInternalTrafficPolicyLocal
+	ready, notReady, terminating := true, false, true
+	makeEndpoint := func(ip string, nodeName string, isReady bool) discovery.Endpoint {
+		endpoint := discovery.Endpoint{
+			Addresses:  []string{ip},
+			Topology:   map[string]string{corev1.LabelHostname: nodeName},
+			Conditions: discovery.EndpointConditions{Ready: &ready, Serving: &ready},
+		}
+		if !isReady {
+			endpoint.Conditions = discovery.EndpointConditions{Ready: &notReady, Serving: &ready, Terminating: &terminating}
+		}
+		return endpoint
+	}
+	tests := []struct {
+		name              string
+		endpoints         []discovery.Endpoint
+		expectedEndpoints []string
+	}{
+		{
+			name: "ready local Endpoint",
+			endpoints: []discovery.Endpoint{
+				makeEndpoint("10.180.0.1", localNodeName, true),
+				makeEndpoint("10.180.0.2", remoteNodeName, true),
+				makeEndpoint("10.180.0.3", localNodeName, false),
+			},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.3:80"},
+		},
+		{
+			name: "terminating local Endpoint only",
+			endpoints: []discovery.Endpoint{
+				makeEndpoint("10.180.0.1", localNodeName, false),
+				makeEndpoint("10.180.0.2", remoteNodeName, true),
+			},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+		{
+			name: "terminating Endpoints only",
+			endpoints: []discovery.Endpoint{
+				makeEndpoint("10.180.0.1", localNodeName, false),
+				makeEndpoint("10.180.0.2", remoteNodeName, false),
+			},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockOFClient := ofmock.NewMockClient(ctrl)
+			mockRouteClient := routemock.NewMockInterface(ctrl)
+			fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+			fp.endpointsChanges = newEndpointsChangesTracker(fp.hostname, true, false, nil)
+			fp.endpointSliceEnabled = true
+
+			makeServiceMap(fp, makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+				svc.Spec.ClusterIP = svcIPv4.String()
+				svc.Spec.InternalTrafficPolicy = &internalTrafficPolicyLocal
+				svc.Spec.Ports = []corev1.ServicePort{{
+					Name:     svcPortName.Port,
+					Port:     int32(svcPort),
+					Protocol: corev1.ProtocolTCP,
+				}}
+			}))
+			portName := svcPortName.Port
+			portNumber := int32(svcPort)
+			protocol := corev1.ProtocolTCP
+			fp.endpointsChanges.OnEndpointSliceUpdate(&discovery.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      svcPortName.Name + "-1",
+					Namespace: svcPortName.Namespace,
+					Labels:    map[string]string{discovery.LabelServiceName: svcPortName.Name},
+				},
+				AddressType: discovery.AddressTypeIPv4,
+				Endpoints:   tt.endpoints,
+				Ports: []discovery.EndpointPort{{
+					Name:     &portName,
+					Port:     &portNumber,
+					Protocol: &protocol,
+				}},
+			}, false)
+			fp.endpointsChanges.OnEndpointsSynced()
+
+			groupID, _ := fp.groupCounter.Get(svcPortName, false)
+			mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+			mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+				assert.ElementsMatch(t, tt.expectedEndpoints, getEndpointStrings(endpoints))
+			}).Times(1)
+			mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+
+			fp.syncProxyRules()
+		})
+	}
+}
+
+func TestTopologyAwareHints(t *testing.T) {
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	notReady, serving, terminating := false, true, true
+	makeEndpoint := func(ip net.IP, zone string) discovery.Endpoint {
+		endpoint := discovery.Endpoint{Addresses: []string{ip.String()}}
+		if zone != "" {
+			endpoint.Hints = &discovery.EndpointHints{ForZones: []discovery.ForZone{{Name: zone}}}
+		}
+		return endpoint
+	}
+	tests := []struct {
+		name              string
+		hintsAnnotation   string
+		nodeZone          string
+		endpoints         []discovery.Endpoint
+		expectedEndpoints []string
+	}{
+		{
+			name:              "Endpoint in local zone",
+			hintsAnnotation:   "Auto",
+			nodeZone:          "zone-a",
+			endpoints:         []discovery.Endpoint{makeEndpoint(ep1IPv4, "zone-a"), makeEndpoint(ep2IPv4, "zone-b")},
+			expectedEndpoints: []string{"10.180.0.1:80"},
+		},
+		{
+			name:              "no Endpoint in local zone",
+			hintsAnnotation:   "Auto",
+			nodeZone:          "zone-c",
+			endpoints:         []discovery.Endpoint{makeEndpoint(ep1IPv4, "zone-a"), makeEndpoint(ep2IPv4, "zone-b")},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+		{
+			name:              "Endpoint without hints",
+			hintsAnnotation:   "Auto",
+			nodeZone:          "zone-a",
+			endpoints:         []discovery.Endpoint{makeEndpoint(ep1IPv4, "zone-a"), makeEndpoint(ep2IPv4, "")},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+		{
+			name:              "hints disabled",
+			nodeZone:          "zone-a",
+			endpoints:         []discovery.Endpoint{makeEndpoint(ep1IPv4, "zone-a"), makeEndpoint(ep2IPv4, "zone-b")},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.2:80"},
+		},
+		{
+			name:            "terminating Endpoint without hints",
+			hintsAnnotation: "Auto",
+			nodeZone:        "zone-a",
+			endpoints: []discovery.Endpoint{makeEndpoint(ep1IPv4, "zone-a"), makeEndpoint(ep2IPv4, "zone-b"), {
+				Addresses:  []string{"10.180.0.3"},
+				Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &serving, Terminating: &terminating},
+			}},
+			expectedEndpoints: []string{"10.180.0.1:80", "10.180.0.3:80"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockOFClient := ofmock.NewMockClient(ctrl)
+			mockRouteClient := routemock.NewMockInterface(ctrl)
+			fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+			fp.endpointsChanges = newEndpointsChangesTracker(fp.hostname, true, false, nil)
+			fp.endpointSliceEnabled = true
+			fp.topologyAwareHintsEnabled = true
+			fp.OnNodeAdd(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   fp.hostname,
+					Labels: map[string]string{corev1.LabelTopologyZone: tt.nodeZone},
+				},
+			})
+
+			makeServiceMap(fp, makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+				svc.Annotations[corev1.AnnotationTopologyAwareHints] = tt.hintsAnnotation
+				svc.Spec.ClusterIP = svcIPv4.String()
+				svc.Spec.Ports = []corev1.ServicePort{{
+					Name:     svcPortName.Port,
+					Port:     int32(svcPort),
+					Protocol: corev1.ProtocolTCP,
+				}}
+			}))
+			portName := svcPortName.Port
+			portNumber := int32(svcPort)
+			protocol := corev1.ProtocolTCP
+			fp.endpointsChanges.OnEndpointSliceUpdate(&discovery.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      svcPortName.Name + "-1",
+					Namespace: svcPortName.Namespace,
+					Labels:    map[string]string{discovery.LabelServiceName: svcPortName.Name},
+				},
+				AddressType: discovery.AddressTypeIPv4,
+				Endpoints:   tt.endpoints,
+				Ports: []discovery.EndpointPort{{
+					Name:     &portName,
+					Port:     &portNumber,
+					Protocol: &protocol,
+				}},
+			}, false)
+			fp.endpointsChanges.OnEndpointsSynced()
+
+			groupID, _ := fp.groupCounter.Get(svcPortName, false)
+			mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+			mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+				assert.ElementsMatch(t, tt.expectedEndpoints, getEndpointStrings(endpoints))
+			}).Times(1)
+			mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+
+			fp.syncProxyRules()
+		})
+	}
+}
+
+func TestTerminatingEndpoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+	fp.endpointsChanges = newEndpointsChangesTracker(fp.hostname, true, false, nil)
+	fp.endpointSliceEnabled = true
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp, makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+		svc.Spec.ClusterIP = svcIPv4.String()
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:     svcPortName.Port,
+			Port:     int32(svcPort),
+			Protocol: corev1.ProtocolTCP,
+		}}
+	}))
+
+	ready, notReady := true, false
+	terminating := true
+	portName := svcPortName.Port
+	portNumber := int32(svcPort)
+	protocol := corev1.ProtocolTCP
+	makeEndpointSlice := func(endpoints ...discovery.Endpoint) *discovery.EndpointSlice {
+		return &discovery.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      svcPortName.Name + "-1",
+				Namespace: svcPortName.Namespace,
+				Labels:    map[string]string{discovery.LabelServiceName: svcPortName.Name},
+			},
+			AddressType: discovery.AddressTypeIPv4,
+			Endpoints:   endpoints,
+			Ports: []discovery.EndpointPort{{
+				Name:     &portName,
+				Port:     &portNumber,
+				Protocol: &protocol,
+			}},
+		}
+	}
+	readyEndpoint := func(ip net.IP) discovery.Endpoint {
+		return discovery.Endpoint{
+			Addresses:  []string{ip.String()},
+			Conditions: discovery.EndpointConditions{Ready: &ready, Serving: &ready},
+		}
+	}
+	terminatingEndpoint := func(ip net.IP) discovery.Endpoint {
+		return discovery.Endpoint{
+			Addresses:  []string{ip.String()},
+			Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &ready, Terminating: &terminating},
+		}
+	}
+	// Not ready Endpoints which are not terminating are ignored.
+	startingEndpoint := discovery.Endpoint{
+		Addresses:  []string{"10.180.0.3"},
+		Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &notReady},
+	}
+	getConditions := func(endpoints []k8sproxy.Endpoint) map[string][3]bool {
+		conditions := map[string][3]bool{}
+		for _, endpoint := range endpoints {
+			conditions[endpoint.String()] = [3]bool{endpoint.IsReady(), endpoint.IsServing(), endpoint.IsTerminating()}
+		}
+		return conditions
+	}
+
+	groupID, _ := fp.groupCounter.Get(svcPortName, false)
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice(readyEndpoint(ep1IPv4), terminatingEndpoint(ep2IPv4), startingEndpoint), false)
+	fp.endpointsChanges.OnEndpointsSynced()
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Equal(t, map[string][3]bool{
+			"10.180.0.1:80": {true, true, false},
+			"10.180.0.2:80": {false, true, true},
+		}, getConditions(endpoints))
+	}).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	fp.syncProxyRules()
+
+	// The group must be updated when the conditions of an installed Endpoint change.
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice(terminatingEndpoint(ep1IPv4), terminatingEndpoint(ep2IPv4)), false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Equal(t, map[string][3]bool{
+			"10.180.0.1:80": {false, true, true},
+			"10.180.0.2:80": {false, true, true},
+		}, getConditions(endpoints))
+	}).Times(1)
+	fp.syncProxyRules()
+	assert.False(t, fp.endpointsInstalledMap[svcPortName]["10.180.0.1:80"].IsReady())
+
+	// The Endpoints are removed once they are deleted from the EndpointSlice.
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice(terminatingEndpoint(ep2IPv4)), false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.ElementsMatch(t, []string{"10.180.0.2:80"}, getEndpointStrings(endpoints))
+	}).Times(1)
+	mockOFClient.EXPECT().UninstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	fp.syncProxyRules()
+}
+
+func TestLoadBalancingMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	fp.endpointsChanges = newEndpointsChangesTracker(fp.hostname, true, false, corelisters.NewPodLister(podIndexer))
+	fp.endpointSliceEnabled = true
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeService := func(lbMode string) *corev1.Service {
+		return makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Annotations[apis.ServiceLoadBalancingModeAnnotationKey] = lbMode
+			svc.Spec.ClusterIP = svcIPv4.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		})
+	}
+	weightedService := makeService("Weighted")
+	makeServiceMap(fp, weightedService)
+
+	ready := true
+	portName := svcPortName.Port
+	portNumber := int32(svcPort)
+	protocol := corev1.ProtocolTCP
+	makeEndpointSlice := func(name string, ip net.IP, weight string) *discovery.EndpointSlice {
+		endpointSlice := &discovery.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   svcPortName.Namespace,
+				Labels:      map[string]string{discovery.LabelServiceName: svcPortName.Name},
+				Annotations: map[string]string{},
+			},
+			AddressType: discovery.AddressTypeIPv4,
+			Endpoints: []discovery.Endpoint{{
+				Addresses:  []string{ip.String()},
+				Conditions: discovery.EndpointConditions{Ready: &ready, Serving: &ready},
+				TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: svcPortName.Namespace, Name: name},
+			}},
+			Ports: []discovery.EndpointPort{{
+				Name:     &portName,
+				Port:     &portNumber,
+				Protocol: &protocol,
+			}},
+		}
+		if weight != "" {
+			endpointSlice.Annotations[apis.EndpointSliceWeightAnnotationKey] = weight
+		}
+		return endpointSlice
+	}
+	getWeights := func(endpoints []k8sproxy.Endpoint) map[string]int {
+		weights := map[string]int{}
+		for _, endpoint := range endpoints {
+			weights[endpoint.String()] = endpoint.GetWeight()
+		}
+		return weights
+	}
+
+	groupID, _ := fp.groupCounter.Get(svcPortName, false)
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice("svc1-1", ep1IPv4, "3"), false)
+	// Invalid weights are ignored.
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice("svc1-2", ep2IPv4, "1000"), false)
+	fp.endpointsChanges.OnEndpointsSynced()
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeWeighted, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Equal(t, map[string]int{"10.180.0.1:80": 3, "10.180.0.2:80": 1}, getWeights(endpoints))
+	}).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	fp.syncProxyRules()
+
+	// The group must be updated when the weight of an installed Endpoint changes.
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice("svc1-2", ep2IPv4, "2"), false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeWeighted, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Equal(t, map[string]int{"10.180.0.1:80": 3, "10.180.0.2:80": 2}, getWeights(endpoints))
+	}).Times(1)
+	fp.syncProxyRules()
+
+	// The weight of the Pod of an Endpoint takes precedence over the weight of its EndpointSlice.
+	podIndexer.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "svc1-1",
+			Namespace:   svcPortName.Namespace,
+			Annotations: map[string]string{apis.PodEndpointWeightAnnotationKey: "5"},
+		},
+	})
+	fp.endpointsChanges.OnEndpointSliceUpdate(makeEndpointSlice("svc1-1", ep1IPv4, "3"), false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeWeighted, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Equal(t, map[string]int{"10.180.0.1:80": 5, "10.180.0.2:80": 2}, getWeights(endpoints))
+	}).Times(1)
+	fp.syncProxyRules()
+
+	// The group must be updated when the load balancing mode of the Service changes.
+	fp.serviceChanges.OnServiceUpdate(weightedService, makeService("ConsistentHash"))
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeConsistentHash, gomock.Any()).Times(1)
+	fp.syncProxyRules()
+}
+
+func TestLoadBalancerDSR(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, true)
+	fp.loadBalancerDSREnabled = true
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeService := func(loadBalancerMode string) *corev1.Service {
+		return makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Annotations[apis.ServiceLoadBalancerModeAnnotationKey] = loadBalancerMode
+			svc.Spec.ClusterIP = svcIPv4.String()
+			svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+			svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: loadBalancerIPv4.String()}}
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		})
+	}
+	dsrService := makeService("DSR")
+	makeServiceMap(fp, dsrService)
+
+	localNodeName := fp.hostname
+	remoteNodeName := "remote"
+	makeEndpointsMap(fp, makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: ep1IPv4.String(), NodeName: &localNodeName},
+				{IP: ep2IPv4.String(), NodeName: &remoteNodeName},
+			},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}))
+
+	groupID, _ := fp.groupCounter.Get(svcPortName, false)
+	localGroupID, _ := fp.groupCounter.Get(svcPortName, true)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.ElementsMatch(t, []string{"10.180.0.1:80", "10.180.0.2:80"}, getEndpointStrings(endpoints))
+	}).Times(1)
+	// The Endpoints of the Node receive the LoadBalancer traffic forwarded by the other Nodes.
+	mockOFClient.EXPECT().InstallServiceGroup(localGroupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.ElementsMatch(t, []string{"10.180.0.1:80"}, getEndpointStrings(endpoints))
+	}).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeLoadBalancer).Times(1)
+	mockOFClient.EXPECT().InstallLoadBalancerServiceDSRFlows(groupID, localGroupID, loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().InstallLoadBalancerServiceFromOutsideFlows(loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockRouteClient.EXPECT().AddClusterIPRoute(svcIPv4).Times(1)
+	mockRouteClient.EXPECT().AddLoadBalancer([]string{loadBalancerIPv4.String()}).Times(1)
+	mockRouteClient.EXPECT().AddLoadBalancerDSR([]string{loadBalancerIPv4.String()}, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	fp.syncProxyRules()
+
+	// The DSR flows must be removed when the Service switches back to the NAT mode.
+	fp.serviceChanges.OnServiceUpdate(dsrService, makeService("NAT"))
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(svcIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().UninstallLoadBalancerServiceDSRFlows(loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().UninstallServiceFlows(loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().UninstallLoadBalancerServiceFromOutsideFlows(loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeLoadBalancer).Times(1)
+	mockOFClient.EXPECT().InstallLoadBalancerServiceFromOutsideFlows(loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockRouteClient.EXPECT().DeleteClusterIPRoute(svcIPv4).Times(1)
+	mockRouteClient.EXPECT().AddClusterIPRoute(svcIPv4).Times(1)
+	mockRouteClient.EXPECT().DeleteLoadBalancer([]string{loadBalancerIPv4.String()}).Times(1)
+	mockRouteClient.EXPECT().DeleteLoadBalancerDSR([]string{loadBalancerIPv4.String()}, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockRouteClient.EXPECT().AddLoadBalancer([]string{loadBalancerIPv4.String()}).Times(1)
+	fp.syncProxyRules()
+}
+
+func TestLoadBalancerDSRExternalIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, true)
+	fp.loadBalancerDSREnabled = true
+	recorder := record.NewFakeRecorder(10)
+	fp.recorder = recorder
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	svc := makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+		svc.Annotations[apis.ServiceLoadBalancerModeAnnotationKey] = "DSR"
+		svc.Spec.ClusterIP = svcIPv4.String()
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+		svc.Spec.ExternalIPs = []string{"50.60.70.81"}
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: loadBalancerIPv4.String()}}
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:     svcPortName.Port,
+			Port:     int32(svcPort),
+			Protocol: corev1.ProtocolTCP,
+		}}
+	})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(svc))
+	fp.serviceLister = corelisters.NewServiceLister(indexer)
+	makeServiceMap(fp, svc)
+	makeEndpointsMap(fp, makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: ep1IPv4.String()}},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}))
+
+	// The DSR mode is rejected, the LoadBalancer IP is load balanced with NAT.
+	groupID, _ := fp.groupCounter.Get(svcPortName, false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeLoadBalancer).Times(1)
+	mockOFClient.EXPECT().InstallLoadBalancerServiceFromOutsideFlows(loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	mockRouteClient.EXPECT().AddClusterIPRoute(svcIPv4).Times(1)
+	mockRouteClient.EXPECT().AddLoadBalancer([]string{loadBalancerIPv4.String()}).Times(1)
+	fp.syncProxyRules()
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "LoadBalancerModeNotSupported")
+	// The event is not recorded again when the Service is synced without changes.
+	fp.syncProxyRules()
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestServiceWithSubGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp, makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+		svc.Spec.ClusterIP = svcIPv4.String()
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:     svcPortName.Port,
+			Port:     int32(svcPort),
+			Protocol: corev1.ProtocolTCP,
+		}}
+	}))
+	makeEndpoints := func(count int) *corev1.Endpoints {
+		return makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+			addresses := make([]corev1.EndpointAddress, count)
+			for i := range addresses {
+				addresses[i].IP = fmt.Sprintf("10.180.%d.%d", i/256, i%256)
+			}
+			ept.Subsets = []corev1.EndpointSubset{{
+				Addresses: addresses,
+				Ports: []corev1.EndpointPort{{
+					Name:     svcPortName.Port,
+					Port:     int32(svcPort),
+					Protocol: corev1.ProtocolTCP,
+				}},
+			}}
+		})
+	}
+	makeEndpointsMap(fp, makeEndpoints(2000))
+
+	groupID, _ := fp.groupCounter.Get(svcPortName, false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroupWithSubGroups(groupID, gomock.Any(), false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, subGroupIDs []binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Len(t, subGroupIDs, 3)
+		assert.NotContains(t, subGroupIDs, groupID)
+		assert.Len(t, endpoints, 2000)
+	}).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	fp.syncProxyRules()
+	assert.Equal(t, map[serviceGroupKey]int{{svcPortName, false}: 3}, fp.serviceSubGroupCounts)
+	// The sub-groups are not used to identify the Service traffic.
+	assert.Equal(t, []binding.GroupIDType{groupID}, fp.groupCounter.GetAllGroupIDs(svcPortName.NamespacedName.String()))
+
+	// The sub-groups are removed once the Endpoints fit in the group.
+	fp.endpointsChanges.OnEndpointUpdate(makeEndpoints(2000), makeEndpoints(10))
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().UninstallServiceGroup(gomock.Any()).Times(3)
+	mockOFClient.EXPECT().UninstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1990)
+	fp.syncProxyRules()
+	assert.Empty(t, fp.serviceSubGroupCounts)
+}
+
+func TestLookupTableModeTruncatedEndpoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, false, false)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeService := func(lbMode string) *corev1.Service {
+		return makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Annotations[apis.ServiceLoadBalancingModeAnnotationKey] = lbMode
+			svc.Spec.ClusterIP = svcIPv4.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		})
+	}
+	sourceIPHashService := makeService("SourceIPHash")
+	makeServiceMap(fp, sourceIPHashService)
+	makeEndpointsMap(fp, makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, func(ept *corev1.Endpoints) {
+		addresses := make([]corev1.EndpointAddress, 300)
+		for i := range addresses {
+			addresses[i].IP = fmt.Sprintf("10.180.%d.%d", i/256, i%256)
+		}
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: addresses,
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}))
+
+	// The Endpoints which don't fit in the lookup table are dropped, and the Service is reported as truncated.
+	groupID, _ := fp.groupCounter.Get(svcPortName, false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeSourceIPHash, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Len(t, endpoints, openflow.ServiceMaxEndpoints(agenttypes.ServiceLBModeSourceIPHash))
+	}).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	fp.syncProxyRules()
+	assert.True(t, fp.oversizeServiceSet.Has(svcPortName.String()))
+
+	// All the Endpoints are installed with the default mode.
+	fp.serviceChanges.OnServiceUpdate(sourceIPHashService, makeService(""))
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
+	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, agenttypes.ServiceLBModeDefault, gomock.Any()).Do(func(_ binding.GroupIDType, _ bool, _ agenttypes.ServiceLBMode, endpoints []k8sproxy.Endpoint) {
+		assert.Len(t, endpoints, 300)
+	}).Times(1)
+	fp.syncProxyRules()
+	assert.False(t, fp.oversizeServiceSet.Has(svcPortName.String()))
+}
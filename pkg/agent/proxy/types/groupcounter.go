@@ -34,6 +34,15 @@ type GroupCounter interface {
 	// Recycle removes a Service Group ID mapping. The recycled groupID can be
 	// reused.
 	Recycle(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool) bool
+	// GetSubGroup generates a global unique group ID for the sub-group with
+	// the given index of a Service group, which holds a part of the Endpoints
+	// of the Service when they don't fit in a single group. If the group ID
+	// of the sub-group has been generated, then return the prior one. The
+	// bool return value indicates whether the groupID is newly generated.
+	GetSubGroup(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool, index int) (binding.GroupIDType, bool)
+	// RecycleSubGroup removes a Service sub-group ID mapping. The recycled
+	// groupID can be reused.
+	RecycleSubGroup(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool, index int) bool
 	// GetAllGroupIDs gets all groupID related to a Service.
 	GetAllGroupIDs(svcNamespacedName string) []binding.GroupIDType
 }
@@ -84,38 +93,62 @@ func (c *groupCounter) deleteServicePortNameMap(svcNamespacedName string, svcKey
 	}
 }
 
+func subGroupKeyString(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool, index int) string {
+	return fmt.Sprintf("%s/%d", keyString(svcPortName, isEndpointsLocal), index)
+}
+
 func (c *groupCounter) Get(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool) (binding.GroupIDType, bool) {
+	return c.get(svcPortName, keyString(svcPortName, isEndpointsLocal), false)
+}
+
+func (c *groupCounter) GetSubGroup(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool, index int) (binding.GroupIDType, bool) {
+	return c.get(svcPortName, subGroupKeyString(svcPortName, isEndpointsLocal, index), true)
+}
+
+// get returns the group ID of key, generating it if needed. The sub-groups are
+// not returned by GetAllGroupIDs and their updates are not notified, as the
+// Service traffic is only identified by the group IDs of the Service groups.
+func (c *groupCounter) get(svcPortName k8sproxy.ServicePortName, key string, isSubGroup bool) (binding.GroupIDType, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	key := keyString(svcPortName, isEndpointsLocal)
 	if id, ok := c.groupMap[key]; ok {
 		return id, false
-	} else if len(c.recycled) != 0 {
+	}
+	var id binding.GroupIDType
+	if len(c.recycled) != 0 {
 		id = c.recycled[len(c.recycled)-1]
 		c.recycled = c.recycled[:len(c.recycled)-1]
-		c.groupMap[key] = id
-		c.updateServicePortNameMap(svcPortName.NamespacedName.String(), key)
-		c.groupIDUpdates <- svcPortName.NamespacedName.String()
-		return id, true
 	} else {
 		c.groupIDCounter += 1
-		c.groupMap[key] = c.groupIDCounter
+		id = c.groupIDCounter
+	}
+	c.groupMap[key] = id
+	if !isSubGroup {
 		c.updateServicePortNameMap(svcPortName.NamespacedName.String(), key)
 		c.groupIDUpdates <- svcPortName.NamespacedName.String()
-		return c.groupIDCounter, true
 	}
+	return id, true
 }
 
 func (c *groupCounter) Recycle(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool) bool {
+	return c.recycle(svcPortName, keyString(svcPortName, isEndpointsLocal), false)
+}
+
+func (c *groupCounter) RecycleSubGroup(svcPortName k8sproxy.ServicePortName, isEndpointsLocal bool, index int) bool {
+	return c.recycle(svcPortName, subGroupKeyString(svcPortName, isEndpointsLocal, index), true)
+}
+
+func (c *groupCounter) recycle(svcPortName k8sproxy.ServicePortName, key string, isSubGroup bool) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := keyString(svcPortName, isEndpointsLocal)
 	if id, ok := c.groupMap[key]; ok {
 		delete(c.groupMap, key)
 		c.recycled = append(c.recycled, id)
-		c.deleteServicePortNameMap(svcPortName.NamespacedName.String(), key)
-		c.groupIDUpdates <- svcPortName.NamespacedName.String()
+		if !isSubGroup {
+			c.deleteServicePortNameMap(svcPortName.NamespacedName.String(), key)
+			c.groupIDUpdates <- svcPortName.NamespacedName.String()
+		}
 		return true
 	}
 	return false
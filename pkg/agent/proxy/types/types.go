@@ -16,8 +16,11 @@ package types
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 
+	agenttypes "antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/apis"
 	"antrea.io/antrea/pkg/ovs/openflow"
 	k8sproxy "antrea.io/antrea/third_party/proxy"
 )
@@ -27,11 +30,29 @@ type ServiceInfo struct {
 	*k8sproxy.BaseServiceInfo
 	// cache for performance
 	OFProtocol openflow.Protocol
+	// LBMode is the load balancing mode specified by the annotation of the Service.
+	LBMode agenttypes.ServiceLBMode
+	// LoadBalancerMode is the load balancer mode specified by the annotation of the Service.
+	LoadBalancerMode agenttypes.LoadBalancerMode
 }
 
 // NewServiceInfo returns a new k8sproxy.ServicePort which abstracts a serviceInfo.
 func NewServiceInfo(port *corev1.ServicePort, service *corev1.Service, baseInfo *k8sproxy.BaseServiceInfo) k8sproxy.ServicePort {
 	info := &ServiceInfo{BaseServiceInfo: baseInfo}
+	switch lbMode := agenttypes.ServiceLBMode(service.Annotations[apis.ServiceLoadBalancingModeAnnotationKey]); lbMode {
+	case agenttypes.ServiceLBModeDefault, agenttypes.ServiceLBModeWeighted, agenttypes.ServiceLBModeConsistentHash, agenttypes.ServiceLBModeSourceIPHash:
+		info.LBMode = lbMode
+	default:
+		klog.InfoS("Ignoring unknown load balancing mode of Service", "Service", klog.KObj(service), "mode", lbMode)
+	}
+	info.LoadBalancerMode = agenttypes.LoadBalancerModeNAT
+	switch loadBalancerMode := agenttypes.LoadBalancerMode(service.Annotations[apis.ServiceLoadBalancerModeAnnotationKey]); loadBalancerMode {
+	case "", agenttypes.LoadBalancerModeNAT:
+	case agenttypes.LoadBalancerModeDSR:
+		info.LoadBalancerMode = loadBalancerMode
+	default:
+		klog.InfoS("Ignoring unknown load balancer mode of Service", "Service", klog.KObj(service), "mode", loadBalancerMode)
+	}
 	if utilnet.IsIPv6(baseInfo.ClusterIP()) {
 		info.OFProtocol = openflow.ProtocolTCPv6
 		if port.Protocol == corev1.ProtocolUDP {
@@ -71,6 +71,12 @@ type Interface interface {
 	// DeleteLoadBalancer deletes related configurations when a LoadBalancer Service is deleted.
 	DeleteLoadBalancer(externalIPs []string) error
 
+	// AddLoadBalancerDSR adds configurations when a LoadBalancer Service port in DSR mode is created.
+	AddLoadBalancerDSR(externalIPs []string, port uint16, protocol binding.Protocol) error
+
+	// DeleteLoadBalancerDSR deletes related configurations when a LoadBalancer Service port in DSR mode is deleted.
+	DeleteLoadBalancerDSR(externalIPs []string, port uint16, protocol binding.Protocol) error
+
 	// Run starts the sync loop.
 	Run(stopCh <-chan struct{})
 
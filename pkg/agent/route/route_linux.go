@@ -62,6 +62,10 @@ const (
 	antreaNodePortIPSet  = "ANTREA-NODEPORT-IP"
 	antreaNodePortIP6Set = "ANTREA-NODEPORT-IP6"
 
+	// antreaDSRLoadBalancerIPSet contains the IP,port:protocol entries of the LoadBalancer Services in DSR mode. DSR is
+	// only supported for IPv4.
+	antreaDSRLoadBalancerIPSet = "ANTREA-DSR-LB-IP"
+
 	// Antrea managed iptables chains.
 	antreaForwardChain     = "ANTREA-FORWARD"
 	antreaPreRoutingChain  = "ANTREA-PREROUTING"
@@ -109,6 +113,8 @@ type Client struct {
 	nodePortsIPv4 sync.Map
 	// nodePortsIPv6 caches all existing IPv6 NodePorts.
 	nodePortsIPv6 sync.Map
+	// dsrLoadBalancers caches all existing entries of the LoadBalancer Services in DSR mode.
+	dsrLoadBalancers sync.Map
 	// clusterIPv4CIDR stores the calculated ClusterIP CIDR for IPv4.
 	clusterIPv4CIDR *net.IPNet
 	// clusterIPv6CIDR stores the calculated ClusterIP CIDR for IPv6.
@@ -304,6 +310,17 @@ func (c *Client) syncIPSet() error {
 			}
 			return true
 		})
+
+		if err := ipset.CreateIPSet(antreaDSRLoadBalancerIPSet, ipset.HashIPPort, false); err != nil {
+			return err
+		}
+		c.dsrLoadBalancers.Range(func(k, _ interface{}) bool {
+			ipSetEntry := k.(string)
+			if err := ipset.AddEntry(antreaDSRLoadBalancerIPSet, ipSetEntry); err != nil {
+				return false
+			}
+			return true
+		})
 	}
 
 	if c.connectUplinkToBridge {
@@ -486,6 +503,20 @@ func (c *Client) restoreIptablesData(podCIDR *net.IPNet, podIPSet, localAntreaFl
 		c.writeEKSMangleRule(iptablesData)
 	}
 
+	// The requests to a LoadBalancer Service in DSR mode are forwarded from the Node receiving them to the Node of the
+	// Endpoint through the tunnel, while the replies are sent directly to the clients. Clamp the MSS of the SYN-ACK
+	// packets of the Endpoints, so that the segments sent by the clients still fit in the MTU once encapsulated.
+	if c.proxyAll && serviceVirtualIP.To4() != nil {
+		writeLine(iptablesData, []string{
+			"-A", antreaMangleChain,
+			"-m", "comment", "--comment", `"Antrea: clamp MSS of DSR LoadBalancer replies"`,
+			"-i", c.nodeConfig.GatewayConfig.Name,
+			"-p", "tcp", "--tcp-flags", "SYN,RST", "SYN",
+			"-m", "set", "--match-set", antreaDSRLoadBalancerIPSet, "src,src",
+			"-j", iptables.TCPMSSTarget, "--set-mss", strconv.Itoa(c.dsrMSS()),
+		}...)
+	}
+
 	// To make liveness/readiness probe traffic bypass ingress rules of Network Policies, mark locally generated packets
 	// that will be sent to OVS so we can identify them later in the OVS pipeline.
 	// It must match source address because kube-proxy ipvs mode will redirect ingress packets to output chain, and they
@@ -1217,6 +1248,63 @@ func (c *Client) DeleteLoadBalancer(externalIPs []string) error {
 	return nil
 }
 
+// dsrMSS returns the TCP MSS of the connections to the LoadBalancer Services in DSR mode, so that the packets of the
+// clients fit in the MTU of the Node network once encapsulated. In encap mode, the Node MTU already accounts for the
+// tunnel headers.
+func (c *Client) dsrMSS() int {
+	// The size of the IPv4 and TCP headers without options.
+	mss := c.nodeConfig.NodeMTU - 40
+	if !c.networkConfig.TrafficEncapMode.SupportsEncap() {
+		switch c.networkConfig.TunnelType {
+		case ovsconfig.VXLANTunnel:
+			mss -= config.VXLANOverhead
+		case ovsconfig.GeneveTunnel:
+			mss -= config.GeneveOverhead
+		case ovsconfig.GRETunnel:
+			mss -= config.GREOverhead
+		}
+	}
+	return mss
+}
+
+// AddLoadBalancerDSR is used to add IP,port:protocol entries to the DSR ipset when a LoadBalancer Service port in DSR
+// mode is added, to clamp the MSS of its TCP connections. An entry is added for every LoadBalancer IP.
+func (c *Client) AddLoadBalancerDSR(externalIPs []string, port uint16, protocol binding.Protocol) error {
+	if protocol != binding.ProtocolTCP {
+		return nil
+	}
+	for _, svcIPStr := range externalIPs {
+		if svcIPStr == "" {
+			continue
+		}
+		ipSetEntry := fmt.Sprintf("%s,%s:%d", svcIPStr, getTransProtocolStr(protocol), port)
+		if err := ipset.AddEntry(antreaDSRLoadBalancerIPSet, ipSetEntry); err != nil {
+			return err
+		}
+		c.dsrLoadBalancers.Store(ipSetEntry, struct{}{})
+	}
+	return nil
+}
+
+// DeleteLoadBalancerDSR is used to delete related IP set entries when a LoadBalancer Service port in DSR mode is
+// deleted.
+func (c *Client) DeleteLoadBalancerDSR(externalIPs []string, port uint16, protocol binding.Protocol) error {
+	if protocol != binding.ProtocolTCP {
+		return nil
+	}
+	for _, svcIPStr := range externalIPs {
+		if svcIPStr == "" {
+			continue
+		}
+		ipSetEntry := fmt.Sprintf("%s,%s:%d", svcIPStr, getTransProtocolStr(protocol), port)
+		if err := ipset.DelEntry(antreaDSRLoadBalancerIPSet, ipSetEntry); err != nil {
+			return err
+		}
+		c.dsrLoadBalancers.Delete(ipSetEntry)
+	}
+	return nil
+}
+
 // AddLocalAntreaFlexibleIPAMPodRule is used to add IP to target ip set when an AntreaFlexibleIPAM Pod is added. An entry is added
 // for every Pod IP.
 func (c *Client) AddLocalAntreaFlexibleIPAMPodRule(podAddresses []net.IP) error {
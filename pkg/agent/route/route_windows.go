@@ -406,6 +406,16 @@ func (c *Client) DeleteLoadBalancer(externalIPs []string) error {
 	return nil
 }
 
+// AddLoadBalancerDSR is not supported on Windows, as DSR is only supported on Linux Nodes.
+func (c *Client) AddLoadBalancerDSR(externalIPs []string, port uint16, protocol binding.Protocol) error {
+	return nil
+}
+
+// DeleteLoadBalancerDSR is not supported on Windows.
+func (c *Client) DeleteLoadBalancerDSR(externalIPs []string, port uint16, protocol binding.Protocol) error {
+	return nil
+}
+
 func (c *Client) AddLocalAntreaFlexibleIPAMPodRule(podAddresses []net.IP) error {
 	return nil
 }
@@ -78,6 +78,20 @@ func (mr *MockInterfaceMockRecorder) AddLoadBalancer(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoadBalancer", reflect.TypeOf((*MockInterface)(nil).AddLoadBalancer), arg0)
 }
 
+// AddLoadBalancerDSR mocks base method
+func (m *MockInterface) AddLoadBalancerDSR(arg0 []string, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLoadBalancerDSR", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLoadBalancerDSR indicates an expected call of AddLoadBalancerDSR
+func (mr *MockInterfaceMockRecorder) AddLoadBalancerDSR(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoadBalancerDSR", reflect.TypeOf((*MockInterface)(nil).AddLoadBalancerDSR), arg0, arg1, arg2)
+}
+
 // AddLocalAntreaFlexibleIPAMPodRule mocks base method
 func (m *MockInterface) AddLocalAntreaFlexibleIPAMPodRule(arg0 []net.IP) error {
 	m.ctrl.T.Helper()
@@ -162,6 +176,20 @@ func (mr *MockInterfaceMockRecorder) DeleteLoadBalancer(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoadBalancer", reflect.TypeOf((*MockInterface)(nil).DeleteLoadBalancer), arg0)
 }
 
+// DeleteLoadBalancerDSR mocks base method
+func (m *MockInterface) DeleteLoadBalancerDSR(arg0 []string, arg1 uint16, arg2 openflow.Protocol) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoadBalancerDSR", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoadBalancerDSR indicates an expected call of DeleteLoadBalancerDSR
+func (mr *MockInterfaceMockRecorder) DeleteLoadBalancerDSR(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoadBalancerDSR", reflect.TypeOf((*MockInterface)(nil).DeleteLoadBalancerDSR), arg0, arg1, arg2)
+}
+
 // DeleteLocalAntreaFlexibleIPAMPodRule mocks base method
 func (m *MockInterface) DeleteLocalAntreaFlexibleIPAMPodRule(arg0 []net.IP) error {
 	m.ctrl.T.Helper()
@@ -19,16 +19,20 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent"
+	"antrea.io/antrea/pkg/agent/metrics"
 	"antrea.io/antrea/pkg/agent/openflow"
 	agenttypes "antrea.io/antrea/pkg/agent/types"
 	cpv1beta "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	statsv1alpha1 "antrea.io/antrea/pkg/apis/stats/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
 	"antrea.io/antrea/pkg/querier"
 	"antrea.io/antrea/pkg/util/env"
+	"antrea.io/antrea/pkg/util/rulemetrics"
 )
 
 const (
@@ -59,15 +63,28 @@ type Collector struct {
 	// lastStatsCollection is the last statistics that has been reported to antrea-controller successfully.
 	// It is used to calculate the delta of the statistics that will be reported.
 	lastStatsCollection *statsCollection
+	// ruleMetricsBudget decides which rules are exported as Prometheus metrics. It's nil if the rule metrics are
+	// disabled.
+	ruleMetricsBudget *rulemetrics.Budget
+	// tierLister is used to get the Tiers of the Antrea-native policies when the rule metrics are filtered by Tier.
+	tierLister crdlisters.TierLister
+	// lastRuleMetrics is the last statistics of the Openflow rules, used to calculate the increments of the
+	// Prometheus metrics, which unlike the reported stats don't depend on the success of the report.
+	lastRuleMetrics map[uint32]*agenttypes.RuleMetric
 }
 
-func NewCollector(antreaClientProvider agent.AntreaClientProvider, ofClient openflow.Client, npQuerier querier.AgentNetworkPolicyInfoQuerier) *Collector {
+// NewCollector returns a new *Collector. ruleMetricsBudget and tierLister can be nil if the rule metrics are disabled
+// or not filtered by Tier.
+func NewCollector(antreaClientProvider agent.AntreaClientProvider, ofClient openflow.Client, npQuerier querier.AgentNetworkPolicyInfoQuerier,
+	ruleMetricsBudget *rulemetrics.Budget, tierLister crdlisters.TierLister) *Collector {
 	nodeName, _ := env.GetNodeName()
 	manager := &Collector{
 		nodeName:             nodeName,
 		antreaClientProvider: antreaClientProvider,
 		ofClient:             ofClient,
 		networkPolicyQuerier: npQuerier,
+		ruleMetricsBudget:    ruleMetricsBudget,
+		tierLister:           tierLister,
 	}
 	return manager
 }
@@ -107,6 +124,14 @@ func (m *Collector) collect() *statsCollection {
 	npStatsMap := map[types.UID]*statsv1alpha1.TrafficStats{}
 	acnpStatsMap := map[types.UID]map[string]*statsv1alpha1.TrafficStats{}
 	anpStatsMap := map[types.UID]map[string]*statsv1alpha1.TrafficStats{}
+	var ruleMetrics map[rulemetrics.Key]*agenttypes.RuleMetric
+	var policyTiers map[types.UID]string
+	if m.ruleMetricsBudget != nil {
+		ruleMetrics = map[rulemetrics.Key]*agenttypes.RuleMetric{}
+		if m.ruleMetricsBudget.HasTiers() {
+			policyTiers = m.getPolicyTiers()
+		}
+	}
 
 	for ofID, ruleStats := range ruleStatsMap {
 		rule := m.networkPolicyQuerier.GetRuleByFlowID(ofID)
@@ -126,6 +151,13 @@ func (m *Collector) collect() *statsCollection {
 		case cpv1beta.AntreaNetworkPolicy:
 			addRuleStatsUp(anpStatsMap, ruleStats, rule)
 		}
+		if ruleMetrics != nil && m.ruleMetricsBudget.Allows(policyTiers[rule.PolicyRef.UID], rule.PolicyRef.Namespace) {
+			addRuleMetricUp(ruleMetrics, m.ruleMetricIncrement(ofID, ruleStats), rule)
+		}
+	}
+	if ruleMetrics != nil {
+		m.lastRuleMetrics = ruleStatsMap
+		updateRuleMetrics(m.ruleMetricsBudget, ruleMetrics)
 	}
 	return &statsCollection{
 		networkPolicyStats:              npStatsMap,
@@ -157,6 +189,82 @@ func addRuleStatsUp(ruleStatsMap map[types.UID]map[string]*statsv1alpha1.Traffic
 	addUp(trafficStats, ruleStats)
 }
 
+// ruleMetricIncrement returns the increment of the statistics of an Openflow rule since the last collection.
+func (m *Collector) ruleMetricIncrement(ofID uint32, ruleStats *agenttypes.RuleMetric) *agenttypes.RuleMetric {
+	inc := *ruleStats
+	// The statistics restart from 0 if the flows have been reinstalled since last time.
+	if lastStats, exists := m.lastRuleMetrics[ofID]; exists && ruleStats.Bytes >= lastStats.Bytes && ruleStats.Packets >= lastStats.Packets &&
+		ruleStats.Sessions >= lastStats.Sessions {
+		inc.Bytes -= lastStats.Bytes
+		inc.Packets -= lastStats.Packets
+		inc.Sessions -= lastStats.Sessions
+	}
+	return &inc
+}
+
+// getPolicyTiers returns a mapping from the UIDs of the Antrea-native policies to the names of their Tiers.
+func (m *Collector) getPolicyTiers() map[types.UID]string {
+	tiers, err := m.tierLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list Tiers")
+		return nil
+	}
+	tierNames := make(map[int32]string, len(tiers))
+	for _, tier := range tiers {
+		tierNames[tier.Spec.Priority] = tier.Name
+	}
+	policyTiers := map[types.UID]string{}
+	for _, policy := range m.networkPolicyQuerier.GetNetworkPolicies(&querier.NetworkPolicyQueryFilter{}) {
+		if policy.TierPriority != nil {
+			policyTiers[policy.SourceRef.UID] = tierNames[*policy.TierPriority]
+		}
+	}
+	return policyTiers
+}
+
+func addRuleMetricUp(ruleMetrics map[rulemetrics.Key]*agenttypes.RuleMetric, inc *agenttypes.RuleMetric, rule *agenttypes.PolicyRule) {
+	key := rulemetrics.Key{
+		PolicyType:      string(rule.PolicyRef.Type),
+		PolicyNamespace: rule.PolicyRef.Namespace,
+		PolicyName:      rule.PolicyRef.Name,
+	}
+	// The stats of K8s NetworkPolicies are per policy.
+	if rule.PolicyRef.Type != cpv1beta.K8sNetworkPolicy {
+		key.Rule = rule.Name
+	}
+	metric, exists := ruleMetrics[key]
+	if !exists {
+		metric = new(agenttypes.RuleMetric)
+		ruleMetrics[key] = metric
+	}
+	metric.Merge(inc)
+}
+
+// updateRuleMetrics adds the increments of the statistics of the rules to their Prometheus metrics, and deletes the
+// metrics of the rules which are no longer applied, so that the budget can be used by other rules.
+func updateRuleMetrics(budget *rulemetrics.Budget, ruleMetrics map[rulemetrics.Key]*agenttypes.RuleMetric) {
+	for key, inc := range ruleMetrics {
+		if !budget.Admit(key) {
+			metrics.NetworkPolicyRuleMetricsRejectedCount.Inc()
+			continue
+		}
+		labelValues := key.LabelValues()
+		metrics.NetworkPolicyRulePacketCount.WithLabelValues(labelValues...).Add(float64(inc.Packets))
+		metrics.NetworkPolicyRuleByteCount.WithLabelValues(labelValues...).Add(float64(inc.Bytes))
+		metrics.NetworkPolicyRuleSessionCount.WithLabelValues(labelValues...).Add(float64(inc.Sessions))
+	}
+	staleKeys := budget.Release(func(key rulemetrics.Key) bool {
+		_, exists := ruleMetrics[key]
+		return !exists
+	})
+	for _, key := range staleKeys {
+		labels := key.LabelSet()
+		metrics.NetworkPolicyRulePacketCount.Delete(labels)
+		metrics.NetworkPolicyRuleByteCount.Delete(labels)
+		metrics.NetworkPolicyRuleSessionCount.Delete(labels)
+	}
+}
+
 func addUp(stats *statsv1alpha1.TrafficStats, inc *agenttypes.RuleMetric) {
 	stats.Sessions += int64(inc.Sessions)
 	stats.Packets += int64(inc.Packets)
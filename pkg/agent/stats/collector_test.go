@@ -20,13 +20,17 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/metrics/testutil"
 
+	"antrea.io/antrea/pkg/agent/metrics"
 	oftest "antrea.io/antrea/pkg/agent/openflow/testing"
 	agenttypes "antrea.io/antrea/pkg/agent/types"
 	cpv1beta "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	statsv1alpha1 "antrea.io/antrea/pkg/apis/stats/v1alpha1"
 	queriertest "antrea.io/antrea/pkg/querier/testing"
+	"antrea.io/antrea/pkg/util/rulemetrics"
 )
 
 var (
@@ -559,3 +563,42 @@ func TestCalculateRuleDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateRuleMetrics(t *testing.T) {
+	metrics.InitializeNetworkPolicyMetrics()
+	metrics.NetworkPolicyRulePacketCount.Reset()
+	initialRejectedCount, _ := testutil.GetCounterMetricValue(metrics.NetworkPolicyRuleMetricsRejectedCount)
+	getPacketCount := func(key rulemetrics.Key) float64 {
+		v, err := testutil.GetCounterMetricValue(metrics.NetworkPolicyRulePacketCount.WithLabelValues(key.LabelValues()...))
+		require.NoError(t, err)
+		return v
+	}
+	np1Key := rulemetrics.Key{PolicyType: string(np1.Type), PolicyNamespace: np1.Namespace, PolicyName: np1.Name}
+	anp1Key := rulemetrics.Key{PolicyType: string(anp1.Type), PolicyNamespace: anp1.Namespace, PolicyName: anp1.Name, Rule: "rule1"}
+	// The budget only allows 1 rule.
+	budget := rulemetrics.NewBudget(1, nil, nil)
+
+	updateRuleMetrics(budget, map[rulemetrics.Key]*agenttypes.RuleMetric{
+		np1Key: {Bytes: 10, Packets: 1, Sessions: 1},
+	})
+	// The rule of anp1 is rejected as long as the rule of np1 is applied.
+	updateRuleMetrics(budget, map[rulemetrics.Key]*agenttypes.RuleMetric{
+		np1Key:  {Bytes: 20, Packets: 2, Sessions: 0},
+		anp1Key: {Bytes: 50, Packets: 5, Sessions: 1},
+	})
+	assert.Equal(t, float64(3), getPacketCount(np1Key))
+
+	// The rule of np1 is released once it's no longer applied.
+	updateRuleMetrics(budget, map[rulemetrics.Key]*agenttypes.RuleMetric{
+		anp1Key: {Bytes: 50, Packets: 5, Sessions: 1},
+	})
+	updateRuleMetrics(budget, map[rulemetrics.Key]*agenttypes.RuleMetric{
+		anp1Key: {Bytes: 50, Packets: 5, Sessions: 1},
+	})
+	assert.Equal(t, float64(5), getPacketCount(anp1Key))
+	// The metrics of np1 start from 0 again as they have been deleted.
+	assert.Equal(t, float64(0), getPacketCount(np1Key))
+	rejectedCount, err := testutil.GetCounterMetricValue(metrics.NetworkPolicyRuleMetricsRejectedCount)
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), rejectedCount-initialRejectedCount)
+}
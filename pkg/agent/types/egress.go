@@ -0,0 +1,26 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// SNATMetric represents the statistics of the egress packets of a Pod which
+// are SNAT'd with an Egress IP.
+type SNATMetric struct {
+	Bytes, Packets uint64
+}
+
+func (m *SNATMetric) Merge(m1 *SNATMetric) {
+	m.Bytes += m1.Bytes
+	m.Packets += m1.Packets
+}
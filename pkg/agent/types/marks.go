@@ -18,11 +18,16 @@ const (
 	// HostLocalSourceBit is the bit of the iptables fwmark space to mark locally generated packets.
 	// Value must be within the range [0, 31].
 	HostLocalSourceBit = 0
+	// L7RedirectBit is the bit of the iptables fwmark space to mark the packets redirected by OVS to the L7 proxy.
+	// Value must be within the range [0, 31] and must not overlap with SNATIPMarkMask.
+	L7RedirectBit = 8
 )
 
 var (
 	// HostLocalSourceMark is the mark generated from HostLocalSourceBit.
 	HostLocalSourceMark = uint32(1 << HostLocalSourceBit)
+	// L7RedirectMark is the mark generated from L7RedirectBit.
+	L7RedirectMark = uint32(1 << L7RedirectBit)
 
 	// SNATIPMarkMask is the bits of packet mark that stores the ID of the
 	// SNAT IP for a "Pod -> external" egress packet, that is to be SNAT'd.
@@ -0,0 +1,52 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// ServiceLBMode is the algorithm selecting the Endpoints of the new connections
+// to a Service.
+type ServiceLBMode string
+
+const (
+	// ServiceLBModeDefault load-balances the new connections uniformly across
+	// the Endpoints.
+	ServiceLBModeDefault ServiceLBMode = ""
+	// ServiceLBModeWeighted load-balances the new connections across the
+	// Endpoints in proportion to their weights.
+	ServiceLBModeWeighted ServiceLBMode = "Weighted"
+	// ServiceLBModeConsistentHash selects the Endpoint of a new connection with
+	// a Maglev lookup table indexed by the hash of its 5-tuple, so that few
+	// connections select other Endpoints when the Endpoints change.
+	ServiceLBModeConsistentHash ServiceLBMode = "ConsistentHash"
+	// ServiceLBModeSourceIPHash selects the Endpoint of a new connection with a
+	// Maglev lookup table indexed by the hash of its source IP, so that
+	// the connections from a source IP select the same Endpoint, without
+	// learning flows.
+	ServiceLBModeSourceIPHash ServiceLBMode = "SourceIPHash"
+)
+
+// LoadBalancerMode is the way the traffic of the LoadBalancer IPs of a Service
+// is forwarded to remote Endpoints.
+type LoadBalancerMode string
+
+const (
+	// LoadBalancerModeNAT forwards the requests to remote Endpoints after DNAT
+	// and SNAT, so that the replies go back through the Node which received
+	// the requests.
+	LoadBalancerModeNAT LoadBalancerMode = "NAT"
+	// LoadBalancerModeDSR forwards the requests to remote Endpoints through the
+	// tunnel with the LoadBalancer IP preserved, so that the Nodes of the
+	// Endpoints reply directly to the clients (Direct Server Return).
+	LoadBalancerModeDSR LoadBalancerMode = "DSR"
+)
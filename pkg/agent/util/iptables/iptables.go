@@ -42,6 +42,7 @@ const (
 	NoTrackTarget    = "NOTRACK"
 	SNATTarget       = "SNAT"
 	DNATTarget       = "DNAT"
+	TCPMSSTarget     = "TCPMSS"
 
 	PreRoutingChain  = "PREROUTING"
 	ForwardChain     = "FORWARD"
@@ -24,6 +24,8 @@ import (
 	"antrea.io/antrea/pkg/agent/openflow"
 	fallbackversion "antrea.io/antrea/pkg/antctl/fallback/version"
 	"antrea.io/antrea/pkg/antctl/raw/featuregates"
+	"antrea.io/antrea/pkg/antctl/raw/fqdncache"
+	"antrea.io/antrea/pkg/antctl/raw/packetcapture"
 	"antrea.io/antrea/pkg/antctl/raw/proxy"
 	"antrea.io/antrea/pkg/antctl/raw/supportbundle"
 	"antrea.io/antrea/pkg/antctl/raw/traceflow"
@@ -32,6 +34,7 @@ import (
 	"antrea.io/antrea/pkg/antctl/transform/controllerinfo"
 	"antrea.io/antrea/pkg/antctl/transform/networkpolicy"
 	"antrea.io/antrea/pkg/antctl/transform/ovstracing"
+	"antrea.io/antrea/pkg/antctl/transform/recommendations"
 	"antrea.io/antrea/pkg/antctl/transform/version"
 	cpv1beta "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	systemv1beta1 "antrea.io/antrea/pkg/apis/system/v1beta1"
@@ -39,6 +42,7 @@ import (
 	"antrea.io/antrea/pkg/client/clientset/versioned/scheme"
 	controllernetworkpolicy "antrea.io/antrea/pkg/controller/networkpolicy"
 	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/flowrecords"
+	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/flows"
 	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/recordmetrics"
 )
 
@@ -508,6 +512,102 @@ var CommandList = &commandList{
 			},
 			transformedResponse: reflect.TypeOf(recordmetrics.Response{}),
 		},
+		{
+			use:   "flows",
+			short: "Print flows aggregated over a sliding window in the flow aggregator",
+			long:  "Print flows aggregated over a sliding window in the flow aggregator. The flow records exported during the window are grouped by flow, Pod pair, Namespace, Service or policy, and sorted by decreasing bytes. They can be filtered by Namespace, Pod, Service and policy.",
+			example: `  Get the top 10 Pod pairs by bytes in the last 5 minutes
+  $ antctl get flows --groupby pod-pair --window 5m --top 10
+  Get all the flows denied by the policy deny-all in the Namespace default
+  $ antctl get flows --policy default/deny-all --denied true
+  Get the bytes per Namespace
+  $ antctl get flows --groupby namespace
+  Get the flows of the Pod web in the Namespace prod, to the Service prod/db
+  $ antctl get flows --pod prod/web --service prod/db`,
+			commandGroup: get,
+			flowAggregatorEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/flows",
+					params: []flagInfo{
+						{
+							name:            "groupby",
+							usage:           "Group the flow records by flow, pod-pair, namespace, service or policy.",
+							defaultValue:    "flow",
+							supportedValues: []string{"flow", "pod-pair", "namespace", "service", "policy"},
+						},
+						{
+							name:  "window",
+							usage: "Only aggregate the flow records exported during this duration before now, e.g. 5m. Defaults to the whole window kept by the flow aggregator.",
+						},
+						{
+							name:  "top",
+							usage: "Only print the given number of results with the most bytes.",
+						},
+						{
+							name:      "namespace",
+							usage:     "Get flows whose source or destination Pod is in the Namespace.",
+							shorthand: "n",
+						},
+						{
+							name:  "pod",
+							usage: "Get flows whose source or destination Pod matches, as <Namespace>/<Name> or <Name>.",
+						},
+						{
+							name:  "service",
+							usage: "Get flows to the Service, as <Namespace>/<Name>[:<Port>].",
+						},
+						{
+							name:  "policy",
+							usage: "Get flows whose ingress or egress NetworkPolicy matches, as <Namespace>/<Name> or <Name>.",
+						},
+						{
+							name:            "denied",
+							usage:           "Only get flows dropped or rejected by a NetworkPolicy.",
+							supportedValues: []string{"true", "false"},
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			transformedResponse: reflect.TypeOf(flows.Response{}),
+		},
+		{
+			use:   "recommend-policies",
+			short: "Recommend NetworkPolicies allowing the flows observed by the flow aggregator",
+			long:  "Recommend NetworkPolicies allowing the flows observed by the flow aggregator. For each workload, i.e. the Pods of a Namespace sharing the same labels, a policy allowing exactly the connections observed from and to its Pods is recommended, as YAML documents which can be reviewed and applied with kubectl. Antrea-native policies can be staged into a Tier, which is recommended too with a low priority if it is not a default Tier.",
+			example: `  Recommend Kubernetes NetworkPolicies for all the workloads
+  $ antctl recommend-policies
+  Recommend Antrea NetworkPolicies for the workloads of the Namespace prod, in the Tier staging
+  $ antctl recommend-policies --type anp -n prod --tier staging > policies.yaml
+  Recommend Antrea ClusterNetworkPolicies for all the workloads
+  $ antctl recommend-policies --type acnp`,
+			commandGroup: flat,
+			flowAggregatorEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/recommendations",
+					params: []flagInfo{
+						{
+							name:            "type",
+							usage:           "Type of the recommended policies: k8s (Kubernetes NetworkPolicies), anp (Antrea NetworkPolicies) or acnp (Antrea ClusterNetworkPolicies).",
+							defaultValue:    "k8s",
+							supportedValues: []string{"k8s", "anp", "acnp"},
+						},
+						{
+							name:      "namespace",
+							usage:     "Only recommend policies for the workloads of the Namespace.",
+							shorthand: "n",
+						},
+						{
+							name:  "tier",
+							usage: "Tier of the recommended Antrea-native policies.",
+						},
+					},
+					outputType: single,
+				},
+				addonTransform: recommendations.Transform,
+			},
+			transformedResponse: reflect.TypeOf(""),
+		},
 	},
 	rawCommands: []rawCommand{
 		{
@@ -520,6 +620,11 @@ var CommandList = &commandList{
 			supportAgent:      true,
 			supportController: true,
 		},
+		{
+			cobraCommand:      packetcapture.Command,
+			supportAgent:      true,
+			supportController: true,
+		},
 		{
 			cobraCommand:      proxy.Command,
 			supportAgent:      false,
@@ -531,6 +636,12 @@ var CommandList = &commandList{
 			supportController: true,
 			commandGroup:      get,
 		},
+		{
+			cobraCommand:      fqdncache.Command,
+			supportAgent:      true,
+			supportController: true,
+			commandGroup:      get,
+		},
 	},
 	codec: scheme.Codecs,
 }
@@ -0,0 +1,138 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fqdncache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"antrea.io/antrea/pkg/agent/apiserver/handlers/fqdncache"
+	"antrea.io/antrea/pkg/antctl/raw"
+	"antrea.io/antrea/pkg/antctl/runtime"
+)
+
+var (
+	Command *cobra.Command
+	option  = &struct {
+		nodeName string
+		output   string
+	}{}
+)
+
+func init() {
+	Command = &cobra.Command{
+		Use:     "fqdncache [FQDN]",
+		Aliases: []string{"fqdn"},
+		Short:   "Print the DNS cache of FQDN policy rules",
+		Long: "Print the FQDNs cached by the Antrea Agent for FQDN policy rules, with the IPs they are resolved to, " +
+			"the remaining TTL of the IPs, and the rules and policies selecting them. The numbers of DNS responses " +
+			"intercepted, DNS responses failed to be parsed and rules resynced are printed as well.",
+		Example: `  Get the DNS cache of FQDN policy rules
+  $ antctl get fqdncache
+  Get the cache entry of a FQDN
+  $ antctl get fqdncache www.example.com
+  Get the DNS cache of FQDN policy rules of the Antrea Agent running on Node node1 (from outside the Agent Pods)
+  $ antctl get fqdncache --node node1
+  Get the DNS cache of FQDN policy rules in json format
+  $ antctl get fqdncache -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runE,
+	}
+	if !(runtime.Mode == runtime.ModeAgent && runtime.InPod) {
+		Command.Flags().StringVar(&option.nodeName, "node", "", "Name of the Node whose Antrea Agent is queried")
+	}
+	Command.Flags().StringVarP(&option.output, "output", "o", "table", "Output format, table or json")
+}
+
+func runE(cmd *cobra.Command, args []string) error {
+	if option.output != "table" && option.output != "json" {
+		return fmt.Errorf("unsupported output format %q", option.output)
+	}
+	kubeconfig, err := raw.ResolveKubeconfig(cmd)
+	if err != nil {
+		return err
+	}
+	kubeconfig.GroupVersion = &schema.GroupVersion{Group: "", Version: ""}
+	restconfigTmpl := rest.CopyConfig(kubeconfig)
+	raw.SetupKubeconfig(restconfigTmpl)
+
+	agentClientCfg := restconfigTmpl
+	// In the Agent Pod, antctl can only talk to the local Agent.
+	if !(runtime.Mode == runtime.ModeAgent && runtime.InPod) {
+		if option.nodeName == "" {
+			return fmt.Errorf("--node must be specified")
+		}
+		k8sClientset, antreaClientset, err := raw.SetupClients(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create clientset: %w", err)
+		}
+		agentClientCfg, err = raw.CreateAgentClientCfg(k8sClientset, antreaClientset, restconfigTmpl, option.nodeName)
+		if err != nil {
+			return fmt.Errorf("error when creating Agent client config: %w", err)
+		}
+	}
+	agentClient, err := rest.RESTClientFor(agentClientCfg)
+	if err != nil {
+		return fmt.Errorf("error when creating Agent client: %w", err)
+	}
+
+	u := url.URL{Path: "/fqdncache"}
+	if len(args) > 0 {
+		u.RawQuery = url.Values{"fqdn": []string{args[0]}}.Encode()
+	}
+	data, err := agentClient.Get().RequestURI(u.RequestURI()).DoRaw(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error when requesting the DNS cache: %w", err)
+	}
+	var resp fqdncache.Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("error when decoding the DNS cache: %w", err)
+	}
+	if option.output == "json" {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resp)
+	}
+	return output(resp, cmd.OutOrStdout())
+}
+
+func output(resp fqdncache.Response, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "FQDN\tIPS\tTTL\tRULES\tPOLICIES")
+	for _, e := range resp.Entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.FQDN, joinOrNone(e.IPs), strconv.FormatInt(e.RemainingTTL, 10), joinOrNone(e.RuleIDs), joinOrNone(e.Policies))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "DNS-PACKET-INS\tPARSE-FAILURES\tRULES-RESYNCED")
+	fmt.Fprintf(w, "%d\t%d\t%d\n", resp.Stats.DNSPacketIns, resp.Stats.ParseFailures, resp.Stats.RulesResynced)
+	return w.Flush()
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "<NONE>"
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"antrea.io/antrea/pkg/antctl/raw"
+	"antrea.io/antrea/pkg/antctl/runtime"
+	"antrea.io/antrea/pkg/apis/crd/v1alpha1"
+)
+
+var (
+	Command *cobra.Command
+	option  = &struct {
+		outputFile string
+	}{}
+)
+
+func init() {
+	Command = &cobra.Command{
+		Use:     "packetcapture NAME",
+		Short:   "Download the capture file of a PacketCapture",
+		Long:    "Download the pcapng file of a PacketCapture from the Antrea Agent which captured the packets.",
+		Aliases: []string{"pcap", "packetcaptures"},
+		Example: `  Download the capture file of PacketCapture pc1 to ./pc1.pcapng
+  $antctl packetcapture pc1
+  Download the capture file of PacketCapture pc1 to /tmp/pc1.pcapng
+  $antctl packetcapture pc1 -o /tmp/pc1.pcapng
+`,
+		RunE: runE,
+		Args: cobra.ExactArgs(1),
+	}
+
+	Command.Flags().StringVarP(&option.outputFile, "output", "o", "", "path of the output file, defaults to NAME.pcapng in the current directory")
+}
+
+func runE(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	kubeconfig, err := raw.ResolveKubeconfig(cmd)
+	if err != nil {
+		return err
+	}
+	kubeconfig.GroupVersion = &schema.GroupVersion{Group: "", Version: ""}
+	restconfigTmpl := rest.CopyConfig(kubeconfig)
+	raw.SetupKubeconfig(restconfigTmpl)
+
+	k8sClientset, antreaClientset, err := raw.SetupClients(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	pc, err := antreaClientset.CrdV1alpha1().PacketCaptures().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error when getting PacketCapture %s: %w", name, err)
+	}
+	if pc.Status.Phase != v1alpha1.PacketCaptureSucceeded {
+		return fmt.Errorf("PacketCapture %s is not completed, current phase: %q, reason: %q", name, pc.Status.Phase, pc.Status.Reason)
+	}
+
+	agentClientCfg := restconfigTmpl
+	// In the Agent Pod, antctl can only talk to the local Agent.
+	if !(runtime.Mode == runtime.ModeAgent && runtime.InPod) {
+		agentClientCfg, err = raw.CreateAgentClientCfg(k8sClientset, antreaClientset, restconfigTmpl, pc.Status.Node)
+		if err != nil {
+			return fmt.Errorf("error when creating Agent client config: %w", err)
+		}
+	}
+	agentClient, err := rest.RESTClientFor(agentClientCfg)
+	if err != nil {
+		return fmt.Errorf("error when creating Agent client: %w", err)
+	}
+	u := url.URL{Path: "/packetcaptures", RawQuery: url.Values{"name": []string{name}}.Encode()}
+	data, err := agentClient.Get().RequestURI(u.RequestURI()).DoRaw(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error when downloading the capture file of PacketCapture %s: %w", name, err)
+	}
+
+	outputFile := option.outputFile
+	if outputFile == "" {
+		outputFile = name + ".pcapng"
+	}
+	if err := ioutil.WriteFile(outputFile, data, 0644); err != nil {
+		return fmt.Errorf("error when writing the capture file: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Capture file of PacketCapture %s written to %s\n", name, outputFile)
+	return nil
+}
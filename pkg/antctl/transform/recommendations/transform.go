@@ -0,0 +1,37 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recommendations
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/recommendations"
+)
+
+func Transform(reader io.Reader, _ bool, _ map[string]string) (interface{}, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(recommendations.Response)
+	err = json.Unmarshal(b, resp)
+	if err != nil {
+		return nil, err
+	}
+	// Output the recommended policies as YAML documents, which can be applied with kubectl.
+	return []byte(resp.Policies), nil
+}
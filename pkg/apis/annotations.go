@@ -0,0 +1,38 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apis
+
+const (
+	// ServiceExternalIPPoolAnnotationKey is the key of the Service annotation which specifies the ExternalIPPool the
+	// LoadBalancer IP of the Service is allocated from. It's used by both the antrea-controller, which allocates the
+	// IP, and the antrea-agent, which announces the IP.
+	ServiceExternalIPPoolAnnotationKey = "service.antrea.io/external-ip-pool"
+
+	// ServiceLoadBalancingModeAnnotationKey is the key of the Service annotation which specifies the algorithm used by
+	// AntreaProxy to select the Endpoints of the new connections to the Service: "Weighted", "ConsistentHash" or
+	// "SourceIPHash". The new connections are uniformly load-balanced across the Endpoints when it's not set.
+	ServiceLoadBalancingModeAnnotationKey = "service.antrea.io/load-balancing-mode"
+	// EndpointSliceWeightAnnotationKey is the key of the EndpointSlice annotation which specifies the relative weight,
+	// from 1 to 100, of the Endpoints of the EndpointSlice, for the Services using the "Weighted" load balancing mode.
+	EndpointSliceWeightAnnotationKey = "service.antrea.io/endpoint-weight"
+	// PodEndpointWeightAnnotationKey is the key of the Pod annotation which specifies the relative weight, from 1 to
+	// 100, of the Endpoints of the Pod, for the Services using the "Weighted" load balancing mode. It takes precedence
+	// over the weight of the EndpointSlice.
+	PodEndpointWeightAnnotationKey = "service.antrea.io/endpoint-weight"
+	// ServiceLoadBalancerModeAnnotationKey is the key of the Service annotation which specifies how AntreaProxy
+	// forwards the traffic of the LoadBalancer IPs of the Service to remote Endpoints: "NAT" or "DSR". The traffic is
+	// forwarded with NAT when it's not set.
+	ServiceLoadBalancerModeAnnotationKey = "service.antrea.io/load-balancer-mode"
+)
@@ -217,6 +217,9 @@ type NetworkPolicyRule struct {
 	To NetworkPolicyPeer
 	// Services is a list of services which should be matched.
 	Services []Service
+	// L7Protocols is a list of L7 protocols which should be matched. If it's set,
+	// only the requests matching one of the protocols are allowed.
+	L7Protocols []L7Protocol
 	// Name describes the intention of this rule.
 	// Name should be unique within the policy.
 	Name string
@@ -230,6 +233,10 @@ type NetworkPolicyRule struct {
 	// EnableLogging is used to indicate if agent should generate logs
 	// when rules are matched. Should be default to false.
 	EnableLogging bool
+	// Audit indicates that the Drop or Reject action of the rule is not enforced:
+	// the traffic matching the rule is allowed and logged as it would have been
+	// dropped or rejected.
+	Audit bool
 	// AppliedToGroups is a list of names of AppliedToGroups to which this rule applies.
 	// Cannot be set in conjunction with NetworkPolicy.AppliedToGroups of the NetworkPolicy
 	// that this Rule is referred to.
@@ -263,6 +270,22 @@ type Service struct {
 	EndPort *int32
 }
 
+// L7Protocol defines an L7 protocol matched by a rule. Only one protocol can be set.
+type L7Protocol struct {
+	HTTP *HTTPProtocol
+}
+
+// HTTPProtocol matches HTTP requests with specific host, method, and path. The
+// fields that are not provided match any value.
+type HTTPProtocol struct {
+	// Host represents the hostname present in the URI or the HTTP Host header to match.
+	Host string
+	// Method represents the HTTP method to match.
+	Method string
+	// Path represents the URI path prefix to match.
+	Path string
+}
+
 // NetworkPolicyPeer describes a peer of NetworkPolicyRules.
 // It could be a list of names of AddressGroups and/or a list of IPBlock.
 type NetworkPolicyPeer struct {
@@ -439,10 +439,38 @@ func (m *GroupReference) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GroupReference proto.InternalMessageInfo
 
+func (m *HTTPProtocol) Reset()      { *m = HTTPProtocol{} }
+func (*HTTPProtocol) ProtoMessage() {}
+func (*HTTPProtocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fbaa7d016762fa1d, []int{14}
+}
+func (m *HTTPProtocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HTTPProtocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *HTTPProtocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTTPProtocol.Merge(m, src)
+}
+func (m *HTTPProtocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *HTTPProtocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTTPProtocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTTPProtocol proto.InternalMessageInfo
+
 func (m *IPBlock) Reset()      { *m = IPBlock{} }
 func (*IPBlock) ProtoMessage() {}
 func (*IPBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{14}
+	return fileDescriptor_fbaa7d016762fa1d, []int{15}
 }
 func (m *IPBlock) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -470,7 +498,7 @@ var xxx_messageInfo_IPBlock proto.InternalMessageInfo
 func (m *IPNet) Reset()      { *m = IPNet{} }
 func (*IPNet) ProtoMessage() {}
 func (*IPNet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{15}
+	return fileDescriptor_fbaa7d016762fa1d, []int{16}
 }
 func (m *IPNet) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -495,10 +523,38 @@ func (m *IPNet) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_IPNet proto.InternalMessageInfo
 
+func (m *L7Protocol) Reset()      { *m = L7Protocol{} }
+func (*L7Protocol) ProtoMessage() {}
+func (*L7Protocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fbaa7d016762fa1d, []int{17}
+}
+func (m *L7Protocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *L7Protocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *L7Protocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_L7Protocol.Merge(m, src)
+}
+func (m *L7Protocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *L7Protocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_L7Protocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_L7Protocol proto.InternalMessageInfo
+
 func (m *NamedPort) Reset()      { *m = NamedPort{} }
 func (*NamedPort) ProtoMessage() {}
 func (*NamedPort) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{16}
+	return fileDescriptor_fbaa7d016762fa1d, []int{18}
 }
 func (m *NamedPort) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -526,7 +582,7 @@ var xxx_messageInfo_NamedPort proto.InternalMessageInfo
 func (m *NetworkPolicy) Reset()      { *m = NetworkPolicy{} }
 func (*NetworkPolicy) ProtoMessage() {}
 func (*NetworkPolicy) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{17}
+	return fileDescriptor_fbaa7d016762fa1d, []int{19}
 }
 func (m *NetworkPolicy) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -554,7 +610,7 @@ var xxx_messageInfo_NetworkPolicy proto.InternalMessageInfo
 func (m *NetworkPolicyList) Reset()      { *m = NetworkPolicyList{} }
 func (*NetworkPolicyList) ProtoMessage() {}
 func (*NetworkPolicyList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{18}
+	return fileDescriptor_fbaa7d016762fa1d, []int{20}
 }
 func (m *NetworkPolicyList) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -582,7 +638,7 @@ var xxx_messageInfo_NetworkPolicyList proto.InternalMessageInfo
 func (m *NetworkPolicyNodeStatus) Reset()      { *m = NetworkPolicyNodeStatus{} }
 func (*NetworkPolicyNodeStatus) ProtoMessage() {}
 func (*NetworkPolicyNodeStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{19}
+	return fileDescriptor_fbaa7d016762fa1d, []int{21}
 }
 func (m *NetworkPolicyNodeStatus) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -610,7 +666,7 @@ var xxx_messageInfo_NetworkPolicyNodeStatus proto.InternalMessageInfo
 func (m *NetworkPolicyPeer) Reset()      { *m = NetworkPolicyPeer{} }
 func (*NetworkPolicyPeer) ProtoMessage() {}
 func (*NetworkPolicyPeer) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{20}
+	return fileDescriptor_fbaa7d016762fa1d, []int{22}
 }
 func (m *NetworkPolicyPeer) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -638,7 +694,7 @@ var xxx_messageInfo_NetworkPolicyPeer proto.InternalMessageInfo
 func (m *NetworkPolicyReference) Reset()      { *m = NetworkPolicyReference{} }
 func (*NetworkPolicyReference) ProtoMessage() {}
 func (*NetworkPolicyReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{21}
+	return fileDescriptor_fbaa7d016762fa1d, []int{23}
 }
 func (m *NetworkPolicyReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -666,7 +722,7 @@ var xxx_messageInfo_NetworkPolicyReference proto.InternalMessageInfo
 func (m *NetworkPolicyRule) Reset()      { *m = NetworkPolicyRule{} }
 func (*NetworkPolicyRule) ProtoMessage() {}
 func (*NetworkPolicyRule) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{22}
+	return fileDescriptor_fbaa7d016762fa1d, []int{24}
 }
 func (m *NetworkPolicyRule) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -694,7 +750,7 @@ var xxx_messageInfo_NetworkPolicyRule proto.InternalMessageInfo
 func (m *NetworkPolicyStats) Reset()      { *m = NetworkPolicyStats{} }
 func (*NetworkPolicyStats) ProtoMessage() {}
 func (*NetworkPolicyStats) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{23}
+	return fileDescriptor_fbaa7d016762fa1d, []int{25}
 }
 func (m *NetworkPolicyStats) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -722,7 +778,7 @@ var xxx_messageInfo_NetworkPolicyStats proto.InternalMessageInfo
 func (m *NetworkPolicyStatus) Reset()      { *m = NetworkPolicyStatus{} }
 func (*NetworkPolicyStatus) ProtoMessage() {}
 func (*NetworkPolicyStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{24}
+	return fileDescriptor_fbaa7d016762fa1d, []int{26}
 }
 func (m *NetworkPolicyStatus) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -750,7 +806,7 @@ var xxx_messageInfo_NetworkPolicyStatus proto.InternalMessageInfo
 func (m *NodeStatsSummary) Reset()      { *m = NodeStatsSummary{} }
 func (*NodeStatsSummary) ProtoMessage() {}
 func (*NodeStatsSummary) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{25}
+	return fileDescriptor_fbaa7d016762fa1d, []int{27}
 }
 func (m *NodeStatsSummary) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -778,7 +834,7 @@ var xxx_messageInfo_NodeStatsSummary proto.InternalMessageInfo
 func (m *PodReference) Reset()      { *m = PodReference{} }
 func (*PodReference) ProtoMessage() {}
 func (*PodReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{26}
+	return fileDescriptor_fbaa7d016762fa1d, []int{28}
 }
 func (m *PodReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -806,7 +862,7 @@ var xxx_messageInfo_PodReference proto.InternalMessageInfo
 func (m *Service) Reset()      { *m = Service{} }
 func (*Service) ProtoMessage() {}
 func (*Service) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{27}
+	return fileDescriptor_fbaa7d016762fa1d, []int{29}
 }
 func (m *Service) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -834,7 +890,7 @@ var xxx_messageInfo_Service proto.InternalMessageInfo
 func (m *ServiceReference) Reset()      { *m = ServiceReference{} }
 func (*ServiceReference) ProtoMessage() {}
 func (*ServiceReference) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fbaa7d016762fa1d, []int{28}
+	return fileDescriptor_fbaa7d016762fa1d, []int{30}
 }
 func (m *ServiceReference) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -874,8 +930,10 @@ func init() {
 	proto.RegisterType((*GroupAssociation)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.GroupAssociation")
 	proto.RegisterType((*GroupMember)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.GroupMember")
 	proto.RegisterType((*GroupReference)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.GroupReference")
+	proto.RegisterType((*HTTPProtocol)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.HTTPProtocol")
 	proto.RegisterType((*IPBlock)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.IPBlock")
 	proto.RegisterType((*IPNet)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.IPNet")
+	proto.RegisterType((*L7Protocol)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.L7Protocol")
 	proto.RegisterType((*NamedPort)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.NamedPort")
 	proto.RegisterType((*NetworkPolicy)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicy")
 	proto.RegisterType((*NetworkPolicyList)(nil), "antrea_io.antrea.pkg.apis.controlplane.v1beta2.NetworkPolicyList")
@@ -896,126 +954,133 @@ func init() {
 }
 
 var fileDescriptor_fbaa7d016762fa1d = []byte{
-	// 1892 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0x4d, 0x6c, 0x23, 0x49,
-	0x15, 0x4e, 0xfb, 0x27, 0x89, 0x5f, 0x9c, 0xc4, 0xa9, 0xec, 0x30, 0x66, 0x19, 0xec, 0x6c, 0xf3,
-	0xa3, 0x1c, 0xd8, 0xf6, 0x26, 0xcc, 0xee, 0x0c, 0xec, 0x0f, 0xc4, 0x9b, 0x4c, 0x64, 0x69, 0xd6,
-	0x6b, 0x2a, 0x59, 0x8d, 0x84, 0x58, 0xd8, 0x4e, 0x77, 0xd9, 0x69, 0xd2, 0xee, 0x6a, 0xba, 0xcb,
-	0x61, 0x22, 0x24, 0xb4, 0x08, 0x38, 0x2c, 0x20, 0xc1, 0x8d, 0x33, 0x27, 0x2e, 0x9c, 0xb9, 0x73,
-	0x40, 0x1a, 0x71, 0x5a, 0x84, 0x10, 0x7b, 0xb2, 0x18, 0x23, 0x40, 0x1c, 0xb8, 0x71, 0xca, 0x5e,
-	0x50, 0x55, 0x57, 0xff, 0x3a, 0x9e, 0x8c, 0x27, 0x99, 0x20, 0xb1, 0x7b, 0xb2, 0xbb, 0xea, 0xbd,
-	0xf7, 0xbd, 0x57, 0xdf, 0xab, 0xf7, 0xaa, 0xba, 0xe1, 0x35, 0xdd, 0x61, 0x1e, 0xd1, 0x35, 0x8b,
-	0x36, 0x82, 0x7f, 0x0d, 0xf7, 0xa8, 0xd7, 0xd0, 0x5d, 0xcb, 0x6f, 0x18, 0xd4, 0x61, 0x1e, 0xb5,
-	0x5d, 0x5b, 0x77, 0x48, 0xe3, 0x78, 0xe3, 0x80, 0x30, 0x7d, 0xb3, 0xd1, 0x23, 0x0e, 0xf1, 0x74,
-	0x46, 0x4c, 0xcd, 0xf5, 0x28, 0xa3, 0x48, 0x0b, 0xb4, 0xbe, 0x65, 0x51, 0xf9, 0x4f, 0x73, 0x8f,
-	0x7a, 0x1a, 0xd7, 0xd7, 0x92, 0xfa, 0x9a, 0xd4, 0x7f, 0xf6, 0xf6, 0x64, 0x3c, 0x9f, 0xe9, 0xcc,
-	0x6f, 0x1c, 0x6f, 0xe8, 0xb6, 0x7b, 0xa8, 0x6f, 0x64, 0x91, 0x9e, 0x7d, 0xbe, 0x67, 0xb1, 0xc3,
-	0xc1, 0x81, 0x66, 0xd0, 0x7e, 0xa3, 0x47, 0x7b, 0xb4, 0x21, 0x86, 0x0f, 0x06, 0x5d, 0xf1, 0x24,
-	0x1e, 0xc4, 0x3f, 0x29, 0x7e, 0xf3, 0xe8, 0xb6, 0x2f, 0x50, 0x5c, 0xab, 0xaf, 0x1b, 0x87, 0x96,
-	0x43, 0xbc, 0x93, 0x18, 0xab, 0x4f, 0x98, 0xde, 0x38, 0x1e, 0x07, 0x69, 0x4c, 0xd2, 0xf2, 0x06,
-	0x0e, 0xb3, 0xfa, 0x64, 0x4c, 0xe1, 0xa5, 0xf3, 0x14, 0x7c, 0xe3, 0x90, 0xf4, 0xf5, 0x31, 0xbd,
-	0x2f, 0x4e, 0xd2, 0x1b, 0x30, 0xcb, 0x6e, 0x58, 0x0e, 0xf3, 0x99, 0x97, 0x55, 0x52, 0xff, 0xa9,
-	0x40, 0x79, 0xcb, 0x34, 0x3d, 0xe2, 0xfb, 0xbb, 0x1e, 0x1d, 0xb8, 0xe8, 0x1d, 0x98, 0xe7, 0x91,
-	0x98, 0x3a, 0xd3, 0xab, 0xca, 0x9a, 0xb2, 0xbe, 0xb0, 0xf9, 0x82, 0x16, 0x18, 0xd6, 0x92, 0x86,
-	0x63, 0x4e, 0xb8, 0xb4, 0x76, 0xbc, 0xa1, 0xbd, 0x79, 0xf0, 0x6d, 0x62, 0xb0, 0x37, 0x08, 0xd3,
-	0x9b, 0xe8, 0xc1, 0xb0, 0x3e, 0x33, 0x1a, 0xd6, 0x21, 0x1e, 0xc3, 0x91, 0x55, 0x34, 0x80, 0x72,
-	0x8f, 0x43, 0xbd, 0x41, 0xfa, 0x07, 0xc4, 0xf3, 0xab, 0xb9, 0xb5, 0xfc, 0xfa, 0xc2, 0xe6, 0xcb,
-	0x53, 0xd2, 0xae, 0xed, 0xc6, 0x36, 0x9a, 0xcf, 0x48, 0xc0, 0x72, 0x62, 0xd0, 0xc7, 0x29, 0x18,
-	0xf5, 0x4f, 0x0a, 0x54, 0x92, 0x91, 0xde, 0xb5, 0x7c, 0x86, 0xbe, 0x31, 0x16, 0xad, 0xf6, 0x78,
-	0xd1, 0x72, 0x6d, 0x11, 0x6b, 0x45, 0x42, 0xcf, 0x87, 0x23, 0x89, 0x48, 0x75, 0x28, 0x5a, 0x8c,
-	0xf4, 0xc3, 0x10, 0x5f, 0x99, 0x36, 0xc4, 0xa4, 0xbb, 0xcd, 0x45, 0x09, 0x54, 0x6c, 0x71, 0x93,
-	0x38, 0xb0, 0xac, 0xbe, 0x97, 0x87, 0x95, 0xa4, 0x58, 0x47, 0x67, 0xc6, 0xe1, 0x15, 0x90, 0xf8,
-	0x23, 0x05, 0x56, 0x74, 0xd3, 0x24, 0xe6, 0xee, 0x25, 0x53, 0xf9, 0x49, 0x09, 0xcb, 0xa3, 0x4a,
-	0x5b, 0xc7, 0xe3, 0x80, 0xe8, 0x27, 0x0a, 0xac, 0x7a, 0xa4, 0x4f, 0x8f, 0x33, 0x8e, 0xe4, 0x2f,
-	0xee, 0xc8, 0xa7, 0xa4, 0x23, 0xab, 0x78, 0xdc, 0x3e, 0x3e, 0x0b, 0x54, 0xfd, 0x97, 0x02, 0x4b,
-	0x5b, 0xae, 0x6b, 0x5b, 0xc4, 0xdc, 0xa7, 0xff, 0xe7, 0xbb, 0xe9, 0x2f, 0x0a, 0xa0, 0x74, 0xac,
-	0x57, 0xb0, 0x9f, 0x8c, 0xf4, 0x7e, 0x7a, 0x6d, 0xea, 0xfd, 0x94, 0x72, 0x78, 0xc2, 0x8e, 0xfa,
-	0x69, 0x1e, 0x56, 0xd3, 0x82, 0x1f, 0xef, 0xa9, 0xff, 0xdd, 0x9e, 0xfa, 0x30, 0x07, 0xab, 0xaf,
-	0xdb, 0x03, 0x9f, 0x11, 0x2f, 0xe5, 0xe4, 0xd3, 0x67, 0xe3, 0x07, 0x0a, 0x54, 0x48, 0xb7, 0x4b,
-	0x0c, 0x66, 0x1d, 0x93, 0x4b, 0x24, 0xa3, 0x2a, 0x51, 0x2b, 0x3b, 0x19, 0xe3, 0x78, 0x0c, 0x0e,
-	0x7d, 0x1f, 0x56, 0xa2, 0xb1, 0x56, 0xa7, 0x69, 0x53, 0xe3, 0x28, 0xe4, 0xe1, 0xc5, 0x69, 0x7d,
-	0x68, 0x75, 0xda, 0x84, 0xc5, 0xa9, 0xb0, 0x93, 0xb5, 0x8b, 0xc7, 0xa1, 0xd4, 0x7f, 0x28, 0xb0,
-	0xb0, 0xd3, 0xfb, 0x08, 0x1c, 0x0e, 0xfe, 0xa8, 0xc0, 0x72, 0x22, 0xd0, 0x2b, 0xa8, 0x65, 0xef,
-	0xa4, 0x6b, 0xd9, 0xd4, 0x11, 0x26, 0xbc, 0x9d, 0x50, 0xc8, 0x7e, 0x96, 0x87, 0x4a, 0x42, 0x2a,
-	0xa8, 0x62, 0x26, 0x00, 0x8d, 0xd6, 0xfd, 0x52, 0x39, 0x4c, 0xd8, 0xfd, 0xb8, 0x92, 0x9d, 0x51,
-	0xc9, 0x6c, 0xb8, 0xbe, 0x73, 0x9f, 0x11, 0xcf, 0xd1, 0xed, 0x1d, 0x87, 0x59, 0xec, 0x04, 0x93,
-	0x2e, 0xf1, 0x88, 0x63, 0x10, 0xb4, 0x06, 0x05, 0x47, 0xef, 0x13, 0x41, 0x47, 0xa9, 0x59, 0x96,
-	0xa6, 0x0b, 0x6d, 0xbd, 0x4f, 0xb0, 0x98, 0x41, 0x0d, 0x28, 0xf1, 0x5f, 0xdf, 0xd5, 0x0d, 0x52,
-	0xcd, 0x09, 0xb1, 0x15, 0x29, 0x56, 0x6a, 0x87, 0x13, 0x38, 0x96, 0x51, 0x3f, 0x54, 0xa0, 0x22,
-	0xe0, 0xb7, 0x7c, 0x9f, 0x1a, 0x96, 0xce, 0x2c, 0xea, 0x5c, 0x4d, 0x0b, 0xab, 0xe8, 0x12, 0x51,
-	0xc6, 0xff, 0xc4, 0xdd, 0x5a, 0x68, 0x47, 0x8b, 0x14, 0xd7, 0xcd, 0xad, 0x8c, 0x7d, 0x3c, 0x86,
-	0xa8, 0xfe, 0x27, 0x07, 0x0b, 0x89, 0xc5, 0x47, 0xf7, 0x20, 0xef, 0x52, 0x53, 0xc6, 0x3c, 0xf5,
-	0x31, 0xbc, 0x43, 0xcd, 0xd8, 0x8d, 0xb9, 0xd1, 0xb0, 0x9e, 0xe7, 0x23, 0xdc, 0x22, 0xfa, 0xa1,
-	0x02, 0x4b, 0x24, 0xc5, 0xaa, 0x60, 0x67, 0x61, 0x73, 0x77, 0xea, 0xfd, 0x7c, 0x76, 0x6e, 0x34,
-	0xd1, 0x68, 0x58, 0x5f, 0xca, 0x4c, 0x66, 0x20, 0xd1, 0xe7, 0x21, 0x6f, 0xb9, 0x41, 0x5a, 0x97,
-	0x9b, 0xcf, 0x70, 0x07, 0x5b, 0x1d, 0xff, 0x74, 0x58, 0x2f, 0xb5, 0x3a, 0xf2, 0x6e, 0x80, 0xb9,
-	0x00, 0xfa, 0x26, 0x14, 0x5d, 0xea, 0x31, 0xbf, 0x5a, 0x10, 0x8c, 0x7c, 0x69, 0x5a, 0x1f, 0x79,
-	0xa6, 0x99, 0x1d, 0xea, 0xb1, 0xb8, 0xe2, 0xf0, 0x27, 0x1f, 0x07, 0x66, 0xd5, 0x5f, 0x2b, 0xb0,
-	0x94, 0x66, 0x2d, 0x9d, 0xb8, 0xca, 0xf9, 0x89, 0x1b, 0xed, 0x85, 0xdc, 0xc4, 0xbd, 0xd0, 0x84,
-	0xfc, 0xc0, 0x32, 0xab, 0x79, 0x21, 0xf0, 0x82, 0x14, 0xc8, 0xbf, 0xd5, 0xda, 0x3e, 0x1d, 0xd6,
-	0x9f, 0x9b, 0x74, 0x07, 0x66, 0x27, 0x2e, 0xf1, 0xb5, 0xb7, 0x5a, 0xdb, 0x98, 0x2b, 0xab, 0xbf,
-	0x53, 0x60, 0x4e, 0x76, 0x39, 0x74, 0x0f, 0x0a, 0x86, 0x65, 0x7a, 0x32, 0x3b, 0x9e, 0xb0, 0xaf,
-	0x46, 0x8e, 0xbe, 0xde, 0xda, 0xc6, 0x58, 0x18, 0x44, 0x6f, 0xc3, 0x2c, 0xb9, 0x6f, 0x10, 0x97,
-	0xc9, 0x1d, 0xf0, 0x84, 0xa6, 0x97, 0xa4, 0xe9, 0xd9, 0x1d, 0x61, 0x0c, 0x4b, 0xa3, 0x6a, 0x17,
-	0x8a, 0x42, 0x00, 0x7d, 0x06, 0x72, 0x96, 0x2b, 0xdc, 0x2f, 0x37, 0x57, 0x47, 0xc3, 0x7a, 0xae,
-	0xd5, 0x49, 0x93, 0x9f, 0xb3, 0x5c, 0x74, 0x1b, 0xca, 0xae, 0x47, 0xba, 0xd6, 0xfd, 0xbb, 0xc4,
-	0xe9, 0xb1, 0x43, 0xb1, 0xbe, 0xc5, 0xb8, 0x37, 0x76, 0x12, 0x73, 0x38, 0x25, 0xa9, 0xbe, 0xa7,
-	0x40, 0x29, 0x62, 0x9e, 0xf3, 0xc3, 0xc9, 0x16, 0x70, 0xc5, 0x38, 0x6c, 0x3e, 0x87, 0xc5, 0xcc,
-	0x63, 0x30, 0x78, 0x1b, 0xe6, 0xc5, 0xdb, 0x07, 0x83, 0xda, 0x92, 0xc6, 0x1b, 0x61, 0xa7, 0xec,
-	0xc8, 0xf1, 0xd3, 0xc4, 0x7f, 0x1c, 0x49, 0xab, 0xff, 0xce, 0xc3, 0x62, 0x9b, 0xb0, 0xef, 0x52,
-	0xef, 0xa8, 0x43, 0x6d, 0xcb, 0x38, 0xb9, 0x82, 0x9a, 0xd6, 0x85, 0xa2, 0x37, 0xb0, 0x49, 0x58,
-	0xc7, 0xb6, 0xa6, 0xde, 0x35, 0x49, 0x7f, 0xf1, 0xc0, 0x26, 0xf1, 0xee, 0xe1, 0x4f, 0x3e, 0x0e,
-	0xcc, 0xa3, 0x57, 0x61, 0x59, 0x4f, 0xdd, 0x3b, 0x82, 0x1d, 0x5d, 0x12, 0x9c, 0x2e, 0xa7, 0xaf,
-	0x24, 0x3e, 0xce, 0xca, 0xa2, 0x75, 0xbe, 0xa8, 0x16, 0xf5, 0x78, 0x0d, 0x2a, 0xac, 0x29, 0xeb,
-	0x4a, 0xb3, 0x1c, 0x2c, 0x68, 0x30, 0x86, 0xa3, 0x59, 0x74, 0x13, 0xca, 0xcc, 0x22, 0x5e, 0x38,
-	0x53, 0x2d, 0x0a, 0x2a, 0x2b, 0x3c, 0x0d, 0xf6, 0x13, 0xe3, 0x38, 0x25, 0x85, 0x7c, 0x28, 0xf9,
-	0x74, 0xe0, 0x19, 0x04, 0x93, 0x6e, 0x75, 0x56, 0xac, 0xf4, 0x9d, 0x8b, 0x2d, 0x45, 0x54, 0xe3,
-	0x16, 0x79, 0x35, 0xd8, 0x0b, 0x8d, 0xe3, 0x18, 0x47, 0xfd, 0xb3, 0x02, 0x2b, 0x29, 0xa5, 0x2b,
-	0x38, 0x99, 0x1d, 0xa4, 0x4f, 0x66, 0xaf, 0x5e, 0x28, 0xc8, 0x09, 0x67, 0xb3, 0xef, 0xc1, 0xf5,
-	0x94, 0x58, 0x9b, 0x9a, 0x64, 0x8f, 0xe9, 0x6c, 0xe0, 0xa3, 0x2f, 0xc0, 0xbc, 0x43, 0x4d, 0xd2,
-	0x8e, 0x0f, 0x04, 0x91, 0xb3, 0x6d, 0x39, 0x8e, 0x23, 0x09, 0xb4, 0x09, 0x20, 0x5f, 0xe9, 0x59,
-	0xd4, 0x11, 0x5b, 0x2e, 0x1f, 0xa7, 0xf3, 0x6e, 0x34, 0x83, 0x13, 0x52, 0xea, 0x1f, 0x72, 0x99,
-	0x45, 0xed, 0x10, 0xe2, 0xa1, 0x5b, 0xb0, 0xa8, 0x27, 0x5e, 0x24, 0xf9, 0x55, 0x45, 0x24, 0xdf,
-	0xca, 0x68, 0x58, 0x5f, 0x4c, 0xbe, 0x61, 0xf2, 0x71, 0x5a, 0x0e, 0x11, 0x98, 0xb7, 0x5c, 0x79,
-	0x37, 0x09, 0x96, 0xec, 0xd6, 0xf4, 0x85, 0x4e, 0xe8, 0xc7, 0x91, 0x46, 0x97, 0x92, 0xc8, 0x34,
-	0xaa, 0x43, 0xb1, 0xfb, 0x1d, 0xd3, 0x09, 0x37, 0x45, 0x89, 0xaf, 0xe9, 0x9d, 0xaf, 0x6d, 0xb7,
-	0x7d, 0x1c, 0x8c, 0x23, 0x06, 0xc0, 0xe8, 0x1e, 0xf1, 0x8e, 0x2d, 0x83, 0x84, 0x2d, 0xee, 0xab,
-	0xd3, 0x7a, 0x22, 0xf5, 0x13, 0xfd, 0x37, 0x5c, 0xcc, 0xfd, 0xc8, 0x36, 0x4e, 0xe0, 0xf0, 0x2b,
-	0xd2, 0x27, 0xce, 0x4e, 0x6b, 0xf4, 0x22, 0x14, 0x78, 0xdb, 0x91, 0x2c, 0x3e, 0x17, 0x16, 0xc2,
-	0xfd, 0x13, 0x97, 0x9c, 0x0e, 0xeb, 0x69, 0x0a, 0xf8, 0x20, 0x16, 0xe2, 0x53, 0x9f, 0xf5, 0xa2,
-	0x82, 0x9b, 0x3f, 0xaf, 0x65, 0x16, 0x2e, 0xd2, 0x32, 0x7f, 0x55, 0xcc, 0x64, 0x0d, 0x2f, 0x5e,
-	0xe8, 0x15, 0x28, 0x99, 0x96, 0xc7, 0xaf, 0x8d, 0xd4, 0x91, 0x81, 0xd6, 0x42, 0x67, 0xb7, 0xc3,
-	0x89, 0xd3, 0xe4, 0x03, 0x8e, 0x15, 0x90, 0x01, 0x85, 0xae, 0x47, 0xfb, 0xf2, 0xcc, 0x74, 0xb1,
-	0xca, 0xca, 0x93, 0x38, 0x0e, 0xfe, 0x8e, 0x47, 0xfb, 0x58, 0x18, 0x47, 0x6f, 0x43, 0x8e, 0x51,
-	0xb1, 0x38, 0x97, 0x02, 0x01, 0x12, 0x22, 0xb7, 0x4f, 0x71, 0x8e, 0x51, 0x9e, 0xfe, 0x7e, 0x3a,
-	0xe9, 0x6e, 0x3d, 0x61, 0xd2, 0xc5, 0xe9, 0x1f, 0x65, 0x5a, 0x64, 0x9a, 0x97, 0x05, 0x37, 0x53,
-	0xb0, 0xe3, 0x9e, 0x39, 0x56, 0xe2, 0xef, 0xc1, 0xac, 0x1e, 0x70, 0x32, 0x2b, 0x38, 0xf9, 0x0a,
-	0x3f, 0x3f, 0x6c, 0x85, 0x64, 0x6c, 0x3c, 0xe2, 0x0b, 0x8d, 0x67, 0x46, 0xdf, 0x4b, 0x34, 0xce,
-	0x70, 0xa0, 0x84, 0xa5, 0x39, 0xf4, 0x32, 0x2c, 0x12, 0x47, 0x3f, 0xb0, 0xc9, 0x5d, 0xda, 0xeb,
-	0x59, 0x4e, 0xaf, 0x3a, 0xb7, 0xa6, 0xac, 0xcf, 0x37, 0xaf, 0x49, 0x5f, 0x16, 0x77, 0x92, 0x93,
-	0x38, 0x2d, 0x7b, 0x56, 0x87, 0x9b, 0x9f, 0xa2, 0xc3, 0x85, 0x79, 0x5e, 0x9a, 0x94, 0xe7, 0xea,
-	0xcf, 0xf3, 0x80, 0x52, 0x8c, 0xf1, 0x9a, 0xea, 0xf3, 0x53, 0xfa, 0xa2, 0x93, 0x1c, 0x96, 0x5d,
-	0xe3, 0xb2, 0xfa, 0x57, 0x14, 0x7d, 0x7a, 0x3e, 0x8d, 0x89, 0x5c, 0x28, 0x33, 0x4f, 0xef, 0x76,
-	0x2d, 0x43, 0x78, 0x25, 0x93, 0xfe, 0xa5, 0x47, 0xf8, 0x20, 0x3e, 0x5f, 0x69, 0x11, 0x1d, 0xfb,
-	0x09, 0xed, 0xf8, 0xe4, 0x96, 0x1c, 0xc5, 0x29, 0x04, 0xf4, 0xae, 0x02, 0x15, 0x7e, 0xb6, 0x48,
-	0x8a, 0xc8, 0xcb, 0xef, 0x97, 0x1f, 0x1f, 0x16, 0x67, 0x2c, 0xc4, 0x37, 0xb1, 0xec, 0x0c, 0x1e,
-	0x43, 0x53, 0xff, 0xae, 0xc0, 0xea, 0x18, 0x23, 0x83, 0xab, 0x78, 0x7f, 0x67, 0x43, 0x91, 0x77,
-	0xc9, 0xb0, 0x27, 0xed, 0x5e, 0x88, 0xeb, 0xb8, 0x3f, 0xc7, 0x0d, 0x9d, 0x8f, 0xf9, 0x38, 0x00,
-	0x51, 0x7f, 0x5f, 0x80, 0x4a, 0x28, 0xe4, 0xef, 0x0d, 0xfa, 0x7d, 0xdd, 0xbb, 0x8a, 0xb3, 0xe9,
-	0x8f, 0x15, 0x58, 0x4e, 0x66, 0x99, 0x15, 0xc5, 0xdb, 0xbc, 0x50, 0xbc, 0x01, 0xd1, 0xd7, 0x25,
-	0xf6, 0x72, 0x3b, 0x0d, 0x81, 0xb3, 0x98, 0xe8, 0x37, 0x0a, 0xdc, 0x08, 0x50, 0xe4, 0xcb, 0xda,
-	0x8c, 0x86, 0xcc, 0xba, 0xcb, 0x70, 0xea, 0xb3, 0xd2, 0xa9, 0x1b, 0x5b, 0x8f, 0xc0, 0xc3, 0x8f,
-	0xf4, 0x06, 0xfd, 0x52, 0x81, 0x6b, 0x81, 0x40, 0xd6, 0xcf, 0xc2, 0xa5, 0xf9, 0xf9, 0x69, 0xe9,
-	0xe7, 0xb5, 0xad, 0xb3, 0x80, 0xf0, 0xd9, 0xf8, 0xaa, 0x0e, 0xe5, 0xe4, 0xeb, 0x86, 0xa7, 0xf1,
-	0x6a, 0xe8, 0xb7, 0x0a, 0xcc, 0xc9, 0x06, 0x83, 0x6e, 0x26, 0x6e, 0x62, 0x01, 0x44, 0xf5, 0xfc,
-	0x5b, 0x18, 0x6a, 0xcb, 0x3b, 0x60, 0xee, 0x9c, 0x9c, 0x1e, 0x30, 0xcb, 0xd6, 0x82, 0x0f, 0xcf,
-	0x5a, 0xcb, 0x61, 0x6f, 0x7a, 0x7b, 0xcc, 0xb3, 0x9c, 0x5e, 0x73, 0x3e, 0x73, 0x63, 0xfc, 0x1c,
-	0xcc, 0x11, 0x47, 0x5c, 0x2f, 0x45, 0x9b, 0x2e, 0x36, 0x17, 0x46, 0xc3, 0xfa, 0xdc, 0x4e, 0x30,
-	0x84, 0xc3, 0x39, 0x95, 0x40, 0x25, 0x7b, 0x3c, 0x7b, 0x0a, 0xeb, 0xd3, 0x7c, 0xfe, 0xc1, 0xc3,
-	0xda, 0xcc, 0xfb, 0x0f, 0x6b, 0x33, 0x1f, 0x3c, 0xac, 0xcd, 0xbc, 0x3b, 0xaa, 0x29, 0x0f, 0x46,
-	0x35, 0xe5, 0xfd, 0x51, 0x4d, 0xf9, 0x60, 0x54, 0x53, 0xfe, 0x3a, 0xaa, 0x29, 0xbf, 0xf8, 0x5b,
-	0x6d, 0xe6, 0xeb, 0x73, 0x92, 0xfa, 0xff, 0x06, 0x00, 0x00, 0xff, 0xff, 0xbe, 0xe6, 0x40, 0xcb,
-	0xef, 0x20, 0x00, 0x00,
+	// 2011 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0x23, 0x49,
+	0x15, 0x4f, 0xfb, 0x23, 0x89, 0x5f, 0x9c, 0xc4, 0xa9, 0xec, 0x30, 0x66, 0x19, 0xec, 0x6c, 0x03,
+	0xab, 0x1c, 0xd8, 0xf6, 0x26, 0xcc, 0xee, 0x0c, 0xec, 0x07, 0xc4, 0x9b, 0x4c, 0xd6, 0xd2, 0x8c,
+	0xd7, 0x54, 0xbc, 0x1a, 0x69, 0xc5, 0xc2, 0x76, 0xba, 0xcb, 0x76, 0x13, 0xbb, 0xab, 0xb7, 0xbb,
+	0x1c, 0x26, 0x20, 0xa1, 0x45, 0xc0, 0x61, 0x01, 0x09, 0x6e, 0xfc, 0x09, 0x48, 0x88, 0x33, 0x77,
+	0x0e, 0x48, 0x23, 0x4e, 0x8b, 0x10, 0x62, 0x4f, 0x16, 0x63, 0x04, 0x88, 0x03, 0x37, 0x4e, 0xd9,
+	0x0b, 0xaa, 0xea, 0xea, 0x4f, 0x27, 0x13, 0x9c, 0x64, 0x82, 0x04, 0x7b, 0xb2, 0xbb, 0xea, 0xbd,
+	0xf7, 0x7b, 0xaf, 0x7e, 0xaf, 0x5e, 0xbd, 0xea, 0x86, 0x57, 0x75, 0x9b, 0xb9, 0x44, 0xd7, 0x2c,
+	0x5a, 0xf3, 0xff, 0xd5, 0x9c, 0x83, 0x6e, 0x4d, 0x77, 0x2c, 0xaf, 0x66, 0x50, 0x9b, 0xb9, 0xb4,
+	0xef, 0xf4, 0x75, 0x9b, 0xd4, 0x0e, 0x37, 0xf6, 0x09, 0xd3, 0x37, 0x6b, 0x5d, 0x62, 0x13, 0x57,
+	0x67, 0xc4, 0xd4, 0x1c, 0x97, 0x32, 0x8a, 0x34, 0x5f, 0xeb, 0x1b, 0x16, 0x95, 0xff, 0x34, 0xe7,
+	0xa0, 0xab, 0x71, 0x7d, 0x2d, 0xae, 0xaf, 0x49, 0xfd, 0xa7, 0x6f, 0x9f, 0x8e, 0xe7, 0x31, 0x9d,
+	0x79, 0xb5, 0xc3, 0x0d, 0xbd, 0xef, 0xf4, 0xf4, 0x8d, 0x34, 0xd2, 0xd3, 0xcf, 0x75, 0x2d, 0xd6,
+	0x1b, 0xee, 0x6b, 0x06, 0x1d, 0xd4, 0xba, 0xb4, 0x4b, 0x6b, 0x62, 0x78, 0x7f, 0xd8, 0x11, 0x4f,
+	0xe2, 0x41, 0xfc, 0x93, 0xe2, 0x37, 0x0f, 0x6e, 0x7b, 0x02, 0xc5, 0xb1, 0x06, 0xba, 0xd1, 0xb3,
+	0x6c, 0xe2, 0x1e, 0x45, 0x58, 0x03, 0xc2, 0xf4, 0xda, 0xe1, 0x24, 0x48, 0xed, 0x34, 0x2d, 0x77,
+	0x68, 0x33, 0x6b, 0x40, 0x26, 0x14, 0x5e, 0x3c, 0x4b, 0xc1, 0x33, 0x7a, 0x64, 0xa0, 0x4f, 0xe8,
+	0x7d, 0xe1, 0x34, 0xbd, 0x21, 0xb3, 0xfa, 0x35, 0xcb, 0x66, 0x1e, 0x73, 0xd3, 0x4a, 0xea, 0xdf,
+	0x15, 0x28, 0x6e, 0x99, 0xa6, 0x4b, 0x3c, 0x6f, 0xd7, 0xa5, 0x43, 0x07, 0xbd, 0x03, 0xf3, 0x3c,
+	0x12, 0x53, 0x67, 0x7a, 0x59, 0x59, 0x53, 0xd6, 0x17, 0x36, 0x9f, 0xd7, 0x7c, 0xc3, 0x5a, 0xdc,
+	0x70, 0xc4, 0x09, 0x97, 0xd6, 0x0e, 0x37, 0xb4, 0x37, 0xf6, 0xbf, 0x49, 0x0c, 0x76, 0x8f, 0x30,
+	0xbd, 0x8e, 0x1e, 0x8e, 0xaa, 0x33, 0xe3, 0x51, 0x15, 0xa2, 0x31, 0x1c, 0x5a, 0x45, 0x43, 0x28,
+	0x76, 0x39, 0xd4, 0x3d, 0x32, 0xd8, 0x27, 0xae, 0x57, 0xce, 0xac, 0x65, 0xd7, 0x17, 0x36, 0x5f,
+	0x9a, 0x92, 0x76, 0x6d, 0x37, 0xb2, 0x51, 0x7f, 0x4a, 0x02, 0x16, 0x63, 0x83, 0x1e, 0x4e, 0xc0,
+	0xa8, 0x7f, 0x50, 0xa0, 0x14, 0x8f, 0xf4, 0xae, 0xe5, 0x31, 0xf4, 0xb5, 0x89, 0x68, 0xb5, 0xff,
+	0x2c, 0x5a, 0xae, 0x2d, 0x62, 0x2d, 0x49, 0xe8, 0xf9, 0x60, 0x24, 0x16, 0xa9, 0x0e, 0x79, 0x8b,
+	0x91, 0x41, 0x10, 0xe2, 0xcb, 0xd3, 0x86, 0x18, 0x77, 0xb7, 0xbe, 0x28, 0x81, 0xf2, 0x0d, 0x6e,
+	0x12, 0xfb, 0x96, 0xd5, 0xf7, 0xb3, 0xb0, 0x12, 0x17, 0x6b, 0xe9, 0xcc, 0xe8, 0x5d, 0x01, 0x89,
+	0x3f, 0x50, 0x60, 0x45, 0x37, 0x4d, 0x62, 0xee, 0x5e, 0x32, 0x95, 0x9f, 0x94, 0xb0, 0x2b, 0x5b,
+	0x69, 0xeb, 0x78, 0x12, 0x10, 0xfd, 0x48, 0x81, 0x55, 0x97, 0x0c, 0xe8, 0x61, 0xca, 0x91, 0xec,
+	0xc5, 0x1d, 0xf9, 0x94, 0x74, 0x64, 0x15, 0x4f, 0xda, 0xc7, 0x27, 0x81, 0xaa, 0xff, 0x50, 0x60,
+	0x69, 0xcb, 0x71, 0xfa, 0x16, 0x31, 0xdb, 0xf4, 0x7f, 0x7c, 0x37, 0xfd, 0x49, 0x01, 0x94, 0x8c,
+	0xf5, 0x0a, 0xf6, 0x93, 0x91, 0xdc, 0x4f, 0xaf, 0x4e, 0xbd, 0x9f, 0x12, 0x0e, 0x9f, 0xb2, 0xa3,
+	0x7e, 0x9c, 0x85, 0xd5, 0xa4, 0xe0, 0xc7, 0x7b, 0xea, 0xbf, 0xb7, 0xa7, 0x3e, 0xca, 0xc0, 0xea,
+	0x6b, 0xfd, 0xa1, 0xc7, 0x88, 0x9b, 0x70, 0xf2, 0xc9, 0xb3, 0xf1, 0x3d, 0x05, 0x4a, 0xa4, 0xd3,
+	0x21, 0x06, 0xb3, 0x0e, 0xc9, 0x25, 0x92, 0x51, 0x96, 0xa8, 0xa5, 0x9d, 0x94, 0x71, 0x3c, 0x01,
+	0x87, 0xbe, 0x0b, 0x2b, 0xe1, 0x58, 0xa3, 0x55, 0xef, 0x53, 0xe3, 0x20, 0xe0, 0xe1, 0x85, 0x69,
+	0x7d, 0x68, 0xb4, 0x9a, 0x84, 0x45, 0xa9, 0xb0, 0x93, 0xb6, 0x8b, 0x27, 0xa1, 0xd4, 0xbf, 0x29,
+	0xb0, 0xb0, 0xd3, 0xfd, 0x3f, 0x68, 0x0e, 0x7e, 0xaf, 0xc0, 0x72, 0x2c, 0xd0, 0x2b, 0xa8, 0x65,
+	0xef, 0x24, 0x6b, 0xd9, 0xd4, 0x11, 0xc6, 0xbc, 0x3d, 0xa5, 0x90, 0xfd, 0x24, 0x0b, 0xa5, 0x98,
+	0x94, 0x5f, 0xc5, 0x4c, 0x00, 0x1a, 0xae, 0xfb, 0xa5, 0x72, 0x18, 0xb3, 0xfb, 0x71, 0x25, 0x3b,
+	0xa1, 0x92, 0xf5, 0xe1, 0xfa, 0xce, 0x03, 0x46, 0x5c, 0x5b, 0xef, 0xef, 0xd8, 0xcc, 0x62, 0x47,
+	0x98, 0x74, 0x88, 0x4b, 0x6c, 0x83, 0xa0, 0x35, 0xc8, 0xd9, 0xfa, 0x80, 0x08, 0x3a, 0x0a, 0xf5,
+	0xa2, 0x34, 0x9d, 0x6b, 0xea, 0x03, 0x82, 0xc5, 0x0c, 0xaa, 0x41, 0x81, 0xff, 0x7a, 0x8e, 0x6e,
+	0x90, 0x72, 0x46, 0x88, 0xad, 0x48, 0xb1, 0x42, 0x33, 0x98, 0xc0, 0x91, 0x8c, 0xfa, 0x91, 0x02,
+	0x25, 0x01, 0xbf, 0xe5, 0x79, 0xd4, 0xb0, 0x74, 0x66, 0x51, 0xfb, 0x6a, 0x8e, 0xb0, 0x92, 0x2e,
+	0x11, 0x65, 0xfc, 0xe7, 0x3e, 0xad, 0x85, 0x76, 0xb8, 0x48, 0x51, 0xdd, 0xdc, 0x4a, 0xd9, 0xc7,
+	0x13, 0x88, 0xea, 0xbf, 0x32, 0xb0, 0x10, 0x5b, 0x7c, 0x74, 0x1f, 0xb2, 0x0e, 0x35, 0x65, 0xcc,
+	0x53, 0xb7, 0xe1, 0x2d, 0x6a, 0x46, 0x6e, 0xcc, 0x8d, 0x47, 0xd5, 0x2c, 0x1f, 0xe1, 0x16, 0xd1,
+	0xf7, 0x15, 0x58, 0x22, 0x09, 0x56, 0x05, 0x3b, 0x0b, 0x9b, 0xbb, 0x53, 0xef, 0xe7, 0x93, 0x73,
+	0xa3, 0x8e, 0xc6, 0xa3, 0xea, 0x52, 0x6a, 0x32, 0x05, 0x89, 0x9e, 0x85, 0xac, 0xe5, 0xf8, 0x69,
+	0x5d, 0xac, 0x3f, 0xc5, 0x1d, 0x6c, 0xb4, 0xbc, 0xe3, 0x51, 0xb5, 0xd0, 0x68, 0xc9, 0xbb, 0x01,
+	0xe6, 0x02, 0xe8, 0xeb, 0x90, 0x77, 0xa8, 0xcb, 0xbc, 0x72, 0x4e, 0x30, 0xf2, 0xc5, 0x69, 0x7d,
+	0xe4, 0x99, 0x66, 0xb6, 0xa8, 0xcb, 0xa2, 0x8a, 0xc3, 0x9f, 0x3c, 0xec, 0x9b, 0x55, 0x7f, 0xa1,
+	0xc0, 0x52, 0x92, 0xb5, 0x64, 0xe2, 0x2a, 0x67, 0x27, 0x6e, 0xb8, 0x17, 0x32, 0xa7, 0xee, 0x85,
+	0x3a, 0x64, 0x87, 0x96, 0x59, 0xce, 0x0a, 0x81, 0xe7, 0xa5, 0x40, 0xf6, 0xcd, 0xc6, 0xf6, 0xf1,
+	0xa8, 0xfa, 0xcc, 0x69, 0x77, 0x60, 0x76, 0xe4, 0x10, 0x4f, 0x7b, 0xb3, 0xb1, 0x8d, 0xb9, 0xb2,
+	0xfa, 0x6d, 0x28, 0xbe, 0xde, 0x6e, 0xb7, 0x5a, 0x2e, 0x65, 0xd4, 0xa0, 0x7d, 0x8e, 0xda, 0xa3,
+	0x1e, 0x4b, 0xef, 0xc0, 0xd7, 0xa9, 0xc7, 0xb0, 0x98, 0x41, 0xcf, 0xc2, 0xec, 0x80, 0xb0, 0x1e,
+	0x35, 0xa5, 0x67, 0x4b, 0x52, 0x66, 0xf6, 0x9e, 0x18, 0xc5, 0x72, 0x96, 0x5b, 0x72, 0x74, 0xd6,
+	0x2b, 0x67, 0x93, 0x96, 0x5a, 0x3a, 0xeb, 0x61, 0x31, 0xa3, 0xfe, 0x46, 0x81, 0x39, 0x79, 0xc2,
+	0xa2, 0xfb, 0x90, 0x33, 0x2c, 0xd3, 0x95, 0x99, 0x79, 0xce, 0x33, 0x3d, 0x04, 0x79, 0xad, 0xb1,
+	0x8d, 0xb1, 0x30, 0x88, 0xde, 0x86, 0x59, 0xf2, 0xc0, 0x20, 0x0e, 0x93, 0xbb, 0xef, 0x9c, 0xa6,
+	0xc3, 0x28, 0x77, 0x84, 0x31, 0x2c, 0x8d, 0xaa, 0x1d, 0xc8, 0x0b, 0x01, 0xf4, 0x19, 0xc8, 0x58,
+	0x8e, 0x70, 0xbf, 0x58, 0x5f, 0x1d, 0x8f, 0xaa, 0x99, 0x46, 0x2b, 0x99, 0x78, 0x19, 0xcb, 0x41,
+	0xb7, 0xa1, 0xe8, 0xb8, 0xa4, 0x63, 0x3d, 0xb8, 0x4b, 0xec, 0x2e, 0xeb, 0x89, 0x15, 0xcc, 0x47,
+	0xe7, 0x72, 0x2b, 0x36, 0x87, 0x13, 0x92, 0x6a, 0x0f, 0xe0, 0xee, 0xad, 0x90, 0xa5, 0xb7, 0x20,
+	0xd7, 0x63, 0xcc, 0x39, 0xef, 0x3e, 0x8e, 0x33, 0x5e, 0x9f, 0x17, 0xfc, 0xb6, 0xdb, 0x2d, 0x2c,
+	0x6c, 0xaa, 0xef, 0x2b, 0x50, 0x08, 0xf3, 0x5b, 0xb0, 0x48, 0x5d, 0x3f, 0x1f, 0xf2, 0x31, 0x16,
+	0xa9, 0xcb, 0x70, 0xce, 0x91, 0x12, 0x67, 0xe4, 0xe9, 0x6d, 0x98, 0x77, 0x24, 0x9a, 0xcc, 0x86,
+	0x1b, 0x41, 0x3f, 0x10, 0x78, 0x71, 0x1c, 0xfb, 0x8f, 0x43, 0x69, 0xf5, 0x9f, 0x59, 0x58, 0x6c,
+	0x12, 0xf6, 0x2d, 0xea, 0x1e, 0xb4, 0x68, 0xdf, 0x32, 0x8e, 0xae, 0xa0, 0x72, 0x77, 0x20, 0xef,
+	0x0e, 0xfb, 0x24, 0xa8, 0xd6, 0x5b, 0x53, 0xd7, 0x86, 0xb8, 0xbf, 0x78, 0xd8, 0x27, 0x51, 0x8d,
+	0xe0, 0x4f, 0x1e, 0xf6, 0xcd, 0xa3, 0x57, 0x60, 0x59, 0x4f, 0xdc, 0xae, 0xfc, 0xba, 0x55, 0x10,
+	0xd9, 0xb3, 0x9c, 0xbc, 0x78, 0x79, 0x38, 0x2d, 0x8b, 0xd6, 0xf9, 0xa2, 0x5a, 0xd4, 0xe5, 0x95,
+	0x36, 0xb7, 0xa6, 0xac, 0x2b, 0xf5, 0xa2, 0xbf, 0xa0, 0xfe, 0x18, 0x0e, 0x67, 0xd1, 0x4d, 0x28,
+	0x32, 0x8b, 0xb8, 0xc1, 0x4c, 0x39, 0x2f, 0xa8, 0x2c, 0xf1, 0x84, 0x6b, 0xc7, 0xc6, 0x71, 0x42,
+	0x0a, 0x79, 0x50, 0xf0, 0xe8, 0xd0, 0x35, 0x08, 0x26, 0x9d, 0xf2, 0xac, 0x58, 0xe9, 0x3b, 0x17,
+	0x5b, 0x8a, 0xb0, 0x92, 0x2f, 0xf2, 0x9a, 0xb7, 0x17, 0x18, 0xc7, 0x11, 0x8e, 0xfa, 0x47, 0x05,
+	0x56, 0x12, 0x4a, 0x57, 0xd0, 0x7f, 0xee, 0x27, 0xfb, 0xcf, 0x57, 0x2e, 0x14, 0xe4, 0x29, 0x1d,
+	0xe8, 0x77, 0xe0, 0x7a, 0x42, 0xac, 0x49, 0x4d, 0xb2, 0xc7, 0x74, 0x36, 0xf4, 0xd0, 0xe7, 0x61,
+	0xde, 0xa6, 0x26, 0x69, 0x46, 0x6d, 0x4f, 0xe8, 0x6c, 0x53, 0x8e, 0xe3, 0x50, 0x02, 0x6d, 0x02,
+	0xc8, 0x17, 0x97, 0x16, 0xb5, 0xc5, 0x96, 0xcb, 0x46, 0xe9, 0xbc, 0x1b, 0xce, 0xe0, 0x98, 0x94,
+	0xfa, 0xbb, 0x4c, 0x6a, 0x51, 0x5b, 0x84, 0xb8, 0xe8, 0x16, 0x2c, 0xea, 0xb1, 0xd7, 0x65, 0x5e,
+	0x59, 0x11, 0xc9, 0xb7, 0x32, 0x1e, 0x55, 0x17, 0xe3, 0xef, 0xd1, 0x3c, 0x9c, 0x94, 0x43, 0x04,
+	0xe6, 0x2d, 0x47, 0xde, 0xc0, 0xfc, 0x25, 0xbb, 0x35, 0x7d, 0x49, 0x15, 0xfa, 0x51, 0xa4, 0xe1,
+	0xd5, 0x2b, 0x34, 0x8d, 0xaa, 0x90, 0xef, 0xbc, 0x6b, 0xda, 0xc1, 0xa6, 0x28, 0xf0, 0x35, 0xbd,
+	0xf3, 0xd5, 0xed, 0xa6, 0x87, 0xfd, 0x71, 0xc4, 0x00, 0x18, 0xdd, 0x23, 0xee, 0xa1, 0x65, 0x90,
+	0xe0, 0x20, 0xff, 0xca, 0xb4, 0x9e, 0x48, 0xfd, 0x58, 0x97, 0x11, 0x2c, 0x66, 0x3b, 0xb4, 0x8d,
+	0x63, 0x38, 0xfc, 0x22, 0xf8, 0x89, 0x93, 0xd3, 0x1a, 0xbd, 0x00, 0x39, 0x7e, 0xb8, 0x4a, 0x16,
+	0x9f, 0x09, 0x0a, 0x61, 0xfb, 0xc8, 0x21, 0xc7, 0xa3, 0x6a, 0x92, 0x02, 0x3e, 0x88, 0x85, 0xf8,
+	0xd4, 0x1d, 0x6d, 0x58, 0x70, 0xb3, 0x67, 0x35, 0x06, 0xb9, 0x8b, 0x34, 0x06, 0xbf, 0x9c, 0x4d,
+	0x65, 0x0d, 0x2f, 0x5e, 0xe8, 0x65, 0x28, 0x98, 0x96, 0xcb, 0x2f, 0xc7, 0xd4, 0x96, 0x81, 0x56,
+	0x02, 0x67, 0xb7, 0x83, 0x89, 0xe3, 0xf8, 0x03, 0x8e, 0x14, 0x90, 0x01, 0xb9, 0x8e, 0x4b, 0x07,
+	0xb2, 0x33, 0xbc, 0x58, 0x65, 0xe5, 0x49, 0x1c, 0x05, 0x7f, 0xc7, 0xa5, 0x03, 0x2c, 0x8c, 0xa3,
+	0xb7, 0x21, 0xc3, 0x68, 0x39, 0x7b, 0x59, 0x10, 0x20, 0x21, 0x32, 0x6d, 0x8a, 0x33, 0x8c, 0xf2,
+	0xf4, 0xf7, 0x92, 0x49, 0x77, 0xeb, 0x9c, 0x49, 0x17, 0xa5, 0x7f, 0x98, 0x69, 0xa1, 0x69, 0x5e,
+	0x16, 0x9c, 0x54, 0xc1, 0x8e, 0xce, 0xcc, 0x89, 0x12, 0x7f, 0x1f, 0x66, 0x75, 0x9f, 0x93, 0x59,
+	0xc1, 0xc9, 0x97, 0x79, 0xa7, 0xb2, 0x15, 0x90, 0xb1, 0xf1, 0x98, 0xef, 0x50, 0xae, 0x19, 0x7e,
+	0x15, 0xd2, 0x38, 0xc3, 0xbe, 0x12, 0x96, 0xe6, 0xd0, 0x4b, 0xb0, 0x48, 0x6c, 0x7d, 0xbf, 0x4f,
+	0xee, 0xd2, 0x6e, 0xd7, 0xb2, 0xbb, 0xe5, 0xb9, 0x35, 0x65, 0x7d, 0xbe, 0x7e, 0x4d, 0xfa, 0xb2,
+	0xb8, 0x13, 0x9f, 0xc4, 0x49, 0xd9, 0x93, 0x4e, 0xb8, 0xf9, 0x29, 0x4e, 0xb8, 0x20, 0xcf, 0x0b,
+	0xa7, 0xe6, 0xf9, 0xbb, 0xb0, 0xd0, 0x0f, 0x9b, 0x22, 0xaf, 0x0c, 0x82, 0x8e, 0x2f, 0x4d, 0x4b,
+	0x47, 0xd4, 0x57, 0xd5, 0x57, 0x25, 0xc8, 0x42, 0x34, 0xe6, 0xe1, 0x38, 0x06, 0x7a, 0x0a, 0xf2,
+	0xfa, 0xd0, 0xb4, 0x58, 0x79, 0x81, 0x2f, 0x04, 0xf6, 0x1f, 0xd4, 0x9f, 0x66, 0x01, 0x25, 0x52,
+	0x87, 0x17, 0x77, 0x8f, 0x5f, 0x8a, 0x16, 0xed, 0xf8, 0x70, 0x59, 0xb9, 0xd4, 0x83, 0x34, 0xa4,
+	0x21, 0x39, 0x9f, 0xc4, 0x44, 0x0e, 0x14, 0x99, 0xab, 0x77, 0x3a, 0x96, 0x21, 0xbc, 0x92, 0xbb,
+	0xef, 0xc5, 0xc7, 0xf8, 0x20, 0xbe, 0x16, 0x6a, 0x61, 0x5e, 0xb4, 0x63, 0xda, 0x51, 0xb3, 0x1a,
+	0x1f, 0xc5, 0x09, 0x04, 0xf4, 0x9e, 0x02, 0x25, 0xde, 0xe4, 0xc4, 0x45, 0xca, 0xd9, 0x33, 0xd9,
+	0x49, 0xc1, 0xe2, 0x94, 0x85, 0xe8, 0xe2, 0x9b, 0x9e, 0xc1, 0x13, 0x68, 0xea, 0x5f, 0x15, 0x58,
+	0x9d, 0x60, 0x64, 0x78, 0x15, 0xaf, 0x4b, 0xfb, 0x90, 0xe7, 0xc7, 0x75, 0x70, 0x38, 0xee, 0x5e,
+	0x88, 0xeb, 0xa8, 0x51, 0x88, 0x3a, 0x0b, 0x3e, 0xe6, 0x61, 0x1f, 0x44, 0xfd, 0x6d, 0x0e, 0x4a,
+	0x81, 0x90, 0xb7, 0x37, 0x1c, 0x0c, 0x74, 0xf7, 0x2a, 0x9a, 0xe4, 0x1f, 0x2a, 0xb0, 0x1c, 0xcf,
+	0x32, 0x2b, 0x8c, 0xb7, 0x7e, 0xa1, 0x78, 0x7d, 0xa2, 0xaf, 0x4b, 0xec, 0xe5, 0x66, 0x12, 0x02,
+	0xa7, 0x31, 0xd1, 0xaf, 0x14, 0xb8, 0xe1, 0xa3, 0xc8, 0x77, 0xe3, 0x29, 0x8d, 0x72, 0xf6, 0xd2,
+	0x9c, 0xfa, 0xac, 0x74, 0xea, 0xc6, 0xd6, 0x63, 0xf0, 0xf0, 0x63, 0xbd, 0x41, 0x3f, 0x57, 0xe0,
+	0x9a, 0x2f, 0x90, 0xf6, 0x33, 0x77, 0x69, 0x7e, 0x7e, 0x5a, 0xfa, 0x79, 0x6d, 0xeb, 0x24, 0x20,
+	0x7c, 0x32, 0xbe, 0xaa, 0x43, 0x31, 0xfe, 0x76, 0xe7, 0x49, 0xbc, 0x89, 0xfb, 0xb5, 0x02, 0x73,
+	0xf2, 0xa4, 0x43, 0x37, 0x63, 0x57, 0x42, 0x1f, 0xa2, 0x7c, 0xf6, 0x75, 0x10, 0x35, 0xe5, 0x65,
+	0x34, 0x73, 0x46, 0x4e, 0xf3, 0xef, 0xfc, 0x9a, 0xff, 0x9d, 0x5f, 0x6b, 0xd8, 0xec, 0x0d, 0x77,
+	0x8f, 0xb9, 0x96, 0xdd, 0xad, 0xcf, 0xa7, 0xae, 0xae, 0x9f, 0x83, 0x39, 0x62, 0x8b, 0x7b, 0xae,
+	0xe8, 0x17, 0xf2, 0xf5, 0x85, 0xf1, 0xa8, 0x3a, 0xb7, 0xe3, 0x0f, 0xe1, 0x60, 0x4e, 0x25, 0x50,
+	0x4a, 0xf7, 0x89, 0x4f, 0x60, 0x7d, 0xea, 0xcf, 0x3d, 0x7c, 0x54, 0x99, 0xf9, 0xe0, 0x51, 0x65,
+	0xe6, 0xc3, 0x47, 0x95, 0x99, 0xf7, 0xc6, 0x15, 0xe5, 0xe1, 0xb8, 0xa2, 0x7c, 0x30, 0xae, 0x28,
+	0x1f, 0x8e, 0x2b, 0xca, 0x9f, 0xc7, 0x15, 0xe5, 0x67, 0x7f, 0xa9, 0xcc, 0xbc, 0x35, 0x27, 0xa9,
+	0xff, 0xf7, 0x00, 0x48, 0x6c, 0x70, 0x0f, 0x5e, 0x22, 0x00, 0x00,
 }
 
 func (m *AddressGroup) Marshal() (dAtA []byte, err error) {
@@ -1732,6 +1797,44 @@ func (m *GroupReference) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *HTTPProtocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HTTPProtocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HTTPProtocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i -= len(m.Path)
+	copy(dAtA[i:], m.Path)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Path)))
+	i--
+	dAtA[i] = 0x1a
+	i -= len(m.Method)
+	copy(dAtA[i:], m.Method)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Method)))
+	i--
+	dAtA[i] = 0x12
+	i -= len(m.Host)
+	copy(dAtA[i:], m.Host)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Host)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func (m *IPBlock) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1812,6 +1915,41 @@ func (m *IPNet) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *L7Protocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *L7Protocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *L7Protocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.HTTP != nil {
+		{
+			size, err := m.HTTP.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NamedPort) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -2137,6 +2275,28 @@ func (m *NetworkPolicyRule) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	i--
+	if m.Audit {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x58
+	if len(m.L7Protocols) > 0 {
+		for iNdEx := len(m.L7Protocols) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.L7Protocols[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGenerated(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x52
+		}
+	}
 	i -= len(m.Name)
 	copy(dAtA[i:], m.Name)
 	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Name)))
@@ -2782,6 +2942,21 @@ func (m *GroupReference) Size() (n int) {
 	return n
 }
 
+func (m *HTTPProtocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Host)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Method)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Path)
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
 func (m *IPBlock) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2813,6 +2988,19 @@ func (m *IPNet) Size() (n int) {
 	return n
 }
 
+func (m *L7Protocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.HTTP != nil {
+		l = m.HTTP.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
 func (m *NamedPort) Size() (n int) {
 	if m == nil {
 		return 0
@@ -2971,6 +3159,13 @@ func (m *NetworkPolicyRule) Size() (n int) {
 	}
 	l = len(m.Name)
 	n += 1 + l + sovGenerated(uint64(l))
+	if len(m.L7Protocols) > 0 {
+		for _, e := range m.L7Protocols {
+			l = e.Size()
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
+	n += 2
 	return n
 }
 
@@ -3332,6 +3527,18 @@ func (this *GroupReference) String() string {
 	}, "")
 	return s
 }
+func (this *HTTPProtocol) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HTTPProtocol{`,
+		`Host:` + fmt.Sprintf("%v", this.Host) + `,`,
+		`Method:` + fmt.Sprintf("%v", this.Method) + `,`,
+		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *IPBlock) String() string {
 	if this == nil {
 		return "nil"
@@ -3359,6 +3566,16 @@ func (this *IPNet) String() string {
 	}, "")
 	return s
 }
+func (this *L7Protocol) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&L7Protocol{`,
+		`HTTP:` + strings.Replace(this.HTTP.String(), "HTTPProtocol", "HTTPProtocol", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *NamedPort) String() string {
 	if this == nil {
 		return "nil"
@@ -3463,6 +3680,11 @@ func (this *NetworkPolicyRule) String() string {
 		repeatedStringForServices += strings.Replace(strings.Replace(f.String(), "Service", "Service", 1), `&`, ``, 1) + ","
 	}
 	repeatedStringForServices += "}"
+	repeatedStringForL7Protocols := "[]L7Protocol{"
+	for _, f := range this.L7Protocols {
+		repeatedStringForL7Protocols += strings.Replace(strings.Replace(f.String(), "L7Protocol", "L7Protocol", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForL7Protocols += "}"
 	s := strings.Join([]string{`&NetworkPolicyRule{`,
 		`Direction:` + fmt.Sprintf("%v", this.Direction) + `,`,
 		`From:` + strings.Replace(strings.Replace(this.From.String(), "NetworkPolicyPeer", "NetworkPolicyPeer", 1), `&`, ``, 1) + `,`,
@@ -3473,6 +3695,8 @@ func (this *NetworkPolicyRule) String() string {
 		`EnableLogging:` + fmt.Sprintf("%v", this.EnableLogging) + `,`,
 		`AppliedToGroups:` + fmt.Sprintf("%v", this.AppliedToGroups) + `,`,
 		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`L7Protocols:` + repeatedStringForL7Protocols + `,`,
+		`Audit:` + fmt.Sprintf("%v", this.Audit) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5451,6 +5675,152 @@ func (m *GroupReference) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *HTTPProtocol) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HTTPProtocol: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HTTPProtocol: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Host = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Method", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Method = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *IPBlock) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -5671,6 +6041,92 @@ func (m *IPNet) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *L7Protocol) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: L7Protocol: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: L7Protocol: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTP", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.HTTP == nil {
+				m.HTTP = &HTTPProtocol{}
+			}
+			if err := m.HTTP.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *NamedPort) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -6896,6 +7352,60 @@ func (m *NetworkPolicyRule) Unmarshal(dAtA []byte) error {
 			}
 			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field L7Protocols", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.L7Protocols = append(m.L7Protocols, L7Protocol{})
+			if err := m.L7Protocols[len(m.L7Protocols)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Audit", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Audit = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -233,6 +233,13 @@ type NetworkPolicyRule struct {
 	// Name describes the intention of this rule.
 	// Name should be unique within the policy.
 	Name string `json:"name,omitempty" protobuf:"bytes,9,opt,name=name"`
+	// L7Protocols is a list of L7 protocols which should be matched. If it's set,
+	// only the requests matching one of the protocols are allowed.
+	L7Protocols []L7Protocol `json:"l7Protocols,omitempty" protobuf:"bytes,10,rep,name=l7Protocols"`
+	// Audit indicates that the Drop or Reject action of the rule is not enforced:
+	// the traffic matching the rule is allowed and logged as it would have been
+	// dropped or rejected.
+	Audit bool `json:"audit,omitempty" protobuf:"varint,11,opt,name=audit"`
 }
 
 // Protocol defines network protocols supported for things like container ports.
@@ -262,6 +269,22 @@ type Service struct {
 	EndPort *int32 `json:"endPort,omitempty" protobuf:"bytes,3,opt,name=endPort"`
 }
 
+// L7Protocol defines an L7 protocol matched by a rule. Only one protocol can be set.
+type L7Protocol struct {
+	HTTP *HTTPProtocol `json:"http,omitempty" protobuf:"bytes,1,opt,name=http"`
+}
+
+// HTTPProtocol matches HTTP requests with specific host, method, and path. The
+// fields that are not provided match any value.
+type HTTPProtocol struct {
+	// Host represents the hostname present in the URI or the HTTP Host header to match.
+	Host string `json:"host,omitempty" protobuf:"bytes,1,opt,name=host"`
+	// Method represents the HTTP method to match.
+	Method string `json:"method,omitempty" protobuf:"bytes,2,opt,name=method"`
+	// Path represents the URI path prefix to match.
+	Path string `json:"path,omitempty" protobuf:"bytes,3,opt,name=path"`
+}
+
 // NetworkPolicyPeer describes a peer of NetworkPolicyRules.
 // It could be a list of names of AddressGroups and/or a list of IPBlock.
 type NetworkPolicyPeer struct {
@@ -178,6 +178,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*HTTPProtocol)(nil), (*controlplane.HTTPProtocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(a.(*HTTPProtocol), b.(*controlplane.HTTPProtocol), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.HTTPProtocol)(nil), (*HTTPProtocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(a.(*controlplane.HTTPProtocol), b.(*HTTPProtocol), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*IPBlock)(nil), (*controlplane.IPBlock)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_IPBlock_To_controlplane_IPBlock(a.(*IPBlock), b.(*controlplane.IPBlock), scope)
 	}); err != nil {
@@ -198,6 +208,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*L7Protocol)(nil), (*controlplane.L7Protocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_L7Protocol_To_controlplane_L7Protocol(a.(*L7Protocol), b.(*controlplane.L7Protocol), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*controlplane.L7Protocol)(nil), (*L7Protocol)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_controlplane_L7Protocol_To_v1beta2_L7Protocol(a.(*controlplane.L7Protocol), b.(*L7Protocol), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NamedPort)(nil), (*controlplane.NamedPort)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_NamedPort_To_controlplane_NamedPort(a.(*NamedPort), b.(*controlplane.NamedPort), scope)
 	}); err != nil {
@@ -653,6 +673,30 @@ func Convert_controlplane_GroupReference_To_v1beta2_GroupReference(in *controlpl
 	return autoConvert_controlplane_GroupReference_To_v1beta2_GroupReference(in, out, s)
 }
 
+func autoConvert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(in *HTTPProtocol, out *controlplane.HTTPProtocol, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Method = in.Method
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol is an autogenerated conversion function.
+func Convert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(in *HTTPProtocol, out *controlplane.HTTPProtocol, s conversion.Scope) error {
+	return autoConvert_v1beta2_HTTPProtocol_To_controlplane_HTTPProtocol(in, out, s)
+}
+
+func autoConvert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(in *controlplane.HTTPProtocol, out *HTTPProtocol, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Method = in.Method
+	out.Path = in.Path
+	return nil
+}
+
+// Convert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol is an autogenerated conversion function.
+func Convert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(in *controlplane.HTTPProtocol, out *HTTPProtocol, s conversion.Scope) error {
+	return autoConvert_controlplane_HTTPProtocol_To_v1beta2_HTTPProtocol(in, out, s)
+}
+
 func autoConvert_v1beta2_IPBlock_To_controlplane_IPBlock(in *IPBlock, out *controlplane.IPBlock, s conversion.Scope) error {
 	if err := Convert_v1beta2_IPNet_To_controlplane_IPNet(&in.CIDR, &out.CIDR, s); err != nil {
 		return err
@@ -701,6 +745,26 @@ func Convert_controlplane_IPNet_To_v1beta2_IPNet(in *controlplane.IPNet, out *IP
 	return autoConvert_controlplane_IPNet_To_v1beta2_IPNet(in, out, s)
 }
 
+func autoConvert_v1beta2_L7Protocol_To_controlplane_L7Protocol(in *L7Protocol, out *controlplane.L7Protocol, s conversion.Scope) error {
+	out.HTTP = (*controlplane.HTTPProtocol)(unsafe.Pointer(in.HTTP))
+	return nil
+}
+
+// Convert_v1beta2_L7Protocol_To_controlplane_L7Protocol is an autogenerated conversion function.
+func Convert_v1beta2_L7Protocol_To_controlplane_L7Protocol(in *L7Protocol, out *controlplane.L7Protocol, s conversion.Scope) error {
+	return autoConvert_v1beta2_L7Protocol_To_controlplane_L7Protocol(in, out, s)
+}
+
+func autoConvert_controlplane_L7Protocol_To_v1beta2_L7Protocol(in *controlplane.L7Protocol, out *L7Protocol, s conversion.Scope) error {
+	out.HTTP = (*HTTPProtocol)(unsafe.Pointer(in.HTTP))
+	return nil
+}
+
+// Convert_controlplane_L7Protocol_To_v1beta2_L7Protocol is an autogenerated conversion function.
+func Convert_controlplane_L7Protocol_To_v1beta2_L7Protocol(in *controlplane.L7Protocol, out *L7Protocol, s conversion.Scope) error {
+	return autoConvert_controlplane_L7Protocol_To_v1beta2_L7Protocol(in, out, s)
+}
+
 func autoConvert_v1beta2_NamedPort_To_controlplane_NamedPort(in *NamedPort, out *controlplane.NamedPort, s conversion.Scope) error {
 	out.Port = in.Port
 	out.Name = in.Name
@@ -905,6 +969,8 @@ func autoConvert_v1beta2_NetworkPolicyRule_To_controlplane_NetworkPolicyRule(in
 	out.EnableLogging = in.EnableLogging
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
 	out.Name = in.Name
+	out.L7Protocols = *(*[]controlplane.L7Protocol)(unsafe.Pointer(&in.L7Protocols))
+	out.Audit = in.Audit
 	return nil
 }
 
@@ -922,10 +988,12 @@ func autoConvert_controlplane_NetworkPolicyRule_To_v1beta2_NetworkPolicyRule(in
 		return err
 	}
 	out.Services = *(*[]Service)(unsafe.Pointer(&in.Services))
+	out.L7Protocols = *(*[]L7Protocol)(unsafe.Pointer(&in.L7Protocols))
 	out.Name = in.Name
 	out.Priority = in.Priority
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
+	out.Audit = in.Audit
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
 	return nil
 }
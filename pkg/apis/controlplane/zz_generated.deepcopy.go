@@ -489,6 +489,22 @@ func (in *GroupReference) DeepCopy() *GroupReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProtocol) DeepCopyInto(out *HTTPProtocol) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProtocol.
+func (in *HTTPProtocol) DeepCopy() *HTTPProtocol {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProtocol)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in IPAddress) DeepCopyInto(out *IPAddress) {
 	{
@@ -554,6 +570,27 @@ func (in *IPNet) DeepCopy() *IPNet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *L7Protocol) DeepCopyInto(out *L7Protocol) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPProtocol)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L7Protocol.
+func (in *L7Protocol) DeepCopy() *L7Protocol {
+	if in == nil {
+		return nil
+	}
+	out := new(L7Protocol)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamedPort) DeepCopyInto(out *NamedPort) {
 	*out = *in
@@ -738,6 +775,13 @@ func (in *NetworkPolicyRule) DeepCopyInto(out *NetworkPolicyRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.L7Protocols != nil {
+		in, out := &in.L7Protocols, &out.L7Protocols
+		*out = make([]L7Protocol, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Action != nil {
 		in, out := &in.Action, &out.Action
 		*out = new(v1alpha1.RuleAction)
@@ -38,6 +38,7 @@ const (
 	ComponentRouting       TraceflowComponent = "Routing"
 	ComponentNetworkPolicy TraceflowComponent = "NetworkPolicy"
 	ComponentForwarding    TraceflowComponent = "Forwarding"
+	ComponentEgress        TraceflowComponent = "Egress"
 )
 
 type TraceflowAction string
@@ -51,6 +52,12 @@ const (
 	// ActionForwardedOutOfOverlay indicates that the packet has been forwarded out of the network
 	// managed by Antrea. This indicates that the Traceflow request can be considered complete.
 	ActionForwardedOutOfOverlay TraceflowAction = "ForwardedOutOfOverlay"
+	// ActionMarkedForSNAT indicates that the packet has been marked for SNAT
+	// with the IP of an Egress on the local Node.
+	ActionMarkedForSNAT TraceflowAction = "MarkedForSNAT"
+	// ActionForwardedToEgressNode indicates that the packet has been
+	// tunnelled to the remote Node where the IP of its Egress is assigned.
+	ActionForwardedToEgressNode TraceflowAction = "ForwardedToEgressNode"
 )
 
 // List the supported protocols and their codes in traceflow.
@@ -232,6 +239,8 @@ type TraceflowStatus struct {
 	// DataplaneTag is a tag to identify a traceflow session across Nodes.
 	DataplaneTag uint8 `json:"dataplaneTag,omitempty"`
 	// Results is the collection of all observations on different nodes.
+	// Once the Traceflow completes, Results are ordered by the hops the
+	// packet went through, starting with the sender Node.
 	Results []NodeResult `json:"results,omitempty"`
 	// CapturedPacket is the captured packet in live-traffic Traceflow.
 	CapturedPacket *Packet `json:"capturedPacket,omitempty"`
@@ -270,6 +279,12 @@ type Observation struct {
 	TranslatedDstIP string `json:"translatedDstIP,omitempty" yaml:"translatedDstIP,omitempty"`
 	// TunnelDstIP is the tunnel destination IP.
 	TunnelDstIP string `json:"tunnelDstIP,omitempty" yaml:"tunnelDstIP,omitempty"`
+	// Egress is the name of the Egress applied to the packet.
+	Egress string `json:"egress,omitempty" yaml:"egress,omitempty"`
+	// EgressIP is the IP used by the Egress to SNAT the packet.
+	EgressIP string `json:"egressIP,omitempty" yaml:"egressIP,omitempty"`
+	// EgressNode is the name of the Node where the Egress IP is assigned.
+	EgressNode string `json:"egressNode,omitempty" yaml:"egressNode,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -320,6 +335,11 @@ type NetworkPolicySpec struct {
 	// field within a Rule.
 	// +optional
 	Egress []Rule `json:"egress"`
+	// Audit indicates that the Drop and Reject rules of the policy are not
+	// enforced: the traffic matching them is allowed, and logged as it would
+	// have been dropped or rejected.
+	// +optional
+	Audit bool `json:"audit,omitempty"`
 }
 
 // NetworkPolicyPhase defines the phase in which a NetworkPolicy is.
@@ -357,6 +377,12 @@ type Rule struct {
 	// or empty, this rule matches all ports.
 	// +optional
 	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	// Set of L7 protocols matched by the rule. If this field is set, the
+	// traffic matching the other fields of the rule is inspected by the
+	// antrea-agent, and only the requests matching one of the protocols are
+	// allowed. It can only be set when the action of the rule is Allow.
+	// +optional
+	L7Protocols []L7Protocol `json:"l7Protocols,omitempty"`
 	// Rule is matched if traffic originates from workloads selected by
 	// this field. If this field is empty, this rule matches all sources.
 	// +optional
@@ -380,6 +406,12 @@ type Rule struct {
 	// EnableLogging is used to indicate if agent should generate logs
 	// when rules are matched. Should be default to false.
 	EnableLogging bool `json:"enableLogging"`
+	// Audit indicates that the action of this rule is not enforced: the
+	// traffic matching the rule is allowed, and logged as it would have been
+	// dropped or rejected. It can only be set when the action of the rule is
+	// Drop or Reject.
+	// +optional
+	Audit bool `json:"audit,omitempty"`
 	// Select workloads on which this rule will be applied to. Cannot be set in
 	// conjunction with NetworkPolicySpec/ClusterNetworkPolicySpec.AppliedTo.
 	// +optional
@@ -432,7 +464,7 @@ type NetworkPolicyPeer struct {
 	// NetworkPolicyPeer of egress rules.
 	// Supported formats are:
 	//  Exact FQDNs, i.e. "google.com", "db-svc.default.svc.cluster.local"
-	//  Wildcard expressions, i.e. "*wayfair.com".
+	//  Wildcard expressions, i.e. "*wayfair.com", "api-*.example.com".
 	FQDN string `json:"fqdn,omitempty"`
 }
 
@@ -472,6 +504,32 @@ type NetworkPolicyPort struct {
 	EndPort *int32 `json:"endPort,omitempty"`
 }
 
+// L7Protocol defines the L7 protocol matched by a rule. Only one protocol can
+// be set.
+type L7Protocol struct {
+	HTTP *HTTPProtocol `json:"http,omitempty"`
+}
+
+// HTTPProtocol matches HTTP requests with specific host, method, and path. All
+// fields could be used alone or together. If all fields are not provided, it
+// matches all HTTP requests.
+type HTTPProtocol struct {
+	// Host represents the hostname present in the URI or the HTTP Host header
+	// to match. It does not contain the port associated with the host.
+	// "*" can be used as a wildcard, e.g. "*.example.com".
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Method represents the HTTP method to match, e.g. "GET". It is matched
+	// case-insensitively.
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Path represents the URI path prefix to match, on a segment boundary.
+	// A trailing "*" is ignored, so "/api" and "/api/*" both match "/api/v1",
+	// while neither matches "/apiary".
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
 // ServiceReference represents a reference to a v1.Service.
 type ServiceReference struct {
 	// Name of the Service
@@ -547,6 +605,11 @@ type ClusterNetworkPolicySpec struct {
 	// field within a Rule.
 	// +optional
 	Egress []Rule `json:"egress"`
+	// Audit indicates that the Drop and Reject rules of the policy are not
+	// enforced: the traffic matching them is allowed, and logged as it would
+	// have been dropped or rejected.
+	// +optional
+	Audit bool `json:"audit,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -591,3 +654,76 @@ type TierList struct {
 
 	Items []Tier `json:"items"`
 }
+
+type PacketCapturePhase string
+
+const (
+	PacketCaptureRunning   PacketCapturePhase = "Running"
+	PacketCaptureSucceeded PacketCapturePhase = "Succeeded"
+	PacketCaptureFailed    PacketCapturePhase = "Failed"
+)
+
+const (
+	// DefaultPacketCaptureFirstN is the default number of packets to capture.
+	DefaultPacketCaptureFirstN int32 = 100
+	// DefaultPacketCaptureTimeout is the default timeout in seconds.
+	DefaultPacketCaptureTimeout uint16 = 60
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PacketCapture struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PacketCaptureSpec   `json:"spec,omitempty"`
+	Status PacketCaptureStatus `json:"status,omitempty"`
+}
+
+// PacketCaptureSpec describes the spec of the PacketCapture.
+type PacketCaptureSpec struct {
+	// Source is the source of the captured packets. At least one of Source
+	// and Destination must be a Pod.
+	Source Source `json:"source,omitempty"`
+	// Destination is the destination of the captured packets. Service is
+	// not supported as the destination.
+	Destination Destination `json:"destination,omitempty"`
+	// Packet is the 5-tuple filter. Only the protocol and the transport
+	// ports are used for matching, the IPs come from Source and Destination.
+	Packet Packet `json:"packet,omitempty"`
+	// FirstN is the number of matching packets to capture. Defaults to 100
+	// if not set.
+	FirstN int32 `json:"firstN,omitempty"`
+	// Timeout specifies the timeout of the PacketCapture in seconds.
+	// Defaults to 60 seconds if not set.
+	Timeout uint16 `json:"timeout,omitempty"`
+}
+
+// PacketCaptureStatus describes current status of the PacketCapture.
+type PacketCaptureStatus struct {
+	// Phase is the PacketCapture phase.
+	Phase PacketCapturePhase `json:"phase,omitempty"`
+	// Reason is a message indicating the reason of the PacketCapture's
+	// current phase.
+	Reason string `json:"reason,omitempty"`
+	// StartTime is the time at which the capture was started by the Antrea
+	// Agent.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// NumCapturedPackets is the number of packets captured so far.
+	NumCapturedPackets int32 `json:"numCapturedPackets,omitempty"`
+	// Node is the Node on which the packets are captured.
+	Node string `json:"node,omitempty"`
+	// FilePath is the path of the pcapng file on the Node.
+	FilePath string `json:"filePath,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PacketCaptureList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PacketCapture `json:"items"`
+}
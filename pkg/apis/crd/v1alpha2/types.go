@@ -210,6 +210,40 @@ type Egress struct {
 type EgressStatus struct {
 	// The name of the Node that holds the Egress IP.
 	EgressNode string `json:"egressNode"`
+	// EgressIPAssignments reports the Node that holds each of the EgressIPs,
+	// when multiple Egress IPs are specified.
+	EgressIPAssignments []EgressIPAssignment `json:"egressIPAssignments,omitempty"`
+	// Conditions represent the latest available observations of the Egress.
+	Conditions []EgressCondition `json:"conditions,omitempty"`
+}
+
+type EgressConditionType string
+
+const (
+	// IPAllocated means the Egress IP has been allocated from the ExternalIPPool.
+	IPAllocated EgressConditionType = "IPAllocated"
+	// IPAssigned means the Egress IP has been assigned to a Node.
+	IPAssigned EgressConditionType = "IPAssigned"
+	// Failover means the Egress IP has been moved from a Node to another one.
+	Failover EgressConditionType = "Failover"
+)
+
+type EgressCondition struct {
+	Type               EgressConditionType `json:"type"`
+	Status             v1.ConditionStatus  `json:"status"`
+	LastTransitionTime metav1.Time         `json:"lastTransitionTime,omitempty"`
+	// Unique, one-word, CamelCase reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about last transition.
+	Message string `json:"message,omitempty"`
+}
+
+// EgressIPAssignment represents the assignment of an Egress IP to a Node.
+type EgressIPAssignment struct {
+	// The Egress IP.
+	EgressIP string `json:"egressIP"`
+	// The name of the Node that holds the Egress IP.
+	EgressNode string `json:"egressNode"`
 }
 
 // EgressSpec defines the desired state for Egress.
@@ -221,11 +255,31 @@ type EgressSpec struct {
 	// If ExternalIPPool is non-empty, it can be empty and will be assigned by Antrea automatically.
 	// If both ExternalIPPool and EgressIP are non-empty, the IP must be in the pool.
 	EgressIP string `json:"egressIP,omitempty"`
+	// EgressIPs specifies multiple SNAT IP addresses for the selected workloads.
+	// The selected Pods are distributed across the IPs, each of which can be
+	// assigned to a different Node. It cannot be set together with EgressIP.
+	// If ExternalIPPool is non-empty, the IPs must be in the pool.
+	EgressIPs []string `json:"egressIPs,omitempty"`
+	// EgressIPCount specifies the number of Egress IPs to allocate from ExternalIPPool. The allocated IPs are
+	// written to EgressIPs, and IPs are allocated or released when the count changes. It requires ExternalIPPool.
+	// If it is 0, a single EgressIP is allocated when neither EgressIP nor EgressIPs is specified.
+	EgressIPCount int32 `json:"egressIPCount,omitempty"`
 	// ExternalIPPool specifies the IP Pool that the EgressIP should be allocated from.
 	// If it is empty, the specified EgressIP must be assigned to a Node manually.
 	// If it is non-empty, the EgressIP will be assigned to a Node specified by the pool automatically and will failover
 	// to a different Node when the Node becomes unreachable.
 	ExternalIPPool string `json:"externalIPPool"`
+	// Bandwidth specifies the rate limit of the egress traffic of each Egress IP. The limit is enforced on the Node
+	// the Egress IP is assigned to. If it is empty, the egress traffic is not limited.
+	Bandwidth *Bandwidth `json:"bandwidth,omitempty"`
+}
+
+// Bandwidth specifies the rate limit of traffic.
+type Bandwidth struct {
+	// Rate specifies the maximum traffic rate in bits per second, e.g. 500k, 10M.
+	Rate string `json:"rate"`
+	// Burst specifies the maximum burst size in bits when the traffic exceeds the rate, e.g. 500k, 10M.
+	Burst string `json:"burst"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -23,7 +23,7 @@ const (
 	// AntreaAgentAPIPort is the default port for the antrea-agent APIServer.
 	AntreaAgentAPIPort = 10350
 	// AntreaAgentClusterMembershipPort is the default port for the antrea-agent cluster.
-	// A gossip-based cluster will be created in the background when the egress feature is turned on.
+	// A gossip-based cluster will be created in the background when the Egress or ServiceExternalIP feature is turned on.
 	AntreaAgentClusterMembershipPort = 10351
 	// WireGuardListenPort is the default port for WireGuard encrypted traffic.
 	WireGuardListenPort = 51820
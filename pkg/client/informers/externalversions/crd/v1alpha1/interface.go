@@ -26,6 +26,8 @@ type Interface interface {
 	ClusterNetworkPolicies() ClusterNetworkPolicyInformer
 	// NetworkPolicies returns a NetworkPolicyInformer.
 	NetworkPolicies() NetworkPolicyInformer
+	// PacketCaptures returns a PacketCaptureInformer.
+	PacketCaptures() PacketCaptureInformer
 	// Tiers returns a TierInformer.
 	Tiers() TierInformer
 	// Traceflows returns a TraceflowInformer.
@@ -53,6 +55,11 @@ func (v *version) NetworkPolicies() NetworkPolicyInformer {
 	return &networkPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PacketCaptures returns a PacketCaptureInformer.
+func (v *version) PacketCaptures() PacketCaptureInformer {
+	return &packetCaptureInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Tiers returns a TierInformer.
 func (v *version) Tiers() TierInformer {
 	return &tierInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -123,6 +123,14 @@ type AgentConfig struct {
 	// "udp" L4 transport protocols.
 	// Defaults to "flow-aggregator.flow-aggregator.svc:4739:tcp".
 	FlowCollectorAddr string `yaml:"flowCollectorAddr,omitempty"`
+	// Provide the Service of a Flow Aggregator with multiple replicas, with format
+	// <Namespace>/<Name>. When it is set, the records of each flow are sent to the
+	// replica owning the flow, chosen by a consistent hash of the flow key among the
+	// ready endpoints of the Service, instead of flowCollectorAddr. The protocol of
+	// flowCollectorAddr is still used to connect to the replicas, on the port of
+	// their endpoints for this protocol.
+	// Defaults to "", which disables sharding.
+	FlowCollectorShardingService string `yaml:"flowCollectorShardingService,omitempty"`
 	// Provide flow poll interval in format "0s". This determines how often flow
 	// exporter dumps connections in conntrack module. Flow poll interval should
 	// be greater than or equal to 1s(one second).
@@ -144,6 +152,15 @@ type AgentConfig struct {
 	// Defaults to "15s". Valid time units are "ns", "us" (or "µs"), "ms", "s",
 	// "m", "h".
 	IdleFlowExportTimeout string `yaml:"idleFlowExportTimeout,omitempty"`
+	// Enable the export of the TCP handshake round-trip time, handshake retransmission
+	// count and reset count of the connections. The metrics are computed by the
+	// antrea-agent from the SYN, SYN-ACK and RST packets sent to it by OVS.
+	// Defaults to false.
+	FlowExportTCPMetrics bool `yaml:"flowExportTCPMetrics,omitempty"`
+	// Provide the policy deciding which connections are exported by the flow exporter.
+	// It can be updated without restarting the antrea-agent, by editing the antrea-agent.conf
+	// key of the Antrea ConfigMap.
+	FlowExportPolicy FlowExportPolicyConfig `yaml:"flowExportPolicy,omitempty"`
 	// Deprecated. Use the NodePortLocal config options instead.
 	NPLPortRange string `yaml:"nplPortRange,omitempty"`
 	// NodePortLocal (NPL) configuration options.
@@ -178,6 +195,10 @@ type AgentConfig struct {
 	AntreaProxy AntreaProxyConfig `yaml:"antreaProxy,omitempty"`
 	// Egress related configurations.
 	Egress EgressConfig `yaml:"egress"`
+	// Audit logging of Antrea-native policies related configurations.
+	AuditLogging AuditLoggingConfig `yaml:"auditLogging,omitempty"`
+	// Prometheus metrics of the NetworkPolicy rules related configurations.
+	NetworkPolicyMetrics NetworkPolicyMetricsConfig `yaml:"networkPolicyMetrics,omitempty"`
 }
 
 type AntreaProxyConfig struct {
@@ -215,3 +236,83 @@ type NodePortLocalConfig struct {
 type EgressConfig struct {
 	ExceptCIDRs []string `yaml:"exceptCIDRs,omitempty"`
 }
+
+type FlowExportPolicyConfig struct {
+	// Provide the fraction of connections which are exported, in (0, 1]. Connections are
+	// sampled by a hash of their 5-tuple, so a sampled connection is exported every time
+	// its record is due.
+	// Defaults to 1, which exports all the connections.
+	SamplingRate float64 `yaml:"samplingRate,omitempty"`
+	// Sample the connections denied by NetworkPolicies too. By default, all the denied
+	// connections matching the filters are exported, regardless of samplingRate.
+	SampleDeniedConnections bool `yaml:"sampleDeniedConnections,omitempty"`
+	// Only export the connections matching at least one of these filters. When empty,
+	// all the connections are exported.
+	Include []FlowExportFilter `yaml:"include,omitempty"`
+	// Do not export the connections matching any of these filters.
+	Exclude []FlowExportFilter `yaml:"exclude,omitempty"`
+}
+
+// FlowExportFilter matches a connection when all its non-empty fields match the connection.
+// A field matches when any of its values matches.
+type FlowExportFilter struct {
+	// Namespaces of the source or destination Pod.
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	// Label selector of the source or destination Pod, e.g. "app=web,tier!=db". Only the
+	// labels of the Pods running on the Node are known.
+	PodSelector string `yaml:"podSelector,omitempty"`
+	// CIDRs of the source or destination IP address.
+	CIDRs []string `yaml:"cidrs,omitempty"`
+	// Protocols of the connection: TCP, UDP, SCTP, ICMP or ICMPv6.
+	Protocols []string `yaml:"protocols,omitempty"`
+	// Destination ports of the connection, as single ports ("80") or ranges ("8000-8080").
+	Ports []string `yaml:"ports,omitempty"`
+	// Actions of the ingress or egress NetworkPolicy rule applied to the connection: Allow,
+	// Drop, Reject or Audit.
+	PolicyActions []string `yaml:"policyActions,omitempty"`
+}
+
+type AuditLoggingConfig struct {
+	// Provide the format of the audit logs of Antrea-native policies, written to
+	// np.log and forwarded to the remote collector. It can be "text" or "json".
+	// The JSON logs include the UID and Tier priority of the policy, the rule name
+	// and direction, the local Pods and the Node, and the number of packets.
+	// Defaults to "text".
+	Format string `yaml:"format,omitempty"`
+	// Forwarding of the audit logs to a remote collector, e.g. a syslog server.
+	Forwarding AuditLogForwardingConfig `yaml:"forwarding,omitempty"`
+}
+
+type AuditLogForwardingConfig struct {
+	// Provide the address of the remote collector, as a "host:port" pair. The
+	// audit logs are forwarded to it in addition to being written to np.log.
+	// Defaults to "", which disables the forwarding.
+	Address string `yaml:"address,omitempty"`
+	// Provide the transport protocol used to forward the audit logs: "tcp" or "udp".
+	// Defaults to "tcp".
+	Transport string `yaml:"transport,omitempty"`
+	// Wrap every forwarded audit log in a syslog message (RFC 5424).
+	// Defaults to false.
+	Syslog bool `yaml:"syslog,omitempty"`
+	// Provide the maximum number of audit logs buffered while the remote collector
+	// is unreachable or slow. The oldest logs are dropped when the buffer is full.
+	// Defaults to 10000.
+	BufferSize int `yaml:"bufferSize,omitempty"`
+}
+
+type NetworkPolicyMetricsConfig struct {
+	// Enable the export of the packet, byte and session counters of the NetworkPolicy rules
+	// applied on the Node as Prometheus metrics. It requires the NetworkPolicyStats feature
+	// gate and enablePrometheusMetrics. The counters of the K8s NetworkPolicies are per
+	// policy.
+	// Defaults to false.
+	Enable bool `yaml:"enable,omitempty"`
+	// Provide the maximum number of rules with metrics, to bound their cardinality. The
+	// rules beyond the budget are not exported until other rules are deleted.
+	// Defaults to 500.
+	MaxRules int `yaml:"maxRules,omitempty"`
+	// Only export the metrics of the Antrea-native policies in these Tiers, and of the
+	// policies in these Namespaces. When both are empty, all the policies are exported.
+	Tiers      []string `yaml:"tiers,omitempty"`
+	Namespaces []string `yaml:"namespaces,omitempty"`
+}
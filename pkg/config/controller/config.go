@@ -67,4 +67,23 @@ type ControllerConfig struct {
 	LegacyCRDMirroring *bool `yaml:"legacyCRDMirroring,omitempty"`
 	// NodeIPAM Configuration
 	NodeIPAM NodeIPAMConfig `yaml:"nodeIPAM"`
+	// Prometheus metrics of the NetworkPolicy rules related configurations.
+	NetworkPolicyMetrics NetworkPolicyMetricsConfig `yaml:"networkPolicyMetrics,omitempty"`
+}
+
+type NetworkPolicyMetricsConfig struct {
+	// Enable the export of the packet, byte and session counters of the NetworkPolicy rules,
+	// aggregated from all the Nodes, as Prometheus metrics. It requires the NetworkPolicyStats
+	// feature gate and enablePrometheusMetrics. The counters of the K8s NetworkPolicies are
+	// per policy.
+	// Defaults to false.
+	Enable bool `yaml:"enable,omitempty"`
+	// Provide the maximum number of rules with metrics, to bound their cardinality. The
+	// rules beyond the budget are not exported until other rules are deleted.
+	// Defaults to 500.
+	MaxRules int `yaml:"maxRules,omitempty"`
+	// Only export the metrics of the Antrea-native policies in these Tiers, and of the
+	// policies in these Namespaces. When both are empty, all the policies are exported.
+	Tiers      []string `yaml:"tiers,omitempty"`
+	Namespaces []string `yaml:"namespaces,omitempty"`
 }
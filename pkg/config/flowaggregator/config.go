@@ -20,7 +20,8 @@ import (
 
 type FlowAggregatorConfig struct {
 	// Provide the flow collector address as string with format <IP>:<port>[:<proto>], where proto is tcp or udp.
-	// If no L4 transport proto is given, we consider tcp as default.
+	// If no L4 transport proto is given, we consider tcp as default. Records are only exported to an IPFIX
+	// collector when this is set.
 	// Defaults to "".
 	ExternalFlowCollectorAddr string `yaml:"externalFlowCollectorAddr,omitempty"`
 	// Provide the active flow record timeout as a duration string. This determines
@@ -58,10 +59,35 @@ type FlowAggregatorConfig struct {
 	RecordContents RecordContentsConfig `yaml:"recordContents,omitempty"`
 	// apiServer contains APIServer related configuration options.
 	APIServer APIServerConfig `yaml:"apiServer,omitempty"`
+	// kafka contains the configuration options of the Kafka exporter.
+	Kafka KafkaConfig `yaml:"kafka,omitempty"`
+	// clickHouse contains the configuration options of the ClickHouse exporter.
+	ClickHouse ClickHouseConfig `yaml:"clickHouse,omitempty"`
+	// file contains the configuration options of the local file exporter.
+	File FileConfig `yaml:"file,omitempty"`
+	// sharding contains the configuration options to run multiple replicas of the
+	// flow aggregator.
+	Sharding ShardingConfig `yaml:"sharding,omitempty"`
+	// flowQuery contains the configuration options of the flows API, which aggregates
+	// the flow records exported over a sliding window.
+	FlowQuery FlowQueryConfig `yaml:"flowQuery,omitempty"`
+	// policyRecommendation contains the configuration options of the policy
+	// recommendation API, which recommends NetworkPolicies allowing the observed flows.
+	PolicyRecommendation PolicyRecommendationConfig `yaml:"policyRecommendation,omitempty"`
 }
 
 type RecordContentsConfig struct {
 	PodLabels bool `yaml:"podLabels,omitempty"`
+	// Determine whether the name and IP of the Egress used to SNAT Pod-to-External
+	// flows will be included in the records sent to the IPFIX collector.
+	EgressInfo bool `yaml:"egressInfo,omitempty"`
+	// Determine whether the type of the destination Service will be included in the
+	// records sent to the IPFIX collector.
+	ServiceType bool `yaml:"serviceType,omitempty"`
+	// Determine whether the TCP handshake round-trip time, handshake retransmission
+	// count and reset count exported by the Flow Exporters will be included in the
+	// records sent to the IPFIX collector.
+	TCPMetrics bool `yaml:"tcpMetrics,omitempty"`
 }
 
 type APIServerConfig struct {
@@ -73,3 +99,127 @@ type APIServerConfig struct {
 	// TLS min version.
 	TLSMinVersion string `yaml:"tlsMinVersion,omitempty"`
 }
+
+// ExporterBufferConfig contains the buffering and retry options shared by the exporters
+// which do not send records synchronously.
+type ExporterBufferConfig struct {
+	// Maximum number of records buffered by the exporter. Records are dropped when
+	// the buffer is full.
+	// Defaults to 10000.
+	BufferSize int `yaml:"bufferSize,omitempty"`
+	// Maximum amount of time a record can stay in the buffer before being exported,
+	// as a duration string.
+	FlushInterval string `yaml:"flushInterval,omitempty"`
+	// Number of times the export of a batch of records is retried, with an exponential
+	// backoff, before the records are dropped.
+	// Defaults to 5.
+	MaxRetries int `yaml:"maxRetries,omitempty"`
+}
+
+type KafkaConfig struct {
+	// Enable is the switch to enable exporting flow records to Kafka.
+	Enable bool `yaml:"enable,omitempty"`
+	// Brokers is the list of addresses of the Kafka brokers, in the <host>:<port>
+	// format.
+	Brokers []string `yaml:"brokers,omitempty"`
+	// Topic is the Kafka topic the flow records are published to.
+	// Defaults to "flows".
+	Topic string `yaml:"topic,omitempty"`
+	// Format of the Kafka messages, which is Protobuf or JSON.
+	// Defaults to "Protobuf".
+	Format string `yaml:"format,omitempty"`
+	// Version of the Kafka brokers.
+	// Defaults to "2.0.0".
+	Version string `yaml:"version,omitempty"`
+	// Path of the CA certificate used to verify the Kafka brokers. TLS is enabled
+	// when it is provided.
+	TLSCAFile string `yaml:"tlsCAFile,omitempty"`
+	// Paths of the client certificate and key used for TLS client authentication.
+	TLSCertFile string `yaml:"tlsCertFile,omitempty"`
+	TLSKeyFile  string `yaml:"tlsKeyFile,omitempty"`
+	// Skip the verification of the certificates of the Kafka brokers.
+	TLSInsecureSkipVerify bool `yaml:"tlsInsecureSkipVerify,omitempty"`
+	// Defaults to "1s" for flushInterval.
+	ExporterBufferConfig `yaml:",inline"`
+}
+
+type ClickHouseConfig struct {
+	// Enable is the switch to enable exporting flow records to ClickHouse.
+	Enable bool `yaml:"enable,omitempty"`
+	// Database is the name of the database the flows table is created in.
+	// Defaults to "default".
+	Database string `yaml:"database,omitempty"`
+	// DatabaseURL is the URL of the ClickHouse server.
+	// Defaults to "tcp://clickhouse-clickhouse.flow-visibility.svc:9000".
+	DatabaseURL string `yaml:"databaseURL,omitempty"`
+	// Debug enables the debug logs of the ClickHouse client.
+	Debug bool `yaml:"debug,omitempty"`
+	// Compress enables lz4 compression when sending flow records to ClickHouse.
+	// Defaults to true.
+	Compress *bool `yaml:"compress,omitempty"`
+	// Defaults to "8s" for flushInterval: flow records are inserted in batches.
+	ExporterBufferConfig `yaml:",inline"`
+}
+
+type FileConfig struct {
+	// Enable is the switch to enable writing flow records to a local file.
+	Enable bool `yaml:"enable,omitempty"`
+	// Path is the path of the file flow records are written to.
+	// Defaults to "/var/log/antrea/flow-aggregator/flows.log".
+	Path string `yaml:"path,omitempty"`
+	// Format of the flow records, which is CSV or JSON (one record per line).
+	// Defaults to "CSV".
+	Format string `yaml:"format,omitempty"`
+	// MaxSize is the maximum size in megabytes of the file before it gets rotated.
+	// Defaults to 100.
+	MaxSize int `yaml:"maxSize,omitempty"`
+	// MaxBackups is the maximum number of rotated files to retain.
+	// Defaults to 3.
+	MaxBackups int `yaml:"maxBackups,omitempty"`
+	// MaxAge is the maximum number of days to retain rotated files.
+	// Defaults to 28.
+	MaxAge int `yaml:"maxAge,omitempty"`
+	// Compress determines whether rotated files are compressed using gzip.
+	// Defaults to true.
+	Compress *bool `yaml:"compress,omitempty"`
+	// Defaults to "1s" for flushInterval.
+	ExporterBufferConfig `yaml:",inline"`
+}
+
+type ShardingConfig struct {
+	// Enable is the switch to shard flows among the replicas of the flow aggregator.
+	// Each flow is owned by a single replica, chosen by a consistent hash of the flow
+	// key among the ready endpoints of the Service, and only exported by this replica.
+	// The Flow Exporter must be configured with the same Service in the
+	// flowCollectorShardingService parameter of the antrea-agent config.
+	Enable bool `yaml:"enable,omitempty"`
+	// Service is the Service of the flow aggregator, with format <Namespace>/<Name>.
+	// Defaults to "flow-aggregator/flow-aggregator".
+	Service string `yaml:"service,omitempty"`
+}
+
+type FlowQueryConfig struct {
+	// Window is the duration the exported flow records are kept in memory for, as a
+	// duration string. Setting it to "0s" disables the flows API.
+	// Defaults to "15m".
+	Window string `yaml:"window,omitempty"`
+	// MaxRecords is the maximum number of flow records kept in memory. The oldest
+	// records are dropped first.
+	// Defaults to 100000.
+	MaxRecords int `yaml:"maxRecords,omitempty"`
+}
+
+type PolicyRecommendationConfig struct {
+	// Enable the policy recommendation API. It requires recordContents.podLabels to be
+	// enabled, as the recommended policies select the Pods by their labels.
+	// Defaults to false.
+	Enable bool `yaml:"enable,omitempty"`
+	// Window is the duration a connection is kept in memory for after it was last
+	// observed, as a duration string.
+	// Defaults to "24h".
+	Window string `yaml:"window,omitempty"`
+	// MaxConnections is the maximum number of distinct connections kept in memory. The
+	// new connections are ignored when it is reached.
+	// Defaults to 100000.
+	MaxConnections int `yaml:"maxConnections,omitempty"`
+}
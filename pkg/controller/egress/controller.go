@@ -24,12 +24,14 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
@@ -42,6 +44,7 @@ import (
 	"antrea.io/antrea/pkg/controller/externalippool"
 	"antrea.io/antrea/pkg/controller/grouping"
 	antreatypes "antrea.io/antrea/pkg/controller/types"
+	egressutil "antrea.io/antrea/pkg/util/egress"
 )
 
 const (
@@ -59,9 +62,9 @@ const (
 	externalIPPoolIndex = "externalIPPool"
 )
 
-// ipAllocation contains the IP and the IP Pool which allocates it.
+// ipAllocation contains the IPs and the IP Pool which allocates them.
 type ipAllocation struct {
-	ip     net.IP
+	ips    []net.IP
 	ipPool string
 }
 
@@ -71,8 +74,8 @@ type EgressController struct {
 
 	externalIPAllocator externalippool.ExternalIPAllocator
 
-	// ipAllocationMap is a map from Egress name to ipAllocation, which is used to check whether the Egress's IP has
-	// changed and to release the IP after the Egress is removed.
+	// ipAllocationMap is a map from Egress name to ipAllocation, which is used to check whether the Egress's IPs have
+	// changed and to release the IPs after the Egress is removed.
 	ipAllocationMap   map[string]*ipAllocation
 	ipAllocationMutex sync.RWMutex
 
@@ -161,23 +164,25 @@ func (c *EgressController) restoreIPAllocations(egresses []*egressv1alpha2.Egres
 	var previousIPAllocations []externalippool.IPAllocation
 	for _, egress := range egresses {
 		// Ignore Egress that is not associated to ExternalIPPool or doesn't have EgressIP assigned.
-		if egress.Spec.ExternalIPPool == "" || egress.Spec.EgressIP == "" {
+		if egress.Spec.ExternalIPPool == "" {
 			continue
 		}
-		ip := net.ParseIP(egress.Spec.EgressIP)
-		allocation := externalippool.IPAllocation{
-			ObjectReference: v1.ObjectReference{
-				Name: egress.Name,
-				Kind: egress.Kind,
-			},
-			IPPoolName: egress.Spec.ExternalIPPool,
-			IP:         ip,
+		for _, egressIP := range getEgressIPs(egress) {
+			allocation := externalippool.IPAllocation{
+				ObjectReference: v1.ObjectReference{
+					Name: egress.Name,
+					Kind: egress.Kind,
+				},
+				IPPoolName: egress.Spec.ExternalIPPool,
+				IP:         net.ParseIP(egressIP),
+			}
+			previousIPAllocations = append(previousIPAllocations, allocation)
 		}
-		previousIPAllocations = append(previousIPAllocations, allocation)
 	}
 	succeededAllocations := c.externalIPAllocator.RestoreIPAllocations(previousIPAllocations)
 	for _, alloc := range succeededAllocations {
-		c.setIPAllocation(alloc.ObjectReference.Name, alloc.IP, alloc.IPPoolName)
+		prevIPs, _, _ := c.getIPAllocation(alloc.ObjectReference.Name)
+		c.setIPAllocation(alloc.ObjectReference.Name, append(prevIPs, alloc.IP), alloc.IPPoolName)
 		klog.InfoS("Restored EgressIP", "egress", alloc.ObjectReference.Name, "ip", alloc.IP, "pool", alloc.IPPoolName)
 	}
 }
@@ -207,14 +212,14 @@ func (c *EgressController) processNextEgressGroupWorkItem() bool {
 	return true
 }
 
-func (c *EgressController) getIPAllocation(egressName string) (net.IP, string, bool) {
+func (c *EgressController) getIPAllocation(egressName string) ([]net.IP, string, bool) {
 	c.ipAllocationMutex.RLock()
 	defer c.ipAllocationMutex.RUnlock()
 	allocation, exists := c.ipAllocationMap[egressName]
 	if !exists {
 		return nil, "", false
 	}
-	return allocation.ip, allocation.ipPool, true
+	return allocation.ips, allocation.ipPool, true
 }
 
 func (c *EgressController) deleteIPAllocation(egressName string) {
@@ -223,70 +228,168 @@ func (c *EgressController) deleteIPAllocation(egressName string) {
 	delete(c.ipAllocationMap, egressName)
 }
 
-func (c *EgressController) setIPAllocation(egressName string, ip net.IP, poolName string) {
+func (c *EgressController) setIPAllocation(egressName string, ips []net.IP, poolName string) {
 	c.ipAllocationMutex.Lock()
 	defer c.ipAllocationMutex.Unlock()
 	c.ipAllocationMap[egressName] = &ipAllocation{
-		ip:     ip,
+		ips:    ips,
 		ipPool: poolName,
 	}
 }
 
-// syncEgressIP is responsible for releasing stale EgressIP and allocating new EgressIP for an Egress if applicable.
-func (c *EgressController) syncEgressIP(egress *egressv1alpha2.Egress) (net.IP, error) {
-	prevIP, prevIPPool, exists := c.getIPAllocation(egress.Name)
+// getEgressIPs returns the Egress IPs specified in the Egress, either by EgressIPs or by EgressIP.
+func getEgressIPs(egress *egressv1alpha2.Egress) []string {
+	if len(egress.Spec.EgressIPs) > 0 {
+		return egress.Spec.EgressIPs
+	}
+	if egress.Spec.EgressIP != "" {
+		return []string{egress.Spec.EgressIP}
+	}
+	return nil
+}
+
+// ipsEqual returns whether the allocated IPs are the same as the specified IPs, in the same order.
+func ipsEqual(ips []net.IP, specIPs []string) bool {
+	if len(ips) != len(specIPs) {
+		return false
+	}
+	for i := range ips {
+		if ips[i].String() != specIPs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// syncEgressIP is responsible for releasing stale EgressIPs and allocating new EgressIP for an Egress if applicable.
+// It returns the IPs of the Egress.
+func (c *EgressController) syncEgressIP(egress *egressv1alpha2.Egress) ([]net.IP, error) {
+	egressIPs := getEgressIPs(egress)
+	prevIPs, prevIPPool, exists := c.getIPAllocation(egress.Name)
 	if exists {
-		// The EgressIP and the ExternalIPPool don't change, do nothing.
-		if prevIP.String() == egress.Spec.EgressIP && prevIPPool == egress.Spec.ExternalIPPool && c.externalIPAllocator.IPPoolExists(egress.Spec.ExternalIPPool) {
-			return prevIP, nil
+		// The EgressIPs and the ExternalIPPool don't change, only allocate or release IPs if EgressIPCount changes.
+		if ipsEqual(prevIPs, egressIPs) && prevIPPool == egress.Spec.ExternalIPPool && c.externalIPAllocator.IPPoolExists(egress.Spec.ExternalIPPool) {
+			if egress.Spec.EgressIPCount > 0 && len(prevIPs) != int(egress.Spec.EgressIPCount) {
+				return c.resizeEgressIPs(egress, prevIPs)
+			}
+			return prevIPs, nil
 		}
-		// Either EgressIP or ExternalIPPool changes, release the previous one first.
-		if err := c.releaseEgressIP(egress.Name, prevIP, prevIPPool); err != nil {
+		// Either EgressIPs or ExternalIPPool changes, release the previous ones first.
+		if err := c.releaseEgressIP(egress.Name, prevIPs, prevIPPool); err != nil {
 			return nil, err
 		}
 	}
 
 	// Skip allocating EgressIP if ExternalIPPool is not specified and return whatever user specifies.
 	if egress.Spec.ExternalIPPool == "" {
-		return net.ParseIP(egress.Spec.EgressIP), nil
+		var ips []net.IP
+		for _, egressIP := range egressIPs {
+			ips = append(ips, net.ParseIP(egressIP))
+		}
+		return ips, nil
 	}
 
 	if !c.externalIPAllocator.IPPoolExists(egress.Spec.ExternalIPPool) {
-		// The IP pool has been deleted, reclaim the IP from the Egress API.
+		// The IP pool has been deleted, reclaim the IP from the Egress API. The EgressIPs are kept unless they were
+		// allocated according to EgressIPCount.
 		if egress.Spec.EgressIP != "" {
 			if err := c.updateEgressIP(egress, ""); err != nil {
 				return nil, err
 			}
+		} else if egress.Spec.EgressIPCount > 0 && len(egress.Spec.EgressIPs) > 0 {
+			if err := c.updateEgressIPs(egress, nil); err != nil {
+				return nil, err
+			}
 		}
 		return nil, fmt.Errorf("ExternalIPPool %s not exists", egress.Spec.ExternalIPPool)
 	}
 
-	var ip net.IP
-	// User specifies the Egress IP, try to allocate it. If it fails, the datapath may still work, we just don't track
-	// the IP allocation so deleting this Egress won't release the IP to the Pool.
-	// TODO: Use validation webhook to ensure the requested IP matches the pool.
-	if egress.Spec.EgressIP != "" {
-		ip = net.ParseIP(egress.Spec.EgressIP)
-		if err := c.externalIPAllocator.UpdateIPAllocation(egress.Spec.ExternalIPPool, ip); err != nil {
-			return nil, fmt.Errorf("error when allocating IP %v for Egress %s from ExternalIPPool %s: %v", ip, egress.Name, egress.Spec.ExternalIPPool, err)
+	var ips []net.IP
+	// User specifies the Egress IPs, try to allocate them. If it fails, the datapath may still work, we just don't
+	// track the IP allocations so deleting this Egress won't release the IPs to the Pool.
+	if len(egressIPs) > 0 {
+		for _, egressIP := range egressIPs {
+			ip := net.ParseIP(egressIP)
+			if err := c.externalIPAllocator.UpdateIPAllocation(egress.Spec.ExternalIPPool, ip); err != nil {
+				// Release the IPs allocated for this Egress so far.
+				c.releaseIPs(egress.Spec.ExternalIPPool, ips)
+				return nil, fmt.Errorf("error when allocating IP %v for Egress %s from ExternalIPPool %s: %v", ip, egress.Name, egress.Spec.ExternalIPPool, err)
+			}
+			ips = append(ips, ip)
+		}
+	} else if egress.Spec.EgressIPCount > 0 {
+		// User doesn't specify the Egress IPs but their number, allocate them.
+		for len(ips) < int(egress.Spec.EgressIPCount) {
+			ip, err := c.externalIPAllocator.AllocateIPFromPool(egress.Spec.ExternalIPPool)
+			if err != nil {
+				c.releaseIPs(egress.Spec.ExternalIPPool, ips)
+				return nil, err
+			}
+			ips = append(ips, ip)
+		}
+		if err := c.updateEgressIPs(egress, ips); err != nil {
+			c.releaseIPs(egress.Spec.ExternalIPPool, ips)
+			return nil, err
 		}
 	} else {
 		var err error
 		// User doesn't specify the Egress IP, allocate one.
-		if ip, err = c.externalIPAllocator.AllocateIPFromPool(egress.Spec.ExternalIPPool); err != nil {
+		ip, err := c.externalIPAllocator.AllocateIPFromPool(egress.Spec.ExternalIPPool)
+		if err != nil {
 			return nil, err
 		}
 		if err = c.updateEgressIP(egress, ip.String()); err != nil {
-			if rerr := c.externalIPAllocator.ReleaseIP(egress.Spec.ExternalIPPool, ip); rerr != nil &&
-				rerr != externalippool.ErrExternalIPPoolNotFound {
-				klog.ErrorS(rerr, "Failed to release IP", "ip", ip, "pool", egress.Spec.ExternalIPPool)
-			}
+			c.releaseIPs(egress.Spec.ExternalIPPool, []net.IP{ip})
 			return nil, err
 		}
+		ips = []net.IP{ip}
+	}
+	c.setIPAllocation(egress.Name, ips, egress.Spec.ExternalIPPool)
+	klog.InfoS("Allocated EgressIP", "egress", egress.Name, "ips", ips, "pool", egress.Spec.ExternalIPPool)
+	// The specified EgressIPs don't match EgressIPCount, allocate or release the difference.
+	if egress.Spec.EgressIPCount > 0 && len(ips) != int(egress.Spec.EgressIPCount) {
+		return c.resizeEgressIPs(egress, ips)
+	}
+	return ips, nil
+}
+
+// resizeEgressIPs allocates IPs from the ExternalIPPool or releases IPs to it, so that the Egress has EgressIPCount
+// IPs, and updates the Egress's EgressIPs in Kubernetes API. The first IPs are kept so that they don't need to be
+// assigned to Nodes again. It returns the new IPs of the Egress.
+func (c *EgressController) resizeEgressIPs(egress *egressv1alpha2.Egress, ips []net.IP) ([]net.IP, error) {
+	count := int(egress.Spec.EgressIPCount)
+	poolName := egress.Spec.ExternalIPPool
+	var newIPs, allocatedIPs, releasedIPs []net.IP
+	if count < len(ips) {
+		newIPs, releasedIPs = ips[:count], ips[count:]
+	} else {
+		for len(ips)+len(allocatedIPs) < count {
+			ip, err := c.externalIPAllocator.AllocateIPFromPool(poolName)
+			if err != nil {
+				c.releaseIPs(poolName, allocatedIPs)
+				return nil, err
+			}
+			allocatedIPs = append(allocatedIPs, ip)
+		}
+		newIPs = append(append([]net.IP{}, ips...), allocatedIPs...)
+	}
+	if err := c.updateEgressIPs(egress, newIPs); err != nil {
+		c.releaseIPs(poolName, allocatedIPs)
+		return nil, err
+	}
+	c.releaseIPs(poolName, releasedIPs)
+	c.setIPAllocation(egress.Name, newIPs, poolName)
+	klog.InfoS("Resized EgressIPs", "egress", egress.Name, "ips", newIPs, "pool", poolName)
+	return newIPs, nil
+}
+
+// releaseIPs releases the IPs to the pool, ignoring the errors as the IPs are not tracked by any Egress.
+func (c *EgressController) releaseIPs(poolName string, ips []net.IP) {
+	for _, ip := range ips {
+		if err := c.externalIPAllocator.ReleaseIP(poolName, ip); err != nil && err != externalippool.ErrExternalIPPoolNotFound {
+			klog.ErrorS(err, "Failed to release IP", "ip", ip, "pool", poolName)
+		}
 	}
-	c.setIPAllocation(egress.Name, ip, egress.Spec.ExternalIPPool)
-	klog.InfoS("Allocated EgressIP", "egress", egress.Name, "ip", ip, "pool", egress.Spec.ExternalIPPool)
-	return ip, nil
 }
 
 // updateEgressIP updates the Egress's EgressIP in Kubernetes API.
@@ -307,23 +410,95 @@ func (c *EgressController) updateEgressIP(egress *egressv1alpha2.Egress, ip stri
 	return nil
 }
 
-// releaseEgressIP removes the Egress's ipAllocation in the cache and releases the IP to the pool.
-func (c *EgressController) releaseEgressIP(egressName string, egressIP net.IP, poolName string) error {
-	if err := c.externalIPAllocator.ReleaseIP(poolName, egressIP); err != nil {
-		if err == externalippool.ErrExternalIPPoolNotFound {
-			// Ignore the error since the external IP Pool could be deleted.
-			klog.Warningf("Failed to release IP %s because IP Pool %s does not exist", egressIP, poolName)
+// updateEgressIPs updates the Egress's EgressIPs in Kubernetes API. The EgressIP is removed as it cannot be set together
+// with EgressIPs.
+func (c *EgressController) updateEgressIPs(egress *egressv1alpha2.Egress, ips []net.IP) error {
+	var egressIPs []string
+	for _, ip := range ips {
+		egressIPs = append(egressIPs, ip.String())
+	}
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"egressIP":  nil,
+			"egressIPs": egressIPs,
+		},
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if _, err := c.crdClient.CrdV1alpha2().Egresses().Patch(context.TODO(), egress.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error when updating EgressIPs for Egress %s: %v", egress.Name, err)
+	}
+	return nil
+}
+
+// releaseEgressIP removes the Egress's ipAllocation in the cache and releases the IPs to the pool.
+func (c *EgressController) releaseEgressIP(egressName string, egressIPs []net.IP, poolName string) error {
+	for i, egressIP := range egressIPs {
+		if err := c.externalIPAllocator.ReleaseIP(poolName, egressIP); err != nil {
+			if err == externalippool.ErrExternalIPPoolNotFound {
+				// Ignore the error since the external IP Pool could be deleted.
+				klog.Warningf("Failed to release IP %s because IP Pool %s does not exist", egressIP, poolName)
+			} else {
+				klog.ErrorS(err, "Failed to release IP", "ip", egressIP, "pool", poolName)
+				// Keep tracking the IPs that haven't been released.
+				c.setIPAllocation(egressName, egressIPs[i:], poolName)
+				return err
+			}
 		} else {
-			klog.ErrorS(err, "Failed to release IP", "ip", egressIP, "pool", poolName)
-			return err
+			klog.InfoS("Released EgressIP", "egress", egressName, "ip", egressIP, "pool", poolName)
 		}
-	} else {
-		klog.InfoS("Released EgressIP", "egress", egressName, "ip", egressIP, "pool", poolName)
 	}
 	c.deleteIPAllocation(egressName)
 	return nil
 }
 
+// updateIPAllocatedCondition updates the IPAllocated condition of an Egress according to the result of the IP
+// allocation. The condition is removed if the Egress doesn't allocate IPs from an ExternalIPPool.
+func (c *EgressController) updateIPAllocatedCondition(egress *egressv1alpha2.Egress, allocErr error) error {
+	setCondition := func(status *egressv1alpha2.EgressStatus) bool {
+		if egress.Spec.ExternalIPPool == "" {
+			return egressutil.RemoveEgressCondition(status, egressv1alpha2.IPAllocated)
+		}
+		condition := egressv1alpha2.EgressCondition{
+			Type:    egressv1alpha2.IPAllocated,
+			Status:  v1.ConditionTrue,
+			Reason:  "Allocated",
+			Message: fmt.Sprintf("EgressIP is allocated from ExternalIPPool %s", egress.Spec.ExternalIPPool),
+		}
+		if allocErr != nil {
+			condition.Status = v1.ConditionFalse
+			condition.Reason = "AllocationError"
+			condition.Message = allocErr.Error()
+		}
+		return egressutil.SetEgressCondition(status, condition)
+	}
+	// Do nothing if the condition is already up to date.
+	if !setCondition(egress.Status.DeepCopy()) {
+		return nil
+	}
+	// The spec of the Egress may have been updated when allocating the IP, get the latest one to update the status.
+	toUpdate, getErr := c.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{})
+	if getErr != nil {
+		return getErr
+	}
+	var updateErr error
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !setCondition(&toUpdate.Status) {
+			return nil
+		}
+		_, updateErr = c.crdClient.CrdV1alpha2().Egresses().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		if updateErr != nil && errors.IsConflict(updateErr) {
+			if toUpdate, getErr = c.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{}); getErr != nil {
+				return getErr
+			}
+		}
+		// Return the error from UPDATE.
+		return updateErr
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *EgressController) syncEgress(key string) error {
 	startTime := time.Now()
 	defer func() {
@@ -334,14 +509,21 @@ func (c *EgressController) syncEgress(key string) error {
 	egress, err := c.egressLister.Get(key)
 	if err != nil {
 		// The Egress has been deleted, release its EgressIP if there was one.
-		if prevIP, prevIPPool, exists := c.getIPAllocation(key); exists {
-			c.releaseEgressIP(key, prevIP, prevIPPool)
+		if prevIPs, prevIPPool, exists := c.getIPAllocation(key); exists {
+			c.releaseEgressIP(key, prevIPs, prevIPPool)
 		}
 		return nil
 	}
 
-	if _, err := c.syncEgressIP(egress); err != nil {
-		return err
+	_, allocErr := c.syncEgressIP(egress)
+	if err := c.updateIPAllocatedCondition(egress, allocErr); err != nil {
+		klog.ErrorS(err, "Failed to update IPAllocated condition of Egress", "egress", key)
+		if allocErr == nil {
+			return err
+		}
+	}
+	if allocErr != nil {
+		return allocErr
 	}
 
 	egressGroupObj, found, _ := c.egressGroupStore.Get(key)
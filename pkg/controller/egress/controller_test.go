@@ -43,6 +43,7 @@ import (
 	"antrea.io/antrea/pkg/controller/egress/store"
 	"antrea.io/antrea/pkg/controller/externalippool"
 	"antrea.io/antrea/pkg/controller/grouping"
+	egressutil "antrea.io/antrea/pkg/util/egress"
 )
 
 var (
@@ -408,6 +409,18 @@ func TestUpdateEgress(t *testing.T) {
 		return egress.Spec.EgressIP
 	}
 
+	gotIPAllocatedStatus := func() v1.ConditionStatus {
+		egress, err := controller.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		condition := egressutil.GetEgressCondition(&egress.Status, v1alpha2.IPAllocated)
+		if condition == nil {
+			return ""
+		}
+		return condition.Status
+	}
+
 	assert.Equal(t, &watch.Event{
 		Type: watch.Added,
 		Object: &controlplane.EgressGroup{
@@ -418,6 +431,7 @@ func TestUpdateEgress(t *testing.T) {
 		},
 	}, getEvent())
 	assert.Equal(t, "1.1.1.1", gotEgressIP())
+	assert.Equal(t, v1.ConditionTrue, gotIPAllocatedStatus())
 	checkExternalIPPoolUsed(t, controller, eipFoo1.Name, 1)
 
 	// Add a Pod matching the Egress's selector and running on this Node.
@@ -472,6 +486,10 @@ func TestUpdateEgress(t *testing.T) {
 	})
 	assert.NoError(t, err, "IP allocation was not deleted after the ExternalIPPool was deleted")
 	assert.Equal(t, "", gotEgressIP(), "EgressIP was not deleted after the ExternalIPPool was deleted")
+	err = wait.PollImmediate(50*time.Millisecond, 1*time.Second, func() (found bool, err error) {
+		return gotIPAllocatedStatus() == v1.ConditionFalse, nil
+	})
+	assert.NoError(t, err, "IPAllocated condition was not updated after the ExternalIPPool was deleted")
 
 	// Recreate the ExternalIPPool. An EgressIP should be allocated.
 	controller.crdClient.CrdV1alpha2().ExternalIPPools().Create(context.TODO(), eipFoo2, metav1.CreateOptions{})
@@ -480,6 +498,10 @@ func TestUpdateEgress(t *testing.T) {
 		return exists, nil
 	})
 	assert.NoError(t, err, "IP was not allocated after the ExternalIPPool was created")
+	err = wait.PollImmediate(50*time.Millisecond, 1*time.Second, func() (found bool, err error) {
+		return gotIPAllocatedStatus() == v1.ConditionTrue, nil
+	})
+	assert.NoError(t, err, "IPAllocated condition was not updated after the ExternalIPPool was created")
 	checkExternalIPPoolUsed(t, controller, eipFoo2.Name, 1)
 
 	// Delete the Egress. The EgressIP should be released.
@@ -499,6 +521,7 @@ func TestSyncEgressIP(t *testing.T) {
 		existingExternalIPPool     *v1alpha2.ExternalIPPool
 		inputEgress                *v1alpha2.Egress
 		expectedEgressIP           string
+		expectedEgressIPs          []string
 		expectedExternalIPPoolUsed int
 		expectErr                  bool
 	}{
@@ -680,6 +703,141 @@ func TestSyncEgressIP(t *testing.T) {
 			expectedExternalIPPoolUsed: 0,
 			expectErr:                  false,
 		},
+		{
+			name:                   "Egress with multiple EgressIPs and proper ExternalIPPool",
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/24", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIPs:      []string{"1.1.1.2", "1.1.1.3"},
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedEgressIPs:          []string{"1.1.1.2", "1.1.1.3"},
+			expectedExternalIPPoolUsed: 2,
+			expectErr:                  false,
+		},
+		{
+			name: "Egress with updated EgressIPs",
+			existingEgresses: []*v1alpha2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec: v1alpha2.EgressSpec{
+						EgressIPs:      []string{"1.1.1.2", "1.1.1.3"},
+						ExternalIPPool: "ipPoolA",
+					},
+				},
+			},
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/24", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIPs:      []string{"1.1.1.2", "1.1.1.4", "1.1.1.5"},
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedEgressIPs:          []string{"1.1.1.2", "1.1.1.4", "1.1.1.5"},
+			expectedExternalIPPoolUsed: 3,
+			expectErr:                  false,
+		},
+		{
+			name: "Egress with partially conflicting EgressIPs",
+			existingEgresses: []*v1alpha2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec: v1alpha2.EgressSpec{
+						EgressIP:       "1.1.1.3",
+						ExternalIPPool: "ipPoolA",
+					},
+				},
+			},
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/24", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressB", UID: "uidB"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIPs:      []string{"1.1.1.2", "1.1.1.3"},
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedExternalIPPoolUsed: 1,
+			expectErr:                  true,
+		},
+		{
+			name:                   "Egress with EgressIPCount and proper ExternalIPPool",
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/24", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIPCount:  2,
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedEgressIPs:          []string{"1.1.1.1", "1.1.1.2"},
+			expectedExternalIPPoolUsed: 2,
+			expectErr:                  false,
+		},
+		{
+			name:                   "Egress with EgressIPCount exceeding ExternalIPPool",
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/30", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIPCount:  5,
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedExternalIPPoolUsed: 0,
+			expectErr:                  true,
+		},
+		{
+			name: "Egress with increased EgressIPCount",
+			existingEgresses: []*v1alpha2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec: v1alpha2.EgressSpec{
+						EgressIP:       "1.1.1.2",
+						ExternalIPPool: "ipPoolA",
+					},
+				},
+			},
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/24", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIP:       "1.1.1.2",
+					EgressIPCount:  3,
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedEgressIPs:          []string{"1.1.1.2", "1.1.1.1", "1.1.1.3"},
+			expectedExternalIPPoolUsed: 3,
+			expectErr:                  false,
+		},
+		{
+			name: "Egress with decreased EgressIPCount",
+			existingEgresses: []*v1alpha2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec: v1alpha2.EgressSpec{
+						EgressIPs:      []string{"1.1.1.2", "1.1.1.3", "1.1.1.4"},
+						EgressIPCount:  3,
+						ExternalIPPool: "ipPoolA",
+					},
+				},
+			},
+			existingExternalIPPool: newExternalIPPool("ipPoolA", "1.1.1.0/24", "", ""),
+			inputEgress: &v1alpha2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec: v1alpha2.EgressSpec{
+					EgressIPs:      []string{"1.1.1.2", "1.1.1.3", "1.1.1.4"},
+					EgressIPCount:  1,
+					ExternalIPPool: "ipPoolA",
+				},
+			},
+			expectedEgressIPs:          []string{"1.1.1.2"},
+			expectedExternalIPPoolUsed: 1,
+			expectErr:                  false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -695,13 +853,27 @@ func TestSyncEgressIP(t *testing.T) {
 			go controller.externalIPAllocator.Run(stopCh)
 			require.True(t, cache.WaitForCacheSync(stopCh, controller.externalIPAllocator.HasSynced))
 			controller.restoreIPAllocations(tt.existingEgresses)
-			gotEgressIP, err := controller.syncEgressIP(tt.inputEgress)
+			gotEgressIPs, err := controller.syncEgressIP(tt.inputEgress)
 			if tt.expectErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 			}
-			assert.Equal(t, net.ParseIP(tt.expectedEgressIP), gotEgressIP)
+			var expectedEgressIPs []net.IP
+			if tt.expectedEgressIP != "" {
+				expectedEgressIPs = append(expectedEgressIPs, net.ParseIP(tt.expectedEgressIP))
+			}
+			for _, ip := range tt.expectedEgressIPs {
+				expectedEgressIPs = append(expectedEgressIPs, net.ParseIP(ip))
+			}
+			assert.Equal(t, expectedEgressIPs, gotEgressIPs)
+			if tt.inputEgress.Spec.EgressIPCount > 0 && !tt.expectErr {
+				// The allocated IPs should be written to EgressIPs.
+				egress, err := controller.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), tt.inputEgress.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Empty(t, egress.Spec.EgressIP)
+				assert.Equal(t, tt.expectedEgressIPs, egress.Spec.EgressIPs)
+			}
 			checkExternalIPPoolUsed(t, controller, tt.existingExternalIPPool.Name, tt.expectedExternalIPPoolUsed)
 		})
 	}
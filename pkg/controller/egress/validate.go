@@ -17,10 +17,13 @@ package egress
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net"
 
 	admv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	crdv1alpha2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
@@ -47,23 +50,48 @@ func (c *EgressController) ValidateEgress(review *admv1.AdmissionReview) *admv1.
 	}
 
 	shouldAllow := func(oldEgress, newEgress *crdv1alpha2.Egress) (bool, string) {
-		// Allow it if EgressIP and ExternalIPPool don't change.
-		if newEgress.Spec.EgressIP == oldEgress.Spec.EgressIP && newEgress.Spec.ExternalIPPool == oldEgress.Spec.ExternalIPPool {
-			return true, ""
+		if newEgress.Spec.EgressIP != "" && len(newEgress.Spec.EgressIPs) > 0 {
+			return false, "egressIP and egressIPs cannot be set at the same time"
+		}
+		if newEgress.Spec.EgressIPCount < 0 {
+			return false, "egressIPCount must not be negative"
+		}
+		if newEgress.Spec.EgressIPCount > 0 && newEgress.Spec.ExternalIPPool == "" {
+			return false, "egressIPCount requires externalIPPool"
 		}
-		// Only validate whether the specified Egress IP is in the Pool when they are both set.
-		if newEgress.Spec.EgressIP == "" || newEgress.Spec.ExternalIPPool == "" {
+		if newEgress.Spec.Bandwidth != nil {
+			if err := validateBandwidth(newEgress.Spec.Bandwidth); err != nil {
+				return false, err.Error()
+			}
+		}
+		// Allow it if EgressIP, EgressIPs and ExternalIPPool don't change.
+		if newEgress.Spec.EgressIP == oldEgress.Spec.EgressIP && sets.NewString(newEgress.Spec.EgressIPs...).Equal(sets.NewString(oldEgress.Spec.EgressIPs...)) &&
+			newEgress.Spec.ExternalIPPool == oldEgress.Spec.ExternalIPPool {
 			return true, ""
 		}
-		ip := net.ParseIP(newEgress.Spec.EgressIP)
-		if ip == nil {
-			return false, fmt.Sprintf("IP %s is not valid", newEgress.Spec.EgressIP)
+		egressIPs := getEgressIPs(newEgress)
+		var ips []net.IP
+		for _, egressIP := range egressIPs {
+			ip := net.ParseIP(egressIP)
+			if ip == nil {
+				return false, fmt.Sprintf("IP %s is not valid", egressIP)
+			}
+			ips = append(ips, ip)
+		}
+		if sets.NewString(egressIPs...).Len() != len(egressIPs) {
+			return false, "egressIPs must not contain duplicate IPs"
+		}
+		// Only validate whether the specified Egress IPs are in the Pool when they are both set.
+		if len(ips) == 0 || newEgress.Spec.ExternalIPPool == "" {
+			return true, ""
 		}
 		if !c.externalIPAllocator.IPPoolExists(newEgress.Spec.ExternalIPPool) {
 			return false, fmt.Sprintf("ExternalIPPool %s does not exist", newEgress.Spec.ExternalIPPool)
 		}
-		if !c.externalIPAllocator.IPPoolHasIP(newEgress.Spec.ExternalIPPool, ip) {
-			return false, fmt.Sprintf("IP %s is not within the IP range", newEgress.Spec.EgressIP)
+		for _, ip := range ips {
+			if !c.externalIPAllocator.IPPoolHasIP(newEgress.Spec.ExternalIPPool, ip) {
+				return false, fmt.Sprintf("IP %s is not within the IP range", ip)
+			}
 		}
 		return true, ""
 	}
@@ -92,6 +120,22 @@ func (c *EgressController) ValidateEgress(review *admv1.AdmissionReview) *admv1.
 	}
 }
 
+// validateBandwidth checks that the rate and the burst of the Bandwidth are valid quantities which can be enforced
+// with OpenFlow meters, i.e. they are between 1k and the maximum number of kbits that can be represented with uint32.
+func validateBandwidth(bandwidth *crdv1alpha2.Bandwidth) error {
+	for _, f := range []struct{ field, value string }{{"rate", bandwidth.Rate}, {"burst", bandwidth.Burst}} {
+		field, value := f.field, f.value
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return fmt.Errorf("bandwidth %s %q is not valid: %v", field, value, err)
+		}
+		if quantity.Value() < 1000 || quantity.Value()/1000 > math.MaxUint32 {
+			return fmt.Errorf("bandwidth %s %q must be between 1k and %dk", field, value, uint32(math.MaxUint32))
+		}
+	}
+	return nil
+}
+
 func newAdmissionResponseForErr(err error) *admv1.AdmissionResponse {
 	return &admv1.AdmissionResponse{
 		Result: &metav1.Status{
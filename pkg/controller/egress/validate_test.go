@@ -33,6 +33,24 @@ func marshal(object runtime.Object) []byte {
 	return raw
 }
 
+func newEgressWithIPs(name, egressIP string, egressIPs []string, externalIPPool string) *crdv1alpha2.Egress {
+	egress := newEgress(name, egressIP, externalIPPool, nil, nil)
+	egress.Spec.EgressIPs = egressIPs
+	return egress
+}
+
+func newEgressWithIPCount(name, externalIPPool string, count int32) *crdv1alpha2.Egress {
+	egress := newEgress(name, "", externalIPPool, nil, nil)
+	egress.Spec.EgressIPCount = count
+	return egress
+}
+
+func newEgressWithBandwidth(name, egressIP, externalIPPool, rate, burst string) *crdv1alpha2.Egress {
+	egress := newEgress(name, egressIP, externalIPPool, nil, nil)
+	egress.Spec.Bandwidth = &crdv1alpha2.Bandwidth{Rate: rate, Burst: burst}
+	return egress
+}
+
 func TestEgressControllerValidateEgress(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -120,6 +138,127 @@ func TestEgressControllerValidateEgress(t *testing.T) {
 			},
 			expectedResponse: &admv1.AdmissionResponse{Allowed: true},
 		},
+		{
+			name:                   "Requesting both EgressIP and EgressIPs should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithIPs("foo", "10.10.10.1", []string{"10.10.10.2"}, "bar"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "egressIP and egressIPs cannot be set at the same time",
+				},
+			},
+		},
+		{
+			name:                   "Requesting EgressIPCount without ExternalIPPool should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithIPCount("foo", "", 2))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "egressIPCount requires externalIPPool",
+				},
+			},
+		},
+		{
+			name:                   "Requesting EgressIPCount with ExternalIPPool should be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithIPCount("foo", "bar", 2))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{Allowed: true},
+		},
+		{
+			name:                   "Requesting EgressIPs out of range should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithIPs("foo", "", []string{"10.10.10.1", "10.10.11.1"}, "bar"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "IP 10.10.11.1 is not within the IP range",
+				},
+			},
+		},
+		{
+			name:                   "Requesting duplicate EgressIPs should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithIPs("foo", "", []string{"10.10.10.1", "10.10.10.1"}, "bar"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "egressIPs must not contain duplicate IPs",
+				},
+			},
+		},
+		{
+			name:                   "Requesting normal EgressIPs should be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithIPs("foo", "", []string{"10.10.10.1", "10.10.10.2"}, "bar"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{Allowed: true},
+		},
+		{
+			name:                   "Requesting invalid bandwidth should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithBandwidth("foo", "10.10.10.1", "bar", "10M", "500"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "bandwidth burst \"500\" must be between 1k and 4294967295k",
+				},
+			},
+		},
+		{
+			name:                   "Requesting valid bandwidth should be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithBandwidth("foo", "10.10.10.1", "bar", "10M", "1M"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{Allowed: true},
+		},
+		{
+			name:                   "Updating to invalid bandwidth should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "UPDATE",
+				OldObject: runtime.RawExtension{Raw: marshal(newEgress("foo", "10.10.10.1", "bar", nil, nil))},
+				Object:    runtime.RawExtension{Raw: marshal(newEgressWithBandwidth("foo", "10.10.10.1", "bar", "fast", "1M"))},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "bandwidth rate \"fast\" is not valid: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'",
+				},
+			},
+		},
 		{
 			name: "DELETE operation should be allowed",
 			request: &admv1.AdmissionRequest{
@@ -18,6 +18,8 @@ import (
 	"k8s.io/component-base/metrics"
 	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/util/rulemetrics"
 )
 
 const (
@@ -117,6 +119,34 @@ var (
 		Help:           "The total number of actual status updates performed for Antrea ClusterNetworkPolicy Custom Resources",
 		StabilityLevel: metrics.ALPHA,
 	})
+	NetworkPolicyRulePacketCount = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "networkpolicy_rule_packet_count",
+		Help:           "The total number of packets matched by the NetworkPolicy rules on all Nodes, partitioned by policy and rule. Only the rules selected by the networkPolicyMetrics config option are included",
+		StabilityLevel: metrics.ALPHA,
+	}, rulemetrics.Labels)
+	NetworkPolicyRuleByteCount = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "networkpolicy_rule_byte_count",
+		Help:           "The total number of bytes matched by the NetworkPolicy rules on all Nodes, partitioned by policy and rule. Only the rules selected by the networkPolicyMetrics config option are included",
+		StabilityLevel: metrics.ALPHA,
+	}, rulemetrics.Labels)
+	NetworkPolicyRuleSessionCount = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "networkpolicy_rule_session_count",
+		Help:           "The total number of sessions matched by the NetworkPolicy rules on all Nodes, partitioned by policy and rule. Only the rules selected by the networkPolicyMetrics config option are included",
+		StabilityLevel: metrics.ALPHA,
+	}, rulemetrics.Labels)
+	NetworkPolicyRuleMetricsRejectedCount = metrics.NewCounter(&metrics.CounterOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "networkpolicy_rule_metrics_rejected_count",
+		Help:           "The total number of times the statistics of a NetworkPolicy rule were not exported because the maxRules budget of the networkPolicyMetrics config option was exhausted",
+		StabilityLevel: metrics.ALPHA,
+	})
 )
 
 // Initialize Prometheus metrics collection.
@@ -156,4 +186,16 @@ func InitializePrometheusMetrics() {
 	if err := legacyregistry.Register(AntreaClusterNetworkPolicyStatusUpdates); err != nil {
 		klog.Errorf("Failed to register antrea_controller_acnp_status_updates with Prometheus: %s", err.Error())
 	}
+	if err := legacyregistry.Register(NetworkPolicyRulePacketCount); err != nil {
+		klog.Errorf("Failed to register antrea_controller_networkpolicy_rule_packet_count with Prometheus: %s", err.Error())
+	}
+	if err := legacyregistry.Register(NetworkPolicyRuleByteCount); err != nil {
+		klog.Errorf("Failed to register antrea_controller_networkpolicy_rule_byte_count with Prometheus: %s", err.Error())
+	}
+	if err := legacyregistry.Register(NetworkPolicyRuleSessionCount); err != nil {
+		klog.Errorf("Failed to register antrea_controller_networkpolicy_rule_session_count with Prometheus: %s", err.Error())
+	}
+	if err := legacyregistry.Register(NetworkPolicyRuleMetricsRejectedCount); err != nil {
+		klog.Errorf("Failed to register antrea_controller_networkpolicy_rule_metrics_rejected_count with Prometheus: %s", err.Error())
+	}
 }
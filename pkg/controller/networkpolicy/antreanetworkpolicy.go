@@ -150,10 +150,12 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *crdv1alpha1.Net
 			Direction:       controlplane.DirectionIn,
 			From:            *n.toAntreaPeerForCRD(ingressRule.From, np, controlplane.DirectionIn, namedPortExists),
 			Services:        services,
+			L7Protocols:     toAntreaL7ProtocolsForCRD(ingressRule.L7Protocols),
 			Name:            ingressRule.Name,
 			Action:          ingressRule.Action,
 			Priority:        int32(idx),
 			EnableLogging:   ingressRule.EnableLogging,
+			Audit:           isAuditRule(&ingressRule, np.Spec.Audit),
 			AppliedToGroups: appliedToGroupNamesForRule,
 		})
 	}
@@ -178,10 +180,12 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *crdv1alpha1.Net
 			Direction:       controlplane.DirectionOut,
 			To:              *peers,
 			Services:        services,
+			L7Protocols:     toAntreaL7ProtocolsForCRD(egressRule.L7Protocols),
 			Name:            egressRule.Name,
 			Action:          egressRule.Action,
 			Priority:        int32(idx),
 			EnableLogging:   egressRule.EnableLogging,
+			Audit:           isAuditRule(&egressRule, np.Spec.Audit),
 			AppliedToGroups: appliedToGroupNamesForRule,
 		})
 	}
@@ -301,10 +301,12 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *crdv1alpha1.C
 				rule := controlplane.NetworkPolicyRule{
 					Direction:       dir,
 					Services:        services,
+					L7Protocols:     toAntreaL7ProtocolsForCRD(cnpRule.L7Protocols),
 					Name:            cnpRule.Name,
 					Action:          cnpRule.Action,
 					Priority:        int32(idx),
 					EnableLogging:   cnpRule.EnableLogging,
+					Audit:           isAuditRule(&cnpRule, cnp.Spec.Audit),
 					AppliedToGroups: ruleAppliedTos,
 				}
 				if dir == controlplane.DirectionIn {
@@ -54,6 +54,34 @@ func toAntreaServicesForCRD(npPorts []v1alpha1.NetworkPolicyPort) ([]controlplan
 	return antreaServices, namedPortExists
 }
 
+// toAntreaL7ProtocolsForCRD converts a slice of v1alpha1.L7Protocol objects to
+// a slice of Antrea L7Protocol objects.
+func toAntreaL7ProtocolsForCRD(l7Protocols []v1alpha1.L7Protocol) []controlplane.L7Protocol {
+	var antreaL7Protocols []controlplane.L7Protocol
+	for _, l7Protocol := range l7Protocols {
+		var antreaL7Protocol controlplane.L7Protocol
+		if l7Protocol.HTTP != nil {
+			antreaL7Protocol.HTTP = &controlplane.HTTPProtocol{
+				Host:   l7Protocol.HTTP.Host,
+				Method: strings.ToUpper(l7Protocol.HTTP.Method),
+				Path:   l7Protocol.HTTP.Path,
+			}
+		}
+		antreaL7Protocols = append(antreaL7Protocols, antreaL7Protocol)
+	}
+	return antreaL7Protocols
+}
+
+// isAuditRule returns whether the action of an Antrea-native policy rule is
+// audited instead of enforced, because the rule or its policy is in audit
+// mode. Only the Drop and Reject actions can be audited.
+func isAuditRule(rule *v1alpha1.Rule, policyAudit bool) bool {
+	if rule.Action == nil || (*rule.Action != v1alpha1.RuleActionDrop && *rule.Action != v1alpha1.RuleActionReject) {
+		return false
+	}
+	return rule.Audit || policyAudit
+}
+
 // toAntreaIPBlockForCRD converts a v1alpha1.IPBlock to an Antrea IPBlock.
 func toAntreaIPBlockForCRD(ipBlock *v1alpha1.IPBlock) (*controlplane.IPBlock, error) {
 	// Convert the allowed IPBlock to networkpolicy.IPNet.
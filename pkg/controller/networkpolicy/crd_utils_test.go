@@ -89,6 +89,80 @@ func TestToAntreaServicesForCRD(t *testing.T) {
 	}
 }
 
+func TestToAntreaL7ProtocolsForCRD(t *testing.T) {
+	tables := []struct {
+		l7Protocols    []crdv1alpha1.L7Protocol
+		expL7Protocols []controlplane.L7Protocol
+	}{
+		{
+			l7Protocols:    nil,
+			expL7Protocols: nil,
+		},
+		{
+			l7Protocols: []crdv1alpha1.L7Protocol{
+				{
+					HTTP: &crdv1alpha1.HTTPProtocol{
+						Host:   "*.example.com",
+						Method: "get",
+						Path:   "/api/*",
+					},
+				},
+				{
+					HTTP: &crdv1alpha1.HTTPProtocol{},
+				},
+			},
+			expL7Protocols: []controlplane.L7Protocol{
+				{
+					HTTP: &controlplane.HTTPProtocol{
+						Host:   "*.example.com",
+						Method: "GET",
+						Path:   "/api/*",
+					},
+				},
+				{
+					HTTP: &controlplane.HTTPProtocol{},
+				},
+			},
+		},
+	}
+	for _, table := range tables {
+		assert.Equal(t, table.expL7Protocols, toAntreaL7ProtocolsForCRD(table.l7Protocols))
+	}
+}
+
+func TestIsAuditRule(t *testing.T) {
+	allowAction := crdv1alpha1.RuleActionAllow
+	dropAction := crdv1alpha1.RuleActionDrop
+	rejectAction := crdv1alpha1.RuleActionReject
+	tables := []struct {
+		rule        crdv1alpha1.Rule
+		policyAudit bool
+		expAudit    bool
+	}{
+		{
+			rule:     crdv1alpha1.Rule{Action: &dropAction},
+			expAudit: false,
+		},
+		{
+			rule:     crdv1alpha1.Rule{Action: &dropAction, Audit: true},
+			expAudit: true,
+		},
+		{
+			rule:        crdv1alpha1.Rule{Action: &rejectAction},
+			policyAudit: true,
+			expAudit:    true,
+		},
+		{
+			rule:        crdv1alpha1.Rule{Action: &allowAction},
+			policyAudit: true,
+			expAudit:    false,
+		},
+	}
+	for _, table := range tables {
+		assert.Equal(t, table.expAudit, isAuditRule(&table.rule, table.policyAudit))
+	}
+}
+
 func TestToAntreaIPBlockForCRD(t *testing.T) {
 	expIPNet := controlplane.IPNet{
 		IP:           ipStrToIPAddress("10.0.0.0"),
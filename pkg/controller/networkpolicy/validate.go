@@ -17,12 +17,14 @@ package networkpolicy
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 
 	admv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -79,6 +81,9 @@ var (
 	// allowedFQDNChars validates that the matchPattern field contains only valid DNS characters
 	// and the wildcard '*' character.
 	allowedFQDNChars = regexp.MustCompile("^[-0-9a-zA-Z.*]+$")
+	// allowedHTTPMethods stores the set of HTTP methods which can be matched by L7 rules.
+	allowedHTTPMethods = sets.NewString(http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace)
 )
 
 // RegisterAntreaPolicyValidator registers an Antrea-native policy validator
@@ -416,6 +421,14 @@ func (v *antreaPolicyValidator) createValidate(curObj interface{}, userInfo auth
 	if !allowed {
 		return reason, allowed
 	}
+	reason, allowed = v.validateL7Protocols(ingress, egress)
+	if !allowed {
+		return reason, allowed
+	}
+	reason, allowed = v.validateAudit(ingress, egress)
+	if !allowed {
+		return reason, allowed
+	}
 
 	if err := v.validatePort(ingress, egress); err != nil {
 		return err.Error(), false
@@ -548,6 +561,66 @@ func (v *antreaPolicyValidator) validateFQDNSelectors(egressRules []crdv1alpha1.
 	return "", true
 }
 
+// validateL7Protocols validates the l7Protocols field set in Antrea-native policy rules are valid.
+func (v *antreaPolicyValidator) validateL7Protocols(ingress, egress []crdv1alpha1.Rule) (string, bool) {
+	checkRules := func(rules []crdv1alpha1.Rule) (string, bool) {
+		for _, rule := range rules {
+			if len(rule.L7Protocols) == 0 {
+				continue
+			}
+			if !features.DefaultFeatureGate.Enabled(features.L7NetworkPolicy) {
+				return "`l7Protocols` can only be used when L7NetworkPolicy is enabled", false
+			}
+			if rule.Action == nil || *rule.Action != crdv1alpha1.RuleActionAllow {
+				return "`l7Protocols` can only be used with the Allow action", false
+			}
+			for _, port := range rule.Ports {
+				if port.Protocol != nil && *port.Protocol != v1.ProtocolTCP {
+					return "`l7Protocols` can only be used with TCP ports", false
+				}
+				if port.Port != nil && port.Port.Type == intstr.String {
+					return "`l7Protocols` cannot be used with named ports", false
+				}
+			}
+			if len(rule.ToServices) > 0 {
+				return "`l7Protocols` cannot be used with `toServices`", false
+			}
+			for _, peer := range rule.To {
+				if peer.FQDN != "" {
+					return "`l7Protocols` cannot be used with `fqdn`", false
+				}
+			}
+			for _, l7Protocol := range rule.L7Protocols {
+				if l7Protocol.HTTP == nil {
+					return "`http` must be set for each item of `l7Protocols`", false
+				}
+				if l7Protocol.HTTP.Method != "" && !allowedHTTPMethods.Has(strings.ToUpper(l7Protocol.HTTP.Method)) {
+					return fmt.Sprintf("invalid HTTP method in l7Protocols: %s", l7Protocol.HTTP.Method), false
+				}
+				if l7Protocol.HTTP.Path != "" && !strings.HasPrefix(l7Protocol.HTTP.Path, "/") {
+					return fmt.Sprintf("HTTP path in l7Protocols must start with '/': %s", l7Protocol.HTTP.Path), false
+				}
+			}
+		}
+		return "", true
+	}
+	if reason, allowed := checkRules(ingress); !allowed {
+		return reason, allowed
+	}
+	return checkRules(egress)
+}
+
+// validateAudit validates that the audit mode is only set for the rules whose action
+// can be audited, i.e. Drop and Reject.
+func (v *antreaPolicyValidator) validateAudit(ingress, egress []crdv1alpha1.Rule) (string, bool) {
+	for _, rule := range append(ingress, egress...) {
+		if rule.Audit && (rule.Action == nil || (*rule.Action != crdv1alpha1.RuleActionDrop && *rule.Action != crdv1alpha1.RuleActionReject)) {
+			return "`audit` can only be set for rules with the Drop or Reject action", false
+		}
+	}
+	return "", true
+}
+
 // updateValidate validates the UPDATE events of Antrea-native policies.
 func (v *antreaPolicyValidator) updateValidate(curObj, oldObj interface{}, userInfo authenticationv1.UserInfo) (string, bool) {
 	var tier string
@@ -582,6 +655,14 @@ func (v *antreaPolicyValidator) updateValidate(curObj, oldObj interface{}, userI
 	if !allowed {
 		return reason, allowed
 	}
+	reason, allowed = v.validateL7Protocols(ingress, egress)
+	if !allowed {
+		return reason, allowed
+	}
+	reason, allowed = v.validateAudit(ingress, egress)
+	if !allowed {
+		return reason, allowed
+	}
 	if err := v.validatePort(ingress, egress); err != nil {
 		return err.Error(), false
 	}
@@ -0,0 +1,417 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/apis"
+	"antrea.io/antrea/pkg/controller/externalippool"
+)
+
+const (
+	controllerName = "ServiceExternalIPController"
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+	// How long to wait before retrying the processing of a Service change.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// Default number of workers processing a Service change.
+	defaultWorkers = 4
+
+	externalIPPoolIndex = "externalIPPool"
+)
+
+// ipAllocation contains the IP and the IP Pool which allocates it.
+type ipAllocation struct {
+	ip     net.IP
+	ipPool string
+}
+
+// ServiceExternalIPController is responsible for allocating the LoadBalancer IPs of Services from the ExternalIPPools
+// specified by their annotations and reporting them in the status of the Services.
+type ServiceExternalIPController struct {
+	client kubernetes.Interface
+
+	externalIPAllocator externalippool.ExternalIPAllocator
+
+	// ipAllocationMap is a map from Service key to ipAllocation, which is used to check whether the Service's IP has
+	// changed and to release the IP after the Service is removed.
+	ipAllocationMap   map[string]*ipAllocation
+	ipAllocationMutex sync.RWMutex
+
+	serviceLister  corelisters.ServiceLister
+	serviceIndexer cache.Indexer
+	// serviceListerSynced is a function which returns true if the Services shared informer has been synced at least once.
+	serviceListerSynced cache.InformerSynced
+	// queue maintains the keys of the Services that need to be synced.
+	queue workqueue.RateLimitingInterface
+}
+
+// NewServiceExternalIPController returns a new *ServiceExternalIPController.
+func NewServiceExternalIPController(client kubernetes.Interface,
+	serviceInformer coreinformers.ServiceInformer,
+	externalIPAllocator externalippool.ExternalIPAllocator) *ServiceExternalIPController {
+	c := &ServiceExternalIPController{
+		client:              client,
+		externalIPAllocator: externalIPAllocator,
+		ipAllocationMap:     map[string]*ipAllocation{},
+		serviceLister:       serviceInformer.Lister(),
+		serviceIndexer:      serviceInformer.Informer().GetIndexer(),
+		serviceListerSynced: serviceInformer.Informer().HasSynced,
+		queue:               workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "serviceExternalIP"),
+	}
+	serviceInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addService,
+			UpdateFunc: c.updateService,
+			DeleteFunc: c.deleteService,
+		},
+		resyncPeriod,
+	)
+	// externalIPPoolIndex will be used to get all Services associated with a given ExternalIPPool.
+	serviceInformer.Informer().AddIndexers(cache.Indexers{externalIPPoolIndex: func(obj interface{}) ([]string, error) {
+		service, ok := obj.(*corev1.Service)
+		if !ok {
+			return nil, fmt.Errorf("obj is not Service: %+v", obj)
+		}
+		ipPool := getServiceExternalIPPool(service)
+		if ipPool == "" {
+			return nil, nil
+		}
+		return []string{ipPool}, nil
+	}})
+	c.externalIPAllocator.AddEventHandler(func(ipPool string) {
+		c.enqueueServices(ipPool)
+	})
+	return c
+}
+
+// getServiceExternalIPPool returns the ExternalIPPool from which the LoadBalancer IP of the Service should be
+// allocated. It returns an empty string if the Service is not a LoadBalancer Service or doesn't have the annotation.
+func getServiceExternalIPPool(service *corev1.Service) string {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return ""
+	}
+	return service.Annotations[apis.ServiceExternalIPPoolAnnotationKey]
+}
+
+// getServiceExternalIP returns the first LoadBalancer IP in the status of the Service.
+func getServiceExternalIP(service *corev1.Service) string {
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
+
+func (c *ServiceExternalIPController) enqueueService(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get key of Service")
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *ServiceExternalIPController) addService(obj interface{}) {
+	service := obj.(*corev1.Service)
+	if getServiceExternalIPPool(service) == "" {
+		return
+	}
+	klog.V(2).InfoS("Processing Service ADD event", "service", klog.KObj(service))
+	c.enqueueService(service)
+}
+
+func (c *ServiceExternalIPController) updateService(oldObj, curObj interface{}) {
+	oldService := oldObj.(*corev1.Service)
+	curService := curObj.(*corev1.Service)
+	// The Service needs to be synced if it requests or requested an IP from an ExternalIPPool.
+	if getServiceExternalIPPool(oldService) == "" && getServiceExternalIPPool(curService) == "" {
+		return
+	}
+	klog.V(2).InfoS("Processing Service UPDATE event", "service", klog.KObj(curService))
+	c.enqueueService(curService)
+}
+
+func (c *ServiceExternalIPController) deleteService(obj interface{}) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		service, ok = deletedState.Obj.(*corev1.Service)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-Service object: %v", deletedState.Obj)
+			return
+		}
+	}
+	if getServiceExternalIPPool(service) == "" {
+		return
+	}
+	klog.V(2).InfoS("Processing Service DELETE event", "service", klog.KObj(service))
+	c.enqueueService(service)
+}
+
+// enqueueServices enqueues all Services that refer to the provided ExternalIPPool.
+func (c *ServiceExternalIPController) enqueueServices(ipPool string) {
+	objects, _ := c.serviceIndexer.ByIndex(externalIPPoolIndex, ipPool)
+	for _, object := range objects {
+		c.enqueueService(object)
+	}
+}
+
+// Run begins watching and syncing of the ServiceExternalIPController.
+func (c *ServiceExternalIPController) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+
+	cacheSyncs := []cache.InformerSynced{c.serviceListerSynced, c.externalIPAllocator.HasSynced}
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, cacheSyncs...) {
+		return
+	}
+	services, _ := c.serviceLister.List(labels.Everything())
+	c.restoreIPAllocations(services)
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+// restoreIPAllocations restores the existing LoadBalancer IPs of Services and records the successful ones in
+// ipAllocationMap.
+func (c *ServiceExternalIPController) restoreIPAllocations(services []*corev1.Service) {
+	var previousIPAllocations []externalippool.IPAllocation
+	for _, service := range services {
+		ipPool := getServiceExternalIPPool(service)
+		ip := getServiceExternalIP(service)
+		// Ignore Service that is not associated to ExternalIPPool or doesn't have LoadBalancer IP assigned.
+		if ipPool == "" || ip == "" {
+			continue
+		}
+		allocation := externalippool.IPAllocation{
+			ObjectReference: corev1.ObjectReference{
+				Namespace: service.Namespace,
+				Name:      service.Name,
+				Kind:      "Service",
+			},
+			IPPoolName: ipPool,
+			IP:         net.ParseIP(ip),
+		}
+		previousIPAllocations = append(previousIPAllocations, allocation)
+	}
+	succeededAllocations := c.externalIPAllocator.RestoreIPAllocations(previousIPAllocations)
+	for _, alloc := range succeededAllocations {
+		key := alloc.ObjectReference.Namespace + "/" + alloc.ObjectReference.Name
+		c.setIPAllocation(key, alloc.IP, alloc.IPPoolName)
+		klog.InfoS("Restored LoadBalancer IP", "service", key, "ip", alloc.IP, "pool", alloc.IPPoolName)
+	}
+}
+
+func (c *ServiceExternalIPController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *ServiceExternalIPController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncService(key.(string))
+	if err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.queue.AddRateLimited(key)
+		klog.Errorf("Failed to sync Service %s: %v", key, err)
+		return true
+	}
+	// If no error occurs we Forget this item so it does not get queued again until
+	// another change happens.
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *ServiceExternalIPController) getIPAllocation(key string) (net.IP, string, bool) {
+	c.ipAllocationMutex.RLock()
+	defer c.ipAllocationMutex.RUnlock()
+	allocation, exists := c.ipAllocationMap[key]
+	if !exists {
+		return nil, "", false
+	}
+	return allocation.ip, allocation.ipPool, true
+}
+
+func (c *ServiceExternalIPController) deleteIPAllocation(key string) {
+	c.ipAllocationMutex.Lock()
+	defer c.ipAllocationMutex.Unlock()
+	delete(c.ipAllocationMap, key)
+}
+
+func (c *ServiceExternalIPController) setIPAllocation(key string, ip net.IP, poolName string) {
+	c.ipAllocationMutex.Lock()
+	defer c.ipAllocationMutex.Unlock()
+	c.ipAllocationMap[key] = &ipAllocation{
+		ip:     ip,
+		ipPool: poolName,
+	}
+}
+
+// releaseIP removes the Service's ipAllocation in the cache and releases the IP to the pool.
+func (c *ServiceExternalIPController) releaseIP(key string, ip net.IP, poolName string) error {
+	if err := c.externalIPAllocator.ReleaseIP(poolName, ip); err != nil {
+		if err == externalippool.ErrExternalIPPoolNotFound {
+			// Ignore the error since the external IP Pool could be deleted.
+			klog.Warningf("Failed to release IP %s because IP Pool %s does not exist", ip, poolName)
+		} else {
+			klog.ErrorS(err, "Failed to release IP", "ip", ip, "pool", poolName)
+			return err
+		}
+	} else {
+		klog.InfoS("Released LoadBalancer IP", "service", key, "ip", ip, "pool", poolName)
+	}
+	c.deleteIPAllocation(key)
+	return nil
+}
+
+// updateServiceExternalIP updates the LoadBalancer IP in the status of the Service. The LoadBalancer ingress is
+// cleared if the IP is empty.
+func (c *ServiceExternalIPController) updateServiceExternalIP(service *corev1.Service, ip string) error {
+	var ingress []corev1.LoadBalancerIngress
+	if ip != "" {
+		ingress = []corev1.LoadBalancerIngress{{IP: ip}}
+	}
+	toUpdate := service.DeepCopy()
+	var updateErr, getErr error
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if len(toUpdate.Status.LoadBalancer.Ingress) == len(ingress) && getServiceExternalIP(toUpdate) == ip {
+			return nil
+		}
+		toUpdate.Status.LoadBalancer.Ingress = ingress
+		_, updateErr = c.client.CoreV1().Services(service.Namespace).UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		if updateErr != nil && errors.IsConflict(updateErr) {
+			if toUpdate, getErr = c.client.CoreV1().Services(service.Namespace).Get(context.TODO(), service.Name, metav1.GetOptions{}); getErr != nil {
+				return getErr
+			}
+		}
+		// Return the error from UPDATE.
+		return updateErr
+	}); err != nil {
+		return fmt.Errorf("error when updating LoadBalancer IP of Service %s/%s: %v", service.Namespace, service.Name, err)
+	}
+	return nil
+}
+
+func (c *ServiceExternalIPController) syncService(key string) error {
+	startTime := time.Now()
+	defer func() {
+		d := time.Since(startTime)
+		klog.V(2).Infof("Finished syncing Service %s. (%v)", key, d)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The Service has been deleted, release its IP if there was one.
+			if prevIP, prevIPPool, exists := c.getIPAllocation(key); exists {
+				return c.releaseIP(key, prevIP, prevIPPool)
+			}
+			return nil
+		}
+		return err
+	}
+
+	ipPool := getServiceExternalIPPool(service)
+	currentIP := getServiceExternalIP(service)
+	prevIP, prevIPPool, exists := c.getIPAllocation(key)
+	if exists {
+		// The ExternalIPPool and the requested IP don't change, make sure the IP is reported in the status.
+		if prevIPPool == ipPool && c.externalIPAllocator.IPPoolExists(ipPool) &&
+			(service.Spec.LoadBalancerIP == "" || service.Spec.LoadBalancerIP == prevIP.String()) {
+			return c.updateServiceExternalIP(service, prevIP.String())
+		}
+		// Either the ExternalIPPool or the requested IP changes, release the previous IP first.
+		if err := c.releaseIP(key, prevIP, prevIPPool); err != nil {
+			return err
+		}
+		// Reclaim the IP from the Service's status if it's still reported.
+		if currentIP == prevIP.String() {
+			if err := c.updateServiceExternalIP(service, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	if ipPool == "" {
+		return nil
+	}
+	if !c.externalIPAllocator.IPPoolExists(ipPool) {
+		return fmt.Errorf("ExternalIPPool %s not exists", ipPool)
+	}
+
+	var ip net.IP
+	if service.Spec.LoadBalancerIP != "" {
+		// User specifies the LoadBalancer IP, try to allocate it from the ExternalIPPool.
+		ip = net.ParseIP(service.Spec.LoadBalancerIP)
+		if ip == nil {
+			return fmt.Errorf("invalid LoadBalancer IP %s of Service %s", service.Spec.LoadBalancerIP, key)
+		}
+		if err := c.externalIPAllocator.UpdateIPAllocation(ipPool, ip); err != nil {
+			return fmt.Errorf("error when allocating IP %v for Service %s from ExternalIPPool %s: %v", ip, key, ipPool, err)
+		}
+	} else {
+		ip, err = c.externalIPAllocator.AllocateIPFromPool(ipPool)
+		if err != nil {
+			return fmt.Errorf("error when allocating IP for Service %s from ExternalIPPool %s: %v", key, ipPool, err)
+		}
+	}
+	if err := c.updateServiceExternalIP(service, ip.String()); err != nil {
+		if rerr := c.externalIPAllocator.ReleaseIP(ipPool, ip); rerr != nil &&
+			rerr != externalippool.ErrExternalIPPoolNotFound {
+			klog.ErrorS(rerr, "Failed to release IP", "ip", ip, "pool", ipPool)
+		}
+		return err
+	}
+	c.setIPAllocation(key, ip, ipPool)
+	klog.InfoS("Allocated LoadBalancer IP", "service", key, "ip", ip, "pool", ipPool)
+	return nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceexternalip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"antrea.io/antrea/pkg/apis"
+	"antrea.io/antrea/pkg/apis/crd/v1alpha2"
+	"antrea.io/antrea/pkg/client/clientset/versioned"
+	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	"antrea.io/antrea/pkg/controller/externalippool"
+)
+
+var (
+	eipFoo1 = newExternalIPPool("pool1", "1.1.1.0/24", "", "")
+	eipFoo2 = newExternalIPPool("pool2", "", "2.2.2.10", "2.2.2.20")
+)
+
+func newExternalIPPool(name, cidr, start, end string) *v1alpha2.ExternalIPPool {
+	pool := &v1alpha2.ExternalIPPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	if len(cidr) > 0 {
+		pool.Spec.IPRanges = append(pool.Spec.IPRanges, v1alpha2.IPRange{CIDR: cidr})
+	}
+	if len(start) > 0 && len(end) > 0 {
+		pool.Spec.IPRanges = append(pool.Spec.IPRanges, v1alpha2.IPRange{Start: start, End: end})
+	}
+	return pool
+}
+
+func newService(name, externalIPPool, loadBalancerIP, ingressIP string) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.ServiceSpec{
+			Type:           corev1.ServiceTypeLoadBalancer,
+			LoadBalancerIP: loadBalancerIP,
+		},
+	}
+	if externalIPPool != "" {
+		service.Annotations = map[string]string{apis.ServiceExternalIPPoolAnnotationKey: externalIPPool}
+	}
+	if ingressIP != "" {
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: ingressIP}}
+	}
+	return service
+}
+
+type serviceExternalIPController struct {
+	*ServiceExternalIPController
+	client              kubernetes.Interface
+	crdClient           versioned.Interface
+	informerFactory     informers.SharedInformerFactory
+	crdInformerFactory  crdinformers.SharedInformerFactory
+	externalIPAllocator *externalippool.ExternalIPPoolController
+}
+
+func newController(objects, crdObjects []runtime.Object) *serviceExternalIPController {
+	client := fake.NewSimpleClientset(objects...)
+	crdClient := fakeversioned.NewSimpleClientset(crdObjects...)
+	informerFactory := informers.NewSharedInformerFactory(client, resyncPeriod)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, resyncPeriod)
+	externalIPAllocator := externalippool.NewExternalIPPoolController(crdClient, crdInformerFactory.Crd().V1alpha2().ExternalIPPools())
+	controller := NewServiceExternalIPController(client, informerFactory.Core().V1().Services(), externalIPAllocator)
+	return &serviceExternalIPController{
+		controller,
+		client,
+		crdClient,
+		informerFactory,
+		crdInformerFactory,
+		externalIPAllocator,
+	}
+}
+
+func (c *serviceExternalIPController) start(t *testing.T, stopCh chan struct{}) {
+	c.informerFactory.Start(stopCh)
+	c.crdInformerFactory.Start(stopCh)
+	c.informerFactory.WaitForCacheSync(stopCh)
+	c.crdInformerFactory.WaitForCacheSync(stopCh)
+	go c.externalIPAllocator.Run(stopCh)
+	require.True(t, cache.WaitForCacheSync(stopCh, c.externalIPAllocator.HasSynced))
+	go c.Run(stopCh)
+}
+
+// checkServiceExternalIP waits until the Service reports the expected LoadBalancer IP. An empty expectedIP means
+// the IP is expected to be allocated from the given ExternalIPPool.
+func checkServiceExternalIP(t *testing.T, c *serviceExternalIPController, name, expectedIP, ipPool string) string {
+	var gotIP string
+	err := wait.PollImmediate(50*time.Millisecond, 2*time.Second, func() (bool, error) {
+		service, err := c.client.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		gotIP = getServiceExternalIP(service)
+		if expectedIP != "" {
+			return gotIP == expectedIP, nil
+		}
+		return gotIP != "" && c.externalIPAllocator.IPPoolHasIP(ipPool, net.ParseIP(gotIP)), nil
+	})
+	require.NoError(t, err, "Service %s didn't get the expected LoadBalancer IP, got %q", name, gotIP)
+	return gotIP
+}
+
+// checkServiceExternalIPCleared waits until the Service doesn't report any LoadBalancer IP.
+func checkServiceExternalIPCleared(t *testing.T, c *serviceExternalIPController, name string) {
+	err := wait.PollImmediate(50*time.Millisecond, 2*time.Second, func() (bool, error) {
+		service, err := c.client.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return len(service.Status.LoadBalancer.Ingress) == 0, nil
+	})
+	require.NoError(t, err, "LoadBalancer IP of Service %s is not cleared", name)
+}
+
+func checkExternalIPPoolUsed(t *testing.T, c *serviceExternalIPController, poolName string, used int) {
+	err := wait.PollImmediate(50*time.Millisecond, 2*time.Second, func() (found bool, err error) {
+		eip, err := c.crdClient.CrdV1alpha2().ExternalIPPools().Get(context.TODO(), poolName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return eip.Status.Usage.Used == used, nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestAllocateServiceExternalIP(t *testing.T) {
+	tests := []struct {
+		name              string
+		service           *corev1.Service
+		expectedIP        string
+		expectedIPPool    string
+		expectedPool1Used int
+	}{
+		{
+			name:              "allocate IP from pool",
+			service:           newService("svc1", eipFoo1.Name, "", ""),
+			expectedIPPool:    eipFoo1.Name,
+			expectedPool1Used: 1,
+		},
+		{
+			name:              "allocate requested LoadBalancer IP",
+			service:           newService("svc1", eipFoo1.Name, "1.1.1.100", ""),
+			expectedIP:        "1.1.1.100",
+			expectedPool1Used: 1,
+		},
+		{
+			name:              "Service without annotation",
+			service:           newService("svc1", "", "", ""),
+			expectedPool1Used: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			controller := newController([]runtime.Object{tt.service}, []runtime.Object{eipFoo1})
+			controller.start(t, stopCh)
+
+			if tt.expectedIP != "" || tt.expectedIPPool != "" {
+				checkServiceExternalIP(t, controller, tt.service.Name, tt.expectedIP, tt.expectedIPPool)
+			} else {
+				// Give the controller some time to process the Service.
+				time.Sleep(200 * time.Millisecond)
+				service, err := controller.client.CoreV1().Services("default").Get(context.TODO(), tt.service.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Empty(t, service.Status.LoadBalancer.Ingress)
+			}
+			checkExternalIPPoolUsed(t, controller, eipFoo1.Name, tt.expectedPool1Used)
+		})
+	}
+}
+
+func TestUpdateServiceExternalIP(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	service := newService("svc1", eipFoo1.Name, "", "")
+	controller := newController([]runtime.Object{service}, []runtime.Object{eipFoo1, eipFoo2})
+	controller.start(t, stopCh)
+
+	checkServiceExternalIP(t, controller, service.Name, "", eipFoo1.Name)
+	checkExternalIPPoolUsed(t, controller, eipFoo1.Name, 1)
+
+	// Changing the ExternalIPPool should release the previous IP and allocate a new one from the new pool.
+	service, err := controller.client.CoreV1().Services("default").Get(context.TODO(), service.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	service.Annotations[apis.ServiceExternalIPPoolAnnotationKey] = eipFoo2.Name
+	_, err = controller.client.CoreV1().Services("default").Update(context.TODO(), service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	checkServiceExternalIP(t, controller, service.Name, "", eipFoo2.Name)
+	checkExternalIPPoolUsed(t, controller, eipFoo1.Name, 0)
+	checkExternalIPPoolUsed(t, controller, eipFoo2.Name, 1)
+
+	// Requesting a specific IP should replace the allocated one.
+	service, err = controller.client.CoreV1().Services("default").Get(context.TODO(), service.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	service.Spec.LoadBalancerIP = "2.2.2.15"
+	_, err = controller.client.CoreV1().Services("default").Update(context.TODO(), service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	checkServiceExternalIP(t, controller, service.Name, "2.2.2.15", "")
+	checkExternalIPPoolUsed(t, controller, eipFoo2.Name, 1)
+
+	// Removing the annotation should release the IP and reclaim it from the Service.
+	service, err = controller.client.CoreV1().Services("default").Get(context.TODO(), service.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	service.Annotations = nil
+	_, err = controller.client.CoreV1().Services("default").Update(context.TODO(), service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	checkServiceExternalIPCleared(t, controller, service.Name)
+	checkExternalIPPoolUsed(t, controller, eipFoo2.Name, 0)
+}
+
+func TestDeleteService(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	service := newService("svc1", eipFoo1.Name, "", "")
+	controller := newController([]runtime.Object{service}, []runtime.Object{eipFoo1})
+	controller.start(t, stopCh)
+
+	checkServiceExternalIP(t, controller, service.Name, "", eipFoo1.Name)
+	checkExternalIPPoolUsed(t, controller, eipFoo1.Name, 1)
+
+	require.NoError(t, controller.client.CoreV1().Services("default").Delete(context.TODO(), service.Name, metav1.DeleteOptions{}))
+	checkExternalIPPoolUsed(t, controller, eipFoo1.Name, 0)
+}
+
+func TestRestoreIPAllocations(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	// svc1 keeps its IP after restart, svc2 reports an IP which is not in the pool and gets a new one.
+	svc1 := newService("svc1", eipFoo1.Name, "", "1.1.1.10")
+	svc2 := newService("svc2", eipFoo1.Name, "", "3.3.3.3")
+	controller := newController([]runtime.Object{svc1, svc2}, []runtime.Object{eipFoo1})
+	controller.start(t, stopCh)
+
+	checkServiceExternalIP(t, controller, svc1.Name, "1.1.1.10", "")
+	gotIP := checkServiceExternalIP(t, controller, svc2.Name, "", eipFoo1.Name)
+	assert.NotEqual(t, "1.1.1.10", gotIP)
+	checkExternalIPPoolUsed(t, controller, eipFoo1.Name, 2)
+}
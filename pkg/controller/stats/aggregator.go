@@ -21,6 +21,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	networkinginformers "k8s.io/client-go/informers/networking/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -29,12 +30,17 @@ import (
 	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
 	statsv1alpha1 "antrea.io/antrea/pkg/apis/stats/v1alpha1"
 	crdvinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+	"antrea.io/antrea/pkg/controller/metrics"
 	"antrea.io/antrea/pkg/features"
 	"antrea.io/antrea/pkg/util/k8s"
+	"antrea.io/antrea/pkg/util/rulemetrics"
 )
 
 const (
 	uidIndex = "uid"
+	// defaultTierName is the Tier of the Antrea-native policies which don't specify one.
+	defaultTierName = "application"
 )
 
 // Aggregator collects the stats from the antrea-agents, aggregates them, caches the result, and provides interfaces
@@ -58,6 +64,12 @@ type Aggregator struct {
 	cnpListerSynced cache.InformerSynced
 	// anpListerSynced is a function which returns true if the Antrea NetworkPolicy shared informer has been synced at least once.
 	anpListerSynced cache.InformerSynced
+	// cnpLister and anpLister are used to get the Tiers of the Antrea-native policies for the rule metrics.
+	cnpLister crdlisters.ClusterNetworkPolicyLister
+	anpLister crdlisters.NetworkPolicyLister
+	// ruleMetricsBudget decides which rules are exported as Prometheus metrics. It's nil if the rule metrics are
+	// disabled.
+	ruleMetricsBudget *rulemetrics.Budget
 }
 
 // uidIndexFunc is an index function that indexes based on an object's UID.
@@ -69,11 +81,14 @@ func uidIndexFunc(obj interface{}) ([]string, error) {
 	return []string{string(meta.GetUID())}, nil
 }
 
-func NewAggregator(networkPolicyInformer networkinginformers.NetworkPolicyInformer, cnpInformer crdvinformers.ClusterNetworkPolicyInformer, anpInformer crdvinformers.NetworkPolicyInformer) *Aggregator {
+// NewAggregator returns a new *Aggregator. ruleMetricsBudget can be nil if the rule metrics are disabled.
+func NewAggregator(networkPolicyInformer networkinginformers.NetworkPolicyInformer, cnpInformer crdvinformers.ClusterNetworkPolicyInformer, anpInformer crdvinformers.NetworkPolicyInformer,
+	ruleMetricsBudget *rulemetrics.Budget) *Aggregator {
 	aggregator := &Aggregator{
 		networkPolicyStats: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc, uidIndex: uidIndexFunc}),
 		dataCh:             make(chan *controlplane.NodeStatsSummary, 1000),
 		npListerSynced:     networkPolicyInformer.Informer().HasSynced,
+		ruleMetricsBudget:  ruleMetricsBudget,
 	}
 	// Add handlers for NetworkPolicy events.
 	// They are the source of truth of the NetworkPolicyStats, i.e., a NetworkPolicyStats is present only if the
@@ -92,9 +107,11 @@ func NewAggregator(networkPolicyInformer networkinginformers.NetworkPolicyInform
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
 		aggregator.antreaClusterNetworkPolicyStats = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{uidIndex: uidIndexFunc})
 		aggregator.cnpListerSynced = cnpInformer.Informer().HasSynced
+		aggregator.cnpLister = cnpInformer.Lister()
 		cnpInformer.Informer().AddEventHandlerWithResyncPeriod(
 			cache.ResourceEventHandlerFuncs{
 				AddFunc:    aggregator.addCNP,
+				UpdateFunc: aggregator.updateCNP,
 				DeleteFunc: aggregator.deleteCNP,
 			},
 			// Set resyncPeriod to 0 to disable resyncing.
@@ -103,9 +120,11 @@ func NewAggregator(networkPolicyInformer networkinginformers.NetworkPolicyInform
 
 		aggregator.antreaNetworkPolicyStats = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc, uidIndex: uidIndexFunc})
 		aggregator.anpListerSynced = anpInformer.Informer().HasSynced
+		aggregator.anpLister = anpInformer.Lister()
 		anpInformer.Informer().AddEventHandlerWithResyncPeriod(
 			cache.ResourceEventHandlerFuncs{
 				AddFunc:    aggregator.addANP,
+				UpdateFunc: aggregator.updateANP,
 				DeleteFunc: aggregator.deleteANP,
 			},
 			// Set resyncPeriod to 0 to disable resyncing.
@@ -153,6 +172,7 @@ func (a *Aggregator) deleteNetworkPolicy(obj interface{}) {
 			UID:       np.UID,
 		},
 	}
+	a.deleteRuleMetrics(controlplane.K8sNetworkPolicy, np.Namespace, np.Name)
 	a.networkPolicyStats.Delete(stats)
 }
 
@@ -171,6 +191,13 @@ func (a *Aggregator) addCNP(obj interface{}) {
 	a.antreaClusterNetworkPolicyStats.Add(stats)
 }
 
+// updateCNP handles ClusterNetworkPolicy UPDATE events and deletes the metrics of the rules which are no longer in the
+// ClusterNetworkPolicy.
+func (a *Aggregator) updateCNP(_, cur interface{}) {
+	cnp := cur.(*crdv1alpha1.ClusterNetworkPolicy)
+	a.releaseStaleRuleMetrics(controlplane.AntreaClusterNetworkPolicy, "", cnp.Name, cnp.Spec.Tier, cnp.Spec.Ingress, cnp.Spec.Egress)
+}
+
 // deleteCNP handles ClusterNetworkPolicy DELETE events and deletes corresponding ClusterNetworkPolicyStats objects.
 func (a *Aggregator) deleteCNP(obj interface{}) {
 	cnp, ok := obj.(*crdv1alpha1.ClusterNetworkPolicy)
@@ -192,6 +219,7 @@ func (a *Aggregator) deleteCNP(obj interface{}) {
 			UID:  cnp.UID,
 		},
 	}
+	a.deleteRuleMetrics(controlplane.AntreaClusterNetworkPolicy, "", cnp.Name)
 	a.antreaClusterNetworkPolicyStats.Delete(stats)
 }
 
@@ -211,6 +239,13 @@ func (a *Aggregator) addANP(obj interface{}) {
 	a.antreaNetworkPolicyStats.Add(stats)
 }
 
+// updateANP handles Antrea NetworkPolicy UPDATE events and deletes the metrics of the rules which are no longer in the
+// Antrea NetworkPolicy.
+func (a *Aggregator) updateANP(_, cur interface{}) {
+	anp := cur.(*crdv1alpha1.NetworkPolicy)
+	a.releaseStaleRuleMetrics(controlplane.AntreaNetworkPolicy, anp.Namespace, anp.Name, anp.Spec.Tier, anp.Spec.Ingress, anp.Spec.Egress)
+}
+
 // deleteANP handles Antrea NetworkPolicy DELETE events and deletes corresponding AntreaNetworkPolicyStats objects.
 func (a *Aggregator) deleteANP(obj interface{}) {
 	anp, ok := obj.(*crdv1alpha1.NetworkPolicy)
@@ -233,6 +268,7 @@ func (a *Aggregator) deleteANP(obj interface{}) {
 			UID:       anp.UID,
 		},
 	}
+	a.deleteRuleMetrics(controlplane.AntreaNetworkPolicy, anp.Namespace, anp.Name)
 	a.antreaNetworkPolicyStats.Delete(stats)
 }
 
@@ -338,6 +374,7 @@ func (a *Aggregator) doCollect(summary *controlplane.NodeStatsSummary) {
 			curStats := objs[0].(*statsv1alpha1.NetworkPolicyStats).DeepCopy()
 			addUp(&curStats.TrafficStats, &stats.TrafficStats)
 			a.networkPolicyStats.Update(curStats)
+			a.addRuleMetrics(controlplane.K8sNetworkPolicy, curStats.Namespace, curStats.Name, "", &stats)
 		}
 	}
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
@@ -354,6 +391,9 @@ func (a *Aggregator) doCollect(summary *controlplane.NodeStatsSummary) {
 					addRulesUp(&curStats.RuleTrafficStats, &curStats.TrafficStats, stats.RuleTrafficStats)
 				}
 				a.antreaClusterNetworkPolicyStats.Update(curStats)
+				if a.ruleMetricsBudget != nil {
+					a.addRuleMetrics(controlplane.AntreaClusterNetworkPolicy, "", curStats.Name, a.getCNPTier(curStats.Name), &stats)
+				}
 			}
 		}
 
@@ -371,9 +411,106 @@ func (a *Aggregator) doCollect(summary *controlplane.NodeStatsSummary) {
 					addRulesUp(&curStats.RuleTrafficStats, &curStats.TrafficStats, stats.RuleTrafficStats)
 				}
 				a.antreaNetworkPolicyStats.Update(curStats)
+				if a.ruleMetricsBudget != nil {
+					a.addRuleMetrics(controlplane.AntreaNetworkPolicy, curStats.Namespace, curStats.Name, a.getANPTier(curStats.Namespace, curStats.Name), &stats)
+				}
+			}
+		}
+	}
+}
+
+func (a *Aggregator) getCNPTier(name string) string {
+	cnp, err := a.cnpLister.Get(name)
+	if err != nil || cnp.Spec.Tier == "" {
+		return defaultTierName
+	}
+	return cnp.Spec.Tier
+}
+
+func (a *Aggregator) getANPTier(namespace, name string) string {
+	anp, err := a.anpLister.NetworkPolicies(namespace).Get(name)
+	if err != nil || anp.Spec.Tier == "" {
+		return defaultTierName
+	}
+	return anp.Spec.Tier
+}
+
+// addRuleMetrics adds the stats of a policy reported by an antrea-agent to the Prometheus metrics of its rules, or of
+// the policy itself if the stats are not per rule.
+func (a *Aggregator) addRuleMetrics(policyType controlplane.NetworkPolicyType, namespace, name, tier string, stats *controlplane.NetworkPolicyStats) {
+	if a.ruleMetricsBudget == nil || !a.ruleMetricsBudget.Allows(tier, namespace) {
+		return
+	}
+	add := func(rule string, inc *statsv1alpha1.TrafficStats) {
+		key := rulemetrics.Key{PolicyType: string(policyType), PolicyNamespace: namespace, PolicyName: name, Rule: rule}
+		if !a.ruleMetricsBudget.Admit(key) {
+			metrics.NetworkPolicyRuleMetricsRejectedCount.Inc()
+			return
+		}
+		labelValues := key.LabelValues()
+		metrics.NetworkPolicyRulePacketCount.WithLabelValues(labelValues...).Add(float64(inc.Packets))
+		metrics.NetworkPolicyRuleByteCount.WithLabelValues(labelValues...).Add(float64(inc.Bytes))
+		metrics.NetworkPolicyRuleSessionCount.WithLabelValues(labelValues...).Add(float64(inc.Sessions))
+	}
+	if stats.TrafficStats.Bytes > 0 {
+		add("", &stats.TrafficStats)
+		return
+	}
+	for i := range stats.RuleTrafficStats {
+		add(stats.RuleTrafficStats[i].Name, &stats.RuleTrafficStats[i].TrafficStats)
+	}
+}
+
+// deleteRuleMetrics deletes the Prometheus metrics of the rules of a deleted policy.
+func (a *Aggregator) deleteRuleMetrics(policyType controlplane.NetworkPolicyType, namespace, name string) {
+	if a.ruleMetricsBudget == nil {
+		return
+	}
+	a.releaseRuleMetrics(func(key rulemetrics.Key) bool {
+		return key.PolicyType == string(policyType) && key.PolicyNamespace == namespace && key.PolicyName == name
+	})
+}
+
+// releaseStaleRuleMetrics deletes the Prometheus metrics of the rules of an updated Antrea-native policy which are no
+// longer reported, i.e. the rules removed from the policy, or all its rules if the policy is no longer in a Tier or
+// Namespace allowed by the budget, so that the budget can be used by other rules.
+func (a *Aggregator) releaseStaleRuleMetrics(policyType controlplane.NetworkPolicyType, namespace, name, tier string, ingress, egress []crdv1alpha1.Rule) {
+	if a.ruleMetricsBudget == nil {
+		return
+	}
+	if tier == "" {
+		tier = defaultTierName
+	}
+	allowed := a.ruleMetricsBudget.Allows(tier, namespace)
+	ruleNames := sets.NewString()
+	for _, rules := range [][]crdv1alpha1.Rule{ingress, egress} {
+		for _, rule := range rules {
+			// The name of a rule is empty if the mutating webhook is not enabled, the rules cannot be matched with their
+			// metrics in this case.
+			if rule.Name == "" {
+				return
 			}
+			ruleNames.Insert(rule.Name)
 		}
 	}
+	a.releaseRuleMetrics(func(key rulemetrics.Key) bool {
+		if key.PolicyType != string(policyType) || key.PolicyNamespace != namespace || key.PolicyName != name {
+			return false
+		}
+		// The stats reported by the antrea-agents which don't support rule stats are not per rule.
+		return !allowed || (key.Rule != "" && !ruleNames.Has(key.Rule))
+	})
+}
+
+// releaseRuleMetrics releases the selected rules from the budget and deletes their Prometheus metrics.
+func (a *Aggregator) releaseRuleMetrics(selected func(key rulemetrics.Key) bool) {
+	keys := a.ruleMetricsBudget.Release(selected)
+	for _, key := range keys {
+		labels := key.LabelSet()
+		metrics.NetworkPolicyRulePacketCount.Delete(labels)
+		metrics.NetworkPolicyRuleByteCount.Delete(labels)
+		metrics.NetworkPolicyRuleSessionCount.Delete(labels)
+	}
 }
 
 func addUp(stats *statsv1alpha1.TrafficStats, inc *statsv1alpha1.TrafficStats) {
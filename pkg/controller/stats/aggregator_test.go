@@ -28,13 +28,16 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	"k8s.io/component-base/metrics/testutil"
 
 	"antrea.io/antrea/pkg/apis/controlplane"
 	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
 	statsv1alpha1 "antrea.io/antrea/pkg/apis/stats/v1alpha1"
 	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
 	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	"antrea.io/antrea/pkg/controller/metrics"
 	"antrea.io/antrea/pkg/features"
+	"antrea.io/antrea/pkg/util/rulemetrics"
 )
 
 var (
@@ -491,7 +494,7 @@ func TestAggregatorCollectListGet(t *testing.T) {
 			informerFactory := informers.NewSharedInformerFactory(client, 12*time.Hour)
 			crdClient := fakeversioned.NewSimpleClientset(append(tt.existingAntreaClusterNetworkPolicies, tt.existingAntreaNetworkPolicies...)...)
 			crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 12*time.Hour)
-			a := NewAggregator(informerFactory.Networking().V1().NetworkPolicies(), crdInformerFactory.Crd().V1alpha1().ClusterNetworkPolicies(), crdInformerFactory.Crd().V1alpha1().NetworkPolicies())
+			a := NewAggregator(informerFactory.Networking().V1().NetworkPolicies(), crdInformerFactory.Crd().V1alpha1().ClusterNetworkPolicies(), crdInformerFactory.Crd().V1alpha1().NetworkPolicies(), nil)
 			informerFactory.Start(stopCh)
 			crdInformerFactory.Start(stopCh)
 			go a.Run(stopCh)
@@ -537,7 +540,7 @@ func TestDeleteNetworkPolicy(t *testing.T) {
 	informerFactory := informers.NewSharedInformerFactory(client, 12*time.Hour)
 	crdClient := fakeversioned.NewSimpleClientset(cnp1, anp1)
 	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 12*time.Hour)
-	a := NewAggregator(informerFactory.Networking().V1().NetworkPolicies(), crdInformerFactory.Crd().V1alpha1().ClusterNetworkPolicies(), crdInformerFactory.Crd().V1alpha1().NetworkPolicies())
+	a := NewAggregator(informerFactory.Networking().V1().NetworkPolicies(), crdInformerFactory.Crd().V1alpha1().ClusterNetworkPolicies(), crdInformerFactory.Crd().V1alpha1().NetworkPolicies(), nil)
 	informerFactory.Start(stopCh)
 	crdInformerFactory.Start(stopCh)
 	go a.Run(stopCh)
@@ -605,3 +608,142 @@ func TestDeleteNetworkPolicy(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestRuleMetrics(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, features.DefaultFeatureGate, features.AntreaPolicy, true)()
+	metrics.InitializePrometheusMetrics()
+	metrics.NetworkPolicyRulePacketCount.Reset()
+	initialRejectedCount, _ := testutil.GetCounterMetricValue(metrics.NetworkPolicyRuleMetricsRejectedCount)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	client := fake.NewSimpleClientset(np1)
+	informerFactory := informers.NewSharedInformerFactory(client, 12*time.Hour)
+	crdClient := fakeversioned.NewSimpleClientset(cnp1, anp1)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 12*time.Hour)
+	// The budget only allows 2 rules, the rule of anp1 is rejected.
+	budget := rulemetrics.NewBudget(2, nil, nil)
+	a := NewAggregator(informerFactory.Networking().V1().NetworkPolicies(), crdInformerFactory.Crd().V1alpha1().ClusterNetworkPolicies(), crdInformerFactory.Crd().V1alpha1().NetworkPolicies(), budget)
+	informerFactory.Start(stopCh)
+	crdInformerFactory.Start(stopCh)
+	go a.Run(stopCh)
+
+	summary := &controlplane.NodeStatsSummary{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+		NetworkPolicies: []controlplane.NetworkPolicyStats{
+			{
+				NetworkPolicy: controlplane.NetworkPolicyReference{UID: np1.UID},
+				TrafficStats: statsv1alpha1.TrafficStats{
+					Bytes:    10,
+					Packets:  1,
+					Sessions: 1,
+				},
+			},
+		},
+		AntreaClusterNetworkPolicies: []controlplane.NetworkPolicyStats{
+			{
+				NetworkPolicy: controlplane.NetworkPolicyReference{UID: cnp1.UID},
+				RuleTrafficStats: []statsv1alpha1.RuleTrafficStats{
+					{
+						Name: "rule1",
+						TrafficStats: statsv1alpha1.TrafficStats{
+							Bytes:    30,
+							Packets:  3,
+							Sessions: 3,
+						},
+					},
+				},
+			},
+		},
+		AntreaNetworkPolicies: []controlplane.NetworkPolicyStats{
+			{
+				NetworkPolicy: controlplane.NetworkPolicyReference{UID: anp1.UID},
+				RuleTrafficStats: []statsv1alpha1.RuleTrafficStats{
+					{
+						Name: "rule2",
+						TrafficStats: statsv1alpha1.TrafficStats{
+							Bytes:    30,
+							Packets:  3,
+							Sessions: 3,
+						},
+					},
+				},
+			},
+		},
+	}
+	// Wait for the informers to be synced so that the stats are not ignored.
+	err := wait.PollImmediate(100*time.Millisecond, time.Second, func() (done bool, err error) {
+		return len(a.ListNetworkPolicyStats("")) == 1 && len(a.ListAntreaClusterNetworkPolicyStats()) == 1 && len(a.ListAntreaNetworkPolicyStats("")) == 1, nil
+	})
+	require.NoError(t, err)
+	getPacketCount := func(key rulemetrics.Key) float64 {
+		v, err := testutil.GetCounterMetricValue(metrics.NetworkPolicyRulePacketCount.WithLabelValues(key.LabelValues()...))
+		require.NoError(t, err)
+		return v
+	}
+	getRejectedCount := func() float64 {
+		v, err := testutil.GetCounterMetricValue(metrics.NetworkPolicyRuleMetricsRejectedCount)
+		require.NoError(t, err)
+		return v - initialRejectedCount
+	}
+	np1Key := rulemetrics.Key{PolicyType: string(controlplane.K8sNetworkPolicy), PolicyNamespace: np1.Namespace, PolicyName: np1.Name}
+	cnp1Key := rulemetrics.Key{PolicyType: string(controlplane.AntreaClusterNetworkPolicy), PolicyName: cnp1.Name, Rule: "rule1"}
+	anp1Key := rulemetrics.Key{PolicyType: string(controlplane.AntreaNetworkPolicy), PolicyNamespace: anp1.Namespace, PolicyName: anp1.Name, Rule: "rule2"}
+
+	a.Collect(summary)
+	a.Collect(summary)
+	// The rule of anp1 is the last one processed in a summary.
+	err = wait.PollImmediate(100*time.Millisecond, time.Second, func() (done bool, err error) {
+		return getRejectedCount() == 2, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), getPacketCount(np1Key))
+	assert.Equal(t, float64(6), getPacketCount(cnp1Key))
+
+	// Deleting a policy deletes the metrics of its rules and frees its budget.
+	client.NetworkingV1().NetworkPolicies(np1.Namespace).Delete(context.TODO(), np1.Name, metav1.DeleteOptions{})
+	err = wait.PollImmediate(100*time.Millisecond, time.Second, func() (done bool, err error) {
+		return len(a.ListNetworkPolicyStats("")) == 0, nil
+	})
+	require.NoError(t, err)
+	a.Collect(&controlplane.NodeStatsSummary{
+		ObjectMeta:            metav1.ObjectMeta{Name: "node-1"},
+		AntreaNetworkPolicies: summary.AntreaNetworkPolicies,
+	})
+	err = wait.PollImmediate(100*time.Millisecond, time.Second, func() (done bool, err error) {
+		return getPacketCount(anp1Key) == 3, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), getRejectedCount())
+	assert.Equal(t, float64(6), getPacketCount(cnp1Key))
+	// The metrics of np1 start from 0 again as they have been deleted.
+	assert.Equal(t, float64(0), getPacketCount(np1Key))
+
+	// Removing a rule from a policy deletes its metrics and frees its budget for the new rule.
+	updatedCNP1 := cnp1.DeepCopy()
+	updatedCNP1.Spec.Ingress = []crdv1alpha1.Rule{{Name: "rule3"}}
+	crdClient.CrdV1alpha1().ClusterNetworkPolicies().Update(context.TODO(), updatedCNP1, metav1.UpdateOptions{})
+	cnp1NewKey := rulemetrics.Key{PolicyType: string(controlplane.AntreaClusterNetworkPolicy), PolicyName: cnp1.Name, Rule: "rule3"}
+	err = wait.PollImmediate(100*time.Millisecond, time.Second, func() (done bool, err error) {
+		a.Collect(&controlplane.NodeStatsSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			AntreaClusterNetworkPolicies: []controlplane.NetworkPolicyStats{
+				{
+					NetworkPolicy: controlplane.NetworkPolicyReference{UID: cnp1.UID},
+					RuleTrafficStats: []statsv1alpha1.RuleTrafficStats{
+						{
+							Name:         "rule3",
+							TrafficStats: statsv1alpha1.TrafficStats{Bytes: 10, Packets: 1, Sessions: 1},
+						},
+					},
+				},
+			},
+		})
+		return getPacketCount(cnp1NewKey) > 0, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), getPacketCount(cnp1Key))
+	assert.Equal(t, float64(3), getPacketCount(anp1Key))
+}
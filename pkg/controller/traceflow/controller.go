@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -369,6 +370,9 @@ func (c *Controller) updateTraceflowStatus(tf *crdv1alpha1.Traceflow, phase crdv
 		update.Status.StartTime = &t
 	}
 	update.Status.DataplaneTag = dataPlaneTag
+	if phase == crdv1alpha1.Succeeded {
+		sortResultsByHop(update.Status.Results)
+	}
 	if reason != "" {
 		update.Status.Reason = reason
 	}
@@ -376,6 +380,30 @@ func (c *Controller) updateTraceflowStatus(tf *crdv1alpha1.Traceflow, phase crdv
 	return err
 }
 
+// sortResultsByHop orders the NodeResults by the hops the Traceflow packet went
+// through: the sender Node comes first, followed by the other Nodes in
+// decreasing order of the packet TTL reported in their first Observation, as
+// the TTL is decremented when the packet is routed from one Node to another.
+func sortResultsByHop(results []crdv1alpha1.NodeResult) {
+	firstObservation := func(result *crdv1alpha1.NodeResult) crdv1alpha1.Observation {
+		if len(result.Observations) == 0 {
+			return crdv1alpha1.Observation{}
+		}
+		return result.Observations[0]
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		obI, obJ := firstObservation(&results[i]), firstObservation(&results[j])
+		senderI, senderJ := obI.Component == crdv1alpha1.ComponentSpoofGuard, obJ.Component == crdv1alpha1.ComponentSpoofGuard
+		if senderI != senderJ {
+			return senderI
+		}
+		if obI.TTL != obJ.TTL {
+			return obI.TTL > obJ.TTL
+		}
+		return results[i].Timestamp < results[j].Timestamp
+	})
+}
+
 func (c *Controller) occupyTag(tf *crdv1alpha1.Traceflow) error {
 	tag := tf.Status.DataplaneTag
 	if tag < minTagNum || tag > maxTagNum {
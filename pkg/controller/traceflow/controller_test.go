@@ -290,3 +290,34 @@ func Test_podIPsIndexFunc(t *testing.T) {
 		})
 	}
 }
+
+func Test_sortResultsByHop(t *testing.T) {
+	results := []crdv1alpha1.NodeResult{
+		{
+			Node:         "node3",
+			Timestamp:    2,
+			Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentForwarding, Action: crdv1alpha1.ActionReceived, TTL: 61}},
+		},
+		{
+			Node:         "node2",
+			Timestamp:    2,
+			Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentForwarding, Action: crdv1alpha1.ActionReceived, TTL: 62}},
+		},
+		{
+			Node:         "node1",
+			Timestamp:    1,
+			Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard, Action: crdv1alpha1.ActionForwarded, TTL: 64}},
+		},
+		{
+			Node:         "node4",
+			Timestamp:    1,
+			Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentForwarding, Action: crdv1alpha1.ActionReceived, TTL: 61}},
+		},
+	}
+	sortResultsByHop(results)
+	var nodes []string
+	for _, result := range results {
+		nodes = append(nodes, result.Node)
+	}
+	assert.Equal(t, []string{"node1", "node2", "node4", "node3"}, nodes)
+}
@@ -76,6 +76,27 @@ const (
 	// alpha: v1.4
 	// Enable flexible IPAM for Pods.
 	AntreaIPAM featuregate.Feature = "AntreaIPAM"
+
+	// alpha: v1.5
+	// Enable capturing the packets of selected Pods to pcapng files on the Node.
+	PacketCapture featuregate.Feature = "PacketCapture"
+
+	// alpha: v1.5
+	// Enable allocating the LoadBalancer IPs of Services from ExternalIPPools.
+	ServiceExternalIP featuregate.Feature = "ServiceExternalIP"
+
+	// alpha: v1.5
+	// Enable matching HTTP requests with the L7 protocol fields of Antrea-native policy rules.
+	L7NetworkPolicy featuregate.Feature = "L7NetworkPolicy"
+
+	// alpha: v1.5
+	// Enable TopologyAwareHints in AntreaProxy. This requires EndpointSlice to be enabled.
+	TopologyAwareHints featuregate.Feature = "TopologyAwareHints"
+
+	// alpha: v1.5
+	// Enable Direct Server Return for the LoadBalancer Services annotated with the DSR load balancer mode in
+	// AntreaProxy. This requires proxyAll to be enabled.
+	LoadBalancerModeDSR featuregate.Feature = "LoadBalancerModeDSR"
 )
 
 var (
@@ -90,16 +111,21 @@ var (
 	// To add a new feature, define a key for it above and add it here. The features will be
 	// available throughout Antrea binaries.
 	DefaultAntreaFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-		AntreaPolicy:       {Default: true, PreRelease: featuregate.Beta},
-		AntreaProxy:        {Default: true, PreRelease: featuregate.Beta},
-		Egress:             {Default: false, PreRelease: featuregate.Alpha},
-		EndpointSlice:      {Default: false, PreRelease: featuregate.Alpha},
-		Traceflow:          {Default: true, PreRelease: featuregate.Beta},
-		AntreaIPAM:         {Default: false, PreRelease: featuregate.Alpha},
-		FlowExporter:       {Default: false, PreRelease: featuregate.Alpha},
-		NetworkPolicyStats: {Default: true, PreRelease: featuregate.Beta},
-		NodePortLocal:      {Default: true, PreRelease: featuregate.Beta},
-		NodeIPAM:           {Default: false, PreRelease: featuregate.Alpha},
+		AntreaPolicy:        {Default: true, PreRelease: featuregate.Beta},
+		AntreaProxy:         {Default: true, PreRelease: featuregate.Beta},
+		Egress:              {Default: false, PreRelease: featuregate.Alpha},
+		EndpointSlice:       {Default: false, PreRelease: featuregate.Alpha},
+		Traceflow:           {Default: true, PreRelease: featuregate.Beta},
+		AntreaIPAM:          {Default: false, PreRelease: featuregate.Alpha},
+		FlowExporter:        {Default: false, PreRelease: featuregate.Alpha},
+		NetworkPolicyStats:  {Default: true, PreRelease: featuregate.Beta},
+		NodePortLocal:       {Default: true, PreRelease: featuregate.Beta},
+		NodeIPAM:            {Default: false, PreRelease: featuregate.Alpha},
+		PacketCapture:       {Default: false, PreRelease: featuregate.Alpha},
+		ServiceExternalIP:   {Default: false, PreRelease: featuregate.Alpha},
+		L7NetworkPolicy:     {Default: false, PreRelease: featuregate.Alpha},
+		TopologyAwareHints:  {Default: false, PreRelease: featuregate.Alpha},
+		LoadBalancerModeDSR: {Default: false, PreRelease: featuregate.Alpha},
 	}
 
 	// UnsupportedFeaturesOnWindows records the features not supported on
@@ -113,9 +139,13 @@ var (
 	// can have different FeatureSpecs between Linux and Windows, we should
 	// still define a separate defaultAntreaFeatureGates map for Windows.
 	unsupportedFeaturesOnWindows = map[featuregate.Feature]struct{}{
-		NodePortLocal: {},
-		Egress:        {},
-		AntreaIPAM:    {},
+		NodePortLocal:       {},
+		Egress:              {},
+		AntreaIPAM:          {},
+		PacketCapture:       {},
+		ServiceExternalIP:   {},
+		L7NetworkPolicy:     {},
+		LoadBalancerModeDSR: {},
 	}
 )
 
@@ -32,6 +32,8 @@ import (
 	systeminstall "antrea.io/antrea/pkg/apis/system/install"
 	"antrea.io/antrea/pkg/apiserver/handlers/loglevel"
 	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/flowrecords"
+	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/flows"
+	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/recommendations"
 	"antrea.io/antrea/pkg/flowaggregator/apiserver/handlers/recordmetrics"
 	"antrea.io/antrea/pkg/flowaggregator/querier"
 	antreaversion "antrea.io/antrea/pkg/version"
@@ -72,6 +74,8 @@ func (s *flowAggregatorAPIServer) Run(stopCh <-chan struct{}) error {
 func installHandlers(s *genericapiserver.GenericAPIServer, faq querier.FlowAggregatorQuerier) {
 	s.Handler.NonGoRestfulMux.HandleFunc("/flowrecords", flowrecords.HandleFunc(faq))
 	s.Handler.NonGoRestfulMux.HandleFunc("/recordmetrics", recordmetrics.HandleFunc(faq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/flows", flows.HandleFunc(faq))
+	s.Handler.NonGoRestfulMux.HandleFunc("/recommendations", recommendations.HandleFunc(faq))
 	s.Handler.NonGoRestfulMux.HandleFunc("/loglevel", loglevel.HandleFunc())
 }
 
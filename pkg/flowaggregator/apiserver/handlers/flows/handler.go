@@ -0,0 +1,140 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flows
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowquery"
+	"antrea.io/antrea/pkg/flowaggregator/querier"
+)
+
+// Response is the response struct of flows command.
+type Response struct {
+	// GroupBy is the key the result is aggregated by, which decides the columns of
+	// the table output.
+	GroupBy flowquery.GroupBy `json:"groupBy"`
+	flowquery.Result
+}
+
+// HandleFunc returns the function which can handle the /flows API request.
+func HandleFunc(faq querier.FlowAggregatorQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results, err := faq.QueryFlows(query)
+		if err != nil {
+			http.Error(w, "Error when querying flows: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		var resps []Response
+		for _, result := range results {
+			resps = append(resps, Response{GroupBy: query.GroupBy, Result: result})
+		}
+		if err := json.NewEncoder(w).Encode(resps); err != nil {
+			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func parseQuery(r *http.Request) (flowquery.Query, error) {
+	values := r.URL.Query()
+	query := flowquery.Query{
+		GroupBy:   flowquery.GroupBy(values.Get("groupby")),
+		Namespace: values.Get("namespace"),
+		Pod:       values.Get("pod"),
+		Service:   values.Get("service"),
+		Policy:    values.Get("policy"),
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = flowquery.GroupByFlow
+	}
+	var err error
+	if window := values.Get("window"); window != "" {
+		if query.Window, err = time.ParseDuration(window); err != nil {
+			return query, fmt.Errorf("error when parsing window: %v", err)
+		}
+	}
+	if top := values.Get("top"); top != "" {
+		if query.Top, err = strconv.Atoi(top); err != nil {
+			return query, fmt.Errorf("error when parsing top: %v", err)
+		}
+	}
+	if denied := values.Get("denied"); denied != "" {
+		if query.Denied, err = strconv.ParseBool(denied); err != nil {
+			return query, fmt.Errorf("error when parsing denied: %v", err)
+		}
+	}
+	return query, nil
+}
+
+func (r Response) GetTableHeader() []string {
+	switch r.GroupBy {
+	case flowquery.GroupByPodPair:
+		return []string{"SOURCE", "DESTINATION", "BYTES", "PACKETS", "RECORDS"}
+	case flowquery.GroupByNamespace:
+		return []string{"NAMESPACE", "BYTES", "PACKETS", "RECORDS"}
+	case flowquery.GroupByService:
+		return []string{"SERVICE", "BYTES", "PACKETS", "RECORDS"}
+	case flowquery.GroupByPolicy:
+		return []string{"POLICY", "DENIED", "BYTES", "PACKETS", "RECORDS"}
+	default:
+		return []string{"SRC_IP", "DST_IP", "SPORT", "DPORT", "PROTO", "SRC_POD", "DST_POD", "SERVICE", "POLICIES", "DENIED", "BYTES", "PACKETS"}
+	}
+}
+
+func (r Response) GetTableRow(maxColumnLength int) []string {
+	bytes := strconv.FormatUint(r.Bytes, 10)
+	packets := strconv.FormatUint(r.Packets, 10)
+	records := strconv.Itoa(r.Records)
+	switch r.GroupBy {
+	case flowquery.GroupByPodPair:
+		return []string{r.SourcePod, r.DestinationPod, bytes, packets, records}
+	case flowquery.GroupByNamespace:
+		return []string{r.Namespace, bytes, packets, records}
+	case flowquery.GroupByService:
+		return []string{r.Service, bytes, packets, records}
+	case flowquery.GroupByPolicy:
+		return []string{strings.Join(r.Policies, ","), strconv.FormatBool(r.Denied), bytes, packets, records}
+	default:
+		return []string{
+			r.SourceAddress,
+			r.DestinationAddress,
+			strconv.Itoa(int(r.SourcePort)),
+			strconv.Itoa(int(r.DestinationPort)),
+			strconv.Itoa(int(r.Protocol)),
+			r.SourcePod,
+			r.DestinationPod,
+			r.Service,
+			strings.Join(r.Policies, ","),
+			strconv.FormatBool(r.Denied),
+			bytes,
+			packets,
+		}
+	}
+}
+
+// SortRows returns false, as the results are already sorted by decreasing bytes.
+func (r Response) SortRows() bool {
+	return false
+}
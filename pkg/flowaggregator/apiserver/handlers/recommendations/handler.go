@@ -0,0 +1,57 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recommendations
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"antrea.io/antrea/pkg/flowaggregator/policyrecommendation"
+	"antrea.io/antrea/pkg/flowaggregator/querier"
+)
+
+// Response is the response struct of recommend-policies command.
+type Response struct {
+	// Policies are the recommended policies, as YAML documents.
+	Policies string `json:"policies"`
+}
+
+// HandleFunc returns the function which can handle the /recommendations API request.
+func HandleFunc(faq querier.FlowAggregatorQuerier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+		options := policyrecommendation.Options{
+			Type:      policyrecommendation.PolicyType(values.Get("type")),
+			Namespace: values.Get("namespace"),
+			Tier:      values.Get("tier"),
+		}
+		if options.Type == "" {
+			options.Type = policyrecommendation.PolicyTypeK8s
+		}
+		recommendation, err := faq.RecommendPolicies(options)
+		if err != nil {
+			http.Error(w, "Error when recommending policies: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		policies, err := recommendation.YAML()
+		if err != nil {
+			http.Error(w, "Failed to encode policies: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(Response{Policies: string(policies)}); err != nil {
+			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -41,6 +41,13 @@ const (
 	ClientSecretNamespace = "flow-aggregator"
 	// #nosec G101: false positive triggered by variable name which includes "Secret"
 	ClientSecretName = "flow-aggregator-client-tls"
+	// CASecretName is the Secret storing the CA certificate and key shared by the
+	// replicas of the flow aggregator when sharding is enabled.
+	// #nosec G101: false positive triggered by variable name which includes "Secret"
+	CASecretName = "flow-aggregator-ca"
+	// The maximum number of attempts to get or create the shared CA, as it can be
+	// created or updated concurrently by several replicas.
+	maxSharedCAAttempts = 3
 )
 
 var (
@@ -80,7 +87,7 @@ func generateCACertKey() (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
 	return cert, caKey, caPEM.Bytes(), err
 }
 
-func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer bool, flowAggregatorAddress string) ([]byte, []byte, error) {
+func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer bool, flowAggregatorAddress string, podIP string) ([]byte, []byte, error) {
 	var cert *x509.Certificate
 	if isServer {
 		cert = &x509.Certificate{
@@ -104,6 +111,9 @@ func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer b
 			}
 			cert.IPAddresses = flowAggregatorIPs
 		}
+		if ip := net.ParseIP(podIP); ip != nil {
+			cert.IPAddresses = append(cert.IPAddresses, ip)
+		}
 	} else {
 		cert = &x509.Certificate{
 			SerialNumber: big.NewInt(3),
@@ -142,6 +152,84 @@ func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer b
 	return certPEM.Bytes(), certKeyPEM.Bytes(), nil
 }
 
+// getOrCreateSharedCA returns the CA certificate and key stored in the CA Secret, and the
+// PEM encoding of the certificate. The CA is generated and stored by the first replica
+// if the Secret does not exist, or if the CA it stores is invalid or has expired.
+func getOrCreateSharedCA(k8sClient kubernetes.Interface) (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
+	var lastErr error
+	for i := 0; i < maxSharedCAAttempts; i++ {
+		secret, err := k8sClient.CoreV1().Secrets(ClientSecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+		exists := true
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, nil, nil, fmt.Errorf("error getting Secret %s: %v", CASecretName, err)
+			}
+			exists = false
+		} else {
+			caCert, caKey, err := parseCACertKey(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+			if err == nil && time.Now().Before(caCert.NotAfter) {
+				return caCert, caKey, secret.Data[v1.TLSCertKey], nil
+			}
+			klog.InfoS("CA stored in Secret is invalid or has expired, generating a new one", "secret", CASecretName, "err", err)
+		}
+		caCert, caKey, caPEM, err := generateCACertKey()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		caKeyPEM := pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(caKey),
+		})
+		data := map[string][]byte{
+			v1.TLSCertKey:       caPEM,
+			v1.TLSPrivateKeyKey: caKeyPEM,
+		}
+		if exists {
+			secret.Data = data
+			_, err = k8sClient.CoreV1().Secrets(ClientSecretNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		} else {
+			secret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      CASecretName,
+					Namespace: ClientSecretNamespace,
+				},
+				Type: v1.SecretTypeTLS,
+				Data: data,
+			}
+			_, err = k8sClient.CoreV1().Secrets(ClientSecretNamespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+		}
+		if err == nil {
+			return caCert, caKey, caPEM, nil
+		}
+		// Another replica has created or updated the CA first: use its CA.
+		if !errors.IsAlreadyExists(err) && !errors.IsConflict(err) {
+			return nil, nil, nil, fmt.Errorf("error storing CA in Secret %s: %v", CASecretName, err)
+		}
+		lastErr = err
+	}
+	return nil, nil, nil, fmt.Errorf("failed to get or create CA in Secret %s: %v", CASecretName, lastErr)
+}
+
+func parseCACertKey(certPEM, keyPEM []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM data found in CA certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM data found in CA key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
 func syncCAAndClientCert(caCert, clientCert, clientKey []byte, k8sClient kubernetes.Interface) error {
 	klog.Info("Syncing CA certificate, client certificate and client key with ConfigMap")
 	caConfigMap, err := k8sClient.CoreV1().ConfigMaps(CAConfigMapNamespace).Get(context.TODO(), CAConfigMapName, metav1.GetOptions{})
@@ -0,0 +1,63 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowaggregator
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetOrCreateSharedCA(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	// The CA is created by the first replica and reused by the other ones.
+	caCert, caKey, caPEM, err := getOrCreateSharedCA(client)
+	require.NoError(t, err)
+	_, _, caPEM2, err := getOrCreateSharedCA(client)
+	require.NoError(t, err)
+	assert.Equal(t, caPEM, caPEM2)
+
+	// Server certificates signed by the shared CA include the Pod IP.
+	serverCert, _, err := generateCertKey(caCert, caKey, true, "10.96.0.10", "10.10.0.5")
+	require.NoError(t, err)
+	block, _ := pem.Decode(serverCert)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.Len(t, cert.IPAddresses, 2)
+	assert.Equal(t, "10.96.0.10", cert.IPAddresses[0].String())
+	assert.Equal(t, "10.10.0.5", cert.IPAddresses[1].String())
+
+	// An invalid CA is replaced.
+	secret, err := client.CoreV1().Secrets(ClientSecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.Data[v1.TLSCertKey] = []byte("invalid")
+	_, err = client.CoreV1().Secrets(ClientSecretNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, _, caPEM3, err := getOrCreateSharedCA(client)
+	require.NoError(t, err)
+	assert.NotEqual(t, caPEM, caPEM3)
+	secret, err = client.CoreV1().Secrets(ClientSecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, caPEM3, secret.Data[v1.TLSCertKey])
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	// maxBatchSize is the maximum number of records exported in a single batch.
+	maxBatchSize = 1000
+	// minRetryInterval and maxRetryInterval bound the exponential backoff used
+	// when a batch cannot be exported.
+	minRetryInterval = time.Second
+	maxRetryInterval = 30 * time.Second
+)
+
+// BufferOptions configures the in-memory buffer of an exporter and how failed exports
+// are retried.
+type BufferOptions struct {
+	// Size is the maximum number of records which can be buffered by the exporter.
+	// Records are dropped when the buffer is full.
+	Size int
+	// FlushInterval is the maximum amount of time a record can stay in the buffer
+	// before being exported.
+	FlushInterval time.Duration
+	// MaxRetries is the number of times the export of a batch is retried before the
+	// batch is dropped.
+	MaxRetries int
+}
+
+// partialExportError is returned by the export function of a bufferedExporter when
+// only some records of a batch could not be exported, so that only these records are
+// retried.
+type partialExportError struct {
+	failed []*flowrecord.FlowRecord
+	err    error
+}
+
+func (e *partialExportError) Error() string {
+	return e.err.Error()
+}
+
+// bufferedExporter decouples the Flow Aggregator from a sink: records are queued by
+// add and exported in batches by a dedicated goroutine, so that a slow or unavailable
+// sink does not delay the aggregation process or the other sinks.
+type bufferedExporter struct {
+	name        string
+	options     BufferOptions
+	exportBatch func(records []*flowrecord.FlowRecord) error
+	queue       chan *flowrecord.FlowRecord
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+	// numDropped is the number of records dropped because the buffer was full since
+	// the last time it was logged. It must be accessed atomically.
+	numDropped uint64
+}
+
+func newBufferedExporter(name string, options BufferOptions, exportBatch func(records []*flowrecord.FlowRecord) error) *bufferedExporter {
+	return &bufferedExporter{
+		name:        name,
+		options:     options,
+		exportBatch: exportBatch,
+		queue:       make(chan *flowrecord.FlowRecord, options.Size),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+}
+
+func (b *bufferedExporter) start() {
+	go b.run()
+}
+
+// stop exports the records which are still in the buffer, without retrying on failure,
+// and waits for the export goroutine to exit.
+func (b *bufferedExporter) stop() {
+	close(b.stopCh)
+	<-b.doneCh
+}
+
+func (b *bufferedExporter) add(record *flowrecord.FlowRecord) {
+	select {
+	case b.queue <- record:
+	default:
+		atomic.AddUint64(&b.numDropped, 1)
+	}
+}
+
+func (b *bufferedExporter) run() {
+	defer close(b.doneCh)
+	ticker := time.NewTicker(b.options.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]*flowrecord.FlowRecord, 0, maxBatchSize)
+	for {
+		select {
+		case record := <-b.queue:
+			batch = append(batch, record)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if numDropped := atomic.SwapUint64(&b.numDropped, 0); numDropped > 0 {
+				klog.InfoS("Exporter buffer is full, records were dropped", "exporter", b.name, "count", numDropped)
+			}
+			if len(batch) == 0 {
+				continue
+			}
+		case <-b.stopCh:
+			// The queue is only consumed by this goroutine, and add is not expected to
+			// be called once stop has been called.
+			for len(b.queue) > 0 {
+				batch = append(batch, <-b.queue)
+			}
+			if len(batch) > 0 {
+				if err := b.exportBatch(batch); err != nil {
+					klog.ErrorS(err, "Failed to export buffered records on stop", "exporter", b.name, "count", len(batch))
+				}
+			}
+			return
+		}
+		b.exportWithRetry(batch)
+		batch = make([]*flowrecord.FlowRecord, 0, maxBatchSize)
+	}
+}
+
+func (b *bufferedExporter) exportWithRetry(batch []*flowrecord.FlowRecord) {
+	backoff := wait.Backoff{
+		Duration: minRetryInterval,
+		Factor:   2,
+		Steps:    b.options.MaxRetries,
+		Cap:      maxRetryInterval,
+	}
+	for retry := 0; ; retry++ {
+		err := b.exportBatch(batch)
+		if err == nil {
+			return
+		}
+		var partialErr *partialExportError
+		if errors.As(err, &partialErr) {
+			batch = partialErr.failed
+		}
+		if retry >= b.options.MaxRetries {
+			klog.ErrorS(err, "Failed to export records, dropping them", "exporter", b.name, "count", len(batch), "retries", retry)
+			return
+		}
+		delay := backoff.Step()
+		klog.ErrorS(err, "Failed to export records, will retry", "exporter", b.name, "count", len(batch), "retryIn", delay)
+		select {
+		case <-time.After(delay):
+		case <-b.stopCh:
+			klog.InfoS("Exporter is stopping, dropping records which could not be exported", "exporter", b.name, "count", len(batch))
+			return
+		}
+	}
+}
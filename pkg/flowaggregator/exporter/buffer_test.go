@@ -0,0 +1,77 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+func TestBufferedExporterRetry(t *testing.T) {
+	var lock sync.Mutex
+	var attempts int
+	var exported []*flowrecord.FlowRecord
+	exportBatch := func(records []*flowrecord.FlowRecord) error {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("connection refused")
+		}
+		exported = append(exported, records...)
+		return nil
+	}
+	b := newBufferedExporter("test", BufferOptions{Size: 10, FlushInterval: 10 * time.Millisecond, MaxRetries: 1}, exportBatch)
+	b.start()
+	defer b.stop()
+
+	record := &flowrecord.FlowRecord{SourceIP: "10.10.0.1"}
+	b.add(record)
+	err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(exported) == 1, nil
+	})
+	assert.NoError(t, err, "Record was not exported after retry")
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, record, exported[0])
+}
+
+func TestBufferedExporterDropsRecords(t *testing.T) {
+	var exported []*flowrecord.FlowRecord
+	exportBatch := func(records []*flowrecord.FlowRecord) error {
+		exported = append(exported, records...)
+		return nil
+	}
+	// The export goroutine is not started, so the buffer fills up.
+	b := newBufferedExporter("test", BufferOptions{Size: 2, FlushInterval: time.Hour}, exportBatch)
+	for i := 0; i < 3; i++ {
+		b.add(&flowrecord.FlowRecord{SourceTransportPort: uint16(i)})
+	}
+	assert.Equal(t, uint64(1), b.numDropped)
+
+	b.start()
+	b.stop()
+	assert.Len(t, exported, 2)
+}
@@ -0,0 +1,312 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	// Register the "clickhouse" database/sql driver.
+	_ "github.com/ClickHouse/clickhouse-go"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const clickHouseTableName = "flows"
+
+// clickHouseColumns lists the columns of the flows table, in the order in which values
+// are provided by clickHouseValues.
+var clickHouseColumns = []struct {
+	name     string
+	dataType string
+}{
+	{"flowStartSeconds", "DateTime"},
+	{"flowEndSeconds", "DateTime"},
+	{"flowEndReason", "UInt8"},
+	{"sourceIP", "String"},
+	{"destinationIP", "String"},
+	{"sourceTransportPort", "UInt16"},
+	{"destinationTransportPort", "UInt16"},
+	{"protocolIdentifier", "UInt8"},
+	{"packetTotalCount", "UInt64"},
+	{"octetTotalCount", "UInt64"},
+	{"packetDeltaCount", "UInt64"},
+	{"octetDeltaCount", "UInt64"},
+	{"reversePacketTotalCount", "UInt64"},
+	{"reverseOctetTotalCount", "UInt64"},
+	{"reversePacketDeltaCount", "UInt64"},
+	{"reverseOctetDeltaCount", "UInt64"},
+	{"sourcePodName", "String"},
+	{"sourcePodNamespace", "String"},
+	{"sourceNodeName", "String"},
+	{"destinationPodName", "String"},
+	{"destinationPodNamespace", "String"},
+	{"destinationNodeName", "String"},
+	{"destinationClusterIP", "String"},
+	{"destinationServicePort", "UInt16"},
+	{"destinationServicePortName", "String"},
+	{"ingressNetworkPolicyName", "String"},
+	{"ingressNetworkPolicyNamespace", "String"},
+	{"ingressNetworkPolicyType", "UInt8"},
+	{"ingressNetworkPolicyRuleName", "String"},
+	{"ingressNetworkPolicyRuleAction", "UInt8"},
+	{"egressNetworkPolicyName", "String"},
+	{"egressNetworkPolicyNamespace", "String"},
+	{"egressNetworkPolicyType", "UInt8"},
+	{"egressNetworkPolicyRuleName", "String"},
+	{"egressNetworkPolicyRuleAction", "UInt8"},
+	{"tcpState", "String"},
+	{"flowType", "UInt8"},
+	{"sourcePodLabels", "String"},
+	{"destinationPodLabels", "String"},
+	{"destinationServiceType", "String"},
+	{"egressName", "String"},
+	{"egressIP", "String"},
+	{"tcpHandshakeRTT", "Int32"},
+	{"tcpHandshakeRetransmissionCount", "UInt16"},
+	{"tcpResetCount", "UInt16"},
+}
+
+type ClickHouseInput struct {
+	// Username and Password are used to authenticate with the ClickHouse server.
+	Username string
+	Password string
+	// Database is the name of the database the flows table is created in.
+	Database string
+	// DatabaseURL is the URL of the ClickHouse server, e.g. "tcp://localhost:9000".
+	DatabaseURL string
+	// Debug enables the debug logs of the ClickHouse client.
+	Debug bool
+	// Compress enables lz4 compression for the data sent to the ClickHouse server.
+	Compress bool
+	Buffer   BufferOptions
+}
+
+// ClickHouseExporter inserts the aggregated records into a ClickHouse table. Records
+// are buffered and each batch is inserted in a single transaction.
+type ClickHouseExporter struct {
+	input        ClickHouseInput
+	db           *sql.DB
+	tableCreated bool
+	buffer       *bufferedExporter
+}
+
+func NewClickHouseExporter(input ClickHouseInput) (*ClickHouseExporter, error) {
+	dsn, err := input.getDataSourceName()
+	if err != nil {
+		return nil, err
+	}
+	// sql.Open only validates its arguments, the connection to the server is
+	// established when the first batch of records is inserted.
+	db, err := sql.Open("clickhouse", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error when opening ClickHouse database: %v", err)
+	}
+	e := &ClickHouseExporter{
+		input: input,
+		db:    db,
+	}
+	e.buffer = newBufferedExporter("clickhouse", input.Buffer, e.insertRecords)
+	return e, nil
+}
+
+func (i *ClickHouseInput) getDataSourceName() (string, error) {
+	u, err := url.Parse(i.DatabaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ClickHouse database URL %s: %v", i.DatabaseURL, err)
+	}
+	query := u.Query()
+	query.Set("username", i.Username)
+	query.Set("password", i.Password)
+	query.Set("database", i.Database)
+	query.Set("debug", strconv.FormatBool(i.Debug))
+	query.Set("compress", strconv.FormatBool(i.Compress))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (e *ClickHouseExporter) Start() {
+	e.buffer.start()
+}
+
+func (e *ClickHouseExporter) Stop() {
+	e.buffer.stop()
+	if err := e.db.Close(); err != nil {
+		klog.ErrorS(err, "Error when closing ClickHouse database")
+	}
+}
+
+func (e *ClickHouseExporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
+	e.buffer.add(flowrecord.GetFlowRecord(record))
+	return nil
+}
+
+func (e *ClickHouseExporter) insertRecords(records []*flowrecord.FlowRecord) error {
+	if !e.tableCreated {
+		if err := e.prepareTable(); err != nil {
+			return err
+		}
+		e.tableCreated = true
+	}
+	tx, err := e.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error when beginning ClickHouse transaction: %v", err)
+	}
+	stmt, err := tx.Prepare(clickHouseInsertQuery())
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error when preparing ClickHouse insert statement: %v", err)
+	}
+	defer stmt.Close()
+	for _, record := range records {
+		if _, err := stmt.Exec(clickHouseValues(record)...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error when inserting record into ClickHouse: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error when committing ClickHouse transaction: %v", err)
+	}
+	klog.V(4).InfoS("Inserted records into ClickHouse", "count", len(records))
+	return nil
+}
+
+// prepareTable creates the flows table if it doesn't exist, and adds the columns which
+// are missing from a table created by an older version of the Flow Aggregator.
+func (e *ClickHouseExporter) prepareTable() error {
+	if _, err := e.db.Exec(clickHouseCreateTableQuery()); err != nil {
+		return fmt.Errorf("error when creating ClickHouse table: %v", err)
+	}
+	rows, err := e.db.Query("SELECT name, type FROM system.columns WHERE database = currentDatabase() AND table = ?", clickHouseTableName)
+	if err != nil {
+		return fmt.Errorf("error when getting the columns of ClickHouse table: %v", err)
+	}
+	defer rows.Close()
+	existingColumns := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return fmt.Errorf("error when getting the columns of ClickHouse table: %v", err)
+		}
+		existingColumns[name] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error when getting the columns of ClickHouse table: %v", err)
+	}
+	queries, err := clickHouseMigrationQueries(existingColumns)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		if _, err := e.db.Exec(query); err != nil {
+			return fmt.Errorf("error when migrating ClickHouse table: %v", err)
+		}
+		klog.InfoS("Migrated ClickHouse table", "query", query)
+	}
+	return nil
+}
+
+// clickHouseMigrationQueries returns the queries adding the columns which are missing
+// from the existing columns of the flows table. An existing column with a different
+// data type cannot be migrated automatically.
+func clickHouseMigrationQueries(existingColumns map[string]string) ([]string, error) {
+	var queries []string
+	for _, column := range clickHouseColumns {
+		dataType, exist := existingColumns[column.name]
+		if !exist {
+			queries = append(queries, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", clickHouseTableName, column.name, column.dataType))
+			continue
+		}
+		if dataType != column.dataType {
+			return nil, fmt.Errorf("column %s of ClickHouse table %s has type %s instead of %s, the table must be migrated manually",
+				column.name, clickHouseTableName, dataType, column.dataType)
+		}
+	}
+	return queries, nil
+}
+
+func clickHouseCreateTableQuery() string {
+	columns := make([]string, 0, len(clickHouseColumns)+1)
+	columns = append(columns, "timeInserted DateTime DEFAULT now()")
+	for _, column := range clickHouseColumns {
+		columns = append(columns, column.name+" "+column.dataType)
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s) engine=MergeTree ORDER BY (timeInserted, flowEndSeconds)",
+		clickHouseTableName, strings.Join(columns, ", "))
+}
+
+func clickHouseInsertQuery() string {
+	names := make([]string, len(clickHouseColumns))
+	for i, column := range clickHouseColumns {
+		names[i] = column.name
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(clickHouseColumns)), ", ")
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", clickHouseTableName, strings.Join(names, ", "), placeholders)
+}
+
+func clickHouseValues(r *flowrecord.FlowRecord) []interface{} {
+	return []interface{}{
+		r.FlowStartSeconds,
+		r.FlowEndSeconds,
+		r.FlowEndReason,
+		r.SourceIP,
+		r.DestinationIP,
+		r.SourceTransportPort,
+		r.DestinationTransportPort,
+		r.ProtocolIdentifier,
+		r.PacketTotalCount,
+		r.OctetTotalCount,
+		r.PacketDeltaCount,
+		r.OctetDeltaCount,
+		r.ReversePacketTotalCount,
+		r.ReverseOctetTotalCount,
+		r.ReversePacketDeltaCount,
+		r.ReverseOctetDeltaCount,
+		r.SourcePodName,
+		r.SourcePodNamespace,
+		r.SourceNodeName,
+		r.DestinationPodName,
+		r.DestinationPodNamespace,
+		r.DestinationNodeName,
+		r.DestinationClusterIP,
+		r.DestinationServicePort,
+		r.DestinationServicePortName,
+		r.IngressNetworkPolicyName,
+		r.IngressNetworkPolicyNamespace,
+		r.IngressNetworkPolicyType,
+		r.IngressNetworkPolicyRuleName,
+		r.IngressNetworkPolicyRuleAction,
+		r.EgressNetworkPolicyName,
+		r.EgressNetworkPolicyNamespace,
+		r.EgressNetworkPolicyType,
+		r.EgressNetworkPolicyRuleName,
+		r.EgressNetworkPolicyRuleAction,
+		r.TCPState,
+		r.FlowType,
+		r.SourcePodLabels,
+		r.DestinationPodLabels,
+		r.DestinationServiceType,
+		r.EgressName,
+		r.EgressIP,
+		r.TCPHandshakeRTT,
+		r.TCPHandshakeRetransmissionCount,
+		r.TCPResetCount,
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+func TestClickHouseDataSourceName(t *testing.T) {
+	input := ClickHouseInput{
+		Username:    "clickhouse_operator",
+		Password:    "p@ss word",
+		Database:    "default",
+		DatabaseURL: "tcp://clickhouse-clickhouse.flow-visibility.svc:9000",
+		Debug:       true,
+		Compress:    false,
+	}
+	dsn, err := input.getDataSourceName()
+	require.NoError(t, err)
+	assert.Equal(t, "tcp://clickhouse-clickhouse.flow-visibility.svc:9000?compress=false&database=default&debug=true&password=p%40ss+word&username=clickhouse_operator", dsn)
+
+	input.DatabaseURL = "tcp://%zz"
+	_, err = input.getDataSourceName()
+	assert.Error(t, err)
+}
+
+func TestClickHouseInsertQuery(t *testing.T) {
+	query := clickHouseInsertQuery()
+	assert.True(t, strings.HasPrefix(query, "INSERT INTO flows (flowStartSeconds, flowEndSeconds, "))
+	// Every column must have a placeholder and a value.
+	assert.Equal(t, len(clickHouseColumns), strings.Count(query, "?"))
+	assert.Len(t, clickHouseValues(flowrecord.GetFlowRecord(createDataRecord(t))), len(clickHouseColumns))
+	assert.True(t, strings.HasPrefix(clickHouseCreateTableQuery(), "CREATE TABLE IF NOT EXISTS flows (timeInserted DateTime DEFAULT now(), flowStartSeconds DateTime, "))
+}
+
+func TestClickHouseMigrationQueries(t *testing.T) {
+	existingColumns := make(map[string]string)
+	for _, column := range clickHouseColumns {
+		existingColumns[column.name] = column.dataType
+	}
+	queries, err := clickHouseMigrationQueries(existingColumns)
+	require.NoError(t, err)
+	assert.Empty(t, queries)
+
+	// A table created by an older version has no TCP metrics columns.
+	delete(existingColumns, "tcpHandshakeRTT")
+	delete(existingColumns, "tcpResetCount")
+	queries, err = clickHouseMigrationQueries(existingColumns)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE flows ADD COLUMN IF NOT EXISTS tcpHandshakeRTT Int32",
+		"ALTER TABLE flows ADD COLUMN IF NOT EXISTS tcpResetCount UInt16",
+	}, queries)
+
+	existingColumns["egressIP"] = "IPv4"
+	_, err = clickHouseMigrationQueries(existingColumns)
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	FileFormatCSV  = "CSV"
+	FileFormatJSON = "JSON"
+)
+
+type FileInput struct {
+	// Path of the file the records are written to. Rotated files are created in the
+	// same directory.
+	Path string
+	// Format is FileFormatCSV or FileFormatJSON.
+	Format string
+	// MaxSize is the maximum size in megabytes of the file before it gets rotated.
+	MaxSize int
+	// MaxBackups is the maximum number of rotated files to retain.
+	MaxBackups int
+	// MaxAge is the maximum number of days to retain rotated files.
+	MaxAge int
+	// Compress determines whether rotated files are compressed using gzip.
+	Compress bool
+	Buffer   BufferOptions
+}
+
+// FileExporter writes the aggregated records to a local file, one record per line,
+// either as CSV (with the columns in the order of the FlowRecord fields and no
+// header) or as JSON objects. The file is rotated when it reaches its maximum size.
+type FileExporter struct {
+	input  FileInput
+	writer io.WriteCloser
+	buffer *bufferedExporter
+}
+
+func NewFileExporter(input FileInput) (*FileExporter, error) {
+	if input.Format != FileFormatCSV && input.Format != FileFormatJSON {
+		return nil, fmt.Errorf("file format %s is not supported", input.Format)
+	}
+	e := &FileExporter{
+		input: input,
+		writer: &lumberjack.Logger{
+			Filename:   input.Path,
+			MaxSize:    input.MaxSize,
+			MaxBackups: input.MaxBackups,
+			MaxAge:     input.MaxAge,
+			Compress:   input.Compress,
+		},
+	}
+	e.buffer = newBufferedExporter("file", input.Buffer, e.writeRecords)
+	return e, nil
+}
+
+func (e *FileExporter) Start() {
+	e.buffer.start()
+}
+
+func (e *FileExporter) Stop() {
+	e.buffer.stop()
+	if err := e.writer.Close(); err != nil {
+		klog.ErrorS(err, "Error when closing flow records file", "path", e.input.Path)
+	}
+}
+
+func (e *FileExporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
+	e.buffer.add(flowrecord.GetFlowRecord(record))
+	return nil
+}
+
+func (e *FileExporter) writeRecords(records []*flowrecord.FlowRecord) error {
+	// Records are written with a single call to the underlying writer, so that a batch
+	// is never split across rotated files.
+	var w bytes.Buffer
+	if e.input.Format == FileFormatJSON {
+		encoder := json.NewEncoder(&w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+	} else {
+		csvWriter := csv.NewWriter(&w)
+		for _, record := range records {
+			if err := csvWriter.Write(csvFields(record)); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+	}
+	_, err := e.writer.Write(w.Bytes())
+	return err
+}
+
+func csvFields(r *flowrecord.FlowRecord) []string {
+	formatUint := func(v uint64) string {
+		return strconv.FormatUint(v, 10)
+	}
+	return []string{
+		r.FlowStartSeconds.UTC().Format(time.RFC3339),
+		r.FlowEndSeconds.UTC().Format(time.RFC3339),
+		formatUint(uint64(r.FlowEndReason)),
+		r.SourceIP,
+		r.DestinationIP,
+		formatUint(uint64(r.SourceTransportPort)),
+		formatUint(uint64(r.DestinationTransportPort)),
+		formatUint(uint64(r.ProtocolIdentifier)),
+		formatUint(r.PacketTotalCount),
+		formatUint(r.OctetTotalCount),
+		formatUint(r.PacketDeltaCount),
+		formatUint(r.OctetDeltaCount),
+		formatUint(r.ReversePacketTotalCount),
+		formatUint(r.ReverseOctetTotalCount),
+		formatUint(r.ReversePacketDeltaCount),
+		formatUint(r.ReverseOctetDeltaCount),
+		r.SourcePodName,
+		r.SourcePodNamespace,
+		r.SourceNodeName,
+		r.DestinationPodName,
+		r.DestinationPodNamespace,
+		r.DestinationNodeName,
+		r.DestinationClusterIP,
+		formatUint(uint64(r.DestinationServicePort)),
+		r.DestinationServicePortName,
+		r.IngressNetworkPolicyName,
+		r.IngressNetworkPolicyNamespace,
+		formatUint(uint64(r.IngressNetworkPolicyType)),
+		r.IngressNetworkPolicyRuleName,
+		formatUint(uint64(r.IngressNetworkPolicyRuleAction)),
+		r.EgressNetworkPolicyName,
+		r.EgressNetworkPolicyNamespace,
+		formatUint(uint64(r.EgressNetworkPolicyType)),
+		r.EgressNetworkPolicyRuleName,
+		formatUint(uint64(r.EgressNetworkPolicyRuleAction)),
+		r.TCPState,
+		formatUint(uint64(r.FlowType)),
+		r.SourcePodLabels,
+		r.DestinationPodLabels,
+		r.DestinationServiceType,
+		r.EgressName,
+		r.EgressIP,
+		strconv.FormatInt(int64(r.TCPHandshakeRTT), 10),
+		formatUint(uint64(r.TCPHandshakeRetransmissionCount)),
+		formatUint(uint64(r.TCPResetCount)),
+	}
+}
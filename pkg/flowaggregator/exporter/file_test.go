@@ -0,0 +1,110 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+)
+
+func createDataRecord(t *testing.T) ipfixentities.Record {
+	newElement := func(name string, enterpriseID uint32) *ipfixentities.InfoElement {
+		element, err := ipfixregistry.GetInfoElement(name, enterpriseID)
+		require.NoError(t, err)
+		return element
+	}
+	elements := []ipfixentities.InfoElementWithValue{
+		ipfixentities.NewDateTimeSecondsInfoElement(newElement("flowStartSeconds", ipfixregistry.IANAEnterpriseID), 1637706961),
+		ipfixentities.NewDateTimeSecondsInfoElement(newElement("flowEndSeconds", ipfixregistry.IANAEnterpriseID), 1637706973),
+		ipfixentities.NewIPAddressInfoElement(newElement("sourceIPv4Address", ipfixregistry.IANAEnterpriseID), net.ParseIP("10.10.0.79")),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationIPv4Address", ipfixregistry.IANAEnterpriseID), net.ParseIP("10.10.0.80")),
+		ipfixentities.NewUnsigned16InfoElement(newElement("sourceTransportPort", ipfixregistry.IANAEnterpriseID), 44752),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationTransportPort", ipfixregistry.IANAEnterpriseID), 5201),
+		ipfixentities.NewUnsigned8InfoElement(newElement("protocolIdentifier", ipfixregistry.IANAEnterpriseID), 6),
+		ipfixentities.NewUnsigned64InfoElement(newElement("octetDeltaCount", ipfixregistry.IANAEnterpriseID), 1000),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodName", ipfixregistry.AntreaEnterpriseID), "perftest-a"),
+		ipfixentities.NewStringInfoElement(newElement("tcpState", ipfixregistry.AntreaEnterpriseID), "TIME_WAIT"),
+	}
+	record := ipfixentities.NewDataRecord(testTemplateIDv4, len(elements), 0, true)
+	for _, element := range elements {
+		require.NoError(t, record.AddInfoElement(element))
+	}
+	return record
+}
+
+func TestFileExporter(t *testing.T) {
+	testcases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   FileFormatCSV,
+			expected: "2021-11-23T22:36:01Z,2021-11-23T22:36:13Z,0,10.10.0.79,10.10.0.80,44752,5201,6,0,0,0,1000,0,0,0,0,perftest-a,,,,,,,0,,,,0,,0,,,0,,0,TIME_WAIT,0,,,,,,0,0,0\n",
+		},
+		{
+			format: FileFormatJSON,
+			expected: `{"flowStartSeconds":"2021-11-23T22:36:01Z","flowEndSeconds":"2021-11-23T22:36:13Z","flowEndReason":0,` +
+				`"sourceIP":"10.10.0.79","destinationIP":"10.10.0.80","sourceTransportPort":44752,"destinationTransportPort":5201,` +
+				`"protocolIdentifier":6,"packetTotalCount":0,"octetTotalCount":0,"packetDeltaCount":0,"octetDeltaCount":1000,` +
+				`"reversePacketTotalCount":0,"reverseOctetTotalCount":0,"reversePacketDeltaCount":0,"reverseOctetDeltaCount":0,` +
+				`"sourcePodName":"perftest-a","sourcePodNamespace":"","sourceNodeName":"","destinationPodName":"",` +
+				`"destinationPodNamespace":"","destinationNodeName":"","destinationClusterIP":"","destinationServicePort":0,` +
+				`"destinationServicePortName":"","ingressNetworkPolicyName":"","ingressNetworkPolicyNamespace":"",` +
+				`"ingressNetworkPolicyType":0,"ingressNetworkPolicyRuleName":"","ingressNetworkPolicyRuleAction":0,` +
+				`"egressNetworkPolicyName":"","egressNetworkPolicyNamespace":"","egressNetworkPolicyType":0,` +
+				`"egressNetworkPolicyRuleName":"","egressNetworkPolicyRuleAction":0,"tcpState":"TIME_WAIT","flowType":0,` +
+				`"sourcePodLabels":"","destinationPodLabels":"","destinationServiceType":"","egressName":"",` +
+				`"egressIP":"","tcpHandshakeRTT":0,"tcpHandshakeRetransmissionCount":0,"tcpResetCount":0}` + "\n",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "flows.log")
+			e, err := NewFileExporter(FileInput{
+				Path:    path,
+				Format:  tc.format,
+				MaxSize: 1,
+				Buffer: BufferOptions{
+					Size:          10,
+					FlushInterval: time.Hour,
+				},
+			})
+			require.NoError(t, err)
+			e.Start()
+			record := createDataRecord(t)
+			require.NoError(t, e.AddRecord(record, false))
+			require.NoError(t, e.AddRecord(record, false))
+			// Buffered records are written when the exporter is stopped.
+			e.Stop()
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected+tc.expected, string(data))
+		})
+	}
+}
+
+func TestNewFileExporterInvalidFormat(t *testing.T) {
+	_, err := NewFileExporter(FileInput{Path: filepath.Join(t.TempDir(), "flows.log"), Format: "XML"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// Interface is implemented by every sink of the Flow Aggregator. All enabled exporters
+// receive the same aggregated flow records.
+type Interface interface {
+	// Start starts the exporter. It must be called before AddRecord.
+	Start()
+	// Stop flushes the records buffered by the exporter (if any) and releases its
+	// resources.
+	Stop()
+	// AddRecord exports the provided aggregated record, or buffers it to be exported
+	// later. The record is owned by the aggregation process and may be modified once
+	// AddRecord returns, so implementations must not retain a reference to it.
+	AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error
+}
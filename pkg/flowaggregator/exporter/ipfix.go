@@ -0,0 +1,358 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/exporter"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/infoelements"
+	"antrea.io/antrea/pkg/ipfix"
+)
+
+// IPFIXExporter sends the aggregated records to an external IPFIX collector, either as
+// IPFIX sets or as JSON records. Records are sent synchronously and are not buffered:
+// while the collector is unreachable, records are dropped and the connection is
+// re-established at most once per retry interval.
+type IPFIXExporter struct {
+	externalFlowCollectorAddr  string
+	externalFlowCollectorProto string
+	exportingProcess           ipfix.IPFIXExportingProcess
+	sendJSONRecord             bool
+	includePodLabels           bool
+	includeEgressInfo          bool
+	includeServiceType         bool
+	includeTCPMetrics          bool
+	observationDomainID        uint32
+	templateIDv4               uint16
+	templateIDv6               uint16
+	registry                   ipfix.IPFIXRegistry
+	set                        ipfixentities.Set
+	retryInterval              time.Duration
+	nextInitTime               time.Time
+	// Elements of the templates sent to the collector, with empty values.
+	templateElementsV4 []ipfixentities.InfoElementWithValue
+	templateElementsV6 []ipfixentities.InfoElementWithValue
+}
+
+func NewIPFIXExporter(
+	externalFlowCollectorAddr string,
+	externalFlowCollectorProto string,
+	sendJSONRecord bool,
+	includePodLabels bool,
+	includeEgressInfo bool,
+	includeServiceType bool,
+	includeTCPMetrics bool,
+	observationDomainID uint32,
+	retryInterval time.Duration,
+	registry ipfix.IPFIXRegistry,
+) *IPFIXExporter {
+	return &IPFIXExporter{
+		externalFlowCollectorAddr:  externalFlowCollectorAddr,
+		externalFlowCollectorProto: externalFlowCollectorProto,
+		sendJSONRecord:             sendJSONRecord,
+		includePodLabels:           includePodLabels,
+		includeEgressInfo:          includeEgressInfo,
+		includeServiceType:         includeServiceType,
+		includeTCPMetrics:          includeTCPMetrics,
+		observationDomainID:        observationDomainID,
+		retryInterval:              retryInterval,
+		registry:                   registry,
+		set:                        ipfixentities.NewSet(false),
+	}
+}
+
+// Start is a no-op: the connection to the collector is established lazily by AddRecord,
+// so that the Flow Aggregator can start even if the collector is not reachable yet.
+func (e *IPFIXExporter) Start() {}
+
+func (e *IPFIXExporter) Stop() {
+	if e.exportingProcess != nil {
+		e.exportingProcess.CloseConnToCollector()
+		e.exportingProcess = nil
+	}
+}
+
+func (e *IPFIXExporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
+	if e.exportingProcess == nil {
+		if time.Now().Before(e.nextInitTime) {
+			return fmt.Errorf("IPFIX exporter is not connected to collector %s, dropping record", e.externalFlowCollectorAddr)
+		}
+		if err := e.initExportingProcess(); err != nil {
+			e.nextInitTime = time.Now().Add(e.retryInterval)
+			return fmt.Errorf("error when initializing IPFIX exporting process, will retry in %s: %v", e.retryInterval, err)
+		}
+	}
+	if err := e.sendRecord(record, isRecordIPv6); err != nil {
+		// If there is an error when sending the record because of intermittent
+		// connectivity, we reset the connection to the IPFIX collector and try to
+		// reinitialize it when the next record is exported.
+		e.exportingProcess.CloseConnToCollector()
+		e.exportingProcess = nil
+		return err
+	}
+	return nil
+}
+
+func (e *IPFIXExporter) sendRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
+	templateID := e.templateIDv4
+	if isRecordIPv6 {
+		templateID = e.templateIDv6
+	}
+	// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
+	e.set.ResetSet()
+	if err := e.set.PrepareSet(ipfixentities.Data, templateID); err != nil {
+		return err
+	}
+	if err := e.set.AddRecord(e.getTemplateRecordElements(record, isRecordIPv6), templateID); err != nil {
+		return err
+	}
+	sentBytes, err := e.exportingProcess.SendSet(e.set)
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("Data set sent successfully: %d Bytes sent", sentBytes)
+	return nil
+}
+
+// getTemplateRecordElements returns the elements of the record matching the template sent
+// to the collector. Agents may export optional elements which are not part of the
+// template, or may not export elements which are, so the elements are looked up by name
+// when the record does not match the template. Missing elements are exported with empty
+// values.
+func (e *IPFIXExporter) getTemplateRecordElements(record ipfixentities.Record, isRecordIPv6 bool) []ipfixentities.InfoElementWithValue {
+	templateElements := e.templateElementsV4
+	if isRecordIPv6 {
+		templateElements = e.templateElementsV6
+	}
+	recordElements := record.GetOrderedElementList()
+	if elementsMatch(recordElements, templateElements) {
+		return recordElements
+	}
+	recordElementsByName := make(map[string]ipfixentities.InfoElementWithValue, len(recordElements))
+	for _, ie := range recordElements {
+		if ie != nil {
+			recordElementsByName[ie.GetName()] = ie
+		}
+	}
+	elements := make([]ipfixentities.InfoElementWithValue, len(templateElements))
+	for i, templateIE := range templateElements {
+		if ie, exist := recordElementsByName[templateIE.GetName()]; exist {
+			elements[i] = ie
+		} else {
+			elements[i] = templateIE
+		}
+	}
+	return elements
+}
+
+func elementsMatch(recordElements, templateElements []ipfixentities.InfoElementWithValue) bool {
+	if len(recordElements) != len(templateElements) {
+		return false
+	}
+	for i := range recordElements {
+		if recordElements[i] == nil || recordElements[i].GetName() != templateElements[i].GetName() {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *IPFIXExporter) initExportingProcess() error {
+	// TODO: This code can be further simplified by changing the go-ipfix API to accept
+	// externalFlowCollectorAddr and externalFlowCollectorProto instead of net.Addr input.
+	var expInput exporter.ExporterInput
+	if e.externalFlowCollectorProto == "tcp" {
+		// TCP transport does not need any tempRefTimeout, so sending 0.
+		expInput = exporter.ExporterInput{
+			CollectorAddress:    e.externalFlowCollectorAddr,
+			CollectorProtocol:   e.externalFlowCollectorProto,
+			ObservationDomainID: e.observationDomainID,
+			TempRefTimeout:      0,
+			IsEncrypted:         false,
+			SendJSONRecord:      e.sendJSONRecord,
+		}
+	} else {
+		// For UDP transport, hardcoding tempRefTimeout value as 1800s. So we will send out template every 30 minutes.
+		expInput = exporter.ExporterInput{
+			CollectorAddress:    e.externalFlowCollectorAddr,
+			CollectorProtocol:   e.externalFlowCollectorProto,
+			ObservationDomainID: e.observationDomainID,
+			TempRefTimeout:      1800,
+			IsEncrypted:         false,
+			SendJSONRecord:      e.sendJSONRecord,
+		}
+	}
+	ep, err := ipfix.NewIPFIXExportingProcess(expInput)
+	if err != nil {
+		return fmt.Errorf("got error when initializing IPFIX exporting process: %v", err)
+	}
+	e.exportingProcess = ep
+	// Currently, we send two templates for IPv4 and IPv6 regardless of the IP families supported by cluster
+	if err = e.createAndSendTemplate(false); err != nil {
+		return err
+	}
+	if err = e.createAndSendTemplate(true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *IPFIXExporter) createAndSendTemplate(isRecordIPv6 bool) error {
+	templateID := e.exportingProcess.NewTemplateID()
+	recordIPFamily := "IPv4"
+	if isRecordIPv6 {
+		recordIPFamily = "IPv6"
+	}
+	if isRecordIPv6 {
+		e.templateIDv6 = templateID
+	} else {
+		e.templateIDv4 = templateID
+	}
+	bytesSent, err := e.sendTemplateSet(isRecordIPv6)
+	if err != nil {
+		e.exportingProcess.CloseConnToCollector()
+		e.exportingProcess = nil
+		e.set.ResetSet()
+		return fmt.Errorf("sending %s template set failed, err: %v", recordIPFamily, err)
+	}
+	klog.V(2).InfoS("Exporting process initialized", "bytesSent", bytesSent, "templateSetIPFamily", recordIPFamily)
+	return nil
+}
+
+func (e *IPFIXExporter) sendTemplateSet(isIPv6 bool) (int, error) {
+	elements := make([]ipfixentities.InfoElementWithValue, 0)
+	ianaInfoElements := infoelements.IANAInfoElementsIPv4
+	antreaInfoElements := infoelements.AntreaInfoElementsIPv4
+	templateID := e.templateIDv4
+	if isIPv6 {
+		ianaInfoElements = infoelements.IANAInfoElementsIPv6
+		antreaInfoElements = infoelements.AntreaInfoElementsIPv6
+		templateID = e.templateIDv6
+	}
+	for _, ie := range ianaInfoElements {
+		element, err := e.registry.GetInfoElement(ie, ipfixregistry.IANAEnterpriseID)
+		if err != nil {
+			return 0, fmt.Errorf("%s not present. returned error: %v", ie, err)
+		}
+		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, err
+		}
+		elements = append(elements, ie)
+	}
+	for _, ie := range infoelements.IANAReverseInfoElements {
+		element, err := e.registry.GetInfoElement(ie, ipfixregistry.IANAReversedEnterpriseID)
+		if err != nil {
+			return 0, fmt.Errorf("%s not present. returned error: %v", ie, err)
+		}
+		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, err
+		}
+		elements = append(elements, ie)
+	}
+	for _, ie := range antreaInfoElements {
+		element, err := e.registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID)
+		if err != nil {
+			return 0, fmt.Errorf("%s not present. returned error: %v", ie, err)
+		}
+		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, err
+		}
+		elements = append(elements, ie)
+	}
+	// The order of source and destination stats elements needs to match the order specified in
+	// addFieldsForStatsAggregation method in go-ipfix aggregation process.
+	for i := range infoelements.StatsElementList {
+		// Add Antrea source stats fields
+		ieName := infoelements.AntreaSourceStatsElementList[i]
+		element, err := e.registry.GetInfoElement(ieName, ipfixregistry.AntreaEnterpriseID)
+		if err != nil {
+			return 0, fmt.Errorf("%s not present. returned error: %v", ieName, err)
+		}
+		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, err
+		}
+		elements = append(elements, ie)
+		// Add Antrea destination stats fields
+		ieName = infoelements.AntreaDestinationStatsElementList[i]
+		element, err = e.registry.GetInfoElement(ieName, ipfixregistry.AntreaEnterpriseID)
+		if err != nil {
+			return 0, fmt.Errorf("%s not present. returned error: %v", ieName, err)
+		}
+		ie, err = ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, err
+		}
+		elements = append(elements, ie)
+	}
+	if e.includePodLabels {
+		for _, ie := range infoelements.AntreaLabelsElementList {
+			element, err := e.registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID)
+			if err != nil {
+				return 0, fmt.Errorf("error when getting InformationElement %s from registry: %v", ie, err)
+			}
+			ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+			if err != nil {
+				return 0, err
+			}
+			elements = append(elements, ie)
+		}
+	}
+	var optionalElements []string
+	if e.includeServiceType {
+		optionalElements = append(optionalElements, infoelements.AntreaServiceTypeElementList...)
+	}
+	if e.includeEgressInfo {
+		optionalElements = append(optionalElements, infoelements.AntreaEgressElementList...)
+	}
+	if e.includeTCPMetrics {
+		optionalElements = append(optionalElements, infoelements.AntreaTCPMetricsElementList...)
+	}
+	for _, ie := range optionalElements {
+		element, err := e.registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID)
+		if err != nil {
+			return 0, fmt.Errorf("error when getting InformationElement %s from registry: %v", ie, err)
+		}
+		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+		if err != nil {
+			return 0, err
+		}
+		elements = append(elements, ie)
+	}
+	if isIPv6 {
+		e.templateElementsV6 = elements
+	} else {
+		e.templateElementsV4 = elements
+	}
+	e.set.ResetSet()
+	if err := e.set.PrepareSet(ipfixentities.Template, templateID); err != nil {
+		return 0, err
+	}
+	err := e.set.AddRecord(elements, templateID)
+	if err != nil {
+		return 0, fmt.Errorf("error when adding record to set, error: %v", err)
+	}
+	bytesSent, err := e.exportingProcess.SendSet(e.set)
+	return bytesSent, err
+}
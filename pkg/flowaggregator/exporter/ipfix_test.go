@@ -0,0 +1,265 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+
+	"antrea.io/antrea/pkg/flowaggregator/infoelements"
+	ipfixtest "antrea.io/antrea/pkg/ipfix/testing"
+)
+
+const (
+	testTemplateIDv4        = uint16(256)
+	testTemplateIDv6        = uint16(257)
+	testObservationDomainID = 0xabcd
+	testRetryInterval       = 60 * time.Second
+)
+
+func init() {
+	ipfixregistry.LoadRegistry()
+}
+
+func TestIPFIXExporter_AddRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	mockDataSet := ipfixentitiestesting.NewMockSet(ctrl)
+	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+
+	newIPFIXExporter := func() *IPFIXExporter {
+		return &IPFIXExporter{
+			externalFlowCollectorAddr:  "",
+			externalFlowCollectorProto: "",
+			exportingProcess:           mockIPFIXExpProc,
+			templateIDv4:               testTemplateIDv4,
+			templateIDv6:               testTemplateIDv6,
+			set:                        mockDataSet,
+			observationDomainID:        testObservationDomainID,
+			retryInterval:              testRetryInterval,
+		}
+	}
+
+	for _, isIPv6 := range []bool{false, true} {
+		e := newIPFIXExporter()
+		templateID := e.templateIDv4
+		if isIPv6 {
+			templateID = e.templateIDv6
+		}
+		mockDataSet.EXPECT().ResetSet()
+		mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, templateID).Return(nil)
+		elementList := make([]ipfixentities.InfoElementWithValue, 0)
+		mockRecord.EXPECT().GetOrderedElementList().Return(elementList)
+		mockDataSet.EXPECT().AddRecord(elementList, templateID).Return(nil)
+		mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, nil)
+
+		err := e.AddRecord(mockRecord, isIPv6)
+		assert.NoError(t, err, "Error in adding record to IPFIX exporter, isIPv6: %v", isIPv6)
+	}
+
+	// When sending a record fails, the connection to the collector is closed and is not
+	// re-established before the retry interval has elapsed.
+	e := newIPFIXExporter()
+	mockDataSet.EXPECT().ResetSet()
+	mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil)
+	elementList := make([]ipfixentities.InfoElementWithValue, 0)
+	mockRecord.EXPECT().GetOrderedElementList().Return(elementList)
+	mockDataSet.EXPECT().AddRecord(elementList, testTemplateIDv4).Return(nil)
+	mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, fmt.Errorf("broken pipe"))
+	mockIPFIXExpProc.EXPECT().CloseConnToCollector()
+	assert.Error(t, e.AddRecord(mockRecord, false))
+	assert.Nil(t, e.exportingProcess)
+	e.nextInitTime = time.Now().Add(testRetryInterval)
+	assert.Error(t, e.AddRecord(mockRecord, false))
+}
+
+func TestIPFIXExporter_sendTemplateSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	mockIPFIXRegistry := ipfixtest.NewMockIPFIXRegistry(ctrl)
+	mockTempSet := ipfixentitiestesting.NewMockSet(ctrl)
+
+	newIPFIXExporter := func(includePodLabels, includeEgressInfo, includeServiceType, includeTCPMetrics bool) *IPFIXExporter {
+		return &IPFIXExporter{
+			externalFlowCollectorAddr:  "",
+			externalFlowCollectorProto: "",
+			exportingProcess:           mockIPFIXExpProc,
+			templateIDv4:               testTemplateIDv4,
+			templateIDv6:               testTemplateIDv6,
+			registry:                   mockIPFIXRegistry,
+			set:                        mockTempSet,
+			includePodLabels:           includePodLabels,
+			includeEgressInfo:          includeEgressInfo,
+			includeServiceType:         includeServiceType,
+			includeTCPMetrics:          includeTCPMetrics,
+			observationDomainID:        testObservationDomainID,
+		}
+	}
+
+	testcases := []struct {
+		isIPv6             bool
+		includePodLabels   bool
+		includeEgressInfo  bool
+		includeServiceType bool
+		includeTCPMetrics  bool
+	}{
+		{false, true, false, false, false},
+		{true, true, false, false, false},
+		{false, false, false, false, false},
+		{true, false, false, false, false},
+		{false, true, true, true, true},
+		{true, false, true, true, false},
+		{true, false, false, false, true},
+	}
+
+	for _, tc := range testcases {
+		e := newIPFIXExporter(tc.includePodLabels, tc.includeEgressInfo, tc.includeServiceType, tc.includeTCPMetrics)
+		ianaInfoElements := infoelements.IANAInfoElementsIPv4
+		antreaInfoElements := infoelements.AntreaInfoElementsIPv4
+		testTemplateID := e.templateIDv4
+		if tc.isIPv6 {
+			ianaInfoElements = infoelements.IANAInfoElementsIPv6
+			antreaInfoElements = infoelements.AntreaInfoElementsIPv6
+			testTemplateID = e.templateIDv6
+		}
+		// Following consists of all elements that are in ianaInfoElements and antreaInfoElements (globals)
+		// Only the element name is needed, other arguments have dummy values.
+		elemList := make([]ipfixentities.InfoElementWithValue, 0)
+		for i, ie := range ianaInfoElements {
+			elemList = append(elemList, createElement(ie, ipfixregistry.IANAEnterpriseID))
+			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.IANAEnterpriseID).Return(elemList[i].GetInfoElement(), nil)
+		}
+		for i, ie := range infoelements.IANAReverseInfoElements {
+			elemList = append(elemList, createElement(ie, ipfixregistry.IANAReversedEnterpriseID))
+			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.IANAReversedEnterpriseID).Return(elemList[i+len(ianaInfoElements)].GetInfoElement(), nil)
+		}
+		for i, ie := range antreaInfoElements {
+			elemList = append(elemList, createElement(ie, ipfixregistry.AntreaEnterpriseID))
+			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(elemList[i+len(ianaInfoElements)+len(infoelements.IANAReverseInfoElements)].GetInfoElement(), nil)
+		}
+		for i := range infoelements.StatsElementList {
+			elemList = append(elemList, createElement(infoelements.AntreaSourceStatsElementList[i], ipfixregistry.AntreaEnterpriseID))
+			mockIPFIXRegistry.EXPECT().GetInfoElement(infoelements.AntreaSourceStatsElementList[i], ipfixregistry.AntreaEnterpriseID).Return(elemList[i*2+len(ianaInfoElements)+len(infoelements.IANAReverseInfoElements)+len(antreaInfoElements)].GetInfoElement(), nil)
+			elemList = append(elemList, createElement(infoelements.AntreaDestinationStatsElementList[i], ipfixregistry.AntreaEnterpriseID))
+			mockIPFIXRegistry.EXPECT().GetInfoElement(infoelements.AntreaDestinationStatsElementList[i], ipfixregistry.AntreaEnterpriseID).Return(elemList[i*2+1+len(ianaInfoElements)+len(infoelements.IANAReverseInfoElements)+len(antreaInfoElements)].GetInfoElement(), nil)
+		}
+		if tc.includePodLabels {
+			for i, ie := range infoelements.AntreaLabelsElementList {
+				elemList = append(elemList, createElement(ie, ipfixregistry.AntreaEnterpriseID))
+				mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(elemList[i+len(ianaInfoElements)+len(infoelements.IANAReverseInfoElements)+len(antreaInfoElements)+len(infoelements.AntreaSourceStatsElementList)+len(infoelements.AntreaDestinationStatsElementList)].GetInfoElement(), nil)
+			}
+		}
+		var optionalElements []string
+		if tc.includeServiceType {
+			optionalElements = append(optionalElements, infoelements.AntreaServiceTypeElementList...)
+		}
+		if tc.includeEgressInfo {
+			optionalElements = append(optionalElements, infoelements.AntreaEgressElementList...)
+		}
+		if tc.includeTCPMetrics {
+			optionalElements = append(optionalElements, infoelements.AntreaTCPMetricsElementList...)
+		}
+		for _, ie := range optionalElements {
+			element := createStringElement(ie, ipfixregistry.AntreaEnterpriseID)
+			elemList = append(elemList, element)
+			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(element.GetInfoElement(), nil)
+		}
+		mockTempSet.EXPECT().ResetSet()
+		mockTempSet.EXPECT().PrepareSet(ipfixentities.Template, testTemplateID).Return(nil)
+		mockTempSet.EXPECT().AddRecord(elemList, testTemplateID).Return(nil)
+		// Passing 0 for sentBytes as it is not used anywhere in the test. If this not a call to mock, the actual sentBytes
+		// above elements: ianaInfoElements, ianaReverseInfoElements and antreaInfoElements.
+		mockIPFIXExpProc.EXPECT().SendSet(mockTempSet).Return(0, nil)
+
+		_, err := e.sendTemplateSet(tc.isIPv6)
+		assert.NoErrorf(t, err, "Error in sending template record: %v, isIPv6: %v", err, tc.isIPv6)
+		templateElements := e.templateElementsV4
+		if tc.isIPv6 {
+			templateElements = e.templateElementsV6
+		}
+		assert.Equal(t, elemList, templateElements)
+	}
+}
+
+func TestIPFIXExporter_getTemplateRecordElements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sourcePodName := ipfixentities.NewStringInfoElement(createElement("sourcePodName", ipfixregistry.AntreaEnterpriseID).GetInfoElement(), "pod1")
+	destinationPodName := ipfixentities.NewStringInfoElement(createElement("destinationPodName", ipfixregistry.AntreaEnterpriseID).GetInfoElement(), "pod2")
+	egressName := ipfixentities.NewStringInfoElement(createStringElement("egressName", ipfixregistry.AntreaEnterpriseID).GetInfoElement(), "egress1")
+	templateSourcePodName := createElement("sourcePodName", ipfixregistry.AntreaEnterpriseID)
+	templateDestinationPodName := createElement("destinationPodName", ipfixregistry.AntreaEnterpriseID)
+	templateEgressName := createStringElement("egressName", ipfixregistry.AntreaEnterpriseID)
+
+	testcases := []struct {
+		name             string
+		templateElements []ipfixentities.InfoElementWithValue
+		recordElements   []ipfixentities.InfoElementWithValue
+		expectedElements []ipfixentities.InfoElementWithValue
+	}{
+		{
+			name:             "record matching the template",
+			templateElements: []ipfixentities.InfoElementWithValue{templateSourcePodName, templateDestinationPodName},
+			recordElements:   []ipfixentities.InfoElementWithValue{sourcePodName, destinationPodName},
+			expectedElements: []ipfixentities.InfoElementWithValue{sourcePodName, destinationPodName},
+		},
+		{
+			name:             "record with an element not in the template",
+			templateElements: []ipfixentities.InfoElementWithValue{templateSourcePodName, templateDestinationPodName},
+			recordElements:   []ipfixentities.InfoElementWithValue{sourcePodName, egressName, destinationPodName},
+			expectedElements: []ipfixentities.InfoElementWithValue{sourcePodName, destinationPodName},
+		},
+		{
+			name:             "record missing an element of the template",
+			templateElements: []ipfixentities.InfoElementWithValue{templateSourcePodName, templateDestinationPodName, templateEgressName},
+			recordElements:   []ipfixentities.InfoElementWithValue{sourcePodName, destinationPodName},
+			expectedElements: []ipfixentities.InfoElementWithValue{sourcePodName, destinationPodName, templateEgressName},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+			mockRecord.EXPECT().GetOrderedElementList().Return(tc.recordElements)
+			e := &IPFIXExporter{templateElementsV4: tc.templateElements}
+			assert.Equal(t, tc.expectedElements, e.getTemplateRecordElements(mockRecord, false))
+		})
+	}
+}
+
+func createElement(name string, enterpriseID uint32) ipfixentities.InfoElementWithValue {
+	element, _ := ipfixregistry.GetInfoElement(name, enterpriseID)
+	ieWithValue, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+	return ieWithValue
+}
+
+// createStringElement creates a string element which does not need to be present in
+// the registry.
+func createStringElement(name string, enterpriseID uint32) ipfixentities.InfoElementWithValue {
+	element := ipfixentities.NewInfoElement(name, 0, ipfixentities.String, enterpriseID, 65535)
+	ieWithValue, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
+	return ieWithValue
+}
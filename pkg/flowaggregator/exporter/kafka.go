@@ -0,0 +1,246 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/Shopify/sarama"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/kafka/producer/protobuf"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	KafkaFormatProtobuf = "Protobuf"
+	KafkaFormatJSON     = "JSON"
+)
+
+type KafkaInput struct {
+	// Brokers is the list of addresses of the Kafka brokers.
+	Brokers []string
+	// Topic is the Kafka topic the records are published to.
+	Topic string
+	// Format is KafkaFormatProtobuf or KafkaFormatJSON.
+	Format string
+	// Version is the version of the Kafka brokers, e.g. "2.0.0".
+	Version string
+	// TLS is enabled when CAFile is not empty. CertFile and KeyFile can be provided
+	// for client authentication.
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	Buffer             BufferOptions
+}
+
+// KafkaExporter publishes the aggregated records to a Kafka topic, either encoded with
+// the FlowType2 protobuf schema defined by go-ipfix or as JSON objects. The producer is
+// created when the first batch of records is exported, and creation is retried with
+// the same backoff as failed exports.
+type KafkaExporter struct {
+	input    KafkaInput
+	version  sarama.KafkaVersion
+	producer sarama.AsyncProducer
+	buffer   *bufferedExporter
+	// newProducer creates the producer, it's overridden in tests.
+	newProducer func(brokers []string, config *sarama.Config) (sarama.AsyncProducer, error)
+}
+
+func NewKafkaExporter(input KafkaInput) (*KafkaExporter, error) {
+	if len(input.Brokers) == 0 {
+		return nil, fmt.Errorf("at least one Kafka broker must be provided")
+	}
+	if input.Topic == "" {
+		return nil, fmt.Errorf("a topic must be provided for the Kafka exporter")
+	}
+	if input.Format != KafkaFormatProtobuf && input.Format != KafkaFormatJSON {
+		return nil, fmt.Errorf("format %s is not supported by the Kafka exporter", input.Format)
+	}
+	version, err := sarama.ParseKafkaVersion(input.Version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kafka version %s: %v", input.Version, err)
+	}
+	e := &KafkaExporter{
+		input:       input,
+		version:     version,
+		newProducer: sarama.NewAsyncProducer,
+	}
+	e.buffer = newBufferedExporter("kafka", input.Buffer, e.publishRecords)
+	return e, nil
+}
+
+func (e *KafkaExporter) Start() {
+	e.buffer.start()
+}
+
+func (e *KafkaExporter) Stop() {
+	e.buffer.stop()
+	if e.producer != nil {
+		if err := e.producer.Close(); err != nil {
+			klog.ErrorS(err, "Error when closing Kafka producer")
+		}
+		e.producer = nil
+	}
+}
+
+func (e *KafkaExporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
+	e.buffer.add(flowrecord.GetFlowRecord(record))
+	return nil
+}
+
+func (e *KafkaExporter) initProducer() error {
+	config := sarama.NewConfig()
+	config.Version = e.version
+	// The result of every message is read by publishRecords, so that the records
+	// which could not be delivered are retried.
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	if e.input.CAFile != "" {
+		tlsConfig, err := e.input.getTLSConfig()
+		if err != nil {
+			return err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+	producer, err := e.newProducer(e.input.Brokers, config)
+	if err != nil {
+		return fmt.Errorf("error when initializing Kafka producer: %v", err)
+	}
+	e.producer = producer
+	return nil
+}
+
+func (i *KafkaInput) getTLSConfig() (*tls.Config, error) {
+	caCert, err := ioutil.ReadFile(i.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading Kafka CA certificate: %v", err)
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no valid certificate in Kafka CA file %s", i.CAFile)
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:            caCertPool,
+		InsecureSkipVerify: i.InsecureSkipVerify,
+	}
+	if i.CertFile != "" && i.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(i.CertFile, i.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error when loading Kafka client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func (e *KafkaExporter) encodeRecord(record *flowrecord.FlowRecord) ([]byte, error) {
+	if e.input.Format == KafkaFormatProtobuf {
+		return proto.Marshal(flowRecordToFlowType2(record))
+	}
+	return json.Marshal(record)
+}
+
+// publishRecords hands the records over to the asynchronous Kafka producer and waits
+// for the result of each of them. The records which could not be delivered to the
+// brokers are returned in a partialExportError, so that only these are retried.
+func (e *KafkaExporter) publishRecords(records []*flowrecord.FlowRecord) error {
+	if e.producer == nil {
+		if err := e.initProducer(); err != nil {
+			return err
+		}
+	}
+	msgs := make([]*sarama.ProducerMessage, len(records))
+	for i, record := range records {
+		value, err := e.encodeRecord(record)
+		if err != nil {
+			return fmt.Errorf("error when encoding record: %v", err)
+		}
+		msgs[i] = &sarama.ProducerMessage{
+			Topic:    e.input.Topic,
+			Value:    sarama.ByteEncoder(value),
+			Metadata: record,
+		}
+	}
+	// The results must be consumed while the messages are sent, as the producer stops
+	// accepting messages when its result channels are full.
+	go func() {
+		for _, msg := range msgs {
+			e.producer.Input() <- msg
+		}
+	}()
+	var failed []*flowrecord.FlowRecord
+	var lastErr error
+	for range msgs {
+		select {
+		case <-e.producer.Successes():
+		case producerErr := <-e.producer.Errors():
+			failed = append(failed, producerErr.Msg.Metadata.(*flowrecord.FlowRecord))
+			lastErr = producerErr.Err
+		}
+	}
+	if len(failed) > 0 {
+		return &partialExportError{
+			failed: failed,
+			err:    fmt.Errorf("error when delivering %d of %d records to Kafka: %v", len(failed), len(records), lastErr),
+		}
+	}
+	return nil
+}
+
+func flowRecordToFlowType2(r *flowrecord.FlowRecord) *protobuf.FlowType2 {
+	return &protobuf.FlowType2{
+		TimeReceived:           uint32(time.Now().Unix()),
+		TimeFlowStartInSecs:    uint32(r.FlowStartSeconds.Unix()),
+		TimeFlowEndInSecs:      uint32(r.FlowEndSeconds.Unix()),
+		FlowEndReason:          uint32(r.FlowEndReason),
+		TcpState:               r.TCPState,
+		SrcIP:                  r.SourceIP,
+		DstIP:                  r.DestinationIP,
+		SrcPort:                uint32(r.SourceTransportPort),
+		DstPort:                uint32(r.DestinationTransportPort),
+		Proto:                  uint32(r.ProtocolIdentifier),
+		PacketsTotal:           r.PacketTotalCount,
+		BytesTotal:             r.OctetTotalCount,
+		PacketsDelta:           r.PacketDeltaCount,
+		BytesDelta:             r.OctetDeltaCount,
+		ReversePacketsTotal:    r.ReversePacketTotalCount,
+		ReverseBytesTotal:      r.ReverseOctetTotalCount,
+		ReversePacketsDelta:    r.ReversePacketDeltaCount,
+		ReverseBytesDelta:      r.ReverseOctetDeltaCount,
+		SrcPodName:             r.SourcePodName,
+		SrcPodNamespace:        r.SourcePodNamespace,
+		SrcNodeName:            r.SourceNodeName,
+		DstPodName:             r.DestinationPodName,
+		DstPodNamespace:        r.DestinationPodNamespace,
+		DstNodeName:            r.DestinationNodeName,
+		DstClusterIP:           r.DestinationClusterIP,
+		DstServicePort:         uint32(r.DestinationServicePort),
+		DstServicePortName:     r.DestinationServicePortName,
+		IngressPolicyName:      r.IngressNetworkPolicyName,
+		IngressPolicyNamespace: r.IngressNetworkPolicyNamespace,
+		EgressPolicyName:       r.EgressNetworkPolicyName,
+		EgressPolicyNamespace:  r.EgressNetworkPolicyNamespace,
+	}
+}
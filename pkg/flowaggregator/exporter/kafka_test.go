@@ -0,0 +1,160 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/go-ipfix/pkg/kafka/producer/protobuf"
+	"google.golang.org/protobuf/proto"
+)
+
+// newMockKafkaProducer returns a function creating a mock producer with the given
+// expectations, which checks that the results of the messages are returned.
+func newMockKafkaProducer(t *testing.T, setExpectations func(p *mocks.AsyncProducer)) func([]string, *sarama.Config) (sarama.AsyncProducer, error) {
+	return func(brokers []string, config *sarama.Config) (sarama.AsyncProducer, error) {
+		assert.True(t, config.Producer.Return.Successes)
+		assert.True(t, config.Producer.Return.Errors)
+		p := mocks.NewAsyncProducer(t, config)
+		setExpectations(p)
+		return p, nil
+	}
+}
+
+func TestKafkaExporter(t *testing.T) {
+	checkProtobuf := func(val []byte) error {
+		msg := &protobuf.FlowType2{}
+		if err := proto.Unmarshal(val, msg); err != nil {
+			return err
+		}
+		if msg.SrcIP != "10.10.0.79" || msg.DstIP != "10.10.0.80" || msg.SrcPort != 44752 || msg.DstPort != 5201 ||
+			msg.Proto != 6 || msg.BytesDelta != 1000 || msg.SrcPodName != "perftest-a" || msg.TcpState != "TIME_WAIT" ||
+			msg.TimeFlowStartInSecs != 1637706961 || msg.TimeFlowEndInSecs != 1637706973 {
+			return fmt.Errorf("unexpected flow message: %v", msg)
+		}
+		return nil
+	}
+	checkJSON := func(val []byte) error {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(val, &msg); err != nil {
+			return err
+		}
+		if msg["sourceIP"] != "10.10.0.79" || msg["destinationIP"] != "10.10.0.80" || msg["octetDeltaCount"] != float64(1000) ||
+			msg["sourcePodName"] != "perftest-a" || msg["flowStartSeconds"] != "2021-11-23T22:36:01Z" {
+			return fmt.Errorf("unexpected flow message: %s", string(val))
+		}
+		return nil
+	}
+	testcases := []struct {
+		format  string
+		checker mocks.ValueChecker
+	}{
+		{format: KafkaFormatProtobuf, checker: checkProtobuf},
+		{format: KafkaFormatJSON, checker: checkJSON},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			e, err := NewKafkaExporter(KafkaInput{
+				Brokers: []string{"127.0.0.1:9092"},
+				Topic:   "flows",
+				Format:  tc.format,
+				Version: "2.0.0",
+				Buffer: BufferOptions{
+					Size:          10,
+					FlushInterval: time.Hour,
+				},
+			})
+			require.NoError(t, err)
+			// Inject a mock producer so that no connection to the brokers is made.
+			e.newProducer = newMockKafkaProducer(t, func(p *mocks.AsyncProducer) {
+				p.ExpectInputWithCheckerFunctionAndSucceed(tc.checker)
+				p.ExpectInputWithCheckerFunctionAndSucceed(tc.checker)
+			})
+
+			e.Start()
+			record := createDataRecord(t)
+			require.NoError(t, e.AddRecord(record, false))
+			require.NoError(t, e.AddRecord(record, false))
+			// Buffered records are published when the exporter is stopped, and the
+			// mock producer reports the unmet expectations when it's closed.
+			e.Stop()
+			assert.Nil(t, e.producer)
+		})
+	}
+}
+
+func TestKafkaExporterRetry(t *testing.T) {
+	e, err := NewKafkaExporter(KafkaInput{
+		Brokers: []string{"127.0.0.1:9092"},
+		Topic:   "flows",
+		Format:  KafkaFormatJSON,
+		Version: "2.0.0",
+		Buffer: BufferOptions{
+			Size:          10,
+			FlushInterval: 10 * time.Millisecond,
+			MaxRetries:    3,
+		},
+	})
+	require.NoError(t, err)
+	var numSent int32
+	countSent := func(val []byte) error {
+		atomic.AddInt32(&numSent, 1)
+		return nil
+	}
+	e.newProducer = newMockKafkaProducer(t, func(p *mocks.AsyncProducer) {
+		// The first record of the batch is not delivered, and it is the only one which
+		// is sent again.
+		p.ExpectInputWithCheckerFunctionAndFail(countSent, sarama.ErrNotLeaderForPartition)
+		p.ExpectInputWithCheckerFunctionAndSucceed(countSent)
+		p.ExpectInputWithCheckerFunctionAndSucceed(countSent)
+	})
+
+	e.Start()
+	record := createDataRecord(t)
+	require.NoError(t, e.AddRecord(record, false))
+	require.NoError(t, e.AddRecord(record, false))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&numSent) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	// The mock producer reports the unmet or unexpected messages when it's closed.
+	e.Stop()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&numSent))
+}
+
+func TestNewKafkaExporterInvalidInput(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input KafkaInput
+	}{
+		{name: "no broker", input: KafkaInput{Topic: "flows", Format: KafkaFormatJSON, Version: "2.0.0"}},
+		{name: "no topic", input: KafkaInput{Brokers: []string{"127.0.0.1:9092"}, Format: KafkaFormatJSON, Version: "2.0.0"}},
+		{name: "invalid format", input: KafkaInput{Brokers: []string{"127.0.0.1:9092"}, Topic: "flows", Format: "XML", Version: "2.0.0"}},
+		{name: "invalid version", input: KafkaInput{Brokers: []string{"127.0.0.1:9092"}, Topic: "flows", Format: KafkaFormatJSON, Version: "foo"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewKafkaExporter(tc.input)
+			assert.Error(t, err)
+		})
+	}
+}
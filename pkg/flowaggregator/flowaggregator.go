@@ -16,6 +16,8 @@ package flowaggregator
 
 import (
 	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -23,7 +25,6 @@ import (
 
 	"github.com/vmware/go-ipfix/pkg/collector"
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
-	"github.com/vmware/go-ipfix/pkg/exporter"
 	ipfixintermediate "github.com/vmware/go-ipfix/pkg/intermediate"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
 	corev1 "k8s.io/api/core/v1"
@@ -32,100 +33,21 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	"antrea.io/antrea/pkg/flowaggregator/exporter"
+	"antrea.io/antrea/pkg/flowaggregator/flowquery"
+	"antrea.io/antrea/pkg/flowaggregator/infoelements"
+	"antrea.io/antrea/pkg/flowaggregator/policyrecommendation"
 	"antrea.io/antrea/pkg/flowaggregator/querier"
+	"antrea.io/antrea/pkg/flowaggregator/sharding"
 	"antrea.io/antrea/pkg/ipfix"
 )
 
 var (
-	ianaInfoElementsCommon = []string{
-		"flowStartSeconds",
-		"flowEndSeconds",
-		"flowEndReason",
-		"sourceTransportPort",
-		"destinationTransportPort",
-		"protocolIdentifier",
-		"packetTotalCount",
-		"octetTotalCount",
-		"packetDeltaCount",
-		"octetDeltaCount",
-	}
-	ianaInfoElementsIPv4    = append(ianaInfoElementsCommon, []string{"sourceIPv4Address", "destinationIPv4Address"}...)
-	ianaInfoElementsIPv6    = append(ianaInfoElementsCommon, []string{"sourceIPv6Address", "destinationIPv6Address"}...)
-	ianaReverseInfoElements = []string{
-		"reversePacketTotalCount",
-		"reverseOctetTotalCount",
-		"reversePacketDeltaCount",
-		"reverseOctetDeltaCount",
-	}
-	antreaInfoElementsCommon = []string{
-		"sourcePodName",
-		"sourcePodNamespace",
-		"sourceNodeName",
-		"destinationPodName",
-		"destinationPodNamespace",
-		"destinationNodeName",
-		"destinationServicePort",
-		"destinationServicePortName",
-		"ingressNetworkPolicyName",
-		"ingressNetworkPolicyNamespace",
-		"ingressNetworkPolicyType",
-		"ingressNetworkPolicyRuleName",
-		"ingressNetworkPolicyRuleAction",
-		"egressNetworkPolicyName",
-		"egressNetworkPolicyNamespace",
-		"egressNetworkPolicyType",
-		"egressNetworkPolicyRuleName",
-		"egressNetworkPolicyRuleAction",
-		"tcpState",
-		"flowType",
-	}
-	antreaInfoElementsIPv4 = append(antreaInfoElementsCommon, []string{"destinationClusterIPv4"}...)
-	antreaInfoElementsIPv6 = append(antreaInfoElementsCommon, []string{"destinationClusterIPv6"}...)
-
-	nonStatsElementList = []string{
-		"flowEndSeconds",
-		"flowEndReason",
-		"tcpState",
-	}
-	statsElementList = []string{
-		"octetDeltaCount",
-		"octetTotalCount",
-		"packetDeltaCount",
-		"packetTotalCount",
-		"reverseOctetDeltaCount",
-		"reverseOctetTotalCount",
-		"reversePacketDeltaCount",
-		"reversePacketTotalCount",
-	}
-	antreaSourceStatsElementList = []string{
-		"octetDeltaCountFromSourceNode",
-		"octetTotalCountFromSourceNode",
-		"packetDeltaCountFromSourceNode",
-		"packetTotalCountFromSourceNode",
-		"reverseOctetDeltaCountFromSourceNode",
-		"reverseOctetTotalCountFromSourceNode",
-		"reversePacketDeltaCountFromSourceNode",
-		"reversePacketTotalCountFromSourceNode",
-	}
-	antreaDestinationStatsElementList = []string{
-		"octetDeltaCountFromDestinationNode",
-		"octetTotalCountFromDestinationNode",
-		"packetDeltaCountFromDestinationNode",
-		"packetTotalCountFromDestinationNode",
-		"reverseOctetDeltaCountFromDestinationNode",
-		"reverseOctetTotalCountFromDestinationNode",
-		"reversePacketDeltaCountFromDestinationNode",
-		"reversePacketTotalCountFromDestinationNode",
-	}
-	antreaLabelsElementList = []string{
-		"sourcePodLabels",
-		"destinationPodLabels",
-	}
 	aggregationElements = &ipfixintermediate.AggregationElements{
-		NonStatsElements:                   nonStatsElementList,
-		StatsElements:                      statsElementList,
-		AggregatedSourceStatsElements:      antreaSourceStatsElementList,
-		AggregatedDestinationStatsElements: antreaDestinationStatsElementList,
+		NonStatsElements:                   infoelements.NonStatsElementList,
+		StatsElements:                      infoelements.StatsElementList,
+		AggregatedSourceStatsElements:      infoelements.AntreaSourceStatsElementList,
+		AggregatedDestinationStatsElements: infoelements.AntreaDestinationStatsElementList,
 	}
 
 	correlateFields = []string{
@@ -139,6 +61,7 @@ var (
 		"destinationClusterIPv6",
 		"destinationServicePort",
 		"destinationServicePortName",
+		"destinationServiceType",
 		"ingressNetworkPolicyName",
 		"ingressNetworkPolicyNamespace",
 		"ingressNetworkPolicyRuleAction",
@@ -149,6 +72,11 @@ var (
 		"egressNetworkPolicyRuleAction",
 		"egressNetworkPolicyType",
 		"egressNetworkPolicyRuleName",
+		"egressName",
+		"egressIP",
+		"tcpHandshakeRTT",
+		"tcpHandshakeRetransmissionCount",
+		"tcpResetCount",
 	}
 )
 
@@ -171,57 +99,62 @@ const (
 )
 
 type flowAggregator struct {
-	externalFlowCollectorAddr   string
-	externalFlowCollectorProto  string
 	aggregatorTransportProtocol AggregatorTransportProtocol
 	collectingProcess           ipfix.IPFIXCollectingProcess
 	aggregationProcess          ipfix.IPFIXAggregationProcess
 	activeFlowRecordTimeout     time.Duration
 	inactiveFlowRecordTimeout   time.Duration
-	exportingProcess            ipfix.IPFIXExportingProcess
-	templateIDv4                uint16
-	templateIDv6                uint16
+	exporters                   []exporter.Interface
 	registry                    ipfix.IPFIXRegistry
-	set                         ipfixentities.Set
 	flowAggregatorAddress       string
 	includePodLabels            bool
 	k8sClient                   kubernetes.Interface
-	observationDomainID         uint32
 	podInformer                 coreinformers.PodInformer
-	sendJSONRecord              bool
 	numRecordsExported          int64
 	numRecordsReceived          int64
+	// sharder is only set when sharding is enabled, in which case podIP is the
+	// address of this replica among the endpoints of the flow aggregator Service.
+	sharder *sharding.Sharder
+	podIP   string
+	// flowStore keeps the exported records of the last window for the flows API.
+	flowStore *flowquery.Store
+	// recommender keeps the connections of the exported records for the policy
+	// recommendation API.
+	recommender *policyrecommendation.Recommender
 }
 
+// NewFlowAggregator creates a Flow Aggregator which sends every aggregated flow record
+// to all the provided exporters. When sharder is not nil, only the flows owned by this
+// replica, whose IP is podIP, are exported.
 func NewFlowAggregator(
-	externalFlowCollectorAddr string,
-	externalFlowCollectorProto string,
 	activeFlowRecTimeout time.Duration,
 	inactiveFlowRecTimeout time.Duration,
 	aggregatorTransportProtocol AggregatorTransportProtocol,
 	flowAggregatorAddress string,
 	includePodLabels bool,
 	k8sClient kubernetes.Interface,
-	observationDomainID uint32,
 	podInformer coreinformers.PodInformer,
-	sendJSONRecord bool,
+	registry ipfix.IPFIXRegistry,
+	exporters []exporter.Interface,
+	sharder *sharding.Sharder,
+	podIP string,
+	flowStore *flowquery.Store,
+	recommender *policyrecommendation.Recommender,
 ) *flowAggregator {
-	registry := ipfix.NewIPFIXRegistry()
-	registry.LoadRegistry()
 	fa := &flowAggregator{
-		externalFlowCollectorAddr:   externalFlowCollectorAddr,
-		externalFlowCollectorProto:  externalFlowCollectorProto,
 		aggregatorTransportProtocol: aggregatorTransportProtocol,
 		activeFlowRecordTimeout:     activeFlowRecTimeout,
 		inactiveFlowRecordTimeout:   inactiveFlowRecTimeout,
+		exporters:                   exporters,
 		registry:                    registry,
-		set:                         ipfixentities.NewSet(false),
 		flowAggregatorAddress:       flowAggregatorAddress,
 		includePodLabels:            includePodLabels,
 		k8sClient:                   k8sClient,
-		observationDomainID:         observationDomainID,
 		podInformer:                 podInformer,
-		sendJSONRecord:              sendJSONRecord,
+		sharder:                     sharder,
+		podIP:                       podIP,
+		flowStore:                   flowStore,
+		recommender:                 recommender,
 	}
 	podInformer.Informer().AddIndexers(cache.Indexers{podInfoIndex: podInfoIndexFunc})
 	return fa
@@ -245,16 +178,27 @@ func podInfoIndexFunc(obj interface{}) ([]string, error) {
 func (fa *flowAggregator) InitCollectingProcess() error {
 	var cpInput collector.CollectorInput
 	if fa.aggregatorTransportProtocol == AggregatorTransportProtocolTLS {
-		parentCert, privateKey, caCert, err := generateCACertKey()
+		var parentCert *x509.Certificate
+		var privateKey *rsa.PrivateKey
+		var caCert []byte
+		var err error
+		if fa.sharder != nil {
+			// All the replicas must use the same CA, as the Flow Exporter verifies
+			// their certificates with the CA published in the CA ConfigMap.
+			parentCert, privateKey, caCert, err = getOrCreateSharedCA(fa.k8sClient)
+		} else {
+			parentCert, privateKey, caCert, err = generateCACertKey()
+		}
 		if err != nil {
 			return fmt.Errorf("error when generating CA certificate: %v", err)
 		}
-		serverCert, serverKey, err := generateCertKey(parentCert, privateKey, true, fa.flowAggregatorAddress)
+		// With sharding, the Flow Exporter connects to the Pod IP of the replica.
+		serverCert, serverKey, err := generateCertKey(parentCert, privateKey, true, fa.flowAggregatorAddress, fa.podIP)
 		if err != nil {
 			return fmt.Errorf("error when creating server certificate: %v", err)
 		}
 
-		clientCert, clientKey, err := generateCertKey(parentCert, privateKey, false, "")
+		clientCert, clientKey, err := generateCertKey(parentCert, privateKey, false, "", "")
 		if err != nil {
 			return fmt.Errorf("error when creating client certificate: %v", err)
 		}
@@ -289,7 +233,7 @@ func (fa *flowAggregator) InitCollectingProcess() error {
 			IsEncrypted:   false,
 		}
 	}
-	cpInput.NumExtraElements = len(antreaSourceStatsElementList) + len(antreaDestinationStatsElementList) + len(antreaLabelsElementList)
+	cpInput.NumExtraElements = len(infoelements.AntreaSourceStatsElementList) + len(infoelements.AntreaDestinationStatsElementList) + len(infoelements.AntreaLabelsElementList)
 	var err error
 	fa.collectingProcess, err = ipfix.NewIPFIXCollectingProcess(cpInput)
 	return err
@@ -309,75 +253,15 @@ func (fa *flowAggregator) InitAggregationProcess() error {
 	return err
 }
 
-func (fa *flowAggregator) createAndSendTemplate(isRecordIPv6 bool) error {
-	templateID := fa.exportingProcess.NewTemplateID()
-	recordIPFamily := "IPv4"
-	if isRecordIPv6 {
-		recordIPFamily = "IPv6"
-	}
-	if isRecordIPv6 {
-		fa.templateIDv6 = templateID
-	} else {
-		fa.templateIDv4 = templateID
-	}
-	bytesSent, err := fa.sendTemplateSet(isRecordIPv6)
-	if err != nil {
-		fa.exportingProcess.CloseConnToCollector()
-		fa.exportingProcess = nil
-		fa.set.ResetSet()
-		return fmt.Errorf("sending %s template set failed, err: %v", recordIPFamily, err)
-	}
-	klog.V(2).InfoS("Exporting process initialized", "bytesSent", bytesSent, "templateSetIPFamily", recordIPFamily)
-	return nil
-}
-
-func (fa *flowAggregator) initExportingProcess() error {
-	// TODO: This code can be further simplified by changing the go-ipfix API to accept
-	// externalFlowCollectorAddr and externalFlowCollectorProto instead of net.Addr input.
-	var expInput exporter.ExporterInput
-	if fa.externalFlowCollectorProto == "tcp" {
-		// TCP transport does not need any tempRefTimeout, so sending 0.
-		expInput = exporter.ExporterInput{
-			CollectorAddress:    fa.externalFlowCollectorAddr,
-			CollectorProtocol:   fa.externalFlowCollectorProto,
-			ObservationDomainID: fa.observationDomainID,
-			TempRefTimeout:      0,
-			IsEncrypted:         false,
-			SendJSONRecord:      fa.sendJSONRecord,
-		}
-	} else {
-		// For UDP transport, hardcoding tempRefTimeout value as 1800s. So we will send out template every 30 minutes.
-		expInput = exporter.ExporterInput{
-			CollectorAddress:    fa.externalFlowCollectorAddr,
-			CollectorProtocol:   fa.externalFlowCollectorProto,
-			ObservationDomainID: fa.observationDomainID,
-			TempRefTimeout:      1800,
-			IsEncrypted:         false,
-			SendJSONRecord:      fa.sendJSONRecord,
-		}
-	}
-	ep, err := ipfix.NewIPFIXExportingProcess(expInput)
-	if err != nil {
-		return fmt.Errorf("got error when initializing IPFIX exporting process: %v", err)
-	}
-	fa.exportingProcess = ep
-	// Currently, we send two templates for IPv4 and IPv6 regardless of the IP families supported by cluster
-	if err = fa.createAndSendTemplate(false); err != nil {
-		return err
-	}
-	if err = fa.createAndSendTemplate(true); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (fa *flowAggregator) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 	defer wg.Done()
 	go fa.collectingProcess.Start()
 	defer fa.collectingProcess.Stop()
 	go fa.aggregationProcess.Start()
 	defer fa.aggregationProcess.Stop()
+	for _, exp := range fa.exporters {
+		exp.Start()
+	}
 	go fa.flowRecordExpiryCheck(stopCh)
 
 	<-stopCh
@@ -389,29 +273,18 @@ func (fa *flowAggregator) flowRecordExpiryCheck(stopCh <-chan struct{}) {
 	for {
 		select {
 		case <-stopCh:
-			if fa.exportingProcess != nil {
-				fa.exportingProcess.CloseConnToCollector()
+			// The exporters are stopped from this goroutine, as it is the only one
+			// adding records to them.
+			for _, exp := range fa.exporters {
+				exp.Stop()
 			}
 			expireTimer.Stop()
 			return
 		case <-expireTimer.C:
-			if fa.exportingProcess == nil {
-				err := fa.initExportingProcess()
-				if err != nil {
-					klog.Errorf("Error when initializing exporting process: %v, will retry in %s", err, fa.activeFlowRecordTimeout)
-					// Initializing exporting process fails, will retry in next cycle.
-					expireTimer.Reset(fa.activeFlowRecordTimeout)
-					continue
-				}
-			}
 			// Pop the flow record item from expire priority queue in the Aggregation
 			// Process and send the flow records.
 			if err := fa.aggregationProcess.ForAllExpiredFlowRecordsDo(fa.sendFlowKeyRecord); err != nil {
 				klog.Errorf("Error when sending expired flow records: %v", err)
-				// If there is an error when sending flow records because of intermittent connectivity, we reset the connection
-				// to IPFIX collector and retry in the next export cycle to reinitialize the connection and send flow records.
-				fa.exportingProcess.CloseConnToCollector()
-				fa.exportingProcess = nil
 				expireTimer.Reset(fa.activeFlowRecordTimeout)
 				continue
 			}
@@ -428,16 +301,14 @@ func (fa *flowAggregator) flowRecordExpiryCheck(stopCh <-chan struct{}) {
 }
 
 func (fa *flowAggregator) sendFlowKeyRecord(key ipfixintermediate.FlowKey, record *ipfixintermediate.AggregationFlowRecord) error {
-	isRecordIPv4 := fa.aggregationProcess.IsAggregatedRecordIPv4(*record)
-	templateID := fa.templateIDv4
-	if !isRecordIPv4 {
-		templateID = fa.templateIDv6
-	}
-	// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
-	fa.set.ResetSet()
-	if err := fa.set.PrepareSet(ipfixentities.Data, templateID); err != nil {
-		return err
+	if fa.sharder != nil && !fa.isOwner(key) && !hasDeltaStats(record.Record) {
+		// The flow has been moved to another replica, which exports the records
+		// received since then. The stats received by this replica have already been
+		// exported, so the record is not exported again and expires eventually.
+		klog.V(4).InfoS("Skipping flow record owned by another replica", "flowKey", key)
+		return nil
 	}
+	isRecordIPv4 := fa.aggregationProcess.IsAggregatedRecordIPv4(*record)
 	if !fa.aggregationProcess.AreCorrelatedFieldsFilled(*record) {
 		fa.fillK8sMetadata(key, record.Record)
 		fa.aggregationProcess.SetCorrelatedFieldsFilled(record)
@@ -446,115 +317,42 @@ func (fa *flowAggregator) sendFlowKeyRecord(key ipfixintermediate.FlowKey, recor
 		fa.fillPodLabels(key, record.Record)
 		fa.aggregationProcess.SetExternalFieldsFilled(record)
 	}
-	if err := fa.set.AddRecord(record.Record.GetOrderedElementList(), templateID); err != nil {
-		return err
+	// A failure of one exporter must not prevent the record from being sent to the
+	// other ones: each exporter is responsible for buffering and retrying.
+	for _, exp := range fa.exporters {
+		if err := exp.AddRecord(record.Record, !isRecordIPv4); err != nil {
+			klog.ErrorS(err, "Error when exporting flow record", "flowKey", key)
+		}
 	}
-
-	sentBytes, err := fa.exportingProcess.SendSet(fa.set)
-	if err != nil {
-		return err
+	if fa.flowStore != nil {
+		fa.flowStore.Add(record.Record, time.Now())
+	}
+	if fa.recommender != nil {
+		fa.recommender.Add(record.Record, time.Now())
 	}
-	if err = fa.aggregationProcess.ResetStatElementsInRecord(record.Record); err != nil {
+	if err := fa.aggregationProcess.ResetStatElementsInRecord(record.Record); err != nil {
 		return err
 	}
-
-	klog.V(4).Infof("Data set sent successfully: %d Bytes sent", sentBytes)
 	fa.numRecordsExported = fa.numRecordsExported + 1
 	return nil
 }
 
-func (fa *flowAggregator) sendTemplateSet(isIPv6 bool) (int, error) {
-	elements := make([]ipfixentities.InfoElementWithValue, 0)
-	ianaInfoElements := ianaInfoElementsIPv4
-	antreaInfoElements := antreaInfoElementsIPv4
-	templateID := fa.templateIDv4
-	if isIPv6 {
-		ianaInfoElements = ianaInfoElementsIPv6
-		antreaInfoElements = antreaInfoElementsIPv6
-		templateID = fa.templateIDv6
-	}
-	for _, ie := range ianaInfoElements {
-		element, err := fa.registry.GetInfoElement(ie, ipfixregistry.IANAEnterpriseID)
-		if err != nil {
-			return 0, fmt.Errorf("%s not present. returned error: %v", ie, err)
-		}
-		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-		if err != nil {
-			return 0, err
-		}
-		elements = append(elements, ie)
-	}
-	for _, ie := range ianaReverseInfoElements {
-		element, err := fa.registry.GetInfoElement(ie, ipfixregistry.IANAReversedEnterpriseID)
-		if err != nil {
-			return 0, fmt.Errorf("%s not present. returned error: %v", ie, err)
-		}
-		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-		if err != nil {
-			return 0, err
-		}
-		elements = append(elements, ie)
-	}
-	for _, ie := range antreaInfoElements {
-		element, err := fa.registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID)
-		if err != nil {
-			return 0, fmt.Errorf("%s not present. returned error: %v", ie, err)
-		}
-		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-		if err != nil {
-			return 0, err
-		}
-		elements = append(elements, ie)
-	}
-	// The order of source and destination stats elements needs to match the order specified in
-	// addFieldsForStatsAggregation method in go-ipfix aggregation process.
-	for i := range statsElementList {
-		// Add Antrea source stats fields
-		ieName := antreaSourceStatsElementList[i]
-		element, err := fa.registry.GetInfoElement(ieName, ipfixregistry.AntreaEnterpriseID)
-		if err != nil {
-			return 0, fmt.Errorf("%s not present. returned error: %v", ieName, err)
-		}
-		ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-		if err != nil {
-			return 0, err
-		}
-		elements = append(elements, ie)
-		// Add Antrea destination stats fields
-		ieName = antreaDestinationStatsElementList[i]
-		element, err = fa.registry.GetInfoElement(ieName, ipfixregistry.AntreaEnterpriseID)
-		if err != nil {
-			return 0, fmt.Errorf("%s not present. returned error: %v", ieName, err)
-		}
-		ie, err = ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-		if err != nil {
-			return 0, err
-		}
-		elements = append(elements, ie)
-	}
-	if fa.includePodLabels {
-		for _, ie := range antreaLabelsElementList {
-			element, err := fa.registry.GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID)
-			if err != nil {
-				return 0, fmt.Errorf("error when getting InformationElement %s from registry: %v", ie, err)
-			}
-			ie, err := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-			if err != nil {
-				return 0, err
-			}
-			elements = append(elements, ie)
+// isOwner returns whether this replica owns the flow. All flows are owned by this replica
+// when the ready replicas are not known yet.
+func (fa *flowAggregator) isOwner(key ipfixintermediate.FlowKey) bool {
+	owner, ok := fa.sharder.GetOwner(sharding.FlowKey(key.SourceAddress, key.DestinationAddress, key.Protocol, key.SourcePort, key.DestinationPort))
+	return !ok || owner == fa.podIP
+}
+
+// hasDeltaStats returns whether packets have been received for the flow since its
+// record was last exported.
+func hasDeltaStats(record ipfixentities.Record) bool {
+	for _, name := range []string{"packetDeltaCount", "reversePacketDeltaCount"} {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist && ie.GetUnsigned64Value() > 0 {
+			return true
 		}
 	}
-	fa.set.ResetSet()
-	if err := fa.set.PrepareSet(ipfixentities.Template, templateID); err != nil {
-		return 0, err
-	}
-	err := fa.set.AddRecord(elements, templateID)
-	if err != nil {
-		return 0, fmt.Errorf("error when adding record to set, error: %v", err)
-	}
-	bytesSent, err := fa.exportingProcess.SendSet(fa.set)
-	return bytesSent, err
+	return false
 }
 
 // fillK8sMetadata fills Pod name, Pod namespace and Node name for inter-Node flows
@@ -660,6 +458,20 @@ func (fa *flowAggregator) GetFlowRecords(flowKey *ipfixintermediate.FlowKey) []m
 	return fa.aggregationProcess.GetRecords(flowKey)
 }
 
+func (fa *flowAggregator) QueryFlows(query flowquery.Query) ([]flowquery.Result, error) {
+	if fa.flowStore == nil {
+		return nil, fmt.Errorf("flow queries are not enabled")
+	}
+	return fa.flowStore.Query(query, time.Now())
+}
+
+func (fa *flowAggregator) RecommendPolicies(options policyrecommendation.Options) (*policyrecommendation.Recommendation, error) {
+	if fa.recommender == nil {
+		return nil, fmt.Errorf("policy recommendation is not enabled")
+	}
+	return fa.recommender.Recommend(options, time.Now())
+}
+
 func (fa *flowAggregator) GetRecordMetrics() querier.Metrics {
 	return querier.Metrics{
 		NumRecordsExported: fa.numRecordsExported,
@@ -16,28 +16,35 @@ package flowaggregator
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
 	ipfixintermediate "github.com/vmware/go-ipfix/pkg/intermediate"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
+	"antrea.io/antrea/pkg/flowaggregator/exporter"
+	exportertesting "antrea.io/antrea/pkg/flowaggregator/exporter/testing"
+	"antrea.io/antrea/pkg/flowaggregator/flowquery"
+	"antrea.io/antrea/pkg/flowaggregator/sharding"
 	ipfixtest "antrea.io/antrea/pkg/ipfix/testing"
 )
 
 const (
-	testTemplateIDv4        = uint16(256)
-	testTemplateIDv6        = uint16(257)
-	testActiveTimeout       = 60 * time.Second
-	testInactiveTimeout     = 180 * time.Second
-	testObservationDomainID = 0xabcd
-	informerDefaultResync   = 12 * time.Hour
+	testActiveTimeout     = 60 * time.Second
+	testInactiveTimeout   = 180 * time.Second
+	informerDefaultResync = 12 * time.Hour
 )
 
 func init() {
@@ -48,9 +55,9 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	mockIPFIXExporter := exportertesting.NewMockInterface(ctrl)
+	mockKafkaExporter := exportertesting.NewMockInterface(ctrl)
 	mockIPFIXRegistry := ipfixtest.NewMockIPFIXRegistry(ctrl)
-	mockDataSet := ipfixentitiestesting.NewMockSet(ctrl)
 	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
 	mockAggregationProcess := ipfixtest.NewMockIPFIXAggregationProcess(ctrl)
 
@@ -59,20 +66,14 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 
 	newFlowAggregator := func(includePodLabels bool) *flowAggregator {
 		return &flowAggregator{
-			externalFlowCollectorAddr:   "",
-			externalFlowCollectorProto:  "",
 			aggregatorTransportProtocol: "tcp",
 			aggregationProcess:          mockAggregationProcess,
 			activeFlowRecordTimeout:     testActiveTimeout,
 			inactiveFlowRecordTimeout:   testInactiveTimeout,
-			exportingProcess:            mockIPFIXExpProc,
-			templateIDv4:                testTemplateIDv4,
-			templateIDv6:                testTemplateIDv6,
+			exporters:                   []exporter.Interface{mockIPFIXExporter, mockKafkaExporter},
 			registry:                    mockIPFIXRegistry,
-			set:                         mockDataSet,
 			flowAggregatorAddress:       "",
 			includePodLabels:            includePodLabels,
-			observationDomainID:         testObservationDomainID,
 			podInformer:                 informerFactory.Core().V1().Pods(),
 		}
 	}
@@ -137,16 +138,10 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 
 	for _, tc := range testcases {
 		fa := newFlowAggregator(tc.includePodLabels)
-		templateID := fa.templateIDv4
-		if tc.isIPv6 {
-			templateID = fa.templateIDv6
-		}
-		mockDataSet.EXPECT().ResetSet()
-		mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, templateID).Return(nil)
-		elementList := make([]ipfixentities.InfoElementWithValue, 0)
-		mockRecord.EXPECT().GetOrderedElementList().Return(elementList)
-		mockDataSet.EXPECT().AddRecord(elementList, templateID).Return(nil)
-		mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, nil)
+		// An error returned by one exporter must not prevent the record from being
+		// sent to the other exporters.
+		mockIPFIXExporter.EXPECT().AddRecord(mockRecord, tc.isIPv6).Return(fmt.Errorf("connection refused"))
+		mockKafkaExporter.EXPECT().AddRecord(mockRecord, tc.isIPv6).Return(nil)
 		mockAggregationProcess.EXPECT().ResetStatElementsInRecord(mockRecord).Return(nil)
 		mockAggregationProcess.EXPECT().AreCorrelatedFieldsFilled(*tc.flowRecord).Return(false)
 		emptyStr := make([]byte, 0)
@@ -171,98 +166,89 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 
 		err := fa.sendFlowKeyRecord(tc.flowKey, tc.flowRecord)
 		assert.NoError(t, err, "Error in sending flow key record: %v, key: %v, record: %v", err, tc.flowKey, tc.flowRecord)
+		assert.Equal(t, int64(1), fa.numRecordsExported)
 	}
 }
 
-func TestFlowAggregator_sendTemplateSet(t *testing.T) {
+func TestFlowAggregator_sendFlowKeyRecordWithSharding(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
-	mockIPFIXRegistry := ipfixtest.NewMockIPFIXRegistry(ctrl)
-	mockTempSet := ipfixentitiestesting.NewMockSet(ctrl)
+	mockExporter := exportertesting.NewMockInterface(ctrl)
+	mockAggregationProcess := ipfixtest.NewMockIPFIXAggregationProcess(ctrl)
 
-	newFlowAggregator := func(includePodLabels bool) *flowAggregator {
-		return &flowAggregator{
-			externalFlowCollectorAddr:   "",
-			externalFlowCollectorProto:  "",
-			aggregatorTransportProtocol: "tcp",
-			collectingProcess:           nil,
-			aggregationProcess:          nil,
-			activeFlowRecordTimeout:     testActiveTimeout,
-			exportingProcess:            mockIPFIXExpProc,
-			templateIDv4:                testTemplateIDv4,
-			templateIDv6:                testTemplateIDv6,
-			registry:                    mockIPFIXRegistry,
-			set:                         mockTempSet,
-			flowAggregatorAddress:       "",
-			includePodLabels:            includePodLabels,
-			k8sClient:                   nil,
-			observationDomainID:         testObservationDomainID,
+	key := ipfixintermediate.FlowKey{
+		SourceAddress:      "10.0.0.1",
+		DestinationAddress: "10.0.0.2",
+		Protocol:           6,
+		SourcePort:         1234,
+		DestinationPort:    5678,
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "flow-aggregator", Name: "flow-aggregator", ResourceVersion: "1"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.10.0.1"}, {IP: "10.10.0.2"}},
+		}},
+	}))
+	sharder := sharding.NewSharder("flow-aggregator", "flow-aggregator", corelisters.NewEndpointsLister(indexer))
+	owner, ok := sharder.GetOwner(sharding.FlowKey(key.SourceAddress, key.DestinationAddress, key.Protocol, key.SourcePort, key.DestinationPort))
+	require.True(t, ok)
+	other := "10.10.0.1"
+	if owner == other {
+		other = "10.10.0.2"
+	}
+
+	newRecord := func(packetDeltaCount uint64) *ipfixintermediate.AggregationFlowRecord {
+		elements := []ipfixentities.InfoElementWithValue{
+			ipfixentities.NewUnsigned64InfoElement(ipfixentities.NewInfoElement("packetDeltaCount", 2, ipfixentities.Unsigned64, ipfixregistry.IANAEnterpriseID, 8), packetDeltaCount),
+			ipfixentities.NewUnsigned64InfoElement(ipfixentities.NewInfoElement("reversePacketDeltaCount", 2, ipfixentities.Unsigned64, ipfixregistry.IANAReversedEnterpriseID, 8), 0),
 		}
+		record := ipfixentities.NewDataRecord(256, 0, 0, true)
+		for _, element := range elements {
+			require.NoError(t, record.AddInfoElement(element))
+		}
+		return &ipfixintermediate.AggregationFlowRecord{Record: record, ReadyToSend: true}
 	}
 
 	testcases := []struct {
-		isIPv6           bool
-		includePodLabels bool
+		name             string
+		podIP            string
+		packetDeltaCount uint64
+		expectExport     bool
 	}{
-		{false, true},
-		{true, true},
-		{false, false},
-		{true, false},
+		{"owned flow", owner, 0, true},
+		{"flow owned by other replica without new stats", other, 0, false},
+		{"flow owned by other replica with new stats", other, 10, true},
 	}
-
 	for _, tc := range testcases {
-		fa := newFlowAggregator(tc.includePodLabels)
-		ianaInfoElements := ianaInfoElementsIPv4
-		antreaInfoElements := antreaInfoElementsIPv4
-		testTemplateID := fa.templateIDv4
-		if tc.isIPv6 {
-			ianaInfoElements = ianaInfoElementsIPv6
-			antreaInfoElements = antreaInfoElementsIPv6
-			testTemplateID = fa.templateIDv6
-		}
-		// Following consists of all elements that are in ianaInfoElements and antreaInfoElements (globals)
-		// Only the element name is needed, other arguments have dummy values.
-		elemList := make([]ipfixentities.InfoElementWithValue, 0)
-		for i, ie := range ianaInfoElements {
-			elemList = append(elemList, createElement(ie, ipfixregistry.IANAEnterpriseID))
-			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.IANAEnterpriseID).Return(elemList[i].GetInfoElement(), nil)
-		}
-		for i, ie := range ianaReverseInfoElements {
-			elemList = append(elemList, createElement(ie, ipfixregistry.IANAReversedEnterpriseID))
-			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.IANAReversedEnterpriseID).Return(elemList[i+len(ianaInfoElements)].GetInfoElement(), nil)
-		}
-		for i, ie := range antreaInfoElements {
-			elemList = append(elemList, createElement(ie, ipfixregistry.AntreaEnterpriseID))
-			mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(elemList[i+len(ianaInfoElements)+len(ianaReverseInfoElements)].GetInfoElement(), nil)
-		}
-		for i := range statsElementList {
-			elemList = append(elemList, createElement(antreaSourceStatsElementList[i], ipfixregistry.AntreaEnterpriseID))
-			mockIPFIXRegistry.EXPECT().GetInfoElement(antreaSourceStatsElementList[i], ipfixregistry.AntreaEnterpriseID).Return(elemList[i*2+len(ianaInfoElements)+len(ianaReverseInfoElements)+len(antreaInfoElements)].GetInfoElement(), nil)
-			elemList = append(elemList, createElement(antreaDestinationStatsElementList[i], ipfixregistry.AntreaEnterpriseID))
-			mockIPFIXRegistry.EXPECT().GetInfoElement(antreaDestinationStatsElementList[i], ipfixregistry.AntreaEnterpriseID).Return(elemList[i*2+1+len(ianaInfoElements)+len(ianaReverseInfoElements)+len(antreaInfoElements)].GetInfoElement(), nil)
-		}
-		if tc.includePodLabels {
-			for i, ie := range antreaLabelsElementList {
-				elemList = append(elemList, createElement(ie, ipfixregistry.AntreaEnterpriseID))
-				mockIPFIXRegistry.EXPECT().GetInfoElement(ie, ipfixregistry.AntreaEnterpriseID).Return(elemList[i+len(ianaInfoElements)+len(ianaReverseInfoElements)+len(antreaInfoElements)+len(antreaSourceStatsElementList)+len(antreaDestinationStatsElementList)].GetInfoElement(), nil)
+		t.Run(tc.name, func(t *testing.T) {
+			fa := &flowAggregator{
+				aggregationProcess: mockAggregationProcess,
+				exporters:          []exporter.Interface{mockExporter},
+				sharder:            sharder,
+				podIP:              tc.podIP,
+				flowStore:          flowquery.NewStore(time.Minute, 10),
 			}
-		}
-		mockTempSet.EXPECT().ResetSet()
-		mockTempSet.EXPECT().PrepareSet(ipfixentities.Template, testTemplateID).Return(nil)
-		mockTempSet.EXPECT().AddRecord(elemList, testTemplateID).Return(nil)
-		// Passing 0 for sentBytes as it is not used anywhere in the test. If this not a call to mock, the actual sentBytes
-		// above elements: ianaInfoElements, ianaReverseInfoElements and antreaInfoElements.
-		mockIPFIXExpProc.EXPECT().SendSet(mockTempSet).Return(0, nil)
-
-		_, err := fa.sendTemplateSet(tc.isIPv6)
-		assert.NoErrorf(t, err, "Error in sending template record: %v, isIPv6: %v", err, tc.isIPv6)
+			record := newRecord(tc.packetDeltaCount)
+			if tc.expectExport {
+				mockAggregationProcess.EXPECT().IsAggregatedRecordIPv4(*record).Return(true)
+				mockAggregationProcess.EXPECT().AreCorrelatedFieldsFilled(*record).Return(true)
+				mockExporter.EXPECT().AddRecord(record.Record, false).Return(nil)
+				mockAggregationProcess.EXPECT().ResetStatElementsInRecord(record.Record).Return(nil)
+			}
+			require.NoError(t, fa.sendFlowKeyRecord(key, record))
+			// Only the exported records are kept for the flows API.
+			results, err := fa.QueryFlows(flowquery.Query{})
+			require.NoError(t, err)
+			if tc.expectExport {
+				assert.Equal(t, int64(1), fa.numRecordsExported)
+				require.Len(t, results, 1)
+				assert.Equal(t, tc.packetDeltaCount, results[0].Packets)
+			} else {
+				assert.Equal(t, int64(0), fa.numRecordsExported)
+				assert.Empty(t, results)
+			}
+		})
 	}
 }
-
-func createElement(name string, enterpriseID uint32) ipfixentities.InfoElementWithValue {
-	element, _ := ipfixregistry.GetInfoElement(name, enterpriseID)
-	ieWithValue, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(element, nil)
-	return ieWithValue
-}
@@ -0,0 +1,377 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flowquery keeps the flow records exported by the Flow Aggregator in memory over
+// a sliding window, and aggregates them on demand to answer queries such as the top Pod
+// pairs by bytes or the flows denied by a NetworkPolicy.
+package flowquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// GroupBy is the key the records are aggregated by.
+type GroupBy string
+
+const (
+	GroupByFlow      GroupBy = "flow"
+	GroupByPodPair   GroupBy = "pod-pair"
+	GroupByNamespace GroupBy = "namespace"
+	GroupByService   GroupBy = "service"
+	GroupByPolicy    GroupBy = "policy"
+)
+
+// Query selects and aggregates the records of the window.
+type Query struct {
+	// Window is the period before now the records are selected from. It must not be
+	// greater than the window of the Store, which is used when Window is zero.
+	Window  time.Duration
+	GroupBy GroupBy
+	// Top is the maximum number of results, sorted by decreasing bytes. All the
+	// results are returned when Top is zero.
+	Top int
+	// Namespace selects the records of the flows whose source or destination Pod is in
+	// this Namespace.
+	Namespace string
+	// Pod selects the records of the flows whose source or destination Pod matches,
+	// with format <Namespace>/<Name>, or <Name> for a Pod in any Namespace.
+	Pod string
+	// Service selects the records of the flows to this Service, with format
+	// <Namespace>/<Name>[:<Port>].
+	Service string
+	// Policy selects the records of the flows whose ingress or egress NetworkPolicy
+	// matches, with format <Namespace>/<Name>, or <Name> for a cluster-scoped policy or
+	// a policy in any Namespace.
+	Policy string
+	// Denied selects the records of the flows dropped or rejected by a NetworkPolicy.
+	Denied bool
+}
+
+// Result is the aggregation of the records of a group. Only the fields of the key of the
+// group are set, except for GroupByFlow, where the fields of the latest record of the
+// flow are set.
+type Result struct {
+	SourceAddress      string `json:"sourceAddress,omitempty"`
+	DestinationAddress string `json:"destinationAddress,omitempty"`
+	Protocol           uint8  `json:"protocol,omitempty"`
+	SourcePort         uint16 `json:"sourcePort,omitempty"`
+	DestinationPort    uint16 `json:"destinationPort,omitempty"`
+	// SourcePod and DestinationPod have the format <Namespace>/<Name>. For
+	// GroupByPodPair, they are the IP address of the endpoint when it is not a Pod.
+	SourcePod      string `json:"sourcePod,omitempty"`
+	DestinationPod string `json:"destinationPod,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+	Service        string `json:"service,omitempty"`
+	// Policies are the ingress and egress NetworkPolicies, with format
+	// <Namespace>/<Name> or <Name>.
+	Policies []string `json:"policies,omitempty"`
+	Denied   bool     `json:"denied,omitempty"`
+	Bytes    uint64   `json:"bytes"`
+	Packets  uint64   `json:"packets"`
+	// Records is the number of records aggregated in the result.
+	Records int `json:"records"`
+}
+
+// entry is the summary of an exported record, keeping only the fields needed to filter
+// and aggregate it.
+type entry struct {
+	time                 time.Time
+	sourceAddress        string
+	destinationAddress   string
+	protocol             uint8
+	sourcePort           uint16
+	destinationPort      uint16
+	sourcePod            string
+	destinationPod       string
+	sourceNamespace      string
+	destinationNamespace string
+	service              string
+	ingressPolicy        string
+	egressPolicy         string
+	denied               bool
+	bytes                uint64
+	packets              uint64
+}
+
+// Store keeps the entries of the records exported during the last window, up to
+// maxEntries. The oldest entries are dropped first.
+type Store struct {
+	window     time.Duration
+	maxEntries int
+	mutex      sync.Mutex
+	// entries are sorted by time, as they are added when the records are exported.
+	entries []entry
+}
+
+func NewStore(window time.Duration, maxEntries int) *Store {
+	return &Store{
+		window:     window,
+		maxEntries: maxEntries,
+	}
+}
+
+// Add adds an exported record. It must be called before the delta counters of the
+// record are reset.
+func (s *Store) Add(record ipfixentities.Record, now time.Time) {
+	e := newEntry(record, now)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, e)
+	s.pruneLocked(now)
+}
+
+func (s *Store) pruneLocked(now time.Time) {
+	start := sort.Search(len(s.entries), func(i int) bool {
+		return now.Sub(s.entries[i].time) <= s.window
+	})
+	if len(s.entries)-start > s.maxEntries {
+		start = len(s.entries) - s.maxEntries
+	}
+	if start > 0 {
+		// Re-slicing keeps the underlying array, whose unused head is released when
+		// append allocates a larger one.
+		s.entries = s.entries[start:]
+	}
+}
+
+// Query returns the results of the query, sorted by decreasing bytes.
+func (s *Store) Query(q Query, now time.Time) ([]Result, error) {
+	if q.Window < 0 || q.Window > s.window {
+		return nil, fmt.Errorf("window must be between 0 and %v", s.window)
+	}
+	if q.Window == 0 {
+		q.Window = s.window
+	}
+	if q.Top < 0 {
+		return nil, fmt.Errorf("top must not be negative")
+	}
+	if q.GroupBy == "" {
+		q.GroupBy = GroupByFlow
+	}
+	switch q.GroupBy {
+	case GroupByFlow, GroupByPodPair, GroupByNamespace, GroupByService, GroupByPolicy:
+	default:
+		return nil, fmt.Errorf("unsupported groupBy %s", q.GroupBy)
+	}
+
+	results := make(map[string]*Result)
+	var keys []string
+	add := func(key string, e *entry, fill func(r *Result)) {
+		r, ok := results[key]
+		if !ok {
+			r = &Result{}
+			results[key] = r
+			keys = append(keys, key)
+		}
+		fill(r)
+		r.Bytes += e.bytes
+		r.Packets += e.packets
+		r.Records++
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pruneLocked(now)
+	start := sort.Search(len(s.entries), func(i int) bool {
+		return now.Sub(s.entries[i].time) <= q.Window
+	})
+	for i := start; i < len(s.entries); i++ {
+		e := &s.entries[i]
+		if !e.matches(&q) {
+			continue
+		}
+		switch q.GroupBy {
+		case GroupByFlow:
+			key := fmt.Sprintf("%s,%s,%d,%d,%d", e.sourceAddress, e.destinationAddress, e.protocol, e.sourcePort, e.destinationPort)
+			add(key, e, func(r *Result) {
+				r.SourceAddress, r.DestinationAddress = e.sourceAddress, e.destinationAddress
+				r.Protocol, r.SourcePort, r.DestinationPort = e.protocol, e.sourcePort, e.destinationPort
+				r.SourcePod, r.DestinationPod = e.sourcePod, e.destinationPod
+				r.Service = e.service
+				r.Policies = e.policies()
+				r.Denied = e.denied
+			})
+		case GroupByPodPair:
+			source, destination := e.sourcePod, e.destinationPod
+			if source == "" {
+				source = e.sourceAddress
+			}
+			if destination == "" {
+				destination = e.destinationAddress
+			}
+			add(source+","+destination, e, func(r *Result) {
+				r.SourcePod, r.DestinationPod = source, destination
+			})
+		case GroupByNamespace:
+			// The records are counted for both Namespaces of inter-Namespace flows.
+			namespaces := []string{e.sourceNamespace}
+			if e.destinationNamespace != e.sourceNamespace {
+				namespaces = append(namespaces, e.destinationNamespace)
+			}
+			for _, namespace := range namespaces {
+				if namespace == "" {
+					continue
+				}
+				ns := namespace
+				add(ns, e, func(r *Result) { r.Namespace = ns })
+			}
+		case GroupByService:
+			if e.service == "" {
+				continue
+			}
+			add(e.service, e, func(r *Result) { r.Service = e.service })
+		case GroupByPolicy:
+			for _, policy := range e.policies() {
+				p := policy
+				add(p, e, func(r *Result) {
+					r.Policies = []string{p}
+					r.Denied = r.Denied || e.denied
+				})
+			}
+		}
+	}
+
+	list := make([]Result, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, *results[key])
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Bytes > list[j].Bytes })
+	if q.Top > 0 && len(list) > q.Top {
+		list = list[:q.Top]
+	}
+	return list, nil
+}
+
+func (e *entry) policies() []string {
+	var policies []string
+	if e.ingressPolicy != "" {
+		policies = append(policies, e.ingressPolicy)
+	}
+	if e.egressPolicy != "" && e.egressPolicy != e.ingressPolicy {
+		policies = append(policies, e.egressPolicy)
+	}
+	return policies
+}
+
+func (e *entry) matches(q *Query) bool {
+	if q.Denied && !e.denied {
+		return false
+	}
+	if q.Namespace != "" && e.sourceNamespace != q.Namespace && e.destinationNamespace != q.Namespace {
+		return false
+	}
+	if q.Pod != "" && !matchesName(e.sourcePod, q.Pod) && !matchesName(e.destinationPod, q.Pod) {
+		return false
+	}
+	if q.Service != "" && e.service != q.Service && !strings.HasPrefix(e.service, q.Service+":") {
+		return false
+	}
+	if q.Policy != "" && !matchesName(e.ingressPolicy, q.Policy) && !matchesName(e.egressPolicy, q.Policy) {
+		return false
+	}
+	return true
+}
+
+// matchesName returns whether the <Namespace>/<Name> or <Name> value matches the filter,
+// which matches any Namespace when it does not include one.
+func matchesName(value, filter string) bool {
+	if value == "" {
+		return false
+	}
+	if value == filter {
+		return true
+	}
+	if !strings.Contains(filter, "/") {
+		if i := strings.Index(value, "/"); i >= 0 {
+			return value[i+1:] == filter
+		}
+	}
+	return false
+}
+
+func namespacedName(namespace, name string) string {
+	if name == "" {
+		return ""
+	}
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+func newEntry(record ipfixentities.Record, now time.Time) entry {
+	getString := func(name string) string {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			return ie.GetStringValue()
+		}
+		return ""
+	}
+	getUnsigned64 := func(name string) uint64 {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			return ie.GetUnsigned64Value()
+		}
+		return 0
+	}
+	getUnsigned8 := func(name string) uint8 {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			return ie.GetUnsigned8Value()
+		}
+		return 0
+	}
+	getUnsigned16 := func(name string) uint16 {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			return ie.GetUnsigned16Value()
+		}
+		return 0
+	}
+	getAddress := func(ipv4Name, ipv6Name string) string {
+		if ie, _, exist := record.GetInfoElementWithValue(ipv4Name); exist {
+			return ie.GetIPAddressValue().String()
+		}
+		if ie, _, exist := record.GetInfoElementWithValue(ipv6Name); exist {
+			return ie.GetIPAddressValue().String()
+		}
+		return ""
+	}
+	isDenied := func(action uint8) bool {
+		return action == registry.NetworkPolicyRuleActionDrop || action == registry.NetworkPolicyRuleActionReject
+	}
+
+	e := entry{
+		time:                 now,
+		sourceAddress:        getAddress("sourceIPv4Address", "sourceIPv6Address"),
+		destinationAddress:   getAddress("destinationIPv4Address", "destinationIPv6Address"),
+		protocol:             getUnsigned8("protocolIdentifier"),
+		sourcePort:           getUnsigned16("sourceTransportPort"),
+		destinationPort:      getUnsigned16("destinationTransportPort"),
+		sourceNamespace:      getString("sourcePodNamespace"),
+		destinationNamespace: getString("destinationPodNamespace"),
+		service:              getString("destinationServicePortName"),
+		ingressPolicy:        namespacedName(getString("ingressNetworkPolicyNamespace"), getString("ingressNetworkPolicyName")),
+		egressPolicy:         namespacedName(getString("egressNetworkPolicyNamespace"), getString("egressNetworkPolicyName")),
+		denied:               isDenied(getUnsigned8("ingressNetworkPolicyRuleAction")) || isDenied(getUnsigned8("egressNetworkPolicyRuleAction")),
+		bytes:                getUnsigned64("octetDeltaCount") + getUnsigned64("reverseOctetDeltaCount"),
+		packets:              getUnsigned64("packetDeltaCount") + getUnsigned64("reversePacketDeltaCount"),
+	}
+	e.sourcePod = namespacedName(e.sourceNamespace, getString("sourcePodName"))
+	e.destinationPod = namespacedName(e.destinationNamespace, getString("destinationPodName"))
+	return e
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowquery
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+)
+
+type testFlow struct {
+	srcIP, dstIP         string
+	srcPort              uint16
+	srcNS, srcPod        string
+	dstNS, dstPod        string
+	service              string
+	policyNS, policyName string
+	action               uint8
+	bytes                uint64
+}
+
+func newTestRecord(t *testing.T, f testFlow) ipfixentities.Record {
+	ipfixregistry.LoadRegistry()
+	newElement := func(name string, enterpriseID uint32) *ipfixentities.InfoElement {
+		element, err := ipfixregistry.GetInfoElement(name, enterpriseID)
+		require.NoError(t, err)
+		return element
+	}
+	iana, antrea := ipfixregistry.IANAEnterpriseID, ipfixregistry.AntreaEnterpriseID
+	elements := []ipfixentities.InfoElementWithValue{
+		ipfixentities.NewIPAddressInfoElement(newElement("sourceIPv4Address", iana), net.ParseIP(f.srcIP)),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationIPv4Address", iana), net.ParseIP(f.dstIP)),
+		ipfixentities.NewUnsigned16InfoElement(newElement("sourceTransportPort", iana), f.srcPort),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationTransportPort", iana), 80),
+		ipfixentities.NewUnsigned8InfoElement(newElement("protocolIdentifier", iana), 6),
+		ipfixentities.NewUnsigned64InfoElement(newElement("octetDeltaCount", iana), f.bytes),
+		ipfixentities.NewUnsigned64InfoElement(newElement("packetDeltaCount", iana), 1),
+		ipfixentities.NewUnsigned64InfoElement(newElement("reverseOctetDeltaCount", ipfixregistry.IANAReversedEnterpriseID), f.bytes),
+		ipfixentities.NewUnsigned64InfoElement(newElement("reversePacketDeltaCount", ipfixregistry.IANAReversedEnterpriseID), 1),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodNamespace", antrea), f.srcNS),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodName", antrea), f.srcPod),
+		ipfixentities.NewStringInfoElement(newElement("destinationPodNamespace", antrea), f.dstNS),
+		ipfixentities.NewStringInfoElement(newElement("destinationPodName", antrea), f.dstPod),
+		ipfixentities.NewStringInfoElement(newElement("destinationServicePortName", antrea), f.service),
+		ipfixentities.NewStringInfoElement(newElement("ingressNetworkPolicyNamespace", antrea), f.policyNS),
+		ipfixentities.NewStringInfoElement(newElement("ingressNetworkPolicyName", antrea), f.policyName),
+		ipfixentities.NewUnsigned8InfoElement(newElement("ingressNetworkPolicyRuleAction", antrea), f.action),
+	}
+	record := ipfixentities.NewDataRecord(256, 0, 0, true)
+	for _, element := range elements {
+		require.NoError(t, record.AddInfoElement(element))
+	}
+	return record
+}
+
+func TestStoreQuery(t *testing.T) {
+	store := NewStore(10*time.Minute, 100)
+	now := time.Now()
+	webToDB := testFlow{srcIP: "10.10.0.1", dstIP: "10.10.1.1", srcPort: 30001, srcNS: "prod", srcPod: "web", dstNS: "prod", dstPod: "db",
+		policyNS: "prod", policyName: "allow-web", action: ipfixregistry.NetworkPolicyRuleActionAllow, bytes: 100}
+	clientToWeb := testFlow{srcIP: "10.10.2.1", dstIP: "10.10.0.1", srcPort: 30002, srcNS: "dev", srcPod: "client", dstNS: "prod", dstPod: "web",
+		service: "prod/web:http", bytes: 50}
+	denied := testFlow{srcIP: "10.10.2.1", dstIP: "10.10.1.1", srcPort: 30003, srcNS: "dev", srcPod: "client", dstNS: "prod", dstPod: "db",
+		policyName: "deny-dev", action: ipfixregistry.NetworkPolicyRuleActionDrop, bytes: 10}
+	external := testFlow{srcIP: "10.10.0.1", dstIP: "8.8.8.8", srcPort: 30004, srcNS: "prod", srcPod: "web", bytes: 500}
+
+	// This record is out of the window of the query below.
+	store.Add(newTestRecord(t, webToDB), now.Add(-6*time.Minute))
+	store.Add(newTestRecord(t, webToDB), now.Add(-time.Minute))
+	store.Add(newTestRecord(t, webToDB), now.Add(-time.Minute))
+	store.Add(newTestRecord(t, clientToWeb), now.Add(-time.Minute))
+	store.Add(newTestRecord(t, denied), now.Add(-time.Minute))
+	store.Add(newTestRecord(t, external), now)
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []Result
+	}{
+		{
+			name:  "top Pod pairs",
+			query: Query{Window: 5 * time.Minute, GroupBy: GroupByPodPair, Top: 2},
+			expected: []Result{
+				{SourcePod: "prod/web", DestinationPod: "8.8.8.8", Bytes: 1000, Packets: 2, Records: 1},
+				{SourcePod: "prod/web", DestinationPod: "prod/db", Bytes: 400, Packets: 4, Records: 2},
+			},
+		},
+		{
+			name:  "flows denied by policy",
+			query: Query{Policy: "deny-dev", Denied: true},
+			expected: []Result{
+				{SourceAddress: "10.10.2.1", DestinationAddress: "10.10.1.1", Protocol: 6, SourcePort: 30003, DestinationPort: 80,
+					SourcePod: "dev/client", DestinationPod: "prod/db", Policies: []string{"deny-dev"}, Denied: true, Bytes: 20, Packets: 2, Records: 1},
+			},
+		},
+		{
+			name:  "bytes per Namespace",
+			query: Query{GroupBy: GroupByNamespace},
+			expected: []Result{
+				{Namespace: "prod", Bytes: 1720, Packets: 12, Records: 6},
+				{Namespace: "dev", Bytes: 120, Packets: 4, Records: 2},
+			},
+		},
+		{
+			name:  "filtered by Pod and Service",
+			query: Query{GroupBy: GroupByService, Pod: "client", Service: "prod/web"},
+			expected: []Result{
+				{Service: "prod/web:http", Bytes: 100, Packets: 2, Records: 1},
+			},
+		},
+		{
+			name:  "policies in a Namespace",
+			query: Query{GroupBy: GroupByPolicy, Namespace: "prod", Pod: "prod/db"},
+			expected: []Result{
+				{Policies: []string{"prod/allow-web"}, Bytes: 600, Packets: 6, Records: 3},
+				{Policies: []string{"deny-dev"}, Denied: true, Bytes: 20, Packets: 2, Records: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := store.Query(tt.query, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, results)
+		})
+	}
+
+	_, err := store.Query(Query{Window: time.Hour}, now)
+	assert.Error(t, err)
+	_, err = store.Query(Query{GroupBy: "node"}, now)
+	assert.Error(t, err)
+}
+
+func TestStorePrune(t *testing.T) {
+	store := NewStore(time.Minute, 3)
+	now := time.Now()
+	flow := testFlow{srcIP: "10.10.0.1", dstIP: "10.10.1.1", srcNS: "prod", srcPod: "web", bytes: 1}
+	for i := 0; i < 5; i++ {
+		flow.srcPort = uint16(30000 + i)
+		store.Add(newTestRecord(t, flow), now)
+	}
+	// Only the latest maxEntries entries are kept.
+	results, err := store.Query(Query{}, now)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, uint16(30002), results[0].SourcePort)
+
+	// The entries are dropped when they get out of the window.
+	results, err = store.Query(Query{}, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Empty(t, store.entries)
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowrecord
+
+import (
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// FlowRecord is a snapshot of an aggregated flow record. The IPFIX record maintained by the
+// aggregation process is updated in place (e.g. the delta counters are reset after each export),
+// so exporters which do not send the record synchronously must work on a FlowRecord instead.
+type FlowRecord struct {
+	FlowStartSeconds                time.Time `json:"flowStartSeconds"`
+	FlowEndSeconds                  time.Time `json:"flowEndSeconds"`
+	FlowEndReason                   uint8     `json:"flowEndReason"`
+	SourceIP                        string    `json:"sourceIP"`
+	DestinationIP                   string    `json:"destinationIP"`
+	SourceTransportPort             uint16    `json:"sourceTransportPort"`
+	DestinationTransportPort        uint16    `json:"destinationTransportPort"`
+	ProtocolIdentifier              uint8     `json:"protocolIdentifier"`
+	PacketTotalCount                uint64    `json:"packetTotalCount"`
+	OctetTotalCount                 uint64    `json:"octetTotalCount"`
+	PacketDeltaCount                uint64    `json:"packetDeltaCount"`
+	OctetDeltaCount                 uint64    `json:"octetDeltaCount"`
+	ReversePacketTotalCount         uint64    `json:"reversePacketTotalCount"`
+	ReverseOctetTotalCount          uint64    `json:"reverseOctetTotalCount"`
+	ReversePacketDeltaCount         uint64    `json:"reversePacketDeltaCount"`
+	ReverseOctetDeltaCount          uint64    `json:"reverseOctetDeltaCount"`
+	SourcePodName                   string    `json:"sourcePodName"`
+	SourcePodNamespace              string    `json:"sourcePodNamespace"`
+	SourceNodeName                  string    `json:"sourceNodeName"`
+	DestinationPodName              string    `json:"destinationPodName"`
+	DestinationPodNamespace         string    `json:"destinationPodNamespace"`
+	DestinationNodeName             string    `json:"destinationNodeName"`
+	DestinationClusterIP            string    `json:"destinationClusterIP"`
+	DestinationServicePort          uint16    `json:"destinationServicePort"`
+	DestinationServicePortName      string    `json:"destinationServicePortName"`
+	IngressNetworkPolicyName        string    `json:"ingressNetworkPolicyName"`
+	IngressNetworkPolicyNamespace   string    `json:"ingressNetworkPolicyNamespace"`
+	IngressNetworkPolicyType        uint8     `json:"ingressNetworkPolicyType"`
+	IngressNetworkPolicyRuleName    string    `json:"ingressNetworkPolicyRuleName"`
+	IngressNetworkPolicyRuleAction  uint8     `json:"ingressNetworkPolicyRuleAction"`
+	EgressNetworkPolicyName         string    `json:"egressNetworkPolicyName"`
+	EgressNetworkPolicyNamespace    string    `json:"egressNetworkPolicyNamespace"`
+	EgressNetworkPolicyType         uint8     `json:"egressNetworkPolicyType"`
+	EgressNetworkPolicyRuleName     string    `json:"egressNetworkPolicyRuleName"`
+	EgressNetworkPolicyRuleAction   uint8     `json:"egressNetworkPolicyRuleAction"`
+	TCPState                        string    `json:"tcpState"`
+	FlowType                        uint8     `json:"flowType"`
+	SourcePodLabels                 string    `json:"sourcePodLabels"`
+	DestinationPodLabels            string    `json:"destinationPodLabels"`
+	DestinationServiceType          string    `json:"destinationServiceType"`
+	EgressName                      string    `json:"egressName"`
+	EgressIP                        string    `json:"egressIP"`
+	TCPHandshakeRTT                 int32     `json:"tcpHandshakeRTT"`
+	TCPHandshakeRetransmissionCount uint16    `json:"tcpHandshakeRetransmissionCount"`
+	TCPResetCount                   uint16    `json:"tcpResetCount"`
+}
+
+// GetFlowRecord copies the values of the known Information Elements of an aggregated IPFIX
+// record into a new FlowRecord. Elements which are not present in the record are left empty.
+func GetFlowRecord(record ipfixentities.Record) *FlowRecord {
+	r := &FlowRecord{}
+	for _, ie := range record.GetOrderedElementList() {
+		if ie == nil {
+			continue
+		}
+		switch ie.GetName() {
+		case "flowStartSeconds":
+			r.FlowStartSeconds = time.Unix(int64(ie.GetUnsigned32Value()), 0).UTC()
+		case "flowEndSeconds":
+			r.FlowEndSeconds = time.Unix(int64(ie.GetUnsigned32Value()), 0).UTC()
+		case "flowEndReason":
+			r.FlowEndReason = ie.GetUnsigned8Value()
+		case "sourceIPv4Address", "sourceIPv6Address":
+			r.SourceIP = ie.GetIPAddressValue().String()
+		case "destinationIPv4Address", "destinationIPv6Address":
+			r.DestinationIP = ie.GetIPAddressValue().String()
+		case "sourceTransportPort":
+			r.SourceTransportPort = ie.GetUnsigned16Value()
+		case "destinationTransportPort":
+			r.DestinationTransportPort = ie.GetUnsigned16Value()
+		case "protocolIdentifier":
+			r.ProtocolIdentifier = ie.GetUnsigned8Value()
+		case "packetTotalCount":
+			r.PacketTotalCount = ie.GetUnsigned64Value()
+		case "octetTotalCount":
+			r.OctetTotalCount = ie.GetUnsigned64Value()
+		case "packetDeltaCount":
+			r.PacketDeltaCount = ie.GetUnsigned64Value()
+		case "octetDeltaCount":
+			r.OctetDeltaCount = ie.GetUnsigned64Value()
+		case "reversePacketTotalCount":
+			r.ReversePacketTotalCount = ie.GetUnsigned64Value()
+		case "reverseOctetTotalCount":
+			r.ReverseOctetTotalCount = ie.GetUnsigned64Value()
+		case "reversePacketDeltaCount":
+			r.ReversePacketDeltaCount = ie.GetUnsigned64Value()
+		case "reverseOctetDeltaCount":
+			r.ReverseOctetDeltaCount = ie.GetUnsigned64Value()
+		case "sourcePodName":
+			r.SourcePodName = ie.GetStringValue()
+		case "sourcePodNamespace":
+			r.SourcePodNamespace = ie.GetStringValue()
+		case "sourceNodeName":
+			r.SourceNodeName = ie.GetStringValue()
+		case "destinationPodName":
+			r.DestinationPodName = ie.GetStringValue()
+		case "destinationPodNamespace":
+			r.DestinationPodNamespace = ie.GetStringValue()
+		case "destinationNodeName":
+			r.DestinationNodeName = ie.GetStringValue()
+		case "destinationClusterIPv4", "destinationClusterIPv6":
+			// An unspecified address means that the flow is not destined to a Service.
+			if ip := ie.GetIPAddressValue(); ip != nil && !ip.IsUnspecified() {
+				r.DestinationClusterIP = ip.String()
+			}
+		case "destinationServicePort":
+			r.DestinationServicePort = ie.GetUnsigned16Value()
+		case "destinationServicePortName":
+			r.DestinationServicePortName = ie.GetStringValue()
+		case "ingressNetworkPolicyName":
+			r.IngressNetworkPolicyName = ie.GetStringValue()
+		case "ingressNetworkPolicyNamespace":
+			r.IngressNetworkPolicyNamespace = ie.GetStringValue()
+		case "ingressNetworkPolicyType":
+			r.IngressNetworkPolicyType = ie.GetUnsigned8Value()
+		case "ingressNetworkPolicyRuleName":
+			r.IngressNetworkPolicyRuleName = ie.GetStringValue()
+		case "ingressNetworkPolicyRuleAction":
+			r.IngressNetworkPolicyRuleAction = ie.GetUnsigned8Value()
+		case "egressNetworkPolicyName":
+			r.EgressNetworkPolicyName = ie.GetStringValue()
+		case "egressNetworkPolicyNamespace":
+			r.EgressNetworkPolicyNamespace = ie.GetStringValue()
+		case "egressNetworkPolicyType":
+			r.EgressNetworkPolicyType = ie.GetUnsigned8Value()
+		case "egressNetworkPolicyRuleName":
+			r.EgressNetworkPolicyRuleName = ie.GetStringValue()
+		case "egressNetworkPolicyRuleAction":
+			r.EgressNetworkPolicyRuleAction = ie.GetUnsigned8Value()
+		case "tcpState":
+			r.TCPState = ie.GetStringValue()
+		case "flowType":
+			r.FlowType = ie.GetUnsigned8Value()
+		case "sourcePodLabels":
+			r.SourcePodLabels = ie.GetStringValue()
+		case "destinationPodLabels":
+			r.DestinationPodLabels = ie.GetStringValue()
+		case "destinationServiceType":
+			r.DestinationServiceType = ie.GetStringValue()
+		case "egressName":
+			r.EgressName = ie.GetStringValue()
+		case "egressIP":
+			r.EgressIP = ie.GetStringValue()
+		case "tcpHandshakeRTT":
+			r.TCPHandshakeRTT = ie.GetSigned32Value()
+		case "tcpHandshakeRetransmissionCount":
+			r.TCPHandshakeRetransmissionCount = ie.GetUnsigned16Value()
+		case "tcpResetCount":
+			r.TCPResetCount = ie.GetUnsigned16Value()
+		}
+	}
+	return r
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowrecord
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+)
+
+func init() {
+	ipfixregistry.LoadRegistry()
+}
+
+func TestGetFlowRecord(t *testing.T) {
+	newElement := func(name string, enterpriseID uint32) *ipfixentities.InfoElement {
+		element, err := ipfixregistry.GetInfoElement(name, enterpriseID)
+		require.NoError(t, err)
+		return element
+	}
+	elements := []ipfixentities.InfoElementWithValue{
+		ipfixentities.NewDateTimeSecondsInfoElement(newElement("flowStartSeconds", ipfixregistry.IANAEnterpriseID), 1637706961),
+		ipfixentities.NewDateTimeSecondsInfoElement(newElement("flowEndSeconds", ipfixregistry.IANAEnterpriseID), 1637706973),
+		ipfixentities.NewUnsigned8InfoElement(newElement("flowEndReason", ipfixregistry.IANAEnterpriseID), 3),
+		ipfixentities.NewIPAddressInfoElement(newElement("sourceIPv4Address", ipfixregistry.IANAEnterpriseID), net.ParseIP("10.10.0.79")),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationIPv4Address", ipfixregistry.IANAEnterpriseID), net.ParseIP("10.10.0.80")),
+		ipfixentities.NewUnsigned16InfoElement(newElement("sourceTransportPort", ipfixregistry.IANAEnterpriseID), 44752),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationTransportPort", ipfixregistry.IANAEnterpriseID), 5201),
+		ipfixentities.NewUnsigned8InfoElement(newElement("protocolIdentifier", ipfixregistry.IANAEnterpriseID), 6),
+		ipfixentities.NewUnsigned64InfoElement(newElement("packetTotalCount", ipfixregistry.IANAEnterpriseID), 823188),
+		ipfixentities.NewUnsigned64InfoElement(newElement("octetDeltaCount", ipfixregistry.IANAEnterpriseID), 1000),
+		ipfixentities.NewUnsigned64InfoElement(newElement("reverseOctetTotalCount", ipfixregistry.IANAReversedEnterpriseID), 2000),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodName", ipfixregistry.AntreaEnterpriseID), "perftest-a"),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodNamespace", ipfixregistry.AntreaEnterpriseID), "antrea-test"),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationClusterIPv4", ipfixregistry.AntreaEnterpriseID), net.IPv4zero),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationServicePort", ipfixregistry.AntreaEnterpriseID), 5201),
+		ipfixentities.NewUnsigned8InfoElement(newElement("ingressNetworkPolicyRuleAction", ipfixregistry.AntreaEnterpriseID), 1),
+		ipfixentities.NewStringInfoElement(newElement("tcpState", ipfixregistry.AntreaEnterpriseID), "TIME_WAIT"),
+		ipfixentities.NewUnsigned8InfoElement(newElement("flowType", ipfixregistry.AntreaEnterpriseID), 1),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodLabels", ipfixregistry.AntreaEnterpriseID), `{"app":"perftool"}`),
+		// These elements are not part of the go-ipfix registry yet.
+		ipfixentities.NewStringInfoElement(ipfixentities.NewInfoElement("destinationServiceType", 0, ipfixentities.String, ipfixregistry.AntreaEnterpriseID, 65535), "NodePort"),
+		ipfixentities.NewStringInfoElement(ipfixentities.NewInfoElement("egressName", 0, ipfixentities.String, ipfixregistry.AntreaEnterpriseID, 65535), "egress-a"),
+		ipfixentities.NewStringInfoElement(ipfixentities.NewInfoElement("egressIP", 0, ipfixentities.String, ipfixregistry.AntreaEnterpriseID, 65535), "172.18.0.100"),
+		ipfixentities.NewSigned32InfoElement(ipfixentities.NewInfoElement("tcpHandshakeRTT", 0, ipfixentities.Signed32, ipfixregistry.AntreaEnterpriseID, 4), 1500),
+		ipfixentities.NewUnsigned16InfoElement(ipfixentities.NewInfoElement("tcpHandshakeRetransmissionCount", 0, ipfixentities.Unsigned16, ipfixregistry.AntreaEnterpriseID, 2), 1),
+		ipfixentities.NewUnsigned16InfoElement(ipfixentities.NewInfoElement("tcpResetCount", 0, ipfixentities.Unsigned16, ipfixregistry.AntreaEnterpriseID, 2), 2),
+	}
+	record := ipfixentities.NewDataRecord(256, len(elements), 0, true)
+	for _, element := range elements {
+		require.NoError(t, record.AddInfoElement(element))
+	}
+
+	expected := &FlowRecord{
+		FlowStartSeconds:                time.Unix(1637706961, 0).UTC(),
+		FlowEndSeconds:                  time.Unix(1637706973, 0).UTC(),
+		FlowEndReason:                   3,
+		SourceIP:                        "10.10.0.79",
+		DestinationIP:                   "10.10.0.80",
+		SourceTransportPort:             44752,
+		DestinationTransportPort:        5201,
+		ProtocolIdentifier:              6,
+		PacketTotalCount:                823188,
+		OctetDeltaCount:                 1000,
+		ReverseOctetTotalCount:          2000,
+		SourcePodName:                   "perftest-a",
+		SourcePodNamespace:              "antrea-test",
+		DestinationServicePort:          5201,
+		IngressNetworkPolicyRuleAction:  1,
+		TCPState:                        "TIME_WAIT",
+		FlowType:                        1,
+		SourcePodLabels:                 `{"app":"perftool"}`,
+		DestinationServiceType:          "NodePort",
+		EgressName:                      "egress-a",
+		EgressIP:                        "172.18.0.100",
+		TCPHandshakeRTT:                 1500,
+		TCPHandshakeRetransmissionCount: 1,
+		TCPResetCount:                   2,
+	}
+	assert.Equal(t, expected, GetFlowRecord(record))
+}
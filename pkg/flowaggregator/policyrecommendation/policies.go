@@ -0,0 +1,363 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyrecommendation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+)
+
+// PolicyType is the type of the recommended policies.
+type PolicyType string
+
+const (
+	// PolicyTypeK8s recommends Kubernetes NetworkPolicies.
+	PolicyTypeK8s PolicyType = "k8s"
+	// PolicyTypeANP recommends Antrea NetworkPolicies.
+	PolicyTypeANP PolicyType = "anp"
+	// PolicyTypeACNP recommends Antrea ClusterNetworkPolicies.
+	PolicyTypeACNP PolicyType = "acnp"
+)
+
+const (
+	// namespaceNameLabel is set by Kubernetes on all Namespaces since v1.21.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+	// recommendedPolicyPriority is the priority of the recommended Antrea-native policies
+	// in their Tier. They do not overlap, as each of them applies to a different workload.
+	recommendedPolicyPriority = 5
+	// recommendedTierPriority is the priority of the Tier created for the recommended
+	// policies, which is the lowest priority of a user-created Tier, so that the policies
+	// of all the other Tiers but the application and baseline Tiers take precedence.
+	recommendedTierPriority = 249
+)
+
+// defaultTiers are created by Antrea, and do not need to be recommended.
+var defaultTiers = map[string]bool{
+	"emergency":   true,
+	"securityops": true,
+	"networkops":  true,
+	"platform":    true,
+	"application": true,
+	"baseline":    true,
+}
+
+// workloadNameLabels are the labels whose value is used to name the policies of a workload,
+// by order of preference.
+var workloadNameLabels = []string{"app.kubernetes.io/name", "app", "k8s-app", "name"}
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Options select the recommended policies.
+type Options struct {
+	Type PolicyType
+	// Namespace only recommends the policies of the workloads in this Namespace. The
+	// policies of the workloads of all Namespaces are recommended when it is empty.
+	Namespace string
+	// Tier is the Tier of the recommended Antrea-native policies. The Tier is recommended
+	// too, with a low priority, when it is not created by Antrea.
+	Tier string
+}
+
+func (o *Options) validate() error {
+	switch o.Type {
+	case PolicyTypeK8s:
+		if o.Tier != "" {
+			return fmt.Errorf("tier is only supported by Antrea-native policies")
+		}
+	case PolicyTypeANP, PolicyTypeACNP:
+	default:
+		return fmt.Errorf("unsupported policy type %s", o.Type)
+	}
+	return nil
+}
+
+func (o *Options) selects(e endpoint) bool {
+	return o.Namespace == "" || e.namespace == o.Namespace
+}
+
+// Recommendation is the result of a policy recommendation.
+type Recommendation struct {
+	// Objects are the recommended Tier and policies.
+	Objects []runtime.Object
+	// Warnings are the reasons why some observed connections are not allowed by the
+	// recommended policies.
+	Warnings []string
+}
+
+// YAML returns the warnings as comments, followed by the objects as YAML documents, which
+// can be applied with kubectl.
+func (r *Recommendation) YAML() ([]byte, error) {
+	var b bytes.Buffer
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&b, "# Warning: %s\n", warning)
+	}
+	for _, obj := range r.Objects {
+		// The empty fields, such as the creation timestamp and the status, are removed
+		// from the output.
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, err
+		}
+		delete(object, "status")
+		data, err = yaml.Marshal(pruneEmptyFields(object))
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("---\n")
+		b.Write(data)
+	}
+	return b.Bytes(), nil
+}
+
+func pruneEmptyFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field = pruneEmptyFields(field); field == nil {
+				delete(v, key)
+			} else {
+				v[key] = field
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = pruneEmptyFields(v[i])
+		}
+	}
+	return value
+}
+
+func newPolicies(workloads []*workload, options Options) []runtime.Object {
+	var objects []runtime.Object
+	if options.Tier != "" && !defaultTiers[options.Tier] {
+		objects = append(objects, &crdv1alpha1.Tier{
+			TypeMeta:   metav1.TypeMeta{APIVersion: crdv1alpha1.SchemeGroupVersion.String(), Kind: "Tier"},
+			ObjectMeta: metav1.ObjectMeta{Name: options.Tier},
+			Spec: crdv1alpha1.TierSpec{
+				Priority:    recommendedTierPriority,
+				Description: "Policies recommended from the observed flows",
+			},
+		})
+	}
+	for _, w := range workloads {
+		switch options.Type {
+		case PolicyTypeK8s:
+			objects = append(objects, newK8sNetworkPolicy(w))
+		case PolicyTypeANP:
+			objects = append(objects, newAntreaNetworkPolicy(w, options.Tier))
+		case PolicyTypeACNP:
+			objects = append(objects, newAntreaClusterNetworkPolicy(w, options.Tier))
+		}
+	}
+	return objects
+}
+
+// policyName returns a name for the policy of the workload, made of the value of its
+// name label and of a hash of its Namespace and labels, which makes it unique.
+func policyName(w endpoint, includeNamespace bool) string {
+	podLabels, _ := labels.ConvertSelectorToLabelsMap(w.labels)
+	name := "pods"
+	for _, key := range workloadNameLabels {
+		if value := podLabels[key]; value != "" {
+			name = strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(value), "-"), "-")
+			break
+		}
+	}
+	if includeNamespace {
+		name = w.namespace + "-" + name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(w.String()))
+	return fmt.Sprintf("recommend-%s-%08x", name, h.Sum32())
+}
+
+func podSelector(e endpoint) *metav1.LabelSelector {
+	podLabels, _ := labels.ConvertSelectorToLabelsMap(e.labels)
+	return &metav1.LabelSelector{MatchLabels: podLabels}
+}
+
+func namespaceSelector(namespace string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: namespace}}
+}
+
+func ipCIDR(ip string) string {
+	if net.ParseIP(ip).To4() != nil {
+		return ip + "/32"
+	}
+	return ip + "/128"
+}
+
+func protocol(p port) *corev1.Protocol {
+	var protocol corev1.Protocol
+	switch p.protocol {
+	case protocolTCP:
+		protocol = corev1.ProtocolTCP
+	case protocolUDP:
+		protocol = corev1.ProtocolUDP
+	case protocolSCTP:
+		protocol = corev1.ProtocolSCTP
+	}
+	return &protocol
+}
+
+func portNumber(p port) *intstr.IntOrString {
+	number := intstr.FromInt(int(p.port))
+	return &number
+}
+
+func newK8sNetworkPolicy(w *workload) *networkingv1.NetworkPolicy {
+	newPeer := func(e endpoint) networkingv1.NetworkPolicyPeer {
+		if !e.isPod() {
+			return networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: ipCIDR(e.ip)}}
+		}
+		peer := networkingv1.NetworkPolicyPeer{PodSelector: podSelector(e)}
+		if e.namespace != w.endpoint.namespace {
+			peer.NamespaceSelector = namespaceSelector(e.namespace)
+		}
+		return peer
+	}
+	newPorts := func(ports map[port]struct{}) []networkingv1.NetworkPolicyPort {
+		var policyPorts []networkingv1.NetworkPolicyPort
+		for _, p := range sortedPorts(ports) {
+			policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{Protocol: protocol(p), Port: portNumber(p)})
+		}
+		return policyPorts
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: policyName(w.endpoint, false), Namespace: w.endpoint.namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *podSelector(w.endpoint),
+			// Both directions are isolated, so that only the observed connections are
+			// allowed.
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	for _, peer := range sortedPeers(w.ingress) {
+		policy.Spec.Ingress = append(policy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  []networkingv1.NetworkPolicyPeer{newPeer(peer)},
+			Ports: newPorts(w.ingress[peer]),
+		})
+	}
+	for _, peer := range sortedPeers(w.egress) {
+		policy.Spec.Egress = append(policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			To:    []networkingv1.NetworkPolicyPeer{newPeer(peer)},
+			Ports: newPorts(w.egress[peer]),
+		})
+	}
+	return policy
+}
+
+// newAntreaRules returns the rules allowing the connections of the workload, followed by
+// the rules dropping all the other connections, as Antrea-native policies do not isolate
+// the Pods they apply to.
+func newAntreaRules(w *workload, clusterScoped bool) (ingress, egress []crdv1alpha1.Rule) {
+	allow, drop := crdv1alpha1.RuleActionAllow, crdv1alpha1.RuleActionDrop
+	newPeer := func(e endpoint) crdv1alpha1.NetworkPolicyPeer {
+		if !e.isPod() {
+			return crdv1alpha1.NetworkPolicyPeer{IPBlock: &crdv1alpha1.IPBlock{CIDR: ipCIDR(e.ip)}}
+		}
+		peer := crdv1alpha1.NetworkPolicyPeer{PodSelector: podSelector(e)}
+		// The Pod selector of a ClusterNetworkPolicy peer selects the Pods of all
+		// Namespaces.
+		if clusterScoped || e.namespace != w.endpoint.namespace {
+			peer.NamespaceSelector = namespaceSelector(e.namespace)
+		}
+		return peer
+	}
+	newPorts := func(ports map[port]struct{}) []crdv1alpha1.NetworkPolicyPort {
+		var policyPorts []crdv1alpha1.NetworkPolicyPort
+		for _, p := range sortedPorts(ports) {
+			policyPorts = append(policyPorts, crdv1alpha1.NetworkPolicyPort{Protocol: protocol(p), Port: portNumber(p)})
+		}
+		return policyPorts
+	}
+
+	for i, peer := range sortedPeers(w.ingress) {
+		ingress = append(ingress, crdv1alpha1.Rule{
+			Name:   fmt.Sprintf("allow-ingress-%d", i),
+			Action: &allow,
+			From:   []crdv1alpha1.NetworkPolicyPeer{newPeer(peer)},
+			Ports:  newPorts(w.ingress[peer]),
+		})
+	}
+	ingress = append(ingress, crdv1alpha1.Rule{Name: "drop-ingress", Action: &drop})
+	for i, peer := range sortedPeers(w.egress) {
+		egress = append(egress, crdv1alpha1.Rule{
+			Name:   fmt.Sprintf("allow-egress-%d", i),
+			Action: &allow,
+			To:     []crdv1alpha1.NetworkPolicyPeer{newPeer(peer)},
+			Ports:  newPorts(w.egress[peer]),
+		})
+	}
+	egress = append(egress, crdv1alpha1.Rule{Name: "drop-egress", Action: &drop})
+	return ingress, egress
+}
+
+func newAntreaNetworkPolicy(w *workload, tier string) *crdv1alpha1.NetworkPolicy {
+	ingress, egress := newAntreaRules(w, false)
+	return &crdv1alpha1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: crdv1alpha1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: policyName(w.endpoint, false), Namespace: w.endpoint.namespace},
+		Spec: crdv1alpha1.NetworkPolicySpec{
+			Tier:      tier,
+			Priority:  recommendedPolicyPriority,
+			AppliedTo: []crdv1alpha1.NetworkPolicyPeer{{PodSelector: podSelector(w.endpoint)}},
+			Ingress:   ingress,
+			Egress:    egress,
+		},
+	}
+}
+
+func newAntreaClusterNetworkPolicy(w *workload, tier string) *crdv1alpha1.ClusterNetworkPolicy {
+	ingress, egress := newAntreaRules(w, true)
+	return &crdv1alpha1.ClusterNetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: crdv1alpha1.SchemeGroupVersion.String(), Kind: "ClusterNetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: policyName(w.endpoint, true)},
+		Spec: crdv1alpha1.ClusterNetworkPolicySpec{
+			Tier:     tier,
+			Priority: recommendedPolicyPriority,
+			AppliedTo: []crdv1alpha1.NetworkPolicyPeer{{
+				PodSelector:       podSelector(w.endpoint),
+				NamespaceSelector: namespaceSelector(w.endpoint.namespace),
+			}},
+			Ingress: ingress,
+			Egress:  egress,
+		},
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policyrecommendation keeps the connections observed in the flow records exported
+// by the Flow Aggregator, and recommends the NetworkPolicies allowing exactly these
+// connections for each workload. A workload is the set of Pods of a Namespace sharing the
+// same labels, ignoring the labels which differ between the Pods of a controller, such as
+// pod-template-hash.
+package policyrecommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	protocolTCP  = 6
+	protocolUDP  = 17
+	protocolSCTP = 132
+)
+
+// ignoredLabels are set by the controllers on their Pods, and differ between Pods of the
+// same workload.
+var ignoredLabels = []string{
+	"pod-template-hash",
+	"controller-revision-hash",
+	"pod-template-generation",
+	"statefulset.kubernetes.io/pod-name",
+}
+
+// endpoint is the source or the destination of a connection: either a Pod, identified by
+// its Namespace and the canonical form of its labels, or an IP address.
+type endpoint struct {
+	namespace string
+	labels    string
+	ip        string
+}
+
+func (e endpoint) isPod() bool {
+	return e.ip == ""
+}
+
+func (e endpoint) String() string {
+	if e.isPod() {
+		return e.namespace + "/" + e.labels
+	}
+	return e.ip
+}
+
+// connection is a connection observed in the flow records. The source port is ignored, as
+// it does not matter to NetworkPolicies.
+type connection struct {
+	source      endpoint
+	destination endpoint
+	protocol    uint8
+	port        uint16
+}
+
+// Recommender keeps the connections observed in the exported flow records, until they
+// have not been observed for window.
+type Recommender struct {
+	window         time.Duration
+	maxConnections int
+	mutex          sync.Mutex
+	// connections maps the observed connections to the last time they were observed.
+	connections map[connection]time.Time
+	// unselectablePods maps the Pods which cannot be selected by labels, as <Namespace>/<Name>,
+	// to the last time one of their connections was observed.
+	unselectablePods map[string]time.Time
+	// truncated is true when connections were ignored because maxConnections was reached.
+	truncated bool
+}
+
+func NewRecommender(window time.Duration, maxConnections int) *Recommender {
+	return &Recommender{
+		window:           window,
+		maxConnections:   maxConnections,
+		connections:      make(map[connection]time.Time),
+		unselectablePods: make(map[string]time.Time),
+	}
+}
+
+// Add adds the connection of an exported record. The connections denied by a
+// NetworkPolicy, and those whose protocol cannot be selected by a NetworkPolicy port,
+// are ignored.
+func (r *Recommender) Add(record ipfixentities.Record, now time.Time) {
+	getString := func(name string) string {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			return ie.GetStringValue()
+		}
+		return ""
+	}
+	getUnsigned8 := func(name string) uint8 {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			return ie.GetUnsigned8Value()
+		}
+		return 0
+	}
+	getAddress := func(ipv4Name, ipv6Name string) string {
+		if ie, _, exist := record.GetInfoElementWithValue(ipv4Name); exist {
+			return ie.GetIPAddressValue().String()
+		}
+		if ie, _, exist := record.GetInfoElementWithValue(ipv6Name); exist {
+			return ie.GetIPAddressValue().String()
+		}
+		return ""
+	}
+	isDenied := func(action uint8) bool {
+		return action == registry.NetworkPolicyRuleActionDrop || action == registry.NetworkPolicyRuleActionReject
+	}
+
+	if isDenied(getUnsigned8("ingressNetworkPolicyRuleAction")) || isDenied(getUnsigned8("egressNetworkPolicyRuleAction")) {
+		return
+	}
+	conn := connection{protocol: getUnsigned8("protocolIdentifier")}
+	switch conn.protocol {
+	case protocolTCP, protocolUDP, protocolSCTP:
+	default:
+		return
+	}
+	if ie, _, exist := record.GetInfoElementWithValue("destinationTransportPort"); exist {
+		conn.port = ie.GetUnsigned16Value()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var selectable bool
+	conn.source, selectable = r.newEndpointLocked(getString("sourcePodNamespace"), getString("sourcePodName"),
+		getString("sourcePodLabels"), getAddress("sourceIPv4Address", "sourceIPv6Address"), now)
+	if !selectable {
+		return
+	}
+	conn.destination, selectable = r.newEndpointLocked(getString("destinationPodNamespace"), getString("destinationPodName"),
+		getString("destinationPodLabels"), getAddress("destinationIPv4Address", "destinationIPv6Address"), now)
+	if !selectable {
+		return
+	}
+	if !conn.source.isPod() && !conn.destination.isPod() {
+		return
+	}
+	if _, exist := r.connections[conn]; !exist && len(r.connections) >= r.maxConnections {
+		r.pruneLocked(now)
+		if len(r.connections) >= r.maxConnections {
+			if !r.truncated {
+				klog.InfoS("Maximum number of connections for policy recommendation reached, ignoring new connections", "maxConnections", r.maxConnections)
+			}
+			r.truncated = true
+			return
+		}
+	}
+	r.connections[conn] = now
+}
+
+// newEndpointLocked returns the endpoint of a Pod, or of an IP address when the Pod name
+// is empty. It returns false when the Pod cannot be selected by labels.
+func (r *Recommender) newEndpointLocked(namespace, name, labelsJSON, ip string, now time.Time) (endpoint, bool) {
+	if name == "" {
+		return endpoint{ip: ip}, true
+	}
+	podLabels := make(map[string]string)
+	if labelsJSON != "" {
+		if err := json.Unmarshal([]byte(labelsJSON), &podLabels); err != nil {
+			klog.V(2).InfoS("Invalid Pod labels in flow record", "pod", namespace+"/"+name, "labels", labelsJSON)
+		}
+	}
+	for _, key := range ignoredLabels {
+		delete(podLabels, key)
+	}
+	if len(podLabels) == 0 {
+		r.unselectablePods[namespace+"/"+name] = now
+		return endpoint{}, false
+	}
+	return endpoint{namespace: namespace, labels: labels.Set(podLabels).String()}, true
+}
+
+func (r *Recommender) pruneLocked(now time.Time) {
+	for conn, lastSeen := range r.connections {
+		if now.Sub(lastSeen) > r.window {
+			delete(r.connections, conn)
+		}
+	}
+	for pod, lastSeen := range r.unselectablePods {
+		if now.Sub(lastSeen) > r.window {
+			delete(r.unselectablePods, pod)
+		}
+	}
+}
+
+// port is a protocol and a destination port allowed by a rule.
+type port struct {
+	protocol uint8
+	port     uint16
+}
+
+// workload is the set of connections from and to the Pods of a workload, grouped by peer.
+type workload struct {
+	endpoint endpoint
+	ingress  map[endpoint]map[port]struct{}
+	egress   map[endpoint]map[port]struct{}
+}
+
+// Recommend returns the recommended policies for the connections observed during the
+// window.
+func (r *Recommender) Recommend(options Options, now time.Time) (*Recommendation, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	workloads := make(map[endpoint]*workload)
+	getWorkload := func(e endpoint) *workload {
+		w, exist := workloads[e]
+		if !exist {
+			w = &workload{
+				endpoint: e,
+				ingress:  make(map[endpoint]map[port]struct{}),
+				egress:   make(map[endpoint]map[port]struct{}),
+			}
+			workloads[e] = w
+		}
+		return w
+	}
+	addPort := func(rules map[endpoint]map[port]struct{}, peer endpoint, p port) {
+		if rules[peer] == nil {
+			rules[peer] = make(map[port]struct{})
+		}
+		rules[peer][p] = struct{}{}
+	}
+
+	recommendation := &Recommendation{}
+	r.mutex.Lock()
+	r.pruneLocked(now)
+	for conn := range r.connections {
+		p := port{protocol: conn.protocol, port: conn.port}
+		if conn.destination.isPod() && options.selects(conn.destination) {
+			addPort(getWorkload(conn.destination).ingress, conn.source, p)
+		}
+		if conn.source.isPod() && options.selects(conn.source) {
+			addPort(getWorkload(conn.source).egress, conn.destination, p)
+		}
+	}
+	for pod := range r.unselectablePods {
+		if options.Namespace == "" || strings.HasPrefix(pod, options.Namespace+"/") {
+			recommendation.Warnings = append(recommendation.Warnings,
+				fmt.Sprintf("Pod %s has no labels selecting it, its connections are not allowed by the recommended policies", pod))
+		}
+	}
+	if r.truncated {
+		recommendation.Warnings = append(recommendation.Warnings,
+			fmt.Sprintf("The connections observed after %d distinct connections were ignored", r.maxConnections))
+	}
+	r.mutex.Unlock()
+	sort.Strings(recommendation.Warnings)
+
+	sortedWorkloads := make([]*workload, 0, len(workloads))
+	for _, w := range workloads {
+		sortedWorkloads = append(sortedWorkloads, w)
+	}
+	sort.Slice(sortedWorkloads, func(i, j int) bool {
+		return endpointLess(sortedWorkloads[i].endpoint, sortedWorkloads[j].endpoint)
+	})
+	recommendation.Objects = newPolicies(sortedWorkloads, options)
+	return recommendation, nil
+}
+
+func endpointLess(a, b endpoint) bool {
+	if a.isPod() != b.isPod() {
+		return a.isPod()
+	}
+	if a.namespace != b.namespace {
+		return a.namespace < b.namespace
+	}
+	if a.labels != b.labels {
+		return a.labels < b.labels
+	}
+	ipA, ipB := net.ParseIP(a.ip), net.ParseIP(b.ip)
+	if ipA != nil && ipB != nil && len(ipA.To4()) != len(ipB.To4()) {
+		// IPv4 addresses first.
+		return ipA.To4() != nil
+	}
+	return a.ip < b.ip
+}
+
+// sortedPeers returns the peers of the rules, sorted like the workloads.
+func sortedPeers(rules map[endpoint]map[port]struct{}) []endpoint {
+	peers := make([]endpoint, 0, len(rules))
+	for peer := range rules {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return endpointLess(peers[i], peers[j]) })
+	return peers
+}
+
+func sortedPorts(ports map[port]struct{}) []port {
+	list := make([]port, 0, len(ports))
+	for p := range ports {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].protocol != list[j].protocol {
+			return list[i].protocol < list[j].protocol
+		}
+		return list[i].port < list[j].port
+	})
+	return list
+}
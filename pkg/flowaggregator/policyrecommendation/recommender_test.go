@@ -0,0 +1,286 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyrecommendation
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+)
+
+type testFlow struct {
+	srcIP, dstIP      string
+	srcNS, srcPod     string
+	srcLabels         string
+	dstNS, dstPod     string
+	dstLabels         string
+	protocol          uint8
+	dstPort           uint16
+	ingressRuleAction uint8
+}
+
+func newTestRecord(t *testing.T, f testFlow) ipfixentities.Record {
+	ipfixregistry.LoadRegistry()
+	newElement := func(name string, enterpriseID uint32) *ipfixentities.InfoElement {
+		element, err := ipfixregistry.GetInfoElement(name, enterpriseID)
+		require.NoError(t, err)
+		return element
+	}
+	iana, antrea := ipfixregistry.IANAEnterpriseID, ipfixregistry.AntreaEnterpriseID
+	elements := []ipfixentities.InfoElementWithValue{
+		ipfixentities.NewIPAddressInfoElement(newElement("sourceIPv4Address", iana), net.ParseIP(f.srcIP)),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationIPv4Address", iana), net.ParseIP(f.dstIP)),
+		ipfixentities.NewUnsigned16InfoElement(newElement("sourceTransportPort", iana), 34567),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationTransportPort", iana), f.dstPort),
+		ipfixentities.NewUnsigned8InfoElement(newElement("protocolIdentifier", iana), f.protocol),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodNamespace", antrea), f.srcNS),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodName", antrea), f.srcPod),
+		ipfixentities.NewStringInfoElement(newElement("destinationPodNamespace", antrea), f.dstNS),
+		ipfixentities.NewStringInfoElement(newElement("destinationPodName", antrea), f.dstPod),
+		ipfixentities.NewUnsigned8InfoElement(newElement("ingressNetworkPolicyRuleAction", antrea), f.ingressRuleAction),
+		ipfixentities.NewStringInfoElement(newElement("sourcePodLabels", antrea), f.srcLabels),
+		ipfixentities.NewStringInfoElement(newElement("destinationPodLabels", antrea), f.dstLabels),
+	}
+	record := ipfixentities.NewDataRecord(256, 0, 0, true)
+	for _, element := range elements {
+		require.NoError(t, record.AddInfoElement(element))
+	}
+	return record
+}
+
+func newTestRecommender(t *testing.T, now time.Time) *Recommender {
+	r := NewRecommender(time.Hour, 100)
+	webLabels := `{"app":"web","pod-template-hash":"5d4f8b7c9"}`
+	dbLabels := `{"app":"db","statefulset.kubernetes.io/pod-name":"db-0"}`
+	flows := []testFlow{
+		// Two Pods of the web workload.
+		{srcIP: "10.10.0.1", dstIP: "10.10.1.1", srcNS: "prod", srcPod: "web-1", srcLabels: webLabels,
+			dstNS: "prod", dstPod: "db-0", dstLabels: dbLabels, protocol: 6, dstPort: 5432},
+		{srcIP: "10.10.0.2", dstIP: "10.10.1.1", srcNS: "prod", srcPod: "web-2", srcLabels: webLabels,
+			dstNS: "prod", dstPod: "db-0", dstLabels: dbLabels, protocol: 6, dstPort: 5432},
+		{srcIP: "10.10.2.1", dstIP: "10.10.0.1", srcNS: "dev", srcPod: "client", srcLabels: `{"run":"client"}`,
+			dstNS: "prod", dstPod: "web-1", dstLabels: webLabels, protocol: 6, dstPort: 8080},
+		{srcIP: "10.10.0.1", dstIP: "8.8.8.8", srcNS: "prod", srcPod: "web-1", srcLabels: webLabels, protocol: 17, dstPort: 53},
+		// Denied connection.
+		{srcIP: "10.10.2.1", dstIP: "10.10.1.1", srcNS: "dev", srcPod: "client", srcLabels: `{"run":"client"}`,
+			dstNS: "prod", dstPod: "db-0", dstLabels: dbLabels, protocol: 6, dstPort: 5432, ingressRuleAction: ipfixregistry.NetworkPolicyRuleActionDrop},
+		// ICMP connection.
+		{srcIP: "10.10.0.1", dstIP: "10.10.1.1", srcNS: "prod", srcPod: "web-1", srcLabels: webLabels,
+			dstNS: "prod", dstPod: "db-0", dstLabels: dbLabels, protocol: 1},
+		// Pod without labels.
+		{srcIP: "10.10.2.2", dstIP: "10.10.0.1", srcNS: "dev", srcPod: "debug", dstNS: "prod", dstPod: "web-1",
+			dstLabels: webLabels, protocol: 6, dstPort: 8080},
+	}
+	for _, f := range flows {
+		r.Add(newTestRecord(t, f), now)
+	}
+	return r
+}
+
+func TestRecommendK8sNetworkPolicies(t *testing.T) {
+	now := time.Now()
+	r := newTestRecommender(t, now)
+	recommendation, err := r.Recommend(Options{Type: PolicyTypeK8s, Namespace: "prod"}, now)
+	require.NoError(t, err)
+	data, err := recommendation.YAML()
+	require.NoError(t, err)
+	// The Pod without labels is not in the prod Namespace.
+	expected := `---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: recommend-db-42f6c1a1
+  namespace: prod
+spec:
+  ingress:
+  - from:
+    - podSelector:
+        matchLabels:
+          app: web
+    ports:
+    - port: 5432
+      protocol: TCP
+  podSelector:
+    matchLabels:
+      app: db
+  policyTypes:
+  - Ingress
+  - Egress
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: recommend-web-cd3189b7
+  namespace: prod
+spec:
+  egress:
+  - ports:
+    - port: 5432
+      protocol: TCP
+    to:
+    - podSelector:
+        matchLabels:
+          app: db
+  - ports:
+    - port: 53
+      protocol: UDP
+    to:
+    - ipBlock:
+        cidr: 8.8.8.8/32
+  ingress:
+  - from:
+    - namespaceSelector:
+        matchLabels:
+          kubernetes.io/metadata.name: dev
+      podSelector:
+        matchLabels:
+          run: client
+    ports:
+    - port: 8080
+      protocol: TCP
+  podSelector:
+    matchLabels:
+      app: web
+  policyTypes:
+  - Ingress
+  - Egress
+`
+	assert.Equal(t, expected, string(data))
+}
+
+func TestRecommendAntreaPolicies(t *testing.T) {
+	now := time.Now()
+	r := newTestRecommender(t, now)
+
+	recommendation, err := r.Recommend(Options{Type: PolicyTypeACNP, Namespace: "dev", Tier: "recommended"}, now)
+	require.NoError(t, err)
+	data, err := recommendation.YAML()
+	require.NoError(t, err)
+	expected := `# Warning: Pod dev/debug has no labels selecting it, its connections are not allowed by the recommended policies
+---
+apiVersion: crd.antrea.io/v1alpha1
+kind: Tier
+metadata:
+  name: recommended
+spec:
+  description: Policies recommended from the observed flows
+  priority: 249
+---
+apiVersion: crd.antrea.io/v1alpha1
+kind: ClusterNetworkPolicy
+metadata:
+  name: recommend-dev-pods-cb670b9a
+spec:
+  appliedTo:
+  - namespaceSelector:
+      matchLabels:
+        kubernetes.io/metadata.name: dev
+    podSelector:
+      matchLabels:
+        run: client
+  egress:
+  - action: Allow
+    enableLogging: false
+    name: allow-egress-0
+    ports:
+    - port: 8080
+      protocol: TCP
+    to:
+    - namespaceSelector:
+        matchLabels:
+          kubernetes.io/metadata.name: prod
+      podSelector:
+        matchLabels:
+          app: web
+  - action: Drop
+    enableLogging: false
+    name: drop-egress
+  ingress:
+  - action: Drop
+    enableLogging: false
+    name: drop-ingress
+  priority: 5
+  tier: recommended
+`
+	assert.Equal(t, expected, string(data))
+
+	recommendation, err = r.Recommend(Options{Type: PolicyTypeANP, Tier: "application"}, now)
+	require.NoError(t, err)
+	// No Tier is recommended for a default Tier.
+	require.Len(t, recommendation.Objects, 3)
+	data, err = recommendation.YAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `kind: NetworkPolicy
+metadata:
+  name: recommend-db-42f6c1a1
+  namespace: prod
+spec:
+  appliedTo:
+  - podSelector:
+      matchLabels:
+        app: db
+  egress:
+  - action: Drop
+    enableLogging: false
+    name: drop-egress
+  ingress:
+  - action: Allow
+    enableLogging: false
+    from:
+    - podSelector:
+        matchLabels:
+          app: web
+    name: allow-ingress-0
+    ports:
+    - port: 5432
+      protocol: TCP
+  - action: Drop
+    enableLogging: false
+    name: drop-ingress
+  priority: 5
+  tier: application
+`)
+
+	_, err = r.Recommend(Options{Type: PolicyTypeK8s, Tier: "recommended"}, now)
+	assert.Error(t, err)
+	_, err = r.Recommend(Options{Type: "calico"}, now)
+	assert.Error(t, err)
+}
+
+func TestRecommenderPrune(t *testing.T) {
+	now := time.Now()
+	r := NewRecommender(time.Hour, 2)
+	flow := testFlow{srcIP: "10.10.0.1", dstIP: "10.10.1.1", srcNS: "prod", srcPod: "web", srcLabels: `{"app":"web"}`, protocol: 6}
+	for i := 0; i < 3; i++ {
+		flow.dstPort = uint16(8080 + i)
+		r.Add(newTestRecord(t, flow), now.Add(time.Duration(i)*time.Hour))
+	}
+	// The first connection is out of the window when the third one is added.
+	assert.Len(t, r.connections, 2)
+	assert.False(t, r.truncated)
+
+	flow.dstPort = 9090
+	r.Add(newTestRecord(t, flow), now.Add(2*time.Hour))
+	assert.Len(t, r.connections, 2)
+	assert.True(t, r.truncated)
+	recommendation, err := r.Recommend(Options{Type: PolicyTypeK8s}, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"The connections observed after 2 distinct connections were ignored"}, recommendation.Warnings)
+}
@@ -16,6 +16,9 @@ package querier
 
 import (
 	ipfixintermediate "github.com/vmware/go-ipfix/pkg/intermediate"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowquery"
+	"antrea.io/antrea/pkg/flowaggregator/policyrecommendation"
 )
 
 type Metrics struct {
@@ -28,4 +31,6 @@ type Metrics struct {
 type FlowAggregatorQuerier interface {
 	GetFlowRecords(flowKey *ipfixintermediate.FlowKey) []map[string]interface{}
 	GetRecordMetrics() Metrics
+	QueryFlows(query flowquery.Query) ([]flowquery.Result, error)
+	RecommendPolicies(options policyrecommendation.Options) (*policyrecommendation.Recommendation, error)
 }
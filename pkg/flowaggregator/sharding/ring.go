@@ -0,0 +1,87 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodesPerMember is the number of points of each member on the ring. It must be
+// the same for the Flow Exporter and the Flow Aggregator, so that they agree on the owner
+// of each flow.
+const virtualNodesPerMember = 100
+
+// ring is an immutable consistent hash ring. When a member is added or removed, only the
+// keys owned by this member are moved to other members.
+type ring struct {
+	hashes  []uint64
+	owners  map[uint64]string
+	members []string
+}
+
+func newRing(members []string) *ring {
+	r := &ring{
+		hashes:  make([]uint64, 0, len(members)*virtualNodesPerMember),
+		owners:  make(map[uint64]string, len(members)*virtualNodesPerMember),
+		members: members,
+	}
+	for _, member := range members {
+		for i := 0; i < virtualNodesPerMember; i++ {
+			h := hash(member + "#" + strconv.Itoa(i))
+			// In the unlikely event of a collision, the point is owned by the
+			// smallest member, which does not depend on the order of members.
+			if owner, exists := r.owners[h]; exists {
+				if owner > member {
+					r.owners[h] = member
+				}
+				continue
+			}
+			r.owners[h] = member
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// get returns the member owning the key, or false if the ring is empty.
+func (r *ring) get(key string) (string, bool) {
+	if len(r.hashes) == 0 {
+		return "", false
+	}
+	h := hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]], true
+}
+
+// hash returns the FNV-1a hash of s, mixed with the finalizer of SplitMix64 as FNV-1a does
+// not spread short strings which only differ by their last characters, such as the
+// virtual nodes of a member, well enough over the ring.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
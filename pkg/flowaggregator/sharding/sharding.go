@@ -0,0 +1,174 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharding assigns flows to the replicas of the Flow Aggregator. A flow is owned
+// by a single replica, chosen by a consistent hash of its flow key among the ready
+// endpoints of the Flow Aggregator Service. The Flow Exporter sends the records of each
+// flow to the owning replica, and each replica only exports the flows it owns, so that
+// the records of both Nodes of a flow are correlated by the same replica.
+package sharding
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// FlowKey returns the key used to shard the flow with the provided 5-tuple. The addresses
+// are formatted the same way as in the flow keys of the go-ipfix aggregation process.
+func FlowKey(sourceAddress, destinationAddress string, protocol uint8, sourcePort, destinationPort uint16) string {
+	return sourceAddress + "," + destinationAddress + "," + strconv.Itoa(int(protocol)) + "," + strconv.Itoa(int(sourcePort)) + "," + strconv.Itoa(int(destinationPort))
+}
+
+// RecordFlowKey returns the key used to shard the flow of an IPFIX data record.
+func RecordFlowKey(record ipfixentities.Record) (string, error) {
+	var sourceAddress, destinationAddress string
+	if ie, _, exist := record.GetInfoElementWithValue("sourceIPv4Address"); exist {
+		sourceAddress = ie.GetIPAddressValue().String()
+	} else if ie, _, exist := record.GetInfoElementWithValue("sourceIPv6Address"); exist {
+		sourceAddress = ie.GetIPAddressValue().String()
+	} else {
+		return "", fmt.Errorf("source address is not present in the record")
+	}
+	if ie, _, exist := record.GetInfoElementWithValue("destinationIPv4Address"); exist {
+		destinationAddress = ie.GetIPAddressValue().String()
+	} else if ie, _, exist := record.GetInfoElementWithValue("destinationIPv6Address"); exist {
+		destinationAddress = ie.GetIPAddressValue().String()
+	} else {
+		return "", fmt.Errorf("destination address is not present in the record")
+	}
+	protocol, _, exist := record.GetInfoElementWithValue("protocolIdentifier")
+	if !exist {
+		return "", fmt.Errorf("protocolIdentifier is not present in the record")
+	}
+	sourcePort, _, exist := record.GetInfoElementWithValue("sourceTransportPort")
+	if !exist {
+		return "", fmt.Errorf("sourceTransportPort is not present in the record")
+	}
+	destinationPort, _, exist := record.GetInfoElementWithValue("destinationTransportPort")
+	if !exist {
+		return "", fmt.Errorf("destinationTransportPort is not present in the record")
+	}
+	return FlowKey(sourceAddress, destinationAddress, protocol.GetUnsigned8Value(), sourcePort.GetUnsigned16Value(), destinationPort.GetUnsigned16Value()), nil
+}
+
+// Sharder assigns flows to the ready endpoints of the Flow Aggregator Service. The ring
+// is rebuilt lazily, whenever the Endpoints object changes, so that the Flow Exporter
+// and the Flow Aggregator replicas converge on the same assignment.
+type Sharder struct {
+	namespace       string
+	name            string
+	endpointsLister corelisters.EndpointsLister
+
+	mutex           sync.Mutex
+	resourceVersion string
+	ring            *ring
+	ports           []corev1.EndpointPort
+}
+
+func NewSharder(namespace, name string, endpointsLister corelisters.EndpointsLister) *Sharder {
+	return &Sharder{
+		namespace:       namespace,
+		name:            name,
+		endpointsLister: endpointsLister,
+		ring:            newRing(nil),
+	}
+}
+
+// GetOwner returns the IP address of the replica owning the flow with the provided key,
+// or false if there is no ready replica.
+func (s *Sharder) GetOwner(key string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+	return s.ring.get(key)
+}
+
+// GetReplicas returns the IP addresses of all the ready replicas.
+func (s *Sharder) GetReplicas() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+	return s.ring.members
+}
+
+// GetPort returns the port of the replicas for the provided protocol.
+func (s *Sharder) GetPort(protocol corev1.Protocol) (int32, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+	for _, port := range s.ports {
+		if port.Protocol == protocol {
+			return port.Port, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Sharder) syncLocked() {
+	endpoints, err := s.endpointsLister.Endpoints(s.namespace).Get(s.name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Error when getting Flow Aggregator Endpoints", "namespace", s.namespace, "name", s.name)
+			return
+		}
+		if s.resourceVersion != "" {
+			klog.InfoS("Flow Aggregator Endpoints not found, no flow can be sharded", "namespace", s.namespace, "name", s.name)
+		}
+		s.resourceVersion = ""
+		s.ring = newRing(nil)
+		s.ports = nil
+		return
+	}
+	if endpoints.ResourceVersion == s.resourceVersion {
+		return
+	}
+	s.resourceVersion = endpoints.ResourceVersion
+	replicas, ports := getReadyReplicas(endpoints)
+	s.ring = newRing(replicas)
+	s.ports = ports
+	klog.InfoS("Updated Flow Aggregator replicas", "replicas", replicas)
+}
+
+// getReadyReplicas returns the sorted, unique IP addresses of the ready endpoints, and the
+// ports of the first subset including them. All the replicas are expected to expose the
+// same ports.
+func getReadyReplicas(endpoints *corev1.Endpoints) ([]string, []corev1.EndpointPort) {
+	replicaSet := make(map[string]struct{})
+	var ports []corev1.EndpointPort
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) == 0 {
+			continue
+		}
+		if ports == nil {
+			ports = subset.Ports
+		}
+		for _, address := range subset.Addresses {
+			replicaSet[address.IP] = struct{}{}
+		}
+	}
+	replicas := make([]string, 0, len(replicaSet))
+	for replica := range replicaSet {
+		replicas = append(replicas, replica)
+	}
+	sort.Strings(replicas)
+	return replicas, ports
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newEndpoints(resourceVersion string, ready []string, notReady []string) *corev1.Endpoints {
+	subset := corev1.EndpointSubset{
+		Ports: []corev1.EndpointPort{
+			{Name: "ipfix-udp", Port: 4739, Protocol: corev1.ProtocolUDP},
+			{Name: "ipfix-tcp", Port: 4740, Protocol: corev1.ProtocolTCP},
+		},
+	}
+	for _, ip := range ready {
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: ip})
+	}
+	for _, ip := range notReady {
+		subset.NotReadyAddresses = append(subset.NotReadyAddresses, corev1.EndpointAddress{IP: ip})
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "flow-aggregator", Name: "flow-aggregator", ResourceVersion: resourceVersion},
+		Subsets:    []corev1.EndpointSubset{subset},
+	}
+}
+
+func TestRing(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = FlowKey("10.10.0.1", fmt.Sprintf("10.10.1.%d", i%256), 6, uint16(30000+i), 80)
+	}
+	owners := func(r *ring) map[string]string {
+		m := make(map[string]string, len(keys))
+		for _, key := range keys {
+			owner, ok := r.get(key)
+			require.True(t, ok)
+			m[key] = owner
+		}
+		return m
+	}
+
+	_, ok := newRing(nil).get(keys[0])
+	assert.False(t, ok)
+
+	ring3 := owners(newRing([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}))
+	// The assignment does not depend on the order of members.
+	assert.Equal(t, ring3, owners(newRing([]string{"10.0.0.3", "10.0.0.1", "10.0.0.2"})))
+	counts := make(map[string]int)
+	for _, owner := range ring3 {
+		counts[owner]++
+	}
+	for member, count := range counts {
+		assert.Greater(t, count, len(keys)/10, "member %s owns too few keys", member)
+	}
+
+	// Only the keys of the removed member are moved.
+	ring2 := owners(newRing([]string{"10.0.0.1", "10.0.0.3"}))
+	for key, owner := range ring3 {
+		if owner != "10.0.0.2" {
+			assert.Equal(t, owner, ring2[key])
+		} else {
+			assert.NotEqual(t, "10.0.0.2", ring2[key])
+		}
+	}
+}
+
+func TestSharder(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	sharder := NewSharder("flow-aggregator", "flow-aggregator", corelisters.NewEndpointsLister(indexer))
+	key := FlowKey("10.10.0.1", "10.10.1.2", 6, 34567, 80)
+
+	_, ok := sharder.GetOwner(key)
+	assert.False(t, ok)
+	_, ok = sharder.GetPort(corev1.ProtocolTCP)
+	assert.False(t, ok)
+
+	require.NoError(t, indexer.Add(newEndpoints("1", []string{"10.0.0.2", "10.0.0.1"}, []string{"10.0.0.3"})))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, sharder.GetReplicas())
+	owner, ok := sharder.GetOwner(key)
+	require.True(t, ok)
+	assert.Contains(t, []string{"10.0.0.1", "10.0.0.2"}, owner)
+	port, ok := sharder.GetPort(corev1.ProtocolTCP)
+	require.True(t, ok)
+	assert.Equal(t, int32(4740), port)
+
+	// The ring is not rebuilt until the resource version changes.
+	require.NoError(t, indexer.Update(newEndpoints("1", []string{owner}, nil)))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, sharder.GetReplicas())
+	require.NoError(t, indexer.Update(newEndpoints("2", []string{owner}, nil)))
+	assert.Equal(t, []string{owner}, sharder.GetReplicas())
+
+	require.NoError(t, indexer.Delete(newEndpoints("2", nil, nil)))
+	_, ok = sharder.GetOwner(key)
+	assert.False(t, ok)
+}
+
+func TestRecordFlowKey(t *testing.T) {
+	ipfixregistry.LoadRegistry()
+	newElement := func(name string) *ipfixentities.InfoElement {
+		element, err := ipfixregistry.GetInfoElement(name, ipfixregistry.IANAEnterpriseID)
+		require.NoError(t, err)
+		return element
+	}
+	elements := []ipfixentities.InfoElementWithValue{
+		ipfixentities.NewIPAddressInfoElement(newElement("sourceIPv6Address"), net.ParseIP("2001:db8::1")),
+		ipfixentities.NewIPAddressInfoElement(newElement("destinationIPv6Address"), net.ParseIP("2001:db8::2")),
+		ipfixentities.NewUnsigned16InfoElement(newElement("sourceTransportPort"), 34567),
+		ipfixentities.NewUnsigned16InfoElement(newElement("destinationTransportPort"), 80),
+	}
+	newRecord := func(elements []ipfixentities.InfoElementWithValue) ipfixentities.Record {
+		record := ipfixentities.NewDataRecord(256, 0, 0, true)
+		for _, element := range elements {
+			require.NoError(t, record.AddInfoElement(element))
+		}
+		return record
+	}
+	_, err := RecordFlowKey(newRecord(elements))
+	assert.Error(t, err)
+
+	elements = append(elements, ipfixentities.NewUnsigned8InfoElement(newElement("protocolIdentifier"), 6))
+	key, err := RecordFlowKey(newRecord(elements))
+	require.NoError(t, err)
+	assert.Equal(t, FlowKey("2001:db8::1", "2001:db8::2", 6, 34567, 80), key)
+}
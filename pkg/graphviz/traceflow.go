@@ -201,6 +201,17 @@ func getTraceflowMessage(o *crdv1alpha1.Observation, spec *crdv1alpha1.Traceflow
 	if o.Action != crdv1alpha1.ActionDropped && len(o.TunnelDstIP) > 0 {
 		str += "\nTunnel Destination IP : " + o.TunnelDstIP
 	}
+	if o.Component == crdv1alpha1.ComponentEgress {
+		if len(o.Egress) > 0 {
+			str += "\nEgress: " + o.Egress
+		}
+		if len(o.EgressIP) > 0 {
+			str += "\nEgress IP: " + o.EgressIP
+		}
+		if len(o.EgressNode) > 0 {
+			str += "\nEgress Node: " + o.EgressNode
+		}
+	}
 	return str
 }
 
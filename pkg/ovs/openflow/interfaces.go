@@ -76,6 +76,23 @@ const (
 	NxmFieldDstIPv6     = "NXM_NX_IPV6_DST"
 )
 
+// HashFields are the fields of a packet hashed by the multipath action.
+type HashFields uint16
+
+const (
+	// HashFieldsNWSrc hashes the source IPv4 or IPv6 address.
+	HashFieldsNWSrc HashFields = 4
+)
+
+// MultipathAlgorithm is the algorithm selecting a link from the hash computed by
+// the multipath action.
+type MultipathAlgorithm uint16
+
+const (
+	// MultipathAlgorithmModuloN selects the link hash % (maxLink + 1).
+	MultipathAlgorithmModuloN MultipathAlgorithm = 0
+)
+
 const (
 	AddMessage OFOperation = iota
 	ModifyMessage
@@ -195,6 +212,9 @@ type Action interface {
 	MoveRange(fromName, toName string, from, to Range) FlowBuilder
 	Resubmit(port uint16, table uint8) FlowBuilder
 	ResubmitToTable(table uint8) FlowBuilder
+	// CloneResubmitToTable resubmits a copy of the packet to the table. The actions applied to the copy, including
+	// the meter of the flow it matches, don't affect the packet.
+	CloneResubmitToTable(table uint8) FlowBuilder
 	CT(commit bool, tableID uint8, zone int) CTAction
 	Drop() FlowBuilder
 	Output(port uint32) FlowBuilder
@@ -255,6 +275,8 @@ type FlowBuilder interface {
 	MatchConjID(value uint32) FlowBuilder
 	MatchDstPort(port uint16, portMask *uint16) FlowBuilder
 	MatchSrcPort(port uint16, portMask *uint16) FlowBuilder
+	// MatchTCPFlags matches the TCP flags of the packet. Only the bits set in mask are matched.
+	MatchTCPFlags(flags, mask uint16) FlowBuilder
 	MatchICMPv6Type(icmp6Type byte) FlowBuilder
 	MatchICMPv6Code(icmp6Code byte) FlowBuilder
 	MatchTunnelDst(dstIP net.IP) FlowBuilder
@@ -290,6 +312,7 @@ type LearnAction interface {
 	MatchLearnedTCPv6DstPort() LearnAction
 	MatchLearnedUDPv6DstPort() LearnAction
 	MatchLearnedSCTPv6DstPort() LearnAction
+	MatchLearnedSrcPort(protocol Protocol) LearnAction
 	MatchLearnedSrcIP() LearnAction
 	MatchLearnedDstIP() LearnAction
 	MatchLearnedSrcIPv6() LearnAction
@@ -317,6 +340,10 @@ type BucketBuilder interface {
 	LoadRegRange(regID int, data uint32, rng *Range) BucketBuilder
 	LoadToRegField(field *RegField, data uint32) BucketBuilder
 	ResubmitToTable(tableID uint8) BucketBuilder
+	Group(id GroupIDType) BucketBuilder
+	// Multipath hashes the fields of the packet and loads the selected link, from
+	// 0 to maxLink, into the field.
+	Multipath(fields HashFields, basis uint16, algorithm MultipathAlgorithm, maxLink uint16, arg uint32, field *RegField) BucketBuilder
 	Done() Group
 }
 
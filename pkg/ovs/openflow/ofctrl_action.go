@@ -287,6 +287,12 @@ func (a *ofFlowAction) ResubmitToTable(table uint8) FlowBuilder {
 	return a.Resubmit(openflow13.OFPP_IN_PORT, table)
 }
 
+// CloneResubmitToTable is an action to resubmit a copy of the packet to the specified table.
+func (a *ofFlowAction) CloneResubmitToTable(table uint8) FlowBuilder {
+	a.builder.ApplyAction(&cloneAction{clone: &nxActionCloneResubmit{table: table}})
+	return a.builder
+}
+
 // DecTTL is an action to decrease TTL. It is used in routing functions implemented by Openflow.
 func (a *ofFlowAction) DecTTL() FlowBuilder {
 	decTTLAct := new(ofctrl.DecTTLAction)
@@ -452,6 +458,24 @@ func (a *ofLearnAction) MatchLearnedSCTPv6DstPort() LearnAction {
 	return a.MatchTransportDst(ProtocolSCTPv6)
 }
 
+// MatchLearnedSrcPort specifies that the transport layer source field
+// {tcp|udp|sctp}_src in the learned flow must match the same field of the
+// packet currently being processed. It must follow the match of the transport
+// layer destination field of the same protocol, which adds the prerequisite
+// matches of the field.
+func (a *ofLearnAction) MatchLearnedSrcPort(protocol Protocol) LearnAction {
+	switch protocol {
+	case ProtocolTCP, ProtocolUDP, ProtocolSCTP, ProtocolTCPv6, ProtocolUDPv6, ProtocolSCTPv6:
+	default:
+		// Return directly if the protocol is not acceptable.
+		return a
+	}
+	trimProtocol := strings.ReplaceAll(string(protocol), "v6", "")
+	fieldName := fmt.Sprintf("OXM_OF_%s_SRC", strings.ToUpper(trimProtocol))
+	a.nxLearn.AddMatch(&ofctrl.LearnField{Name: fieldName}, 2*8, &ofctrl.LearnField{Name: fieldName}, nil)
+	return a
+}
+
 // MatchLearnedSrcIP makes the learned flow to match the nw_src of current IP packet.
 func (a *ofLearnAction) MatchLearnedSrcIP() LearnAction {
 	a.nxLearn.AddMatch(&ofctrl.LearnField{Name: "NXM_OF_IP_SRC"}, 4*8, &ofctrl.LearnField{Name: "NXM_OF_IP_SRC"}, nil)
@@ -552,3 +576,65 @@ func (a *ofFlowAction) GotoTable(tableID uint8) FlowBuilder {
 	a.builder.ofFlow.Goto(tableID)
 	return a.builder
 }
+
+const (
+	nxastResubmitTable       = 14
+	nxastClone               = 42
+	nxActionCloneResubmitLen = 32
+	nxActionCloneHeaderLen   = 16
+	nxActionResubmitTableLen = 16
+)
+
+// nxActionCloneResubmit is the NXAST_CLONE action of OVS, with a single NXAST_RESUBMIT_TABLE action resubmitting the
+// copy of the packet to a table, which is not provided by libOpenflow. The packet is resubmitted with its in_port.
+type nxActionCloneResubmit struct {
+	table uint8
+}
+
+func (a *nxActionCloneResubmit) Header() *openflow13.ActionHeader {
+	return &openflow13.ActionHeader{Type: ofpatExperimenter, Length: nxActionCloneResubmitLen}
+}
+
+func (a *nxActionCloneResubmit) Len() uint16 {
+	return nxActionCloneResubmitLen
+}
+
+func (a *nxActionCloneResubmit) MarshalBinary() ([]byte, error) {
+	data := make([]byte, nxActionCloneResubmitLen)
+	binary.BigEndian.PutUint16(data[0:], ofpatExperimenter)
+	binary.BigEndian.PutUint16(data[2:], nxActionCloneResubmitLen)
+	binary.BigEndian.PutUint32(data[4:], nxExperimenterID)
+	binary.BigEndian.PutUint16(data[8:], nxastClone)
+	resubmit := data[nxActionCloneHeaderLen:]
+	binary.BigEndian.PutUint16(resubmit[0:], ofpatExperimenter)
+	binary.BigEndian.PutUint16(resubmit[2:], nxActionResubmitTableLen)
+	binary.BigEndian.PutUint32(resubmit[4:], nxExperimenterID)
+	binary.BigEndian.PutUint16(resubmit[8:], nxastResubmitTable)
+	binary.BigEndian.PutUint16(resubmit[10:], openflow13.OFPP_IN_PORT)
+	resubmit[12] = a.table
+	return data, nil
+}
+
+func (a *nxActionCloneResubmit) UnmarshalBinary(data []byte) error {
+	if len(data) < nxActionCloneResubmitLen {
+		return fmt.Errorf("the data is too short to unmarshal a clone action: %d bytes", len(data))
+	}
+	if subtype := binary.BigEndian.Uint16(data[nxActionCloneHeaderLen+8:]); subtype != nxastResubmitTable {
+		return fmt.Errorf("the clone action doesn't resubmit the packet, subtype of the nested action: %d", subtype)
+	}
+	a.table = data[nxActionCloneHeaderLen+12]
+	return nil
+}
+
+// cloneAction wraps nxActionCloneResubmit to be applied to a flow.
+type cloneAction struct {
+	clone *nxActionCloneResubmit
+}
+
+func (a *cloneAction) GetActionMessage() openflow13.Action {
+	return a.clone
+}
+
+func (a *cloneAction) GetActionType() string {
+	return "NXActionClone"
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneResubmitAction(t *testing.T) {
+	action := &nxActionCloneResubmit{table: 115}
+	data, err := action.MarshalBinary()
+	require.NoError(t, err)
+	// clone(resubmit(,115))
+	assert.Equal(t, []byte{
+		0xff, 0xff, 0x00, 0x20, 0x00, 0x00, 0x23, 0x20,
+		0x00, 0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xff, 0xff, 0x00, 0x10, 0x00, 0x00, 0x23, 0x20,
+		0x00, 0x0e, 0xff, 0xf8, 0x73, 0x00, 0x00, 0x00,
+	}, data)
+	assert.Equal(t, uint16(len(data)), action.Len())
+
+	unmarshalled := new(nxActionCloneResubmit)
+	require.NoError(t, unmarshalled.UnmarshalBinary(data))
+	assert.Equal(t, action, unmarshalled)
+}
@@ -529,6 +529,15 @@ func (b *ofFlowBuilder) MatchSrcPort(port uint16, portMask *uint16) FlowBuilder
 	return b
 }
 
+// MatchTCPFlags adds match condition for matching the TCP flags. Only the bits set in mask
+// are matched, e.g. flags=0x2 and mask=0x2 matches all the packets with the SYN flag.
+func (b *ofFlowBuilder) MatchTCPFlags(flags, mask uint16) FlowBuilder {
+	b.Match.TcpFlags = &flags
+	b.Match.TcpFlagsMask = &mask
+	b.matchers = append(b.matchers, fmt.Sprintf("tcp_flags=0x%x/0x%x", flags, mask))
+	return b
+}
+
 // MatchCTSrcIP matches the source IPv4 address of the connection tracker original direction tuple. This match requires
 // a match to valid connection tracking state as a prerequisite, and valid connection tracking state matches include
 // "+new", "+est", "+rel" and "+trk-inv".
@@ -15,6 +15,7 @@
 package openflow
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"antrea.io/libOpenflow/openflow13"
@@ -128,6 +129,27 @@ func (b *bucketBuilder) ResubmitToTable(tableID uint8) BucketBuilder {
 	return b
 }
 
+// Group is an action to output packets to the specified group when the bucket is selected.
+func (b *bucketBuilder) Group(id GroupIDType) BucketBuilder {
+	b.bucket.AddAction(openflow13.NewActionGroup(uint32(id)))
+	return b
+}
+
+// Multipath is an action to hash the fields of the packet and load the selected link to the field when the bucket is
+// selected.
+func (b *bucketBuilder) Multipath(fields HashFields, basis uint16, algorithm MultipathAlgorithm, maxLink uint16, arg uint32, field *RegField) BucketBuilder {
+	b.bucket.AddAction(&nxActionMultipath{
+		fields:    fields,
+		basis:     basis,
+		algorithm: algorithm,
+		maxLink:   maxLink,
+		arg:       arg,
+		ofsNbits:  field.rng.ToNXRange().ToOfsBits(),
+		dst:       nxmRegHeader(field.regID),
+	})
+	return b
+}
+
 // Weight sets the weight of a bucket.
 func (b *bucketBuilder) Weight(val uint16) BucketBuilder {
 	b.bucket.Weight = val
@@ -138,3 +160,65 @@ func (b *bucketBuilder) Done() Group {
 	b.group.ofctrl.AddBuckets(b.bucket)
 	return b.group
 }
+
+const (
+	ofpatExperimenter    = 0xffff
+	nxExperimenterID     = 0x00002320
+	nxastMultipath       = 10
+	nxActionMultipathLen = 32
+)
+
+// nxmRegHeader returns the NXM header of NXM_NX_REG<regID>.
+func nxmRegHeader(regID int) uint32 {
+	return 0x0001<<16 | uint32(regID)<<9 | 4
+}
+
+// nxActionMultipath is the NXAST_MULTIPATH action of OVS, which is not provided
+// by libOpenflow.
+type nxActionMultipath struct {
+	fields    HashFields
+	basis     uint16
+	algorithm MultipathAlgorithm
+	maxLink   uint16
+	arg       uint32
+	ofsNbits  uint16
+	dst       uint32
+}
+
+func (a *nxActionMultipath) Header() *openflow13.ActionHeader {
+	return &openflow13.ActionHeader{Type: ofpatExperimenter, Length: nxActionMultipathLen}
+}
+
+func (a *nxActionMultipath) Len() uint16 {
+	return nxActionMultipathLen
+}
+
+func (a *nxActionMultipath) MarshalBinary() ([]byte, error) {
+	data := make([]byte, nxActionMultipathLen)
+	binary.BigEndian.PutUint16(data[0:], ofpatExperimenter)
+	binary.BigEndian.PutUint16(data[2:], nxActionMultipathLen)
+	binary.BigEndian.PutUint32(data[4:], nxExperimenterID)
+	binary.BigEndian.PutUint16(data[8:], nxastMultipath)
+	binary.BigEndian.PutUint16(data[10:], uint16(a.fields))
+	binary.BigEndian.PutUint16(data[12:], a.basis)
+	binary.BigEndian.PutUint16(data[16:], uint16(a.algorithm))
+	binary.BigEndian.PutUint16(data[18:], a.maxLink)
+	binary.BigEndian.PutUint32(data[20:], a.arg)
+	binary.BigEndian.PutUint16(data[26:], a.ofsNbits)
+	binary.BigEndian.PutUint32(data[28:], a.dst)
+	return data, nil
+}
+
+func (a *nxActionMultipath) UnmarshalBinary(data []byte) error {
+	if len(data) < nxActionMultipathLen {
+		return fmt.Errorf("the data is too short to unmarshal a multipath action: %d bytes", len(data))
+	}
+	a.fields = HashFields(binary.BigEndian.Uint16(data[10:]))
+	a.basis = binary.BigEndian.Uint16(data[12:])
+	a.algorithm = MultipathAlgorithm(binary.BigEndian.Uint16(data[16:]))
+	a.maxLink = binary.BigEndian.Uint16(data[18:])
+	a.arg = binary.BigEndian.Uint32(data[20:])
+	a.ofsNbits = binary.BigEndian.Uint16(data[26:])
+	a.dst = binary.BigEndian.Uint32(data[28:])
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipathAction(t *testing.T) {
+	field := NewRegField(8, 0, 7, "testHash")
+	action := &nxActionMultipath{
+		fields:    HashFieldsNWSrc,
+		algorithm: MultipathAlgorithmModuloN,
+		maxLink:   255,
+		ofsNbits:  field.rng.ToNXRange().ToOfsBits(),
+		dst:       nxmRegHeader(field.regID),
+	}
+	data, err := action.MarshalBinary()
+	require.NoError(t, err)
+	// multipath(nw_src,0,modulo_n,256,0,NXM_NX_REG8[0..7])
+	assert.Equal(t, []byte{
+		0xff, 0xff, 0x00, 0x20, 0x00, 0x00, 0x23, 0x20,
+		0x00, 0x0a, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x07, 0x00, 0x01, 0x10, 0x04,
+	}, data)
+	assert.Equal(t, uint16(len(data)), action.Len())
+
+	unmarshalled := new(nxActionMultipath)
+	require.NoError(t, unmarshalled.UnmarshalBinary(data))
+	assert.Equal(t, action, unmarshalled)
+}
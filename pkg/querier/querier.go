@@ -15,6 +15,9 @@
 package querier
 
 import (
+	"net"
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 
 	"antrea.io/antrea/pkg/agent/types"
@@ -38,6 +41,22 @@ type AgentNetworkPolicyInfoQuerier interface {
 	GetAppliedNetworkPolicies(pod, namespace string, npFilter *NetworkPolicyQueryFilter) []cpv1beta.NetworkPolicy
 	GetNetworkPolicyByRuleFlowID(ruleFlowID uint32) *cpv1beta.NetworkPolicyReference
 	GetRuleByFlowID(ruleFlowID uint32) *types.PolicyRule
+	// GetFQDNCache returns the FQDNs cached for FQDN policy rules, with the
+	// IPs they are resolved to and the rules selecting them.
+	GetFQDNCache() []FQDNCacheEntry
+	// GetFQDNStats returns the counters of the DNS responses intercepted and
+	// the rules resynced for FQDN policy rules.
+	GetFQDNStats() FQDNStats
+}
+
+// EgressQuerier is used to query the Egresses realized by the agent.
+type EgressQuerier interface {
+	// GetEgress returns the name, the IP and the Node of the effective
+	// Egress of a local Pod.
+	GetEgress(podNamespace, podName string) (string, string, string, error)
+	// GetEgressByIP returns the name of an Egress whose IP is assigned to
+	// the local Node.
+	GetEgressByIP(egressIP string) (string, bool)
 }
 
 type ControllerNetworkPolicyInfoQuerier interface {
@@ -92,3 +111,28 @@ type NetworkPolicyQueryFilter struct {
 	// The type of the original NetworkPolicy that the internal NetworkPolicy is created for.(K8sNP, CNP, ANP)
 	SourceType cpv1beta.NetworkPolicyType
 }
+
+// FQDNCacheEntry is a FQDN cached for FQDN policy rules.
+type FQDNCacheEntry struct {
+	FQDN string
+	// IPs are the IP addresses the FQDN is resolved to.
+	IPs []net.IP
+	// ExpirationTime is the time when the IP addresses expire, which is the
+	// DNS response receiving time plus the lowest applicable TTL.
+	ExpirationTime time.Time
+	// RuleIDs are the IDs of the rules which select the FQDN.
+	RuleIDs []string
+	// Policies are the original NetworkPolicies of the rules which select the FQDN.
+	Policies []cpv1beta.NetworkPolicyReference
+}
+
+// FQDNStats contains the counters of the FQDN policy implementation.
+type FQDNStats struct {
+	// DNSPacketIns is the number of DNS response packets intercepted.
+	DNSPacketIns uint64
+	// ParseFailures is the number of DNS responses which failed to be parsed.
+	ParseFailures uint64
+	// RulesResynced is the number of times rules were resynced because of
+	// the IP address updates of the FQDNs they select.
+	RulesResynced uint64
+}
@@ -14,7 +14,7 @@
 //
 
 // Code generated by MockGen. DO NOT EDIT.
-// Source: antrea.io/antrea/pkg/querier (interfaces: AgentNetworkPolicyInfoQuerier)
+// Source: antrea.io/antrea/pkg/querier (interfaces: AgentNetworkPolicyInfoQuerier,EgressQuerier)
 
 // Package testing is a generated GoMock package.
 package testing
@@ -148,6 +148,34 @@ func (mr *MockAgentNetworkPolicyInfoQuerierMockRecorder) GetNetworkPolicies(arg0
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetworkPolicies", reflect.TypeOf((*MockAgentNetworkPolicyInfoQuerier)(nil).GetNetworkPolicies), arg0)
 }
 
+// GetFQDNCache mocks base method
+func (m *MockAgentNetworkPolicyInfoQuerier) GetFQDNCache() []querier.FQDNCacheEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFQDNCache")
+	ret0, _ := ret[0].([]querier.FQDNCacheEntry)
+	return ret0
+}
+
+// GetFQDNCache indicates an expected call of GetFQDNCache
+func (mr *MockAgentNetworkPolicyInfoQuerierMockRecorder) GetFQDNCache() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFQDNCache", reflect.TypeOf((*MockAgentNetworkPolicyInfoQuerier)(nil).GetFQDNCache))
+}
+
+// GetFQDNStats mocks base method
+func (m *MockAgentNetworkPolicyInfoQuerier) GetFQDNStats() querier.FQDNStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFQDNStats")
+	ret0, _ := ret[0].(querier.FQDNStats)
+	return ret0
+}
+
+// GetFQDNStats indicates an expected call of GetFQDNStats
+func (mr *MockAgentNetworkPolicyInfoQuerierMockRecorder) GetFQDNStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFQDNStats", reflect.TypeOf((*MockAgentNetworkPolicyInfoQuerier)(nil).GetFQDNStats))
+}
+
 // GetNetworkPolicyByRuleFlowID mocks base method
 func (m *MockAgentNetworkPolicyInfoQuerier) GetNetworkPolicyByRuleFlowID(arg0 uint32) *v1beta2.NetworkPolicyReference {
 	m.ctrl.T.Helper()
@@ -189,3 +217,58 @@ func (mr *MockAgentNetworkPolicyInfoQuerierMockRecorder) GetRuleByFlowID(arg0 in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleByFlowID", reflect.TypeOf((*MockAgentNetworkPolicyInfoQuerier)(nil).GetRuleByFlowID), arg0)
 }
+
+// MockEgressQuerier is a mock of EgressQuerier interface
+type MockEgressQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockEgressQuerierMockRecorder
+}
+
+// MockEgressQuerierMockRecorder is the mock recorder for MockEgressQuerier
+type MockEgressQuerierMockRecorder struct {
+	mock *MockEgressQuerier
+}
+
+// NewMockEgressQuerier creates a new mock instance
+func NewMockEgressQuerier(ctrl *gomock.Controller) *MockEgressQuerier {
+	mock := &MockEgressQuerier{ctrl: ctrl}
+	mock.recorder = &MockEgressQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEgressQuerier) EXPECT() *MockEgressQuerierMockRecorder {
+	return m.recorder
+}
+
+// GetEgress mocks base method
+func (m *MockEgressQuerier) GetEgress(arg0, arg1 string) (string, string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEgress", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetEgress indicates an expected call of GetEgress
+func (mr *MockEgressQuerierMockRecorder) GetEgress(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEgress", reflect.TypeOf((*MockEgressQuerier)(nil).GetEgress), arg0, arg1)
+}
+
+// GetEgressByIP mocks base method
+func (m *MockEgressQuerier) GetEgressByIP(arg0 string) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEgressByIP", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetEgressByIP indicates an expected call of GetEgressByIP
+func (mr *MockEgressQuerierMockRecorder) GetEgressByIP(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEgressByIP", reflect.TypeOf((*MockEgressQuerier)(nil).GetEgressByIP), arg0)
+}
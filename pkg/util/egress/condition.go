@@ -0,0 +1,75 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+)
+
+// GetEgressCondition returns the condition of the provided type from the
+// EgressStatus, or nil if it doesn't exist.
+func GetEgressCondition(status *crdv1a2.EgressStatus, condType crdv1a2.EgressConditionType) *crdv1a2.EgressCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetEgressCondition adds or updates the condition in the EgressStatus. The
+// LastTransitionTime is only updated when the status of the condition changes.
+// It returns true if the EgressStatus has been changed.
+func SetEgressCondition(status *crdv1a2.EgressStatus, condition crdv1a2.EgressCondition) bool {
+	existing := GetEgressCondition(status, condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		status.Conditions = append(status.Conditions, condition)
+		return true
+	}
+	if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return false
+	}
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		if condition.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		} else {
+			existing.LastTransitionTime = condition.LastTransitionTime
+		}
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+	return true
+}
+
+// RemoveEgressCondition removes the condition of the provided type from the
+// EgressStatus. It returns true if the condition existed.
+func RemoveEgressCondition(status *crdv1a2.EgressStatus, condType crdv1a2.EgressConditionType) bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			status.Conditions = append(status.Conditions[:i], status.Conditions[i+1:]...)
+			if len(status.Conditions) == 0 {
+				status.Conditions = nil
+			}
+			return true
+		}
+	}
+	return false
+}
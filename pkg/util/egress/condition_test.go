@@ -0,0 +1,104 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+)
+
+func TestSetEgressCondition(t *testing.T) {
+	oldTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	existing := crdv1a2.EgressCondition{
+		Type:               crdv1a2.IPAllocated,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: oldTime,
+		Reason:             "Allocated",
+	}
+	tests := []struct {
+		name                string
+		conditions          []crdv1a2.EgressCondition
+		condition           crdv1a2.EgressCondition
+		expectedChanged     bool
+		expectedStatus      corev1.ConditionStatus
+		expectedMessage     string
+		expectedTimeUpdated bool
+	}{
+		{
+			name:                "add new condition",
+			condition:           crdv1a2.EgressCondition{Type: crdv1a2.IPAllocated, Status: corev1.ConditionTrue, Reason: "Allocated"},
+			expectedChanged:     true,
+			expectedStatus:      corev1.ConditionTrue,
+			expectedTimeUpdated: true,
+		},
+		{
+			name:            "same condition",
+			conditions:      []crdv1a2.EgressCondition{existing},
+			condition:       crdv1a2.EgressCondition{Type: crdv1a2.IPAllocated, Status: corev1.ConditionTrue, Reason: "Allocated"},
+			expectedChanged: false,
+			expectedStatus:  corev1.ConditionTrue,
+		},
+		{
+			name:            "update message only",
+			conditions:      []crdv1a2.EgressCondition{existing},
+			condition:       crdv1a2.EgressCondition{Type: crdv1a2.IPAllocated, Status: corev1.ConditionTrue, Reason: "Allocated", Message: "foo"},
+			expectedChanged: true,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedMessage: "foo",
+		},
+		{
+			name:                "update status",
+			conditions:          []crdv1a2.EgressCondition{existing},
+			condition:           crdv1a2.EgressCondition{Type: crdv1a2.IPAllocated, Status: corev1.ConditionFalse, Reason: "AllocationError", Message: "pool exhausted"},
+			expectedChanged:     true,
+			expectedStatus:      corev1.ConditionFalse,
+			expectedMessage:     "pool exhausted",
+			expectedTimeUpdated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &crdv1a2.EgressStatus{Conditions: tt.conditions}
+			assert.Equal(t, tt.expectedChanged, SetEgressCondition(status, tt.condition))
+			cond := GetEgressCondition(status, tt.condition.Type)
+			if assert.NotNil(t, cond) {
+				assert.Equal(t, tt.expectedStatus, cond.Status)
+				assert.Equal(t, tt.expectedMessage, cond.Message)
+				assert.Equal(t, tt.expectedTimeUpdated, !cond.LastTransitionTime.Equal(&oldTime))
+			}
+		})
+	}
+}
+
+func TestRemoveEgressCondition(t *testing.T) {
+	status := &crdv1a2.EgressStatus{
+		Conditions: []crdv1a2.EgressCondition{
+			{Type: crdv1a2.IPAllocated, Status: corev1.ConditionTrue},
+			{Type: crdv1a2.IPAssigned, Status: corev1.ConditionTrue},
+		},
+	}
+	assert.False(t, RemoveEgressCondition(status, crdv1a2.Failover))
+	assert.True(t, RemoveEgressCondition(status, crdv1a2.IPAllocated))
+	assert.Nil(t, GetEgressCondition(status, crdv1a2.IPAllocated))
+	assert.NotNil(t, GetEgressCondition(status, crdv1a2.IPAssigned))
+	assert.True(t, RemoveEgressCondition(status, crdv1a2.IPAssigned))
+	assert.Nil(t, status.Conditions)
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maglev builds the lookup tables of Maglev consistent hashing, as
+// described in "Maglev: A Fast and Reliable Software Network Load Balancer".
+package maglev
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// Table returns a lookup table of the provided size, whose entries are the
+// indexes of the backends they are assigned to. The backends are identified by
+// their names, and get almost the same number of entries. When a backend is
+// added or removed, the entries of the other backends are mostly unchanged.
+// The table doesn't depend on the order of the backends. size must be at least
+// 2, and should be a prime number larger than the number of backends: when
+// there are more backends than entries, some backends get no entry.
+func Table(backends []string, size int) []int {
+	table := make([]int, size)
+	if len(backends) == 0 {
+		return table
+	}
+	// The backends take turns in the order of their names, so that the table
+	// doesn't depend on the order of the backends.
+	order := make([]int, len(backends))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return backends[order[i]] < backends[order[j]] })
+
+	// Each backend fills the entries in the order of its own permutation of
+	// the table, given by an offset and a skip, which must be coprime with
+	// size.
+	offsets := make([]int, len(backends))
+	skips := make([]int, len(backends))
+	for i, backend := range backends {
+		h := fnv.New64a()
+		h.Write([]byte(backend))
+		sum := h.Sum64()
+		offsets[i] = int(sum % uint64(size))
+		skips[i] = int((sum>>32)%uint64(size-1)) + 1
+		for gcd(skips[i], size) != 1 {
+			skips[i] = skips[i]%(size-1) + 1
+		}
+	}
+
+	for i := range table {
+		table[i] = -1
+	}
+	next := make([]int, len(backends))
+	for filled := 0; ; {
+		for _, i := range order {
+			entry := (offsets[i] + next[i]*skips[i]) % size
+			for table[entry] >= 0 {
+				next[i]++
+				entry = (offsets[i] + next[i]*skips[i]) % size
+			}
+			table[entry] = i
+			next[i]++
+			filled++
+			if filled == size {
+				return table
+			}
+		}
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
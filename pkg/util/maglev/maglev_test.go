@@ -0,0 +1,83 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maglev
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func backendNames(count int) []string {
+	backends := make([]string, count)
+	for i := range backends {
+		backends[i] = fmt.Sprintf("10.10.%d.%d:80", i/256, i%256)
+	}
+	return backends
+}
+
+// names returns the names of the backends of the entries of the table.
+func names(backends []string, table []int) []string {
+	entries := make([]string, len(table))
+	for i, backend := range table {
+		entries[i] = backends[backend]
+	}
+	return entries
+}
+
+func TestTable(t *testing.T) {
+	for _, size := range []int{797, 256} {
+		t.Run(fmt.Sprintf("size %d", size), func(t *testing.T) {
+			backends := backendNames(10)
+			table := Table(backends, size)
+			counts := make([]int, len(backends))
+			for _, backend := range table {
+				counts[backend]++
+			}
+			// The entries are evenly distributed across the backends.
+			for _, count := range counts {
+				assert.InDelta(t, size/len(backends), count, 1)
+			}
+
+			reversed := make([]string, len(backends))
+			for i, backend := range backends {
+				reversed[len(backends)-1-i] = backend
+			}
+			assert.Equal(t, names(backends, table), names(reversed, Table(reversed, size)), "The table should not depend on the order of the backends")
+
+			// Removing a backend reassigns its entries, and only few other entries.
+			removed := backends[3]
+			remaining := append(append([]string{}, backends[:3]...), backends[4:]...)
+			before, after := names(backends, table), names(remaining, Table(remaining, size))
+			changed := 0
+			for i := range before {
+				if before[i] != removed && before[i] != after[i] {
+					changed++
+				}
+			}
+			assert.Less(t, changed, size/10)
+		})
+	}
+}
+
+func TestTableMoreBackendsThanEntries(t *testing.T) {
+	table := Table(backendNames(20), 7)
+	assert.Len(t, table, 7)
+	for _, backend := range table {
+		assert.GreaterOrEqual(t, backend, 0)
+	}
+	assert.Equal(t, make([]int, 7), Table(nil, 7))
+}
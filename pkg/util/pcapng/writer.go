@@ -0,0 +1,109 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pcapng implements a minimal writer for the pcapng file format, as
+// described in https://datatracker.ietf.org/doc/draft-tuexen-opsawg-pcapng/.
+// Only a single Ethernet interface is supported, which is all that is needed to
+// save the packets captured by Antrea.
+package pcapng
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+const (
+	blockTypeSectionHeader        uint32 = 0x0A0D0D0A
+	blockTypeInterfaceDescription uint32 = 0x00000001
+	blockTypeEnhancedPacket       uint32 = 0x00000006
+
+	byteOrderMagic uint32 = 0x1A2B3C4D
+
+	// LinkTypeEthernet is the link type of the interface described in the
+	// file.
+	LinkTypeEthernet uint16 = 1
+)
+
+// Writer writes packets to an io.Writer in the pcapng format. The Section
+// Header Block and the Interface Description Block are written when the Writer
+// is created. The timestamps are in microseconds, which is the default
+// resolution of the format.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter creates a Writer and writes the file headers to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	pw := &Writer{w: w}
+	if err := pw.writeSectionHeader(); err != nil {
+		return nil, err
+	}
+	if err := pw.writeInterfaceDescription(); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *Writer) writeBlock(blockType uint32, body []byte) error {
+	padding := (4 - len(body)%4) % 4
+	// Block Type, Block Total Length, body, padding, Block Total Length.
+	totalLength := 12 + len(body) + padding
+	buf := make([]byte, totalLength)
+	binary.LittleEndian.PutUint32(buf[0:4], blockType)
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(totalLength))
+	copy(buf[8:], body)
+	binary.LittleEndian.PutUint32(buf[totalLength-4:], uint32(totalLength))
+	_, err := pw.w.Write(buf)
+	return err
+}
+
+func (pw *Writer) writeSectionHeader() error {
+	body := make([]byte, 16)
+	binary.LittleEndian.PutUint32(body[0:4], byteOrderMagic)
+	// Major version 1, minor version 0.
+	binary.LittleEndian.PutUint16(body[4:6], 1)
+	binary.LittleEndian.PutUint16(body[6:8], 0)
+	// Section Length is not specified.
+	binary.LittleEndian.PutUint64(body[8:16], 0xFFFFFFFFFFFFFFFF)
+	return pw.writeBlock(blockTypeSectionHeader, body)
+}
+
+func (pw *Writer) writeInterfaceDescription() error {
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint16(body[0:2], LinkTypeEthernet)
+	// SnapLen 0 means no limit.
+	binary.LittleEndian.PutUint32(body[4:8], 0)
+	return pw.writeBlock(blockTypeInterfaceDescription, body)
+}
+
+// WritePacket writes a packet as an Enhanced Packet Block. data is the captured
+// part of the packet, starting with the Ethernet header, and originalLength is
+// the length of the packet on the wire, which may be larger than len(data) if
+// the packet was truncated.
+func (pw *Writer) WritePacket(timestamp time.Time, data []byte, originalLength int) error {
+	if originalLength < len(data) {
+		originalLength = len(data)
+	}
+	body := make([]byte, 20+len(data))
+	ts := uint64(timestamp.UnixNano() / int64(time.Microsecond))
+	// Interface ID 0.
+	binary.LittleEndian.PutUint32(body[0:4], 0)
+	binary.LittleEndian.PutUint32(body[4:8], uint32(ts>>32))
+	binary.LittleEndian.PutUint32(body[8:12], uint32(ts))
+	binary.LittleEndian.PutUint32(body[12:16], uint32(len(data)))
+	binary.LittleEndian.PutUint32(body[16:20], uint32(originalLength))
+	copy(body[20:], data)
+	return pw.writeBlock(blockTypeEnhancedPacket, body)
+}
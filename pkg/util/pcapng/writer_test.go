@@ -0,0 +1,83 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcapng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type block struct {
+	blockType uint32
+	body      []byte
+}
+
+func readBlocks(t *testing.T, data []byte) []block {
+	var blocks []block
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 12)
+		blockType := binary.LittleEndian.Uint32(data[0:4])
+		totalLength := int(binary.LittleEndian.Uint32(data[4:8]))
+		require.Equal(t, 0, totalLength%4, "block length must be a multiple of 4")
+		require.GreaterOrEqual(t, len(data), totalLength)
+		require.Equal(t, uint32(totalLength), binary.LittleEndian.Uint32(data[totalLength-4:totalLength]))
+		blocks = append(blocks, block{blockType: blockType, body: data[8 : totalLength-4]})
+		data = data[totalLength:]
+	}
+	return blocks
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+
+	ts := time.Unix(1600000000, 123456000)
+	packets := [][]byte{
+		bytes.Repeat([]byte{0xab}, 64),
+		bytes.Repeat([]byte{0xcd}, 61),
+	}
+	require.NoError(t, w.WritePacket(ts, packets[0], 64))
+	require.NoError(t, w.WritePacket(ts, packets[1], 1500))
+
+	blocks := readBlocks(t, buf.Bytes())
+	require.Len(t, blocks, 4)
+
+	assert.Equal(t, blockTypeSectionHeader, blocks[0].blockType)
+	assert.Equal(t, byteOrderMagic, binary.LittleEndian.Uint32(blocks[0].body[0:4]))
+	assert.Equal(t, uint16(1), binary.LittleEndian.Uint16(blocks[0].body[4:6]))
+
+	assert.Equal(t, blockTypeInterfaceDescription, blocks[1].blockType)
+	assert.Equal(t, LinkTypeEthernet, binary.LittleEndian.Uint16(blocks[1].body[0:2]))
+
+	expectedTS := uint64(ts.UnixNano() / int64(time.Microsecond))
+	expectedOriginalLengths := []uint32{64, 1500}
+	for i, b := range blocks[2:] {
+		assert.Equal(t, blockTypeEnhancedPacket, b.blockType)
+		body := b.body
+		tsHigh := binary.LittleEndian.Uint32(body[4:8])
+		tsLow := binary.LittleEndian.Uint32(body[8:12])
+		assert.Equal(t, expectedTS, uint64(tsHigh)<<32|uint64(tsLow))
+		capturedLength := binary.LittleEndian.Uint32(body[12:16])
+		assert.Equal(t, uint32(len(packets[i])), capturedLength)
+		assert.Equal(t, expectedOriginalLengths[i], binary.LittleEndian.Uint32(body[16:20]))
+		assert.Equal(t, packets[i], body[20:20+capturedLength])
+	}
+}
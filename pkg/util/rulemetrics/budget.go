@@ -0,0 +1,117 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rulemetrics bounds the cardinality of the Prometheus metrics exported
+// per NetworkPolicy rule by the antrea-agent and the antrea-controller.
+package rulemetrics
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Labels are the labels of the metrics of a NetworkPolicy rule, in the order of
+// Key.LabelValues.
+var Labels = []string{"policy_type", "policy_namespace", "policy_name", "rule"}
+
+// Key identifies a NetworkPolicy rule in the metrics. Rule is empty for the K8s
+// NetworkPolicies, whose metrics are per policy.
+type Key struct {
+	PolicyType      string
+	PolicyNamespace string
+	PolicyName      string
+	Rule            string
+}
+
+// LabelValues returns the values of Labels for the rule.
+func (k Key) LabelValues() []string {
+	return []string{k.PolicyType, k.PolicyNamespace, k.PolicyName, k.Rule}
+}
+
+// LabelSet returns the labels of the metrics of the rule.
+func (k Key) LabelSet() map[string]string {
+	return map[string]string{Labels[0]: k.PolicyType, Labels[1]: k.PolicyNamespace, Labels[2]: k.PolicyName, Labels[3]: k.Rule}
+}
+
+// Budget decides which NetworkPolicy rules have metrics. The policies must be in
+// the allowlist of Tiers or Namespaces, and at most maxRules rules are admitted
+// at the same time. A rule stays admitted until it is released, so the metrics
+// of the admitted rules are never interrupted by new rules. It is safe for
+// concurrent use.
+type Budget struct {
+	maxRules   int
+	tiers      sets.String
+	namespaces sets.String
+
+	mutex    sync.Mutex
+	admitted map[Key]struct{}
+}
+
+// NewBudget returns a Budget admitting at most maxRules rules. When tiers and
+// namespaces are both empty, the rules of all the policies are allowed.
+func NewBudget(maxRules int, tiers, namespaces []string) *Budget {
+	return &Budget{
+		maxRules:   maxRules,
+		tiers:      sets.NewString(tiers...),
+		namespaces: sets.NewString(namespaces...),
+		admitted:   map[Key]struct{}{},
+	}
+}
+
+// HasTiers returns whether the allowlist includes Tiers, in which case callers
+// must provide the Tiers of the policies to Allows.
+func (b *Budget) HasTiers() bool {
+	return b.tiers.Len() > 0
+}
+
+// Allows returns whether the rules of a policy in the provided Tier and
+// Namespace are allowed. tier is empty for K8s NetworkPolicies and namespace is
+// empty for cluster-scoped policies.
+func (b *Budget) Allows(tier, namespace string) bool {
+	if b.tiers.Len() == 0 && b.namespaces.Len() == 0 {
+		return true
+	}
+	return (tier != "" && b.tiers.Has(tier)) || (namespace != "" && b.namespaces.Has(namespace))
+}
+
+// Admit returns whether the rule has metrics, admitting it if the budget is not
+// exhausted.
+func (b *Budget) Admit(key Key) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, exists := b.admitted[key]; exists {
+		return true
+	}
+	if len(b.admitted) >= b.maxRules {
+		return false
+	}
+	b.admitted[key] = struct{}{}
+	return true
+}
+
+// Release releases the rules whose keys are selected by the provided function,
+// and returns them so that their metrics can be deleted.
+func (b *Budget) Release(selected func(key Key) bool) []Key {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var released []Key
+	for key := range b.admitted {
+		if selected(key) {
+			delete(b.admitted, key)
+			released = append(released, key)
+		}
+	}
+	return released
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulemetrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetAllows(t *testing.T) {
+	tests := []struct {
+		name       string
+		tiers      []string
+		namespaces []string
+		tier       string
+		namespace  string
+		expected   bool
+	}{
+		{name: "no allowlist", tier: "application", namespace: "ns1", expected: true},
+		{name: "allowed tier", tiers: []string{"securityops"}, tier: "securityops", expected: true},
+		{name: "other tier", tiers: []string{"securityops"}, tier: "application", namespace: "ns1", expected: false},
+		{name: "allowed namespace", namespaces: []string{"ns1"}, tier: "application", namespace: "ns1", expected: true},
+		{name: "K8s NetworkPolicy in allowed namespace", tiers: []string{"securityops"}, namespaces: []string{"ns1"}, namespace: "ns1", expected: true},
+		{name: "cluster-scoped policy", namespaces: []string{"ns1"}, tier: "application", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBudget(10, tt.tiers, tt.namespaces)
+			assert.Equal(t, tt.expected, b.Allows(tt.tier, tt.namespace))
+		})
+	}
+}
+
+func TestBudgetAdmit(t *testing.T) {
+	b := NewBudget(2, nil, nil)
+	rule1 := Key{PolicyType: "AntreaNetworkPolicy", PolicyNamespace: "ns1", PolicyName: "np1", Rule: "rule1"}
+	rule2 := Key{PolicyType: "AntreaNetworkPolicy", PolicyNamespace: "ns1", PolicyName: "np1", Rule: "rule2"}
+	rule3 := Key{PolicyType: "AntreaClusterNetworkPolicy", PolicyName: "cnp1", Rule: "rule1"}
+
+	assert.True(t, b.Admit(rule1))
+	assert.True(t, b.Admit(rule2))
+	// The budget is exhausted, but the admitted rules stay admitted.
+	assert.False(t, b.Admit(rule3))
+	assert.True(t, b.Admit(rule1))
+
+	released := b.Release(func(key Key) bool { return key.PolicyName == "np1" && key.Rule == "rule2" })
+	assert.Equal(t, []Key{rule2}, released)
+	assert.True(t, b.Admit(rule3))
+	assert.Equal(t, []string{"AntreaClusterNetworkPolicy", "", "cnp1", "rule1"}, rule3.LabelValues())
+	assert.Equal(t, map[string]string{"policy_type": "AntreaClusterNetworkPolicy", "policy_namespace": "", "policy_name": "cnp1", "rule": "rule1"}, rule3.LabelSet())
+}
@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"antrea.io/antrea/pkg/apis"
 	"antrea.io/antrea/pkg/features"
 )
 
@@ -260,6 +261,46 @@ func testLoadBalancerLocalFromPod(t *testing.T, data *TestData, pods []string, u
 	}
 }
 
+// TestProxyLoadBalancerServiceDSR tests that a LoadBalancer Service in DSR mode can receive requests larger than the
+// MTU from the clients, which are forwarded through the tunnel to the Node of the Endpoint.
+func TestProxyLoadBalancerServiceDSR(t *testing.T) {
+	skipIfNotIPv4Cluster(t)
+	skipIfProxyDisabled(t)
+	skipIfFeatureDisabled(t, features.LoadBalancerModeDSR, true /* checkAgent */, false /* checkController */)
+	skipIfHasWindowsNodes(t)
+	skipIfNumNodesLessThan(t, 2)
+	data, err := setupTest(t)
+	if err != nil {
+		t.Fatalf("Error when setting up test: %v", err)
+	}
+	defer teardownTest(t, data)
+	skipIfProxyAllDisabled(t, data)
+
+	ingressIP := []string{"169.254.169.3"}
+	ipProtocol := corev1.IPv4Protocol
+	svc, err := data.createAgnhostLoadBalancerService("agnhost-dsr", false, false, ingressIP, &ipProtocol)
+	require.NoError(t, err)
+	svc.Annotations = map[string]string{apis.ServiceLoadBalancerModeAnnotationKey: "DSR"}
+	_, err = data.clientset.CoreV1().Services(svc.Namespace).Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// The only Endpoint runs on another Node than the client, so that the requests are forwarded through the tunnel.
+	createAgnhostPod(t, data, "agnhost-dsr", nodeName(1), false)
+	url := net.JoinHostPort(ingressIP[0], "8080")
+
+	t.Run("Small Request", func(t *testing.T) {
+		require.NoError(t, probeFromNode(nodeName(0), url), "Service LoadBalancer in DSR mode should be able to be connected from Node")
+	})
+	t.Run("Large Request", func(t *testing.T) {
+		// Upload 1MiB, so that the segments sent by the client are as large as the MSS allows. Without clamping the
+		// MSS, they would exceed the MTU of the Node network once encapsulated, and the upload would time out.
+		cmd := fmt.Sprintf("head -c 1048576 /dev/zero | curl --connect-timeout 1 --retry 5 --retry-connrefused --max-time 30 -sS -F file=@- %s/upload", url)
+		rc, stdout, stderr, err := RunCommandOnNode(nodeName(0), cmd)
+		require.NoError(t, err)
+		require.Equal(t, 0, rc, "Service LoadBalancer in DSR mode should receive large requests, stdout: %s, stderr: %s", stdout, stderr)
+	})
+}
+
 func TestProxyNodePortServiceIPv4(t *testing.T) {
 	skipIfNotIPv4Cluster(t)
 	testProxyNodePortService(t, false)
@@ -115,7 +115,7 @@ func TestConnectivityFlows(t *testing.T) {
 		antrearuntime.WindowsOS = runtime.GOOS
 	}
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, true, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 	defer func() {
@@ -159,7 +159,7 @@ func TestAntreaFlexibleIPAMConnectivityFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, true, false, false, true)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, true, false, false, true, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 	defer func() {
@@ -201,7 +201,7 @@ func TestAntreaFlexibleIPAMConnectivityFlows(t *testing.T) {
 }
 
 func TestReplayFlowsConnectivityFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -237,7 +237,7 @@ func TestReplayFlowsConnectivityFlows(t *testing.T) {
 }
 
 func TestReplayFlowsNetworkPolicyFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -423,7 +423,7 @@ func TestNetworkPolicyFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
@@ -533,7 +533,7 @@ func TestIPv6ConnectivityFlows(t *testing.T) {
 	// Initialize ovs metrics (Prometheus) to test them
 	metrics.InitializeOVSMetrics()
 
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, true, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge: %v", err))
 
@@ -575,7 +575,7 @@ type svcConfig struct {
 }
 
 func TestProxyServiceFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, true, false, false, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
@@ -593,10 +593,14 @@ func TestProxyServiceFlows(t *testing.T) {
 		k8stypes.NewEndpointInfo(&k8sproxy.BaseEndpointInfo{
 			Endpoint: net.JoinHostPort("192.168.1.2", "8081"),
 			IsLocal:  true,
+			Ready:    true,
+			Serving:  true,
 		}),
 		k8stypes.NewEndpointInfo(&k8sproxy.BaseEndpointInfo{
 			Endpoint: net.JoinHostPort("10.20.1.11", "8081"),
 			IsLocal:  false,
+			Ready:    true,
+			Serving:  true,
 		}),
 	}
 
@@ -661,7 +665,7 @@ func installServiceFlows(t *testing.T, gid uint32, svc svcConfig, endpointList [
 	groupID := ofconfig.GroupIDType(gid)
 	err := c.InstallEndpointFlows(svc.protocol, endpointList)
 	assert.NoError(t, err, "no error should return when installing flows for Endpoints")
-	err = c.InstallServiceGroup(groupID, svc.withSessionAffinity, endpointList)
+	err = c.InstallServiceGroup(groupID, svc.withSessionAffinity, types.ServiceLBModeDefault, endpointList)
 	assert.NoError(t, err, "no error should return when installing groups for Service")
 	err = c.InstallServiceFlows(groupID, svc.ip, svc.port, svc.protocol, stickyMaxAgeSeconds, false, v1.ServiceTypeClusterIP)
 	assert.NoError(t, err, "no error should return when installing flows for Service")
@@ -1514,7 +1518,7 @@ func prepareSNATFlows(snatIP net.IP, mark, podOFPort, podOFPortRemote uint32, vM
 }
 
 func TestSNATFlows(t *testing.T) {
-	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, false, false, true, false, false, false)
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, false, false, true, false, false, false, false)
 	err := ofTestUtils.PrepareOVSBridge(br)
 	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
 
@@ -1563,3 +1567,71 @@ func TestSNATFlows(t *testing.T) {
 		ofTestUtils.CheckFlowExists(t, ovsCtlClient, tableFlow.tableName, 0, false, tableFlow.flows)
 	}
 }
+
+func TestPacketCaptureFlowsWithTCPMetrics(t *testing.T) {
+	c = ofClient.NewClient(br, bridgeMgmtAddr, ovsconfig.OVSDatapathNetdev, false, false, true, false, false, false, false)
+	err := ofTestUtils.PrepareOVSBridge(br)
+	require.Nil(t, err, fmt.Sprintf("Failed to prepare OVS bridge %s", br))
+
+	config := prepareConfiguration()
+	_, err = c.Initialize(roundInfo, config.nodeConfig, &config1.NetworkConfig{TrafficEncapMode: config1.TrafficEncapModeEncap})
+	require.Nil(t, err, "Failed to initialize OFClient")
+
+	defer func() {
+		err = c.Disconnect()
+		assert.Nil(t, err, fmt.Sprintf("Error while disconnecting from OVS bridge: %v", err))
+		err = ofTestUtils.DeleteOVSBridge(br)
+		assert.Nil(t, err, fmt.Sprintf("Error while deleting OVS bridge: %v", err))
+	}()
+
+	require.Nil(t, c.InstallTCPMetricsFlows())
+	packet := &ofconfig.Packet{
+		SourceIP:        net.ParseIP("10.10.0.2"),
+		DestinationIP:   net.ParseIP("10.10.0.3"),
+		IPProto:         6,
+		DestinationPort: 80,
+	}
+	require.Nil(t, c.InstallPacketCaptureFlows("pc1", packet, 300))
+
+	// countFlows returns the number of L2ForwardingOut flows which contain all the
+	// provided strings, and the number of controller actions of each of them.
+	countFlows := func(substrings ...string) (int, []int) {
+		flowList, err := ofTestUtils.OfctlDumpTableFlows(ovsCtlClient, "L2ForwardingOut")
+		require.Nil(t, err)
+		var controllerActions []int
+		for _, flow := range flowList {
+			matched := true
+			for _, s := range substrings {
+				if !strings.Contains(flow, s) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				controllerActions = append(controllerActions, strings.Count(flow, "controller("))
+			}
+		}
+		return len(controllerActions), controllerActions
+	}
+
+	// The TCP metrics flows have a lower priority than the PacketCapture flows.
+	count, _ := countFlows("priority=201,tcp,", "tcp_flags=+syn")
+	assert.Equal(t, 1, count)
+	count, _ = countFlows("priority=201,tcp,", "tcp_flags=+rst")
+	assert.Equal(t, 1, count)
+	count, actions := countFlows("priority=202,tcp,", "nw_src=10.10.0.2,nw_dst=10.10.0.3,tp_dst=80")
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []int{1}, actions)
+	// The captured SYN and RST packets are sent to the controller for both features.
+	for _, flag := range []string{"tcp_flags=+syn", "tcp_flags=+rst"} {
+		count, actions = countFlows("priority=203,tcp,", "nw_src=10.10.0.2,nw_dst=10.10.0.3,tp_dst=80", flag)
+		assert.Equal(t, 1, count, "Missing PacketCapture flow with %s", flag)
+		assert.Equal(t, []int{2}, actions)
+	}
+
+	require.Nil(t, c.UninstallPacketCaptureFlows("pc1"))
+	count, _ = countFlows("nw_src=10.10.0.2,nw_dst=10.10.0.3")
+	assert.Equal(t, 0, count)
+	count, _ = countFlows("priority=201,tcp,")
+	assert.Equal(t, 2, count)
+}
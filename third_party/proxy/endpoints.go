@@ -30,12 +30,13 @@ limitations under the License.
 
 Modifies:
 - Remove imports: "net", "reflect", "strconv", "sync", "time", "k8s.io/api/core/v1",
-  "k8s.io/api/discovery/v1beta1", "k8s.io/apimachinery/pkg/util/sets",
-  "k8s.io/client-go/tools/record", "k8s.io/klog/v2", "k8s.io/utils/net"
+  "k8s.io/api/discovery/v1beta1", "k8s.io/client-go/tools/record", "k8s.io/klog/v2",
+  "k8s.io/utils/net"
 - Remove vars: "supportedEndpointSliceAddressTypes"
 - Remove functions: "newBaseEndpointInfo", "makeEndpointFunc",
   "NewEndpointChangeTracker", "detectStaleConnections"
 - Remove structs: "EndpointChangeTracker", "EndpointsMap"
+- Add Weight to BaseEndpointInfo
 */
 package proxy
 
@@ -43,6 +44,8 @@ import (
 	"net"
 	"strconv"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	utilproxy "antrea.io/antrea/third_party/proxy/util"
 )
 
@@ -55,6 +58,31 @@ type BaseEndpointInfo struct {
 	// IsLocal indicates whether the endpoint is running in same host as kube-proxy.
 	IsLocal  bool
 	Topology map[string]string
+
+	// Ready indicates whether this endpoint is ready and NOT terminating.
+	// For pods, this is true if a pod has a ready status and a nil deletion timestamp.
+	// This is only set when watching EndpointSlices. If using Endpoints, this is always
+	// true since only ready endpoints are read from Endpoints.
+	// TODO: Ready can be inferred from Serving and Terminating below when enabled by default.
+	Ready bool
+	// Serving indicates whether this endpoint is ready regardless of its terminating state.
+	// For pods this is true if it has a ready status regardless of its deletion timestamp.
+	// This is only set when watching EndpointSlices. If using Endpoints, this is always
+	// true since only ready endpoints are read from Endpoints.
+	Serving bool
+	// Terminating indicates whether this endpoint is terminating.
+	// For pods this is true if it has a non-nil deletion timestamp.
+	// This is only set when watching EndpointSlices. If using Endpoints, this is always
+	// false since terminating endpoints are always excluded from Endpoints.
+	Terminating bool
+
+	// ZoneHints represent the zone hints for the endpoint. This is based on
+	// endpoint.hints.forZones[*].name in the EndpointSlice API.
+	ZoneHints sets.String
+
+	// Weight is the relative weight of the endpoint, or 0 if no weight is
+	// specified. It's read from the annotation of the Pod or the EndpointSlice.
+	Weight int
 }
 
 var _ Endpoint = &BaseEndpointInfo{}
@@ -74,6 +102,37 @@ func (info *BaseEndpointInfo) GetTopology() map[string]string {
 	return info.Topology
 }
 
+// GetZoneHints returns the zone hints for the endpoint.
+func (info *BaseEndpointInfo) GetZoneHints() sets.String {
+	return info.ZoneHints
+}
+
+// IsReady returns true if an endpoint is ready and not terminating.
+func (info *BaseEndpointInfo) IsReady() bool {
+	return info.Ready
+}
+
+// IsServing returns true if an endpoint is ready, regardless of if the
+// endpoint is terminating.
+func (info *BaseEndpointInfo) IsServing() bool {
+	return info.Serving
+}
+
+// IsTerminating returns true if an endpoint is terminating. For pods,
+// that is any pod with a deletion timestamp.
+func (info *BaseEndpointInfo) IsTerminating() bool {
+	return info.Terminating
+}
+
+// GetWeight returns the relative weight of the endpoint, which is 1 when no
+// weight is specified.
+func (info *BaseEndpointInfo) GetWeight() int {
+	if info.Weight == 0 {
+		return 1
+	}
+	return info.Weight
+}
+
 // IP returns just the IP part of the endpoint, it's a part of proxy.Endpoint interface.
 func (info *BaseEndpointInfo) IP() string {
 	return utilproxy.IPPart(info.Endpoint)
@@ -86,13 +145,24 @@ func (info *BaseEndpointInfo) Port() (int, error) {
 
 // Equal is part of proxy.Endpoint interface.
 func (info *BaseEndpointInfo) Equal(other Endpoint) bool {
-	return info.String() == other.String() && info.GetIsLocal() == other.GetIsLocal()
+	return info.String() == other.String() &&
+		info.GetIsLocal() == other.GetIsLocal() &&
+		info.IsReady() == other.IsReady() &&
+		info.IsServing() == other.IsServing() &&
+		info.IsTerminating() == other.IsTerminating() &&
+		info.GetWeight() == other.GetWeight()
 }
 
-func NewBaseEndpointInfo(IP string, port int, isLocal bool, topology map[string]string) *BaseEndpointInfo {
+func NewBaseEndpointInfo(IP string, port int, isLocal bool, topology map[string]string,
+	ready, serving, terminating bool, zoneHints sets.String, weight int) *BaseEndpointInfo {
 	return &BaseEndpointInfo{
-		Endpoint: net.JoinHostPort(IP, strconv.Itoa(port)),
-		IsLocal:  isLocal,
-		Topology: topology,
+		Endpoint:    net.JoinHostPort(IP, strconv.Itoa(port)),
+		IsLocal:     isLocal,
+		Topology:    topology,
+		Ready:       ready,
+		Serving:     serving,
+		Terminating: terminating,
+		ZoneHints:   zoneHints,
+		Weight:      weight,
 	}
 }
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/*
+// Copyright 2021 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+Modifies:
+- Replace the feature gate checks with the topologyAwareHintsEnabled parameter of FilterEndpoints
+- Keep the non-ready Endpoints when filtering Endpoints with hints or internalTrafficPolicy, as they are only used
+  for their existing connections or as fallback Endpoints
+- Fall back to all the endpoints when internalTrafficPolicy is Local and there is no ready local endpoint
+*/
+
+package proxy
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// FilterEndpoints filters endpoints based on Service configuration, node
+// labels, and enabled feature gates. This is primarily used to enable topology
+// aware routing.
+func FilterEndpoints(endpoints []Endpoint, svcInfo ServicePort, nodeLabels map[string]string, topologyAwareHintsEnabled bool) []Endpoint {
+	if svcInfo.NodeLocalExternal() {
+		return endpoints
+	}
+
+	if svcInfo.NodeLocalInternal() {
+		return filterEndpointsInternalTrafficPolicy(svcInfo.InternalTrafficPolicy(), endpoints)
+	}
+
+	if topologyAwareHintsEnabled {
+		return filterEndpointsWithHints(endpoints, svcInfo.HintsAnnotation(), nodeLabels)
+	}
+
+	return endpoints
+}
+
+// filterEndpointsWithHints provides filtering based on the hints included in
+// EndpointSlices. If any of the following are true, the full list of endpoints
+// will be returned without any filtering:
+//   - The AnnotationTopologyAwareHints annotation is not set to "Auto" for this
+//     Service.
+//   - No zone is specified in node labels.
+//   - No endpoints for this Service have a hint pointing to the zone this
+//     instance of the proxy is running in.
+//   - One or more ready endpoints for this Service do not have hints specified.
+//
+// The non-ready endpoints, which have no hints, are never filtered out.
+func filterEndpointsWithHints(endpoints []Endpoint, hintsAnnotation string, nodeLabels map[string]string) []Endpoint {
+	if hintsAnnotation != "Auto" && hintsAnnotation != "auto" {
+		if hintsAnnotation != "" && hintsAnnotation != "Disabled" && hintsAnnotation != "disabled" {
+			klog.InfoS("Skipping topology aware endpoint filtering since Service has unexpected value", "annotationTopologyAwareHints", v1.AnnotationTopologyAwareHints, "hints", hintsAnnotation)
+		}
+		return endpoints
+	}
+
+	zone, ok := nodeLabels[v1.LabelTopologyZone]
+	if !ok || zone == "" {
+		klog.InfoS("Skipping topology aware endpoint filtering since node is missing label", "label", v1.LabelTopologyZone)
+		return endpoints
+	}
+
+	filteredEndpoints := []Endpoint{}
+	hasReadyEndpoint := false
+
+	for _, endpoint := range endpoints {
+		if !endpoint.IsReady() {
+			filteredEndpoints = append(filteredEndpoints, endpoint)
+			continue
+		}
+		if endpoint.GetZoneHints().Len() == 0 {
+			klog.InfoS("Skipping topology aware endpoint filtering since one or more endpoints is missing a zone hint")
+			return endpoints
+		}
+		if endpoint.GetZoneHints().Has(zone) {
+			filteredEndpoints = append(filteredEndpoints, endpoint)
+			hasReadyEndpoint = true
+		}
+	}
+
+	if !hasReadyEndpoint {
+		klog.InfoS("Skipping topology aware endpoint filtering since no hints were provided for zone", "zone", zone)
+		return endpoints
+	}
+
+	return filteredEndpoints
+}
+
+// filterEndpointsInternalTrafficPolicy returns the node local endpoints based
+// on configured InternalTrafficPolicy. The full list of endpoints is returned
+// if there is no ready node local endpoint.
+//
+// The non-ready endpoints are never filtered out.
+func filterEndpointsInternalTrafficPolicy(internalTrafficPolicy *v1.ServiceInternalTrafficPolicyType, endpoints []Endpoint) []Endpoint {
+	if internalTrafficPolicy == nil || *internalTrafficPolicy == v1.ServiceInternalTrafficPolicyCluster {
+		return endpoints
+	}
+
+	var filteredEndpoints []Endpoint
+	hasReadyEndpoint := false
+
+	// Get all the local endpoints
+	for _, endpoint := range endpoints {
+		if !endpoint.IsReady() {
+			filteredEndpoints = append(filteredEndpoints, endpoint)
+			continue
+		}
+		if endpoint.GetIsLocal() {
+			filteredEndpoints = append(filteredEndpoints, endpoint)
+			hasReadyEndpoint = true
+		}
+	}
+
+	if !hasReadyEndpoint {
+		klog.V(4).InfoS("Falling back to all the endpoints since there is no ready node local endpoint")
+		return endpoints
+	}
+
+	// When internalTrafficPolicy is Local, only return the local endpoints
+	return filteredEndpoints
+}
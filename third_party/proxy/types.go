@@ -33,6 +33,7 @@ Modifies:
 - Remove config.EndpointSliceHandler, config.NodeHandler from Provider interface type
 - Remove NodeHandler, EndpointSliceHandler, Sync() from Provider interface
 - Add Run() to Provider interface
+- Add GetWeight() to Endpoint interface
 */
 
 package proxy
@@ -43,6 +44,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"antrea.io/antrea/third_party/proxy/config"
 )
@@ -121,6 +123,27 @@ type Endpoint interface {
 	GetIsLocal() bool
 	// GetTopology returns the topology information of the endpoint.
 	GetTopology() map[string]string
+	// GetZoneHints returns the zone hints for the endpoint. This is based on
+	// endpoint.hints.forZones[*].name in the EndpointSlice API.
+	GetZoneHints() sets.String
+	// IsReady returns true if an endpoint is ready and not terminating.
+	// This is only set when watching EndpointSlices. If using Endpoints, this is always
+	// true since only ready endpoints are read from Endpoints.
+	IsReady() bool
+	// IsServing returns true if an endpoint is ready. It does not account
+	// for terminating state.
+	// This is only set when watching EndpointSlices. If using Endpoints, this is always
+	// true since only ready endpoints are read from Endpoints.
+	IsServing() bool
+	// IsTerminating returns true if an endpoint is terminating. For pods,
+	// that is any pod with a deletion timestamp.
+	// This is only set when watching EndpointSlices. If using Endpoints, this is always
+	// false since terminating endpoints are always excluded from Endpoints.
+	IsTerminating() bool
+	// GetWeight returns the relative weight of the endpoint, which is used
+	// by the Weighted load balancing mode of Services. It's 1 when no weight
+	// is specified.
+	GetWeight() int
 	// IP returns IP part of the endpoint.
 	IP() string
 	// Port returns the Port part of the endpoint.